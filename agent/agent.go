@@ -23,6 +23,8 @@ import (
 
 	"google.golang.org/adk/artifact"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/internal/telemetry"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
@@ -156,9 +158,14 @@ func (a *agent) SubAgents() []Agent {
 
 func (a *agent) Run(ctx InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
+		spanCtx, spans := telemetry.StartTrace(ctx, "agent_run ["+a.name+"]")
+		telemetry.TraceAgentRun(spans, a.name, a.description, ctx.InvocationID())
+		var spanErr error
+		defer func() { telemetry.EndTrace(spans, spanErr) }()
+
 		// TODO: verify&update the setup here. Should we branch etc.
 		ctx := &invocationContext{
-			Context:   ctx,
+			Context:   spanCtx,
 			agent:     a,
 			artifacts: ctx.Artifacts(),
 			memory:    ctx.Memory(),
@@ -183,9 +190,15 @@ func (a *agent) Run(ctx InvocationContext) iter.Seq2[*session.Event, error] {
 		}
 
 		for event, err := range a.run(ctx) {
+			if err != nil {
+				spanErr = err
+			}
 			if event != nil && event.Author == "" {
 				event.Author = getAuthorForEvent(ctx, event)
 			}
+			if event != nil && event.Branch == "" {
+				event.Branch = ctx.Branch()
+			}
 			if !yield(event, err) {
 				return
 			}
@@ -225,7 +238,11 @@ func runBeforeAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 		actions:           &session.EventActions{StateDelta: make(map[string]any)},
 	}
 
-	for _, callback := range ctx.Agent().internal().beforeAgentCallbacks {
+	var pluginCallbacks []func(context.Context) (*genai.Content, error)
+	if cfg := runconfig.FromContext(ctx); cfg != nil {
+		pluginCallbacks = cfg.BeforeAgentCallbacks
+	}
+	for _, callback := range withPluginCallbacks(pluginCallbacks, agent.internal().beforeAgentCallbacks) {
 		content, err := callback(callbackCtx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to run before agent callback: %w", err)
@@ -268,7 +285,11 @@ func runAfterAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 		actions:           &session.EventActions{StateDelta: make(map[string]any)},
 	}
 
-	for _, callback := range agent.internal().afterAgentCallbacks {
+	var pluginCallbacks []func(context.Context) (*genai.Content, error)
+	if cfg := runconfig.FromContext(ctx); cfg != nil {
+		pluginCallbacks = cfg.AfterAgentCallbacks
+	}
+	for _, callback := range withPluginCallbacks(pluginCallbacks, agent.internal().afterAgentCallbacks) {
 		newContent, err := callback(callbackCtx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to run after agent callback: %w", err)
@@ -300,6 +321,21 @@ func runAfterAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 	return nil, nil
 }
 
+// withPluginCallbacks returns the agent callbacks preceded by the hooks of
+// the runner plugins.
+func withPluginCallbacks[T ~func(CallbackContext) (*genai.Content, error)](pluginCallbacks []func(context.Context) (*genai.Content, error), callbacks []T) []T {
+	if len(pluginCallbacks) == 0 {
+		return callbacks
+	}
+	all := make([]T, 0, len(pluginCallbacks)+len(callbacks))
+	for _, callback := range pluginCallbacks {
+		all = append(all, func(ctx CallbackContext) (*genai.Content, error) {
+			return callback(ctx)
+		})
+	}
+	return append(all, callbacks...)
+}
+
 // TODO: unify with internal/context.callbackContext
 
 type callbackContext struct {
@@ -324,6 +360,14 @@ func (c *callbackContext) Artifacts() Artifacts {
 	return c.invocationContext.Artifacts()
 }
 
+// Usage implements CallbackContext.
+func (c *callbackContext) Usage() *model.Usage {
+	if cfg := runconfig.FromContext(c); cfg != nil {
+		return cfg.Usage
+	}
+	return nil
+}
+
 func (c *callbackContext) InvocationID() string {
 	return c.invocationContext.InvocationID()
 }
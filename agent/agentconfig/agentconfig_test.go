@@ -0,0 +1,446 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/agentconfig"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// stdioServerEnv makes the test binary act as a MCP stdio server, see
+// TestMain.
+const stdioServerEnv = "AGENTCONFIG_TEST_STDIO_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stdioServerEnv) == "" {
+		os.Exit(m.Run())
+	}
+	if err := os.WriteFile(os.Getenv("PID_FILE"), []byte(strconv.Itoa(os.Getpid())), 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_weather", Description: "returns weather in the given city"},
+		func(context.Context, *mcp.CallToolRequest, weatherArgs) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "sunny"}}}, nil, nil
+		})
+	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// writeFiles writes the files, keyed by path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	cfg := &agentconfig.Config{
+		AgentClass:  agentconfig.ClassSequentialAgent,
+		Name:        "pipeline",
+		Description: "Writes and reviews.",
+		SubAgents: []agentconfig.SubAgentConfig{
+			{Agent: &agentconfig.Config{
+				Name:        "writer",
+				Model:       "gemini-2.5-flash",
+				Instruction: "Write about: {topic}",
+				OutputKey:   "draft",
+				Tools: []agentconfig.ToolConfig{
+					{Name: "google_search"},
+					{MCP: &agentconfig.MCPConfig{
+						Command:    "npx",
+						Args:       []string{"-y", "@modelcontextprotocol/server-filesystem", "."},
+						Env:        map[string]string{"DEBUG": "1"},
+						ToolFilter: []string{"read_file"},
+						NamePrefix: "fs_",
+					}},
+					{MCP: &agentconfig.MCPConfig{
+						URL:       "https://example.com/mcp",
+						Transport: "sse",
+						Headers:   map[string]string{"Authorization": "Bearer ${TOKEN}"},
+					}},
+				},
+			}},
+			{Agent: &agentconfig.Config{
+				AgentClass:    agentconfig.ClassLoopAgent,
+				Name:          "review_loop",
+				MaxIterations: 3,
+			}},
+			{ConfigPath: "reviewer.yaml"},
+		},
+	}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got, err := agentconfig.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v, YAML:\n%s", err, data)
+	}
+	if diff := cmp.Diff(cfg, got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"root_agent.yaml": `
+agent_class: SequentialAgent
+name: pipeline
+sub_agents:
+  - agent:
+      name: writer
+      model: gemini-2.5-flash
+      output_key: draft
+      tools:
+        - name: google_search
+        - name: load_artifacts
+  - config_path: review/loop.yaml
+`,
+		"review/loop.yaml": `
+agent_class: LoopAgent
+name: review_loop
+max_iterations: 3
+sub_agents:
+  - config_path: reviewer.yaml
+`,
+		"review/reviewer.yaml": `
+name: reviewer
+model: gemini-2.5-flash
+instruction: "Review the draft, call exit_loop when it is good: {draft}"
+tools:
+  - name: exit_loop
+`,
+	})
+
+	a, err := agentconfig.Load(filepath.Join(dir, "root_agent.yaml"), nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// tree returns the names of the agents of the tree, depth first.
+	var tree func(a agent.Agent) []string
+	tree = func(a agent.Agent) []string {
+		names := []string{a.Name()}
+		for _, sub := range a.SubAgents() {
+			names = append(names, tree(sub)...)
+		}
+		return names
+	}
+	if diff := cmp.Diff([]string{"pipeline", "writer", "review_loop", "reviewer"}, tree(a)); diff != "" {
+		t.Errorf("agent tree mismatch (-want +got):\n%s", diff)
+	}
+
+	writer := llminternal.Reveal(a.SubAgents()[0].(llminternal.Agent))
+	var toolNames []string
+	for _, tl := range writer.Tools {
+		toolNames = append(toolNames, tl.Name())
+	}
+	if diff := cmp.Diff([]string{"google_search", "load_artifacts"}, toolNames); diff != "" {
+		t.Errorf("writer tools mismatch (-want +got):\n%s", diff)
+	}
+	if writer.OutputKey != "draft" {
+		t.Errorf("writer output key = %q, want draft", writer.OutputKey)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			files:   map[string]string{"root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntemperature: 1\n"},
+			wantErr: "line 3: field temperature not found",
+		},
+		{
+			name:    "missing name",
+			files:   map[string]string{"root_agent.yaml": "model: gemini-2.5-flash\n"},
+			wantErr: "root_agent.yaml: name: is required",
+		},
+		{
+			name:    "missing model",
+			files:   map[string]string{"root_agent.yaml": "name: a\n"},
+			wantErr: "root_agent.yaml: model: is required",
+		},
+		{
+			name:    "unsupported agent class",
+			files:   map[string]string{"root_agent.yaml": "name: a\nagent_class: CustomAgent\n"},
+			wantErr: "agent_class: unsupported agent class",
+		},
+		{
+			name:    "model of a workflow agent",
+			files:   map[string]string{"root_agent.yaml": "name: a\nagent_class: LoopAgent\nmodel: gemini-2.5-flash\n"},
+			wantErr: "model: is only supported by LlmAgent",
+		},
+		{
+			name:    "max iterations of an LLM agent",
+			files:   map[string]string{"root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\nmax_iterations: 2\n"},
+			wantErr: "max_iterations: is only supported by LoopAgent",
+		},
+		{
+			name:    "unknown tool",
+			files:   map[string]string{"root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntools:\n  - name: google_search\n  - name: unknown\n"},
+			wantErr: `tools[1].name: unknown tool "unknown"`,
+		},
+		{
+			name:    "empty tool",
+			files:   map[string]string{"root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntools:\n  - {}\n"},
+			wantErr: "tools[0]: one of name and mcp is required",
+		},
+		{
+			name:    "MCP without server",
+			files:   map[string]string{"root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntools:\n  - mcp:\n      tool_filter: [x]\n"},
+			wantErr: "tools[0].mcp: one of command and url is required",
+		},
+		{
+			name:    "MCP unsupported transport",
+			files:   map[string]string{"root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntools:\n  - mcp:\n      url: http://localhost/mcp\n      transport: websocket\n"},
+			wantErr: "tools[0].mcp.transport: unsupported transport",
+		},
+		{
+			name:    "invalid inline sub-agent",
+			files:   map[string]string{"root_agent.yaml": "name: a\nagent_class: SequentialAgent\nsub_agents:\n  - agent:\n      name: b\n      model: gemini-2.5-flash\n  - agent:\n      name: c\n"},
+			wantErr: "sub_agents[1].agent.model: is required",
+		},
+		{
+			name: "invalid sub-agent file",
+			files: map[string]string{
+				"root_agent.yaml": "name: a\nagent_class: SequentialAgent\nsub_agents:\n  - config_path: sub/b.yaml\n",
+				"sub/b.yaml":      "name: b\nmodel: gemini-2.5-flash\ntools:\n  - name: unknown\n",
+			},
+			wantErr: filepath.Join("sub", "b.yaml") + `: tools[0].name: unknown tool "unknown"`,
+		},
+		{
+			name:    "missing sub-agent file",
+			files:   map[string]string{"root_agent.yaml": "name: a\nagent_class: SequentialAgent\nsub_agents:\n  - config_path: missing.yaml\n"},
+			wantErr: "sub_agents[0].config_path: failed to read agent config",
+		},
+		{
+			name:    "cyclic sub-agents",
+			files:   map[string]string{"root_agent.yaml": "name: a\nagent_class: LoopAgent\nsub_agents:\n  - config_path: root_agent.yaml\n"},
+			wantErr: "the config includes itself",
+		},
+		{
+			name:    "sub-agent with config path and agent",
+			files:   map[string]string{"root_agent.yaml": "name: a\nagent_class: LoopAgent\nsub_agents:\n  - config_path: b.yaml\n    agent:\n      name: b\n"},
+			wantErr: "sub_agents[0]: only one of config_path and agent can be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			_, err := agentconfig.Load(filepath.Join(dir, "root_agent.yaml"), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type weatherArgs struct {
+	City string `json:"city"`
+}
+
+func TestRegistry(t *testing.T) {
+	weather, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(tool.Context, weatherArgs) (string, error) { return "sunny", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := agentconfig.NewRegistry()
+	registry.RegisterTool(weather)
+	registry.RegisterToolset("weather_tools", tool.Toolset(nil))
+
+	cfg, err := agentconfig.Parse([]byte("name: a\nmodel: gemini-2.5-flash\ntools:\n  - name: get_weather\n  - name: weather_tools\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, err := agentconfig.Build(cfg, t.TempDir(), nil); err == nil {
+		t.Error("Build() with the default registry succeeded, want an unknown tool error")
+	}
+
+	a, err := agentconfig.Build(cfg, t.TempDir(), registry)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	state := llminternal.Reveal(a.(llminternal.Agent))
+	if len(state.Tools) != 1 || state.Tools[0] != weather || len(state.Toolsets) != 1 {
+		t.Errorf("tools = %v, toolsets = %v, want the registered tool and tool set", state.Tools, state.Toolsets)
+	}
+}
+
+func TestMCPToolset(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
+	for _, name := range []string{"get_weather", "get_forecast", "delete_city"} {
+		mcp.AddTool(server, &mcp.Tool{Name: name, Description: "returns weather in the given city"},
+			func(context.Context, *mcp.CallToolRequest, weatherArgs) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "sunny"}}}, nil, nil
+			})
+	}
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mcpHandler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		// The MCP session of the tool set keeps a connection open.
+		httpServer.CloseClientConnections()
+		httpServer.Close()
+	})
+	t.Setenv("WEATHER_TOKEN", "secret")
+
+	cfg, err := agentconfig.Parse([]byte(`
+name: a
+model: gemini-2.5-flash
+tools:
+  - mcp:
+      url: ` + httpServer.URL + `
+      headers:
+        Authorization: Bearer ${WEATHER_TOKEN}
+      tool_filter: [get_weather, get_forecast]
+      name_prefix: weather_
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	a, err := agentconfig.Build(cfg, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	toolsets := llminternal.Reveal(a.(llminternal.Agent)).Toolsets
+	if len(toolsets) != 1 {
+		t.Fatalf("toolsets = %v, want one MCP tool set", toolsets)
+	}
+	tools, err := toolsets[0].Tools(icontext.NewReadonlyContext(
+		icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}),
+	))
+	if err != nil {
+		t.Fatalf("Tools() error = %v", err)
+	}
+	var got []string
+	for _, tl := range tools {
+		got = append(got, tl.Name())
+	}
+	if diff := cmp.Diff([]string{"weather_get_forecast", "weather_get_weather"}, got); diff != "" {
+		t.Errorf("MCP tools mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMCPToolset_CommandRestart(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	cfg, err := agentconfig.Parse([]byte(`
+name: a
+model: gemini-2.5-flash
+tools:
+  - mcp:
+      command: ` + strconv.Quote(exe) + `
+      env:
+        ` + stdioServerEnv + `: serve
+        PID_FILE: ` + strconv.Quote(pidFile) + `
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	a, err := agentconfig.Build(cfg, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	ts := llminternal.Reveal(a.(llminternal.Agent)).Toolsets[0]
+	t.Cleanup(func() { _ = ts.(io.Closer).Close() })
+
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	tools, err := ts.Tools(icontext.NewReadonlyContext(ctx))
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("Tools() = %v, %v, want the get_weather tool", tools, err)
+	}
+	weather := tools[0].(toolinternal.FunctionTool)
+	pid := readPID(t, pidFile)
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tool set starts a new server process and retries the call.
+	got, err := weather.Run(toolinternal.NewToolContext(ctx, "", nil), map[string]any{"city": "london"})
+	if err != nil {
+		t.Fatalf("Run() after the server was killed error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"output": "sunny"}, got); diff != "" {
+		t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+	}
+	if got := readPID(t, pidFile); got == pid {
+		t.Errorf("server wasn't restarted, pid = %d", got)
+	}
+}
+
+func readPID(t *testing.T, name string) int {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
@@ -0,0 +1,313 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/mcptoolset"
+)
+
+// Load reads the agent config file and creates its agent, with its
+// sub-agents. The tools are resolved with the registry, or [NewRegistry] if
+// nil. The errors of invalid configs start with the path of the file and of
+// the invalid field, e.g. "root_agent.yaml: tools[1].name: unknown tool".
+func Load(path string, registry *Registry) (agent.Agent, error) {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	return (&builder{registry: registry}).load(path)
+}
+
+// Build creates the agent of the config, with its sub-agents. The config
+// paths of the sub-agents are relative to dir. The tools are resolved with
+// the registry, or [NewRegistry] if nil.
+func Build(cfg *Config, dir string, registry *Registry) (agent.Agent, error) {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	return (&builder{registry: registry}).build(cfg, dir)
+}
+
+// fieldError is an error of a field of a config.
+type fieldError struct {
+	path string // e.g. sub_agents[0].tools[1].name
+	err  error
+}
+
+func (e *fieldError) Error() string { return e.path + ": " + e.err.Error() }
+
+func (e *fieldError) Unwrap() error { return e.err }
+
+// at prefixes the path of the field errors with the path of their parent
+// field.
+func at(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if fe, ok := err.(*fieldError); ok {
+		return &fieldError{path: path + "." + fe.path, err: fe.err}
+	}
+	return &fieldError{path: path, err: err}
+}
+
+// builder creates the agents of configs.
+type builder struct {
+	registry *Registry
+	// files are the config files being loaded, to detect cycles.
+	files []string
+}
+
+func (b *builder) load(path string) (agent.Agent, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(b.files, path) {
+		return nil, fmt.Errorf("%s: the config includes itself", path)
+	}
+	b.files = append(b.files, path)
+	defer func() { b.files = b.files[:len(b.files)-1] }()
+
+	cfg, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a, err := b.build(cfg, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+func (b *builder) build(cfg *Config, dir string) (agent.Agent, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	subAgents := make([]agent.Agent, 0, len(cfg.SubAgents))
+	for i, sub := range cfg.SubAgents {
+		path := fmt.Sprintf("sub_agents[%d]", i)
+		var a agent.Agent
+		var err error
+		switch {
+		case sub.ConfigPath != "" && sub.Agent != nil:
+			err = fmt.Errorf("only one of config_path and agent can be set")
+		case sub.ConfigPath != "":
+			subPath := sub.ConfigPath
+			if !filepath.IsAbs(subPath) {
+				subPath = filepath.Join(dir, subPath)
+			}
+			a, err = b.load(subPath)
+			path += ".config_path"
+		case sub.Agent != nil:
+			a, err = b.build(sub.Agent, dir)
+			path += ".agent"
+		default:
+			err = fmt.Errorf("one of config_path and agent is required")
+		}
+		if err != nil {
+			return nil, at(path, err)
+		}
+		subAgents = append(subAgents, a)
+	}
+
+	base := agent.Config{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+		SubAgents:   subAgents,
+	}
+	var a agent.Agent
+	var err error
+	switch cfg.AgentClass {
+	case "", ClassLLMAgent:
+		var tools []tool.Tool
+		var toolsets []tool.Toolset
+		for i, tc := range cfg.Tools {
+			t, ts, err := b.tool(tc)
+			if err != nil {
+				return nil, at(fmt.Sprintf("tools[%d]", i), err)
+			}
+			if t != nil {
+				tools = append(tools, t)
+			} else {
+				toolsets = append(toolsets, ts)
+			}
+		}
+		a, err = llmagent.New(llmagent.Config{
+			Name:                     cfg.Name,
+			Description:              cfg.Description,
+			SubAgents:                subAgents,
+			ModelName:                cfg.Model,
+			Instruction:              cfg.Instruction,
+			GlobalInstruction:        cfg.GlobalInstruction,
+			OutputKey:                cfg.OutputKey,
+			DisallowTransferToParent: cfg.DisallowTransferToParent,
+			DisallowTransferToPeers:  cfg.DisallowTransferToPeers,
+			Tools:                    tools,
+			Toolsets:                 toolsets,
+		})
+	case ClassSequentialAgent:
+		a, err = sequentialagent.New(sequentialagent.Config{AgentConfig: base})
+	case ClassParallelAgent:
+		a, err = parallelagent.New(parallelagent.Config{AgentConfig: base, MaxConcurrency: cfg.MaxConcurrency})
+	case ClassLoopAgent:
+		a, err = loopagent.New(loopagent.Config{AgentConfig: base, MaxIterations: cfg.MaxIterations})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("agent %q: %w", cfg.Name, err)
+	}
+	return a, nil
+}
+
+// validate checks the fields of the config, without its sub-agents.
+func (c *Config) validate() error {
+	if c.Name == "" {
+		return at("name", errors.New("is required"))
+	}
+	llm := c.AgentClass == "" || c.AgentClass == ClassLLMAgent
+	switch {
+	case !llm && !slices.Contains([]string{ClassSequentialAgent, ClassParallelAgent, ClassLoopAgent}, c.AgentClass):
+		return at("agent_class", fmt.Errorf("unsupported agent class %q", c.AgentClass))
+	case llm && c.Model == "":
+		return at("model", errors.New("is required"))
+	case !llm && c.Model != "":
+		return at("model", fmt.Errorf("is only supported by %s", ClassLLMAgent))
+	case !llm && c.Instruction != "":
+		return at("instruction", fmt.Errorf("is only supported by %s", ClassLLMAgent))
+	case !llm && len(c.Tools) > 0:
+		return at("tools", fmt.Errorf("are only supported by %s", ClassLLMAgent))
+	case c.MaxIterations > 0 && c.AgentClass != ClassLoopAgent:
+		return at("max_iterations", fmt.Errorf("is only supported by %s", ClassLoopAgent))
+	case c.MaxConcurrency != 0 && c.AgentClass != ClassParallelAgent:
+		return at("max_concurrency", fmt.Errorf("is only supported by %s", ClassParallelAgent))
+	}
+	return nil
+}
+
+// tool returns the tool or the tool set of the config.
+func (b *builder) tool(tc ToolConfig) (tool.Tool, tool.Toolset, error) {
+	switch {
+	case tc.Name != "" && tc.MCP != nil:
+		return nil, nil, errors.New("only one of name and mcp can be set")
+	case tc.Name != "":
+		t, ts, ok := b.registry.lookup(tc.Name)
+		if !ok {
+			return nil, nil, at("name", fmt.Errorf("unknown tool %q", tc.Name))
+		}
+		return t, ts, nil
+	case tc.MCP != nil:
+		ts, err := newMCPToolset(tc.MCP)
+		if err != nil {
+			return nil, nil, at("mcp", err)
+		}
+		return nil, ts, nil
+	default:
+		return nil, nil, errors.New("one of name and mcp is required")
+	}
+}
+
+// newMCPToolset creates the MCP tool set of the config. The server is
+// connected to on the first use of the tool set.
+func newMCPToolset(c *MCPConfig) (tool.Toolset, error) {
+	var transport mcp.Transport
+	switch {
+	case c.Command != "" && c.URL != "":
+		return nil, errors.New("only one of command and url can be set")
+	case c.Command != "":
+		if c.Transport != "" || len(c.Headers) > 0 {
+			return nil, at("transport", errors.New("transport and headers are only supported with url"))
+		}
+		env := os.Environ()
+		for _, k := range slices.Sorted(maps.Keys(c.Env)) {
+			env = append(env, k+"="+c.Env[k])
+		}
+		transport = &commandTransport{command: c.Command, args: c.Args, env: env}
+	case c.URL != "":
+		if len(c.Args) > 0 || len(c.Env) > 0 {
+			return nil, at("args", errors.New("args and env are only supported with command"))
+		}
+		client := http.DefaultClient
+		if len(c.Headers) > 0 {
+			client = &http.Client{Transport: &headerTransport{headers: c.Headers, base: http.DefaultTransport}}
+		}
+		switch c.Transport {
+		case "", "streamable":
+			transport = &mcp.StreamableClientTransport{Endpoint: c.URL, HTTPClient: client}
+		case "sse":
+			transport = &mcp.SSEClientTransport{Endpoint: c.URL, HTTPClient: client}
+		default:
+			return nil, at("transport", fmt.Errorf("unsupported transport %q, use streamable or sse", c.Transport))
+		}
+	default:
+		return nil, errors.New("one of command and url is required")
+	}
+
+	var filter tool.Predicate
+	if len(c.ToolFilter) > 0 {
+		filter = tool.StringPredicate(c.ToolFilter)
+	}
+	return mcptoolset.New(mcptoolset.Config{
+		Transport:  transport,
+		ToolFilter: filter,
+		NamePrefix: c.NamePrefix,
+	})
+}
+
+// commandTransport starts a new server process on every connection, so that
+// the tool set can reconnect after the process exited: an exec.Cmd can only
+// be started once.
+type commandTransport struct {
+	command string
+	args    []string
+	env     []string
+}
+
+func (t *commandTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	cmd := exec.Command(t.command, t.args...)
+	cmd.Env = t.env
+	return (&mcp.CommandTransport{Command: cmd}).Connect(ctx)
+}
+
+// headerTransport sets the headers, with the environment variables
+// expanded, on the requests.
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentconfig creates agents from declarative YAML configs, so that
+// agents can be changed without compiling Go code.
+//
+// A config defines an LLM agent or a workflow agent, its tools and its
+// sub-agents:
+//
+//	name: research_agent
+//	model: gemini-2.5-flash
+//	description: Researches a topic.
+//	instruction: Answer the questions of the user with Google Search.
+//	tools:
+//	  - name: google_search
+//	  - mcp:
+//	      command: npx
+//	      args: ["-y", "@modelcontextprotocol/server-filesystem", "."]
+//	      tool_filter: [read_file, list_directory]
+//	sub_agents:
+//	  - config_path: summarizer.yaml
+//
+// The tools referenced by name are resolved with a [Registry], which
+// includes the built-in tools and the tools registered in code. The agents
+// are regular agents, e.g. for the agent loaders of the launchers.
+package agentconfig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Agent classes of the configs.
+const (
+	ClassLLMAgent        = "LlmAgent"
+	ClassSequentialAgent = "SequentialAgent"
+	ClassParallelAgent   = "ParallelAgent"
+	ClassLoopAgent       = "LoopAgent"
+)
+
+// Config is the declarative config of an agent.
+type Config struct {
+	// AgentClass is the kind of agent, one of the Class constants.
+	// Optional: ClassLLMAgent if empty.
+	AgentClass  string `yaml:"agent_class,omitempty"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+
+	// Model is the name of the model of an LLM agent, resolved with the
+	// model registry, e.g. "gemini-2.5-flash".
+	Model                    string `yaml:"model,omitempty"`
+	Instruction              string `yaml:"instruction,omitempty"`
+	GlobalInstruction        string `yaml:"global_instruction,omitempty"`
+	OutputKey                string `yaml:"output_key,omitempty"`
+	DisallowTransferToParent bool   `yaml:"disallow_transfer_to_parent,omitempty"`
+	DisallowTransferToPeers  bool   `yaml:"disallow_transfer_to_peers,omitempty"`
+	// Tools are the tools and tool sets of an LLM agent.
+	Tools []ToolConfig `yaml:"tools,omitempty"`
+
+	// MaxIterations bounds the iterations of a loop agent, 0 for no bound.
+	MaxIterations uint `yaml:"max_iterations,omitempty"`
+	// MaxConcurrency bounds the sub-agents of a parallel agent running at
+	// the same time, 0 for no bound.
+	MaxConcurrency int `yaml:"max_concurrency,omitempty"`
+
+	SubAgents []SubAgentConfig `yaml:"sub_agents,omitempty"`
+}
+
+// ToolConfig is a tool or tool set of an LLM agent. Exactly one of the
+// fields is set.
+type ToolConfig struct {
+	// Name is the name of a tool or tool set of the registry.
+	Name string `yaml:"name,omitempty"`
+	// MCP is an MCP tool set.
+	MCP *MCPConfig `yaml:"mcp,omitempty"`
+}
+
+// MCPConfig is the config of an MCP tool set, connecting to an MCP server
+// either by running its command, or by its URL.
+type MCPConfig struct {
+	// Command is the command of the server, communicating over its
+	// standard input and output.
+	Command string   `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+	// Env are additional environment variables of the command.
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// URL is the endpoint of a remote server.
+	URL string `yaml:"url,omitempty"`
+	// Transport is the transport of the remote server, "streamable" or
+	// "sse". Optional: "streamable" if empty.
+	Transport string `yaml:"transport,omitempty"`
+	// Headers are sent with the requests to the remote server. Environment
+	// variables are expanded in the values, e.g. "Bearer ${GITHUB_PAT}".
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// ToolFilter are the names of the tools of the server to expose.
+	// Optional: all the tools if empty.
+	ToolFilter []string `yaml:"tool_filter,omitempty"`
+	// NamePrefix is prepended to the names of the tools.
+	NamePrefix string `yaml:"name_prefix,omitempty"`
+}
+
+// SubAgentConfig is a sub-agent. Exactly one of the fields is set.
+type SubAgentConfig struct {
+	// ConfigPath is the path of the config file of the sub-agent, relative
+	// to the directory of the parent config file.
+	ConfigPath string `yaml:"config_path,omitempty"`
+	// Agent is the inline config of the sub-agent.
+	Agent *Config `yaml:"agent,omitempty"`
+}
+
+// Parse parses an agent config. Unknown fields are errors.
+func Parse(data []byte) (*Config, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ReadFile reads and parses an agent config file.
+func ReadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent config: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Marshal returns the YAML of the config.
+func (c *Config) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+import (
+	"sync"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/exitlooptool"
+	"google.golang.org/adk/tool/geminitool"
+	"google.golang.org/adk/tool/loadartifactstool"
+)
+
+// Registry resolves the names of tools and tool sets in the configs.
+type Registry struct {
+	mu       sync.RWMutex
+	tools    map[string]tool.Tool
+	toolsets map[string]tool.Toolset
+}
+
+// NewRegistry returns a registry of the built-in tools: google_search,
+// url_context, exit_loop and load_artifacts.
+func NewRegistry() *Registry {
+	r := &Registry{tools: map[string]tool.Tool{}, toolsets: map[string]tool.Toolset{}}
+	r.RegisterTool(geminitool.GoogleSearch{})
+	r.RegisterTool(geminitool.URLContext{})
+	r.RegisterTool(loadartifactstool.New())
+	if exitLoop, err := exitlooptool.New(); err == nil {
+		r.RegisterTool(exitLoop)
+	}
+	return r
+}
+
+// RegisterTool registers the tool by its name. Registering the same name
+// again replaces the tool.
+func (r *Registry) RegisterTool(t tool.Tool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[t.Name()] = t
+}
+
+// RegisterToolset registers the tool set by name. Registering the same name
+// again replaces the tool set. Tools take precedence over tool sets of the
+// same name.
+func (r *Registry) RegisterToolset(name string, ts tool.Toolset) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.toolsets[name] = ts
+}
+
+// lookup returns the tool or the tool set of the name.
+func (r *Registry) lookup(name string) (tool.Tool, tool.Toolset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if t, ok := r.tools[name]; ok {
+		return t, nil, true
+	}
+	if ts, ok := r.toolsets[name]; ok {
+		return nil, ts, true
+	}
+	return nil, nil, false
+}
@@ -19,6 +19,7 @@ import (
 
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
 
@@ -120,4 +121,6 @@ type CallbackContext interface {
 
 	Artifacts() Artifacts
 	State() session.State
+	// Usage returns the token usage aggregated over the current invocation.
+	Usage() *model.Usage
 }
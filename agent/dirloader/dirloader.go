@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dirloader provides an agent loader discovering several apps in a
+// directory.
+//
+// Each subdirectory of the directory is an app named after the
+// subdirectory. Its agent is created either by a [Factory] registered in
+// code for the app name, or from the declarative agent config in the
+// root_agent.yaml file of the subdirectory, see the agentconfig package:
+//
+//	agents/
+//	  weather/root_agent.yaml
+//	  travel/            (dirloader.Register("travel", travel.New))
+//
+// The loader is a regular [agent.Loader], e.g. for the AgentLoader of the
+// launcher configuration, and the apps it finds are listed by the list-apps
+// API.
+package dirloader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/agentconfig"
+)
+
+// ConfigFile is the name of the agent config file of an app directory.
+const ConfigFile = "root_agent.yaml"
+
+// Factory creates the agent of an app, given the directory of the app.
+type Factory func(dir string) (agent.Agent, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register registers the factory of the app. The factory takes precedence
+// over the agent config file of the app directory. Registering the same app
+// again replaces its factory.
+//
+// Register panics if the app name is empty or the factory is nil.
+func Register(app string, f Factory) {
+	if app == "" || f == nil {
+		panic("dirloader: Register requires an app name and a factory")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[app] = f
+}
+
+func lookup(app string) Factory {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[app]
+}
+
+// Option configures the loader.
+type Option func(*options)
+
+type options struct {
+	root     string
+	registry *agentconfig.Registry
+}
+
+// WithRoot sets the app of the root agent of the loader. Defaults to the
+// first app in alphabetical order.
+func WithRoot(app string) Option {
+	return func(o *options) {
+		o.root = app
+	}
+}
+
+// WithRegistry sets the registry resolving the tools of the agent configs.
+// Defaults to agentconfig.NewRegistry, i.e. the built-in tools.
+func WithRegistry(r *agentconfig.Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// New returns a loader of the apps in the subdirectories of dir. The agents
+// are created at once, so that invalid configs are reported at startup.
+// Subdirectories without a registered factory nor an agent config file are
+// skipped, as are hidden ones. New fails if no app is found.
+func New(dir string, opts ...Option) (agent.Loader, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.registry == nil {
+		o.registry = agentconfig.NewRegistry()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agents directory: %w", err)
+	}
+	apps := map[string]agent.Agent{}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		a, err := load(filepath.Join(dir, e.Name()), e.Name(), o.registry)
+		if errors.Is(err, errNoAgent) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load app %q: %w", e.Name(), err)
+		}
+		apps[e.Name()] = a
+	}
+	if len(apps) == 0 {
+		return nil, fmt.Errorf("no apps found in %q: the subdirectories require a registered factory or a %s file", dir, ConfigFile)
+	}
+
+	root := o.root
+	if root == "" {
+		root = slices.Sorted(maps.Keys(apps))[0]
+	}
+	return agent.NewMapLoader(root, apps)
+}
+
+// errNoAgent is returned by load when the directory defines no agent.
+var errNoAgent = errors.New("no agent")
+
+// load creates the agent of the app directory.
+func load(dir, app string, registry *agentconfig.Registry) (agent.Agent, error) {
+	if f := lookup(app); f != nil {
+		return f(dir)
+	}
+	path := filepath.Join(dir, ConfigFile)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, errNoAgent
+	}
+	return agentconfig.Load(path, registry)
+}
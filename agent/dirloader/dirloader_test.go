@@ -0,0 +1,268 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dirloader_test
+
+import (
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/agentconfig"
+	"google.golang.org/adk/agent/dirloader"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/server/adkrest"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// writeFiles writes the files, keyed by path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newAppsDir returns a directory with a YAML app, a workflow app with
+// sub-agents, an app registered in code, and directories which aren't apps.
+func newAppsDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"weather/root_agent.yaml": `
+name: weather_agent
+model: gemini-2.5-flash
+description: Answers questions about the weather.
+instruction: You are a helpful weather assistant.
+tools:
+  - name: google_search
+`,
+		"pipeline/root_agent.yaml": `
+agent_class: SequentialAgent
+name: pipeline
+sub_agents:
+  - config_path: steps/writer.yaml
+  - config_path: steps/reviewer.yaml
+`,
+		"pipeline/steps/writer.yaml": `
+name: writer
+model: gemini-2.5-flash
+output_key: draft
+`,
+		"pipeline/steps/reviewer.yaml": `
+name: reviewer
+model: gemini-2.5-flash
+instruction: "Review the draft: {draft}"
+`,
+		"coded/README.md":         "An app registered in code.",
+		"notes/README.md":         "Not an app.",
+		".hidden/root_agent.yaml": "name: hidden\nmodel: gemini-2.5-flash\n",
+	})
+
+	dirloader.Register("coded", func(dir string) (agent.Agent, error) {
+		if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+			return nil, err
+		}
+		return agent.New(agent.Config{
+			Name: "coded_agent",
+			Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(func(*session.Event, error) bool) {}
+			},
+		})
+	})
+	return dir
+}
+
+func TestNew(t *testing.T) {
+	loader, err := dirloader.New(newAppsDir(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got, want := loader.ListAgents(), []string{"coded", "pipeline", "weather"}; !slices.Equal(got, want) {
+		t.Errorf("ListAgents() = %v, want %v", got, want)
+	}
+	if got := loader.RootAgent().Name(); got != "coded_agent" {
+		t.Errorf("RootAgent() = %q, want the agent of the first app", got)
+	}
+
+	for app, wantAgent := range map[string]string{"coded": "coded_agent", "pipeline": "pipeline", "weather": "weather_agent"} {
+		a, err := loader.LoadAgent(app)
+		if err != nil {
+			t.Fatalf("LoadAgent(%q) error = %v", app, err)
+		}
+		if a.Name() != wantAgent {
+			t.Errorf("LoadAgent(%q) = %q, want %q", app, a.Name(), wantAgent)
+		}
+	}
+
+	pipeline, _ := loader.LoadAgent("pipeline")
+	var subAgents []string
+	for _, sub := range pipeline.SubAgents() {
+		subAgents = append(subAgents, sub.Name())
+	}
+	if want := []string{"writer", "reviewer"}; !slices.Equal(subAgents, want) {
+		t.Errorf("pipeline sub-agents = %v, want %v", subAgents, want)
+	}
+
+	for _, app := range []string{"unknown", "notes", ".hidden"} {
+		if _, err := loader.LoadAgent(app); !errors.Is(err, agent.ErrAgentNotFound) {
+			t.Errorf("LoadAgent(%q) error = %v, want ErrAgentNotFound", app, err)
+		}
+	}
+}
+
+func TestNew_WithRoot(t *testing.T) {
+	loader, err := dirloader.New(newAppsDir(t), dirloader.WithRoot("weather"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := loader.RootAgent().Name(); got != "weather_agent" {
+		t.Errorf("RootAgent() = %q, want weather_agent", got)
+	}
+
+	if _, err := dirloader.New(newAppsDir(t), dirloader.WithRoot("unknown")); err == nil {
+		t.Error("New() with an unknown root succeeded, want an error")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "no apps",
+			files: map[string]string{"notes/README.md": "Not an app."},
+		},
+		{
+			name:  "unknown field",
+			files: map[string]string{"app/root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntemperature: 1\n"},
+		},
+		{
+			name:  "missing name",
+			files: map[string]string{"app/root_agent.yaml": "model: gemini-2.5-flash\n"},
+		},
+		{
+			name:  "missing model",
+			files: map[string]string{"app/root_agent.yaml": "name: a\n"},
+		},
+		{
+			name:  "unknown tool",
+			files: map[string]string{"app/root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntools:\n  - name: unknown\n"},
+		},
+		{
+			name:  "unsupported agent class",
+			files: map[string]string{"app/root_agent.yaml": "name: a\nagent_class: CustomAgent\n"},
+		},
+		{
+			name:  "model of a workflow agent",
+			files: map[string]string{"app/root_agent.yaml": "name: a\nagent_class: LoopAgent\nmodel: gemini-2.5-flash\n"},
+		},
+		{
+			name:  "missing sub-agent",
+			files: map[string]string{"app/root_agent.yaml": "name: a\nagent_class: SequentialAgent\nsub_agents:\n  - config_path: missing.yaml\n"},
+		},
+		{
+			name:  "cyclic sub-agents",
+			files: map[string]string{"app/root_agent.yaml": "name: a\nagent_class: LoopAgent\nsub_agents:\n  - config_path: root_agent.yaml\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			if _, err := dirloader.New(dir); err == nil {
+				t.Error("New() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestNew_WithRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/root_agent.yaml": "name: a\nmodel: gemini-2.5-flash\ntools:\n  - name: get_weather\n",
+	})
+	if _, err := dirloader.New(dir); err == nil {
+		t.Fatal("New() without the tool succeeded, want an error")
+	}
+	weather, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(tool.Context, struct{}) (string, error) { return "sunny", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := agentconfig.NewRegistry()
+	registry.RegisterTool(weather)
+	if _, err := dirloader.New(dir, dirloader.WithRegistry(registry)); err != nil {
+		t.Errorf("New() with the tool error = %v", err)
+	}
+}
+
+func TestNew_RESTAPI(t *testing.T) {
+	loader, err := dirloader.New(newAppsDir(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "unknown", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+	handler := adkrest.NewHandler(&launcher.Config{SessionService: sessionService, AgentLoader: loader})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/list-apps", nil))
+	var apps []string
+	if err := json.NewDecoder(rr.Body).Decode(&apps); err != nil {
+		t.Fatalf("GET /list-apps: failed to decode response: %v", err)
+	}
+	if want := []string{"coded", "pipeline", "weather"}; !slices.Equal(apps, want) {
+		t.Errorf("GET /list-apps = %v, want %v", apps, want)
+	}
+
+	rr = httptest.NewRecorder()
+	body := `{"appName": "unknown", "userId": "user", "sessionId": "session", "newMessage": {"role": "user", "parts": [{"text": "hi"}]}}`
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body)))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("POST /run of an unknown app status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestNew_Example(t *testing.T) {
+	// The clock app of the example is registered in code, by a package this
+	// test doesn't import.
+	loader, err := dirloader.New(filepath.Join("..", "..", "examples", "agentsdir", "agents"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got, want := loader.ListAgents(), []string{"files", "story", "weather"}; !slices.Equal(got, want) {
+		t.Errorf("ListAgents() = %v, want %v", got, want)
+	}
+}
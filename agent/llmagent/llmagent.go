@@ -15,8 +15,11 @@
 package llmagent
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
+	"slices"
 	"strings"
 
 	"google.golang.org/genai"
@@ -25,13 +28,19 @@ import (
 	agentinternal "google.golang.org/adk/internal/agent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
+	_ "google.golang.org/adk/model/gemini" // registers Gemini models for ModelName
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 )
 
 // New is a constructor for LLMAgent.
 func New(cfg Config) (agent.Agent, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config for agent %q: %w", cfg.Name, err)
+	}
+
 	beforeModelCallbacks := make([]llminternal.BeforeModelCallback, 0, len(cfg.BeforeModelCallbacks))
 	for _, c := range cfg.BeforeModelCallbacks {
 		beforeModelCallbacks = append(beforeModelCallbacks, llminternal.BeforeModelCallback(c))
@@ -47,6 +56,11 @@ func New(cfg Config) (agent.Agent, error) {
 		beforeToolCallbacks = append(beforeToolCallbacks, llminternal.BeforeToolCallback(c))
 	}
 
+	llm := cfg.Model
+	if cfg.ModelName != "" {
+		llm = &lazyModel{name: cfg.ModelName}
+	}
+
 	afterToolCallbacks := make([]llminternal.AfterToolCallback, 0, len(cfg.AfterToolCallbacks))
 	for _, c := range cfg.AfterToolCallbacks {
 		afterToolCallbacks = append(afterToolCallbacks, llminternal.AfterToolCallback(c))
@@ -54,7 +68,6 @@ func New(cfg Config) (agent.Agent, error) {
 
 	a := &llmAgent{
 		beforeModelCallbacks: beforeModelCallbacks,
-		model:                cfg.Model,
 		afterModelCallbacks:  afterModelCallbacks,
 		beforeToolCallbacks:  beforeToolCallbacks,
 		afterToolCallbacks:   afterToolCallbacks,
@@ -63,14 +76,19 @@ func New(cfg Config) (agent.Agent, error) {
 		outputSchema:         cfg.OutputSchema,
 
 		State: llminternal.State{
-			Model:                    cfg.Model,
-			GenerateContentConfig:    cfg.GenerateContentConfig,
+			Model:                    llm,
+			GenerateContentConfig:    generateContentConfig(cfg),
+			SafetySettings:           cfg.SafetySettings,
 			Tools:                    cfg.Tools,
 			Toolsets:                 cfg.Toolsets,
+			ToolOverrides:            cfg.ToolOverrides,
+			ToolChoice:               cfg.ToolChoice.internal(),
+			AllowedFunctionNames:     cfg.AllowedFunctionNames,
 			DisallowTransferToParent: cfg.DisallowTransferToParent,
 			DisallowTransferToPeers:  cfg.DisallowTransferToPeers,
 			InputSchema:              cfg.InputSchema,
 			OutputSchema:             cfg.OutputSchema,
+			OutputJSON:               cfg.OutputJSON,
 			// TODO: internal type for includeContents
 			IncludeContents:           string(cfg.IncludeContents),
 			Instruction:               cfg.Instruction,
@@ -78,6 +96,8 @@ func New(cfg Config) (agent.Agent, error) {
 			GlobalInstruction:         cfg.GlobalInstruction,
 			GlobalInstructionProvider: llminternal.InstructionProvider(cfg.GlobalInstructionProvider),
 			OutputKey:                 cfg.OutputKey,
+			ContextCompression:        cfg.ContextCompression.internal(),
+			HistoryTrimming:           cfg.HistoryTrimming.internal(),
 		},
 	}
 
@@ -100,6 +120,122 @@ func New(cfg Config) (agent.Agent, error) {
 	return a, nil
 }
 
+// generateContentConfig returns the GenerateContentConfig of cfg completed
+// with its sampling fields, which don't override the values already set.
+func generateContentConfig(cfg Config) *genai.GenerateContentConfig {
+	if cfg.Temperature == nil && cfg.TopP == nil && cfg.TopK == nil && cfg.MaxOutputTokens == 0 && len(cfg.StopSequences) == 0 {
+		return cfg.GenerateContentConfig
+	}
+	var genCfg genai.GenerateContentConfig
+	if cfg.GenerateContentConfig != nil {
+		genCfg = *cfg.GenerateContentConfig
+	}
+	if genCfg.Temperature == nil {
+		genCfg.Temperature = cfg.Temperature
+	}
+	if genCfg.TopP == nil {
+		genCfg.TopP = cfg.TopP
+	}
+	if genCfg.TopK == nil {
+		genCfg.TopK = cfg.TopK
+	}
+	if genCfg.MaxOutputTokens == 0 {
+		genCfg.MaxOutputTokens = cfg.MaxOutputTokens
+	}
+	if len(genCfg.StopSequences) == 0 {
+		genCfg.StopSequences = slices.Clone(cfg.StopSequences)
+	}
+	return &genCfg
+}
+
+// validateConfig detects combinations of tools, sub-agents and schemas
+// which can't work together, so that they are reported at construction
+// rather than failing in the middle of a conversation.
+func validateConfig(cfg Config) error {
+	names := make(map[string]bool, len(cfg.Tools))
+	for _, t := range cfg.Tools {
+		if t == nil {
+			return fmt.Errorf("tool can't be nil")
+		}
+		if names[t.Name()] {
+			return fmt.Errorf("tool %q is defined more than once, tool names must be unique", t.Name())
+		}
+		names[t.Name()] = true
+	}
+
+	if cfg.OutputSchema != nil || cfg.OutputJSON {
+		field := "OutputSchema"
+		if cfg.OutputSchema == nil {
+			field = "OutputJSON"
+		}
+		// The model replies with JSON, so it can't request function calls.
+		if len(cfg.Tools) > 0 || len(cfg.Toolsets) > 0 {
+			return fmt.Errorf("tools can't be used together with %s, the agent can only reply with the structured output", field)
+		}
+		if len(cfg.SubAgents) > 0 {
+			return fmt.Errorf("sub-agents can't be used together with %s, the agent can't transfer to them", field)
+		}
+	}
+
+	if cfg.ModelName != "" {
+		if cfg.Model != nil {
+			return fmt.Errorf("only one of Model and ModelName can be set")
+		}
+		// Resolution is deferred until the first use, but unknown names are reported early.
+		if _, err := model.Lookup(cfg.ModelName); err != nil {
+			return err
+		}
+	}
+
+	switch cfg.IncludeContents {
+	case "", IncludeContentsDefault, IncludeContentsNone:
+	default:
+		return fmt.Errorf("unknown IncludeContents %q, must be %q or %q", cfg.IncludeContents, IncludeContentsDefault, IncludeContentsNone)
+	}
+
+	if len(cfg.SafetySettings) > 0 && cfg.GenerateContentConfig != nil && len(cfg.GenerateContentConfig.SafetySettings) > 0 {
+		return fmt.Errorf("only one of SafetySettings and GenerateContentConfig.SafetySettings can be set")
+	}
+
+	switch cfg.ToolChoice {
+	case "", ToolChoiceAuto:
+	case ToolChoiceAny:
+		if len(cfg.Tools) == 0 && len(cfg.Toolsets) == 0 && len(cfg.SubAgents) == 0 {
+			return fmt.Errorf("ToolChoice %q requires tools, the model has to call one of them", cfg.ToolChoice)
+		}
+	case ToolChoiceNone:
+		if len(cfg.AllowedFunctionNames) > 0 {
+			return fmt.Errorf("AllowedFunctionNames can't be used together with ToolChoice %q, the model can't call any function", cfg.ToolChoice)
+		}
+	default:
+		return fmt.Errorf("unknown ToolChoice %q, must be %q, %q or %q", cfg.ToolChoice, ToolChoiceAuto, ToolChoiceAny, ToolChoiceNone)
+	}
+	if len(cfg.AllowedFunctionNames) > 0 && cfg.ToolChoice != ToolChoiceAny {
+		return fmt.Errorf("AllowedFunctionNames requires ToolChoice %q", ToolChoiceAny)
+	}
+
+	if c := cfg.ContextCompression; c != nil {
+		if c.MaxTokens < 0 || c.MaxEvents < 0 || c.KeepRecentTurns < 0 {
+			return fmt.Errorf("ContextCompression thresholds can't be negative")
+		}
+		if c.MaxTokens == 0 && c.MaxEvents == 0 {
+			return fmt.Errorf("ContextCompression requires MaxTokens or MaxEvents to be set")
+		}
+		if c.Compactor != nil && c.Summarizer != nil {
+			return fmt.Errorf("only one of ContextCompression Compactor and Summarizer can be set")
+		}
+	}
+	if h := cfg.HistoryTrimming; h != nil {
+		if h.MaxTokens < 0 || h.MaxEvents < 0 {
+			return fmt.Errorf("HistoryTrimming limits can't be negative")
+		}
+		if h.MaxTokens == 0 && h.MaxEvents == 0 {
+			return fmt.Errorf("HistoryTrimming requires MaxTokens or MaxEvents to be set")
+		}
+	}
+	return nil
+}
+
 // Config of the LLMAgent.
 type Config struct {
 	// Name must be a non-empty string, unique within the agent tree.
@@ -139,6 +275,28 @@ type Config struct {
 	// For example: use this config to adjust model temperature, configure
 	// safety settings, etc.
 	GenerateContentConfig *genai.GenerateContentConfig
+	// SafetySettings adjust the safety filters of the model, e.g. the
+	// threshold of blocking dangerous content. When the model blocks the
+	// prompt or the response, the model call fails with an error matching
+	// model.ErrBlocked, which AfterModelCallbacks can handle.
+	//
+	// Only one of SafetySettings and GenerateContentConfig.SafetySettings
+	// can be set.
+	SafetySettings []*genai.SafetySetting
+
+	// Temperature, TopP, TopK, MaxOutputTokens and StopSequences are
+	// shortcuts for the sampling fields of GenerateContentConfig. The values
+	// set in GenerateContentConfig take precedence over them.
+	// optional
+	Temperature *float32
+	// optional
+	TopP *float32
+	// optional
+	TopK *float32
+	// optional, zero means the default of the model
+	MaxOutputTokens int32
+	// optional
+	StopSequences []string
 
 	// BeforeModelCallbacks will be called in the order they are provided until
 	// there's a callback that returns a non-nil LLMResponse or error. Then
@@ -150,6 +308,12 @@ type Config struct {
 	BeforeModelCallbacks []BeforeModelCallback
 	// Model that is used by the agent.
 	Model model.LLM
+	// ModelName is an alternative to Model, e.g. "gemini-2.5-flash". The name
+	// is resolved with model.Resolve at the first use of the model, so the
+	// model is configured from the environment at that point. The name must
+	// match a pattern registered with model.Register; Gemini models are
+	// registered by default. Only one of Model and ModelName can be set.
+	ModelName string
 	// AfterModelCallbacks will be called in the order they are provided until
 	// there's a callback that returns a non-nil LLMResponse or error. Then
 	// actual LLM response is replaced with the returned response/error.
@@ -174,7 +338,8 @@ type Config struct {
 	//
 	Instruction string
 	// InstructionProvider allows to create instructions dynamically based on
-	// the agent context.
+	// the agent context, e.g. to include live data such as the current time
+	// or a profile of the user fetched from a service.
 	//
 	// It takes over the Instruction field if both are set.
 	InstructionProvider InstructionProvider
@@ -212,16 +377,37 @@ type Config struct {
 	DisallowTransferToPeers bool
 
 	// Whether to include contents (conversation history) in the model request.
+	// Defaults to IncludeContentsDefault. IncludeContentsNone suits stateless
+	// agents, e.g. tool executors run via agenttool, which only need the
+	// current request and their instruction.
 	IncludeContents IncludeContents
+	// ContextCompression, if set, enables summarization of older conversation
+	// history when it grows over the configured thresholds.
+	ContextCompression *ContextCompressionConfig
+	// HistoryTrimming, if set, drops the oldest conversation history from
+	// the model requests when it grows over the configured limits.
+	HistoryTrimming *HistoryTrimmingConfig
 
 	// TODO(ngeorgy): consider to switch to jsonschema for input and output schema.
 	// The input schema when agent is used as a tool.
 	InputSchema *genai.Schema
 	// The output schema when agent replies.
+	// The model is asked to reply with JSON matching the schema. The final
+	// response is validated against it, failing the run if it doesn't match,
+	// and the parsed value is saved under OutputKey if set.
 	//
 	// NOTE: when this is set, agent can only reply and cannot use any tools,
 	// such as function tools, RAGs, agent transfer, etc.
 	OutputSchema *genai.Schema
+	// OutputJSON asks the model to reply with JSON, without a schema. It is
+	// implied by OutputSchema and has the same restrictions. The final
+	// response must parse as JSON, and the parsed value is saved under
+	// OutputKey if set.
+	//
+	// The models supporting JSON mode, see [model.JSONModeSupporter], are
+	// configured to reply with JSON, and with the OutputSchema if set. The
+	// other models are asked to do so in the system instruction.
+	OutputJSON bool
 
 	// Callbacks are executed in the order they are provided.
 	// The execution of the callback chain stops at the first callback that returns a non-nil
@@ -232,6 +418,9 @@ type Config struct {
 	//     error is propagated. Subsequent [BeforeToolCallback]s are skipped.
 	//   - If a callback returns (nil, nil), the execution continues to the next [BeforeToolCallback]
 	//     in the sequence.
+	//
+	// The function calls of a model response run concurrently, but the tool callbacks, including
+	// the plugin ones, are never called concurrently with each other.
 	BeforeToolCallbacks []BeforeToolCallback
 	// Tools available to the agent.
 	Tools []tool.Tool
@@ -244,10 +433,29 @@ type Config struct {
 	//     callback itself, and will be propagated. Subsequent [AfterToolCallback]s are skipped.
 	//   - If a callback returns (nil, nil), the execution continues to the next [AfterToolCallback]
 	//     in the sequence.
+	//
+	// As for [BeforeToolCallback]s, the callbacks are never called concurrently with each other.
 	AfterToolCallbacks []AfterToolCallback
 	// Toolsets will be used by llmagent to extract tools and pass to the
 	// underlying LLM.
 	Toolsets []tool.Toolset
+	// ToolOverrides maps tool names to descriptions which replace the
+	// descriptions declared by the tools.
+	//
+	// It allows reusing the same tool across agents with a description
+	// specific to each agent's context, e.g. a "search" tool described
+	// differently for a research agent and for a shopping agent.
+	// Applies to the tools from both Tools and Toolsets.
+	ToolOverrides map[string]string
+	// ToolChoice controls whether the model calls functions, i.e. tools and
+	// agent transfers. Defaults to ToolChoiceAuto.
+	//
+	// It overrides the FunctionCallingConfig of GenerateContentConfig.
+	ToolChoice ToolChoice
+	// AllowedFunctionNames limits the functions the model can call to the
+	// named ones. It requires ToolChoiceAny, e.g. a routing agent can be
+	// forced to call "transfer_to_agent".
+	AllowedFunctionNames []string
 
 	// OutputKey is an optional parameter to specify the key in session state for the agent output.
 	//
@@ -288,6 +496,104 @@ type BeforeToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]a
 //   - err:    The error returned by the tool's Run method.
 type AfterToolCallback func(ctx tool.Context, tool tool.Tool, args, result map[string]any, err error) (map[string]any, error)
 
+// ContextCompressionConfig configures summarization of older conversation history.
+//
+// Before calling the model, if the history exceeds MaxEvents or MaxTokens,
+// everything but the most recent KeepRecentTurns turns is replaced with a
+// summary generated by the Compactor. The summary is stored in the session
+// as an event with [session.EventCompaction] action, so it is computed only once
+// and reused by the following requests. A function call and its response are
+// never split between the summary and the recent turns.
+//
+// It has no effect if IncludeContents is IncludeContentsNone.
+type ContextCompressionConfig struct {
+	// Compactor generates the summary. Defaults to a compactor asking the
+	// Summarizer to summarize the history, see [NewModelCompactor].
+	Compactor HistoryCompactor
+	// Summarizer is the model generating the summary. Defaults to the agent's
+	// model. It can't be set together with Compactor.
+	Summarizer model.LLM
+	// MaxTokens triggers the compression when the estimated number of tokens
+	// of the history exceeds it. Tokens are estimated as 4 characters per token.
+	// Zero disables the threshold.
+	MaxTokens int
+	// MaxEvents triggers the compression when the number of history contents
+	// exceeds it. Zero disables the threshold.
+	MaxEvents int
+	// KeepRecentTurns is the number of the most recent turns, each starting
+	// with a user message, which are kept verbatim. Defaults to 2.
+	KeepRecentTurns int
+}
+
+func (c *ContextCompressionConfig) internal() *llminternal.ContextCompression {
+	if c == nil {
+		return nil
+	}
+	return &llminternal.ContextCompression{
+		Compactor:       c.Compactor,
+		Summarizer:      c.Summarizer,
+		MaxTokens:       c.MaxTokens,
+		MaxEvents:       c.MaxEvents,
+		KeepRecentTurns: c.KeepRecentTurns,
+	}
+}
+
+// HistoryCompactor summarizes the older conversation history for
+// [ContextCompressionConfig]. The summary replaces the summarized contents in
+// the following model requests.
+type HistoryCompactor interface {
+	// Summarize returns the summary of the contents. The context is the
+	// invocation context of the agent.
+	Summarize(ctx context.Context, contents []*genai.Content) (string, error)
+}
+
+// NewModelCompactor returns the default [HistoryCompactor], which asks m to
+// summarize the history. The calls to m count towards the limits and the
+// usage of the invocation, e.g. [agent.RunConfig].MaxLLMCalls.
+func NewModelCompactor(m model.LLM) HistoryCompactor {
+	return &llminternal.ModelCompactor{Model: m}
+}
+
+// HistoryTrimmingConfig limits the conversation history sent to the model,
+// e.g. to keep long sessions within its context window.
+//
+// Before calling the model, the oldest history is dropped from the request
+// until it is within MaxEvents and MaxTokens. The session itself isn't
+// changed. The latest user message is always kept, even if it alone exceeds
+// the limits, and a function call and its response are dropped together.
+// The system instruction isn't part of the history and isn't counted.
+//
+// It has no effect if IncludeContents is IncludeContentsNone. Combined with
+// ContextCompression, it trims the history which follows the summary.
+type HistoryTrimmingConfig struct {
+	// MaxTokens is the maximum number of tokens of the history. Zero
+	// disables the limit.
+	MaxTokens int
+	// MaxEvents is the maximum number of history contents. Zero disables
+	// the limit.
+	MaxEvents int
+	// TokenCounter counts the tokens for MaxTokens, e.g. the Gemini model of
+	// the agent. It is called again after each trimming step. If nil, tokens
+	// are estimated as 4 characters per token.
+	TokenCounter model.TokenCounter
+	// KeepWholeTurns drops whole turns, each starting with a user message,
+	// rather than single events, so that the kept history never starts in
+	// the middle of a turn.
+	KeepWholeTurns bool
+}
+
+func (c *HistoryTrimmingConfig) internal() *llminternal.HistoryTrimming {
+	if c == nil {
+		return nil
+	}
+	return &llminternal.HistoryTrimming{
+		MaxTokens:      c.MaxTokens,
+		MaxEvents:      c.MaxEvents,
+		TokenCounter:   c.TokenCounter,
+		KeepWholeTurns: c.KeepWholeTurns,
+	}
+}
+
 // IncludeContents controls what parts of prior conversation history is received by llmagent.
 type IncludeContents string
 
@@ -298,13 +604,41 @@ const (
 	IncludeContentsDefault IncludeContents = "default"
 )
 
+// ToolChoice controls whether the model calls functions.
+type ToolChoice string
+
+const (
+	// ToolChoiceAuto lets the model decide whether to call a function or to
+	// reply with text.
+	ToolChoiceAuto ToolChoice = "auto"
+	// ToolChoiceAny forces the model to call a function.
+	//
+	// The model calls a function in each request of the agent, so the agent
+	// keeps calling functions until one of them transfers to another agent or
+	// sets SkipSummarization.
+	ToolChoiceAny ToolChoice = "any"
+	// ToolChoiceNone forbids the model to call functions.
+	ToolChoiceNone ToolChoice = "none"
+)
+
+func (c ToolChoice) internal() genai.FunctionCallingConfigMode {
+	switch c {
+	case ToolChoiceAuto:
+		return genai.FunctionCallingConfigModeAuto
+	case ToolChoiceAny:
+		return genai.FunctionCallingConfigModeAny
+	case ToolChoiceNone:
+		return genai.FunctionCallingConfigModeNone
+	}
+	return ""
+}
+
 type llmAgent struct {
 	agent.Agent
 	llminternal.State
 	agentState
 
 	beforeModelCallbacks []llminternal.BeforeModelCallback
-	model                model.LLM
 	afterModelCallbacks  []llminternal.AfterModelCallback
 	instruction          string
 
@@ -320,17 +654,18 @@ type agentState = agentinternal.State
 func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	// TODO: branch context?
 	ctx = icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
-		Artifacts:   ctx.Artifacts(),
-		Memory:      ctx.Memory(),
-		Session:     ctx.Session(),
-		Branch:      ctx.Branch(),
-		Agent:       a,
-		UserContent: ctx.UserContent(),
-		RunConfig:   ctx.RunConfig(),
+		Artifacts:    ctx.Artifacts(),
+		Memory:       ctx.Memory(),
+		Session:      ctx.Session(),
+		Branch:       ctx.Branch(),
+		Agent:        a,
+		UserContent:  ctx.UserContent(),
+		RunConfig:    ctx.RunConfig(),
+		InvocationID: ctx.InvocationID(),
 	})
 
 	f := &llminternal.Flow{
-		Model:                a.model,
+		Model:                a.State.Model,
 		RequestProcessors:    llminternal.DefaultRequestProcessors,
 		ResponseProcessors:   llminternal.DefaultResponseProcessors,
 		BeforeModelCallbacks: a.beforeModelCallbacks,
@@ -341,7 +676,14 @@ func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, er
 
 	return func(yield func(*session.Event, error) bool) {
 		for ev, err := range f.Run(ctx) {
-			a.maybeSaveOutputToState(ev)
+			if err == nil {
+				if err := a.maybeSaveOutputToState(ev); err != nil {
+					if yield(ev, nil) {
+						yield(nil, err)
+					}
+					return
+				}
+			}
 			if !yield(ev, err) {
 				return
 			}
@@ -351,43 +693,85 @@ func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, er
 
 // maybeSaveOutputToState saves the model output to state if needed. skip if the event
 // was authored by some other agent (e.g. current agent transferred to another agent)
-func (a *llmAgent) maybeSaveOutputToState(event *session.Event) {
+//
+// If the agent has an OutputSchema or OutputJSON, the final response is
+// validated against it and the parsed value is saved instead of the raw text.
+// An error is returned if the response does not match the schema.
+func (a *llmAgent) maybeSaveOutputToState(event *session.Event) error {
 	if event == nil {
-		return
+		return nil
 	}
 	if event.Author != a.Name() {
 		// TODO: log "Skipping output save for agent %s: event authored by %s"
-		return
+		return nil
 	}
-	if a.OutputKey != "" && !event.Partial && event.Content != nil && len(event.Content.Parts) > 0 {
-		var sb strings.Builder
-		for _, part := range event.Content.Parts {
-			if part.Text != "" && !part.Thought {
-				sb.WriteString(part.Text)
-			}
+	if event.Partial || event.Content == nil || len(event.Content.Parts) == 0 {
+		return nil
+	}
+	jsonOutput := a.OutputSchema != nil || a.OutputJSON
+	if a.OutputKey == "" && !jsonOutput {
+		return nil
+	}
+	var sb strings.Builder
+	for _, part := range event.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
 		}
-		result := sb.String()
-
-		// TODO: add output schema validation and unmarshalling
-		if a.OutputSchema != nil {
-			// If the result from the final chunk is just whitespace or empty,
-			// it means this is an empty final chunk of a stream.
-			// Do not attempt to parse it as JSON.
-			if strings.TrimSpace(result) == "" {
-				return
+	}
+	result := sb.String()
+
+	var output any = result
+	if jsonOutput {
+		// If the result from the final chunk is just whitespace or empty,
+		// it means this is an empty final chunk of a stream.
+		// Do not attempt to parse it as JSON.
+		if strings.TrimSpace(result) == "" || !event.IsFinalResponse() {
+			return nil
+		}
+		parsed, err := parseOutput(result, a.OutputSchema)
+		if err != nil {
+			if a.OutputSchema == nil {
+				return fmt.Errorf("agent %q response is not JSON: %w", a.Name(), err)
 			}
+			return fmt.Errorf("agent %q response does not match OutputSchema: %w", a.Name(), err)
 		}
+		output = parsed
+	}
 
-		if event.Actions.StateDelta == nil {
-			event.Actions.StateDelta = make(map[string]any)
-		}
+	if a.OutputKey == "" {
+		return nil
+	}
+	if event.Actions.StateDelta == nil {
+		event.Actions.StateDelta = make(map[string]any)
+	}
+	event.Actions.StateDelta[a.OutputKey] = output
+	return nil
+}
 
-		event.Actions.StateDelta[a.OutputKey] = result
+// parseOutput parses the JSON output of the model. Objects are validated
+// against the schema, if any. Models asked for JSON in the instruction often
+// wrap it in a markdown code block, which is removed.
+func parseOutput(output string, schema *genai.Schema) (any, error) {
+	output = strings.TrimSpace(output)
+	if trimmed, ok := strings.CutPrefix(output, "```"); ok {
+		if body, ok := strings.CutSuffix(trimmed, "```"); ok {
+			output = strings.TrimPrefix(body, "json")
+		}
+	}
+	if schema != nil && schema.Type == genai.TypeObject {
+		return utils.ValidateOutputSchema(output, schema)
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse output JSON: %w", err)
 	}
+	return parsed, nil
 }
 
 // InstructionProvider allows to create instructions dynamically. It is called
-// on each agent invocation.
+// before each model request of the agent, so the instruction reflects the
+// current state of the session. If it returns an error, the request isn't
+// sent and the agent run fails with the error.
 //
 // NOTE: when InstructionProvider is used, ADK will NOT inject session state
 // placeholders into the instruction. You can use
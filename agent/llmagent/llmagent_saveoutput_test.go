@@ -50,12 +50,22 @@ func createTestEvent(author, contentText string, isFinal bool) *session.Event {
 }
 
 func TestLlmAgent_MaybeSaveOutputToState(t *testing.T) {
+	outputSchema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"message":    {Type: genai.TypeString},
+			"confidence": {Type: genai.TypeNumber},
+		},
+		Required: []string{"message"},
+	}
+
 	// Define the structure for our test cases
 	testCases := []struct {
 		name             string
 		agentConfig      Config
 		event            *session.Event
 		wantStateDelta   map[string]any
+		wantErr          bool
 		customEventParts []*genai.Part // For multi-part test
 	}{
 		{
@@ -105,7 +115,32 @@ func TestLlmAgent_MaybeSaveOutputToState(t *testing.T) {
 			event:          createTestEvent("testagent", "Test response", true),
 			wantStateDelta: map[string]any{},
 		},
-		// TODO tests with OutputSchema
+		{
+			name:           "saves parsed output matching output schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: outputSchema},
+			event:          createTestEvent("test_agent", `{"message": "hello", "confidence": 0.9}`, true),
+			wantStateDelta: map[string]any{"result": map[string]any{"message": "hello", "confidence": 0.9}},
+		},
+		{
+			name:           "skips empty final chunk with output schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: outputSchema},
+			event:          createTestEvent("test_agent", "  ", true),
+			wantStateDelta: map[string]any{},
+		},
+		{
+			name:           "fails on output not matching output schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: outputSchema},
+			event:          createTestEvent("test_agent", `{"confidence": 0.9}`, true),
+			wantStateDelta: map[string]any{},
+			wantErr:        true,
+		},
+		{
+			name:           "fails on invalid JSON without output key",
+			agentConfig:    Config{Name: "test_agent", OutputSchema: outputSchema},
+			event:          createTestEvent("test_agent", "not JSON", true),
+			wantStateDelta: map[string]any{},
+			wantErr:        true,
+		},
 	}
 
 	// Iterate over the test cases
@@ -126,7 +161,10 @@ func TestLlmAgent_MaybeSaveOutputToState(t *testing.T) {
 			if !ok {
 				t.Fatalf("failed to convert to llmagent")
 			}
-			createdLlmAgent.maybeSaveOutputToState(tc.event)
+			err = createdLlmAgent.maybeSaveOutputToState(tc.event)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("maybeSaveOutputToState() error = %v, wantErr %v", err, tc.wantErr)
+			}
 
 			// --- Assertion ---
 			gotStateDelta := tc.event.Actions.StateDelta
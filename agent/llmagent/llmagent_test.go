@@ -15,25 +15,35 @@
 package llmagent_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"iter"
+	"log/slog"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
 	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
 	"google.golang.org/adk/tool/functiontool"
 )
 
@@ -665,7 +675,7 @@ func TestInstructionProvider(t *testing.T) {
 			}
 
 			testRunner := testutil.NewTestAgentRunner(t, agent)
-			testRunner.SetInitSessionState(map[string]any{"var": "custom_value"})
+			testRunner.SetInitSessionState(t, map[string]any{"var": "custom_value"})
 
 			stream := testRunner.Run(t, "session", "user input")
 
@@ -685,6 +695,90 @@ func TestInstructionProvider(t *testing.T) {
 	}
 }
 
+func TestInstructionProvider_PerRequest(t *testing.T) {
+	clockTool, err := functiontool.New(functiontool.Config{Name: "tick", Description: "advances the clock"}, func(ctx tool.Context, _ struct{}) (string, error) {
+		return "ok", ctx.State().Set("clock", "10:01")
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	mockModel := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("tick", nil, genai.RoleModel),
+		genai.NewContentFromText("done", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "clock_agent",
+		Model: mockModel,
+		Tools: []tool.Tool{clockTool},
+		InstructionProvider: func(ctx agent.ReadonlyContext) (string, error) {
+			clock, err := ctx.ReadonlyState().Get("clock")
+			if err != nil {
+				clock = "10:00"
+			}
+			return fmt.Sprintf("The time is %v.", clock), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "what time is it?")); err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+	if len(mockModel.Requests) != 2 {
+		t.Fatalf("model got %d requests, want 2", len(mockModel.Requests))
+	}
+	for i, want := range []string{"The time is 10:00.", "The time is 10:01."} {
+		si := mockModel.Requests[i].Config.SystemInstruction
+		if si == nil || !strings.Contains(si.Parts[0].Text, want) {
+			t.Errorf("request %d system instruction = %v, want it to contain %q", i, si, want)
+		}
+	}
+}
+
+func TestInstructionProvider_Error(t *testing.T) {
+	errProfile := errors.New("profile service unavailable")
+	for _, tc := range []struct {
+		name    string
+		cfg     llmagent.Config
+		wantErr string
+	}{
+		{
+			name: "instruction",
+			cfg: llmagent.Config{
+				InstructionProvider: func(agent.ReadonlyContext) (string, error) { return "", errProfile },
+			},
+			wantErr: `failed to append instructions of agent "profile_agent": failed to evaluate instruction provider: profile service unavailable`,
+		},
+		{
+			name: "global instruction",
+			cfg: llmagent.Config{
+				GlobalInstructionProvider: func(agent.ReadonlyContext) (string, error) { return "", errProfile },
+			},
+			wantErr: "failed to append global instructions: failed to evaluate global instruction provider: profile service unavailable",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("hi", genai.RoleModel)}}
+			cfg := tc.cfg
+			cfg.Name = "profile_agent"
+			cfg.Model = mockModel
+			a, err := llmagent.New(cfg)
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			_, err = testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "hello"))
+			if !errors.Is(err, errProfile) || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("agent run error = %v, want it to contain %q", err, tc.wantErr)
+			}
+			if len(mockModel.Requests) != 0 {
+				t.Errorf("model got %d requests, want none", len(mockModel.Requests))
+			}
+		})
+	}
+}
+
 func TestFunctionTool(t *testing.T) {
 	model := newGeminiModel(t, modelName, nil)
 
@@ -734,6 +828,165 @@ func TestFunctionTool(t *testing.T) {
 	}
 }
 
+func TestToolLoggingCallbacks(t *testing.T) {
+	type Args struct {
+		City string `json:"city"`
+	}
+	weather, err := functiontool.New(functiontool.Config{Name: "weather", Description: "returns the weather"}, func(_ tool.Context, args Args) (map[string]any, error) {
+		return map[string]any{"forecast": "sunny"}, nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	broken, err := functiontool.New(functiontool.Config{Name: "broken", Description: "always fails"}, func(_ tool.Context, args Args) (map[string]any, error) {
+		return nil, errors.New("service unavailable")
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			{
+				Role: genai.RoleModel,
+				Parts: []*genai.Part{
+					{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "weather", Args: map[string]any{"city": "Paris"}}},
+					{FunctionCall: &genai.FunctionCall{ID: "call_2", Name: "broken", Args: map[string]any{"city": "Paris"}}},
+				},
+			},
+			genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel),
+		},
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	before, after := llmagent.ToolLoggingCallbacks(logger)
+	a, err := llmagent.New(llmagent.Config{
+		Name:                "agent",
+		Model:               mockModel,
+		Tools:               []tool.Tool{weather, broken},
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{before},
+		AfterToolCallbacks:  []llmagent.AfterToolCallback{after},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "what is the weather in Paris?")); err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+
+	records := map[string]map[string]any{} // by message and function call id
+	for line := range strings.Lines(logs.String()) {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		if rec["level"] != "DEBUG" {
+			t.Errorf("record %v, want debug level", rec)
+		}
+		records[fmt.Sprintf("%s %s", rec["msg"], rec["function_call_id"])] = rec
+	}
+	if len(records) != 4 {
+		t.Fatalf("got log records %v, want a call and a result record for each tool", records)
+	}
+	if rec := records["Tool call call_1"]; rec["tool"] != "weather" || rec["args"] != `{"city":"Paris"}` {
+		t.Errorf("tool call record = %v", rec)
+	}
+	if rec := records["Tool result call_1"]; rec["result"] != `{"forecast":"sunny"}` || rec["latency"] == nil {
+		t.Errorf("tool result record = %v", rec)
+	}
+	if rec := records["Tool result call_2"]; rec["tool"] != "broken" || rec["error"] != "service unavailable" {
+		t.Errorf("failed tool result record = %v", rec)
+	}
+}
+
+func TestGlobalInstruction(t *testing.T) {
+	systemInstruction := func(t *testing.T, m *testutil.MockModel) string {
+		t.Helper()
+		if len(m.Requests) != 1 {
+			t.Fatalf("model got %d requests, want 1", len(m.Requests))
+		}
+		si := m.Requests[0].Config.SystemInstruction
+		if si == nil {
+			return ""
+		}
+		var texts []string
+		for _, p := range si.Parts {
+			texts = append(texts, p.Text)
+		}
+		return strings.Join(texts, "\n")
+	}
+
+	t.Run("LLM root agent", func(t *testing.T) {
+		subModel := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("hello from sub", genai.RoleModel)}}
+		subAgent, err := llmagent.New(llmagent.Config{
+			Name:              "sub_agent",
+			Model:             subModel,
+			Instruction:       "Sub instruction.",
+			GlobalInstruction: "Sub global instruction.",
+		})
+		if err != nil {
+			t.Fatalf("llmagent.New() error = %v", err)
+		}
+		rootModel := &testutil.MockModel{Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("transfer_to_agent", map[string]any{"agent_name": "sub_agent"}, genai.RoleModel),
+		}}
+		rootAgent, err := llmagent.New(llmagent.Config{
+			Name:              "root_agent",
+			Model:             rootModel,
+			Instruction:       "Root instruction.",
+			GlobalInstruction: "Root global instruction.",
+			SubAgents:         []agent.Agent{subAgent},
+		})
+		if err != nil {
+			t.Fatalf("llmagent.New() error = %v", err)
+		}
+
+		if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, rootAgent).Run(t, "session", "hi")); err != nil {
+			t.Fatalf("agent run failed: %v", err)
+		}
+		rootSI := systemInstruction(t, rootModel)
+		if !strings.Contains(rootSI, "Root global instruction.") || !strings.Contains(rootSI, "Root instruction.") {
+			t.Errorf("root agent system instruction = %q, want the global and root instructions", rootSI)
+		}
+		subSI := systemInstruction(t, subModel)
+		if !strings.Contains(subSI, "Root global instruction.") || !strings.Contains(subSI, "Sub instruction.") {
+			t.Errorf("sub-agent system instruction = %q, want the root global instruction and the sub instruction", subSI)
+		}
+		if strings.Contains(subSI, "Sub global instruction.") || strings.Contains(subSI, "Root instruction.") {
+			t.Errorf("sub-agent system instruction = %q, want neither its own global instruction nor the root instruction", subSI)
+		}
+	})
+
+	t.Run("workflow root agent", func(t *testing.T) {
+		subModel := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("hello", genai.RoleModel)}}
+		subAgent, err := llmagent.New(llmagent.Config{
+			Name:              "sub_agent",
+			Model:             subModel,
+			Instruction:       "Sub instruction.",
+			GlobalInstruction: "Sub global instruction.",
+		})
+		if err != nil {
+			t.Fatalf("llmagent.New() error = %v", err)
+		}
+		rootAgent, err := sequentialagent.New(sequentialagent.Config{
+			AgentConfig: agent.Config{Name: "pipeline", SubAgents: []agent.Agent{subAgent}},
+		})
+		if err != nil {
+			t.Fatalf("sequentialagent.New() error = %v", err)
+		}
+
+		if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, rootAgent).Run(t, "session", "hi")); err != nil {
+			t.Fatalf("agent run failed: %v", err)
+		}
+		subSI := systemInstruction(t, subModel)
+		if !strings.Contains(subSI, "Sub instruction.") || strings.Contains(subSI, "Sub global instruction.") {
+			t.Errorf("sub-agent system instruction = %q, want only its instruction", subSI)
+		}
+	})
+}
+
 func TestAgentTransfer(t *testing.T) {
 	// Helpers to create genai.Content conveniently.
 	transferCall := func(agentName string) *genai.Content {
@@ -968,3 +1221,1430 @@ type roundTripperFunc func(*http.Request) (*http.Response, error)
 func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return fn(req)
 }
+
+func TestToolOverrides(t *testing.T) {
+	type Args struct {
+		Query string `json:"query"`
+	}
+	search, err := functiontool.New(functiontool.Config{
+		Name:        "search",
+		Description: "searches the web",
+	}, func(_ tool.Context, args Args) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		name            string
+		overrides       map[string]string
+		wantDescription string
+	}{
+		{
+			name:            "no overrides",
+			wantDescription: "searches the web",
+		},
+		{
+			name:            "research agent",
+			overrides:       map[string]string{"search": "searches scientific papers"},
+			wantDescription: "searches scientific papers",
+		},
+		{
+			name:            "shopping agent",
+			overrides:       map[string]string{"search": "searches the product catalog"},
+			wantDescription: "searches the product catalog",
+		},
+		{
+			name:            "override of unknown tool",
+			overrides:       map[string]string{"other": "other tool"},
+			wantDescription: "searches the web",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{genai.NewContentFromText("done", genai.RoleModel)},
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:          "agent",
+				Model:         mockModel,
+				Tools:         []tool.Tool{search},
+				ToolOverrides: tc.overrides,
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			runner := testutil.NewTestAgentRunner(t, a)
+			if _, err := testutil.CollectEvents(runner.Run(t, "session", "find something")); err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+
+			if len(mockModel.Requests) != 1 {
+				t.Fatalf("got %d model requests, want 1", len(mockModel.Requests))
+			}
+			var got []string
+			for _, genaiTool := range mockModel.Requests[0].Config.Tools {
+				for _, decl := range genaiTool.FunctionDeclarations {
+					if decl.Name == "search" {
+						got = append(got, decl.Description)
+					}
+				}
+			}
+			if diff := cmp.Diff([]string{tc.wantDescription}, got); diff != "" {
+				t.Errorf("unexpected search tool description (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// The tool itself must not be changed by the overrides.
+	if got := search.Description(); got != "searches the web" {
+		t.Errorf("search.Description() = %q, want unchanged description", got)
+	}
+}
+
+func TestRunConfigValues(t *testing.T) {
+	type tenantKey struct{}
+
+	type Args struct {
+		Table string `json:"table"`
+	}
+	var toolTenant any
+	query, err := functiontool.New(functiontool.Config{
+		Name:        "query",
+		Description: "queries the database",
+	}, func(ctx tool.Context, args Args) (string, error) {
+		toolTenant = ctx.Value(tenantKey{})
+		return "42 rows", nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+
+	var callbackTenant any
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("query", map[string]any{"table": "orders"}, genai.RoleModel),
+			genai.NewContentFromText("there are 42 orders", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: mockModel,
+		Tools: []tool.Tool{query},
+		BeforeModelCallbacks: []llmagent.BeforeModelCallback{
+			func(ctx agent.CallbackContext, llmRequest *model.LLMRequest) (*model.LLMResponse, error) {
+				callbackTenant = ctx.Value(tenantKey{})
+				return nil, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	cfg := agent.RunConfig{Values: map[any]any{tenantKey{}: "tenant-1"}}
+	if _, err := testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("count orders", genai.RoleUser), cfg)); err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+
+	if toolTenant != "tenant-1" {
+		t.Errorf("tool read tenant %v, want %q", toolTenant, "tenant-1")
+	}
+	if callbackTenant != "tenant-1" {
+		t.Errorf("callback read tenant %v, want %q", callbackTenant, "tenant-1")
+	}
+}
+
+func TestMaxLLMCalls(t *testing.T) {
+	loop, err := functiontool.New(functiontool.Config{
+		Name:        "loop",
+		Description: "asks to be called again",
+	}, func(tool.Context, struct{}) (string, error) {
+		return "call me again", nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	loopingModel := &loopingModel{toolName: "loop"}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: loopingModel,
+		Tools: []tool.Tool{loop},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	cfg := agent.RunConfig{MaxLLMCalls: 3}
+	_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("loop", genai.RoleUser), cfg))
+	if !errors.Is(err, model.ErrLLMCallsLimitExceeded) {
+		t.Fatalf("agent run error = %v, want %v", err, model.ErrLLMCallsLimitExceeded)
+	}
+	if loopingModel.calls != 3 {
+		t.Errorf("model was called %d times, want 3", loopingModel.calls)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	calls := 0
+	loop, err := functiontool.New(functiontool.Config{
+		Name:        "loop",
+		Description: "asks to be called again",
+	}, func(tool.Context, struct{}) (string, error) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return "call me again", nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	loopingModel := &loopingModel{toolName: "loop"}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: loopingModel,
+		Tools: []tool.Tool{loop},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "test_app", Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "test_app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+
+	var lastErr error
+	for _, err := range r.Run(ctx, "user", "session", genai.NewContentFromText("loop", genai.RoleUser), agent.RunConfig{}) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Fatalf("agent run error = %v, want %v", lastErr, context.Canceled)
+	}
+	// The function-calling loop stops before the next model call.
+	if loopingModel.calls != 2 {
+		t.Errorf("model was called %d times, want 2", loopingModel.calls)
+	}
+}
+
+func TestParallelToolCalls(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	newSlowTool := func(name string) tool.Tool {
+		slow, err := functiontool.New(functiontool.Config{
+			Name:        name,
+			Description: "takes a while",
+		}, func(ctx tool.Context, _ struct{}) (string, error) {
+			time.Sleep(delay)
+			if err := ctx.State().Set(name, "done"); err != nil {
+				return "", err
+			}
+			return name + " result", nil
+		})
+		if err != nil {
+			t.Fatalf("functiontool.New() error = %v", err)
+		}
+		return slow
+	}
+
+	tests := []struct {
+		name         string
+		maxParallel  int
+		wantDuration func(time.Duration) bool
+	}{
+		{
+			name:         "concurrent",
+			wantDuration: func(d time.Duration) bool { return d < 2*delay-50*time.Millisecond },
+		},
+		{
+			name:         "sequential",
+			maxParallel:  1,
+			wantDuration: func(d time.Duration) bool { return d >= 2*delay },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{
+					{Role: genai.RoleModel, Parts: []*genai.Part{
+						{FunctionCall: &genai.FunctionCall{ID: "1", Name: "first", Args: map[string]any{}}},
+						{FunctionCall: &genai.FunctionCall{ID: "2", Name: "second", Args: map[string]any{}}},
+					}},
+					genai.NewContentFromText("done", genai.RoleModel),
+				},
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:  "agent",
+				Model: mockModel,
+				Tools: []tool.Tool{newSlowTool("first"), newSlowTool("second")},
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			runner := testutil.NewTestAgentRunner(t, a)
+			cfg := agent.RunConfig{MaxParallelToolCalls: tt.maxParallel}
+			start := time.Now()
+			events, err := testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("run both", genai.RoleUser), cfg))
+			if err != nil {
+				t.Fatalf("agent run error = %v", err)
+			}
+			if elapsed := time.Since(start); !tt.wantDuration(elapsed) {
+				t.Errorf("agent run took %v with tools taking %v each", elapsed, delay)
+			}
+
+			var gotNames []string
+			for _, part := range events[1].Content.Parts {
+				if part.FunctionResponse != nil {
+					gotNames = append(gotNames, part.FunctionResponse.Name)
+				}
+			}
+			if diff := cmp.Diff([]string{"first", "second"}, gotNames); diff != "" {
+				t.Errorf("function responses mismatch (-want +got):\n%s", diff)
+			}
+			wantDelta := map[string]any{"first": "done", "second": "done"}
+			if diff := cmp.Diff(wantDelta, events[1].Actions.StateDelta); diff != "" {
+				t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParallelToolCalls_Callbacks(t *testing.T) {
+	const calls = 8
+	echo, err := functiontool.New(functiontool.Config{
+		Name:        "echo",
+		Description: "echoes the call number",
+	}, func(_ tool.Context, args struct{ N int }) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return args.N, nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	var parts []*genai.Part
+	for i := range calls {
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{ID: strconv.Itoa(i), Name: "echo", Args: map[string]any{"N": i}}})
+	}
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			{Role: genai.RoleModel, Parts: parts},
+			genai.NewContentFromText("done", genai.RoleModel),
+		},
+	}
+
+	// The callbacks keep unsynchronized state, the race detector reports
+	// them if they are called concurrently.
+	active, maxActive := 0, 0
+	seen := map[string]bool{}
+	results := 0
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: mockModel,
+		Tools: []tool.Tool{echo},
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{
+			func(ctx tool.Context, _ tool.Tool, _ map[string]any) (map[string]any, error) {
+				active++
+				maxActive = max(maxActive, active)
+				time.Sleep(time.Millisecond)
+				seen[ctx.FunctionCallID()] = true
+				active--
+				return nil, nil
+			},
+		},
+		AfterToolCallbacks: []llmagent.AfterToolCallback{
+			func(tool.Context, tool.Tool, map[string]any, map[string]any, error) (map[string]any, error) {
+				results++
+				return nil, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "run all")); err != nil {
+		t.Fatalf("agent run error = %v", err)
+	}
+	if maxActive != 1 {
+		t.Errorf("BeforeToolCallback was called %d times concurrently, want 1", maxActive)
+	}
+	if len(seen) != calls || results != calls {
+		t.Errorf("callbacks were called for %d calls before and %d after, want %d", len(seen), results, calls)
+	}
+}
+
+func TestMaxLLMCalls_TransferLoop(t *testing.T) {
+	// The agents keep transferring to each other.
+	modelA := &loopingModel{toolName: "transfer_to_agent", args: map[string]any{"agent_name": "agent_b"}}
+	modelB := &loopingModel{toolName: "transfer_to_agent", args: map[string]any{"agent_name": "agent_a"}}
+	agentB, err := llmagent.New(llmagent.Config{
+		Name:        "agent_b",
+		Description: "agent b",
+		Model:       modelB,
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	agentA, err := llmagent.New(llmagent.Config{
+		Name:        "agent_a",
+		Description: "agent a",
+		Model:       modelA,
+		SubAgents:   []agent.Agent{agentB},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, agentA)
+	cfg := agent.RunConfig{MaxLLMCalls: 6}
+	_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("transfer", genai.RoleUser), cfg))
+	if !errors.Is(err, model.ErrLLMCallsLimitExceeded) {
+		t.Fatalf("agent run error = %v, want %v", err, model.ErrLLMCallsLimitExceeded)
+	}
+	if calls := modelA.calls + modelB.calls; calls != 6 {
+		t.Errorf("models were called %d times, want 6", calls)
+	}
+}
+
+func TestMaxLLMCalls_SharedWithAgentTool(t *testing.T) {
+	subModel := &testutil.MockModel{
+		Responses: []*genai.Content{genai.NewContentFromText("sub-agent answer", genai.RoleModel)},
+	}
+	subAgent, err := llmagent.New(llmagent.Config{
+		Name:        "sub_agent",
+		Description: "answers questions",
+		Model:       subModel,
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	rootModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("sub_agent", map[string]any{"request": "question"}, genai.RoleModel),
+			genai.NewContentFromText("final answer", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: rootModel,
+		Tools: []tool.Tool{agenttool.New(subAgent, nil)},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	// The root agent and the sub-agent make one call each, the second call
+	// of the root agent exceeds the limit.
+	cfg := agent.RunConfig{MaxLLMCalls: 2}
+	_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("ask sub-agent", genai.RoleUser), cfg))
+	if !errors.Is(err, model.ErrLLMCallsLimitExceeded) {
+		t.Fatalf("agent run error = %v, want %v", err, model.ErrLLMCallsLimitExceeded)
+	}
+	// The sub-agent runs in streaming mode, which doesn't record requests.
+	if len(subModel.Responses) != 0 {
+		t.Errorf("sub-agent model was not called")
+	}
+	if len(rootModel.Requests) != 1 {
+		t.Errorf("root model got %d requests, want 1", len(rootModel.Requests))
+	}
+}
+
+func TestMaxDuration(t *testing.T) {
+	for _, mode := range []agent.StreamingMode{agent.StreamingModeNone, agent.StreamingModeSSE} {
+		t.Run(string(mode), func(t *testing.T) {
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{genai.NewContentFromText("slow answer", genai.RoleModel)},
+				Delay:     time.Minute,
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:  "slow_agent",
+				Model: mockModel,
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			runner := testutil.NewTestAgentRunner(t, a)
+			cfg := agent.RunConfig{StreamingMode: mode, MaxDuration: 50 * time.Millisecond}
+			start := time.Now()
+			_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("question", genai.RoleUser), cfg))
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("agent run error = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("agent run took %v, want it to be cut off after MaxDuration", elapsed)
+			}
+		})
+	}
+}
+
+func TestMaxDuration_AgentTool(t *testing.T) {
+	subModel := &testutil.MockModel{
+		Responses: []*genai.Content{genai.NewContentFromText("slow sub-agent answer", genai.RoleModel)},
+		Delay:     time.Minute,
+	}
+	subAgent, err := llmagent.New(llmagent.Config{
+		Name:        "sub_agent",
+		Description: "answers questions",
+		Model:       subModel,
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	rootModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("sub_agent", map[string]any{"request": "question"}, genai.RoleModel),
+			genai.NewContentFromText("final answer", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: rootModel,
+		Tools: []tool.Tool{agenttool.New(subAgent, nil)},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	cfg := agent.RunConfig{MaxDuration: 50 * time.Millisecond}
+	_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("ask sub-agent", genai.RoleUser), cfg))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("agent run error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(rootModel.Requests) != 1 {
+		t.Errorf("root model got %d requests, want 1", len(rootModel.Requests))
+	}
+}
+
+func TestInvocationUsage(t *testing.T) {
+	lookup, err := functiontool.New(functiontool.Config{
+		Name:        "lookup",
+		Description: "looks up the answer",
+	}, func(tool.Context, struct{}) (string, error) {
+		return "42", nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("lookup", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("the answer is 42", genai.RoleModel),
+		},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     10,
+			CandidatesTokenCount: 5,
+			TotalTokenCount:      15,
+		},
+	}
+	var callbackTokens []int64
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: mockModel,
+		Tools: []tool.Tool{lookup},
+		AfterModelCallbacks: []llmagent.AfterModelCallback{
+			func(ctx agent.CallbackContext, llmResponse *model.LLMResponse, llmResponseError error) (*model.LLMResponse, error) {
+				callbackTokens = append(callbackTokens, ctx.Usage().TotalTokens())
+				return nil, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "what is the answer?"))
+	if err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+
+	// After model callbacks run before the usage of the response is recorded.
+	if diff := cmp.Diff([]int64{0, 15}, callbackTokens); diff != "" {
+		t.Errorf("usage seen by callbacks mismatch (-want +got):\n%s", diff)
+	}
+	var gotUsage []*genai.GenerateContentResponseUsageMetadata
+	for _, event := range events {
+		gotUsage = append(gotUsage, event.InvocationUsage)
+	}
+	wantUsage := []*genai.GenerateContentResponseUsageMetadata{
+		nil, // function call
+		nil, // function response
+		{PromptTokenCount: 20, CandidatesTokenCount: 10, TotalTokenCount: 30},
+	}
+	if diff := cmp.Diff(wantUsage, gotUsage); diff != "" {
+		t.Errorf("invocation usage mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMaxTotalTokens(t *testing.T) {
+	loop, err := functiontool.New(functiontool.Config{
+		Name:        "loop",
+		Description: "asks to be called again",
+	}, func(tool.Context, struct{}) (string, error) {
+		return "call me again", nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	mockModel := &testutil.MockModel{
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{TotalTokenCount: 15},
+	}
+	for range 5 {
+		mockModel.Responses = append(mockModel.Responses, genai.NewContentFromFunctionCall("loop", map[string]any{}, genai.RoleModel))
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: mockModel,
+		Tools: []tool.Tool{loop},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	cfg := agent.RunConfig{MaxTotalTokens: 20}
+	_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("loop", genai.RoleUser), cfg))
+	if !errors.Is(err, model.ErrMaxTotalTokensExceeded) {
+		t.Fatalf("agent run error = %v, want %v", err, model.ErrMaxTotalTokensExceeded)
+	}
+	if len(mockModel.Requests) != 2 {
+		t.Errorf("model got %d requests, want 2", len(mockModel.Requests))
+	}
+}
+
+func TestContextCompression(t *testing.T) {
+	mockModel := &testutil.MockModel{}
+	for _, text := range []string{"A", "B", "C", "D"} {
+		mockModel.Responses = append(mockModel.Responses, genai.NewContentFromText(text, genai.RoleModel))
+	}
+	summarizer := &testutil.MockModel{
+		Responses: []*genai.Content{genai.NewContentFromText("the user said a and b", genai.RoleModel)},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: mockModel,
+		ContextCompression: &llmagent.ContextCompressionConfig{
+			Summarizer:      summarizer,
+			MaxEvents:       4,
+			KeepRecentTurns: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	var compactionEvents int
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		for ev, err := range runner.Run(t, "session", msg) {
+			if err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+			if ev.Actions.Compaction != nil {
+				compactionEvents++
+			}
+		}
+	}
+
+	const summary = "Summary of the earlier conversation:\nthe user said a and b"
+	wantContents := [][]string{
+		{"a"},
+		{"a", "A", "b"},
+		// The history exceeds 4 contents, everything before the last turn is summarized.
+		{summary, "c"},
+		// The stored summary is reused.
+		{summary, "c", "C", "d"},
+	}
+	var gotContents [][]string
+	for _, req := range mockModel.Requests {
+		var texts []string
+		for _, content := range req.Contents {
+			texts = append(texts, content.Parts[0].Text)
+		}
+		gotContents = append(gotContents, texts)
+	}
+	if diff := cmp.Diff(wantContents, gotContents); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+	if len(summarizer.Requests) != 1 {
+		t.Fatalf("summarizer got %d requests, want 1", len(summarizer.Requests))
+	}
+	var summarized []string
+	for _, content := range summarizer.Requests[0].Contents {
+		summarized = append(summarized, content.Parts[0].Text)
+	}
+	if diff := cmp.Diff([]string{"a", "A", "b", "B"}, summarized[:len(summarized)-1]); diff != "" {
+		t.Errorf("summarized contents mismatch (-want +got):\n%s", diff)
+	}
+	if compactionEvents != 1 {
+		t.Errorf("got %d compaction events, want 1", compactionEvents)
+	}
+}
+
+// recordingCompactor summarizes the history as the texts of its contents.
+type recordingCompactor struct {
+	calls [][]string
+}
+
+func (c *recordingCompactor) Summarize(ctx context.Context, contents []*genai.Content) (string, error) {
+	var texts []string
+	for _, content := range contents {
+		texts = append(texts, content.Parts[0].Text)
+	}
+	c.calls = append(c.calls, texts)
+	return "said " + strings.Join(texts, ","), nil
+}
+
+func TestContextCompression_Compactor(t *testing.T) {
+	mockModel := &testutil.MockModel{}
+	for _, text := range []string{"A", "B", "C", "D"} {
+		mockModel.Responses = append(mockModel.Responses, genai.NewContentFromText(text, genai.RoleModel))
+	}
+	compactor := &recordingCompactor{}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: mockModel,
+		ContextCompression: &llmagent.ContextCompressionConfig{
+			Compactor:       compactor,
+			MaxEvents:       4,
+			KeepRecentTurns: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		for _, err := range runner.Run(t, "session", msg) {
+			if err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+		}
+	}
+
+	const summary = "Summary of the earlier conversation:\nsaid a,A,b,B"
+	wantContents := [][]string{
+		{"a"},
+		{"a", "A", "b"},
+		{summary, "c"},
+		// The recorded summary is reused, the compactor isn't called again.
+		{summary, "c", "C", "d"},
+	}
+	var gotContents [][]string
+	for _, req := range mockModel.Requests {
+		var texts []string
+		for _, content := range req.Contents {
+			texts = append(texts, content.Parts[0].Text)
+		}
+		gotContents = append(gotContents, texts)
+	}
+	if diff := cmp.Diff(wantContents, gotContents); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"a", "A", "b", "B"}}, compactor.calls); diff != "" {
+		t.Errorf("compactor calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewModelCompactor(t *testing.T) {
+	summarizer := &testutil.MockModel{
+		Responses: []*genai.Content{genai.NewContentFromText("the user said hi", genai.RoleModel)},
+	}
+	got, err := llmagent.NewModelCompactor(summarizer).Summarize(t.Context(), []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if want := "the user said hi"; got != want {
+		t.Errorf("Summarize() = %q, want %q", got, want)
+	}
+	if len(summarizer.Requests) != 1 || len(summarizer.Requests[0].Contents) != 2 {
+		t.Fatalf("summarizer requests = %v, want one with the history and the summary instruction", summarizer.Requests)
+	}
+}
+
+func TestHistoryTrimming(t *testing.T) {
+	mockModel := &testutil.MockModel{}
+	for _, text := range []string{"A", "B", "C"} {
+		mockModel.Responses = append(mockModel.Responses, genai.NewContentFromText(text, genai.RoleModel))
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "agent",
+		Model:       mockModel,
+		Instruction: "Be brief.",
+		HistoryTrimming: &llmagent.HistoryTrimmingConfig{
+			MaxEvents:      3,
+			KeepWholeTurns: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	for _, msg := range []string{"a", "b", "c"} {
+		if _, err := testutil.CollectEvents(runner.Run(t, "session", msg)); err != nil {
+			t.Fatalf("agent run failed: %v", err)
+		}
+	}
+
+	wantContents := [][]string{
+		{"a"},
+		{"a", "A", "b"},
+		// The oldest turn is dropped to stay within 3 contents.
+		{"b", "B", "c"},
+	}
+	var gotContents [][]string
+	for _, req := range mockModel.Requests {
+		var texts []string
+		for _, content := range req.Contents {
+			texts = append(texts, content.Parts[0].Text)
+		}
+		gotContents = append(gotContents, texts)
+		if si := req.Config.SystemInstruction; si == nil || !strings.Contains(si.Parts[0].Text, "Be brief.") {
+			t.Errorf("request system instruction = %v, want the agent instruction", si)
+		}
+	}
+	if diff := cmp.Diff(wantContents, gotContents); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// loopingModel always responds with a call to the same tool.
+type loopingModel struct {
+	toolName string
+	args     map[string]any
+	calls    int
+}
+
+func (m *loopingModel) Name() string {
+	return "looping-model"
+}
+
+func (m *loopingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		yield(&model.LLMResponse{
+			Content: genai.NewContentFromFunctionCall(m.toolName, m.args, genai.RoleModel),
+		}, nil)
+	}
+}
+
+// blockingModel blocks every response with a safety filter.
+type blockingModel struct {
+	requests []*model.LLMRequest
+}
+
+func (m *blockingModel) Name() string {
+	return "blocking-model"
+}
+
+func (m *blockingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.requests = append(m.requests, req)
+		yield(nil, &model.BlockedError{
+			Reason:     string(genai.FinishReasonSafety),
+			Categories: []genai.HarmCategory{genai.HarmCategoryDangerousContent},
+		})
+	}
+}
+
+func TestSafetySettings(t *testing.T) {
+	safetySettings := []*genai.SafetySetting{{
+		Category:  genai.HarmCategoryDangerousContent,
+		Threshold: genai.HarmBlockThresholdBlockLowAndAbove,
+	}}
+	blockedModel := &blockingModel{}
+	var gotCategories []genai.HarmCategory
+	a, err := llmagent.New(llmagent.Config{
+		Name:                  "careful_agent",
+		Model:                 blockedModel,
+		GenerateContentConfig: &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.5)},
+		SafetySettings:        safetySettings,
+		AfterModelCallbacks: []llmagent.AfterModelCallback{
+			func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+				var blocked *model.BlockedError
+				if !errors.As(respErr, &blocked) {
+					return nil, nil
+				}
+				gotCategories = blocked.Categories
+				return &model.LLMResponse{Content: genai.NewContentFromText("I can't help with that.", genai.RoleModel)}, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "How do I pick a lock?"))
+	if err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Content == nil || events[0].Content.Parts[0].Text != "I can't help with that." {
+		t.Errorf("agent events = %v, want the response of the callback", events)
+	}
+	if diff := cmp.Diff([]genai.HarmCategory{genai.HarmCategoryDangerousContent}, gotCategories); diff != "" {
+		t.Errorf("blocked categories mismatch (-want +got):\n%s", diff)
+	}
+	if len(blockedModel.requests) != 1 {
+		t.Fatalf("model got %d requests, want 1", len(blockedModel.requests))
+	}
+	cfg := blockedModel.requests[0].Config
+	if diff := cmp.Diff(safetySettings, cfg.SafetySettings); diff != "" {
+		t.Errorf("request SafetySettings mismatch (-want +got):\n%s", diff)
+	}
+	if cfg.Temperature == nil || *cfg.Temperature != 0.5 {
+		t.Errorf("request Temperature = %v, want 0.5", cfg.Temperature)
+	}
+}
+
+func TestSamplingConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		genCfg *genai.GenerateContentConfig
+		want   *genai.GenerateContentConfig
+	}{
+		{
+			name: "shortcut fields",
+			want: &genai.GenerateContentConfig{
+				Temperature:     genai.Ptr[float32](0.2),
+				TopP:            genai.Ptr[float32](0.9),
+				TopK:            genai.Ptr[float32](40),
+				MaxOutputTokens: 256,
+				StopSequences:   []string{"END"},
+			},
+		},
+		{
+			name: "GenerateContentConfig takes precedence",
+			genCfg: &genai.GenerateContentConfig{
+				Temperature:     genai.Ptr[float32](1),
+				MaxOutputTokens: 1024,
+				CandidateCount:  1,
+			},
+			want: &genai.GenerateContentConfig{
+				Temperature:     genai.Ptr[float32](1),
+				TopP:            genai.Ptr[float32](0.9),
+				TopK:            genai.Ptr[float32](40),
+				MaxOutputTokens: 1024,
+				StopSequences:   []string{"END"},
+				CandidateCount:  1,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{genai.NewContentFromText("answer", genai.RoleModel)},
+			}
+			var userCfg *genai.GenerateContentConfig
+			if tc.genCfg != nil {
+				copied := *tc.genCfg
+				userCfg = &copied
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:                  "tuned_agent",
+				Model:                 mockModel,
+				GenerateContentConfig: tc.genCfg,
+				Temperature:           genai.Ptr[float32](0.2),
+				TopP:                  genai.Ptr[float32](0.9),
+				TopK:                  genai.Ptr[float32](40),
+				MaxOutputTokens:       256,
+				StopSequences:         []string{"END"},
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+			if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "question")); err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+
+			got := mockModel.Requests[0].Config
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(genai.GenerateContentConfig{}, "SystemInstruction")); diff != "" {
+				t.Errorf("request config mismatch (-want +got):\n%s", diff)
+			}
+			// The config of the user isn't modified.
+			if diff := cmp.Diff(userCfg, tc.genCfg); diff != "" {
+				t.Errorf("GenerateContentConfig was modified (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIncludeContentsNone(t *testing.T) {
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("first answer", genai.RoleModel),
+			genai.NewContentFromText("second answer", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:            "stateless_agent",
+		Model:           mockModel,
+		Instruction:     "Answer the question.",
+		IncludeContents: llmagent.IncludeContentsNone,
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	for _, msg := range []string{"first question", "second question"} {
+		if _, err := testutil.CollectEvents(runner.Run(t, "session", msg)); err != nil {
+			t.Fatalf("agent run failed: %v", err)
+		}
+	}
+
+	if len(mockModel.Requests) != 2 {
+		t.Fatalf("model got %d requests, want 2", len(mockModel.Requests))
+	}
+	req := mockModel.Requests[1]
+	want := []*genai.Content{genai.NewContentFromText("second question", genai.RoleUser)}
+	if diff := cmp.Diff(want, req.Contents); diff != "" {
+		t.Errorf("second request contents mismatch (-want +got):\n%s", diff)
+	}
+	if si := req.Config.SystemInstruction; si == nil || !strings.Contains(si.Parts[0].Text, "Answer the question.") {
+		t.Errorf("second request system instruction = %v, want the agent instruction", si)
+	}
+}
+
+func TestToolChoice(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		toolChoice           llmagent.ToolChoice
+		allowedFunctionNames []string
+		want                 *genai.ToolConfig
+	}{
+		{
+			name: "default",
+			want: &genai.ToolConfig{RetrievalConfig: &genai.RetrievalConfig{LanguageCode: "en"}},
+		},
+		{
+			name:                 "any with allowed function names",
+			toolChoice:           llmagent.ToolChoiceAny,
+			allowedFunctionNames: []string{"transfer_to_agent"},
+			want: &genai.ToolConfig{
+				RetrievalConfig: &genai.RetrievalConfig{LanguageCode: "en"},
+				FunctionCallingConfig: &genai.FunctionCallingConfig{
+					Mode:                 genai.FunctionCallingConfigModeAny,
+					AllowedFunctionNames: []string{"transfer_to_agent"},
+				},
+			},
+		},
+		{
+			name:       "none",
+			toolChoice: llmagent.ToolChoiceNone,
+			want: &genai.ToolConfig{
+				RetrievalConfig:       &genai.RetrievalConfig{LanguageCode: "en"},
+				FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeNone},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			subAgent, err := llmagent.New(llmagent.Config{Name: "billing_agent", Description: "handles billing"})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+			mockModel := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("answer", genai.RoleModel)}}
+			generateContentConfig := &genai.GenerateContentConfig{
+				ToolConfig: &genai.ToolConfig{RetrievalConfig: &genai.RetrievalConfig{LanguageCode: "en"}},
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:                  "router",
+				Model:                 mockModel,
+				SubAgents:             []agent.Agent{subAgent},
+				GenerateContentConfig: generateContentConfig,
+				ToolChoice:            tc.toolChoice,
+				AllowedFunctionNames:  tc.allowedFunctionNames,
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "question")); err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+			if len(mockModel.Requests) != 1 {
+				t.Fatalf("model got %d requests, want 1", len(mockModel.Requests))
+			}
+			if diff := cmp.Diff(tc.want, mockModel.Requests[0].Config.ToolConfig); diff != "" {
+				t.Errorf("request ToolConfig mismatch (-want +got):\n%s", diff)
+			}
+			if generateContentConfig.ToolConfig.FunctionCallingConfig != nil {
+				t.Errorf("agent's GenerateContentConfig was modified: %+v", generateContentConfig.ToolConfig)
+			}
+		})
+	}
+}
+
+func TestModelName(t *testing.T) {
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("first answer", genai.RoleModel),
+			genai.NewContentFromText("second answer", genai.RoleModel),
+		},
+	}
+	var resolved []string
+	model.Register(`llmagent-test-.*`, func(ctx context.Context, name string) (model.LLM, error) {
+		resolved = append(resolved, name)
+		return mockModel, nil
+	})
+
+	a, err := llmagent.New(llmagent.Config{
+		Name:      "named_model_agent",
+		ModelName: "llmagent-test-model",
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	if len(resolved) != 0 {
+		t.Fatalf("model resolved at construction: %v, want lazy resolution", resolved)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	for _, msg := range []string{"first question", "second question"} {
+		if _, err := testutil.CollectEvents(runner.Run(t, "session", msg)); err != nil {
+			t.Fatalf("agent run failed: %v", err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"llmagent-test-model"}, resolved); diff != "" {
+		t.Errorf("resolved models mismatch (-want +got):\n%s", diff)
+	}
+	if len(mockModel.Requests) != 2 {
+		t.Errorf("model got %d requests, want 2", len(mockModel.Requests))
+	}
+}
+
+func TestModelName_ResolveError(t *testing.T) {
+	model.Register(`llmagent-failing-.*`, func(ctx context.Context, name string) (model.LLM, error) {
+		return nil, errors.New("missing credentials")
+	})
+	a, err := llmagent.New(llmagent.Config{
+		Name:      "failing_model_agent",
+		ModelName: "llmagent-failing-model",
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	_, err = testutil.CollectEvents(runner.Run(t, "session", "question"))
+	if err == nil || !strings.Contains(err.Error(), "missing credentials") {
+		t.Errorf("agent run error = %v, want model resolution error", err)
+	}
+}
+
+func TestOutputSchema(t *testing.T) {
+	outputSchema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"city":       {Type: genai.TypeString},
+			"population": {Type: genai.TypeInteger},
+		},
+		Required: []string{"city", "population"},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		response  string
+		wantState any
+		wantErr   string
+	}{
+		{
+			name:      "structured output",
+			response:  `{"city": "Paris", "population": 2100000}`,
+			wantState: map[string]any{"city": "Paris", "population": float64(2100000)},
+		},
+		{
+			name:     "output not matching the schema",
+			response: `{"city": "Paris"}`,
+			wantErr:  "does not match OutputSchema",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{genai.NewContentFromText(tc.response, genai.RoleModel)},
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:         "city_agent",
+				Model:        mockModel,
+				Instruction:  "Describe the capital of France.",
+				OutputSchema: outputSchema,
+				OutputKey:    "capital",
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			runner := testutil.NewTestAgentRunner(t, a)
+			events, err := testutil.CollectEvents(runner.Run(t, "session", "What is the capital of France?"))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("agent run error = %v, want error containing %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+
+			if len(mockModel.Requests) != 1 {
+				t.Fatalf("model got %d requests, want 1", len(mockModel.Requests))
+			}
+			cfg := mockModel.Requests[0].Config
+			if cfg.ResponseMIMEType != "application/json" || cfg.ResponseSchema != outputSchema {
+				t.Errorf("request config = (%q, %v), want JSON response matching the output schema", cfg.ResponseMIMEType, cfg.ResponseSchema)
+			}
+
+			if tc.wantErr != "" {
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("agent returned %d events, want 1", len(events))
+			}
+			if diff := cmp.Diff(tc.wantState, events[0].Actions.StateDelta["capital"]); diff != "" {
+				t.Errorf("saved output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// noJSONModeModel is a model without JSON mode.
+type noJSONModeModel struct {
+	*testutil.MockModel
+}
+
+func (noJSONModeModel) SupportsJSONMode() bool {
+	return false
+}
+
+func TestOutputJSON(t *testing.T) {
+	outputSchema := &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+		Required:   []string{"city"},
+	}
+
+	for _, tc := range []struct {
+		name            string
+		schema          *genai.Schema
+		noJSONMode      bool
+		response        string
+		wantInstruction string
+		wantState       any
+		wantErr         string
+	}{
+		{
+			name:      "JSON mode",
+			response:  `["Paris", "Lyon"]`,
+			wantState: []any{"Paris", "Lyon"},
+		},
+		{
+			name:     "response is not JSON",
+			response: "Paris and Lyon",
+			wantErr:  "response is not JSON",
+		},
+		{
+			name:            "model without JSON mode",
+			noJSONMode:      true,
+			response:        "```json\n[\"Paris\", \"Lyon\"]\n```",
+			wantInstruction: "Reply only with a JSON value, without any other text.",
+			wantState:       []any{"Paris", "Lyon"},
+		},
+		{
+			name:            "model without JSON mode with schema",
+			schema:          outputSchema,
+			noJSONMode:      true,
+			response:        `{"city": "Paris"}`,
+			wantInstruction: `The JSON value must match this schema:`,
+			wantState:       map[string]any{"city": "Paris"},
+		},
+		{
+			name:       "model without JSON mode not matching the schema",
+			schema:     outputSchema,
+			noJSONMode: true,
+			response:   `{"town": "Paris"}`,
+			wantErr:    "does not match OutputSchema",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{genai.NewContentFromText(tc.response, genai.RoleModel)},
+			}
+			var llm model.LLM = mockModel
+			if tc.noJSONMode {
+				llm = noJSONModeModel{mockModel}
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:         "city_agent",
+				Model:        llm,
+				Instruction:  "List the biggest cities of France.",
+				OutputJSON:   true,
+				OutputSchema: tc.schema,
+				OutputKey:    "cities",
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "Which cities?"))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("agent run error = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("agent run failed: %v", err)
+			}
+
+			cfg := mockModel.Requests[0].Config
+			if tc.noJSONMode {
+				if cfg.ResponseMIMEType != "" || cfg.ResponseSchema != nil {
+					t.Errorf("request config = (%q, %v), want no JSON mode", cfg.ResponseMIMEType, cfg.ResponseSchema)
+				}
+				if got := cfg.SystemInstruction.Parts[0].Text; !strings.Contains(got, tc.wantInstruction) {
+					t.Errorf("system instruction = %q, want it to contain %q", got, tc.wantInstruction)
+				}
+			} else if cfg.ResponseMIMEType != "application/json" {
+				t.Errorf("request ResponseMIMEType = %q, want application/json", cfg.ResponseMIMEType)
+			}
+			if diff := cmp.Diff(tc.wantState, events[len(events)-1].Actions.StateDelta["cities"]); diff != "" {
+				t.Errorf("saved output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	newTool := func(name string) tool.Tool {
+		t.Helper()
+		ft, err := functiontool.New(functiontool.Config{Name: name, Description: name}, func(tool.Context, struct{}) (string, error) {
+			return "", nil
+		})
+		if err != nil {
+			t.Fatalf("functiontool.New() error = %v", err)
+		}
+		return ft
+	}
+	subAgent, err := llmagent.New(llmagent.Config{Name: "sub_agent"})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	outputSchema := &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{"answer": {Type: genai.TypeString}}}
+
+	for _, tc := range []struct {
+		name    string
+		cfg     llmagent.Config
+		wantErr string
+	}{
+		{
+			name:    "duplicate tool names",
+			cfg:     llmagent.Config{Name: "agent", Tools: []tool.Tool{newTool("search"), newTool("lookup"), newTool("search")}},
+			wantErr: `tool "search" is defined more than once`,
+		},
+		{
+			name:    "nil tool",
+			cfg:     llmagent.Config{Name: "agent", Tools: []tool.Tool{nil}},
+			wantErr: "tool can't be nil",
+		},
+		{
+			name:    "tools with output schema",
+			cfg:     llmagent.Config{Name: "agent", Tools: []tool.Tool{newTool("search")}, OutputSchema: outputSchema},
+			wantErr: "tools can't be used together with OutputSchema",
+		},
+		{
+			name:    "sub-agents with output schema",
+			cfg:     llmagent.Config{Name: "agent", SubAgents: []agent.Agent{subAgent}, OutputSchema: outputSchema},
+			wantErr: "sub-agents can't be used together with OutputSchema",
+		},
+		{
+			name:    "tools with JSON output",
+			cfg:     llmagent.Config{Name: "agent", Tools: []tool.Tool{newTool("search")}, OutputJSON: true},
+			wantErr: "tools can't be used together with OutputJSON",
+		},
+		{
+			name:    "unknown include contents",
+			cfg:     llmagent.Config{Name: "agent", IncludeContents: "all"},
+			wantErr: `unknown IncludeContents "all"`,
+		},
+		{
+			name:    "unknown model name",
+			cfg:     llmagent.Config{Name: "agent", ModelName: "unknown-model"},
+			wantErr: `no model registered for name "unknown-model", registered patterns: [`,
+		},
+		{
+			name:    "both model and model name",
+			cfg:     llmagent.Config{Name: "agent", Model: &testutil.MockModel{}, ModelName: "gemini-2.5-flash"},
+			wantErr: "only one of Model and ModelName can be set",
+		},
+		{
+			name: "both safety settings",
+			cfg: llmagent.Config{
+				Name:                  "agent",
+				SafetySettings:        []*genai.SafetySetting{{Category: genai.HarmCategoryHarassment, Threshold: genai.HarmBlockThresholdBlockNone}},
+				GenerateContentConfig: &genai.GenerateContentConfig{SafetySettings: []*genai.SafetySetting{{Category: genai.HarmCategoryHateSpeech, Threshold: genai.HarmBlockThresholdBlockNone}}},
+			},
+			wantErr: "only one of SafetySettings and GenerateContentConfig.SafetySettings can be set",
+		},
+		{
+			name:    "unknown tool choice",
+			cfg:     llmagent.Config{Name: "agent", ToolChoice: "required"},
+			wantErr: `unknown ToolChoice "required"`,
+		},
+		{
+			name:    "tool choice any without tools",
+			cfg:     llmagent.Config{Name: "agent", ToolChoice: llmagent.ToolChoiceAny},
+			wantErr: `ToolChoice "any" requires tools`,
+		},
+		{
+			name:    "tool choice none with allowed function names",
+			cfg:     llmagent.Config{Name: "agent", Tools: []tool.Tool{newTool("search")}, ToolChoice: llmagent.ToolChoiceNone, AllowedFunctionNames: []string{"search"}},
+			wantErr: `AllowedFunctionNames can't be used together with ToolChoice "none"`,
+		},
+		{
+			name:    "allowed function names without tool choice",
+			cfg:     llmagent.Config{Name: "agent", Tools: []tool.Tool{newTool("search")}, AllowedFunctionNames: []string{"search"}},
+			wantErr: `AllowedFunctionNames requires ToolChoice "any"`,
+		},
+		{
+			name: "context compression with compactor and summarizer",
+			cfg: llmagent.Config{Name: "agent", ContextCompression: &llmagent.ContextCompressionConfig{
+				MaxEvents:  10,
+				Compactor:  llmagent.NewModelCompactor(&testutil.MockModel{}),
+				Summarizer: &testutil.MockModel{},
+			}},
+			wantErr: "only one of ContextCompression Compactor and Summarizer can be set",
+		},
+		{
+			name:    "history trimming without limits",
+			cfg:     llmagent.Config{Name: "agent", HistoryTrimming: &llmagent.HistoryTrimmingConfig{KeepWholeTurns: true}},
+			wantErr: "HistoryTrimming requires MaxTokens or MaxEvents to be set",
+		},
+		{
+			name:    "negative history trimming limit",
+			cfg:     llmagent.Config{Name: "agent", HistoryTrimming: &llmagent.HistoryTrimmingConfig{MaxEvents: -1}},
+			wantErr: "HistoryTrimming limits can't be negative",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := llmagent.New(tc.cfg)
+			if err == nil {
+				t.Fatalf("llmagent.New() error = nil, want %q", tc.wantErr)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) || !strings.Contains(err.Error(), `agent "agent"`) {
+				t.Errorf("llmagent.New() error = %q, want error about agent %q containing %q", err, "agent", tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/tool"
+)
+
+const maxLoggedValueLength = 200
+
+// ToolLoggingCallbacks returns callbacks logging tool executions at the debug
+// level: the arguments when a tool is called, and the result or the error
+// with the latency when it completes. The callbacks don't change the tool
+// results, they should be put first in Config.BeforeToolCallbacks and
+// Config.AfterToolCallbacks to observe all tool executions.
+func ToolLoggingCallbacks(logger *slog.Logger) (BeforeToolCallback, AfterToolCallback) {
+	var starts sync.Map // function call id -> time.Time
+
+	before := func(ctx tool.Context, t tool.Tool, args map[string]any) (map[string]any, error) {
+		starts.Store(ctx.FunctionCallID(), time.Now())
+		logger.LogAttrs(ctx, slog.LevelDebug, "Tool call",
+			slog.String("tool", t.Name()),
+			slog.String("invocation_id", ctx.InvocationID()),
+			slog.String("function_call_id", ctx.FunctionCallID()),
+			slog.String("args", loggedValue(args)),
+		)
+		return nil, nil
+	}
+
+	after := func(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+		attrs := []slog.Attr{
+			slog.String("tool", t.Name()),
+			slog.String("invocation_id", ctx.InvocationID()),
+			slog.String("function_call_id", ctx.FunctionCallID()),
+		}
+		if start, ok := starts.LoadAndDelete(ctx.FunctionCallID()); ok {
+			attrs = append(attrs, slog.Duration("latency", time.Since(start.(time.Time))))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		} else {
+			attrs = append(attrs, slog.String("result", loggedValue(result)))
+		}
+		logger.LogAttrs(ctx, slog.LevelDebug, "Tool result", attrs...)
+		return nil, nil
+	}
+
+	return before, after
+}
+
+// loggedValue returns the JSON of v, truncated to keep log records short.
+func loggedValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "<not serializable>"
+	}
+	if len(b) > maxLoggedValueLength {
+		return string(b[:maxLoggedValueLength]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"google.golang.org/adk/model"
+)
+
+// lazyModel is a model.LLM resolved from the model registry at its first use.
+// A failed resolution is not cached, so it is retried by the next request.
+type lazyModel struct {
+	name string
+
+	mu  sync.Mutex
+	llm model.LLM
+}
+
+func (m *lazyModel) Name() string {
+	return m.name
+}
+
+func (m *lazyModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	llm, err := m.resolve(ctx)
+	if err != nil {
+		return func(yield func(*model.LLMResponse, error) bool) {
+			yield(nil, err)
+		}
+	}
+	return llm.GenerateContent(ctx, req, stream)
+}
+
+// SupportsJSONMode implements model.JSONModeSupporter. The model is assumed
+// to support JSON mode until it is resolved.
+func (m *lazyModel) SupportsJSONMode() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.llm == nil || model.SupportsJSONMode(m.llm)
+}
+
+func (m *lazyModel) resolve(ctx context.Context) (model.LLM, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.llm != nil {
+		return m.llm, nil
+	}
+	llm, err := model.Resolve(ctx, m.name)
+	if err != nil {
+		return nil, err
+	}
+	m.llm = llm
+	return llm, nil
+}
@@ -15,14 +15,22 @@
 package agent
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 )
 
+// ErrAgentNotFound is the error returned by [Loader.LoadAgent] when there is
+// no agent with the given name.
+var ErrAgentNotFound = errors.New("agent not found")
+
 // Loader allows to load a particular agent by name and get the root agent
 type Loader interface {
 	// ListAgents returns a list of names of all agents
 	ListAgents() []string
-	// LoadAgent returns an agent by its name. Returns error if there is no agent with such a name.
+	// LoadAgent returns an agent by its name. Returns an error wrapping
+	// ErrAgentNotFound if there is no agent with such a name.
 	LoadAgent(name string) (Agent, error)
 	// RootAgent returns the root agent
 	RootAgent() Agent
@@ -57,7 +65,7 @@ func (s *singleLoader) LoadAgent(name string) (Agent, error) {
 	if name == s.root.Name() {
 		return s.root, nil
 	}
-	return nil, fmt.Errorf("cannot load agent '%s' - provide an empty string or use '%s'", name, s.root.Name())
+	return nil, fmt.Errorf("cannot load agent '%s' - provide an empty string or use '%s': %w", name, s.root.Name(), ErrAgentNotFound)
 }
 
 // singleAgentLoader implements AgentLoader. Returns the root agent.
@@ -83,20 +91,37 @@ func NewMultiLoader(root Agent, agents ...Agent) (Loader, error) {
 	}, nil
 }
 
-// multiAgentLoader implements AgentLoader. Returns the list of all agents' names (including root agent)
-func (m *multiLoader) ListAgents() []string {
-	agents := make([]string, 0, len(m.agentMap))
-	for name := range m.agentMap {
-		agents = append(agents, name)
+// NewMapLoader returns a loader of the agents keyed by app name, e.g. to
+// register in code several apps which may share agent names. The root agent
+// is the agent of the root app.
+func NewMapLoader(root string, agents map[string]Agent) (Loader, error) {
+	rootAgent, ok := agents[root]
+	if !ok {
+		return nil, fmt.Errorf("root app %q is not one of the apps %v", root, slices.Sorted(maps.Keys(agents)))
 	}
-	return agents
+	m := make(map[string]Agent, len(agents))
+	for name, a := range agents {
+		if name == "" || a == nil {
+			return nil, fmt.Errorf("apps require a name and an agent")
+		}
+		m[name] = a
+	}
+	return &multiLoader{
+		agentMap: m,
+		root:     rootAgent,
+	}, nil
+}
+
+// multiAgentLoader implements AgentLoader. Returns the sorted list of all agents' names (including root agent)
+func (m *multiLoader) ListAgents() []string {
+	return slices.Sorted(maps.Keys(m.agentMap))
 }
 
 // multiAgentLoader implements LoadAgent. Returns an agent with given name or error if no such an agent is found
 func (m *multiLoader) LoadAgent(name string) (Agent, error) {
 	agent, ok := m.agentMap[name]
 	if !ok {
-		return nil, fmt.Errorf("agent %s not found. Please specify one of those: %v", name, m.ListAgents())
+		return nil, fmt.Errorf("%w: %s. Please specify one of those: %v", ErrAgentNotFound, name, m.ListAgents())
 	}
 	return agent, nil
 }
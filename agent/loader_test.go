@@ -15,7 +15,9 @@
 package agent
 
 import (
+	"errors"
 	"iter"
+	"slices"
 	"testing"
 
 	"google.golang.org/adk/session"
@@ -103,3 +105,33 @@ func TestDuplicateName(t *testing.T) {
 		}
 	}
 }
+
+func TestMapLoader(t *testing.T) {
+	// Apps may share agent names.
+	weather := &testAgent{name: "assistant"}
+	travel := &testAgent{name: "assistant"}
+
+	loader, err := NewMapLoader("weather", map[string]Agent{"weather": weather, "travel": travel})
+	if err != nil {
+		t.Fatalf("NewMapLoader() error = %v", err)
+	}
+	if got, want := loader.ListAgents(), []string{"travel", "weather"}; !slices.Equal(got, want) {
+		t.Errorf("ListAgents() = %v, want %v", got, want)
+	}
+	if got := loader.RootAgent(); got != weather {
+		t.Errorf("RootAgent() = %v, want the weather agent", got)
+	}
+	if got, err := loader.LoadAgent("travel"); err != nil || got != travel {
+		t.Errorf("LoadAgent(travel) = %v, %v, want the travel agent", got, err)
+	}
+	if _, err := loader.LoadAgent("unknown"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("LoadAgent(unknown) error = %v, want ErrAgentNotFound", err)
+	}
+
+	if _, err := NewMapLoader("unknown", map[string]Agent{"weather": weather}); err == nil {
+		t.Error("NewMapLoader() with an unknown root succeeded, want an error")
+	}
+	if _, err := NewMapLoader("weather", map[string]Agent{"weather": weather, "travel": nil}); err == nil {
+		t.Error("NewMapLoader() with a nil agent succeeded, want an error")
+	}
+}
@@ -16,11 +16,16 @@
 package remoteagent
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"iter"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2aclient"
@@ -48,10 +53,23 @@ type A2AConfig struct {
 	ClientFactory *a2aclient.Factory
 	// MessageSendConfig is attached to a2a.MessageSendParams sent on every agent invocation.
 	MessageSendConfig *a2a.MessageSendConfig
+
+	// Headers are attached to every request sent to the remote agent, including the agent card
+	// request. They can be used to provide static credentials, e.g. an Authorization header.
+	Headers map[string]string
+	// Timeout limits the duration of an agent invocation, including the agent card resolution.
+	// Zero means no limit.
+	Timeout time.Duration
 }
 
 // NewA2A creates a remote A2A agent. A2A (Agent-To-Agent) protocol is used for communication with an
 // agent which can run in a different process or on a different host.
+//
+// The agent sends the conversation parts the remote agent hasn't seen yet as an A2A message and
+// converts the received messages, task status and artifact updates to session events. Responses
+// are streamed if the agent card declares the streaming capability. The remote agent can be used
+// as a sub-agent of an LLM agent, which can transfer the conversation to it, or as a tool with
+// agenttool. The resolved agent card is reused by all the invocations.
 func NewA2A(cfg A2AConfig) (agent.Agent, error) {
 	if cfg.AgentCard == nil && cfg.AgentCardSource == "" {
 		return nil, fmt.Errorf("either AgentCard or AgentCardSource must be provided")
@@ -68,29 +86,39 @@ func NewA2A(cfg A2AConfig) (agent.Agent, error) {
 }
 
 type a2aAgent struct {
+	mu           sync.Mutex
 	resolvedCard *a2a.AgentCard
 }
 
 func (a *a2aAgent) run(ctx agent.InvocationContext, cfg A2AConfig) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
-		card, err := resolveAgentCard(ctx, cfg)
+		var callCtx context.Context = ctx
+		if cfg.Timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+		}
+
+		card, err := a.agentCard(callCtx, cfg)
 		if err != nil {
 			yield(toErrorEvent(ctx, fmt.Errorf("agent card resolution failed: %w", err)), nil)
 			return
 		}
-		a.resolvedCard = card
 
 		var client *a2aclient.Client
 		if cfg.ClientFactory != nil {
-			client, err = cfg.ClientFactory.CreateFromCard(ctx, card)
+			client, err = cfg.ClientFactory.CreateFromCard(callCtx, card)
 		} else {
-			client, err = a2aclient.NewFromCard(ctx, card)
+			client, err = a2aclient.NewFromCard(callCtx, card)
 		}
 		if err != nil {
 			yield(toErrorEvent(ctx, fmt.Errorf("client creation failed: %w", err)), nil)
 			return
 		}
 		defer destroy(client)
+		if len(cfg.Headers) > 0 {
+			client.AddCallInterceptor(headerInterceptor(cfg.Headers))
+		}
 
 		msg, err := newMessage(ctx)
 		if err != nil {
@@ -104,7 +132,7 @@ func (a *a2aAgent) run(ctx agent.InvocationContext, cfg A2AConfig) iter.Seq2[*se
 		}
 
 		req := &a2a.MessageSendParams{Message: msg, Config: cfg.MessageSendConfig}
-		for a2aEvent, err := range client.SendStreamingMessage(ctx, req) {
+		for a2aEvent, err := range client.SendStreamingMessage(callCtx, req) {
 			if err != nil {
 				event := toErrorEvent(ctx, err)
 				updateCustomMetadata(event, req, nil)
@@ -132,13 +160,32 @@ func (a *a2aAgent) run(ctx agent.InvocationContext, cfg A2AConfig) iter.Seq2[*se
 	}
 }
 
-func resolveAgentCard(ctx agent.InvocationContext, cfg A2AConfig) (*a2a.AgentCard, error) {
+// agentCard returns the agent card resolved by a previous invocation or resolves it.
+func (a *a2aAgent) agentCard(ctx context.Context, cfg A2AConfig) (*a2a.AgentCard, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.resolvedCard != nil {
+		return a.resolvedCard, nil
+	}
+	card, err := resolveAgentCard(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.resolvedCard = card
+	return card, nil
+}
+
+func resolveAgentCard(ctx context.Context, cfg A2AConfig) (*a2a.AgentCard, error) {
 	if cfg.AgentCard != nil {
 		return cfg.AgentCard, nil
 	}
 
 	if strings.HasPrefix(cfg.AgentCardSource, "http://") || strings.HasPrefix(cfg.AgentCardSource, "https://") {
-		card, err := agentcard.DefaultResolver.Resolve(ctx, cfg.AgentCardSource, cfg.CardResolveOptions...)
+		opts := slices.Clone(cfg.CardResolveOptions)
+		for _, name := range slices.Sorted(maps.Keys(cfg.Headers)) {
+			opts = append(opts, agentcard.WithRequestHeader(name, cfg.Headers[name]))
+		}
+		card, err := agentcard.DefaultResolver.Resolve(ctx, cfg.AgentCardSource, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch an agent card: %w", err)
 		}
@@ -150,12 +197,26 @@ func resolveAgentCard(ctx agent.InvocationContext, cfg A2AConfig) (*a2a.AgentCar
 		return nil, fmt.Errorf("failed to read agent card from %q: %w", cfg.AgentCardSource, err)
 	}
 
-	var card *a2a.AgentCard
-	if err := json.Unmarshal(fileBytes, card); err != nil {
+	var card a2a.AgentCard
+	if err := json.Unmarshal(fileBytes, &card); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal an agent card: %w", err)
 	}
 
-	return card, nil
+	return &card, nil
+}
+
+// headerInterceptor attaches the headers to every request sent by a2aclient.Client.
+type headerInterceptor map[string]string
+
+func (h headerInterceptor) Before(ctx context.Context, req *a2aclient.Request) (context.Context, error) {
+	for name, value := range h {
+		req.Meta[name] = append(req.Meta[name], value)
+	}
+	return ctx, nil
+}
+
+func (h headerInterceptor) After(ctx context.Context, resp *a2aclient.Response) error {
+	return nil
 }
 
 func newMessage(ctx agent.InvocationContext) (*a2a.Message, error) {
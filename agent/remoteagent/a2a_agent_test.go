@@ -23,8 +23,12 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2aclient"
@@ -39,11 +43,15 @@ import (
 	"google.golang.org/grpc/test/bufconn"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
 	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/server/adka2a"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
 )
 
 const connBufSize int = 1024 * 1024
@@ -628,3 +636,204 @@ func TestRemoteAgent_ErrorEventOnServerError(t *testing.T) {
 		t.Fatalf("event.ErrorMessage = %s, want to contain %q", gotEvents[0].ErrorMessage, executorErr.Error())
 	}
 }
+
+func TestRemoteAgent_HeadersAndCardCaching(t *testing.T) {
+	listener := bufconn.Listen(connBufSize)
+	var gotAuth []string
+	executor := &mockA2AExecutor{
+		executeFn: func(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+			if callCtx, ok := a2asrv.CallContextFrom(ctx); ok {
+				auth, _ := callCtx.RequestMeta().Get("Authorization")
+				gotAuth = append(gotAuth, auth...)
+			}
+			return queue.Write(ctx, a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: "Hello!"}))
+		},
+	}
+	go startA2AServer(t, executor, listener)
+
+	var cardRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/agent-card.json", func(w http.ResponseWriter, r *http.Request) {
+		cardRequests.Add(1)
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		card := &a2a.AgentCard{PreferredTransport: a2a.TransportProtocolGRPC, URL: "passthrough:///bufnet", Capabilities: a2a.AgentCapabilities{Streaming: true}}
+		if err := json.NewEncoder(w).Encode(card); err != nil {
+			t.Errorf("json.Encode(agentCard) error = %v", err)
+		}
+	})
+	cardServer := httptest.NewServer(mux)
+	t.Cleanup(cardServer.Close)
+
+	remoteAgent, err := NewA2A(A2AConfig{
+		Name:            "a2a",
+		AgentCardSource: cardServer.URL,
+		ClientFactory:   newTestClientFactory(listener),
+		Headers:         map[string]string{"Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("remoteagent.NewA2A() error = %v", err)
+	}
+
+	for range 2 {
+		ictx := newInvocationContext(t, []*session.Event{newUserHello()})
+		gotEvents, err := runAndCollect(ictx, remoteAgent)
+		if err != nil {
+			t.Fatalf("agent.Run() error = %v", err)
+		}
+		if len(gotEvents) != 1 || gotEvents[0].ErrorMessage != "" {
+			t.Fatalf("agent.Run() = %v, want a single response", gotEvents)
+		}
+	}
+
+	if got := cardRequests.Load(); got != 1 {
+		t.Errorf("agent card requests = %d, want 1", got)
+	}
+	if diff := cmp.Diff([]string{"Bearer token", "Bearer token"}, gotAuth); diff != "" {
+		t.Errorf("Authorization headers mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemoteAgent_Timeout(t *testing.T) {
+	listener := bufconn.Listen(connBufSize)
+	executor := &mockA2AExecutor{
+		executeFn: func(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	go startA2AServer(t, executor, listener)
+
+	card := &a2a.AgentCard{PreferredTransport: a2a.TransportProtocolGRPC, URL: "passthrough:///bufnet", Capabilities: a2a.AgentCapabilities{Streaming: true}}
+	remoteAgent, err := NewA2A(A2AConfig{
+		Name:          "a2a",
+		AgentCard:     card,
+		ClientFactory: newTestClientFactory(listener),
+		Timeout:       50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("remoteagent.NewA2A() error = %v", err)
+	}
+
+	ictx := newInvocationContext(t, []*session.Event{newUserHello()})
+	gotEvents, err := runAndCollect(ictx, remoteAgent)
+	if err != nil {
+		t.Fatalf("agent.Run() error = %v", err)
+	}
+	if len(gotEvents) != 1 {
+		t.Fatalf("len(events) = %d, want 1", len(gotEvents))
+	}
+	if !strings.Contains(strings.ToLower(gotEvents[0].ErrorMessage), "deadline") {
+		t.Fatalf("event.ErrorMessage = %s, want a deadline error", gotEvents[0].ErrorMessage)
+	}
+}
+
+func TestRemoteAgent_AgentCardFile(t *testing.T) {
+	listener := bufconn.Listen(connBufSize)
+	executor := newA2AEventReplay(t, []a2a.Event{a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Hello!"})})
+	go startA2AServer(t, executor, listener)
+
+	card := &a2a.AgentCard{PreferredTransport: a2a.TransportProtocolGRPC, URL: "passthrough:///bufnet", Capabilities: a2a.AgentCapabilities{Streaming: true}}
+	data, err := json.Marshal(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cardFile := filepath.Join(t.TempDir(), "agent-card.json")
+	if err := os.WriteFile(cardFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	remoteAgent, err := NewA2A(A2AConfig{Name: "a2a", AgentCardSource: cardFile, ClientFactory: newTestClientFactory(listener)})
+	if err != nil {
+		t.Fatalf("remoteagent.NewA2A() error = %v", err)
+	}
+
+	ictx := newInvocationContext(t, []*session.Event{newUserHello()})
+	gotEvents, err := runAndCollect(ictx, remoteAgent)
+	if err != nil {
+		t.Fatalf("agent.Run() error = %v", err)
+	}
+	wantResponses := []model.LLMResponse{{Content: genai.NewContentFromText("Hello!", genai.RoleModel)}}
+	if diff := cmp.Diff(wantResponses, toLLMResponses(gotEvents), cmpopts.IgnoreFields(model.LLMResponse{}, "CustomMetadata")); diff != "" {
+		t.Fatalf("agent.Run() wrong result (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemoteAgent_InAgentTree(t *testing.T) {
+	remoteEvents := []*session.Event{
+		{LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("remote answer", genai.RoleModel)}},
+	}
+
+	testCases := []struct {
+		name           string
+		newConfig      func(remote agent.Agent) llmagent.Config
+		responses      []*genai.Content
+		want           string
+		wantToolResult map[string]any
+	}{
+		{
+			name: "sub-agent",
+			newConfig: func(remote agent.Agent) llmagent.Config {
+				return llmagent.Config{Name: "root", SubAgents: []agent.Agent{remote}}
+			},
+			responses: []*genai.Content{
+				genai.NewContentFromFunctionCall("transfer_to_agent", map[string]any{"agent_name": "helper"}, genai.RoleModel),
+			},
+			want: "remote answer",
+		},
+		{
+			name: "agent tool",
+			newConfig: func(remote agent.Agent) llmagent.Config {
+				return llmagent.Config{Name: "root", Tools: []tool.Tool{agenttool.New(remote, nil)}}
+			},
+			responses: []*genai.Content{
+				genai.NewContentFromFunctionCall("helper", map[string]any{"request": "hello"}, genai.RoleModel),
+				genai.NewContentFromText("done", genai.RoleModel),
+			},
+			want:           "done",
+			wantToolResult: map[string]any{"result": "remote answer"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listener := bufconn.Listen(connBufSize)
+			go startA2AServer(t, newADKEventReplay(t, remoteEvents), listener)
+			remote := newA2ARemoteAgent(t, "helper", listener)
+
+			cfg := tc.newConfig(remote)
+			llm := &testutil.MockModel{Responses: tc.responses}
+			cfg.Model = llm
+			root, err := llmagent.New(cfg)
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			var gotText string
+			var gotToolResult map[string]any
+			for event, err := range testutil.NewTestAgentRunner(t, root).Run(t, "session", "hello") {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				if event.Content == nil {
+					continue
+				}
+				for _, part := range event.Content.Parts {
+					if part.Text != "" {
+						gotText = part.Text
+					}
+					if part.FunctionResponse != nil && part.FunctionResponse.Name == "helper" {
+						gotToolResult = part.FunctionResponse.Response
+					}
+				}
+			}
+			if gotText != tc.want {
+				t.Errorf("last text = %q, want %q", gotText, tc.want)
+			}
+			if diff := cmp.Diff(tc.wantToolResult, gotToolResult); diff != "" {
+				t.Errorf("tool result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@
 
 package agent
 
+import "time"
+
 // StreamingMode defines the streaming mode for agent execution.
 type StreamingMode string
 
@@ -32,4 +34,35 @@ type RunConfig struct {
 	// If true, ADK runner will save each part of the user input that is a blob
 	// (e.g., images, files) as an artifact.
 	SaveInputBlobsAsArtifacts bool
+	// Values are request-scoped values, e.g. tenant id or authenticated subject,
+	// made available to agents, tools and callbacks of the invocation.
+	// They can be read with the Value method of any ADK context, the same way
+	// as values attached with context.WithValue to the context passed to the runner.
+	// Keys should be of an unexported type to avoid collisions, nil keys make
+	// the run fail. Values are not persisted in the session.
+	Values map[any]any
+	// MaxLLMCalls limits the number of LLM calls made during a single
+	// invocation, protecting from runaway tool loops. The limit is shared by
+	// all agents of the invocation, including sub-agents run via transfer or
+	// agenttool. When exceeded, the invocation stops with an error wrapping
+	// model.ErrLLMCallsLimitExceeded.
+	// Zero means the default of 500 calls, a negative value disables the limit.
+	MaxLLMCalls int
+	// MaxTotalTokens limits the total number of tokens used by LLM calls
+	// during a single invocation. The budget is shared the same way as
+	// MaxLLMCalls. When exceeded, the invocation stops with an error wrapping
+	// model.ErrMaxTotalTokensExceeded. Zero means no limit.
+	MaxTotalTokens int64
+	// MaxDuration limits how long a single run may take. When exceeded, the
+	// run is canceled and the event iterator ends with an error wrapping
+	// context.DeadlineExceeded. Runs of sub-agents invoked via agenttool are
+	// bounded by the deadline of the parent run. Zero means no limit.
+	MaxDuration time.Duration
+	// MaxParallelToolCalls limits how many function calls of a single model
+	// response run concurrently. The function responses are sent to the model
+	// in the order of the calls whatever the limit. Runs of sub-agents invoked
+	// via agenttool use the limit of the parent run. Zero or a negative value
+	// means no limit, 1 runs the calls sequentially. Whatever the limit, the
+	// tool callbacks of the agents and the plugins are called one at a time.
+	MaxParallelToolCalls int
 }
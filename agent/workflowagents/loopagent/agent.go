@@ -33,6 +33,12 @@ type Config struct {
 	// If MaxIterations == 0, then LoopAgent runs indefinitely or until any
 	// sub-agent escalates.
 	MaxIterations uint
+
+	// StopCondition is an optional function called for each event yielded
+	// by the sub-agents. If it returns true, LoopAgent stops after yielding
+	// the event, the same way as when a sub-agent escalates (e.g. with the
+	// exit_loop tool from the exitlooptool package).
+	StopCondition func(*session.Event) bool
 }
 
 // New creates a LoopAgent.
@@ -42,6 +48,9 @@ type Config struct {
 //
 // Use the LoopAgent when your workflow involves repetition or iterative
 // refinement, such as like revising code.
+//
+// When the invocation context is canceled, the LoopAgent returns its error
+// before running the next sub-agent, so canceling stops infinite loops.
 func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Run != nil {
 		return nil, fmt.Errorf("LoopAgent doesn't allow custom Run implementations")
@@ -49,6 +58,7 @@ func New(cfg Config) (agent.Agent, error) {
 
 	loopAgentImpl := &loopAgent{
 		maxIterations: cfg.MaxIterations,
+		stopCondition: cfg.StopCondition,
 	}
 	cfg.AgentConfig.Run = loopAgentImpl.Run
 
@@ -70,6 +80,7 @@ func New(cfg Config) (agent.Agent, error) {
 
 type loopAgent struct {
 	maxIterations uint
+	stopCondition func(*session.Event) bool
 }
 
 func (a *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
@@ -79,13 +90,21 @@ func (a *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, e
 		for {
 			shouldExit := false
 			for _, subAgent := range ctx.Agent().SubAgents() {
+				// Stop between the sub-agents once the invocation is canceled.
+				if err := ctx.Err(); err != nil {
+					yield(nil, err)
+					return
+				}
 				for event, err := range subAgent.Run(ctx) {
 					// TODO: ensure consistency -- if there's an error, return and close iterator, verify everywhere in ADK.
 					if !yield(event, err) {
 						return
 					}
 
-					if event.Actions.Escalate {
+					if event == nil {
+						continue
+					}
+					if event.Actions.Escalate || (a.stopCondition != nil && a.stopCondition(event)) {
 						shouldExit = true
 					}
 				}
@@ -16,6 +16,7 @@ package loopagent_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"testing"
@@ -27,10 +28,12 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/exitlooptool"
 	"google.golang.org/adk/tool/functiontool"
 )
 
@@ -249,6 +252,49 @@ func TestNewLoopAgent(t *testing.T) {
 	}
 }
 
+func TestLoopAgent_Cancel(t *testing.T) {
+	custom := &customAgent{}
+	subAgent, err := agent.New(agent.Config{Name: "custom_agent", Run: custom.Run})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loopAgent, err := loopagent.New(loopagent.Config{
+		AgentConfig: agent.Config{Name: "loop_agent", SubAgents: []agent.Agent{subAgent}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "test_app", Agent: loopAgent, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "test_app", UserID: "user_id", SessionID: "session_id"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var lastErr error
+	for event, err := range r.Run(ctx, "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if event != nil && custom.callCounter == 3 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("last error = %v, want %v", lastErr, context.Canceled)
+	}
+	// The infinite loop stops before running the sub-agent again.
+	if custom.callCounter != 3 {
+		t.Errorf("sub-agent ran %d times, want 3", custom.callCounter)
+	}
+}
+
 func newCustomAgent(t *testing.T, id int) agent.Agent {
 	t.Helper()
 
@@ -357,3 +403,89 @@ func (f *FakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, st
 		}
 	}
 }
+
+func TestLoopAgent_ExitLoopTool(t *testing.T) {
+	exitLoopTool, err := exitlooptool.New()
+	if err != nil {
+		t.Fatalf("exitlooptool.New() error = %v", err)
+	}
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("iteration 1", genai.RoleModel),
+			genai.NewContentFromFunctionCall("exit_loop", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("iteration 3 should not be reached", genai.RoleModel),
+		},
+	}
+	worker, err := llmagent.New(llmagent.Config{
+		Name:  "worker",
+		Model: mockModel,
+		Tools: []tool.Tool{exitLoopTool},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	loopAgent, err := loopagent.New(loopagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "looper",
+			SubAgents: []agent.Agent{worker},
+		},
+		MaxIterations: 10,
+	})
+	if err != nil {
+		t.Fatalf("loopagent.New() error = %v", err)
+	}
+
+	events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, loopAgent).Run(t, "session", "start"))
+	if err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+
+	if got := len(mockModel.Requests); got != 2 {
+		t.Errorf("model was called %d times, want 2", got)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	if last := events[len(events)-1]; !last.Actions.Escalate {
+		t.Errorf("last event Actions.Escalate = false, want true")
+	}
+}
+
+func TestLoopAgent_StopCondition(t *testing.T) {
+	var iterations int
+	counter, err := agent.New(agent.Config{
+		Name: "counter",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				iterations++
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(fmt.Sprintf("count %d", iterations), genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	loopAgent, err := loopagent.New(loopagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "looper",
+			SubAgents: []agent.Agent{counter},
+		},
+		MaxIterations: 10,
+		StopCondition: func(event *session.Event) bool {
+			return event.Content != nil && event.Content.Parts[0].Text == "count 3"
+		},
+	})
+	if err != nil {
+		t.Fatalf("loopagent.New() error = %v", err)
+	}
+
+	texts, err := testutil.CollectTextParts(testutil.NewTestAgentRunner(t, loopAgent).Run(t, "session", "start"))
+	if err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"count 1", "count 2", "count 3"}, texts); diff != "" {
+		t.Errorf("unexpected events (-want +got):\n%s", diff)
+	}
+}
@@ -16,6 +16,7 @@
 package parallelagent
 
 import (
+	"context"
 	"fmt"
 	"iter"
 
@@ -27,10 +28,30 @@ import (
 	"google.golang.org/adk/session"
 )
 
+// ErrorPolicy defines how a ParallelAgent reacts to a sub-agent failure.
+type ErrorPolicy int
+
+const (
+	// FailFast cancels all running sub-agents as soon as one of them fails.
+	// Sub-agents which were not started yet are not run.
+	FailFast ErrorPolicy = iota
+	// ContinueOnError lets the other sub-agents run to completion when one
+	// of them fails. The failing sub-agent's error is yielded once and the
+	// events of the other sub-agents are still streamed.
+	ContinueOnError
+)
+
 // Config defines the configuration for a ParallelAgent.
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
+
+	// MaxConcurrency bounds the number of sub-agents running at the same time.
+	// Sub-agents are started in order as slots become free.
+	// Zero or negative value means all sub-agents are run at once.
+	MaxConcurrency int
+	// ErrorPolicy defines what happens when a sub-agent fails. Defaults to FailFast.
+	ErrorPolicy ErrorPolicy
 }
 
 // New creates a ParallelAgent.
@@ -41,12 +62,22 @@ type Config struct {
 // attempts on a single task, such as:
 // - Running different algorithms simultaneously.
 // - Generating multiple responses for review by a subsequent evaluation agent.
+//
+// Events of a single sub-agent are yielded in the order the sub-agent produced
+// them, while events of different sub-agents are interleaved in no particular
+// order. Each sub-agent runs in its own branch, so the sub-agents don't see
+// each other's events.
 func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Run != nil {
 		return nil, fmt.Errorf("ParallelAgent doesn't allow custom Run implementations")
 	}
+	if cfg.ErrorPolicy != FailFast && cfg.ErrorPolicy != ContinueOnError {
+		return nil, fmt.Errorf("unknown error policy: %d", cfg.ErrorPolicy)
+	}
 
-	cfg.AgentConfig.Run = run
+	cfg.AgentConfig.Run = func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+		return run(ctx, cfg.MaxConcurrency, cfg.ErrorPolicy)
+	}
 
 	parallelAgent, err := agent.New(cfg.AgentConfig)
 	if err != nil {
@@ -64,41 +95,62 @@ func New(cfg Config) (agent.Agent, error) {
 	return parallelAgent, nil
 }
 
-func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+func run(ctx agent.InvocationContext, maxConcurrency int, errorPolicy ErrorPolicy) iter.Seq2[*session.Event, error] {
 	curAgent := ctx.Agent()
 
 	var (
-		errGroup, errGroupCtx = errgroup.WithContext(ctx)
-		doneChan              = make(chan bool)
-		resultsChan           = make(chan result)
+		errGroup    *errgroup.Group
+		errGroupCtx context.Context = ctx
+		doneChan                    = make(chan bool)
+		resultsChan                 = make(chan result)
 	)
-
-	for _, sa := range ctx.Agent().SubAgents() {
-		branch := fmt.Sprintf("%s.%s", curAgent.Name(), sa.Name())
-		if ctx.Branch() != "" {
-			branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
-		}
-		subAgent := sa
-		errGroup.Go(func() error {
-			subCtx := icontext.NewInvocationContext(errGroupCtx, icontext.InvocationContextParams{
-				Artifacts:   ctx.Artifacts(),
-				Memory:      ctx.Memory(),
-				Session:     ctx.Session(),
-				Branch:      branch,
-				Agent:       subAgent,
-				UserContent: ctx.UserContent(),
-				RunConfig:   ctx.RunConfig(),
-			})
-
-			if err := runSubAgent(subCtx, subAgent, resultsChan, doneChan); err != nil {
-				return fmt.Errorf("failed to run sub-agent %q: %w", subAgent.Name(), err)
-			}
-
-			return nil
-		})
+	if errorPolicy == FailFast {
+		errGroup, errGroupCtx = errgroup.WithContext(ctx)
+	} else {
+		errGroup = &errgroup.Group{}
+	}
+	if maxConcurrency > 0 {
+		errGroup.SetLimit(maxConcurrency)
 	}
 
+	// Sub-agents are started from a separate goroutine, since with limited
+	// concurrency starting a sub-agent blocks until a running one finishes,
+	// which requires its events to be consumed.
 	go func() {
+	launch:
+		for _, sa := range ctx.Agent().SubAgents() {
+			select {
+			case <-doneChan:
+				break launch
+			case <-errGroupCtx.Done():
+				break launch
+			default:
+			}
+			branch := fmt.Sprintf("%s.%s", curAgent.Name(), sa.Name())
+			if ctx.Branch() != "" {
+				branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+			}
+			subAgent := sa
+			errGroup.Go(func() error {
+				subCtx := icontext.NewInvocationContext(errGroupCtx, icontext.InvocationContextParams{
+					Artifacts:    ctx.Artifacts(),
+					Memory:       ctx.Memory(),
+					Session:      ctx.Session(),
+					Branch:       branch,
+					Agent:        subAgent,
+					UserContent:  ctx.UserContent(),
+					RunConfig:    ctx.RunConfig(),
+					InvocationID: ctx.InvocationID(),
+				})
+
+				if err := runSubAgent(subCtx, subAgent, resultsChan, doneChan); err != nil {
+					return fmt.Errorf("failed to run sub-agent %q: %w", subAgent.Name(), err)
+				}
+
+				return nil
+			})
+		}
+
 		_ = errGroup.Wait() // this error is already sent to the user via iterator
 		close(resultsChan)
 	}()
@@ -16,10 +16,13 @@ package parallelagent_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	rand "math/rand/v2"
 	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,8 +30,11 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/agent/workflowagents/loopagent"
 	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
@@ -54,6 +60,7 @@ func TestNewParallelAgent(t *testing.T) {
 					for responseCount := 1; responseCount <= 2; responseCount++ {
 						res = append(res, &session.Event{
 							Author: fmt.Sprintf("sub%d", agentID),
+							Branch: fmt.Sprintf("test_agent.loop_agent_%d", agentID),
 							LLMResponse: model.LLMResponse{
 								Content: &genai.Content{
 									Parts: []*genai.Part{
@@ -161,6 +168,201 @@ func TestNewParallelAgent(t *testing.T) {
 	}
 }
 
+func TestParallelAgent_BranchIsolation(t *testing.T) {
+	modelA := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("output of agent_a run 1", genai.RoleModel),
+			genai.NewContentFromText("output of agent_a run 2", genai.RoleModel),
+		},
+	}
+	modelB := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("output of agent_b run 1", genai.RoleModel),
+			genai.NewContentFromText("output of agent_b run 2", genai.RoleModel),
+		},
+	}
+	agentA := must(llmagent.New(llmagent.Config{Name: "agent_a", Model: modelA}))
+	agentB := must(llmagent.New(llmagent.Config{Name: "agent_b", Model: modelB}))
+	parallelAgent := must(parallelagent.New(parallelagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "parallel",
+			SubAgents: []agent.Agent{agentA, agentB},
+		},
+	}))
+
+	testRunner := testutil.NewTestAgentRunner(t, parallelAgent)
+	// The second run sees the events of the first run in the session history.
+	for _, msg := range []string{"first request", "second request"} {
+		events, err := testutil.CollectEvents(testRunner.Run(t, "session", msg))
+		if err != nil {
+			t.Fatalf("agent run failed: %v", err)
+		}
+		for _, ev := range events {
+			if want := "parallel." + ev.Author; ev.Branch != want {
+				t.Errorf("event from %q has branch %q, want %q", ev.Author, ev.Branch, want)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		model       *testutil.MockModel
+		wantOwn     string
+		wantSibling string
+	}{
+		{model: modelA, wantOwn: "agent_a", wantSibling: "agent_b"},
+		{model: modelB, wantOwn: "agent_b", wantSibling: "agent_a"},
+	} {
+		if len(tc.model.Requests) != 2 {
+			t.Fatalf("%s model got %d requests, want 2", tc.wantOwn, len(tc.model.Requests))
+		}
+		var texts []string
+		for _, content := range tc.model.Requests[1].Contents {
+			for _, part := range content.Parts {
+				texts = append(texts, part.Text)
+			}
+		}
+		for _, text := range texts {
+			if strings.Contains(text, tc.wantSibling) {
+				t.Errorf("%s model request contains sibling output %q", tc.wantOwn, text)
+			}
+		}
+		if !slices.ContainsFunc(texts, func(s string) bool { return strings.Contains(s, "output of "+tc.wantOwn) }) {
+			t.Errorf("%s model request does not contain its own output, got %q", tc.wantOwn, texts)
+		}
+	}
+}
+
+func TestParallelAgent_ContinueOnError(t *testing.T) {
+	agentErr := fmt.Errorf("agent failed")
+	slowRun := func(id int) func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+		return func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for i := range 2 {
+					// Give the failing agent time to fail first.
+					select {
+					case <-ctx.Done():
+						yield(nil, ctx.Err())
+						return
+					case <-time.After(20 * time.Millisecond):
+					}
+					if !yield(&session.Event{
+						LLMResponse: model.LLMResponse{
+							Content: genai.NewContentFromText(fmt.Sprintf("hello %d.%d", id, i), genai.RoleModel),
+						},
+					}, nil) {
+						return
+					}
+				}
+			}
+		}
+	}
+
+	parallelAgent := must(parallelagent.New(parallelagent.Config{
+		AgentConfig: agent.Config{
+			Name: "test_agent",
+			SubAgents: []agent.Agent{
+				must(agent.New(agent.Config{Name: "sub1", Run: slowRun(1)})),
+				must(agent.New(agent.Config{Name: "error_agent", Run: customRun(-1, agentErr)})),
+				must(agent.New(agent.Config{Name: "sub2", Run: slowRun(2)})),
+			},
+		},
+		ErrorPolicy: parallelagent.ContinueOnError,
+	}))
+
+	var gotTexts []string
+	var gotErrs []error
+	for ev, err := range parallelAgent.Run(newInvocationContext(t, parallelAgent)) {
+		if err != nil {
+			gotErrs = append(gotErrs, err)
+			continue
+		}
+		gotTexts = append(gotTexts, ev.LLMResponse.Content.Parts[0].Text)
+	}
+
+	if len(gotErrs) != 1 || !errors.Is(gotErrs[0], agentErr) {
+		t.Errorf("got errors %v, want exactly one %v", gotErrs, agentErr)
+	}
+	// Events of a single sub-agent keep their order.
+	for _, id := range []int{1, 2} {
+		first := slices.Index(gotTexts, fmt.Sprintf("hello %d.0", id))
+		second := slices.Index(gotTexts, fmt.Sprintf("hello %d.1", id))
+		if first == -1 || second == -1 || first > second {
+			t.Errorf("events of sub%d are missing or out of order: %v", id, gotTexts)
+		}
+	}
+	if len(gotTexts) != 4 {
+		t.Errorf("got %d events, want 4: %v", len(gotTexts), gotTexts)
+	}
+}
+
+func TestParallelAgent_MaxConcurrency(t *testing.T) {
+	const numSubAgents, maxConcurrency = 5, 2
+	var running, maxRunning atomic.Int32
+
+	var subAgents []agent.Agent
+	for i := range numSubAgents {
+		subAgents = append(subAgents, must(agent.New(agent.Config{
+			Name: fmt.Sprintf("sub%d", i),
+			Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(yield func(*session.Event, error) bool) {
+					cur := running.Add(1)
+					defer running.Add(-1)
+					for {
+						prev := maxRunning.Load()
+						if cur <= prev || maxRunning.CompareAndSwap(prev, cur) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					yield(&session.Event{
+						LLMResponse: model.LLMResponse{
+							Content: genai.NewContentFromText(fmt.Sprintf("hello %d", i), genai.RoleModel),
+						},
+					}, nil)
+				}
+			},
+		})))
+	}
+
+	parallelAgent := must(parallelagent.New(parallelagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "test_agent",
+			SubAgents: subAgents,
+		},
+		MaxConcurrency: maxConcurrency,
+	}))
+
+	gotEvents := 0
+	for _, err := range parallelAgent.Run(newInvocationContext(t, parallelAgent)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		gotEvents++
+	}
+
+	if gotEvents != numSubAgents {
+		t.Errorf("got %d events, want %d", gotEvents, numSubAgents)
+	}
+	if got := maxRunning.Load(); got > maxConcurrency {
+		t.Errorf("%d sub-agents ran concurrently, want at most %d", got, maxConcurrency)
+	}
+}
+
+func TestNew_UnknownErrorPolicy(t *testing.T) {
+	_, err := parallelagent.New(parallelagent.Config{
+		AgentConfig: agent.Config{Name: "test_agent"},
+		ErrorPolicy: parallelagent.ErrorPolicy(42),
+	})
+	if err == nil {
+		t.Error("parallelagent.New() error = nil, want error")
+	}
+}
+
+func newInvocationContext(t *testing.T, a agent.Agent) agent.InvocationContext {
+	t.Helper()
+	return icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a})
+}
+
 // newParallelAgent creates parallel agent with 2 subagents emitting maxIterations events or infinitely if maxIterations==0.
 func newParallelAgent(t *testing.T, maxIterations uint, numSubAgents int, agentErr error) agent.Agent {
 	var subAgents []agent.Agent
@@ -17,10 +17,12 @@ package sequentialagent
 
 import (
 	"fmt"
+	"iter"
+	"strings"
 
 	"google.golang.org/adk/agent"
-	"google.golang.org/adk/agent/workflowagents/loopagent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/session"
 )
 
 // New creates a SequentialAgent.
@@ -29,13 +31,29 @@ import (
 //
 // Use the SequentialAgent when you want the execution to occur in a fixed,
 // strict order.
+//
+// If a sub-agent returns an error, the remaining sub-agents are skipped.
+// The same happens when a sub-agent escalates, unless StopOnEscalation is
+// set to false. When the invocation context is canceled, the agent returns
+// its error before running the next sub-agent.
 func New(cfg Config) (agent.Agent, error) {
-	sequentialAgent, err := loopagent.New(loopagent.Config{
-		AgentConfig:   cfg.AgentConfig,
-		MaxIterations: 1,
-	})
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("SequentialAgent doesn't allow custom Run implementations")
+	}
+	if len(cfg.OutputKeys) > 0 && len(cfg.OutputKeys) != len(cfg.AgentConfig.SubAgents) {
+		return nil, fmt.Errorf("OutputKeys must be aligned with SubAgents: got %d output keys for %d sub-agents", len(cfg.OutputKeys), len(cfg.AgentConfig.SubAgents))
+	}
+
+	seqAgentImpl := &sequentialAgent{
+		stopOnEscalation: cfg.StopOnEscalation == nil || *cfg.StopOnEscalation,
+		outputKeys:       cfg.OutputKeys,
+	}
+	agentCfg := cfg.AgentConfig
+	agentCfg.Run = seqAgentImpl.Run
+
+	sequentialAgent, err := agent.New(agentCfg)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create base agent: %w", err)
 	}
 
 	internalAgent, ok := sequentialAgent.(agentinternal.Agent)
@@ -53,4 +71,77 @@ func New(cfg Config) (agent.Agent, error) {
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
+
+	// StopOnEscalation defines whether the remaining sub-agents are skipped
+	// when a sub-agent escalates. Defaults to true when nil.
+	StopOnEscalation *bool
+
+	// OutputKeys are optional session state keys aligned with
+	// AgentConfig.SubAgents. If the key of a sub-agent is not empty, the text
+	// of the sub-agent's final response is saved under it, so later
+	// sub-agents can use it, e.g. with {key} placeholders in instructions.
+	OutputKeys []string
+}
+
+type sequentialAgent struct {
+	stopOnEscalation bool
+	outputKeys       []string
+}
+
+func (a *sequentialAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		for i, subAgent := range ctx.Agent().SubAgents() {
+			// Stop between the sub-agents once the invocation is canceled.
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			outputKey := ""
+			if i < len(a.outputKeys) {
+				outputKey = a.outputKeys[i]
+			}
+
+			shouldStop := false
+			for event, err := range subAgent.Run(ctx) {
+				if err != nil {
+					yield(event, err)
+					return
+				}
+				if event != nil {
+					if outputKey != "" {
+						saveOutputToState(event, outputKey)
+					}
+					if event.Actions.Escalate && a.stopOnEscalation {
+						shouldStop = true
+					}
+				}
+				if !yield(event, nil) {
+					return
+				}
+			}
+			if shouldStop {
+				return
+			}
+		}
+	}
+}
+
+// saveOutputToState saves the text of a final response event under the key.
+func saveOutputToState(event *session.Event, key string) {
+	if !event.IsFinalResponse() || event.Content == nil {
+		return
+	}
+	var sb strings.Builder
+	for _, part := range event.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return
+	}
+	if event.Actions.StateDelta == nil {
+		event.Actions.StateDelta = make(map[string]any)
+	}
+	event.Actions.StateDelta[key] = sb.String()
 }
@@ -16,6 +16,7 @@ package sequentialagent_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"testing"
@@ -149,7 +150,7 @@ func TestNewSequentialAgent(t *testing.T) {
 				}, "test_agent"), newCustomAgent(t, 3)},
 			},
 			wantErr:        true,
-			wantErrMessage: `failed to create agent tree: agent names must be unique in the agent tree, found duplicate: "test_agent"`,
+			wantErrMessage: `failed to create agent tree: agent names must be unique in the agent tree, found duplicate: "test_agent", used by the root agent and a sub-agent of "test_agent"`,
 		},
 		{
 			name: "err with 2 levels of inner sequential with same name as parent ",
@@ -160,7 +161,7 @@ func TestNewSequentialAgent(t *testing.T) {
 				}, "test_agent1"), newCustomAgent(t, 3)},
 			},
 			wantErr:        true,
-			wantErrMessage: `failed to create agent tree: agent names must be unique in the agent tree, found duplicate: "test_agent1"`,
+			wantErrMessage: `failed to create agent tree: agent names must be unique in the agent tree, found duplicate: "test_agent1", used by sub-agents of "test_agent" and "test_agent1"`,
 		},
 		{
 			name: "err with repeated inner sequential",
@@ -263,6 +264,251 @@ func TestNewSequentialAgent(t *testing.T) {
 	}
 }
 
+func TestSequentialAgent_StopOnEscalation(t *testing.T) {
+	tests := []struct {
+		name             string
+		stopOnEscalation *bool
+		wantRuns         []string
+	}{
+		{
+			name:     "stops by default",
+			wantRuns: []string{"step_0", "step_1"},
+		},
+		{
+			name:             "continues when disabled",
+			stopOnEscalation: new(bool),
+			wantRuns:         []string{"step_0", "step_1", "step_2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRuns []string
+			record := func(name string) { gotRuns = append(gotRuns, name) }
+
+			seqAgent, err := sequentialagent.New(sequentialagent.Config{
+				AgentConfig: agent.Config{
+					Name: "test_agent",
+					SubAgents: []agent.Agent{
+						newStepAgent(t, "step_0", record, false, nil),
+						newStepAgent(t, "step_1", record, true, nil),
+						newStepAgent(t, "step_2", record, false, nil),
+					},
+				},
+				StopOnEscalation: tt.stopOnEscalation,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, err := range runAgent(t, seqAgent) {
+				if err != nil {
+					t.Fatalf("got unexpected error: %v", err)
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantRuns, gotRuns); diff != "" {
+				t.Errorf("sub-agent runs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSequentialAgent_ErrorSkipsRemainingSteps(t *testing.T) {
+	var gotRuns []string
+	record := func(name string) { gotRuns = append(gotRuns, name) }
+	wantErr := errors.New("step failed")
+
+	seqAgent, err := sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{
+			Name: "test_agent",
+			SubAgents: []agent.Agent{
+				newStepAgent(t, "step_0", record, false, wantErr),
+				newStepAgent(t, "step_1", record, false, nil),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotErr error
+	for _, err := range runAgent(t, seqAgent) {
+		if err != nil {
+			gotErr = err
+		}
+	}
+
+	if !errors.Is(gotErr, wantErr) {
+		t.Errorf("got error %v, want %v", gotErr, wantErr)
+	}
+	if diff := cmp.Diff([]string{"step_0"}, gotRuns); diff != "" {
+		t.Errorf("sub-agent runs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSequentialAgent_OutputKeys(t *testing.T) {
+	reader, err := llmagent.New(llmagent.Config{
+		Name:        "reader",
+		Model:       &FakeLLM{id: 1},
+		Instruction: "Previous output: {first_output}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqAgent, err := sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "test_agent",
+			SubAgents: []agent.Agent{newCustomAgent(t, 0), reader},
+		},
+		OutputKeys: []string{"first_output", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	agentRunner, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          seqAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range agentRunner.Run(t.Context(), "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+	}
+
+	resp, err := sessionService.Get(t.Context(), &session.GetRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := resp.Session.State().Get("first_output")
+	if err != nil {
+		t.Fatalf("State().Get() error = %v", err)
+	}
+	if got != "hello 0" {
+		t.Errorf("state[first_output] = %v, want %q", got, "hello 0")
+	}
+}
+
+func TestSequentialAgent_Cancel(t *testing.T) {
+	var ran []string
+	record := func(name string) { ran = append(ran, name) }
+	a := newSequentialAgent(t, []agent.Agent{
+		newStepAgent(t, "first", record, false, nil),
+		newStepAgent(t, "second", record, false, nil),
+	}, "test_agent")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var events int
+	var lastErr error
+	for event, err := range runAgentWithContext(t, ctx, a) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if event != nil {
+			events++
+			// Cancel the invocation after the first sub-agent responds.
+			cancel()
+		}
+	}
+
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("last error = %v, want %v", lastErr, context.Canceled)
+	}
+	if events != 1 {
+		t.Errorf("got %d events, want 1", events)
+	}
+	if diff := cmp.Diff([]string{"first"}, ran); diff != "" {
+		t.Errorf("sub-agents run mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_MismatchedOutputKeys(t *testing.T) {
+	_, err := sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "test_agent",
+			SubAgents: []agent.Agent{newCustomAgent(t, 0), newCustomAgent(t, 1)},
+		},
+		OutputKeys: []string{"only_one"},
+	})
+	if err == nil {
+		t.Error("sequentialagent.New() error = nil, want error")
+	}
+}
+
+// newStepAgent creates an agent which records its run and emits a single event,
+// optionally escalating, or returns the given error.
+func newStepAgent(t *testing.T, name string, record func(string), escalate bool, runErr error) agent.Agent {
+	t.Helper()
+
+	a, err := agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				record(name)
+				if runErr != nil {
+					yield(nil, runErr)
+					return
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = name
+				event.Content = genai.NewContentFromText("done", genai.RoleModel)
+				event.Actions.Escalate = escalate
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func runAgent(t *testing.T, a agent.Agent) iter.Seq2[*session.Event, error] {
+	t.Helper()
+	return runAgentWithContext(t, t.Context(), a)
+}
+
+func runAgentWithContext(t *testing.T, ctx context.Context, a agent.Agent) iter.Seq2[*session.Event, error] {
+	t.Helper()
+
+	sessionService := session.InMemoryService()
+	agentRunner, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          a,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return agentRunner.Run(ctx, "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{})
+}
+
 func newCustomAgent(t *testing.T, id int) agent.Agent {
 	t.Helper()
 
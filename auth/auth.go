@@ -0,0 +1,277 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth defines the credentials which tools can request from the
+// client, and the adk_request_credential function call used to request them.
+//
+// A tool describes the credential it needs with a Config, and calls
+// tool.Context.RequestCredential if tool.Context.Credential doesn't return
+// one yet. The agent then ends the invocation with an
+// adk_request_credential function call, which the client, e.g. the Web UI,
+// answers with a function response carrying the Config with the Credential
+// set. In the next invocation the tool is called again, and
+// tool.Context.Credential returns the credential.
+//
+// The API key and HTTP (bearer token and basic) schemes are supported.
+// OAuth2 credentials must carry an access token, as authorization codes
+// and service account keys aren't exchanged yet.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RequestCredentialFunctionName is the name of the function call by which the
+// agent requests a credential from the client. The arguments of the call are
+// RequestCredentialArgs, and the client answers with a function response
+// with the same name and ID, whose response is the Config with Credential set.
+const RequestCredentialFunctionName = "adk_request_credential"
+
+// RequestCredentialArgs are the arguments of the adk_request_credential
+// function call.
+type RequestCredentialArgs struct {
+	// FunctionCallID is the ID of the tool call which requested the credential.
+	FunctionCallID string `json:"functionCallId"`
+	// Config describes the requested credential.
+	Config *Config `json:"authConfig"`
+}
+
+// Config describes the credential required by a tool.
+type Config struct {
+	// Scheme describes how the tool authenticates to its service.
+	Scheme *Scheme `json:"authScheme"`
+	// RawCredential is the part of the credential the tool knows before the
+	// client is involved, e.g. the OAuth2 client ID. Optional.
+	RawCredential *Credential `json:"rawAuthCredential,omitempty"`
+	// Credential is the credential provided by the client.
+	Credential *Credential `json:"exchangedAuthCredential,omitempty"`
+	// CredentialKey identifies the credential in the session. If empty, it's
+	// derived from Scheme and RawCredential, see Key.
+	CredentialKey string `json:"credentialKey,omitempty"`
+}
+
+// Key returns the key identifying the credential in the session, which is
+// CredentialKey if set.
+func (c *Config) Key() string {
+	if c.CredentialKey != "" {
+		return c.CredentialKey
+	}
+	key := "adk"
+	if c.Scheme != nil {
+		key += "_" + string(c.Scheme.Type) + "_" + hash(c.Scheme)
+	}
+	if c.RawCredential != nil {
+		key += "_" + string(c.RawCredential.Type) + "_" + hash(c.RawCredential)
+	}
+	return key
+}
+
+func hash(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ValidateCredential checks that Credential is set and can be used with Scheme.
+func (c *Config) ValidateCredential() error {
+	if c.Scheme == nil {
+		return errors.New("auth scheme is not set")
+	}
+	cred := c.Credential
+	if cred == nil {
+		return errors.New("credential is not set")
+	}
+	switch c.Scheme.Type {
+	case SchemeTypeAPIKey:
+		if cred.APIKey == "" {
+			return errors.New("API key is not set")
+		}
+	case SchemeTypeHTTP:
+		if cred.HTTP == nil {
+			return errors.New("HTTP credential is not set")
+		}
+		switch strings.ToLower(c.Scheme.Scheme) {
+		case "bearer":
+			if cred.HTTP.Credentials.Token == "" {
+				return errors.New("bearer token is not set")
+			}
+		case "basic":
+			if cred.HTTP.Credentials.Username == "" {
+				return errors.New("username is not set")
+			}
+		default:
+			return fmt.Errorf("unsupported HTTP auth scheme %q", c.Scheme.Scheme)
+		}
+	case SchemeTypeOAuth2, SchemeTypeOpenIDConnect:
+		if cred.OAuth2 == nil || cred.OAuth2.AccessToken == "" {
+			return errors.New("OAuth2 access token is not set, exchanging authorization codes and service account keys isn't supported yet")
+		}
+	default:
+		return fmt.Errorf("unsupported auth scheme type %q", c.Scheme.Type)
+	}
+	return nil
+}
+
+// SchemeType is the type of an authentication scheme.
+type SchemeType string
+
+// The types of the authentication schemes, as in OpenAPI security schemes.
+const (
+	SchemeTypeAPIKey        SchemeType = "apiKey"
+	SchemeTypeHTTP          SchemeType = "http"
+	SchemeTypeOAuth2        SchemeType = "oauth2"
+	SchemeTypeOpenIDConnect SchemeType = "openIdConnect"
+)
+
+// Scheme describes how a tool authenticates to its service. It follows the
+// OpenAPI security scheme object.
+type Scheme struct {
+	// Type is the type of the scheme.
+	Type SchemeType `json:"type"`
+	// Description describes the scheme to the user.
+	Description string `json:"description,omitempty"`
+	// Name is the name of the header, query parameter or cookie carrying the
+	// API key. Only for the apiKey schemes.
+	Name string `json:"name,omitempty"`
+	// In is the location of the API key: "header", "query" or "cookie".
+	// Only for the apiKey schemes.
+	In string `json:"in,omitempty"`
+	// Scheme is the HTTP authorization scheme, "bearer" or "basic".
+	// Only for the http schemes.
+	Scheme string `json:"scheme,omitempty"`
+	// BearerFormat hints the format of the bearer token, e.g. "JWT".
+	// Only for the http schemes.
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	// Flows describes the OAuth2 flows. Only for the oauth2 schemes.
+	Flows *OAuthFlows `json:"flows,omitempty"`
+	// OpenIDConnectURL is the URL of the OpenID Connect discovery document.
+	// Only for the openIdConnect schemes.
+	OpenIDConnectURL string `json:"openIdConnectUrl,omitempty"`
+}
+
+// OAuthFlows describes the OAuth2 flows supported by a scheme.
+type OAuthFlows struct {
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+}
+
+// OAuthFlow describes an OAuth2 flow.
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"`
+}
+
+// Apply adds the credential to the request according to the scheme.
+func (s *Scheme) Apply(req *http.Request, cred *Credential) error {
+	if err := (&Config{Scheme: s, Credential: cred}).ValidateCredential(); err != nil {
+		return err
+	}
+	switch s.Type {
+	case SchemeTypeAPIKey:
+		switch strings.ToLower(s.In) {
+		case "header":
+			req.Header.Set(s.Name, cred.APIKey)
+		case "query":
+			query := req.URL.Query()
+			query.Set(s.Name, cred.APIKey)
+			req.URL.RawQuery = query.Encode()
+		case "cookie":
+			req.AddCookie(&http.Cookie{Name: s.Name, Value: cred.APIKey})
+		default:
+			return fmt.Errorf("unsupported API key location %q", s.In)
+		}
+	case SchemeTypeHTTP:
+		if strings.EqualFold(s.Scheme, "basic") {
+			req.SetBasicAuth(cred.HTTP.Credentials.Username, cred.HTTP.Credentials.Password)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+cred.HTTP.Credentials.Token)
+		}
+	case SchemeTypeOAuth2, SchemeTypeOpenIDConnect:
+		req.Header.Set("Authorization", "Bearer "+cred.OAuth2.AccessToken)
+	}
+	return nil
+}
+
+// CredentialType is the type of a credential.
+type CredentialType string
+
+// The types of the credentials.
+const (
+	CredentialTypeAPIKey         CredentialType = "apiKey"
+	CredentialTypeHTTP           CredentialType = "http"
+	CredentialTypeOAuth2         CredentialType = "oauth2"
+	CredentialTypeOpenIDConnect  CredentialType = "openIdConnect"
+	CredentialTypeServiceAccount CredentialType = "serviceAccount"
+)
+
+// Credential holds the secrets used to authenticate. The field matching Type
+// is set.
+type Credential struct {
+	Type           CredentialType            `json:"authType"`
+	APIKey         string                    `json:"apiKey,omitempty"`
+	HTTP           *HTTPCredential           `json:"http,omitempty"`
+	OAuth2         *OAuth2Credential         `json:"oauth2,omitempty"`
+	ServiceAccount *ServiceAccountCredential `json:"serviceAccount,omitempty"`
+}
+
+// HTTPCredential is a credential of the HTTP authentication schemes.
+type HTTPCredential struct {
+	// Scheme is the HTTP authorization scheme, "bearer" or "basic".
+	Scheme      string          `json:"scheme"`
+	Credentials HTTPCredentials `json:"credentials"`
+}
+
+// HTTPCredentials are the secrets of a HTTPCredential: the token for the
+// bearer scheme, the username and password for the basic scheme.
+type HTTPCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// OAuth2Credential is a credential of the OAuth2 and OpenID Connect schemes.
+type OAuth2Credential struct {
+	ClientID        string `json:"clientId,omitempty"`
+	ClientSecret    string `json:"clientSecret,omitempty"`
+	AuthURI         string `json:"authUri,omitempty"`
+	State           string `json:"state,omitempty"`
+	RedirectURI     string `json:"redirectUri,omitempty"`
+	AuthResponseURI string `json:"authResponseUri,omitempty"`
+	AuthCode        string `json:"authCode,omitempty"`
+	AccessToken     string `json:"accessToken,omitempty"`
+	RefreshToken    string `json:"refreshToken,omitempty"`
+	// ExpiresAt is the expiry time of the access token in seconds since the
+	// Unix epoch.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// ServiceAccountCredential is a Google Cloud service account, used to
+// obtain OAuth2 access tokens.
+type ServiceAccountCredential struct {
+	// Key is the JSON key of the service account.
+	Key map[string]any `json:"serviceAccountCredential,omitempty"`
+	// Scopes are the OAuth2 scopes of the access tokens.
+	Scopes []string `json:"scopes,omitempty"`
+	// UseDefaultCredential uses the Application Default Credentials instead
+	// of Key.
+	UseDefaultCredential bool `json:"useDefaultCredential,omitempty"`
+}
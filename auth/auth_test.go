@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/auth"
+)
+
+func TestConfig_Key(t *testing.T) {
+	apiKey := &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "header", Name: "X-API-Key"}}
+	otherAPIKey := &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "query", Name: "key"}}
+	withCredential := &auth.Config{
+		Scheme:     apiKey.Scheme,
+		Credential: &auth.Credential{Type: auth.CredentialTypeAPIKey, APIKey: "secret"},
+	}
+	withRawCredential := &auth.Config{
+		Scheme:        &auth.Scheme{Type: auth.SchemeTypeOAuth2},
+		RawCredential: &auth.Credential{Type: auth.CredentialTypeOAuth2, OAuth2: &auth.OAuth2Credential{ClientID: "client"}},
+	}
+
+	if !strings.HasPrefix(apiKey.Key(), "adk_apiKey_") {
+		t.Errorf("Key() = %q, want it to start with adk_apiKey_", apiKey.Key())
+	}
+	if apiKey.Key() == otherAPIKey.Key() {
+		t.Errorf("Key() = %q for different schemes", apiKey.Key())
+	}
+	if apiKey.Key() != withCredential.Key() {
+		t.Errorf("Key() = %q, want %q: the provided credential doesn't change the key", withCredential.Key(), apiKey.Key())
+	}
+	if !strings.Contains(withRawCredential.Key(), "_oauth2_") || strings.Count(withRawCredential.Key(), "_") != 4 {
+		t.Errorf("Key() = %q, want the scheme and the raw credential", withRawCredential.Key())
+	}
+	if got := (&auth.Config{Scheme: apiKey.Scheme, CredentialKey: "weather_api_key"}).Key(); got != "weather_api_key" {
+		t.Errorf("Key() = %q, want CredentialKey", got)
+	}
+}
+
+func TestRequestCredentialArgs_JSON(t *testing.T) {
+	args := auth.RequestCredentialArgs{
+		FunctionCallID: "call1",
+		Config: &auth.Config{
+			Scheme: &auth.Scheme{Type: auth.SchemeTypeHTTP, Scheme: "bearer"},
+			Credential: &auth.Credential{
+				Type: auth.CredentialTypeHTTP,
+				HTTP: &auth.HTTPCredential{Scheme: "bearer", Credentials: auth.HTTPCredentials{Token: "token"}},
+			},
+		},
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"functionCallId":"call1","authConfig":{"authScheme":{"type":"http","scheme":"bearer"},` +
+		`"exchangedAuthCredential":{"authType":"http","http":{"scheme":"bearer","credentials":{"token":"token"}}}}}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var got auth.RequestCredentialArgs
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(args, got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfig_ValidateCredential(t *testing.T) {
+	apiKey := &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "header", Name: "X-API-Key"}
+	bearer := &auth.Scheme{Type: auth.SchemeTypeHTTP, Scheme: "bearer"}
+	oauth2 := &auth.Scheme{Type: auth.SchemeTypeOAuth2}
+	tests := []struct {
+		name    string
+		cfg     *auth.Config
+		wantErr string
+	}{
+		{
+			name: "api key",
+			cfg:  &auth.Config{Scheme: apiKey, Credential: &auth.Credential{Type: auth.CredentialTypeAPIKey, APIKey: "key"}},
+		},
+		{
+			name: "bearer token",
+			cfg: &auth.Config{Scheme: bearer, Credential: &auth.Credential{
+				Type: auth.CredentialTypeHTTP,
+				HTTP: &auth.HTTPCredential{Scheme: "bearer", Credentials: auth.HTTPCredentials{Token: "token"}},
+			}},
+		},
+		{
+			name: "oauth2 access token",
+			cfg: &auth.Config{Scheme: oauth2, Credential: &auth.Credential{
+				Type:   auth.CredentialTypeOAuth2,
+				OAuth2: &auth.OAuth2Credential{AccessToken: "token"},
+			}},
+		},
+		{
+			name:    "no scheme",
+			cfg:     &auth.Config{Credential: &auth.Credential{APIKey: "key"}},
+			wantErr: "auth scheme is not set",
+		},
+		{
+			name:    "no credential",
+			cfg:     &auth.Config{Scheme: apiKey},
+			wantErr: "credential is not set",
+		},
+		{
+			name:    "empty api key",
+			cfg:     &auth.Config{Scheme: apiKey, Credential: &auth.Credential{Type: auth.CredentialTypeAPIKey}},
+			wantErr: "API key is not set",
+		},
+		{
+			name:    "bearer scheme with api key",
+			cfg:     &auth.Config{Scheme: bearer, Credential: &auth.Credential{Type: auth.CredentialTypeAPIKey, APIKey: "key"}},
+			wantErr: "HTTP credential is not set",
+		},
+		{
+			name: "oauth2 authorization code",
+			cfg: &auth.Config{Scheme: oauth2, Credential: &auth.Credential{
+				Type:   auth.CredentialTypeOAuth2,
+				OAuth2: &auth.OAuth2Credential{AuthCode: "code"},
+			}},
+			wantErr: "access token is not set",
+		},
+		{
+			name: "unsupported http scheme",
+			cfg: &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeTypeHTTP, Scheme: "digest"}, Credential: &auth.Credential{
+				Type: auth.CredentialTypeHTTP,
+				HTTP: &auth.HTTPCredential{Scheme: "digest"},
+			}},
+			wantErr: `unsupported HTTP auth scheme "digest"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.ValidateCredential()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCredential() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateCredential() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheme_Apply(t *testing.T) {
+	apiKeyCredential := &auth.Credential{Type: auth.CredentialTypeAPIKey, APIKey: "secret"}
+	tests := []struct {
+		name       string
+		scheme     *auth.Scheme
+		cred       *auth.Credential
+		wantHeader http.Header
+		wantURL    string
+	}{
+		{
+			name:       "api key in header",
+			scheme:     &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "header", Name: "X-API-Key"},
+			cred:       apiKeyCredential,
+			wantHeader: http.Header{"X-Api-Key": {"secret"}},
+			wantURL:    "https://example.com/weather?city=london",
+		},
+		{
+			name:       "api key in query",
+			scheme:     &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "query", Name: "key"},
+			cred:       apiKeyCredential,
+			wantHeader: http.Header{},
+			wantURL:    "https://example.com/weather?city=london&key=secret",
+		},
+		{
+			name:       "api key in cookie",
+			scheme:     &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "cookie", Name: "key"},
+			cred:       apiKeyCredential,
+			wantHeader: http.Header{"Cookie": {"key=secret"}},
+			wantURL:    "https://example.com/weather?city=london",
+		},
+		{
+			name:   "bearer token",
+			scheme: &auth.Scheme{Type: auth.SchemeTypeHTTP, Scheme: "bearer"},
+			cred: &auth.Credential{
+				Type: auth.CredentialTypeHTTP,
+				HTTP: &auth.HTTPCredential{Scheme: "bearer", Credentials: auth.HTTPCredentials{Token: "token"}},
+			},
+			wantHeader: http.Header{"Authorization": {"Bearer token"}},
+			wantURL:    "https://example.com/weather?city=london",
+		},
+		{
+			name:   "basic",
+			scheme: &auth.Scheme{Type: auth.SchemeTypeHTTP, Scheme: "basic"},
+			cred: &auth.Credential{
+				Type: auth.CredentialTypeHTTP,
+				HTTP: &auth.HTTPCredential{Scheme: "basic", Credentials: auth.HTTPCredentials{Username: "user", Password: "pass"}},
+			},
+			wantHeader: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			wantURL:    "https://example.com/weather?city=london",
+		},
+		{
+			name:   "oauth2",
+			scheme: &auth.Scheme{Type: auth.SchemeTypeOAuth2},
+			cred: &auth.Credential{
+				Type:   auth.CredentialTypeOAuth2,
+				OAuth2: &auth.OAuth2Credential{AccessToken: "token"},
+			},
+			wantHeader: http.Header{"Authorization": {"Bearer token"}},
+			wantURL:    "https://example.com/weather?city=london",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://example.com/weather?city=london", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.scheme.Apply(req, tt.cred); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantHeader, req.Header); diff != "" {
+				t.Errorf("header mismatch (-want +got):\n%s", diff)
+			}
+			if got := req.URL.String(); got != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+
+	t.Run("invalid credential", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://example.com/weather", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scheme := &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "header", Name: "X-API-Key"}
+		if err := scheme.Apply(req, &auth.Credential{Type: auth.CredentialTypeAPIKey}); err == nil {
+			t.Error("Apply() succeeded, want error")
+		}
+	})
+}
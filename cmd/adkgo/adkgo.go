@@ -17,6 +17,8 @@ package main
 
 import (
 	_ "google.golang.org/adk/cmd/adkgo/internal/deploy/cloudrun"
+	_ "google.golang.org/adk/cmd/adkgo/internal/deploy/kubernetes"
+	_ "google.golang.org/adk/cmd/adkgo/internal/evalcmd"
 	"google.golang.org/adk/cmd/adkgo/internal/root"
 )
 
@@ -0,0 +1,182 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/adk/internal/cli/util"
+)
+
+// DefaultBaseImage is the container base image used for the compiled server.
+const DefaultBaseImage = "gcr.io/distroless/static-debian11"
+
+// Default target platform of the compiled server.
+const (
+	DefaultGOOS   = "linux"
+	DefaultGOARCH = "amd64"
+)
+
+// supportedGOARCH lists the architectures for which the default base image is published.
+var supportedGOARCH = []string{"amd64", "arm64", "arm", "ppc64le", "s390x"}
+
+// Platform is the target platform of the compiled server.
+type Platform struct {
+	GOOS   string
+	GOARCH string
+}
+
+// Validate checks that the server can be compiled for the platform and run
+// in a container based on the default base image.
+func (p Platform) Validate() error {
+	if p.GOOS != "linux" {
+		return fmt.Errorf("unsupported GOOS %q: containers require \"linux\"", p.GOOS)
+	}
+	if !slices.Contains(supportedGOARCH, p.GOARCH) {
+		return fmt.Errorf("unsupported GOARCH %q: supported values are %s", p.GOARCH, strings.Join(supportedGOARCH, ", "))
+	}
+	return nil
+}
+
+// String returns the platform in the OCI format used by docker, e.g. "linux/arm64".
+func (p Platform) String() string {
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// ServerFlags describe how the compiled server is started inside a container.
+type ServerFlags struct {
+	ExecFile   string // name of the executable copied to /app
+	ServerPort int
+	BaseImage  string   // defaults to DefaultBaseImage
+	Platform   Platform // platform of the base image, defaults to the platform of the build host
+
+	API          bool
+	WebUIAddress string // passed to api as -webui_address
+
+	A2A         bool
+	A2AAgentURL string // passed to a2a as --a2a_agent_url
+
+	WebUI            bool
+	APIServerAddress string // passed to webui as --api_server_address
+}
+
+// CompileEntryPoint builds a statically linked server executable for the
+// platform from the entry point located in srcBasePath.
+func CompileEntryPoint(p util.Printer, srcBasePath, entryPointPath, execPath string, platform Platform) error {
+	p("Using", entryPointPath, "as entry point, building for", platform.String())
+	// for help on ldflags you can run go build -ldflags="--help" ./examples/quickstart/main.go
+	//    -s    disable symbol table
+	//    -w    disable DWARF generation
+	//   using those flags reduces the size of an executable
+	cmd := exec.Command("go", "build", "-ldflags", "-s -w", "-o", execPath, entryPointPath)
+
+	cmd.Dir = srcBasePath
+	// build using staticallly linked libs, for the target platform
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS="+platform.GOOS, "GOARCH="+platform.GOARCH)
+	if err := util.LogCommand(cmd, p); err != nil {
+		return err
+	}
+	return checkExecutable(execPath, entryPointPath)
+}
+
+// checkExecutable checks that go build produced an executable, so that a
+// broken image isn't deployed.
+func checkExecutable(execPath, entryPointPath string) error {
+	f, err := os.Open(execPath)
+	if err != nil {
+		return fmt.Errorf("go build didn't produce the server executable: %w", err)
+	}
+	defer f.Close()
+	header := make([]byte, len(archiveHeader))
+	n, err := io.ReadFull(f, header)
+	switch {
+	case n == 0:
+		return fmt.Errorf("go build produced an empty server executable %s", execPath)
+	case err == nil && string(header) == archiveHeader:
+		// go build writes a package archive for non-main packages.
+		return fmt.Errorf("go build didn't produce an executable: entry point %s is not in a main package", entryPointPath)
+	}
+	return nil
+}
+
+// archiveHeader starts the package archives written by go build.
+const archiveHeader = "!<arch>\n"
+
+// Dockerfile returns the content of a Dockerfile running the compiled server.
+func Dockerfile(f ServerFlags) string {
+	baseImage := f.BaseImage
+	if baseImage == "" {
+		baseImage = DefaultBaseImage
+	}
+
+	from := baseImage
+	if f.Platform != (Platform{}) {
+		from = "--platform=" + f.Platform.String() + " " + baseImage
+	}
+
+	return `
+FROM ` + from + `
+
+COPY ` + f.ExecFile + `  /app/` + f.ExecFile + `
+EXPOSE ` + strconv.Itoa(f.ServerPort) + `
+# Command to run the executable when the container starts
+CMD ` + execForm(f.command()) + "\n"
+}
+
+// command returns the command line starting the server in the container.
+func (f ServerFlags) command() []string {
+	args := []string{"/app/" + f.ExecFile, "web", "-port", strconv.Itoa(f.ServerPort)}
+	if f.API {
+		args = append(args, "api", "-webui_address", f.WebUIAddress)
+	}
+	if f.A2A {
+		args = append(args, "a2a", "--a2a_agent_url", f.A2AAgentURL)
+	}
+	if f.WebUI {
+		args = append(args, "webui", "--api_server_address", f.APIServerAddress)
+	}
+	return args
+}
+
+// execForm formats args as the JSON array of the exec form of a Dockerfile
+// instruction, e.g. ["/app/main", "web"].
+func execForm(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		// Marshaling a string doesn't fail.
+		data, _ := json.Marshal(arg)
+		quoted[i] = string(data)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// CopyDockerfile copies the Dockerfile supplied by the user to dst, in place
+// of the generated one. The Dockerfile must copy the server executable, which
+// is in the build context, into the image and run it.
+func CopyDockerfile(p util.Printer, src, dst string) error {
+	p("Using Dockerfile:", src)
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read the Dockerfile: %w", err)
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/cmd/adkgo/internal/deploy"
+)
+
+func TestDockerfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     deploy.ServerFlags
+		wantFrom  string
+		wantCmdTo string
+	}{
+		{
+			name: "all sublaunchers",
+			flags: deploy.ServerFlags{
+				ExecFile: "main", ServerPort: 8080,
+				API: true, WebUIAddress: "127.0.0.1:8081",
+				A2A: true, A2AAgentURL: "http://127.0.0.1:8081",
+				WebUI: true, APIServerAddress: "http://127.0.0.1:8081/api",
+			},
+			wantFrom:  "FROM " + deploy.DefaultBaseImage,
+			wantCmdTo: `CMD ["/app/main", "web", "-port", "8080", "api", "-webui_address", "127.0.0.1:8081", "a2a", "--a2a_agent_url", "http://127.0.0.1:8081", "webui", "--api_server_address", "http://127.0.0.1:8081/api"]`,
+		},
+		{
+			name: "custom base image without webui",
+			flags: deploy.ServerFlags{
+				ExecFile: "agent", ServerPort: 9000, BaseImage: "alpine:3",
+				API: true, WebUIAddress: "example.com",
+			},
+			wantFrom:  "FROM alpine:3",
+			wantCmdTo: `CMD ["/app/agent", "web", "-port", "9000", "api", "-webui_address", "example.com"]`,
+		},
+		{
+			name: "arm64 platform",
+			flags: deploy.ServerFlags{
+				ExecFile: "main", ServerPort: 8080,
+				Platform: deploy.Platform{GOOS: "linux", GOARCH: "arm64"},
+			},
+			wantFrom:  "FROM --platform=linux/arm64 " + deploy.DefaultBaseImage,
+			wantCmdTo: `CMD ["/app/main", "web", "-port", "8080"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deploy.Dockerfile(tt.flags)
+			if !strings.Contains(got, tt.wantFrom+"\n") {
+				t.Errorf("Dockerfile() = %q, want it to contain %q", got, tt.wantFrom)
+			}
+			if !strings.Contains(got, tt.wantCmdTo+"\n") {
+				t.Errorf("Dockerfile() = %q, want it to contain %q", got, tt.wantCmdTo)
+			}
+		})
+	}
+}
+
+func TestDockerfile_Cmd(t *testing.T) {
+	for _, api := range []bool{false, true} {
+		for _, a2a := range []bool{false, true} {
+			for _, webui := range []bool{false, true} {
+				flags := deploy.ServerFlags{
+					ExecFile: "main", ServerPort: 8080,
+					API: api, WebUIAddress: "127.0.0.1:8081",
+					A2A: a2a, A2AAgentURL: `http://example.com/a "quoted" \ path`,
+					WebUI: webui, APIServerAddress: "http://127.0.0.1:8081/api",
+				}
+				want := []string{"/app/main", "web", "-port", "8080"}
+				if api {
+					want = append(want, "api", "-webui_address", "127.0.0.1:8081")
+				}
+				if a2a {
+					want = append(want, "a2a", "--a2a_agent_url", `http://example.com/a "quoted" \ path`)
+				}
+				if webui {
+					want = append(want, "webui", "--api_server_address", "http://127.0.0.1:8081/api")
+				}
+				for _, platform := range []deploy.Platform{{}, {GOOS: "linux", GOARCH: "arm64"}} {
+					flags.Platform = platform
+					got := parseCmd(t, deploy.Dockerfile(flags))
+					if diff := cmp.Diff(want, got); diff != "" {
+						t.Errorf("Dockerfile(api=%v, a2a=%v, webui=%v, platform=%q) CMD mismatch (-want +got):\n%s", api, a2a, webui, platform, diff)
+					}
+				}
+			}
+		}
+	}
+}
+
+// parseCmd returns the arguments of the exec form CMD instruction of the
+// Dockerfile.
+func parseCmd(t *testing.T, dockerfile string) []string {
+	t.Helper()
+	for line := range strings.Lines(dockerfile) {
+		cmd, ok := strings.CutPrefix(line, "CMD ")
+		if !ok {
+			continue
+		}
+		var args []string
+		if err := json.Unmarshal([]byte(cmd), &args); err != nil {
+			t.Fatalf("CMD %s isn't a JSON array: %v", cmd, err)
+		}
+		return args
+	}
+	t.Fatalf("Dockerfile %q has no CMD instruction", dockerfile)
+	return nil
+}
+
+func TestCompileEntryPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{
+			name:   "main package",
+			source: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:    "not a main package",
+			source:  "package agent\n",
+			wantErr: "not in a main package",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/agent\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(tt.source), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			execPath := filepath.Join(t.TempDir(), "main")
+			platform := deploy.Platform{GOOS: deploy.DefaultGOOS, GOARCH: deploy.DefaultGOARCH}
+
+			err := deploy.CompileEntryPoint(t.Log, dir, "main.go", execPath, platform)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("CompileEntryPoint() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompileEntryPoint() error = %v", err)
+			}
+			if info, err := os.Stat(execPath); err != nil || info.Size() == 0 {
+				t.Errorf("CompileEntryPoint() didn't produce an executable: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlatformValidate(t *testing.T) {
+	tests := []struct {
+		platform deploy.Platform
+		wantErr  bool
+	}{
+		{platform: deploy.Platform{GOOS: deploy.DefaultGOOS, GOARCH: deploy.DefaultGOARCH}},
+		{platform: deploy.Platform{GOOS: "linux", GOARCH: "arm64"}},
+		{platform: deploy.Platform{GOOS: "darwin", GOARCH: "arm64"}, wantErr: true},
+		{platform: deploy.Platform{GOOS: "windows", GOARCH: "amd64"}, wantErr: true},
+		{platform: deploy.Platform{GOOS: "linux", GOARCH: "386"}, wantErr: true},
+		{platform: deploy.Platform{GOOS: "linux", GOARCH: ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform.String(), func(t *testing.T) {
+			err := tt.platform.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -16,18 +16,23 @@
 package cloudrun
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"google.golang.org/adk/cmd/adkgo/internal/deploy"
+	"google.golang.org/adk/cmd/launcher/serviceuri"
 	"google.golang.org/adk/internal/cli/util"
 )
 
@@ -40,9 +45,19 @@ type cloudRunServiceFlags struct {
 	serviceName     string
 	serverPort      int
 	a2aAgentCardURL string
-	a2a             bool // enable a2a or not
-	api             bool // enable api or not
-	webui           bool // enable webui or not
+	a2a             bool     // enable a2a or not
+	api             bool     // enable api or not
+	webui           bool     // enable webui or not
+	envVars         []string // KEY=VALUE pairs set as environment variables
+	secrets         []string // NAME=SECRET:VERSION pairs exposed as environment variables
+	serviceAccount  string   // service account the service runs as, the default compute one if empty
+	allowUnauth     bool     // allow unauthenticated access to the service
+	minInstances    int      // minimum number of instances, -1 leaves the setting of the service unchanged
+	maxInstances    int      // maximum number of instances, -1 leaves the setting of the service unchanged
+	cpu             string   // CPU limit of an instance, e.g. "1" or "2"
+	memory          string   // memory limit of an instance, e.g. "512Mi" or "2Gi"
+	sessionService  string   // URI of the session service, in-memory if empty
+	artifactService string   // URI of the artifact service, none if empty
 }
 
 type localProxyFlags struct {
@@ -50,6 +65,10 @@ type localProxyFlags struct {
 }
 
 type buildFlags struct {
+	goos                string
+	goarch              string
+	baseImage           string
+	dockerfile          string // Dockerfile supplied by the user, generated if empty
 	tempDir             string
 	execPath            string
 	execFile            string
@@ -67,6 +86,9 @@ type deployCloudRunFlags struct {
 	proxy    localProxyFlags
 	build    buildFlags
 	source   sourceFlags
+
+	dryRun        bool // print the Dockerfile and the gcloud command instead of deploying
+	skipPreflight bool // skip the checks of the prerequisites
 }
 
 var flags deployCloudRunFlags
@@ -78,6 +100,8 @@ var cloudrunCmd = &cobra.Command{
 	Long: `Deployment prepares a Dockerfile which is fed with locally compiled server executable containing Web UI static files.
 	Service on Cloudrun is created using this information. 
 	Local proxy adding authentication is started. 
+	Before building, gcloud, the project, the region, the enabled APIs and the Go toolchain are checked.
+	With --dry_run, the Dockerfile and the gcloud command are printed instead of deploying.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return flags.deployOnCloudRun()
@@ -91,6 +115,10 @@ func init() {
 	cloudrunCmd.PersistentFlags().StringVarP(&flags.gcloud.region, "region", "r", "", "GCP Region")
 	cloudrunCmd.PersistentFlags().StringVarP(&flags.gcloud.projectName, "project_name", "p", "", "GCP Project Name")
 	cloudrunCmd.PersistentFlags().StringVarP(&flags.cloudRun.serviceName, "service_name", "s", "", "Cloud Run Service name")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.build.goos, "goos", deploy.DefaultGOOS, "Target operating system of the server executable")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.build.goarch, "goarch", deploy.DefaultGOARCH, "Target architecture of the server executable, e.g. amd64 or arm64")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.build.baseImage, "base_image", deploy.DefaultBaseImage, "Base image of the generated Dockerfile")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.build.dockerfile, "dockerfile", "", "Path to a Dockerfile used instead of the generated one. The server executable, named after the entry point, is in the build context")
 	cloudrunCmd.PersistentFlags().StringVarP(&flags.build.tempDir, "temp_dir", "t", "", "Temp dir for build, defaults to os.TempDir() if not specified")
 	cloudrunCmd.PersistentFlags().IntVar(&flags.proxy.port, "proxy_port", 8081, "Local proxy port")
 	cloudrunCmd.PersistentFlags().IntVar(&flags.cloudRun.serverPort, "server_port", 8080, "Cloudrun server port")
@@ -99,22 +127,81 @@ func init() {
 	cloudrunCmd.PersistentFlags().StringVarP(&flags.cloudRun.a2aAgentCardURL, "a2a_agent_url", "a", "http://127.0.0.1:8081", "A2A agent card URL as advertised in the public agent card")
 	cloudrunCmd.PersistentFlags().BoolVar(&flags.cloudRun.api, "api", true, "Enable API")
 	cloudrunCmd.PersistentFlags().BoolVar(&flags.cloudRun.webui, "webui", true, "Enable Web UI")
+	cloudrunCmd.PersistentFlags().StringArrayVar(&flags.cloudRun.envVars, "env", nil, "Environment variable in KEY=VALUE format, can be repeated. Set GOOGLE_GENAI_USE_VERTEXAI=true to use Vertex AI with the credentials of the service account")
+	cloudrunCmd.PersistentFlags().StringArrayVar(&flags.cloudRun.secrets, "secret", nil, "Secret exposed as environment variable in NAME=SECRET:VERSION format, can be repeated. Defaults to GOOGLE_API_KEY=GOOGLE_API_KEY:latest unless Vertex AI is used")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.cloudRun.serviceAccount, "service_account", "", "Service account the service runs as, defaults to the Compute Engine default service account")
+	cloudrunCmd.PersistentFlags().BoolVar(&flags.cloudRun.allowUnauth, "allow_unauthenticated", false, "Allow unauthenticated access to the service")
+	cloudrunCmd.PersistentFlags().IntVar(&flags.cloudRun.minInstances, "min_instances", -1, "Minimum number of instances, the setting of the service is kept if not specified")
+	cloudrunCmd.PersistentFlags().IntVar(&flags.cloudRun.maxInstances, "max_instances", -1, "Maximum number of instances, the setting of the service is kept if not specified")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.cloudRun.cpu, "cpu", "", "CPU limit of an instance, e.g. '1' or '2'")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.cloudRun.memory, "memory", "", "Memory limit of an instance, e.g. '512Mi' or '2Gi'")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.cloudRun.sessionService, "session_service", "", "URI of the session service of the server, e.g. 'agentengine://...' with the scheme registered in the server. In-memory sessions are lost when an instance restarts or scales")
+	cloudrunCmd.PersistentFlags().StringVar(&flags.cloudRun.artifactService, "artifact_service", "", "URI of the artifact service of the server, e.g. 'gs://my-bucket' when the server imports the serviceuri/gcsuri package")
+	cloudrunCmd.PersistentFlags().BoolVar(&flags.dryRun, "dry_run", false, "Check the prerequisites, then print the Dockerfile and the gcloud command instead of deploying")
+	cloudrunCmd.PersistentFlags().BoolVar(&flags.skipPreflight, "skip_preflight", false, "Skip the checks of gcloud, the project, the region, the enabled APIs and the Go toolchain")
+	cloudrunCmd.SetGlobalNormalizationFunc(normalizeFlagName)
+}
+
+// flagAliases maps the previous names of the flags to the current ones.
+var flagAliases = map[string]string{
+	"set_env_var": "env",
+	"set_secret":  "secret",
+}
+
+// normalizeFlagName accepts gcloud style flag names with dashes, e.g.
+// --service-account, and the previous names of the flags.
+func normalizeFlagName(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	name = strings.ReplaceAll(name, "-", "_")
+	if alias, ok := flagAliases[name]; ok {
+		name = alias
+	}
+	return pflag.NormalizedName(name)
 }
 
 // computeFlags uses command line arguments to create a full config
 func (f *deployCloudRunFlags) computeFlags() error {
 	return util.LogStartStop("Computing flags & preparing temp",
 		func(p util.Printer) error {
-			absp, err := filepath.Abs(flags.source.entryPointPath)
+			if err := f.cloudRun.validate(); err != nil {
+				return err
+			}
+			if err := f.build.platform().Validate(); err != nil {
+				return err
+			}
+
+			absp, err := filepath.Abs(f.source.entryPointPath)
 			if err != nil {
 				return fmt.Errorf("cannot make an absolute path from '%v': %w", f.source.entryPointPath, err)
 			}
 			f.source.entryPointPath = absp
 
-			if flags.build.tempDir == "" {
-				flags.build.tempDir = os.TempDir()
+			if f.build.dockerfile != "" {
+				absp, err := filepath.Abs(f.build.dockerfile)
+				if err != nil {
+					return fmt.Errorf("cannot make an absolute path from '%v': %w", f.build.dockerfile, err)
+				}
+				if _, err := os.Stat(absp); err != nil {
+					return fmt.Errorf("cannot use the Dockerfile: %w", err)
+				}
+				f.build.dockerfile = absp
 			}
-			absp, err = filepath.Abs(flags.build.tempDir)
+
+			// come up with a executable name based on entry point path
+			dir, file := path.Split(f.source.entryPointPath)
+			f.source.srcBasePath = dir
+			f.source.entryPointPath = file
+			exec, err := util.StripExtension(f.source.entryPointPath, ".go")
+			if err != nil {
+				return fmt.Errorf("cannot strip '.go' extension from entry point path '%v': %w", f.source.entryPointPath, err)
+			}
+			f.build.execFile = exec
+
+			// The temp dir is created last, so that it's removed whenever
+			// computeFlags succeeds.
+			if f.build.tempDir == "" {
+				f.build.tempDir = os.TempDir()
+			}
+			absp, err = filepath.Abs(f.build.tempDir)
 			if err != nil {
 				return fmt.Errorf("cannot make an absolute path from '%v': %w", f.build.tempDir, err)
 			}
@@ -123,25 +210,17 @@ func (f *deployCloudRunFlags) computeFlags() error {
 				return fmt.Errorf("cannot create a temporary sub directory in '%v': %w", absp, err)
 			}
 			p("Using temp dir:", f.build.tempDir)
-
-			// come up with a executable name based on entry point path
-			dir, file := path.Split(f.source.entryPointPath)
-			f.source.srcBasePath = dir
-			f.source.entryPointPath = file
-			if f.build.execPath == "" {
-				exec, err := util.StripExtension(f.source.entryPointPath, ".go")
-				if err != nil {
-					return fmt.Errorf("cannot strip '.go' extension from entry point path '%v': %w", f.source.entryPointPath, err)
-				}
-				f.build.execFile = exec
-				f.build.execPath = path.Join(f.build.tempDir, exec)
-			}
+			f.build.execPath = path.Join(f.build.tempDir, exec)
 			f.build.dockerfileBuildPath = path.Join(f.build.tempDir, "Dockerfile")
 
 			return nil
 		})
 }
 
+func (f *buildFlags) platform() deploy.Platform {
+	return deploy.Platform{GOOS: f.goos, GOARCH: f.goarch}
+}
+
 func (f *deployCloudRunFlags) cleanTemp() error {
 	return util.LogStartStop("Cleaning temp",
 		func(p util.Printer) error {
@@ -158,70 +237,226 @@ func (f *deployCloudRunFlags) cleanTemp() error {
 func (f *deployCloudRunFlags) compileEntryPoint() error {
 	return util.LogStartStop("Compiling server",
 		func(p util.Printer) error {
-			p("Using", f.source.entryPointPath, "as entry point")
-			// for help on ldflags you can run go build -ldflags="--help" ./examples/quickstart/main.go
-			//    -s    disable symbol table
-			//    -w    disable DWARF generation
-			//   using those flags reduces the size of an executable
-			cmd := exec.Command("go", "build", "-ldflags", "-s -w", "-o", f.build.execPath, f.source.entryPointPath)
-
-			cmd.Dir = f.source.srcBasePath
-			// build using staticallly linked libs, for linux/amd64
-			cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH=amd64")
-			return util.LogCommand(cmd, p)
+			return deploy.CompileEntryPoint(p, f.source.srcBasePath, f.source.entryPointPath, f.build.execPath, f.build.platform())
 		})
 }
 
-// prepareDockerfile creates a temporary Dockerfile which will be executed by CloudRun
 func (f *deployCloudRunFlags) prepareDockerfile() error {
 	return util.LogStartStop("Preparing Dockerfile",
 		func(p util.Printer) error {
 			p("Writing:", f.build.dockerfileBuildPath)
-
-			var b strings.Builder
-			b.WriteString(`
-FROM gcr.io/distroless/static-debian11
-
-COPY ` + f.build.execFile + `  /app/` + f.build.execFile + `
-EXPOSE ` + strconv.Itoa(flags.cloudRun.serverPort) + `
-# Command to run the executable when the container starts
-CMD ["/app/` + f.build.execFile + `", "web", "-port", "` + strconv.Itoa(flags.cloudRun.serverPort) + `"`)
-
-			if flags.cloudRun.api {
-				b.WriteString(`, "api", "-webui_address", "127.0.0.1:` + strconv.Itoa(f.proxy.port) + `"`)
-			}
-			if flags.cloudRun.a2a {
-				b.WriteString(`, "a2a", "--a2a_agent_url", "` + flags.cloudRun.a2aAgentCardURL + `"`)
-			}
-			if flags.cloudRun.webui {
-				b.WriteString(`, "webui", "--api_server_address", "http://127.0.0.1:` + strconv.Itoa(f.proxy.port) + `/api"]
-				`)
+			if f.build.dockerfile != "" {
+				return deploy.CopyDockerfile(p, f.build.dockerfile, f.build.dockerfileBuildPath)
 			}
-			return os.WriteFile(f.build.dockerfileBuildPath, []byte(b.String()), 0o600)
+
+			dockerfile := deploy.Dockerfile(deploy.ServerFlags{
+				ExecFile:         f.build.execFile,
+				ServerPort:       f.cloudRun.serverPort,
+				BaseImage:        f.build.baseImage,
+				Platform:         f.build.platform(),
+				API:              f.cloudRun.api,
+				WebUIAddress:     "127.0.0.1:" + strconv.Itoa(f.proxy.port),
+				A2A:              f.cloudRun.a2a,
+				A2AAgentURL:      f.cloudRun.a2aAgentCardURL,
+				WebUI:            f.cloudRun.webui,
+				APIServerAddress: "http://127.0.0.1:" + strconv.Itoa(f.proxy.port) + "/api",
+			})
+			return os.WriteFile(f.build.dockerfileBuildPath, []byte(dockerfile), 0o600)
 		})
 }
 
-// gcloudDeployToCloudRun invokes gcloud to deploy source on CloudRun
 func (f *deployCloudRunFlags) gcloudDeployToCloudRun() error {
 	return util.LogStartStop("Deploying to Cloud Run",
 		func(p util.Printer) error {
-			params := []string{
-				"run", "deploy", f.cloudRun.serviceName,
-				"--source", ".",
-				"--set-secrets=GOOGLE_API_KEY=GOOGLE_API_KEY:latest",
-				"--region", f.gcloud.region,
-				"--project", f.gcloud.projectName,
-				"--ingress", "all",
-				"--no-allow-unauthenticated",
-			}
-
-			cmd := exec.Command("gcloud", params...)
+			cmd := exec.Command("gcloud", f.gcloudDeployArgs()...)
 
 			cmd.Dir = f.build.tempDir
 			return util.LogCommand(cmd, p)
 		})
 }
 
+// gcloudDeployArgs returns the arguments of the gcloud command deploying the service.
+func (f *deployCloudRunFlags) gcloudDeployArgs() []string {
+	args := []string{
+		"run", "deploy", f.cloudRun.serviceName,
+		"--source", ".",
+		"--region", f.gcloud.region,
+		"--project", f.gcloud.projectName,
+		"--ingress", "all",
+	}
+	if secrets := f.cloudRun.secretsOrDefault(); len(secrets) > 0 {
+		args = append(args, "--set-secrets="+gcloudList(secrets))
+	}
+	if envVars := f.cloudRun.allEnvVars(); len(envVars) > 0 {
+		args = append(args, "--set-env-vars="+gcloudList(envVars))
+	}
+	if f.cloudRun.allowUnauth {
+		args = append(args, "--allow-unauthenticated")
+	} else {
+		args = append(args, "--no-allow-unauthenticated")
+	}
+	if f.cloudRun.serviceAccount != "" {
+		args = append(args, "--service-account="+f.cloudRun.serviceAccount)
+	}
+	if f.cloudRun.minInstances >= 0 {
+		args = append(args, "--min-instances="+strconv.Itoa(f.cloudRun.minInstances))
+	}
+	if f.cloudRun.maxInstances >= 0 {
+		args = append(args, "--max-instances="+strconv.Itoa(f.cloudRun.maxInstances))
+	}
+	if f.cloudRun.cpu != "" {
+		args = append(args, "--cpu="+f.cloudRun.cpu)
+	}
+	if f.cloudRun.memory != "" {
+		args = append(args, "--memory="+f.cloudRun.memory)
+	}
+	return args
+}
+
+// allEnvVars returns the environment variables of the service, including the
+// ones passing the service URIs to the server.
+func (f *cloudRunServiceFlags) allEnvVars() []string {
+	envVars := slices.Clone(f.envVars)
+	if f.sessionService != "" {
+		envVars = append(envVars, serviceuri.SessionServiceEnv+"="+f.sessionService)
+	}
+	if f.artifactService != "" {
+		envVars = append(envVars, serviceuri.ArtifactServiceEnv+"="+f.artifactService)
+	}
+	return envVars
+}
+
+// secretsOrDefault returns the configured secrets or the GOOGLE_API_KEY secret if none are set.
+// No secret is returned by default if Vertex AI is used, as it authenticates with the
+// credentials of the service account.
+func (f *cloudRunServiceFlags) secretsOrDefault() []string {
+	if len(f.secrets) == 0 && !f.usesVertexAI() {
+		return []string{"GOOGLE_API_KEY=GOOGLE_API_KEY:latest"}
+	}
+	return f.secrets
+}
+
+// usesVertexAI reports whether GOOGLE_GENAI_USE_VERTEXAI is set to true.
+func (f *cloudRunServiceFlags) usesVertexAI() bool {
+	for _, envVar := range f.envVars {
+		key, value, _ := strings.Cut(envVar, "=")
+		if key == "GOOGLE_GENAI_USE_VERTEXAI" {
+			useVertexAI, _ := strconv.ParseBool(value)
+			return useVertexAI || value == "1"
+		}
+	}
+	return false
+}
+
+// validate checks the format of the flags and rejects the combinations which
+// gcloud would fail on, before anything is built.
+func (f *cloudRunServiceFlags) validate() error {
+	if err := validateEnvVars(f.envVars); err != nil {
+		return err
+	}
+	if err := validateSecrets(f.secrets); err != nil {
+		return err
+	}
+	if err := validateServiceURI("--session_service", f.sessionService); err != nil {
+		return err
+	}
+	if err := validateServiceURI("--artifact_service", f.artifactService); err != nil {
+		return err
+	}
+	names := make(map[string]string)
+	if f.sessionService != "" {
+		names[serviceuri.SessionServiceEnv] = "--session_service"
+	}
+	if f.artifactService != "" {
+		names[serviceuri.ArtifactServiceEnv] = "--artifact_service"
+	}
+	for _, envVar := range f.envVars {
+		key, _, _ := strings.Cut(envVar, "=")
+		if flag, ok := names[key]; ok {
+			if flag != "--env" {
+				return fmt.Errorf("environment variable %s can't be set by both --env and %s", key, flag)
+			}
+			return fmt.Errorf("environment variable %s is set more than once", key)
+		}
+		names[key] = "--env"
+	}
+	for _, secret := range f.secrets {
+		name, _, _ := strings.Cut(secret, "=")
+		if flag, ok := names[name]; ok {
+			if flag != "--secret" {
+				return fmt.Errorf("environment variable %s can't be set by both %s and --secret", name, flag)
+			}
+			return fmt.Errorf("environment variable %s is set more than once", name)
+		}
+		names[name] = "--secret"
+	}
+	if f.minInstances < -1 || f.maxInstances < -1 {
+		return fmt.Errorf("--min_instances and --max_instances can't be negative")
+	}
+	if f.minInstances >= 0 && f.maxInstances >= 0 && f.minInstances > f.maxInstances {
+		return fmt.Errorf("--min_instances (%d) can't be greater than --max_instances (%d)", f.minInstances, f.maxInstances)
+	}
+	return nil
+}
+
+// validateServiceURI checks the syntax of a service URI. Whether its scheme is
+// supported is only known to the server, which can register its own schemes.
+func validateServiceURI(flag, uri string) error {
+	if uri == "" {
+		return nil
+	}
+	u, err := serviceuri.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", flag, err)
+	}
+	if u.Scheme == "sqlite" {
+		// The database would be lost with the instance, and the server is built without cgo.
+		return fmt.Errorf("invalid %s %q: SQLite can't be used on Cloud Run", flag, uri)
+	}
+	return nil
+}
+
+// validateEnvVars checks that all environment variables are in KEY=VALUE format.
+func validateEnvVars(envVars []string) error {
+	for _, envVar := range envVars {
+		key, _, ok := strings.Cut(envVar, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid --env %q: expected KEY=VALUE format", envVar)
+		}
+	}
+	return nil
+}
+
+// validateSecrets checks that all secrets are in NAME=SECRET:VERSION format.
+func validateSecrets(secrets []string) error {
+	for _, secret := range secrets {
+		name, ref, ok := strings.Cut(secret, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid --secret %q: expected NAME=SECRET:VERSION format", secret)
+		}
+		secretName, version, ok := strings.Cut(ref, ":")
+		if !ok || secretName == "" || version == "" {
+			return fmt.Errorf("invalid --secret %q: expected NAME=SECRET:VERSION format", secret)
+		}
+	}
+	return nil
+}
+
+// gcloudList joins items into a gcloud list argument. If any item contains
+// a comma, gcloud's alternate delimiter syntax (^DELIM^) is used.
+func gcloudList(items []string) string {
+	if !slices.ContainsFunc(items, func(item string) bool { return strings.Contains(item, ",") }) {
+		return strings.Join(items, ",")
+	}
+	all := strings.Join(items, "")
+	for _, delim := range []string{"@", "#", "|", ";", "~"} {
+		if !strings.Contains(all, delim) {
+			return "^" + delim + "^" + strings.Join(items, delim)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
 // runGcloudProxy invokes gcloud to create a proxy which will add authentication headers to requests
 func (f *deployCloudRunFlags) runGcloudProxy() error {
 	return util.LogStartStop("Running local gcloud authenticating proxy",
@@ -246,30 +481,68 @@ func (f *deployCloudRunFlags) runGcloudProxy() error {
 func (f *deployCloudRunFlags) deployOnCloudRun() error {
 	fmt.Println(flags)
 
-	err := f.computeFlags()
-	if err != nil {
+	if err := f.deploy(); err != nil {
 		return err
 	}
-	err = f.compileEntryPoint()
-	if err != nil {
-		return err
+	if f.dryRun {
+		return nil
 	}
-	err = f.prepareDockerfile()
-	if err != nil {
+	return f.runGcloudProxy()
+}
+
+// deploy builds and deploys the service, or prints what would be deployed in
+// a dry run. The temp dir is removed even if a step fails.
+func (f *deployCloudRunFlags) deploy() (err error) {
+	if err := f.computeFlags(); err != nil {
 		return err
 	}
-	err = f.gcloudDeployToCloudRun()
-	if err != nil {
-		return err
+	defer func() {
+		err = errors.Join(err, f.cleanTemp())
+	}()
+
+	if !f.skipPreflight {
+		if err := f.preflight(runCommand); err != nil {
+			return err
+		}
 	}
-	err = f.cleanTemp()
-	if err != nil {
+	if f.dryRun {
+		// The preflight checks compile the entry point, the executable isn't needed.
+		if err := f.prepareDockerfile(); err != nil {
+			return err
+		}
+		return f.printDryRun(os.Stdout)
+	}
+	if err := f.compileEntryPoint(); err != nil {
 		return err
 	}
-	err = f.runGcloudProxy()
-	if err != nil {
+	if err := f.prepareDockerfile(); err != nil {
 		return err
 	}
+	return f.gcloudDeployToCloudRun()
+}
 
+// printDryRun prints the Dockerfile and the gcloud command of the deployment.
+func (f *deployCloudRunFlags) printDryRun(w io.Writer) error {
+	dockerfile, err := os.ReadFile(f.build.dockerfileBuildPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the Dockerfile: %w", err)
+	}
+	fmt.Fprintf(w, "Dockerfile:\n%s\n", strings.TrimSpace(string(dockerfile)))
+	fmt.Fprintf(w, "\nCommand, run in the directory of the Dockerfile and the %s executable:\n%s\n",
+		f.build.execFile, shellJoin(append([]string{"gcloud"}, f.gcloudDeployArgs()...)))
 	return nil
 }
+
+// shellJoin joins args into a command line, quoting the args which the
+// shell would interpret.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=.,/:@%+^") == "" {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,362 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudrun
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateEnvVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		envVars []string
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "valid", envVars: []string{"GOOGLE_CLOUD_LOCATION=europe-west1", "EMPTY="}},
+		{name: "value with equals sign", envVars: []string{"QUERY=a=b"}},
+		{name: "missing equals sign", envVars: []string{"GOOGLE_CLOUD_LOCATION"}, wantErr: true},
+		{name: "missing key", envVars: []string{"=value"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateEnvVars(tt.envVars); (err != nil) != tt.wantErr {
+				t.Errorf("validateEnvVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		wantErr bool
+	}{
+		{name: "valid", secrets: []string{"GOOGLE_API_KEY=api-key:latest", "TOKEN=token:3"}},
+		{name: "missing version", secrets: []string{"GOOGLE_API_KEY=api-key"}, wantErr: true},
+		{name: "empty version", secrets: []string{"GOOGLE_API_KEY=api-key:"}, wantErr: true},
+		{name: "missing secret", secrets: []string{"GOOGLE_API_KEY"}, wantErr: true},
+		{name: "missing name", secrets: []string{"=api-key:latest"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSecrets(tt.secrets); (err != nil) != tt.wantErr {
+				t.Errorf("validateSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGcloudList(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		want  string
+	}{
+		{name: "single", items: []string{"A=1"}, want: "A=1"},
+		{name: "multiple", items: []string{"A=1", "B=2"}, want: "A=1,B=2"},
+		{name: "with comma", items: []string{"A=1,2", "B=3"}, want: "^@^A=1,2@B=3"},
+		{name: "with comma and at sign", items: []string{"A=x@y,z", "B=3"}, want: "^#^A=x@y,z#B=3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gcloudList(tt.items); got != tt.want {
+				t.Errorf("gcloudList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGcloudDeployArgs(t *testing.T) {
+	baseArgs := []string{
+		"run", "deploy", "agent",
+		"--source", ".",
+		"--region", "europe-west1",
+		"--project", "my-project",
+		"--ingress", "all",
+	}
+	tests := []struct {
+		name     string
+		cloudRun cloudRunServiceFlags
+		want     []string
+	}{
+		{
+			name:     "defaults",
+			cloudRun: cloudRunServiceFlags{minInstances: -1, maxInstances: -1},
+			want:     []string{"--set-secrets=GOOGLE_API_KEY=GOOGLE_API_KEY:latest", "--no-allow-unauthenticated"},
+		},
+		{
+			name: "env vars and secrets",
+			cloudRun: cloudRunServiceFlags{
+				envVars:      []string{"A=1", "B=2"},
+				secrets:      []string{"TOKEN=token:3"},
+				minInstances: -1,
+				maxInstances: -1,
+			},
+			want: []string{"--set-secrets=TOKEN=token:3", "--set-env-vars=A=1,B=2", "--no-allow-unauthenticated"},
+		},
+		{
+			name: "vertex ai with application default credentials",
+			cloudRun: cloudRunServiceFlags{
+				envVars:        []string{"GOOGLE_GENAI_USE_VERTEXAI=true", "GOOGLE_CLOUD_PROJECT=my-project"},
+				serviceAccount: "agent@my-project.iam.gserviceaccount.com",
+				minInstances:   -1,
+				maxInstances:   -1,
+			},
+			want: []string{
+				"--set-env-vars=GOOGLE_GENAI_USE_VERTEXAI=true,GOOGLE_CLOUD_PROJECT=my-project",
+				"--no-allow-unauthenticated",
+				"--service-account=agent@my-project.iam.gserviceaccount.com",
+			},
+		},
+		{
+			name: "vertex ai disabled",
+			cloudRun: cloudRunServiceFlags{
+				envVars:      []string{"GOOGLE_GENAI_USE_VERTEXAI=false"},
+				minInstances: -1,
+				maxInstances: -1,
+			},
+			want: []string{
+				"--set-secrets=GOOGLE_API_KEY=GOOGLE_API_KEY:latest",
+				"--set-env-vars=GOOGLE_GENAI_USE_VERTEXAI=false",
+				"--no-allow-unauthenticated",
+			},
+		},
+		{
+			name: "public service with scaling and resources",
+			cloudRun: cloudRunServiceFlags{
+				allowUnauth:  true,
+				minInstances: 0,
+				maxInstances: 5,
+				cpu:          "2",
+				memory:       "1Gi",
+			},
+			want: []string{
+				"--set-secrets=GOOGLE_API_KEY=GOOGLE_API_KEY:latest",
+				"--allow-unauthenticated",
+				"--min-instances=0",
+				"--max-instances=5",
+				"--cpu=2",
+				"--memory=1Gi",
+			},
+		},
+		{
+			name: "service URIs",
+			cloudRun: cloudRunServiceFlags{
+				envVars:         []string{"A=1"},
+				sessionService:  "agentengine://projects/p/locations/l/reasoningEngines/1",
+				artifactService: "gs://my-bucket",
+				minInstances:    -1,
+				maxInstances:    -1,
+			},
+			want: []string{
+				"--set-secrets=GOOGLE_API_KEY=GOOGLE_API_KEY:latest",
+				"--set-env-vars=A=1,ADK_SESSION_SERVICE_URI=agentengine://projects/p/locations/l/reasoningEngines/1,ADK_ARTIFACT_SERVICE_URI=gs://my-bucket",
+				"--no-allow-unauthenticated",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cloudRun.serviceName = "agent"
+			f := &deployCloudRunFlags{
+				gcloud:   gCloudFlags{region: "europe-west1", projectName: "my-project"},
+				cloudRun: tt.cloudRun,
+			}
+			want := append(baseArgs[:len(baseArgs):len(baseArgs)], tt.want...)
+			if diff := cmp.Diff(want, f.gcloudDeployArgs()); diff != "" {
+				t.Errorf("gcloudDeployArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cloudRun cloudRunServiceFlags
+		wantErr  string
+	}{
+		{
+			name: "valid",
+			cloudRun: cloudRunServiceFlags{
+				envVars:      []string{"A=1"},
+				secrets:      []string{"TOKEN=token:3"},
+				minInstances: 1,
+				maxInstances: 3,
+			},
+		},
+		{
+			name:     "invalid env var",
+			cloudRun: cloudRunServiceFlags{envVars: []string{"A"}},
+			wantErr:  "invalid --env",
+		},
+		{
+			name:     "invalid secret",
+			cloudRun: cloudRunServiceFlags{secrets: []string{"TOKEN=token"}},
+			wantErr:  "invalid --secret",
+		},
+		{
+			name:     "duplicate env var",
+			cloudRun: cloudRunServiceFlags{envVars: []string{"A=1", "A=2"}},
+			wantErr:  "A is set more than once",
+		},
+		{
+			name:     "duplicate secret",
+			cloudRun: cloudRunServiceFlags{secrets: []string{"TOKEN=a:1", "TOKEN=b:1"}},
+			wantErr:  "TOKEN is set more than once",
+		},
+		{
+			name: "env var and secret",
+			cloudRun: cloudRunServiceFlags{
+				envVars: []string{"GOOGLE_API_KEY=key"},
+				secrets: []string{"GOOGLE_API_KEY=api-key:latest"},
+			},
+			wantErr: "both --env and --secret",
+		},
+		{
+			name:     "valid service URIs",
+			cloudRun: cloudRunServiceFlags{sessionService: "firestore://my-project", artifactService: "gs://my-bucket"},
+		},
+		{
+			name:     "service URI without scheme",
+			cloudRun: cloudRunServiceFlags{artifactService: "my-bucket"},
+			wantErr:  "invalid --artifact_service",
+		},
+		{
+			name:     "sqlite session service",
+			cloudRun: cloudRunServiceFlags{sessionService: "sqlite:///tmp/sessions.db"},
+			wantErr:  "SQLite can't be used on Cloud Run",
+		},
+		{
+			name: "service URI and env var",
+			cloudRun: cloudRunServiceFlags{
+				envVars:        []string{"ADK_SESSION_SERVICE_URI=memory://"},
+				sessionService: "firestore://my-project",
+			},
+			wantErr: "ADK_SESSION_SERVICE_URI can't be set by both --env and --session_service",
+		},
+		{
+			name:     "negative instances",
+			cloudRun: cloudRunServiceFlags{minInstances: -2, maxInstances: -1},
+			wantErr:  "can't be negative",
+		},
+		{
+			name:     "min instances greater than max instances",
+			cloudRun: cloudRunServiceFlags{minInstances: 3, maxInstances: 2},
+			wantErr:  "--min_instances (3) can't be greater than --max_instances (2)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cloudRun.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlagNames(t *testing.T) {
+	for _, args := range [][]string{
+		{"--env", "A=1", "--secret", "TOKEN=token:3", "--service_account", "sa", "--min_instances", "1"},
+		{"--set-env-var", "A=1", "--set-secret", "TOKEN=token:3", "--service-account", "sa", "--min-instances", "1"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			flags = deployCloudRunFlags{}
+			t.Cleanup(func() { flags = deployCloudRunFlags{} })
+			if err := cloudrunCmd.ParseFlags(args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			want := cloudRunServiceFlags{
+				envVars:        []string{"A=1"},
+				secrets:        []string{"TOKEN=token:3"},
+				serviceAccount: "sa",
+				minInstances:   1,
+			}
+			got := flags.cloudRun
+			if diff := cmp.Diff(want, cloudRunServiceFlags{
+				envVars:        got.envVars,
+				secrets:        got.secrets,
+				serviceAccount: got.serviceAccount,
+				minInstances:   got.minInstances,
+			}, cmp.AllowUnexported(cloudRunServiceFlags{})); diff != "" {
+				t.Errorf("flags mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrepareDockerfile(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "Dockerfile.custom")
+	const customContent = "FROM alpine:3\nCOPY main /main\nCMD [\"/main\", \"web\"]\n"
+	if err := os.WriteFile(custom, []byte(customContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		build buildFlags
+		want  []string
+	}{
+		{
+			name:  "generated",
+			build: buildFlags{goos: "linux", goarch: "amd64", baseImage: "gcr.io/distroless/static-debian12", execFile: "main"},
+			want:  []string{"FROM --platform=linux/amd64 gcr.io/distroless/static-debian12\n", `CMD ["/app/main", "web", "-port", "8080"]`},
+		},
+		{
+			name:  "arm64",
+			build: buildFlags{goos: "linux", goarch: "arm64", baseImage: "gcr.io/distroless/static-debian11", execFile: "main"},
+			want:  []string{"FROM --platform=linux/arm64 gcr.io/distroless/static-debian11\n"},
+		},
+		{
+			name:  "user Dockerfile",
+			build: buildFlags{goos: "linux", goarch: "amd64", dockerfile: custom, execFile: "main"},
+			want:  []string{customContent},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := deployCloudRunFlags{
+				cloudRun: cloudRunServiceFlags{serverPort: 8080},
+				build:    tt.build,
+			}
+			f.build.dockerfileBuildPath = filepath.Join(t.TempDir(), "Dockerfile")
+			if err := f.prepareDockerfile(); err != nil {
+				t.Fatalf("prepareDockerfile() error = %v", err)
+			}
+			data, err := os.ReadFile(f.build.dockerfileBuildPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Dockerfile = %q, want it to contain %q", data, want)
+				}
+			}
+		})
+	}
+}
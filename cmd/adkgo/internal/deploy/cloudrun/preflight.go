@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudrun
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/version"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/internal/cli/util"
+)
+
+// commandRunner runs the command and returns its standard output. It is
+// replaced in tests.
+type commandRunner func(cmd *exec.Cmd) (string, error)
+
+// runCommand runs the command, the returned error includes the standard
+// error of the command.
+func runCommand(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// requiredAPIs are the APIs used to build and run the service from source.
+var requiredAPIs = []string{
+	"run.googleapis.com",
+	"cloudbuild.googleapis.com",
+	"artifactregistry.googleapis.com",
+}
+
+// preflight checks the prerequisites of the deployment before anything is
+// built, reporting all the problems found at once.
+func (f *deployCloudRunFlags) preflight(run commandRunner) error {
+	return util.LogStartStop("Checking prerequisites",
+		func(p util.Printer) error {
+			var problems []error
+			problems = append(problems, f.checkGcloud(p, run)...)
+			problems = append(problems, f.checkGo(p, run)...)
+			if len(problems) > 0 {
+				return fmt.Errorf("%d problem(s) found, fix them or rerun with --skip_preflight:\n%w", len(problems), errors.Join(problems...))
+			}
+			return nil
+		})
+}
+
+// checkGcloud checks that gcloud is authenticated and that the project and
+// region can host the service.
+func (f *deployCloudRunFlags) checkGcloud(p util.Printer, run commandRunner) []error {
+	var problems []error
+	if f.gcloud.projectName == "" {
+		problems = append(problems, fmt.Errorf("--project_name is required"))
+	}
+	if f.gcloud.region == "" {
+		problems = append(problems, fmt.Errorf("--region is required"))
+	}
+
+	if _, err := run(exec.Command("gcloud", "version")); err != nil {
+		return append(problems, fmt.Errorf("gcloud can't be run, install the Google Cloud CLI: %w", err))
+	}
+	account, err := run(exec.Command("gcloud", "auth", "list", "--filter=status:ACTIVE", "--format=value(account)"))
+	if err != nil {
+		return append(problems, fmt.Errorf("can't get the active gcloud account: %w", err))
+	}
+	if account == "" {
+		return append(problems, fmt.Errorf("no active gcloud account, run 'gcloud auth login'"))
+	}
+	p("Using gcloud account:", account)
+	if f.gcloud.projectName == "" {
+		return problems
+	}
+
+	if _, err := run(exec.Command("gcloud", "projects", "describe", f.gcloud.projectName, "--format=value(projectId)")); err != nil {
+		return append(problems, fmt.Errorf("project %q can't be accessed: %w", f.gcloud.projectName, err))
+	}
+
+	out, err := run(exec.Command("gcloud", "services", "list", "--enabled", "--project", f.gcloud.projectName, "--format=value(config.name)"))
+	if err != nil {
+		return append(problems, fmt.Errorf("can't list the enabled APIs of project %q: %w", f.gcloud.projectName, err))
+	}
+	enabled := strings.Fields(out)
+	var missing []string
+	for _, api := range f.requiredAPIs() {
+		if !slices.Contains(enabled, api) {
+			missing = append(missing, api)
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Errorf("APIs not enabled in project %q, run 'gcloud services enable %s --project %s'",
+			f.gcloud.projectName, strings.Join(missing, " "), f.gcloud.projectName))
+	}
+	if f.gcloud.region == "" || slices.Contains(missing, "run.googleapis.com") {
+		return problems
+	}
+
+	out, err = run(exec.Command("gcloud", "run", "regions", "list", "--project", f.gcloud.projectName, "--format=value(locationId)"))
+	if err != nil {
+		return append(problems, fmt.Errorf("can't list the Cloud Run regions: %w", err))
+	}
+	if !slices.Contains(strings.Fields(out), f.gcloud.region) {
+		problems = append(problems, fmt.Errorf("region %q isn't a Cloud Run region, see 'gcloud run regions list'", f.gcloud.region))
+	}
+	return problems
+}
+
+// requiredAPIs returns the APIs which must be enabled in the project.
+func (f *deployCloudRunFlags) requiredAPIs() []string {
+	apis := slices.Clone(requiredAPIs)
+	if len(f.cloudRun.secretsOrDefault()) > 0 {
+		apis = append(apis, "secretmanager.googleapis.com")
+	}
+	if f.cloudRun.usesVertexAI() {
+		apis = append(apis, "aiplatform.googleapis.com")
+	}
+	return apis
+}
+
+// checkGo checks that the Go toolchain satisfies the module of the entry
+// point and that the entry point compiles for the target platform.
+func (f *deployCloudRunFlags) checkGo(p util.Printer, run commandRunner) []error {
+	goCommand := func(args ...string) *exec.Cmd {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = f.source.srcBasePath
+		return cmd
+	}
+
+	goVersion, err := run(goCommand("env", "GOVERSION"))
+	if err != nil {
+		return []error{fmt.Errorf("go can't be run, install Go: %w", err)}
+	}
+	p("Using Go toolchain:", goVersion)
+	// Outside of a module, the compilation reports the problem.
+	if required, err := run(goCommand("list", "-m", "-f", "{{.GoVersion}}")); err == nil && required != "" {
+		if version.Compare(goVersion, "go"+required) < 0 {
+			return []error{fmt.Errorf("the Go toolchain %s is older than go%s required by the module of the entry point", goVersion, required)}
+		}
+	}
+
+	build := goCommand("build", "-o", os.DevNull, f.source.entryPointPath)
+	build.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS="+f.build.goos, "GOARCH="+f.build.goarch)
+	if _, err := run(build); err != nil {
+		return []error{fmt.Errorf("entry point %s doesn't compile: %w", f.source.entryPointPath, err)}
+	}
+	return nil
+}
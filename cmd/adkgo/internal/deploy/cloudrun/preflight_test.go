@@ -0,0 +1,297 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudrun
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeRunner returns the results of the commands by their command line, and
+// succeeds with no output for the other commands.
+type fakeRunner struct {
+	results map[string]fakeResult
+	cmds    []*exec.Cmd
+}
+
+type fakeResult struct {
+	out string
+	err error
+}
+
+func (r *fakeRunner) run(cmd *exec.Cmd) (string, error) {
+	r.cmds = append(r.cmds, cmd)
+	res := r.results[strings.Join(cmd.Args, " ")]
+	return res.out, res.err
+}
+
+const (
+	authListCmd     = "gcloud auth list --filter=status:ACTIVE --format=value(account)"
+	servicesListCmd = "gcloud services list --enabled --project my-project --format=value(config.name)"
+	regionsListCmd  = "gcloud run regions list --project my-project --format=value(locationId)"
+	describeCmd     = "gcloud projects describe my-project --format=value(projectId)"
+	goVersionCmd    = "go env GOVERSION"
+	goModVersionCmd = "go list -m -f {{.GoVersion}}"
+)
+
+var buildCmd = "go build -o " + os.DevNull + " main.go"
+
+// healthyResults returns the results of the commands when all the
+// prerequisites are met.
+func healthyResults() map[string]fakeResult {
+	return map[string]fakeResult{
+		authListCmd:     {out: "dev@example.com"},
+		servicesListCmd: {out: "run.googleapis.com\ncloudbuild.googleapis.com\nartifactregistry.googleapis.com\nsecretmanager.googleapis.com"},
+		regionsListCmd:  {out: "europe-west1\nus-central1"},
+		goVersionCmd:    {out: "go1.24.4"},
+		goModVersionCmd: {out: "1.24"},
+	}
+}
+
+func newPreflightFlags() *deployCloudRunFlags {
+	return &deployCloudRunFlags{
+		gcloud:   gCloudFlags{region: "europe-west1", projectName: "my-project"},
+		cloudRun: cloudRunServiceFlags{minInstances: -1, maxInstances: -1},
+		build:    buildFlags{goos: "linux", goarch: "arm64"},
+		source:   sourceFlags{srcBasePath: "/src/agent", entryPointPath: "main.go"},
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	errFailed := errors.New("exit status 1")
+	tests := []struct {
+		name       string
+		modify     func(f *deployCloudRunFlags, results map[string]fakeResult)
+		wantErrs   []string
+		wantNoCmds []string // commands which must not be run
+	}{
+		{
+			name:   "all prerequisites met",
+			modify: func(*deployCloudRunFlags, map[string]fakeResult) {},
+		},
+		{
+			name: "gcloud not installed",
+			modify: func(_ *deployCloudRunFlags, results map[string]fakeResult) {
+				results["gcloud version"] = fakeResult{err: exec.ErrNotFound}
+			},
+			wantErrs:   []string{"gcloud can't be run, install the Google Cloud CLI"},
+			wantNoCmds: []string{authListCmd, servicesListCmd},
+		},
+		{
+			name: "not logged in",
+			modify: func(_ *deployCloudRunFlags, results map[string]fakeResult) {
+				results[authListCmd] = fakeResult{}
+			},
+			wantErrs:   []string{"no active gcloud account, run 'gcloud auth login'"},
+			wantNoCmds: []string{describeCmd},
+		},
+		{
+			name: "missing project and region",
+			modify: func(f *deployCloudRunFlags, _ map[string]fakeResult) {
+				f.gcloud = gCloudFlags{}
+			},
+			wantErrs:   []string{"--project_name is required", "--region is required"},
+			wantNoCmds: []string{describeCmd},
+		},
+		{
+			name: "inaccessible project",
+			modify: func(_ *deployCloudRunFlags, results map[string]fakeResult) {
+				results[describeCmd] = fakeResult{err: errFailed}
+			},
+			wantErrs:   []string{`project "my-project" can't be accessed: exit status 1`},
+			wantNoCmds: []string{servicesListCmd},
+		},
+		{
+			name: "APIs not enabled",
+			modify: func(f *deployCloudRunFlags, results map[string]fakeResult) {
+				f.cloudRun.envVars = []string{"GOOGLE_GENAI_USE_VERTEXAI=true"}
+				results[servicesListCmd] = fakeResult{out: "cloudbuild.googleapis.com"}
+			},
+			wantErrs:   []string{"'gcloud services enable run.googleapis.com artifactregistry.googleapis.com aiplatform.googleapis.com --project my-project'"},
+			wantNoCmds: []string{regionsListCmd},
+		},
+		{
+			name: "unknown region",
+			modify: func(f *deployCloudRunFlags, _ map[string]fakeResult) {
+				f.gcloud.region = "europe-west99"
+			},
+			wantErrs: []string{`region "europe-west99" isn't a Cloud Run region`},
+		},
+		{
+			name: "go not installed",
+			modify: func(_ *deployCloudRunFlags, results map[string]fakeResult) {
+				results[goVersionCmd] = fakeResult{err: exec.ErrNotFound}
+			},
+			wantErrs:   []string{"go can't be run, install Go"},
+			wantNoCmds: []string{buildCmd},
+		},
+		{
+			name: "old go toolchain",
+			modify: func(_ *deployCloudRunFlags, results map[string]fakeResult) {
+				results[goVersionCmd] = fakeResult{out: "go1.23.2"}
+				results[goModVersionCmd] = fakeResult{out: "1.24.4"}
+			},
+			wantErrs:   []string{"the Go toolchain go1.23.2 is older than go1.24.4"},
+			wantNoCmds: []string{buildCmd},
+		},
+		{
+			name: "all problems at once",
+			modify: func(_ *deployCloudRunFlags, results map[string]fakeResult) {
+				results[servicesListCmd] = fakeResult{out: "run.googleapis.com"}
+				results[regionsListCmd] = fakeResult{out: "us-central1"}
+				results[buildCmd] = fakeResult{err: errors.New("exit status 1: main.go:3:1: syntax error")}
+			},
+			wantErrs: []string{
+				"3 problem(s) found",
+				"cloudbuild.googleapis.com artifactregistry.googleapis.com secretmanager.googleapis.com",
+				`region "europe-west1" isn't a Cloud Run region`,
+				"entry point main.go doesn't compile: exit status 1: main.go:3:1: syntax error",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPreflightFlags()
+			results := healthyResults()
+			tt.modify(f, results)
+			runner := &fakeRunner{results: results}
+
+			err := f.preflight(runner.run)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("preflight() error = %v", err)
+				}
+			}
+			for _, want := range tt.wantErrs {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("preflight() error = %v, want it to contain %q", err, want)
+				}
+			}
+			var ran []string
+			for _, cmd := range runner.cmds {
+				ran = append(ran, strings.Join(cmd.Args, " "))
+			}
+			for _, cmd := range tt.wantNoCmds {
+				if slices.Contains(ran, cmd) {
+					t.Errorf("preflight() ran %q, want it skipped", cmd)
+				}
+			}
+		})
+	}
+}
+
+func TestPreflight_BuildCommand(t *testing.T) {
+	f := newPreflightFlags()
+	runner := &fakeRunner{results: healthyResults()}
+	if err := f.preflight(runner.run); err != nil {
+		t.Fatalf("preflight() error = %v", err)
+	}
+	i := slices.IndexFunc(runner.cmds, func(cmd *exec.Cmd) bool { return strings.Join(cmd.Args, " ") == buildCmd })
+	if i < 0 {
+		t.Fatalf("preflight() didn't compile the entry point, ran %v", runner.cmds)
+	}
+	build := runner.cmds[i]
+	if build.Dir != "/src/agent" {
+		t.Errorf("build ran in %q, want %q", build.Dir, "/src/agent")
+	}
+	for _, want := range []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm64"} {
+		if !slices.Contains(build.Env, want) {
+			t.Errorf("build environment doesn't contain %q", want)
+		}
+	}
+}
+
+func TestDeploy_RemovesTempDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		dryRun  bool
+		wantErr bool
+	}{
+		{name: "dry run", source: "package main\n\nfunc main() {}\n", dryRun: true},
+		{name: "compilation fails", source: "package main\n\nfunc main() {\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(srcDir, "go.mod"), []byte("module example.com/agent\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(tt.source), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			tempDir := t.TempDir()
+			f := newPreflightFlags()
+			f.source = sourceFlags{entryPointPath: filepath.Join(srcDir, "main.go")}
+			f.build = buildFlags{goos: "linux", goarch: "amd64", tempDir: tempDir}
+			f.cloudRun.serviceName = "agent"
+			f.dryRun = tt.dryRun
+			f.skipPreflight = true
+
+			if err := f.deploy(); (err != nil) != tt.wantErr {
+				t.Fatalf("deploy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			entries, err := os.ReadDir(tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) > 0 {
+				t.Errorf("deploy() left %v in the temp dir", entries)
+			}
+		})
+	}
+}
+
+func TestPrintDryRun(t *testing.T) {
+	dir := t.TempDir()
+	f := newPreflightFlags()
+	f.cloudRun.serviceName = "agent"
+	f.cloudRun.envVars = []string{"GREETING=hello world"}
+	f.build.execFile = "main"
+	f.build.dockerfileBuildPath = filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(f.build.dockerfileBuildPath, []byte("\nFROM scratch\nCMD [\"/app/main\"]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := f.printDryRun(&b); err != nil {
+		t.Fatalf("printDryRun() error = %v", err)
+	}
+	want := `Dockerfile:
+FROM scratch
+CMD ["/app/main"]
+
+Command, run in the directory of the Dockerfile and the main executable:
+gcloud run deploy agent --source . --region europe-west1 --project my-project --ingress all --set-secrets=GOOGLE_API_KEY=GOOGLE_API_KEY:latest '--set-env-vars=GREETING=hello world' --no-allow-unauthenticated
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("printDryRun() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"gcloud", "--set-env-vars=A=1,B=2", "--memory=512Mi", "", "it's", "a b", "$HOME"})
+	want := `gcloud --set-env-vars=A=1,B=2 --memory=512Mi '' 'it'\''s' 'a b' '$HOME'`
+	if got != want {
+		t.Errorf("shellJoin() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubernetes implements the "deploy k8s" command which deploys
+// the application to a Kubernetes cluster.
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"google.golang.org/adk/cmd/adkgo/internal/deploy"
+	"google.golang.org/adk/internal/cli/util"
+)
+
+type imageFlags struct {
+	name      string // full image reference, e.g. registry/repo:tag
+	baseImage string
+	push      bool
+}
+
+type workloadFlags struct {
+	appName       string
+	namespace     string
+	replicas      int
+	serverPort    int
+	serviceType   string
+	cpuRequest    string
+	memoryRequest string
+	cpuLimit      string
+	memoryLimit   string
+	secretName    string
+	secretKey     string
+}
+
+type serverFlags struct {
+	a2aAgentCardURL  string
+	webUIAddress     string
+	apiServerAddress string
+	a2a              bool // enable a2a or not
+	api              bool // enable api or not
+	webui            bool // enable webui or not
+}
+
+type outputFlags struct {
+	manifestPath string
+	apply        bool
+}
+
+type buildFlags struct {
+	goos                string
+	goarch              string
+	tempDir             string
+	execPath            string
+	execFile            string
+	dockerfileBuildPath string
+	manifestBuildPath   string
+}
+
+type sourceFlags struct {
+	srcBasePath    string
+	entryPointPath string
+}
+
+type deployKubernetesFlags struct {
+	image    imageFlags
+	workload workloadFlags
+	server   serverFlags
+	output   outputFlags
+	build    buildFlags
+	source   sourceFlags
+}
+
+var flags deployKubernetesFlags
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Deploys the application to Kubernetes.",
+	Long: `Deployment prepares a Dockerfile which is fed with locally compiled server executable containing Web UI static files.
+	A container image is built and pushed using docker.
+	A Deployment and a Service manifest is generated and either written to a file or applied using kubectl.
+	GOOGLE_API_KEY is read from a Kubernetes Secret, which has to be created beforehand.
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return flags.deployOnKubernetes()
+	},
+}
+
+func init() {
+	deploy.DeployCmd.AddCommand(k8sCmd)
+
+	k8sCmd.PersistentFlags().StringVarP(&flags.image.name, "image", "i", "", "Container image to build and push, e.g. 'europe-docker.pkg.dev/project/repo/agent:v1'")
+	k8sCmd.PersistentFlags().StringVar(&flags.image.baseImage, "base_image", deploy.DefaultBaseImage, "Base image of the container")
+	k8sCmd.PersistentFlags().BoolVar(&flags.image.push, "push", true, "Push the built image")
+	k8sCmd.PersistentFlags().StringVarP(&flags.workload.appName, "app_name", "s", "", "Name of the Kubernetes Deployment and Service")
+	k8sCmd.PersistentFlags().StringVarP(&flags.workload.namespace, "namespace", "n", "default", "Kubernetes namespace")
+	k8sCmd.PersistentFlags().IntVar(&flags.workload.replicas, "replicas", 1, "Number of replicas")
+	k8sCmd.PersistentFlags().IntVar(&flags.workload.serverPort, "server_port", 8080, "Server port")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.serviceType, "service_type", "ClusterIP", "Kubernetes Service type, e.g. ClusterIP or LoadBalancer")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.cpuRequest, "cpu_request", "250m", "CPU request of the container")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.memoryRequest, "memory_request", "256Mi", "Memory request of the container")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.cpuLimit, "cpu_limit", "1", "CPU limit of the container")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.memoryLimit, "memory_limit", "512Mi", "Memory limit of the container")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.secretName, "secret_name", "adk-secrets", "Name of the Secret holding GOOGLE_API_KEY")
+	k8sCmd.PersistentFlags().StringVar(&flags.workload.secretKey, "secret_key", "GOOGLE_API_KEY", "Key of GOOGLE_API_KEY in the Secret")
+	k8sCmd.PersistentFlags().StringVar(&flags.build.goos, "goos", deploy.DefaultGOOS, "Target operating system of the server executable")
+	k8sCmd.PersistentFlags().StringVar(&flags.build.goarch, "goarch", deploy.DefaultGOARCH, "Target architecture of the server executable and the image, e.g. amd64 or arm64")
+	k8sCmd.PersistentFlags().StringVarP(&flags.build.tempDir, "temp_dir", "t", "", "Temp dir for build, defaults to os.TempDir() if not specified")
+	k8sCmd.PersistentFlags().StringVarP(&flags.source.entryPointPath, "entry_point_path", "e", "", "Path to an entry point (go 'main')")
+	k8sCmd.PersistentFlags().BoolVar(&flags.server.a2a, "a2a", true, "Enable A2A")
+	k8sCmd.PersistentFlags().StringVarP(&flags.server.a2aAgentCardURL, "a2a_agent_url", "a", "http://127.0.0.1:8080", "A2A agent card URL as advertised in the public agent card")
+	k8sCmd.PersistentFlags().BoolVar(&flags.server.api, "api", true, "Enable API")
+	k8sCmd.PersistentFlags().StringVar(&flags.server.webUIAddress, "webui_address", "127.0.0.1:8080", "Web UI address as seen from the user browser, used to allow CORS requests")
+	k8sCmd.PersistentFlags().BoolVar(&flags.server.webui, "webui", true, "Enable Web UI")
+	k8sCmd.PersistentFlags().StringVar(&flags.server.apiServerAddress, "api_server_address", "http://127.0.0.1:8080/api", "REST API address as seen from the user browser")
+	k8sCmd.PersistentFlags().StringVarP(&flags.output.manifestPath, "output", "o", "", "File to write the manifest to, printed to stdout if not specified")
+	k8sCmd.PersistentFlags().BoolVar(&flags.output.apply, "apply", false, "Apply the manifest using kubectl")
+}
+
+func (f *deployKubernetesFlags) computeFlags() error {
+	return util.LogStartStop("Computing flags & preparing temp",
+		func(p util.Printer) error {
+			if f.image.name == "" {
+				return fmt.Errorf("--image is required")
+			}
+			if f.workload.appName == "" {
+				return fmt.Errorf("--app_name is required")
+			}
+			if f.workload.replicas < 0 {
+				return fmt.Errorf("--replicas must not be negative, got %d", f.workload.replicas)
+			}
+			if err := f.build.platform().Validate(); err != nil {
+				return err
+			}
+
+			absp, err := filepath.Abs(f.source.entryPointPath)
+			if err != nil {
+				return fmt.Errorf("cannot make an absolute path from '%v': %w", f.source.entryPointPath, err)
+			}
+			f.source.entryPointPath = absp
+
+			if f.build.tempDir == "" {
+				f.build.tempDir = os.TempDir()
+			}
+			absp, err = filepath.Abs(f.build.tempDir)
+			if err != nil {
+				return fmt.Errorf("cannot make an absolute path from '%v': %w", f.build.tempDir, err)
+			}
+			f.build.tempDir, err = os.MkdirTemp(absp, "k8s_"+time.Now().Format("20060102_150405__")+"*")
+			if err != nil {
+				return fmt.Errorf("cannot create a temporary sub directory in '%v': %w", absp, err)
+			}
+			p("Using temp dir:", f.build.tempDir)
+
+			// come up with a executable name based on entry point path
+			dir, file := path.Split(f.source.entryPointPath)
+			f.source.srcBasePath = dir
+			f.source.entryPointPath = file
+			if f.build.execPath == "" {
+				exec, err := util.StripExtension(f.source.entryPointPath, ".go")
+				if err != nil {
+					return fmt.Errorf("cannot strip '.go' extension from entry point path '%v': %w", f.source.entryPointPath, err)
+				}
+				f.build.execFile = exec
+				f.build.execPath = path.Join(f.build.tempDir, exec)
+			}
+			f.build.dockerfileBuildPath = path.Join(f.build.tempDir, "Dockerfile")
+			f.build.manifestBuildPath = path.Join(f.build.tempDir, "manifest.yaml")
+
+			return nil
+		})
+}
+
+func (f *buildFlags) platform() deploy.Platform {
+	return deploy.Platform{GOOS: f.goos, GOARCH: f.goarch}
+}
+
+func (f *deployKubernetesFlags) cleanTemp() error {
+	return util.LogStartStop("Cleaning temp",
+		func(p util.Printer) error {
+			p("Clean temp starting with", f.build.tempDir)
+			err := os.RemoveAll(f.build.tempDir)
+			if err != nil {
+				return fmt.Errorf("failed to clean temp directory %v: %w", f.build.tempDir, err)
+			}
+			return nil
+		})
+}
+
+func (f *deployKubernetesFlags) compileEntryPoint() error {
+	return util.LogStartStop("Compiling server",
+		func(p util.Printer) error {
+			return deploy.CompileEntryPoint(p, f.source.srcBasePath, f.source.entryPointPath, f.build.execPath, f.build.platform())
+		})
+}
+
+func (f *deployKubernetesFlags) prepareDockerfile() error {
+	return util.LogStartStop("Preparing Dockerfile",
+		func(p util.Printer) error {
+			p("Writing:", f.build.dockerfileBuildPath)
+
+			dockerfile := deploy.Dockerfile(deploy.ServerFlags{
+				ExecFile:         f.build.execFile,
+				ServerPort:       f.workload.serverPort,
+				BaseImage:        f.image.baseImage,
+				Platform:         f.build.platform(),
+				API:              f.server.api,
+				WebUIAddress:     f.server.webUIAddress,
+				A2A:              f.server.a2a,
+				A2AAgentURL:      f.server.a2aAgentCardURL,
+				WebUI:            f.server.webui,
+				APIServerAddress: f.server.apiServerAddress,
+			})
+			return os.WriteFile(f.build.dockerfileBuildPath, []byte(dockerfile), 0o600)
+		})
+}
+
+func (f *deployKubernetesFlags) buildImage() error {
+	return util.LogStartStop("Building container image",
+		func(p util.Printer) error {
+			cmd := exec.Command("docker", "build", "--platform", f.build.platform().String(), "-t", f.image.name, ".")
+			cmd.Dir = f.build.tempDir
+			return util.LogCommand(cmd, p)
+		})
+}
+
+func (f *deployKubernetesFlags) pushImage() error {
+	return util.LogStartStop("Pushing container image",
+		func(p util.Printer) error {
+			cmd := exec.Command("docker", "push", f.image.name)
+			return util.LogCommand(cmd, p)
+		})
+}
+
+func (f *deployKubernetesFlags) prepareManifest() error {
+	return util.LogStartStop("Preparing manifest",
+		func(p util.Printer) error {
+			p("Writing:", f.build.manifestBuildPath)
+
+			var b strings.Builder
+			if err := manifestTemplate.Execute(&b, f.workload.toManifestParams(f.image.name)); err != nil {
+				return fmt.Errorf("failed to render manifest: %w", err)
+			}
+			return os.WriteFile(f.build.manifestBuildPath, []byte(b.String()), 0o600)
+		})
+}
+
+func (f *deployKubernetesFlags) outputManifest() error {
+	manifest, err := os.ReadFile(f.build.manifestBuildPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest %v: %w", f.build.manifestBuildPath, err)
+	}
+	if f.output.manifestPath == "" {
+		if !f.output.apply {
+			fmt.Println(string(manifest))
+		}
+		return nil
+	}
+	return util.LogStartStop("Writing manifest",
+		func(p util.Printer) error {
+			p("Writing:", f.output.manifestPath)
+			return os.WriteFile(f.output.manifestPath, manifest, 0o644)
+		})
+}
+
+func (f *deployKubernetesFlags) applyManifest() error {
+	return util.LogStartStop("Applying manifest",
+		func(p util.Printer) error {
+			cmd := exec.Command("kubectl", "apply", "-f", f.build.manifestBuildPath)
+			return util.LogCommand(cmd, p)
+		})
+}
+
+func (f *deployKubernetesFlags) deployOnKubernetes() error {
+	steps := []func() error{
+		f.computeFlags,
+		f.compileEntryPoint,
+		f.prepareDockerfile,
+		f.buildImage,
+	}
+	if f.image.push {
+		steps = append(steps, f.pushImage)
+	}
+	steps = append(steps, f.prepareManifest, f.outputManifest)
+	if f.output.apply {
+		steps = append(steps, f.applyManifest)
+	}
+	steps = append(steps, f.cleanTemp)
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import "text/template"
+
+// manifestParams are the values used to render the manifest.
+type manifestParams struct {
+	AppName       string
+	Namespace     string
+	Image         string
+	Replicas      int
+	ServerPort    int
+	ServiceType   string
+	CPURequest    string
+	MemoryRequest string
+	CPULimit      string
+	MemoryLimit   string
+	SecretName    string
+	SecretKey     string
+}
+
+func (w workloadFlags) toManifestParams(image string) manifestParams {
+	return manifestParams{
+		AppName:       w.appName,
+		Namespace:     w.namespace,
+		Image:         image,
+		Replicas:      w.replicas,
+		ServerPort:    w.serverPort,
+		ServiceType:   w.serviceType,
+		CPURequest:    w.cpuRequest,
+		MemoryRequest: w.memoryRequest,
+		CPULimit:      w.cpuLimit,
+		MemoryLimit:   w.memoryLimit,
+		SecretName:    w.secretName,
+		SecretKey:     w.secretKey,
+	}
+}
+
+var manifestTemplate = template.Must(template.New("manifest").Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.AppName}}
+  namespace: {{.Namespace}}
+  labels:
+    app: {{.AppName}}
+spec:
+  replicas: {{.Replicas}}
+  selector:
+    matchLabels:
+      app: {{.AppName}}
+  template:
+    metadata:
+      labels:
+        app: {{.AppName}}
+    spec:
+      containers:
+        - name: {{.AppName}}
+          image: {{.Image}}
+          ports:
+            - containerPort: {{.ServerPort}}
+          env:
+            - name: GOOGLE_API_KEY
+              valueFrom:
+                secretKeyRef:
+                  name: {{.SecretName}}
+                  key: {{.SecretKey}}
+          resources:
+            requests:
+              cpu: "{{.CPURequest}}"
+              memory: "{{.MemoryRequest}}"
+            limits:
+              cpu: "{{.CPULimit}}"
+              memory: "{{.MemoryLimit}}"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{.AppName}}
+  namespace: {{.Namespace}}
+  labels:
+    app: {{.AppName}}
+spec:
+  type: {{.ServiceType}}
+  selector:
+    app: {{.AppName}}
+  ports:
+    - port: {{.ServerPort}}
+      targetPort: {{.ServerPort}}
+`))
@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestManifestTemplate(t *testing.T) {
+	w := workloadFlags{
+		appName:       "weather-agent",
+		namespace:     "agents",
+		replicas:      3,
+		serverPort:    8080,
+		serviceType:   "LoadBalancer",
+		cpuRequest:    "250m",
+		memoryRequest: "256Mi",
+		cpuLimit:      "1",
+		memoryLimit:   "512Mi",
+		secretName:    "adk-secrets",
+		secretKey:     "api-key",
+	}
+	var b strings.Builder
+	if err := manifestTemplate.Execute(&b, w.toManifestParams("registry/weather:v1")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		"kind: Deployment",
+		"kind: Service",
+		"namespace: agents",
+		"replicas: 3",
+		"image: registry/weather:v1",
+		"containerPort: 8080",
+		"name: adk-secrets\n                  key: api-key",
+		"cpu: \"1\"\n              memory: \"512Mi\"",
+		"type: LoadBalancer",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("manifest does not contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package evalcmd handles command line parameters and execution logic for
+// the evaluation of an agent against an eval set.
+package evalcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/cmd/adkgo/internal/root"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/internal/cli/util"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/model"
+)
+
+type evalFlags struct {
+	agent         string // path to an entry point or app name
+	app           string // app served by the entry point
+	evalSetPath   string
+	criteria      string // comma-separated metric=threshold pairs
+	reportPath    string
+	parallelism   int
+	modelOverride string
+}
+
+var flags evalFlags
+
+// loader resolves the app names passed to --agent to agents run in-process.
+// Apps which aren't found are expected to be paths to entry points.
+var loader agent.Loader
+
+// criteriaAliases are the short names of the built-in metrics accepted by
+// --criteria.
+var criteriaAliases = map[string]string{
+	"tool_trajectory":         eval.MetricToolTrajectoryAvgScore,
+	"tool_trajectory_partial": eval.MetricToolTrajectoryPartialScore,
+	"response_match":          eval.MetricResponseMatchScore,
+}
+
+// evalCmd represents the eval command
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Evaluates an agent against an eval set.",
+	Long: `Replays the cases of an eval set through the agent, scores them with the criteria and writes a JSON report.
+	The agent is either the path to an entry point (go 'main'), which is built and served with the API launcher,
+	or the name of an app loaded in-process.
+	The command fails if any case doesn't pass, so it can gate CI.
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		metrics, err := parseCriteria(flags.criteria)
+		if err != nil {
+			return err
+		}
+		if flags.parallelism < 1 {
+			return fmt.Errorf("--parallelism must be at least 1, got %d", flags.parallelism)
+		}
+		// The flags are valid, the errors below aren't usage errors.
+		cmd.SilenceUsage = true
+		return flags.run(cmd.Context(), cmd.OutOrStdout(), metrics)
+	},
+}
+
+// init creates flags and adds subcommand to parent
+func init() {
+	root.RootCmd.AddCommand(evalCmd)
+
+	evalCmd.PersistentFlags().StringVar(&flags.agent, "agent", "", "Path to an entry point (go 'main'), or name of an app loaded in-process")
+	evalCmd.PersistentFlags().StringVar(&flags.app, "app", "", "App to evaluate when the entry point serves several apps")
+	evalCmd.PersistentFlags().StringVar(&flags.evalSetPath, "evalset", "", "Path to the eval set, e.g. weather.evalset.json")
+	evalCmd.PersistentFlags().StringVar(&flags.criteria, "criteria", "", "Comma-separated metric=threshold pairs, e.g. tool_trajectory=1.0,response_match=0.8. Defaults to the default metrics of the eval package")
+	evalCmd.PersistentFlags().StringVar(&flags.reportPath, "report", "eval_report.json", "Path of the JSON report")
+	evalCmd.PersistentFlags().IntVar(&flags.parallelism, "parallelism", 1, "Maximal number of eval cases run concurrently")
+	evalCmd.PersistentFlags().StringVar(&flags.modelOverride, "model-override", "", "Model replacing the models of the LLM agents, e.g. gemini-2.5-flash. Only for apps loaded in-process")
+	_ = evalCmd.MarkPersistentFlagRequired("agent")
+	_ = evalCmd.MarkPersistentFlagRequired("evalset")
+}
+
+// parseCriteria parses the --criteria flag. The metrics are the short names
+// in criteriaAliases or the names of built-in metrics.
+func parseCriteria(s string) ([]eval.Metric, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var metrics []eval.Metric
+	for pair := range strings.SplitSeq(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid criterion %q: expected metric=threshold", pair)
+		}
+		if alias, ok := criteriaAliases[name]; ok {
+			name = alias
+		}
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold of criterion %q: %w", pair, err)
+		}
+		m := eval.Metric{Name: name, Threshold: threshold}
+		if _, err := m.Criterion(); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// evaluator runs the cases of an eval set.
+type evaluator interface {
+	appName() string
+	evaluate(ctx context.Context, set *eval.Set, metrics []eval.Metric, parallelism int) ([]*eval.CaseResult, error)
+	close() error
+}
+
+// report is the JSON report written by the command.
+type report struct {
+	AppName string          `json:"appName"`
+	Result  *eval.SetResult `json:"result"`
+	Summary eval.Summary    `json:"summary"`
+}
+
+func (f *evalFlags) run(ctx context.Context, w io.Writer, metrics []eval.Metric) (err error) {
+	set, err := eval.LoadSet(f.evalSetPath)
+	if err != nil {
+		return err
+	}
+	e, err := f.evaluator(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := e.close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	var results []*eval.CaseResult
+	err = util.LogStartStop("Evaluating "+set.ID, func(p util.Printer) error {
+		p("Running", len(set.Cases), "eval cases of app", e.appName(), "with parallelism", f.parallelism)
+		var err error
+		results, err = e.evaluate(ctx, set, metrics, f.parallelism)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	r := &report{
+		AppName: e.appName(),
+		Result:  eval.NewSetResult(e.appName(), set.ID, results),
+		Summary: eval.Summarize(results),
+	}
+	if err := printResults(w, r); err != nil {
+		return err
+	}
+	if err := writeReport(f.reportPath, r); err != nil {
+		return err
+	}
+	if r.Summary.Passed < r.Summary.Total {
+		return fmt.Errorf("%d of %d eval cases didn't pass", r.Summary.Total-r.Summary.Passed, r.Summary.Total)
+	}
+	return nil
+}
+
+// evaluator returns the evaluator of the --agent flag: an entry point if
+// the path exists, an app loaded in-process otherwise.
+func (f *evalFlags) evaluator(ctx context.Context) (evaluator, error) {
+	if _, err := os.Stat(f.agent); err == nil {
+		if f.modelOverride != "" {
+			return nil, fmt.Errorf("--model-override is only supported for apps loaded in-process")
+		}
+		return startServer(ctx, f.agent, f.app)
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("entry point %q not found", f.agent)
+	}
+	a, err := loader.LoadAgent(f.agent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent %q: %w", f.agent, err)
+	}
+	if f.modelOverride != "" {
+		llm, err := model.Resolve(ctx, f.modelOverride)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create model %q: %w", f.modelOverride, err)
+		}
+		overrideModel(a, llm)
+	}
+	return &localEvaluator{name: f.agent, agent: a}, nil
+}
+
+// overrideModel replaces the models of the LLM agents of the tree.
+func overrideModel(a agent.Agent, llm model.LLM) {
+	if la, ok := a.(llminternal.Agent); ok {
+		llminternal.Reveal(la).Model = llm
+	}
+	for _, sub := range a.SubAgents() {
+		overrideModel(sub, llm)
+	}
+}
+
+// localEvaluator runs the cases in-process.
+type localEvaluator struct {
+	name  string
+	agent agent.Agent
+}
+
+func (e *localEvaluator) appName() string { return e.name }
+
+func (e *localEvaluator) evaluate(ctx context.Context, set *eval.Set, metrics []eval.Metric, parallelism int) ([]*eval.CaseResult, error) {
+	return eval.Run(ctx, eval.Config{
+		AppName:     e.name,
+		Agent:       e.agent,
+		SetID:       set.ID,
+		Metrics:     metrics,
+		Parallelism: parallelism,
+	}, set.Cases)
+}
+
+func (e *localEvaluator) close() error { return nil }
+
+// printResults prints a table of the case results and the summary.
+func printResults(w io.Writer, r *report) error {
+	var metricNames []string
+	for _, c := range r.Result.CaseResults {
+		for _, m := range c.OverallMetricResults {
+			if !slices.Contains(metricNames, m.MetricName) {
+				metricNames = append(metricNames, m.MetricName)
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "CASE\tSTATUS")
+	for _, name := range metricNames {
+		fmt.Fprint(tw, "\t", strings.ToUpper(name))
+	}
+	fmt.Fprintln(tw)
+	for _, c := range r.Result.CaseResults {
+		fmt.Fprint(tw, c.CaseID, "\t", colored(c.FinalStatus, c.FinalStatus.String()))
+		for _, name := range metricNames {
+			fmt.Fprint(tw, "\t", metricCell(c, name))
+		}
+		if c.Error != "" {
+			fmt.Fprint(tw, "\t", c.Error)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	s := r.Summary
+	_, err := fmt.Fprintf(w, "\n%s passed, %s failed, %s not evaluated, out of %d eval cases\n",
+		colored(eval.StatusPassed, strconv.Itoa(s.Passed)),
+		colored(eval.StatusFailed, strconv.Itoa(s.Failed)),
+		colored(eval.StatusNotEvaluated, strconv.Itoa(s.NotEvaluated)),
+		s.Total)
+	return err
+}
+
+// metricCell returns the overall score of the metric and its threshold.
+func metricCell(c *eval.CaseResult, name string) string {
+	for _, m := range c.OverallMetricResults {
+		if m.MetricName != name {
+			continue
+		}
+		if m.Score == nil {
+			return colored(m.Status, "n/a")
+		}
+		return colored(m.Status, fmt.Sprintf("%.2f/%.2f", *m.Score, m.Threshold))
+	}
+	return colored(eval.StatusNotEvaluated, "-")
+}
+
+// colored colors the text with the color of the status. All the colors have
+// the same length, which keeps the table aligned.
+func colored(s eval.Status, text string) string {
+	color := util.Yellow
+	switch s {
+	case eval.StatusPassed:
+		color = util.Green
+	case eval.StatusFailed:
+		color = util.Red
+	}
+	return color + text + util.Reset
+}
+
+// writeReport writes the report as indented JSON.
+func writeReport(path string, r *report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evalcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/cmd/adkgo/internal/root"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+)
+
+const evalSet = `{
+  "eval_set_id": "greetings",
+  "eval_cases": [
+    {
+      "eval_id": "hello",
+      "conversation": [
+        {
+          "user_content": {"parts": [{"text": "hello"}], "role": "user"},
+          "final_response": {"parts": [{"text": "Hello there!"}], "role": "model"}
+        }
+      ]
+    }
+  ]
+}`
+
+// setup registers an agent answering with the response as the app
+// "greeter", and writes the eval set. It returns the path of the eval set
+// and of the report.
+func setup(t *testing.T, response string) (string, string) {
+	t.Helper()
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "greeter",
+		Model: &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText(response, genai.RoleModel)}},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	loader = agent.NewSingleLoader(a)
+	t.Cleanup(func() { loader = nil })
+
+	dir := t.TempDir()
+	setPath := filepath.Join(dir, "greetings.evalset.json")
+	if err := os.WriteFile(setPath, []byte(evalSet), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return setPath, filepath.Join(dir, "report.json")
+}
+
+// execute runs the eval command with the args, and returns its output.
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	root.RootCmd.SetOut(&out)
+	root.RootCmd.SetErr(&out)
+	root.RootCmd.SetArgs(append([]string{"eval", "--model-override", "", "--parallelism", "1", "--criteria", ""}, args...))
+	// cobra keeps the context of the first execution in the subcommand,
+	// which is canceled when the test that ran it ends.
+	evalCmd.SetContext(t.Context())
+	err := root.RootCmd.ExecuteContext(t.Context())
+	return out.String(), err
+}
+
+func readReport(t *testing.T, path string) *report {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	var r report
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	return &r
+}
+
+func TestEvalCmd(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		criteria   string
+		wantErr    bool
+		wantStatus eval.Status
+	}{
+		{
+			name:       "passes",
+			response:   "Hello there!",
+			criteria:   "response_match=0.8",
+			wantStatus: eval.StatusPassed,
+		},
+		{
+			name:       "fails below threshold",
+			response:   "Goodbye.",
+			criteria:   "response_match=0.8",
+			wantErr:    true,
+			wantStatus: eval.StatusFailed,
+		},
+		{
+			name:       "passes lower threshold",
+			response:   "Hello!",
+			criteria:   "response_match_score=0.5,tool_trajectory=1",
+			wantStatus: eval.StatusPassed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPath, reportPath := setup(t, tt.response)
+
+			out, err := execute(t, "--agent", "greeter", "--evalset", setPath, "--report", reportPath, "--criteria", tt.criteria)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("eval error = %v, want error %v, output:\n%s", err, tt.wantErr, out)
+			}
+			if !strings.Contains(out, "hello") || !strings.Contains(out, tt.wantStatus.String()) {
+				t.Errorf("output = %q, want the case and its status %v", out, tt.wantStatus)
+			}
+
+			r := readReport(t, reportPath)
+			if r.AppName != "greeter" || len(r.Result.CaseResults) != 1 {
+				t.Fatalf("report = %+v, want one case of greeter", r)
+			}
+			if got := r.Result.CaseResults[0].FinalStatus; got != tt.wantStatus {
+				t.Errorf("report status = %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEvalCmd_ModelOverride(t *testing.T) {
+	setPath, reportPath := setup(t, "Goodbye.")
+	model.Register("evalcmd-test-.*", func(ctx context.Context, name string) (model.LLM, error) {
+		return &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("Hello there!", genai.RoleModel)}}, nil
+	})
+
+	out, err := execute(t, "--agent", "greeter", "--evalset", setPath, "--report", reportPath, "--model-override", "evalcmd-test-model")
+	if err != nil {
+		t.Fatalf("eval error = %v, output:\n%s", err, out)
+	}
+	if got := readReport(t, reportPath).Summary.Passed; got != 1 {
+		t.Errorf("passed cases = %d, want 1", got)
+	}
+}
+
+func TestEvalCmd_Errors(t *testing.T) {
+	setPath, reportPath := setup(t, "Hello there!")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown app", args: []string{"--agent", "missing", "--evalset", setPath}},
+		{name: "missing eval set", args: []string{"--agent", "greeter", "--evalset", filepath.Join(t.TempDir(), "missing.evalset.json")}},
+		{name: "unsupported metric", args: []string{"--agent", "greeter", "--evalset", setPath, "--criteria", "unknown=1"}},
+		{name: "invalid threshold", args: []string{"--agent", "greeter", "--evalset", setPath, "--criteria", "response_match=high"}},
+		{name: "unknown model", args: []string{"--agent", "greeter", "--evalset", setPath, "--model-override", "unknown-model"}},
+		{name: "invalid parallelism", args: []string{"--agent", "greeter", "--evalset", setPath, "--parallelism", "0"}},
+		{name: "model override of entry point", args: []string{"--agent", setPath, "--evalset", setPath, "--model-override", "evalcmd-test-model"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out, err := execute(t, append(tt.args, "--report", reportPath)...); err == nil {
+				t.Errorf("eval succeeded, want an error, output:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestParseCriteria(t *testing.T) {
+	got, err := parseCriteria("tool_trajectory=1.0, response_match=0.8,tool_trajectory_partial_score=0.5")
+	if err != nil {
+		t.Fatalf("parseCriteria() error = %v", err)
+	}
+	want := []eval.Metric{
+		{Name: eval.MetricToolTrajectoryAvgScore, Threshold: 1},
+		{Name: eval.MetricResponseMatchScore, Threshold: 0.8},
+		{Name: eval.MetricToolTrajectoryPartialScore, Threshold: 0.5},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseCriteria() mismatch (-want +got):\n%s", diff)
+	}
+}
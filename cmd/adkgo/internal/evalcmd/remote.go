@@ -0,0 +1,283 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evalcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/cmd/adkgo/internal/deploy"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/internal/cli/util"
+)
+
+const (
+	// serverStartTimeout is how long the server has to become healthy.
+	serverStartTimeout = 30 * time.Second
+	// serverStopTimeout is how long the server has to shut down gracefully.
+	serverStopTimeout = 10 * time.Second
+)
+
+// remoteEvaluator runs the cases through the eval API of a server built from
+// an entry point.
+type remoteEvaluator struct {
+	app     string
+	baseURL string // URL of the API, e.g. http://127.0.0.1:8080/api
+	tempDir string
+	cmd     *exec.Cmd
+	logs    *logBuffer // output of the server
+	exited  chan struct{}
+}
+
+// startServer builds the entry point and starts it with the API launcher
+// on a free local port. The app is the only app served if empty.
+func startServer(ctx context.Context, entryPointPath, app string) (*remoteEvaluator, error) {
+	e := &remoteEvaluator{app: app, logs: &logBuffer{}, exited: make(chan struct{})}
+	err := util.LogStartStop("Starting server", func(p util.Printer) error {
+		absp, err := filepath.Abs(entryPointPath)
+		if err != nil {
+			return fmt.Errorf("cannot make an absolute path from '%v': %w", entryPointPath, err)
+		}
+		srcBasePath, entryPoint := filepath.Split(absp)
+		if info, err := os.Stat(absp); err == nil && info.IsDir() {
+			srcBasePath, entryPoint = absp, "."
+		}
+		e.tempDir, err = os.MkdirTemp("", "adkgo_eval_*")
+		if err != nil {
+			return fmt.Errorf("cannot create a temporary directory: %w", err)
+		}
+		execPath := filepath.Join(e.tempDir, "server")
+		platform := deploy.Platform{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}
+		if err := deploy.CompileEntryPoint(p, srcBasePath, entryPoint, execPath, platform); err != nil {
+			return fmt.Errorf("failed to build %q: %w", entryPointPath, err)
+		}
+
+		port, err := freePort()
+		if err != nil {
+			return err
+		}
+		e.baseURL = "http://127.0.0.1:" + strconv.Itoa(port) + "/api"
+		e.cmd = exec.Command(execPath, "web", "-port", strconv.Itoa(port), "api")
+		// The server only listens locally, it doesn't need the API keys of
+		// the environment.
+		e.cmd.Env = append(os.Environ(), "ADK_API_KEYS=")
+		e.cmd.Stdout = e.logs
+		e.cmd.Stderr = e.logs
+		p("Running", e.cmd)
+		if err := e.cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start server: %w", err)
+		}
+		go func() {
+			_ = e.cmd.Wait()
+			close(e.exited)
+		}()
+		if err := e.waitHealthy(ctx, "http://127.0.0.1:"+strconv.Itoa(port)+"/healthz"); err != nil {
+			return fmt.Errorf("%w, server output:\n%s", err, e.logs)
+		}
+		return e.resolveApp()
+	})
+	if err != nil {
+		return nil, errors.Join(err, e.close())
+	}
+	return e, nil
+}
+
+// logBuffer is a buffer safe for concurrent use.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// freePort returns a local TCP port nothing listens on.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitHealthy polls the health URL until the server responds.
+func (e *remoteEvaluator) waitHealthy(ctx context.Context, healthURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, serverStartTimeout)
+	defer cancel()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		select {
+		case <-e.exited:
+			return fmt.Errorf("server exited")
+		case <-ctx.Done():
+			return fmt.Errorf("server isn't healthy after %v: %w", serverStartTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolveApp checks that the server serves the app, or picks its only app.
+func (e *remoteEvaluator) resolveApp() error {
+	var apps []string
+	if err := e.call(context.Background(), http.MethodGet, "/list-apps", nil, &apps); err != nil {
+		return err
+	}
+	switch {
+	case e.app != "":
+		if !slices.Contains(apps, e.app) {
+			return fmt.Errorf("app %q isn't served, available apps: %v", e.app, apps)
+		}
+	case len(apps) == 1:
+		e.app = apps[0]
+	default:
+		return fmt.Errorf("the server serves %d apps, pick one with --app: %v", len(apps), apps)
+	}
+	return nil
+}
+
+func (e *remoteEvaluator) appName() string { return e.app }
+
+// evaluate creates the eval set on the server, and runs its cases, each in
+// its own request.
+func (e *remoteEvaluator) evaluate(ctx context.Context, set *eval.Set, metrics []eval.Metric, parallelism int) ([]*eval.CaseResult, error) {
+	setPath := "/apps/" + url.PathEscape(e.app) + "/eval_sets/" + url.PathEscape(set.ID)
+	if err := e.call(ctx, http.MethodPost, setPath, set, nil); err != nil {
+		return nil, fmt.Errorf("failed to create eval set: %w", err)
+	}
+
+	results := make([]*eval.CaseResult, len(set.Cases))
+	errs := make([]error, len(set.Cases))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, c := range set.Cases {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			req := map[string]any{"evalIds": []string{c.ID}, "evalMetrics": metrics}
+			var caseResults []*eval.CaseResult
+			if err := e.call(ctx, http.MethodPost, setPath+"/run_eval", req, &caseResults); err != nil {
+				errs[i] = fmt.Errorf("failed to run eval case %q: %w", c.ID, err)
+				return
+			}
+			if len(caseResults) != 1 {
+				errs[i] = fmt.Errorf("failed to run eval case %q: got %d results", c.ID, len(caseResults))
+				return
+			}
+			results[i] = caseResults[0]
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// call sends a JSON request to the API, and decodes the JSON response into
+// out if not nil.
+func (e *remoteEvaluator) call(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(data))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// close stops the server and removes the executable.
+func (e *remoteEvaluator) close() error {
+	if e.cmd != nil && e.cmd.Process != nil {
+		if err := e.cmd.Process.Signal(os.Interrupt); err != nil {
+			// Interrupts aren't supported on Windows.
+			_ = e.cmd.Process.Kill()
+		}
+		select {
+		case <-e.exited:
+		case <-time.After(serverStopTimeout):
+			_ = e.cmd.Process.Kill()
+			<-e.exited
+		}
+	}
+	if e.tempDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(e.tempDir); err != nil {
+		return fmt.Errorf("failed to clean temp directory %v: %w", e.tempDir, err)
+	}
+	return nil
+}
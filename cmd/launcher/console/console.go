@@ -22,13 +22,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/universal"
 	"google.golang.org/adk/internal/cli/util"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 )
@@ -37,6 +40,8 @@ import (
 type consoleConfig struct {
 	streamingMode       agent.StreamingMode
 	streamingModeString string // command-line param to be converted to agent.StreamingMode
+	maxDuration         time.Duration
+	showUsage           bool
 }
 
 // consoleLauncher allows to interact with an agent in console
@@ -52,6 +57,8 @@ func NewLauncher() launcher.SubLauncher {
 	fs := flag.NewFlagSet("console", flag.ContinueOnError)
 	fs.StringVar(&config.streamingModeString, "streaming_mode", string(agent.StreamingModeSSE),
 		fmt.Sprintf("defines streaming mode (%s|%s)", agent.StreamingModeNone, agent.StreamingModeSSE))
+	fs.DurationVar(&config.maxDuration, "max_duration", 0, "limits how long the agent may take to answer a single message, e.g. 2m. Zero means no limit")
+	fs.BoolVar(&config.showUsage, "show_usage", false, "prints the tokens used by the agent, and their cost if pricing is configured, after each answer")
 
 	return &consoleLauncher{config: config, flags: fs}
 }
@@ -66,24 +73,24 @@ func (l *consoleLauncher) Run(ctx context.Context, config *launcher.Config) erro
 		sessionService = session.InMemoryService()
 	}
 
-	resp, err := sessionService.Create(ctx, &session.CreateRequest{
-		AppName: appName,
-		UserID:  userID,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create the session service: %v", err)
-	}
+	// A new session is created by the first run.
+	sessionID := uuid.NewString()
 
 	rootAgent := config.AgentLoader.RootAgent()
 
-	session := resp.Session
-
-	r, err := runner.New(runner.Config{
-		AppName:         appName,
-		Agent:           rootAgent,
-		SessionService:  sessionService,
-		ArtifactService: config.ArtifactService,
-	})
+	runnerConfig := runner.Config{
+		AppName:           appName,
+		Agent:             rootAgent,
+		SessionService:    sessionService,
+		ArtifactService:   config.ArtifactService,
+		AutoCreateSession: true,
+	}
+	if l.config.showUsage {
+		runnerConfig.UsageCallback = func(ctx context.Context, usage *model.Usage) {
+			printUsage(usage, config.Pricing)
+		}
+	}
+	r, err := runner.New(runnerConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create runner: %v", err)
 	}
@@ -106,20 +113,18 @@ func (l *consoleLauncher) Run(ctx context.Context, config *launcher.Config) erro
 		}
 		fmt.Print("\nAgent -> ")
 		prevText := ""
-		for event, err := range r.Run(ctx, userID, session.ID(), userMsg, agent.RunConfig{
+		for event, err := range r.Run(ctx, userID, sessionID, userMsg, agent.RunConfig{
 			StreamingMode: streamingMode,
+			MaxDuration:   l.config.maxDuration,
 		}) {
 			if err != nil {
 				fmt.Printf("\nAGENT_ERROR: %v\n", err)
 			} else {
-				if event.LLMResponse.Content == nil {
+				if event == nil || event.LLMResponse.Content == nil {
 					continue
 				}
 
-				text := ""
-				for _, p := range event.LLMResponse.Content.Parts {
-					text += p.Text
-				}
+				text := event.Text()
 
 				if streamingMode != agent.StreamingModeSSE {
 					fmt.Print(text)
@@ -144,6 +149,16 @@ func (l *consoleLauncher) Run(ctx context.Context, config *launcher.Config) erro
 	}
 }
 
+// printUsage prints the tokens used by a run and, with pricing, their cost.
+func printUsage(usage *model.Usage, pricing model.PricingFunc) {
+	total := usage.Total()
+	fmt.Printf("\n[usage: %d prompt tokens, %d candidate tokens, %d total tokens", total.PromptTokens, total.CandidateTokens, total.TotalTokens)
+	if pricing != nil {
+		fmt.Printf(", cost %.6f", usage.Cost(pricing))
+	}
+	fmt.Println("]")
+}
+
 // Parse implements launcher.SubLauncher. After parsing console-specific
 // arguments returns remaining un-parsed arguments
 func (l *consoleLauncher) Parse(args []string) ([]string, error) {
@@ -0,0 +1,52 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gcsuri registers the gs:// scheme of the artifact services of
+// package serviceuri: gs://BUCKET stores the artifacts in the Google Cloud
+// Storage bucket.
+//
+// The scheme is not built into the launchers, to keep the Cloud Storage
+// client out of the binaries not using it. Import the package for its side
+// effect in the main package to enable it:
+//
+//	import _ "google.golang.org/adk/cmd/launcher/serviceuri/gcsuri"
+package gcsuri
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/adk/artifact"
+	gcs "google.golang.org/adk/artifact/gcsartifact"
+	"google.golang.org/adk/cmd/launcher/serviceuri"
+)
+
+func init() {
+	serviceuri.RegisterArtifactScheme("gs", newArtifactService)
+}
+
+// newGCSService creates the Cloud Storage artifact service, replaced in tests.
+var newGCSService = gcs.NewService
+
+func newArtifactService(ctx context.Context, u *url.URL) (artifact.Service, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing bucket name, e.g. gs://my-bucket")
+	}
+	if strings.Trim(u.Path, "/") != "" {
+		return nil, fmt.Errorf("bucket paths are not supported, use gs://%s", u.Host)
+	}
+	return newGCSService(ctx, u.Host)
+}
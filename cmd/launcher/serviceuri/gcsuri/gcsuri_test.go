@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcsuri
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/cmd/launcher/serviceuri"
+)
+
+func TestArtifactService(t *testing.T) {
+	var gotBucket string
+	orig := newGCSService
+	newGCSService = func(ctx context.Context, bucketName string, opts ...option.ClientOption) (artifact.Service, error) {
+		gotBucket = bucketName
+		return artifact.InMemoryService(), nil
+	}
+	t.Cleanup(func() { newGCSService = orig })
+
+	for _, uri := range []string{"gs://my-bucket", "gs://my-bucket/"} {
+		gotBucket = ""
+		if _, err := serviceuri.ArtifactService(t.Context(), uri); err != nil {
+			t.Fatalf("ArtifactService(%q) error = %v", uri, err)
+		}
+		if gotBucket != "my-bucket" {
+			t.Errorf("ArtifactService(%q) created a service for bucket %q, want %q", uri, gotBucket, "my-bucket")
+		}
+	}
+}
+
+func TestArtifactService_Errors(t *testing.T) {
+	tests := []struct {
+		uri     string
+		wantErr string
+	}{
+		{uri: "gs://", wantErr: "missing bucket name"},
+		{uri: "gs://bucket/artifacts", wantErr: "bucket paths are not supported"},
+	}
+	for _, tt := range tests {
+		_, err := serviceuri.ArtifactService(t.Context(), tt.uri)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ArtifactService(%q) error = %v, want it to contain %q", tt.uri, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serviceuri creates the session and artifact services of the
+// launchers from URIs, e.g. "sqlite:///var/lib/agent/sessions.db" or
+// "gs://my-bucket", so that the services of a deployed server are chosen by
+// its configuration rather than in code.
+//
+// The memory:// scheme is built in for both kinds of services. The other
+// schemes are registered by packages imported for their side effect in the
+// main package, so that the launchers only depend on the backends they use:
+//   - sqlite://PATH session services, see package sqliteuri. SQLite requires
+//     cgo.
+//   - gs://BUCKET artifact services (Google Cloud Storage), see package
+//     gcsuri.
+//
+// Other backends, e.g. agentengine:// or firestore://, are added with
+// [RegisterSessionScheme] and [RegisterArtifactScheme].
+package serviceuri
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/session"
+)
+
+// Environment variables holding the URIs of the services of a deployed
+// server, used by the web launcher when its flags are not set.
+const (
+	SessionServiceEnv  = "ADK_SESSION_SERVICE_URI"
+	ArtifactServiceEnv = "ADK_ARTIFACT_SERVICE_URI"
+)
+
+// SessionFactory creates a session service from the URI.
+type SessionFactory func(ctx context.Context, uri *url.URL) (session.Service, error)
+
+// ArtifactFactory creates an artifact service from the URI.
+type ArtifactFactory func(ctx context.Context, uri *url.URL) (artifact.Service, error)
+
+var (
+	registryMu        sync.RWMutex
+	sessionFactories  = map[string]SessionFactory{}
+	artifactFactories = map[string]ArtifactFactory{}
+)
+
+func init() {
+	RegisterSessionScheme("memory", func(context.Context, *url.URL) (session.Service, error) {
+		return session.InMemoryService(), nil
+	})
+	RegisterArtifactScheme("memory", func(context.Context, *url.URL) (artifact.Service, error) {
+		return artifact.InMemoryService(), nil
+	})
+}
+
+// RegisterSessionScheme registers the factory of the session services with
+// URIs of the scheme. Registering the same scheme again replaces its factory,
+// which allows overriding the built-in ones.
+//
+// RegisterSessionScheme panics if the factory is nil.
+func RegisterSessionScheme(scheme string, f SessionFactory) {
+	if f == nil {
+		panic(fmt.Sprintf("serviceuri: nil session factory registered for scheme %q", scheme))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	sessionFactories[scheme] = f
+}
+
+// RegisterArtifactScheme registers the factory of the artifact services with
+// URIs of the scheme. Registering the same scheme again replaces its factory,
+// which allows overriding the built-in ones.
+//
+// RegisterArtifactScheme panics if the factory is nil.
+func RegisterArtifactScheme(scheme string, f ArtifactFactory) {
+	if f == nil {
+		panic(fmt.Sprintf("serviceuri: nil artifact factory registered for scheme %q", scheme))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	artifactFactories[scheme] = f
+}
+
+// SessionService creates the session service of the URI with the factory
+// registered for its scheme.
+func SessionService(ctx context.Context, uri string) (session.Service, error) {
+	u, err := Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	f, err := lookup(sessionFactories, "session", u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	s, err := f(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session service %q: %w", uri, err)
+	}
+	return s, nil
+}
+
+// ArtifactService creates the artifact service of the URI with the factory
+// registered for its scheme.
+func ArtifactService(ctx context.Context, uri string) (artifact.Service, error) {
+	u, err := Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	f, err := lookup(artifactFactories, "artifact", u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	s, err := f(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact service %q: %w", uri, err)
+	}
+	return s, nil
+}
+
+// Parse parses a service URI, which must have a scheme. It doesn't check
+// whether a factory is registered for the scheme.
+func Parse(uri string) (*url.URL, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service URI %q: %w", uri, err)
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("invalid service URI %q: missing scheme, e.g. memory://", uri)
+	}
+	return u, nil
+}
+
+func lookup[F any](factories map[string]F, kind, scheme string) (F, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if f, ok := factories[scheme]; ok {
+		return f, nil
+	}
+	var zero F
+	schemes := make([]string, 0, len(factories))
+	for s := range factories {
+		schemes = append(schemes, s)
+	}
+	slices.Sort(schemes)
+	return zero, fmt.Errorf("no %s service registered for scheme %q, registered schemes: [%s]", kind, scheme, strings.Join(schemes, ", "))
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceuri
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/session"
+)
+
+func TestMemoryServices(t *testing.T) {
+	s, err := SessionService(t.Context(), "memory://")
+	if err != nil {
+		t.Fatalf("SessionService(memory://) error = %v", err)
+	}
+	if got, want := reflect.TypeOf(s), reflect.TypeOf(session.InMemoryService()); got != want {
+		t.Errorf("SessionService(memory://) = %v, want %v", got, want)
+	}
+	a, err := ArtifactService(t.Context(), "memory://")
+	if err != nil {
+		t.Fatalf("ArtifactService(memory://) error = %v", err)
+	}
+	if got, want := reflect.TypeOf(a), reflect.TypeOf(artifact.InMemoryService()); got != want {
+		t.Errorf("ArtifactService(memory://) = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterScheme(t *testing.T) {
+	var gotSessionURI, gotArtifactURI string
+	RegisterSessionScheme("firestore", func(_ context.Context, u *url.URL) (session.Service, error) {
+		gotSessionURI = u.String()
+		return session.InMemoryService(), nil
+	})
+	RegisterArtifactScheme("firestore", func(_ context.Context, u *url.URL) (artifact.Service, error) {
+		gotArtifactURI = u.String()
+		return artifact.InMemoryService(), nil
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(sessionFactories, "firestore")
+		delete(artifactFactories, "firestore")
+	})
+
+	const uri = "firestore://my-project/sessions?database=agents"
+	if _, err := SessionService(t.Context(), uri); err != nil {
+		t.Fatalf("SessionService(%q) error = %v", uri, err)
+	}
+	if _, err := ArtifactService(t.Context(), uri); err != nil {
+		t.Fatalf("ArtifactService(%q) error = %v", uri, err)
+	}
+	if gotSessionURI != uri || gotArtifactURI != uri {
+		t.Errorf("factories got URIs %q and %q, want %q", gotSessionURI, gotArtifactURI, uri)
+	}
+}
+
+func TestServiceURIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		create  func(ctx context.Context, uri string) error
+		uri     string
+		wantErr string
+	}{
+		{name: "missing scheme", create: createSession, uri: "sessions.db", wantErr: "missing scheme"},
+		{name: "invalid URI", create: createSession, uri: "sqlite://%zz", wantErr: "invalid service URI"},
+		{name: "unknown session scheme", create: createSession, uri: "agentengine://projects/p/locations/l/reasoningEngines/1", wantErr: `no session service registered for scheme "agentengine", registered schemes: [memory]`},
+		// The schemes of the other packages are only registered when they are imported.
+		{name: "sqlite not imported", create: createSession, uri: "sqlite:///tmp/sessions.db", wantErr: `no session service registered for scheme "sqlite"`},
+		{name: "unknown artifact scheme", create: createArtifact, uri: "s3://bucket", wantErr: `no artifact service registered for scheme "s3", registered schemes: [memory]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.create(t.Context(), tt.uri)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("creating service %q error = %v, want it to contain %q", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func createSession(ctx context.Context, uri string) error {
+	_, err := SessionService(ctx, uri)
+	return err
+}
+
+func createArtifact(ctx context.Context, uri string) error {
+	_, err := ArtifactService(ctx, uri)
+	return err
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqliteuri registers the sqlite:// scheme of the session services
+// of package serviceuri: sqlite://PATH, the database is created if needed,
+// e.g. sqlite:///tmp/sessions.db or sqlite://sessions.db relative to the
+// working directory.
+//
+// SQLite requires cgo, so the scheme is not built into the launchers. Import
+// the package for its side effect in the main package to enable it:
+//
+//	import _ "google.golang.org/adk/cmd/launcher/serviceuri/sqliteuri"
+package sqliteuri
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"gorm.io/driver/sqlite"
+
+	"google.golang.org/adk/cmd/launcher/serviceuri"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+)
+
+func init() {
+	serviceuri.RegisterSessionScheme("sqlite", newSessionService)
+}
+
+func newSessionService(_ context.Context, u *url.URL) (session.Service, error) {
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return nil, fmt.Errorf("missing database path, e.g. sqlite:///tmp/sessions.db")
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	s, err := database.NewSessionService(sqlite.Open(path))
+	if err != nil {
+		return nil, err
+	}
+	if err := database.AutoMigrate(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqliteuri_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/cmd/launcher/serviceuri"
+	_ "google.golang.org/adk/cmd/launcher/serviceuri/sqliteuri"
+	"google.golang.org/adk/session"
+)
+
+func TestSessionService(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	tests := []struct {
+		name   string
+		uri    string
+		wantDB string // database file created by the service
+	}{
+		{name: "absolute path", uri: "sqlite://" + filepath.Join(dir, "abs.db"), wantDB: filepath.Join(dir, "abs.db")},
+		{name: "relative path", uri: "sqlite://rel.db", wantDB: filepath.Join(dir, "rel.db")},
+		{name: "relative path in directory", uri: "sqlite://./data.db", wantDB: filepath.Join(dir, "data.db")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := serviceuri.SessionService(t.Context(), tt.uri)
+			if err != nil {
+				t.Fatalf("SessionService(%q) error = %v", tt.uri, err)
+			}
+			if _, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if _, err := os.Stat(tt.wantDB); err != nil {
+				t.Errorf("SessionService(%q) didn't create the database: %v", tt.uri, err)
+			}
+		})
+	}
+}
+
+func TestSessionService_MissingPath(t *testing.T) {
+	_, err := serviceuri.SessionService(t.Context(), "sqlite://")
+	if err == nil || !strings.Contains(err.Error(), "missing database path") {
+		t.Errorf("SessionService(sqlite://) error = %v, want a missing database path error", err)
+	}
+}
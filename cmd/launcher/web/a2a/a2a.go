@@ -12,17 +12,32 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package a2a provides a sublauncher that adds A2A capabilities to the web server
+// Package a2a provides a sublauncher that adds A2A capabilities to the web server.
+//
+// The A2A protocol is served over the JSON-RPC transport, with message/stream
+// responses streamed as server-sent events, and over the gRPC transport, on
+// the port of the web server. Both transports are advertised in the agent
+// card.
 package a2a
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	a2acore "github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2agrpc"
+	"github.com/a2aproject/a2a-go/a2apb"
 	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/push"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/web"
@@ -31,12 +46,17 @@ import (
 	"google.golang.org/adk/server/adka2a"
 )
 
-// apiPath is a suffix used to build an A2A invocation URL
+// apiPath is a suffix used to build the A2A JSON-RPC invocation URL
 const apiPath = "/a2a/invoke"
 
+// grpcPathPrefix is the path prefix of the A2A gRPC methods.
+var grpcPathPrefix = "/" + a2apb.A2AService_ServiceDesc.ServiceName + "/"
+
 // a2aConfig contains parameters for launching ADK A2A server
 type a2aConfig struct {
-	agentURL string // user-provided url which will be used in the agent card to specify url for invoking A2A
+	agentURL           string // user-provided url which will be used in the agent card to specify url for invoking A2A
+	preferredTransport string // transport advertised as preferred in the agent card
+	pushNotifications  bool   // whether clients can register webhooks to receive task updates
 }
 
 type a2aLauncher struct {
@@ -50,7 +70,9 @@ func NewLauncher() web.Sublauncher {
 
 	fs := flag.NewFlagSet("a2a", flag.ContinueOnError)
 
-	fs.StringVar(&config.agentURL, "a2a_agent_url", "http://localhost:8080", "A2A host URL as advertised in the public agent card. It is used by A2A clients as a connection endpoint.")
+	fs.StringVar(&config.agentURL, "a2a_agent_url", "", "A2A host URL as advertised in the public agent card. It is used by A2A clients as a connection endpoint. If empty, the URL the agent card was requested on is used, including the X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix headers set by proxies.")
+	fs.StringVar(&config.preferredTransport, "a2a_preferred_transport", string(a2acore.TransportProtocolJSONRPC), "A2A transport advertised as preferred in the public agent card: JSONRPC or GRPC. Both transports are served.")
+	fs.BoolVar(&config.pushNotifications, "a2a_push_notifications", false, "Enables A2A push notifications. Clients can register a webhook URL per task and receive task updates on it. Push configs are stored in memory.")
 
 	return &a2aLauncher{
 		config: config,
@@ -73,30 +95,48 @@ func (a *a2aLauncher) Parse(args []string) ([]string, error) {
 	if err != nil || !a.flags.Parsed() {
 		return nil, fmt.Errorf("failed to parse a2a flags: %v", err)
 	}
+	switch a2acore.TransportProtocol(a.config.preferredTransport) {
+	case a2acore.TransportProtocolJSONRPC, a2acore.TransportProtocolGRPC:
+	default:
+		return nil, fmt.Errorf("invalid a2a_preferred_transport %q, want %s or %s", a.config.preferredTransport, a2acore.TransportProtocolJSONRPC, a2acore.TransportProtocolGRPC)
+	}
 	restArgs := a.flags.Args()
 	return restArgs, nil
 }
 
 // SetupSubrouters implements the web.Sublauncher interface. It adds A2A paths to the main router.
 func (a *a2aLauncher) SetupSubrouters(router *mux.Router, config *launcher.Config) error {
-	publicURL, err := url.JoinPath(a.config.agentURL, apiPath)
-	if err != nil {
-		return err
+	var agentURL *url.URL
+	if a.config.agentURL != "" {
+		u, err := url.Parse(a.config.agentURL)
+		if err != nil {
+			return fmt.Errorf("invalid a2a_agent_url: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid a2a_agent_url %q: scheme and host are required", a.config.agentURL)
+		}
+		agentURL = u
 	}
 
 	rootAgent := config.AgentLoader.RootAgent()
 	agentCard := &a2acore.AgentCard{
 		Name:                              rootAgent.Name(),
 		Description:                       rootAgent.Description(),
-		DefaultInputModes:                 []string{"text/plain"},
-		DefaultOutputModes:                []string{"text/plain"},
-		URL:                               publicURL,
-		PreferredTransport:                a2acore.TransportProtocolJSONRPC,
+		DefaultInputModes:                 adka2a.DefaultInputModes,
+		DefaultOutputModes:                adka2a.DefaultOutputModes,
+		PreferredTransport:                a2acore.TransportProtocol(a.config.preferredTransport),
 		Skills:                            adka2a.BuildAgentSkills(rootAgent),
-		Capabilities:                      a2acore.AgentCapabilities{Streaming: true},
+		Capabilities:                      a2acore.AgentCapabilities{Streaming: true, PushNotifications: a.config.pushNotifications},
 		SupportsAuthenticatedExtendedCard: false,
 	}
-	router.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(agentCard))
+	router.Handle(a2asrv.WellKnownAgentCardPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		card, err := withInterfaces(agentCard, cmp.Or(agentURL, requestURL(r)))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a2asrv.NewStaticAgentCardHandler(card).ServeHTTP(w, r)
+	}))
 
 	agent := config.AgentLoader.RootAgent()
 	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
@@ -107,17 +147,90 @@ func (a *a2aLauncher) SetupSubrouters(router *mux.Router, config *launcher.Confi
 			ArtifactService: config.ArtifactService,
 		},
 	})
-	reqHandler := a2asrv.NewHandler(executor, config.A2AOptions...)
-	router.Handle(apiPath, a2asrv.NewJSONRPCHandler(reqHandler))
+	options := config.A2AOptions
+	if a.config.pushNotifications {
+		options = append(slices.Clone(options), a2asrv.WithPushNotifications(push.NewInMemoryStore(), push.NewHTTPPushSender(nil)))
+	}
+	reqHandler := a2asrv.NewHandler(executor, options...)
+	router.Handle(apiPath, streaming(a2asrv.NewJSONRPCHandler(reqHandler)))
+
+	grpcServer := grpc.NewServer()
+	a2agrpc.NewHandler(reqHandler).RegisterWith(grpcServer)
+	router.Methods(http.MethodPost).PathPrefix(grpcPathPrefix).MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+		return isGRPC(r)
+	}).Handler(streaming(grpcServer))
 	return nil
 }
 
+// withInterfaces returns a copy of card advertising the JSON-RPC and gRPC
+// transports served on baseURL, the preferred one as the card URL.
+func withInterfaces(card *a2acore.AgentCard, baseURL *url.URL) (*a2acore.AgentCard, error) {
+	jsonrpcURL, err := url.JoinPath(baseURL.String(), apiPath)
+	if err != nil {
+		return nil, err
+	}
+	// gRPC targets are host:port, the methods are served on the root path.
+	grpcTarget := baseURL.Host
+	if _, _, err := net.SplitHostPort(grpcTarget); err != nil {
+		port := "80"
+		if baseURL.Scheme == "https" {
+			port = "443"
+		}
+		grpcTarget = net.JoinHostPort(baseURL.Hostname(), port)
+	}
+
+	c := *card
+	c.AdditionalInterfaces = []a2acore.AgentInterface{
+		{Transport: a2acore.TransportProtocolJSONRPC, URL: jsonrpcURL},
+		{Transport: a2acore.TransportProtocolGRPC, URL: grpcTarget},
+	}
+	for _, i := range c.AdditionalInterfaces {
+		if i.Transport == c.PreferredTransport {
+			c.URL = i.URL
+		}
+	}
+	return &c, nil
+}
+
+// requestURL returns the base URL r was sent to. The scheme, host and path
+// prefix set by proxies in the X-Forwarded-Proto, X-Forwarded-Host and
+// X-Forwarded-Prefix headers take precedence.
+func requestURL(r *http.Request) *url.URL {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	forwardedProto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	forwardedHost, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Host"), ",")
+	return &url.URL{
+		Scheme: cmp.Or(strings.TrimSpace(forwardedProto), scheme),
+		Host:   cmp.Or(strings.TrimSpace(forwardedHost), r.Host),
+		Path:   r.Header.Get("X-Forwarded-Prefix"),
+	}
+}
+
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// streaming clears the write timeout of the web server for the responses
+// which stream events: the server-sent events of the JSON-RPC transport and
+// the gRPC calls.
+func streaming(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPC(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 // SimpleDescription implements web.Sublauncher
 func (a *a2aLauncher) SimpleDescription() string {
-	return fmt.Sprintf("starts A2A server which handles jsonrpc requests on %s path", apiPath)
+	return fmt.Sprintf("starts A2A server which handles jsonrpc requests on %s path and grpc requests", apiPath)
 }
 
 // UserMessage implements web.Sublauncher.
 func (a *a2aLauncher) UserMessage(webUrl string, printer func(v ...any)) {
-	printer(fmt.Sprintf("       a2a:  you can access A2A using jsonrpc protocol: %s", webUrl))
+	printer(fmt.Sprintf("       a2a:  you can access A2A using jsonrpc protocol: %s%s or grpc protocol on the same port", webUrl, apiPath))
 }
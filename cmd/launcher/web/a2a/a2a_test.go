@@ -15,16 +15,27 @@
 package a2a
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"iter"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	a2acore "github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2aclient"
 	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
 	"google.golang.org/genai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/cmd/launcher"
@@ -130,3 +141,325 @@ func TestWebLauncher_ServesA2A(t *testing.T) {
 		t.Fatalf("task.Artifacts[0].Parts[0] = %v, want %v", parts[0], a2acore.TextPart{Text: wantMessage})
 	}
 }
+
+func TestWebLauncher_PushNotifications(t *testing.T) {
+	ctx := t.Context()
+
+	var mu sync.Mutex
+	var pushed []*a2acore.Task
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var task a2acore.Task
+		if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+			t.Errorf("failed to decode pushed task: %v", err)
+		}
+		if got := r.Header.Get("X-A2A-Notification-Token"); got != "secret" {
+			t.Errorf("push token = %q, want %q", got, "secret")
+		}
+		mu.Lock()
+		pushed = append(pushed, &task)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	port := getFreePort(t)
+	l := web.NewLauncher(NewLauncher())
+	_, err := l.Parse([]string{
+		"--port", strconv.Itoa(port),
+		"a2a", "--a2a_agent_url", "http://localhost:" + strconv.Itoa(port), "--a2a_push_notifications",
+	})
+	if err != nil {
+		t.Fatalf("web.NewLauncher() error = %v", err)
+	}
+
+	agnt, err := agent.New(agent.Config{
+		Name: "HelloWorldAgent",
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ic.InvocationID())
+				event.Content = genai.NewContentFromText("Hello, world!", genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	config := &launcher.Config{
+		AgentLoader:    agent.NewSingleLoader(agnt),
+		SessionService: session.InMemoryService(),
+	}
+
+	go func() {
+		if err := l.Run(t.Context(), config); err != nil {
+			t.Errorf("launcher.Run() error = %v", err)
+		}
+	}()
+
+	var card *a2acore.AgentCard
+	for retry := range 3 {
+		time.Sleep(10 * time.Millisecond) // give server time to start
+		card, err = agentcard.DefaultResolver.Resolve(ctx, "http://localhost:"+strconv.Itoa(port))
+		if err == nil {
+			break
+		}
+		if retry == 2 {
+			t.Fatalf("cardResolver.Resolve() error = %v", err)
+		}
+	}
+	if !card.Capabilities.PushNotifications {
+		t.Errorf("card.Capabilities.PushNotifications = false, want true")
+	}
+
+	client, err := a2aclient.NewFromCard(ctx, card)
+	if err != nil {
+		t.Fatalf("a2aclient.NewFromCard() error = %v", err)
+	}
+	got, err := client.SendMessage(ctx, &a2acore.MessageSendParams{
+		Message: a2acore.NewMessage(a2acore.MessageRoleUser, a2acore.TextPart{Text: "Hi!"}),
+		Config: &a2acore.MessageSendConfig{
+			PushConfig: &a2acore.PushConfig{URL: webhook.URL, Token: "secret"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("client.SendMessage() error = %v", err)
+	}
+	task, ok := got.(*a2acore.Task)
+	if !ok {
+		t.Fatalf("client.SendMessage() result type = %T, want a2a.Task", got)
+	}
+
+	configs, err := client.ListTaskPushConfig(ctx, &a2acore.ListTaskPushConfigParams{TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("client.ListTaskPushConfig() error = %v", err)
+	}
+	if len(configs) != 1 || configs[0].Config.URL != webhook.URL {
+		t.Errorf("client.ListTaskPushConfig() = %v, want a config with URL %q", configs, webhook.URL)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushed) == 0 {
+		t.Fatal("no push notifications received")
+	}
+	last := pushed[len(pushed)-1]
+	if last.ID != task.ID || last.Status.State != a2acore.TaskStateCompleted {
+		t.Errorf("last pushed task = {ID: %s, State: %s}, want {ID: %s, State: %s}", last.ID, last.Status.State, task.ID, a2acore.TaskStateCompleted)
+	}
+}
+
+func TestWebLauncher_Transports(t *testing.T) {
+	ctx := t.Context()
+
+	port := getFreePort(t)
+	l := web.NewLauncher(NewLauncher())
+	if _, err := l.Parse([]string{"--port", strconv.Itoa(port), "a2a"}); err != nil {
+		t.Fatalf("web.NewLauncher() error = %v", err)
+	}
+
+	wantMessage := "Hello, world!"
+	agnt, err := agent.New(agent.Config{
+		Name: "HelloWorldAgent",
+		Run: func(ic agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ic.InvocationID())
+				event.Content = genai.NewContentFromText(wantMessage, genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	config := &launcher.Config{
+		AgentLoader:    agent.NewSingleLoader(agnt),
+		SessionService: session.InMemoryService(),
+	}
+
+	go func() {
+		if err := l.Run(t.Context(), config); err != nil {
+			t.Errorf("launcher.Run() error = %v", err)
+		}
+	}()
+
+	var card *a2acore.AgentCard
+	for retry := range 3 {
+		time.Sleep(10 * time.Millisecond) // give server time to start
+		card, err = agentcard.DefaultResolver.Resolve(ctx, "http://localhost:"+strconv.Itoa(port))
+		if err == nil {
+			break
+		}
+		if retry == 2 {
+			t.Fatalf("cardResolver.Resolve() error = %v", err)
+		}
+	}
+	wantURL := "http://localhost:" + strconv.Itoa(port) + apiPath
+	if card.URL != wantURL || card.PreferredTransport != a2acore.TransportProtocolJSONRPC {
+		t.Errorf("card = {URL: %q, PreferredTransport: %q}, want {URL: %q, PreferredTransport: %q}", card.URL, card.PreferredTransport, wantURL, a2acore.TransportProtocolJSONRPC)
+	}
+
+	t.Run("JSON-RPC streaming", func(t *testing.T) {
+		client, err := a2aclient.NewFromCard(ctx, card)
+		if err != nil {
+			t.Fatalf("a2aclient.NewFromCard() error = %v", err)
+		}
+		var gotText string
+		var gotFinal bool
+		for event, err := range client.SendStreamingMessage(ctx, &a2acore.MessageSendParams{
+			Message: a2acore.NewMessage(a2acore.MessageRoleUser, a2acore.TextPart{Text: "Hi!"}),
+		}) {
+			if err != nil {
+				t.Fatalf("client.SendStreamingMessage() error = %v", err)
+			}
+			switch e := event.(type) {
+			case *a2acore.TaskArtifactUpdateEvent:
+				for _, p := range e.Artifact.Parts {
+					if tp, ok := p.(a2acore.TextPart); ok {
+						gotText += tp.Text
+					}
+				}
+			case *a2acore.TaskStatusUpdateEvent:
+				gotFinal = gotFinal || e.Final
+			}
+		}
+		if gotText != wantMessage {
+			t.Errorf("streamed artifact text = %q, want %q", gotText, wantMessage)
+		}
+		if !gotFinal {
+			t.Error("no final status update streamed")
+		}
+	})
+
+	t.Run("gRPC", func(t *testing.T) {
+		client, err := a2aclient.NewFromCard(ctx, card,
+			a2aclient.WithConfig(a2aclient.Config{PreferredTransports: []a2acore.TransportProtocol{a2acore.TransportProtocolGRPC}}),
+			a2aclient.WithGRPCTransport(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			t.Fatalf("a2aclient.NewFromCard() error = %v", err)
+		}
+		got, err := client.SendMessage(ctx, &a2acore.MessageSendParams{
+			Message: a2acore.NewMessage(a2acore.MessageRoleUser, a2acore.TextPart{Text: "Hi!"}),
+		})
+		if err != nil {
+			t.Fatalf("client.SendMessage() error = %v", err)
+		}
+		task, ok := got.(*a2acore.Task)
+		if !ok {
+			t.Fatalf("client.SendMessage() result type = %T, want a2a.Task", got)
+		}
+		if task.Status.State != a2acore.TaskStateCompleted || len(task.Artifacts) != 1 {
+			t.Fatalf("client.SendMessage() = {State: %s, Artifacts: %d}, want {State: %s, Artifacts: 1}", task.Status.State, len(task.Artifacts), a2acore.TaskStateCompleted)
+		}
+		if gotPart, ok := task.Artifacts[0].Parts[0].(a2acore.TextPart); !ok || gotPart.Text != wantMessage {
+			t.Errorf("task.Artifacts[0].Parts[0] = %v, want %v", task.Artifacts[0].Parts[0], a2acore.TextPart{Text: wantMessage})
+		}
+	})
+}
+
+func TestAgentCard(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		tls           bool
+		header        map[string]string
+		wantPreferred a2acore.TransportProtocol
+		wantURL       string
+		wantJSONRPC   string
+		wantGRPC      string
+	}{
+		{
+			name:          "request URL",
+			wantPreferred: a2acore.TransportProtocolJSONRPC,
+			wantURL:       "http://agent.example.com:8080/a2a/invoke",
+			wantJSONRPC:   "http://agent.example.com:8080/a2a/invoke",
+			wantGRPC:      "agent.example.com:8080",
+		},
+		{
+			name:          "TLS request",
+			tls:           true,
+			wantPreferred: a2acore.TransportProtocolJSONRPC,
+			wantURL:       "https://agent.example.com:8080/a2a/invoke",
+			wantJSONRPC:   "https://agent.example.com:8080/a2a/invoke",
+			wantGRPC:      "agent.example.com:8080",
+		},
+		{
+			name: "forwarded by a proxy",
+			header: map[string]string{
+				"X-Forwarded-Proto":  "https, http",
+				"X-Forwarded-Host":   "public.example.com",
+				"X-Forwarded-Prefix": "/agents/hello",
+			},
+			wantPreferred: a2acore.TransportProtocolJSONRPC,
+			wantURL:       "https://public.example.com/agents/hello/a2a/invoke",
+			wantJSONRPC:   "https://public.example.com/agents/hello/a2a/invoke",
+			wantGRPC:      "public.example.com:443",
+		},
+		{
+			name:          "agent URL flag",
+			args:          []string{"--a2a_agent_url", "https://configured.example.com/prefix"},
+			header:        map[string]string{"X-Forwarded-Host": "public.example.com"},
+			wantPreferred: a2acore.TransportProtocolJSONRPC,
+			wantURL:       "https://configured.example.com/prefix/a2a/invoke",
+			wantJSONRPC:   "https://configured.example.com/prefix/a2a/invoke",
+			wantGRPC:      "configured.example.com:443",
+		},
+		{
+			name:          "gRPC preferred",
+			args:          []string{"--a2a_preferred_transport", "GRPC"},
+			wantPreferred: a2acore.TransportProtocolGRPC,
+			wantURL:       "agent.example.com:8080",
+			wantJSONRPC:   "http://agent.example.com:8080/a2a/invoke",
+			wantGRPC:      "agent.example.com:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLauncher()
+			if _, err := l.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			agnt, err := agent.New(agent.Config{Name: "HelloWorldAgent"})
+			if err != nil {
+				t.Fatalf("agent.New() error = %v", err)
+			}
+			router := mux.NewRouter()
+			if err := l.SetupSubrouters(router, &launcher.Config{AgentLoader: agent.NewSingleLoader(agnt)}); err != nil {
+				t.Fatalf("SetupSubrouters() error = %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://agent.example.com:8080"+a2asrv.WellKnownAgentCardPath, nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("agent card status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var card a2acore.AgentCard
+			if err := json.Unmarshal(rec.Body.Bytes(), &card); err != nil {
+				t.Fatalf("failed to decode agent card: %v", err)
+			}
+			if card.PreferredTransport != tt.wantPreferred || card.URL != tt.wantURL {
+				t.Errorf("card = {PreferredTransport: %q, URL: %q}, want {PreferredTransport: %q, URL: %q}", card.PreferredTransport, card.URL, tt.wantPreferred, tt.wantURL)
+			}
+			wantInterfaces := []a2acore.AgentInterface{
+				{Transport: a2acore.TransportProtocolJSONRPC, URL: tt.wantJSONRPC},
+				{Transport: a2acore.TransportProtocolGRPC, URL: tt.wantGRPC},
+			}
+			if diff := cmp.Diff(wantInterfaces, card.AdditionalInterfaces); diff != "" {
+				t.Errorf("card.AdditionalInterfaces mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParse_InvalidPreferredTransport(t *testing.T) {
+	_, err := NewLauncher().Parse([]string{"--a2a_preferred_transport", "HTTP+JSON"})
+	if err == nil || !strings.Contains(err.Error(), "a2a_preferred_transport") {
+		t.Errorf("Parse() error = %v, want an a2a_preferred_transport error", err)
+	}
+}
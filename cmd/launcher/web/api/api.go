@@ -16,27 +16,92 @@
 package api
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"google.golang.org/adk/cmd/launcher"
 	weblauncher "google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/eval"
 	"google.golang.org/adk/internal/cli/util"
 	"google.golang.org/adk/server/adkrest"
+	"google.golang.org/adk/server/adkrest/auth"
+	"google.golang.org/adk/server/adkrest/controllers"
 )
 
 // apiConfig contains parametres for lauching ADK REST API
 type apiConfig struct {
 	frontendAddress string
+	sseKeepAlive    time.Duration
+	apiDocs         bool
+	evalDir         string
+
+	apiKeys     string // comma-separated [subject:]key entries
+	jwksURL     string
+	jwtIssuer   string
+	jwtAudience string // comma-separated
+	admins      string // comma-separated subjects
+}
+
+// apiKeysEnv is the environment variable with the API keys, used when the
+// -auth_api_keys flag is not set. It keeps the keys out of the process list.
+const apiKeysEnv = "ADK_API_KEYS"
+
+// authOptions returns the handler options enforcing the configured
+// authentication, if any.
+func (c *apiConfig) authOptions() ([]adkrest.Option, error) {
+	apiKeys := c.apiKeys
+	if apiKeys == "" {
+		apiKeys = os.Getenv(apiKeysEnv)
+	}
+	var authenticator auth.Authenticator
+	switch {
+	case apiKeys != "" && c.jwksURL != "":
+		return nil, fmt.Errorf("API keys and -auth_jwks_url cannot be used together")
+	case apiKeys != "":
+		keys := make(map[string]string)
+		for entry := range strings.SplitSeq(apiKeys, ",") {
+			subject, key, ok := strings.Cut(strings.TrimSpace(entry), ":")
+			if !ok {
+				subject, key = "", subject
+			}
+			if key == "" {
+				return nil, fmt.Errorf("empty API key in %q", entry)
+			}
+			keys[key] = subject
+		}
+		authenticator = auth.APIKeys(keys)
+	case c.jwksURL != "":
+		var audience []string
+		if c.jwtAudience != "" {
+			audience = strings.Split(c.jwtAudience, ",")
+		}
+		var err error
+		authenticator, err = auth.JWT(auth.JWTConfig{JWKSURL: c.jwksURL, Issuer: c.jwtIssuer, Audience: audience})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	var admins []string
+	if c.admins != "" {
+		admins = strings.Split(c.admins, ",")
+	}
+	return []adkrest.Option{adkrest.WithAuth(auth.Config{Authenticator: authenticator, Authorizer: auth.SameUser(admins...)})}, nil
 }
 
 // apiLauncher can launch ADK REST API
 type apiLauncher struct {
-	flags  *flag.FlagSet
-	config *apiConfig
+	flags   *flag.FlagSet
+	config  *apiConfig
+	handler *adkrest.Handler
 }
 
 // CommandLineSyntax returns the command-line syntax for the API launcher.
@@ -50,7 +115,7 @@ func corsWithArgs(frontendAddress string) func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", frontendAddress)
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
 				return
@@ -64,24 +129,53 @@ func corsWithArgs(frontendAddress string) func(next http.Handler) http.Handler {
 func (a *apiLauncher) UserMessage(webURL string, printer func(v ...any)) {
 	printer(fmt.Sprintf("       api:  you can access API using %s/api", webURL))
 	printer(fmt.Sprintf("       api:      for instance: %s/api/list-apps", webURL))
+	printer(fmt.Sprintf("       api:      OpenAPI document: %s/api/openapi.json", webURL))
+	if a.config.apiDocs {
+		printer(fmt.Sprintf("       api:      API documentation: %s/api/docs", webURL))
+	}
 }
 
 // SetupSubrouters adds the API router to the parent router.
 func (a *apiLauncher) SetupSubrouters(router *mux.Router, config *launcher.Config) error {
 	// Create the ADK REST API handler
-	apiHandler := adkrest.NewHandler(config)
+	opts, err := a.config.authOptions()
+	if err != nil {
+		return fmt.Errorf("invalid authentication flags: %w", err)
+	}
+	opts = append(opts, adkrest.WithSSEKeepAliveInterval(a.config.sseKeepAlive), adkrest.WithBasePath("/api"))
+	if a.config.apiDocs {
+		opts = append(opts, adkrest.WithSwaggerUI())
+	}
+	if a.config.evalDir != "" {
+		evalService, err := eval.LocalFileService(a.config.evalDir)
+		if err != nil {
+			return fmt.Errorf("invalid -eval_dir: %w", err)
+		}
+		opts = append(opts, adkrest.WithEvalService(evalService))
+	}
+	apiHandler := adkrest.NewHandler(config, opts...)
+	a.handler = apiHandler
 
 	// Wrap it with CORS middleware
 	corsHandler := corsWithArgs(a.config.frontendAddress)(apiHandler)
 
 	// Register it at the /api/ path
-	router.Methods("GET", "POST", "DELETE", "OPTIONS").PathPrefix("/api/").Handler(
+	router.Methods("GET", "POST", "PUT", "DELETE", "OPTIONS").PathPrefix("/api/").Handler(
 		http.StripPrefix("/api", corsHandler),
 	)
 
 	return nil
 }
 
+// Shutdown implements web.Shutdowner. It waits for the active agent runs to
+// finish.
+func (a *apiLauncher) Shutdown(ctx context.Context) error {
+	if a.handler == nil {
+		return nil
+	}
+	return a.handler.Close(ctx)
+}
+
 // Keyword implements web.Sublauncher. Returns the command-line keyword for API launcher.
 func (a *apiLauncher) Keyword() string {
 	return "api"
@@ -109,6 +203,16 @@ func NewLauncher() weblauncher.Sublauncher {
 	fs := flag.NewFlagSet("web", flag.ContinueOnError)
 	fs.StringVar(&config.frontendAddress, "webui_address", "localhost:8080", "ADK WebUI address as seen from the user browser. It's used to allow CORS requests. Please specify only hostname and (optionally) port.")
 
+	fs.DurationVar(&config.sseKeepAlive, "sse_keepalive", controllers.DefaultSSEKeepAliveInterval, "Interval of the keep-alive comments sent on idle SSE streams (/api/run_sse), so that proxies don't drop long agent runs. Zero disables them")
+	fs.StringVar(&config.evalDir, "eval_dir", "", "Directory storing the eval sets and results of the apps as JSON files. If not set, they are kept in memory and lost on restart")
+	fs.BoolVar(&config.apiDocs, "api_docs", false, "Serves a Swagger UI page documenting the API at /api/docs. The OpenAPI document is always served at /api/openapi.json")
+
+	fs.StringVar(&config.apiKeys, "auth_api_keys", "", "Comma-separated API keys required on /api/ requests, as Authorization: Bearer or X-API-Key headers. A key must be prefixed with 'subject:' to access the data of the subject user, keys without a subject are denied. Read from the "+apiKeysEnv+" environment variable if not set")
+	fs.StringVar(&config.jwksURL, "auth_jwks_url", "", "JWKS URL to verify the JSON Web Tokens required on /api/ requests as Authorization: Bearer headers. The token subject may access only the data of the same user")
+	fs.StringVar(&config.jwtIssuer, "auth_jwt_issuer", "", "Expected issuer of the JSON Web Tokens")
+	fs.StringVar(&config.jwtAudience, "auth_jwt_audience", "", "Comma-separated expected audiences of the JSON Web Tokens, one of them must match")
+	fs.StringVar(&config.admins, "auth_admins", "", "Comma-separated subjects which may access the data of all users, e.g. from the API keys or the JSON Web Tokens")
+
 	return &apiLauncher{
 		config: config,
 		flags:  fs,
@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// healthzPath is the liveness probe path.
+	healthzPath = "/healthz"
+	// readyzPath is the readiness probe path.
+	readyzPath = "/readyz"
+
+	// readinessAppName is a sentinel app name used to check that the
+	// session service responds. It's not expected to contain any sessions.
+	readinessAppName = "__adk_readiness_probe__"
+	readinessTimeout = 5 * time.Second
+)
+
+// AddHealthChecks registers liveness (/healthz) and readiness (/readyz)
+// probes on the router. Readiness responds with 503 if the session service
+// from config is unreachable.
+func AddHealthChecks(router *mux.Router, config *launcher.Config) {
+	router.Methods(http.MethodGet).Path(healthzPath).HandlerFunc(healthzHandler)
+	router.Methods(http.MethodGet).Path(readyzPath).HandlerFunc(readyzHandler(config))
+}
+
+// healthzHandler reports that the server is up.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+// readyzHandler reports whether the server is ready to serve requests,
+// i.e. all its backing services are reachable.
+func readyzHandler(config *launcher.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := checkReadiness(r.Context(), config); err != nil {
+			slog.WarnContext(r.Context(), "Readiness check failed", slog.Any("error", err))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, "not ready")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintln(w, "ok")
+	}
+}
+
+func checkReadiness(ctx context.Context, config *launcher.Config) error {
+	if config == nil || config.SessionService == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	_, err := config.SessionService.List(ctx, &session.ListRequest{AppName: readinessAppName})
+	if err != nil {
+		return fmt.Errorf("session service is unreachable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/session"
+)
+
+type unreachableSessionService struct {
+	session.Service
+}
+
+func (unreachableSessionService) List(context.Context, *session.ListRequest) (*session.ListResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAddHealthChecks(t *testing.T) {
+	tests := []struct {
+		name           string
+		sessionService session.Service
+		path           string
+		wantStatus     int
+	}{
+		{
+			name:           "healthz",
+			sessionService: session.InMemoryService(),
+			path:           "/healthz",
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "healthz with unreachable session service",
+			sessionService: unreachableSessionService{},
+			path:           "/healthz",
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "readyz",
+			sessionService: session.InMemoryService(),
+			path:           "/readyz",
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "readyz with unreachable session service",
+			sessionService: unreachableSessionService{},
+			path:           "/readyz",
+			wantStatus:     http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := web.BuildBaseRouter()
+			web.AddHealthChecks(router, &launcher.Config{SessionService: tt.sessionService})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+
+	"google.golang.org/adk/internal/telemetry"
+)
+
+// metricsPath is the path on which the metrics are served with -metrics.
+const metricsPath = "/metrics"
+
+// AddMetrics registers a Prometheus exporter as an ADK metric reader and
+// serves the metrics in Prometheus format on path. It also records the count
+// and the latency of all HTTP requests handled by the router, except the
+// requests for the metrics.
+//
+// It should be called before any agent is run, otherwise the metrics
+// recorded before are not exported.
+func AddMetrics(router *mux.Router, path string) error {
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return fmt.Errorf("failed to create prometheus exporter: %w", err)
+	}
+	telemetry.AddMetricReader(exporter)
+
+	router.Use(recordRequests(path))
+	router.Methods(http.MethodGet).Path(path).Handler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return nil
+}
+
+// recordRequests returns a middleware recording count and latency of served
+// requests. Requests to the metrics path itself are not recorded.
+func recordRequests(metricsPath string) mux.MiddlewareFunc {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if tmpl, err := current.GetPathTemplate(); err == nil {
+					route = tmpl
+				}
+			}
+			if route == metricsPath {
+				inner.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			inner.ServeHTTP(sw, r)
+			telemetry.RecordHTTPRequest(r.Context(), r.Method, route, sw.status, time.Since(start))
+		})
+	}
+}
+
+// statusWriter remembers the status code written to the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	if !s.wroteHeader {
+		s.status = code
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, which is required for SSE responses.
+func (s *statusWriter) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
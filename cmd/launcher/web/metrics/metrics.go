@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides a sublauncher that exposes ADK metrics in Prometheus format (using url /metrics)
+package metrics
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/cmd/launcher"
+	weblauncher "google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/internal/cli/util"
+)
+
+// metricsConfig contains parameters for exposing metrics
+type metricsConfig struct {
+	path string
+}
+
+// metricsLauncher can expose ADK metrics
+type metricsLauncher struct {
+	flags  *flag.FlagSet
+	config *metricsConfig
+}
+
+// CommandLineSyntax implements web.Sublauncher. Returns the command-line syntax for the metrics launcher.
+func (m *metricsLauncher) CommandLineSyntax() string {
+	return util.FormatFlagUsage(m.flags)
+}
+
+// Keyword implements web.Sublauncher. Returns the command-line keyword for metrics launcher.
+func (m *metricsLauncher) Keyword() string {
+	return "metrics"
+}
+
+// Parse implements web.Sublauncher. After parsing metrics-specific arguments returns remaining unparsed arguments
+func (m *metricsLauncher) Parse(args []string) ([]string, error) {
+	err := m.flags.Parse(args)
+	if err != nil || !m.flags.Parsed() {
+		return nil, fmt.Errorf("failed to parse metrics flags: %v", err)
+	}
+	return m.flags.Args(), nil
+}
+
+// SetupSubrouters implements the web.Sublauncher interface. It serves the
+// metrics on the metrics path, see web.AddMetrics.
+func (m *metricsLauncher) SetupSubrouters(router *mux.Router, config *launcher.Config) error {
+	return weblauncher.AddMetrics(router, m.config.path)
+}
+
+// SimpleDescription implements web.Sublauncher. Returns a simple description of the metrics launcher.
+func (m *metricsLauncher) SimpleDescription() string {
+	return "exposes agent, model, tool and HTTP metrics in Prometheus format"
+}
+
+// UserMessage implements the web.Sublauncher interface. Prints message to the user
+func (m *metricsLauncher) UserMessage(webURL string, printer func(v ...any)) {
+	printer(fmt.Sprintf("       metrics:  you can scrape metrics using %s%s", webURL, m.config.path))
+}
+
+// NewLauncher creates a new Sublauncher exposing ADK metrics on a custom path.
+// The prod and full launchers don't include it: their web launcher serves
+// the metrics on /metrics with the -metrics flag. Don't use both.
+func NewLauncher() weblauncher.Sublauncher {
+	config := &metricsConfig{}
+
+	fs := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	fs.StringVar(&config.path, "path", "/metrics", "URL path on which the metrics are served.")
+
+	return &metricsLauncher{
+		config: config,
+		flags:  fs,
+	}
+}
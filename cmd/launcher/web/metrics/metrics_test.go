@@ -0,0 +1,61 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/cmd/launcher/web/metrics"
+	"google.golang.org/adk/internal/telemetry"
+	"google.golang.org/adk/session"
+)
+
+func TestMetricsLauncher(t *testing.T) {
+	config := &launcher.Config{SessionService: session.InMemoryService()}
+	router := web.BuildBaseRouter()
+	l := metrics.NewLauncher()
+	if _, err := l.Parse([]string{"-path", "/custom/metrics"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := l.SetupSubrouters(router, config); err != nil {
+		t.Fatalf("SetupSubrouters() error = %v", err)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	telemetry.RecordToolCall(t.Context(), "get_weather", false)
+
+	resp, err := http.Get(server.URL + "/custom/metrics")
+	if err != nil {
+		t.Fatalf("GET /custom/metrics error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /custom/metrics status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if want := `adk_tool_calls_total{`; !strings.Contains(string(body), want) {
+		t.Errorf("metrics output does not contain %q:\n%s", want, body)
+	}
+}
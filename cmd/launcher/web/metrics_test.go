@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/internal/telemetry"
+)
+
+func TestAddMetrics(t *testing.T) {
+	router := web.BuildBaseRouter()
+	if err := web.AddMetrics(router, "/metrics"); err != nil {
+		t.Fatalf("AddMetrics() error = %v", err)
+	}
+	router.Methods("GET").Path("/items/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/items/42")
+	if err != nil {
+		t.Fatalf("GET /items/42 error = %v", err)
+	}
+	_ = resp.Body.Close()
+	telemetry.RecordAgentRun(t.Context(), "app", "root", time.Second, false)
+	telemetry.RecordToolCall(t.Context(), "get_weather", false)
+	telemetry.RecordAgentTransfer(t.Context(), "root", "helper")
+	telemetry.RecordModelRequest(t.Context(), "gemini-2.5-flash", time.Second, true)
+	telemetry.RecordModelUsage(t.Context(), &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10})
+
+	body := getMetrics(t, server.URL+"/metrics")
+	for _, want := range []string{
+		`adk_http_server_requests_total{`,
+		`http_route="/items/{id}"`,
+		`http_response_status_code="418"`,
+		`adk_http_server_duration_seconds_bucket{`,
+		`adk_invocations_total{`,
+		`adk_tool_calls_total{`,
+		`status="ok",tool="get_weather"`,
+		`adk_agent_transfers_total{`,
+		`adk_agent_to="helper"`,
+		`adk_llm_requests_total{`,
+		`model="gemini-2.5-flash",`,
+		`status="error"`,
+		`adk_llm_latency_seconds_bucket{`,
+		`adk_tokens_total{`,
+		`type="input"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output does not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `http_route="/metrics"`) {
+		t.Errorf("metrics endpoint requests should not be recorded:\n%s", body)
+	}
+}
+
+func getMetrics(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s status = %d, want %d", url, resp.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return string(body)
+}
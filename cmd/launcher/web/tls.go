@@ -0,0 +1,126 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsEnabled returns whether the server is configured to serve HTTPS.
+func (c *webConfig) tlsEnabled() bool {
+	return c.tlsCert != "" || c.autocertHosts != ""
+}
+
+// validateTLS checks that the TLS flags are consistent.
+func (c *webConfig) validateTLS() error {
+	if (c.tlsCert == "") != (c.tlsKey == "") {
+		return fmt.Errorf("both -tls-cert and -tls-key must be specified to serve HTTPS")
+	}
+	if c.tlsCert != "" && c.autocertHosts != "" {
+		return fmt.Errorf("-autocert-hosts cannot be combined with -tls-cert and -tls-key")
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS configuration of the server. Certificates given
+// with -tls-cert and -tls-key are reloaded on SIGHUP until ctx is done, the
+// ones obtained with autocert are renewed by the autocert manager.
+//
+// The server negotiates HTTP/2 over TLS, the configuration doesn't restrict
+// NextProtos.
+func (c *webConfig) tlsConfig(ctx context.Context) (*tls.Config, error) {
+	if c.autocertHosts != "" {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(strings.Split(c.autocertHosts, ",")...),
+			Cache:      autocert.DirCache(c.autocertCacheDir),
+		}
+		return m.TLSConfig(), nil
+	}
+
+	reloader, err := newCertReloader(c.tlsCert, c.tlsKey)
+	if err != nil {
+		return nil, err
+	}
+	reloader.reloadOnSIGHUP(ctx)
+	return &tls.Config{GetCertificate: reloader.getCertificate}, nil
+}
+
+// certReloader serves a certificate loaded from files, reloading it on
+// demand so that renewed certificates are used without a restart.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the certificate from the files. The previous certificate
+// stays in use if loading fails.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	return nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// reloadOnSIGHUP reloads the certificate on every SIGHUP until ctx is done.
+func (r *certReloader) reloadOnSIGHUP(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sighup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sighup:
+				if err := r.reload(); err != nil {
+					slog.WarnContext(ctx, "Keeping the current TLS certificate", slog.Any("error", err))
+					continue
+				}
+				slog.InfoContext(ctx, "Reloaded the TLS certificate", slog.String("cert_file", r.certFile))
+			}
+		}
+	}()
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package web_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/web"
+)
+
+// writeSelfSignedCert writes a certificate for localhost and its key to
+// certFile and keyFile, returning the certificate.
+func writeSelfSignedCert(t *testing.T, certFile, keyFile string, serial int64) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestRun_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	firstCert := writeSelfSignedCert(t, certFile, keyFile, 1)
+
+	sub := &testSublauncher{started: make(chan struct{}), release: make(chan struct{}), shutdown: make(chan struct{})}
+	l := web.NewLauncher(sub)
+	port := freePort(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- l.(launcher.Launcher).Execute(ctx, &launcher.Config{}, []string{"-port", strconv.Itoa(port), "-tls-cert", certFile, "-tls-key", keyFile, "test"})
+	}()
+
+	url := "https://localhost:" + strconv.Itoa(port) + "/healthz"
+	// get makes a request on a new connection, trusting only the given certificate.
+	get := func(trusted *x509.Certificate) (*http.Response, error) {
+		roots := x509.NewCertPool()
+		roots.AddCert(trusted)
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots},
+			ForceAttemptHTTP2: true,
+			DisableKeepAlives: true,
+		}}
+		resp, err := client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		return resp, nil
+	}
+
+	var resp *http.Response
+	var err error
+	for range 100 {
+		if resp, err = get(firstCert); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.ProtoMajor != 2 {
+		t.Errorf("GET %s = %d over %s, want %d over HTTP/2", url, resp.StatusCode, resp.Proto, http.StatusOK)
+	}
+
+	// SIGHUP makes the server pick up the renewed certificate.
+	secondCert := writeSelfSignedCert(t, certFile, keyFile, 2)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	for range 100 {
+		if _, err = get(secondCert); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Errorf("GET %s with the renewed certificate error = %v", url, err)
+	}
+
+	cancel()
+	if err := <-runErr; err != nil {
+		t.Errorf("Run() error = %v, want nil after shutdown", err)
+	}
+}
+
+func TestRun_InvalidTLSFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-tls-cert", "cert.pem", "test"},
+		{"-tls-key", "key.pem", "test"},
+		{"-tls-cert", "cert.pem", "-tls-key", "key.pem", "-autocert-hosts", "example.com", "test"},
+	} {
+		l := web.NewLauncher(&testSublauncher{})
+		if err := l.(launcher.Launcher).Execute(t.Context(), &launcher.Config{}, args); err == nil {
+			t.Errorf("Execute(%q) = nil, want an error", args)
+		}
+	}
+}
@@ -13,20 +13,50 @@
 // limitations under the License.
 
 // Package web provides a way to run ADK using a web server (extended by sublaunchers)
+//
+// The server timeouts can be adjusted with command-line flags. Recommended values:
+//   - for non-streaming deployments (e.g. /api/run only) keep the defaults
+//     (-read-timeout=15s -write-timeout=15s -idle-timeout=60s), increasing
+//     -write-timeout if the agent runs take longer to complete.
+//   - for streaming deployments (e.g. /api/run_sse used by ADK Web UI) the
+//     -write-timeout still applies to regular routes, but it is lifted for
+//     the SSE route so that long agent runs are not cut off mid-stream.
+//     Use -idle-timeout of a few minutes if clients keep connections open.
+//   - -max-body-size limits the size of request bodies. Raise it if users
+//     upload large inline files (images, documents) to the agent.
+//   - -tls-cert and -tls-key (or -autocert-hosts) make the server serve HTTPS
+//     with HTTP/2. Send SIGHUP to reload renewed certificate files.
+//   - -shutdown-timeout limits how long the server waits for the active
+//     requests and agent runs to finish on SIGTERM or interrupt.
+//   - -session-service and -artifact-service replace the services of the
+//     launcher config with the ones created from the URIs, e.g.
+//     sqlite:///var/lib/agent/sessions.db or gs://my-bucket, see package
+//     serviceuri: the schemes other than memory:// are enabled by importing
+//     their packages, e.g. serviceuri/sqliteuri. They default to the ADK_SESSION_SERVICE_URI and
+//     ADK_ARTIFACT_SERVICE_URI environment variables, which are set by
+//     adkgo deploy.
 package web
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 
 	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/serviceuri"
 	"google.golang.org/adk/cmd/launcher/universal"
 	"google.golang.org/adk/internal/cli/util"
 	"google.golang.org/adk/session"
@@ -38,8 +68,25 @@ type webConfig struct {
 	writeTimeout time.Duration
 	readTimeout  time.Duration
 	idleTimeout  time.Duration
+	maxBodySize  int64
+
+	shutdownTimeout time.Duration
+
+	tlsCert          string
+	tlsKey           string
+	autocertHosts    string
+	autocertCacheDir string
+
+	sessionServiceURI  string
+	artifactServiceURI string
+
+	metrics bool
 }
 
+// shutdownDrainTimeout is how long the requests still active after the
+// shutdown timeout get to complete before their connections are closed.
+const shutdownDrainTimeout = 2 * time.Second
+
 // webLauncher can launch web server
 type webLauncher struct {
 	flags        *flag.FlagSet
@@ -81,6 +128,15 @@ type Sublauncher interface {
 	UserMessage(webURL string, printer func(v ...any))
 }
 
+// Shutdowner can be implemented by a Sublauncher to release its resources,
+// e.g. wait for the active agent runs, when the web server shuts down on
+// SIGTERM, interrupt or when the context passed to Run is done. Shutdown is
+// called once the server stopped accepting new requests; ctx is done when the
+// shutdown timeout elapses.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
 // CommandLineSyntax implements launcher.Launcher.
 func (w *webLauncher) CommandLineSyntax() string {
 	var b strings.Builder
@@ -145,11 +201,23 @@ func (w *webLauncher) Parse(args []string) ([]string, error) {
 
 // Run implements launcher.SubLauncher.
 func (w *webLauncher) Run(ctx context.Context, config *launcher.Config) error {
+	if err := w.config.validateTLS(); err != nil {
+		return err
+	}
+	if err := w.config.createServices(ctx, config); err != nil {
+		return err
+	}
 	if config.SessionService == nil {
 		config.SessionService = session.InMemoryService()
 	}
 
 	router := BuildBaseRouter()
+	AddHealthChecks(router, config)
+	if w.config.metrics {
+		if err := AddMetrics(router, metricsPath); err != nil {
+			return err
+		}
+	}
 
 	// check if there are any active sublaunchers
 	if len(w.activeSublaunchers) == 0 {
@@ -169,29 +237,132 @@ func (w *webLauncher) Run(ctx context.Context, config *launcher.Config) error {
 
 	log.Printf("Starting the web server: %+v", w.config)
 	log.Println()
-	webUrl := fmt.Sprintf("http://localhost:%v", fmt.Sprint(w.config.port))
+	scheme := "http"
+	if w.config.tlsEnabled() {
+		scheme = "https"
+	}
+	webUrl := fmt.Sprintf("%s://localhost:%v", scheme, fmt.Sprint(w.config.port))
 	log.Printf("Web servers starts on %s", webUrl)
 	for _, l := range w.activeSublaunchers {
 		l.UserMessage(webUrl, log.Println)
 	}
+	if w.config.metrics {
+		log.Println(fmt.Sprintf("       metrics:  you can scrape metrics using %s%s", webUrl, metricsPath))
+	}
 	log.Println()
 
+	var handler http.Handler = router
+	if w.config.maxBodySize > 0 {
+		handler = http.MaxBytesHandler(router, w.config.maxBodySize)
+	}
+
+	// HTTP/2 is accepted also without TLS (h2c), as gRPC clients use it.
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
 	srv := http.Server{
 		Addr:         fmt.Sprintf(":%v", fmt.Sprint(w.config.port)),
 		WriteTimeout: w.config.writeTimeout,
 		ReadTimeout:  w.config.readTimeout,
 		IdleTimeout:  w.config.idleTimeout,
-		Handler:      router,
+		Handler:      handler,
+		Protocols:    &protocols,
 	}
 
-	err := srv.ListenAndServe()
-	if err != nil {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	if w.config.tlsEnabled() {
+		tlsConfig, err := w.config.tlsConfig(ctx)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+		go func() { serveErr <- srv.ListenAndServeTLS("", "") }()
+	} else {
+		go func() { serveErr <- srv.ListenAndServe() }()
+	}
+	select {
+	case err := <-serveErr:
 		return fmt.Errorf("server failed: %v", err)
+	case <-ctx.Done():
 	}
+	return w.shutdown(context.WithoutCancel(ctx), &srv)
+}
 
+// createServices replaces the services of config with the ones created from
+// the URIs of the flags or, if the flags are not set, of the environment.
+func (c *webConfig) createServices(ctx context.Context, config *launcher.Config) error {
+	if uri := cmp.Or(c.sessionServiceURI, os.Getenv(serviceuri.SessionServiceEnv)); uri != "" {
+		s, err := serviceuri.SessionService(ctx, uri)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Using session service", slog.String("uri", redactedURI(uri)))
+		config.SessionService = s
+	}
+	if uri := cmp.Or(c.artifactServiceURI, os.Getenv(serviceuri.ArtifactServiceEnv)); uri != "" {
+		s, err := serviceuri.ArtifactService(ctx, uri)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Using artifact service", slog.String("uri", redactedURI(uri)))
+		config.ArtifactService = s
+	}
 	return nil
 }
 
+// redactedURI returns the service URI without its user info, query and
+// fragment, which may hold credentials, so that it can be logged.
+func redactedURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "(invalid URI)"
+	}
+	u.User = nil
+	u.RawQuery, u.ForceQuery = "", false
+	u.Fragment, u.RawFragment = "", ""
+	if u.Host == "" && u.Path == "" && u.Opaque == "" {
+		// String drops the slashes of e.g. memory://.
+		return u.Scheme + "://"
+	}
+	return u.String()
+}
+
+// shutdown stops the server from accepting new requests and waits for the
+// active ones and the sublaunchers to finish within the shutdown timeout.
+// Requests still active then are given shutdownDrainTimeout to complete,
+// e.g. to end their streams, before their connections are closed.
+func (w *webLauncher) shutdown(ctx context.Context, srv *http.Server) error {
+	slog.InfoContext(ctx, "Shutting down the web server, waiting for the active requests", slog.Duration("timeout", w.config.shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(ctx, w.config.shutdownTimeout)
+	defer cancel()
+	serverCtx, cancelServer := context.WithTimeout(ctx, w.config.shutdownTimeout+shutdownDrainTimeout)
+	defer cancelServer()
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- srv.Shutdown(serverCtx) }()
+
+	var errs []error
+	for _, l := range w.activeSublaunchers {
+		if s, ok := l.(Shutdowner); ok {
+			if err := s.Shutdown(shutdownCtx); err != nil {
+				errs = append(errs, fmt.Errorf("%s sublauncher shutdown failed: %w", l.Keyword(), err))
+			}
+		}
+	}
+	if err := <-shutdownErr; err != nil {
+		errs = append(errs, fmt.Errorf("server shutdown failed: %w", err))
+		if err := srv.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("server close failed: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // SimpleDescription implements launcher.SubLauncher.
 func (w *webLauncher) SimpleDescription() string {
 	return "starts web server with additional sub-servers specified by sublaunchers"
@@ -204,9 +375,18 @@ func NewLauncher(sublaunchers ...Sublauncher) launcher.SubLauncher {
 
 	fs := flag.NewFlagSet("web", flag.ContinueOnError)
 	fs.IntVar(&config.port, "port", 8080, "Localhost port for the server")
-	fs.DurationVar(&config.writeTimeout, "write-timeout", 15*time.Second, "Server write timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for writing the response after reading the headers & body")
+	fs.DurationVar(&config.writeTimeout, "write-timeout", 15*time.Second, "Server write timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for writing the response after reading the headers & body. Not applied to streaming (SSE) responses")
 	fs.DurationVar(&config.readTimeout, "read-timeout", 15*time.Second, "Server read timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for reading the whole request including body")
 	fs.DurationVar(&config.idleTimeout, "idle-timeout", 60*time.Second, "Server idle timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for waiting for the next request (only when keep-alive is enabled)")
+	fs.DurationVar(&config.shutdownTimeout, "shutdown-timeout", 30*time.Second, "Graceful shutdown timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for finishing the active requests and agent runs on SIGTERM or interrupt")
+	fs.StringVar(&config.tlsCert, "tls-cert", "", "Path to the PEM encoded TLS certificate. Together with -tls-key makes the server serve HTTPS and HTTP/2. The certificate is reloaded on SIGHUP")
+	fs.StringVar(&config.tlsKey, "tls-key", "", "Path to the PEM encoded private key of the TLS certificate")
+	fs.StringVar(&config.autocertHosts, "autocert-hosts", "", "Comma-separated hostnames to obtain TLS certificates for from Let's Encrypt, instead of using -tls-cert and -tls-key. The server must be reachable on port 443 under these hostnames")
+	fs.StringVar(&config.autocertCacheDir, "autocert-cache-dir", "autocert-cache", "Directory to store the certificates obtained with -autocert-hosts in")
+	fs.StringVar(&config.sessionServiceURI, "session-service", "", "URI of the session service, e.g. 'sqlite:///tmp/sessions.db' when the serviceuri/sqliteuri package is imported, replacing the one configured in code. Defaults to $"+serviceuri.SessionServiceEnv)
+	fs.StringVar(&config.artifactServiceURI, "artifact-service", "", "URI of the artifact service, e.g. 'gs://my-bucket' when the serviceuri/gcsuri package is imported, replacing the one configured in code. Defaults to $"+serviceuri.ArtifactServiceEnv)
+	fs.Int64Var(&config.maxBodySize, "max-body-size", 32<<20, "Maximum size of the request body in bytes. Zero or negative value disables the limit")
+	fs.BoolVar(&config.metrics, "metrics", false, "Serve the ADK metrics in Prometheus format on "+metricsPath)
 
 	return &webLauncher{
 		config:       config,
@@ -216,17 +396,21 @@ func NewLauncher(sublaunchers ...Sublauncher) launcher.SubLauncher {
 }
 
 // logger is a middleware that logs the HTTP method, request URI, and the time taken to process the request.
+// Health check requests are not logged to avoid noise.
 func logger(inner http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthzPath || r.URL.Path == readyzPath {
+			inner.ServeHTTP(w, r)
+			return
+		}
 		start := time.Now()
 
 		inner.ServeHTTP(w, r)
 
-		log.Printf(
-			"%s %s %s",
-			r.Method,
-			r.RequestURI,
-			time.Since(start),
+		slog.InfoContext(r.Context(), "HTTP request",
+			slog.String("method", r.Method),
+			slog.String("uri", r.RequestURI),
+			slog.Duration("duration", time.Since(start)),
 		)
 	})
 }
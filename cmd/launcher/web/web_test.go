@@ -0,0 +1,286 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/serviceuri"
+	_ "google.golang.org/adk/cmd/launcher/serviceuri/sqliteuri"
+	"google.golang.org/adk/cmd/launcher/web"
+	"google.golang.org/adk/session"
+)
+
+// testSublauncher serves /slow, which responds once release is closed.
+type testSublauncher struct {
+	started  chan struct{}
+	release  chan struct{}
+	shutdown chan struct{}
+}
+
+func (s *testSublauncher) Keyword() string                       { return "test" }
+func (s *testSublauncher) Parse(args []string) ([]string, error) { return args, nil }
+func (s *testSublauncher) CommandLineSyntax() string             { return "" }
+func (s *testSublauncher) SimpleDescription() string             { return "test sublauncher" }
+func (s *testSublauncher) UserMessage(string, func(...any))      {}
+
+func (s *testSublauncher) SetupSubrouters(router *mux.Router, _ *launcher.Config) error {
+	router.HandleFunc("/slow", func(rw http.ResponseWriter, _ *http.Request) {
+		close(s.started)
+		<-s.release
+		fmt.Fprint(rw, "done")
+	})
+	return nil
+}
+
+func (s *testSublauncher) Shutdown(context.Context) error {
+	close(s.shutdown)
+	return nil
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestRun_GracefulShutdown(t *testing.T) {
+	sub := &testSublauncher{started: make(chan struct{}), release: make(chan struct{}), shutdown: make(chan struct{})}
+	l := web.NewLauncher(sub)
+	port := freePort(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- l.(launcher.Launcher).Execute(ctx, &launcher.Config{}, []string{"-port", strconv.Itoa(port), "-shutdown-timeout", "5s", "test"})
+	}()
+
+	baseURL := "http://localhost:" + strconv.Itoa(port)
+	waitForServer(t, baseURL+"/healthz")
+
+	type result struct {
+		body string
+		err  error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		slow <- result{body: string(body), err: err}
+	}()
+	<-sub.started
+
+	// Shutting down waits for the active request.
+	cancel()
+	<-sub.shutdown
+	select {
+	case err := <-runErr:
+		t.Fatalf("Run() = %v before the active request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sub.release)
+	if res := <-slow; res.err != nil || res.body != "done" {
+		t.Errorf("GET /slow = %q, %v, want %q", res.body, res.err, "done")
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Run() error = %v, want nil after shutdown", err)
+	}
+	if _, err := http.Get(baseURL + "/healthz"); err == nil {
+		t.Error("GET /healthz succeeded after shutdown")
+	}
+}
+
+// configSublauncher records the launcher config and stops the launcher
+// before it starts serving.
+type configSublauncher struct {
+	config *launcher.Config
+}
+
+var errStop = errors.New("stop")
+
+func (s *configSublauncher) Keyword() string                       { return "config" }
+func (s *configSublauncher) Parse(args []string) ([]string, error) { return args, nil }
+func (s *configSublauncher) CommandLineSyntax() string             { return "" }
+func (s *configSublauncher) SimpleDescription() string             { return "config sublauncher" }
+func (s *configSublauncher) UserMessage(string, func(...any))      {}
+
+func (s *configSublauncher) SetupSubrouters(_ *mux.Router, config *launcher.Config) error {
+	s.config = config
+	return errStop
+}
+
+func TestRun_ServiceURIs(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "sessions.db")
+	tests := []struct {
+		name         string
+		args         []string
+		env          map[string]string
+		wantSession  reflect.Type
+		wantArtifact reflect.Type
+		wantErr      string
+	}{
+		{
+			name:        "defaults",
+			wantSession: reflect.TypeOf(session.InMemoryService()),
+		},
+		{
+			name:         "flags",
+			args:         []string{"-session-service", "sqlite://" + dbPath, "-artifact-service", "memory://"},
+			wantSession:  reflect.TypeOf(mustSessionService(t, "sqlite://"+dbPath)),
+			wantArtifact: reflect.TypeOf(artifact.InMemoryService()),
+		},
+		{
+			name:         "environment",
+			env:          map[string]string{serviceuri.SessionServiceEnv: "memory://", serviceuri.ArtifactServiceEnv: "memory://"},
+			wantSession:  reflect.TypeOf(session.InMemoryService()),
+			wantArtifact: reflect.TypeOf(artifact.InMemoryService()),
+		},
+		{
+			name:        "flags override environment",
+			args:        []string{"-session-service", "sqlite://" + dbPath},
+			env:         map[string]string{serviceuri.SessionServiceEnv: "unknown://"},
+			wantSession: reflect.TypeOf(mustSessionService(t, "sqlite://"+dbPath)),
+		},
+		{
+			name:    "unknown session scheme",
+			args:    []string{"-session-service", "firestore://sessions"},
+			wantErr: `no session service registered for scheme "firestore"`,
+		},
+		{
+			name:    "invalid artifact URI in environment",
+			env:     map[string]string{serviceuri.ArtifactServiceEnv: "bucket"},
+			wantErr: "missing scheme",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(serviceuri.SessionServiceEnv, "")
+			t.Setenv(serviceuri.ArtifactServiceEnv, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			sub := &configSublauncher{}
+			args := append(tt.args, "config")
+			err := web.NewLauncher(sub).(launcher.Launcher).Execute(t.Context(), &launcher.Config{}, args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			// The error is wrapped with %v.
+			if err == nil || !strings.Contains(err.Error(), errStop.Error()) {
+				t.Fatalf("Execute() error = %v, want %v", err, errStop)
+			}
+			if got := reflect.TypeOf(sub.config.SessionService); got != tt.wantSession {
+				t.Errorf("session service = %v, want %v", got, tt.wantSession)
+			}
+			if got := reflect.TypeOf(sub.config.ArtifactService); got != tt.wantArtifact {
+				t.Errorf("artifact service = %v, want %v", got, tt.wantArtifact)
+			}
+		})
+	}
+}
+
+func mustSessionService(t *testing.T, uri string) session.Service {
+	t.Helper()
+	s, err := serviceuri.SessionService(t.Context(), uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func waitForServer(t *testing.T, url string) {
+	t.Helper()
+	for range 100 {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server at %s didn't start", url)
+}
+
+var _ web.Shutdowner = (*testSublauncher)(nil)
+
+func TestRun_MetricsFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+	}{
+		{name: "disabled by default", wantStatus: http.StatusNotFound},
+		{name: "enabled", args: []string{"-metrics"}, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &testSublauncher{shutdown: make(chan struct{})}
+			l := web.NewLauncher(sub)
+			port := freePort(t)
+			ctx, cancel := context.WithCancel(t.Context())
+			runErr := make(chan error, 1)
+			go func() {
+				args := append([]string{"-port", strconv.Itoa(port)}, tt.args...)
+				runErr <- l.(launcher.Launcher).Execute(ctx, &launcher.Config{}, append(args, "test"))
+			}()
+			defer func() {
+				cancel()
+				if err := <-runErr; err != nil {
+					t.Errorf("Run() error = %v", err)
+				}
+			}()
+
+			baseURL := "http://localhost:" + strconv.Itoa(port)
+			waitForServer(t, baseURL+"/healthz")
+			resp, err := http.Get(baseURL + "/metrics")
+			if err != nil {
+				t.Fatalf("GET /metrics error = %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET /metrics status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
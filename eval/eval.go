@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eval provides the evaluation of agents against eval sets.
+//
+// An eval set is a collection of eval cases, each one a recorded
+// conversation with an agent: the user messages, and the tool calls and
+// final responses expected from the agent. [Run] replays the cases through
+// the agent and scores the actual conversations against the expected ones
+// with the configured criteria, each one a [Scorer] and a threshold. The
+// built-in scorers match the tool trajectories exactly or partially, and
+// the final responses by their words or with an LLM judge.
+//
+// Eval cases can be recorded from existing sessions with [CaseFromSession],
+// and eval sets loaded from the .evalset.json files of adk-python with
+// [LoadSet]. Eval sets and results are stored by a [Service]. [RunInTest]
+// runs an evaluation in a Go test, e.g. in CI.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/session"
+)
+
+// Set is a collection of eval cases.
+type Set struct {
+	ID          string  `json:"evalSetId"`
+	Name        string  `json:"name,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Cases       []*Case `json:"evalCases"`
+	// CreationTimestamp is the creation time, in seconds since the Unix epoch.
+	CreationTimestamp float64 `json:"creationTimestamp"`
+}
+
+// Case returns the eval case of the set with the given id, or nil.
+func (s *Set) Case(id string) *Case {
+	for _, c := range s.Cases {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Case is a conversation with an agent, used as the reference of an
+// evaluation.
+type Case struct {
+	ID string `json:"evalId"`
+	// Conversation lists the invocations of the agent, each one starting with
+	// a user message, in order.
+	Conversation []*Invocation `json:"conversation"`
+	// SessionInput is the session the conversation is replayed in.
+	SessionInput *SessionInput `json:"sessionInput,omitempty"`
+	// CreationTimestamp is the creation time, in seconds since the Unix epoch.
+	CreationTimestamp float64 `json:"creationTimestamp"`
+}
+
+// Invocation is one turn of a conversation: a user message and the
+// agent's reaction to it.
+type Invocation struct {
+	InvocationID     string            `json:"invocationId,omitempty"`
+	UserContent      *genai.Content    `json:"userContent"`
+	FinalResponse    *genai.Content    `json:"finalResponse,omitempty"`
+	IntermediateData *IntermediateData `json:"intermediateData,omitempty"`
+	// CreationTimestamp is the creation time, in seconds since the Unix epoch.
+	CreationTimestamp float64 `json:"creationTimestamp"`
+}
+
+// IntermediateData holds what happened between the user message and the
+// final response of an invocation.
+type IntermediateData struct {
+	// ToolUses lists the tool calls of the agents, in order.
+	ToolUses      []*genai.FunctionCall     `json:"toolUses,omitempty"`
+	ToolResponses []*genai.FunctionResponse `json:"toolResponses,omitempty"`
+	// IntermediateResponses lists the responses of the agents which are not
+	// the final response, e.g. of sub-agents.
+	IntermediateResponses []*IntermediateResponse `json:"intermediateResponses,omitempty"`
+}
+
+// IntermediateResponse is a response of an agent which is not the final
+// response of an invocation.
+type IntermediateResponse struct {
+	Author string        `json:"author"`
+	Parts  []*genai.Part `json:"parts"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It also accepts the
+// [author, parts] pairs written by adk-python.
+func (r *IntermediateResponse) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err == nil {
+		if len(pair) != 2 {
+			return fmt.Errorf("intermediate response: got %d elements, want [author, parts]", len(pair))
+		}
+		if err := json.Unmarshal(pair[0], &r.Author); err != nil {
+			return fmt.Errorf("intermediate response author: %w", err)
+		}
+		return json.Unmarshal(pair[1], &r.Parts)
+	}
+	// The alias doesn't have the UnmarshalJSON method.
+	type intermediateResponse IntermediateResponse
+	return json.Unmarshal(data, (*intermediateResponse)(r))
+}
+
+// SessionInput is the initial session of an eval case.
+type SessionInput struct {
+	AppName string         `json:"appName"`
+	UserID  string         `json:"userId"`
+	State   map[string]any `json:"state,omitempty"`
+}
+
+// CaseFromSession returns an eval case with the given id recording the
+// conversation of the session. Each user message starts an invocation, the
+// last final response of the agents before the next user message is its
+// final response.
+func CaseFromSession(id string, s session.Session) *Case {
+	c := &Case{
+		ID:                id,
+		SessionInput:      &SessionInput{AppName: s.AppName(), UserID: s.UserID()},
+		CreationTimestamp: timestamp(time.Now()),
+	}
+	var current *recorder
+	for event := range s.Events().All() {
+		content := event.LLMResponse.Content
+		if content == nil {
+			continue
+		}
+		if event.Author == "user" && len(event.FunctionResponses()) == 0 {
+			current = newRecorder(event.InvocationID, content, event.Timestamp)
+			c.Conversation = append(c.Conversation, current.inv)
+			continue
+		}
+		if current == nil {
+			// Agent events before the first user message aren't part of
+			// an invocation.
+			continue
+		}
+		current.record(event)
+	}
+	return c
+}
+
+// recorder records the events of the agents in an invocation.
+type recorder struct {
+	inv *Invocation
+	// finalAuthor is the author of the final response so far.
+	finalAuthor string
+}
+
+func newRecorder(invocationID string, userContent *genai.Content, t time.Time) *recorder {
+	return &recorder{inv: &Invocation{
+		InvocationID:      invocationID,
+		UserContent:       userContent,
+		IntermediateData:  &IntermediateData{},
+		CreationTimestamp: timestamp(t),
+	}}
+}
+
+// record records an agent event.
+func (r *recorder) record(event *session.Event) {
+	data := r.inv.IntermediateData
+	data.ToolUses = append(data.ToolUses, event.FunctionCalls()...)
+	data.ToolResponses = append(data.ToolResponses, event.FunctionResponses()...)
+	if event.LLMResponse.Partial || event.Text() == "" {
+		return
+	}
+	if event.IsFinalResponse() {
+		if r.inv.FinalResponse != nil {
+			// A later agent answered, the earlier answer was intermediate.
+			data.IntermediateResponses = append(data.IntermediateResponses, &IntermediateResponse{
+				Author: r.finalAuthor,
+				Parts:  r.inv.FinalResponse.Parts,
+			})
+		}
+		r.inv.FinalResponse = event.LLMResponse.Content
+		r.finalAuthor = event.Author
+		return
+	}
+	data.IntermediateResponses = append(data.IntermediateResponses, &IntermediateResponse{
+		Author: event.Author,
+		Parts:  event.LLMResponse.Content.Parts,
+	})
+}
+
+// timestamp returns t in seconds since the Unix epoch.
+func timestamp(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
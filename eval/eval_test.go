@@ -0,0 +1,329 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval_test
+
+import (
+	"fmt"
+	"iter"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// weatherAgent returns an agent answering with the responses, with a
+// weather tool.
+func weatherAgent(t *testing.T, responses ...*genai.Content) agent.Agent {
+	t.Helper()
+	type Args struct {
+		City       string `json:"city"`
+		UnitSystem string `json:"unit_system,omitempty"`
+	}
+	weather, err := functiontool.New(functiontool.Config{Name: "weather", Description: "returns the weather"}, func(_ tool.Context, args Args) (map[string]any, error) {
+		return map[string]any{"forecast": "sunny"}, nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "weather_agent",
+		Model: &testutil.MockModel{Responses: responses},
+		Tools: []tool.Tool{weather},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	return a
+}
+
+func weatherCall(city string) *genai.Content {
+	return &genai.Content{
+		Role:  genai.RoleModel,
+		Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "weather", Args: map[string]any{"city": city}}}},
+	}
+}
+
+// recordCase runs the agent on the messages, and returns the eval case of
+// its session.
+func recordCase(t *testing.T, a agent.Agent, messages ...string) *eval.Case {
+	t.Helper()
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, msg := range messages {
+		if _, err := testutil.CollectEvents(r.Run(ctx, "user", created.Session.ID(), genai.NewContentFromText(msg, genai.RoleUser), agent.RunConfig{})); err != nil {
+			t.Fatalf("Run(%q) error = %v", msg, err)
+		}
+	}
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return eval.CaseFromSession("case", resp.Session)
+}
+
+func TestCaseFromSession(t *testing.T) {
+	a := weatherAgent(t,
+		weatherCall("Paris"),
+		genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel),
+		genai.NewContentFromText("You're welcome.", genai.RoleModel),
+	)
+
+	c := recordCase(t, a, "what is the weather in Paris?", "thanks")
+
+	if c.ID != "case" {
+		t.Errorf("ID = %q, want %q", c.ID, "case")
+	}
+	if diff := cmp.Diff(&eval.SessionInput{AppName: "app", UserID: "user"}, c.SessionInput); diff != "" {
+		t.Errorf("SessionInput mismatch (-want +got):\n%s", diff)
+	}
+	if len(c.Conversation) != 2 {
+		t.Fatalf("got %d invocations, want 2", len(c.Conversation))
+	}
+	first, second := c.Conversation[0], c.Conversation[1]
+	if got := first.UserContent.Parts[0].Text; got != "what is the weather in Paris?" {
+		t.Errorf("first user content = %q", got)
+	}
+	if got := first.FinalResponse.Parts[0].Text; got != "It's sunny in Paris." {
+		t.Errorf("first final response = %q", got)
+	}
+	if uses := first.IntermediateData.ToolUses; len(uses) != 1 || uses[0].Name != "weather" || uses[0].Args["city"] != "Paris" {
+		t.Errorf("first tool uses = %v, want the weather call", uses)
+	}
+	if responses := first.IntermediateData.ToolResponses; len(responses) != 1 || responses[0].Response["forecast"] != "sunny" {
+		t.Errorf("first tool responses = %v, want the weather response", responses)
+	}
+	if got := second.FinalResponse.Parts[0].Text; got != "You're welcome." {
+		t.Errorf("second final response = %q", got)
+	}
+	if uses := second.IntermediateData.ToolUses; len(uses) != 0 {
+		t.Errorf("second tool uses = %v, want none", uses)
+	}
+	if first.InvocationID == "" || first.InvocationID == second.InvocationID {
+		t.Errorf("invocation ids = %q, %q, want distinct ids", first.InvocationID, second.InvocationID)
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := recordCase(t, weatherAgent(t,
+		weatherCall("Paris"),
+		genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel),
+	), "what is the weather in Paris?")
+
+	tests := []struct {
+		name       string
+		responses  []*genai.Content
+		wantStatus eval.Status
+		wantScores map[string]float64
+	}{
+		{
+			name: "same conversation",
+			responses: []*genai.Content{
+				weatherCall("Paris"),
+				genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel),
+			},
+			wantStatus: eval.StatusPassed,
+			wantScores: map[string]float64{
+				eval.MetricToolTrajectoryAvgScore: 1,
+				eval.MetricResponseMatchScore:     1,
+			},
+		},
+		{
+			name: "similar response",
+			responses: []*genai.Content{
+				weatherCall("Paris"),
+				genai.NewContentFromText("It's sunny in Paris today.", genai.RoleModel),
+			},
+			wantStatus: eval.StatusPassed,
+			wantScores: map[string]float64{
+				eval.MetricToolTrajectoryAvgScore: 1,
+				eval.MetricResponseMatchScore:     10.0 / 11, // the 5 expected words of 6
+			},
+		},
+		{
+			name: "wrong tool arguments",
+			responses: []*genai.Content{
+				weatherCall("London"),
+				genai.NewContentFromText("It's sunny in London.", genai.RoleModel),
+			},
+			wantStatus: eval.StatusFailed,
+			wantScores: map[string]float64{
+				eval.MetricToolTrajectoryAvgScore: 0,
+				eval.MetricResponseMatchScore:     0.8, // 4 of 5 words
+			},
+		},
+		{
+			name: "no tool call",
+			responses: []*genai.Content{
+				genai.NewContentFromText("I don't know.", genai.RoleModel),
+			},
+			wantStatus: eval.StatusFailed,
+			wantScores: map[string]float64{
+				eval.MetricToolTrajectoryAvgScore: 0,
+				eval.MetricResponseMatchScore:     0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := eval.Run(t.Context(), eval.Config{
+				AppName: "app",
+				Agent:   weatherAgent(t, tt.responses...),
+				SetID:   "set",
+			}, []*eval.Case{c})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			result := results[0]
+			if result.FinalStatus != tt.wantStatus {
+				t.Errorf("FinalStatus = %v, want %v", result.FinalStatus, tt.wantStatus)
+			}
+			if result.SetID != "set" || result.CaseID != "case" || result.UserID != "user" || result.SessionID == "" {
+				t.Errorf("result = %+v, want the set, case, user and session", result)
+			}
+			gotScores := map[string]float64{}
+			for _, m := range result.OverallMetricResults {
+				if m.Score == nil {
+					t.Fatalf("metric %q has no score", m.MetricName)
+				}
+				gotScores[m.MetricName] = *m.Score
+			}
+			if diff := cmp.Diff(tt.wantScores, gotScores, cmpFloat); diff != "" {
+				t.Errorf("scores mismatch (-want +got):\n%s", diff)
+			}
+			if len(result.InvocationResults) != 1 || len(result.InvocationResults[0].MetricResults) != 2 {
+				t.Errorf("InvocationResults = %v, want 2 metrics of 1 invocation", result.InvocationResults)
+			}
+		})
+	}
+}
+
+var cmpFloat = cmp.Comparer(func(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+})
+
+func TestRun_Errors(t *testing.T) {
+	c := &eval.Case{
+		ID: "case",
+		Conversation: []*eval.Invocation{
+			{UserContent: genai.NewContentFromText("hello", genai.RoleUser)},
+		},
+	}
+
+	t.Run("unsupported metric", func(t *testing.T) {
+		_, err := eval.Run(t.Context(), eval.Config{
+			AppName: "app",
+			Agent:   weatherAgent(t),
+			Metrics: []eval.Metric{{Name: "unknown"}},
+		}, []*eval.Case{c})
+		if err == nil {
+			t.Error("Run() succeeded, want an error")
+		}
+	})
+
+	t.Run("agent error", func(t *testing.T) {
+		// The model has no response.
+		results, err := eval.Run(t.Context(), eval.Config{AppName: "app", Agent: weatherAgent(t)}, []*eval.Case{c})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := results[0]; got.FinalStatus != eval.StatusNotEvaluated || got.Error == "" {
+			t.Errorf("result = %+v, want not evaluated with an error", got)
+		}
+	})
+}
+
+func TestRun_Parallelism(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		running, peak int
+	)
+	echo, err := agent.New(agent.Config{
+		Name: "echo",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				mu.Lock()
+				running++
+				peak = max(peak, running)
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					running--
+					mu.Unlock()
+				}()
+				time.Sleep(20 * time.Millisecond)
+
+				ev := session.NewEvent(ctx.InvocationID())
+				ev.Author = ctx.Agent().Name()
+				ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(ctx.UserContent().Parts[0].Text, genai.RoleModel)}
+				yield(ev, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+
+	var cases []*eval.Case
+	for i := range 6 {
+		text := fmt.Sprintf("message %d", i)
+		cases = append(cases, &eval.Case{
+			ID: fmt.Sprintf("case_%d", i),
+			Conversation: []*eval.Invocation{{
+				UserContent:   genai.NewContentFromText(text, genai.RoleUser),
+				FinalResponse: genai.NewContentFromText(text, genai.RoleModel),
+			}},
+		})
+	}
+
+	results, err := eval.Run(t.Context(), eval.Config{
+		Agent:       echo,
+		Metrics:     []eval.Metric{{Name: eval.MetricResponseMatchScore, Threshold: 1}},
+		Parallelism: 3,
+	}, cases)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for i, r := range results {
+		if r.CaseID != cases[i].ID || r.FinalStatus != eval.StatusPassed {
+			t.Errorf("results[%d] = %q %v, want %q PASSED", i, r.CaseID, r.FinalStatus, cases[i].ID)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("%d cases ran concurrently, want at most 3", peak)
+	}
+}
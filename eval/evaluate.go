@@ -0,0 +1,351 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// Status is the status of an evaluation.
+type Status int
+
+const (
+	// StatusPassed means the scores met the thresholds.
+	StatusPassed Status = 1
+	// StatusFailed means a score was below its threshold.
+	StatusFailed Status = 2
+	// StatusNotEvaluated means the evaluation couldn't run.
+	StatusNotEvaluated Status = 3
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPassed:
+		return "PASSED"
+	case StatusFailed:
+		return "FAILED"
+	case StatusNotEvaluated:
+		return "NOT_EVALUATED"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// MetricResult is the score of a metric.
+type MetricResult struct {
+	MetricName string  `json:"metricName"`
+	Threshold  float64 `json:"threshold"`
+	// Score is nil if the metric wasn't evaluated.
+	Score  *float64 `json:"score,omitempty"`
+	Status Status   `json:"evalStatus"`
+	// Error is the error of the scorer, if the metric wasn't evaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// InvocationResult is the evaluation of an invocation of an eval case.
+type InvocationResult struct {
+	Actual        *Invocation     `json:"actualInvocation"`
+	Expected      *Invocation     `json:"expectedInvocation"`
+	MetricResults []*MetricResult `json:"evalMetricResults"`
+}
+
+// CaseResult is the evaluation of an eval case.
+type CaseResult struct {
+	SetID  string `json:"evalSetId"`
+	CaseID string `json:"evalId"`
+	// FinalStatus is StatusPassed if all the overall metrics passed.
+	FinalStatus Status `json:"finalEvalStatus"`
+	// OverallMetricResults are the scores of the case, averaged over the
+	// invocations.
+	OverallMetricResults []*MetricResult     `json:"overallEvalMetricResults"`
+	InvocationResults    []*InvocationResult `json:"evalMetricResultPerInvocation"`
+	// SessionID is the id of the session the case was replayed in.
+	SessionID string `json:"sessionId"`
+	UserID    string `json:"userId"`
+	// Error is the error which stopped the replay, if FinalStatus is
+	// StatusNotEvaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// SetResult is the evaluation of cases of an eval set.
+type SetResult struct {
+	ID          string        `json:"evalSetResultId"`
+	SetID       string        `json:"evalSetId"`
+	CaseResults []*CaseResult `json:"evalCaseResults"`
+	// CreationTimestamp is the creation time, in seconds since the Unix epoch.
+	CreationTimestamp float64 `json:"creationTimestamp"`
+}
+
+// NewSetResult returns the result of the evaluation of cases of an eval set,
+// with an id unique to the set and time.
+func NewSetResult(appName, setID string, results []*CaseResult) *SetResult {
+	now := time.Now()
+	return &SetResult{
+		ID:                fmt.Sprintf("%s_%s_%d", appName, setID, now.UnixNano()),
+		SetID:             setID,
+		CaseResults:       results,
+		CreationTimestamp: timestamp(now),
+	}
+}
+
+// Summary aggregates the results of eval cases.
+type Summary struct {
+	Total        int `json:"total"`
+	Passed       int `json:"passed"`
+	Failed       int `json:"failed"`
+	NotEvaluated int `json:"notEvaluated"`
+	// PassRate is the fraction of the cases which passed, 0 without cases.
+	PassRate float64 `json:"passRate"`
+	// MetricAverages are the average overall scores of the metrics, over the
+	// cases where they were evaluated.
+	MetricAverages map[string]float64 `json:"metricAverages"`
+}
+
+// Summarize returns the aggregate statistics of the results.
+func Summarize(results []*CaseResult) Summary {
+	s := Summary{Total: len(results), MetricAverages: map[string]float64{}}
+	counts := map[string]int{}
+	for _, r := range results {
+		switch r.FinalStatus {
+		case StatusPassed:
+			s.Passed++
+		case StatusFailed:
+			s.Failed++
+		default:
+			s.NotEvaluated++
+		}
+		for _, m := range r.OverallMetricResults {
+			if m.Score != nil {
+				s.MetricAverages[m.MetricName] += *m.Score
+				counts[m.MetricName]++
+			}
+		}
+	}
+	for name, n := range counts {
+		s.MetricAverages[name] /= float64(n)
+	}
+	if s.Total > 0 {
+		s.PassRate = float64(s.Passed) / float64(s.Total)
+	}
+	return s
+}
+
+// defaultUserID is the user the eval cases without a session input are
+// replayed as.
+const defaultUserID = "eval_user"
+
+// Config is the configuration of an evaluation.
+type Config struct {
+	// AppName is the name of the app the cases are replayed in. Optional:
+	// the name of the agent if empty.
+	AppName string
+	// Agent is the root agent of the app.
+	Agent agent.Agent
+	// SetID is the id of the eval set of the cases, reported in the results.
+	SetID string
+	// Metrics are the built-in metrics to score.
+	Metrics []Metric
+	// Criteria are the criteria to score, after the Metrics. If neither
+	// Metrics nor Criteria are set, the cases are scored with
+	// DefaultMetrics.
+	Criteria []Criterion
+	// Parallelism is the maximal number of cases replayed concurrently.
+	// Optional: the cases are replayed one at a time if less than 2.
+	Parallelism int
+}
+
+// criteria returns the criteria to score.
+func (c *Config) criteria() ([]Criterion, error) {
+	metrics := c.Metrics
+	if len(metrics) == 0 && len(c.Criteria) == 0 {
+		metrics = DefaultMetrics
+	}
+	criteria := make([]Criterion, 0, len(metrics)+len(c.Criteria))
+	for _, m := range metrics {
+		criterion, err := m.Criterion()
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, criterion)
+	}
+	for _, criterion := range c.Criteria {
+		if criterion.Name == "" || criterion.Scorer == nil {
+			return nil, fmt.Errorf("eval criteria require a name and a scorer")
+		}
+		criteria = append(criteria, criterion)
+	}
+	return criteria, nil
+}
+
+// Run replays the eval cases through the agent and scores them with the
+// criteria. Each case is replayed in a new in-memory session with the state
+// of its session input; the user messages are sent in order whatever the
+// agent replies. The results are in the order of the cases, whatever
+// the parallelism. A case which fails to replay is reported with
+// StatusNotEvaluated, Run only fails on an invalid configuration.
+func Run(ctx context.Context, cfg Config, cases []*Case) ([]*CaseResult, error) {
+	criteria, err := cfg.criteria()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Agent == nil {
+		return nil, fmt.Errorf("agent is required")
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = cfg.Agent.Name()
+	}
+
+	parallelism := max(cfg.Parallelism, 1)
+	results := make([]*CaseResult, len(cases))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = runCase(ctx, cfg, criteria, c)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// runCase replays and scores an eval case.
+func runCase(ctx context.Context, cfg Config, criteria []Criterion, c *Case) *CaseResult {
+	result := &CaseResult{SetID: cfg.SetID, CaseID: c.ID}
+	actual, err := replay(ctx, cfg, c, result)
+	if err != nil {
+		result.FinalStatus = StatusNotEvaluated
+		result.Error = err.Error()
+		return result
+	}
+	score(ctx, result, criteria, actual, c.Conversation)
+	return result
+}
+
+// replay sends the user messages of the case to the agent, and returns the
+// recorded invocations. It sets the session and user of the result.
+func replay(ctx context.Context, cfg Config, c *Case, result *CaseResult) ([]*Invocation, error) {
+	result.UserID = defaultUserID
+	var state map[string]any
+	if c.SessionInput != nil {
+		if c.SessionInput.UserID != "" {
+			result.UserID = c.SessionInput.UserID
+		}
+		state = c.SessionInput.State
+	}
+
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName: cfg.AppName,
+		UserID:  result.UserID,
+		State:   state,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	result.SessionID = created.Session.ID()
+
+	r, err := runner.New(runner.Config{
+		AppName:        cfg.AppName,
+		Agent:          cfg.Agent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	actual := make([]*Invocation, 0, len(c.Conversation))
+	for _, expected := range c.Conversation {
+		rec := newRecorder("", expected.UserContent, time.Now())
+		for event, err := range r.Run(ctx, result.UserID, result.SessionID, expected.UserContent, agent.RunConfig{}) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to run invocation %d: %w", len(actual)+1, err)
+			}
+			rec.inv.InvocationID = event.InvocationID
+			rec.record(event)
+		}
+		actual = append(actual, rec.inv)
+	}
+	return actual, nil
+}
+
+// score scores the actual invocations against the expected ones, and sets
+// the metric results and final status of the result. The overall score of a
+// criterion is the average over the invocations it could score.
+func score(ctx context.Context, result *CaseResult, criteria []Criterion, actual, expected []*Invocation) {
+	sums := make([]float64, len(criteria))
+	counts := make([]int, len(criteria))
+	errs := make([]error, len(criteria))
+	for i, exp := range expected {
+		inv := &InvocationResult{Actual: actual[i], Expected: exp}
+		for j, criterion := range criteria {
+			s, err := criterion.Scorer.Score(ctx, actual[i], exp)
+			if err != nil {
+				errs[j] = err
+				inv.MetricResults = append(inv.MetricResults, metricResult(criterion, nil, err))
+				continue
+			}
+			sums[j] += s
+			counts[j]++
+			inv.MetricResults = append(inv.MetricResults, metricResult(criterion, &s, nil))
+		}
+		result.InvocationResults = append(result.InvocationResults, inv)
+	}
+
+	result.FinalStatus = StatusPassed
+	for j, criterion := range criteria {
+		var s *float64
+		if counts[j] > 0 {
+			avg := sums[j] / float64(counts[j])
+			s = &avg
+		}
+		r := metricResult(criterion, s, errs[j])
+		result.OverallMetricResults = append(result.OverallMetricResults, r)
+		// A failure takes precedence over a metric which wasn't evaluated.
+		if r.Status != StatusPassed && result.FinalStatus != StatusFailed {
+			result.FinalStatus = r.Status
+		}
+	}
+}
+
+// metricResult returns the result of the criterion for the score, nil if
+// it wasn't evaluated, and the error of the scorer.
+func metricResult(c Criterion, score *float64, err error) *MetricResult {
+	r := &MetricResult{MetricName: c.Name, Threshold: c.Threshold, Score: score}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	switch {
+	case score == nil:
+		r.Status = StatusNotEvaluated
+	case *score >= c.Threshold:
+		r.Status = StatusPassed
+	default:
+		r.Status = StatusFailed
+	}
+	return r
+}
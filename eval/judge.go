@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// MetricFinalResponseMatchV2 is the usual name of the criterion scored by
+// [LLMJudge].
+const MetricFinalResponseMatchV2 = "final_response_match_v2"
+
+const judgePrompt = `You are an expert rater for an AI agent. The agent was asked a question by a user, and you are given a reference answer to this question. Your task is to determine whether the agent's response is valid: it is valid if it is consistent with the reference answer and answers the question, even if it is phrased differently, shorter or longer. It is invalid if it contradicts the reference answer, misses key information of the reference answer, or doesn't answer the question.
+
+User prompt:
+%s
+
+Reference answer:
+%s
+
+Agent response:
+%s
+
+First explain your reasoning in a few sentences. Then, on the last line, output your verdict exactly as:
+"is_the_agent_response_valid": "valid"
+or
+"is_the_agent_response_valid": "invalid"`
+
+// verdictRegexp matches the verdict of the judge.
+var verdictRegexp = regexp.MustCompile(`(?i)"?is_the_agent_response_valid"?\s*:\s*\[?\s*"?(valid|invalid)"?`)
+
+// LLMJudge returns a scorer asking the model whether the final response of
+// the agent is valid with respect to the expected one. It scores 1 if the
+// model judges the response valid, and 0 otherwise. It fails if the model
+// doesn't give a verdict.
+func LLMJudge(llm model.LLM) Scorer {
+	return ScorerFunc(func(ctx context.Context, actual, expected *Invocation) (float64, error) {
+		prompt := fmt.Sprintf(judgePrompt,
+			contentText(expected.UserContent),
+			contentText(expected.FinalResponse),
+			contentText(actual.FinalResponse))
+		req := &model.LLMRequest{
+			Model:    llm.Name(),
+			Contents: []*genai.Content{genai.NewContentFromText(prompt, genai.RoleUser)},
+			Config:   &genai.GenerateContentConfig{},
+		}
+		var verdict strings.Builder
+		for resp, err := range llm.GenerateContent(ctx, req, false) {
+			if err != nil {
+				return 0, fmt.Errorf("judge model %s failed: %w", llm.Name(), err)
+			}
+			verdict.WriteString(contentText(resp.Content))
+		}
+		matches := verdictRegexp.FindAllStringSubmatch(verdict.String(), -1)
+		if len(matches) == 0 {
+			return 0, fmt.Errorf("judge model %s gave no verdict: %q", llm.Name(), verdict.String())
+		}
+		// The last verdict is the final one, the reasoning may quote the
+		// expected format.
+		if strings.EqualFold(matches[len(matches)-1][1], "valid") {
+			return 1, nil
+		}
+		return 0, nil
+	})
+}
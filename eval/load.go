@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// userDataKeys are the keys whose values are user data, e.g. the arguments
+// of function calls, which keep their keys when loading eval sets.
+var userDataKeys = map[string]bool{
+	"args":     true,
+	"response": true,
+	"state":    true,
+}
+
+// LoadSet loads an eval set from a JSON file, e.g. an .evalset.json file
+// written by adk-python, see [ParseSet]. If the file doesn't have an eval set
+// id, it is the name of the file without the .evalset.json or .json suffix.
+func LoadSet(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval set: %w", err)
+	}
+	set, err := ParseSet(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse eval set %s: %w", filepath.Base(path), err)
+	}
+	if set.ID == "" {
+		name := filepath.Base(path)
+		if id, ok := strings.CutSuffix(name, setFileSuffix); ok {
+			set.ID = id
+		} else {
+			set.ID = strings.TrimSuffix(name, ".json")
+		}
+	}
+	return set, nil
+}
+
+// ParseSet parses a JSON eval set. Both the camelCase keys of the ADK REST
+// API and the snake_case keys of the files written by adk-python are
+// supported, e.g. "evalSetId" and "eval_set_id".
+func ParseSet(data []byte) (*Set, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(camelKeys(v))
+	if err != nil {
+		return nil, err
+	}
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	for i, c := range set.Cases {
+		if c == nil || c.ID == "" {
+			return nil, fmt.Errorf("eval case %d has no evalId", i)
+		}
+		for j, inv := range c.Conversation {
+			if inv == nil || inv.UserContent == nil {
+				return nil, fmt.Errorf("invocation %d of eval case %q has no userContent", j, c.ID)
+			}
+		}
+	}
+	return &set, nil
+}
+
+// camelKeys returns v with the snake_case keys of its objects converted to
+// camelCase, except in user data.
+func camelKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			key = snakeToCamel(key)
+			if !userDataKeys[key] {
+				value = camelKeys(value)
+			}
+			m[key] = value
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = camelKeys(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func snakeToCamel(s string) string {
+	if !strings.Contains(s, "_") {
+		return s
+	}
+	var sb strings.Builder
+	upper := false
+	for i, r := range s {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			sb.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
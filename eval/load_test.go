@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/eval"
+)
+
+func TestLoadSet(t *testing.T) {
+	set, err := eval.LoadSet("testdata/weather.evalset.json")
+	if err != nil {
+		t.Fatalf("LoadSet() error = %v", err)
+	}
+	want := &eval.Set{
+		ID:          "weather",
+		Name:        "weather",
+		Description: "Weather questions",
+		Cases: []*eval.Case{{
+			ID: "paris",
+			Conversation: []*eval.Invocation{{
+				InvocationID:  "e-1",
+				UserContent:   genai.NewContentFromText("what is the weather in Paris?", genai.RoleUser),
+				FinalResponse: genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel),
+				IntermediateData: &eval.IntermediateData{
+					ToolUses: []*genai.FunctionCall{
+						// The keys of the arguments are kept.
+						{ID: "adk-1", Name: "weather", Args: map[string]any{"city": "Paris", "unit_system": "metric"}},
+					},
+					IntermediateResponses: []*eval.IntermediateResponse{
+						{Author: "weather_agent", Parts: []*genai.Part{{Text: "Let me check."}}},
+					},
+				},
+				CreationTimestamp: 1747337309.2360144,
+			}},
+			SessionInput: &eval.SessionInput{
+				AppName: "weather_agent",
+				UserID:  "user",
+				State:   map[string]any{"preferred_unit": "metric"},
+			},
+			CreationTimestamp: 1747337309.2360282,
+		}},
+		CreationTimestamp: 1747337309.2360387,
+	}
+	if diff := cmp.Diff(want, set); diff != "" {
+		t.Errorf("LoadSet() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadSet_IDFromFileName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "smoke.evalset.json")
+	if err := os.WriteFile(path, []byte(`{"evalCases": [{"evalId": "hello", "conversation": [{"userContent": {"parts": [{"text": "hello"}]}}]}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := eval.LoadSet(path)
+	if err != nil {
+		t.Fatalf("LoadSet() error = %v", err)
+	}
+	if set.ID != "smoke" || set.Case("hello") == nil {
+		t.Errorf("LoadSet() = %+v, want set smoke with case hello", set)
+	}
+}
+
+func TestParseSet_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"not json":           `{`,
+		"case without id":    `{"eval_cases": [{"conversation": []}]}`,
+		"no user content":    `{"eval_cases": [{"eval_id": "a", "conversation": [{"final_response": {"parts": [{"text": "hi"}]}}]}]}`,
+		"invalid pair":       `{"eval_cases": [{"eval_id": "a", "conversation": [{"user_content": {}, "intermediate_data": {"intermediate_responses": [["agent"]]}}]}]}`,
+		"wrong type of case": `{"eval_cases": [1]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := eval.ParseSet([]byte(data)); err == nil {
+				t.Error("ParseSet() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	setFileSuffix    = ".evalset.json"
+	resultFileSuffix = ".evalset_result.json"
+	resultsDir       = "eval_history"
+)
+
+// LocalFileService returns an eval service storing the eval sets and results
+// as JSON files in dir. The eval sets of an app are stored in
+// {dir}/{app_name}/{eval_set_id}.evalset.json, and its results in
+// {dir}/{app_name}/eval_history/{eval_result_id}.evalset_result.json.
+// Thread-safe within the process.
+func LocalFileService(dir string) (Service, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the eval directory: %w", err)
+	}
+	return &localFileService{dir: dir}, nil
+}
+
+type localFileService struct {
+	dir string
+	// mu serializes the read-modify-write of the eval set files.
+	mu sync.Mutex
+}
+
+func (s *localFileService) setPath(appName, setID string) string {
+	return filepath.Join(s.dir, appName, setID+setFileSuffix)
+}
+
+func (s *localFileService) resultPath(appName, resultID string) string {
+	return filepath.Join(s.dir, appName, resultsDir, resultID+resultFileSuffix)
+}
+
+func (s *localFileService) CreateSet(ctx context.Context, appName string, set *Set) error {
+	if err := validateIDs(appName, set.ID); err != nil {
+		return err
+	}
+	for _, c := range set.Cases {
+		if err := validateIDs(c.ID); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.setPath(appName, set.ID)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("eval set %q: %w", set.ID, ErrAlreadyExists)
+	}
+	if set.CreationTimestamp == 0 {
+		set.CreationTimestamp = timestamp(time.Now())
+	}
+	return writeJSON(path, set)
+}
+
+func (s *localFileService) GetSet(ctx context.Context, appName, setID string) (*Set, error) {
+	if err := validateIDs(appName, setID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readSet(appName, setID)
+}
+
+func (s *localFileService) readSet(appName, setID string) (*Set, error) {
+	var set Set
+	if err := readJSON(s.setPath(appName, setID), &set); err != nil {
+		return nil, fmt.Errorf("eval set %q: %w", setID, err)
+	}
+	return &set, nil
+}
+
+func (s *localFileService) ListSets(ctx context.Context, appName string) ([]string, error) {
+	if err := validateIDs(appName); err != nil {
+		return nil, err
+	}
+	return listFiles(filepath.Join(s.dir, appName), setFileSuffix)
+}
+
+func (s *localFileService) DeleteSet(ctx context.Context, appName, setID string) error {
+	if err := validateIDs(appName, setID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.setPath(appName, setID)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("eval set %q: %w", setID, ErrNotFound)
+		}
+		return fmt.Errorf("failed to delete eval set %q: %w", setID, err)
+	}
+	return nil
+}
+
+func (s *localFileService) AddCase(ctx context.Context, appName, setID string, c *Case) error {
+	return s.updateSet(appName, setID, func(set *Set) error {
+		return addCase(set, c)
+	})
+}
+
+func (s *localFileService) UpdateCase(ctx context.Context, appName, setID string, c *Case) error {
+	return s.updateSet(appName, setID, func(set *Set) error {
+		return updateCase(set, c)
+	})
+}
+
+func (s *localFileService) DeleteCase(ctx context.Context, appName, setID, caseID string) error {
+	return s.updateSet(appName, setID, func(set *Set) error {
+		return deleteCase(set, caseID)
+	})
+}
+
+// updateSet applies update to the stored eval set, and writes it back if
+// update succeeds.
+func (s *localFileService) updateSet(appName, setID string, update func(*Set) error) error {
+	if err := validateIDs(appName, setID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set, err := s.readSet(appName, setID)
+	if err != nil {
+		return err
+	}
+	if err := update(set); err != nil {
+		return err
+	}
+	return writeJSON(s.setPath(appName, setID), set)
+}
+
+func (s *localFileService) SaveResult(ctx context.Context, appName string, result *SetResult) error {
+	if err := validateIDs(appName, result.ID); err != nil {
+		return err
+	}
+	return writeJSON(s.resultPath(appName, result.ID), result)
+}
+
+func (s *localFileService) GetResult(ctx context.Context, appName, resultID string) (*SetResult, error) {
+	if err := validateIDs(appName, resultID); err != nil {
+		return nil, err
+	}
+	var result SetResult
+	if err := readJSON(s.resultPath(appName, resultID), &result); err != nil {
+		return nil, fmt.Errorf("eval result %q: %w", resultID, err)
+	}
+	return &result, nil
+}
+
+func (s *localFileService) ListResults(ctx context.Context, appName string) ([]string, error) {
+	if err := validateIDs(appName); err != nil {
+		return nil, err
+	}
+	return listFiles(filepath.Join(s.dir, appName, resultsDir), resultFileSuffix)
+}
+
+// writeJSON writes v to the file at path, creating its directory if needed.
+// The file is replaced atomically so that readers never see a partial file.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filepath.Base(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// readJSON reads the file at path into v. It returns ErrNotFound if the file
+// doesn't exist.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// listFiles returns the sorted names of the files in dir with the suffix,
+// without the suffix. A missing dir has no files.
+func listFiles(dir, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), suffix); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
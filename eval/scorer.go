@@ -0,0 +1,232 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"google.golang.org/genai"
+)
+
+// Names of the built-in metrics.
+const (
+	// MetricToolTrajectoryAvgScore scores an invocation 1 if the agents made
+	// exactly the expected tool calls, with the expected arguments, in the
+	// expected order, and 0 otherwise. See [TrajectoryExactMatch].
+	MetricToolTrajectoryAvgScore = "tool_trajectory_avg_score"
+	// MetricToolTrajectoryPartialScore scores an invocation with the
+	// fraction of the expected tool calls the agents made in the expected
+	// order. See [TrajectoryPartialMatch].
+	MetricToolTrajectoryPartialScore = "tool_trajectory_partial_score"
+	// MetricResponseMatchScore scores the similarity of the final responses
+	// with the expected ones. See [ResponseMatch].
+	MetricResponseMatchScore = "response_match_score"
+)
+
+// builtinScorers maps the names of the built-in metrics to their scorers.
+var builtinScorers = map[string]Scorer{
+	MetricToolTrajectoryAvgScore:     TrajectoryExactMatch(),
+	MetricToolTrajectoryPartialScore: TrajectoryPartialMatch(),
+	MetricResponseMatchScore:         ResponseMatch(),
+}
+
+// Scorer scores an invocation of an agent against the expected one.
+type Scorer interface {
+	// Score returns the score of the actual invocation, between 0 and 1.
+	Score(ctx context.Context, actual, expected *Invocation) (float64, error)
+}
+
+// ScorerFunc is an adapter to use functions as a [Scorer].
+type ScorerFunc func(ctx context.Context, actual, expected *Invocation) (float64, error)
+
+// Score implements Scorer.
+func (f ScorerFunc) Score(ctx context.Context, actual, expected *Invocation) (float64, error) {
+	return f(ctx, actual, expected)
+}
+
+// Criterion is a scorer and the minimal score an eval case must reach, on
+// average over its invocations, to pass.
+type Criterion struct {
+	// Name is the name of the metric reported in the results.
+	Name      string
+	Scorer    Scorer
+	Threshold float64
+}
+
+// Metric is a built-in metric an evaluation is scored with.
+type Metric struct {
+	// Name is the name of the metric, e.g. MetricResponseMatchScore.
+	Name string `json:"metricName"`
+	// Threshold is the minimal score for the metric to pass.
+	Threshold float64 `json:"threshold"`
+}
+
+// Criterion returns the criterion of the built-in metric.
+func (m Metric) Criterion() (Criterion, error) {
+	scorer, ok := builtinScorers[m.Name]
+	if !ok {
+		return Criterion{}, fmt.Errorf("unsupported eval metric %q", m.Name)
+	}
+	return Criterion{Name: m.Name, Scorer: scorer, Threshold: m.Threshold}, nil
+}
+
+// DefaultMetrics are the metrics used by [Run] when no criteria are
+// configured.
+var DefaultMetrics = []Metric{
+	{Name: MetricToolTrajectoryAvgScore, Threshold: 1.0},
+	{Name: MetricResponseMatchScore, Threshold: 0.8},
+}
+
+// TrajectoryExactMatch returns a scorer scoring 1 if the agents made exactly
+// the expected tool calls, with the expected arguments, in the expected
+// order, and 0 otherwise.
+func TrajectoryExactMatch() Scorer {
+	return ScorerFunc(func(_ context.Context, actual, expected *Invocation) (float64, error) {
+		got, want := toolUses(actual), toolUses(expected)
+		if len(got) != len(want) {
+			return 0, nil
+		}
+		for i := range got {
+			if !sameCall(got[i], want[i]) {
+				return 0, nil
+			}
+		}
+		return 1, nil
+	})
+}
+
+// TrajectoryPartialMatch returns a scorer scoring the fraction of the
+// expected tool calls the agents made in the expected order, i.e. the length
+// of the longest common subsequence of the calls over the number of expected
+// calls. The other calls are ignored. It scores 1 if no tool calls are
+// expected.
+func TrajectoryPartialMatch() Scorer {
+	return ScorerFunc(func(_ context.Context, actual, expected *Invocation) (float64, error) {
+		got, want := toolUses(actual), toolUses(expected)
+		if len(want) == 0 {
+			return 1, nil
+		}
+		return float64(commonCalls(got, want)) / float64(len(want)), nil
+	})
+}
+
+// commonCalls returns the length of the longest common subsequence of the
+// function calls.
+func commonCalls(a, b []*genai.FunctionCall) int {
+	// lengths[j] is the length for a[:i] and b[:j].
+	lengths := make([]int, len(b)+1)
+	for i := range a {
+		prev := 0 // lengths[j-1] for a[:i-1]
+		for j := range b {
+			cur := lengths[j+1]
+			if sameCall(a[i], b[j]) {
+				lengths[j+1] = prev + 1
+			} else {
+				lengths[j+1] = max(lengths[j+1], lengths[j])
+			}
+			prev = cur
+		}
+	}
+	return lengths[len(b)]
+}
+
+func toolUses(inv *Invocation) []*genai.FunctionCall {
+	if inv.IntermediateData == nil {
+		return nil
+	}
+	return inv.IntermediateData.ToolUses
+}
+
+// sameCall reports whether the function calls have the same name and
+// arguments. The call ids are ignored.
+func sameCall(a, b *genai.FunctionCall) bool {
+	return a.Name == b.Name && reflect.DeepEqual(normalize(a.Args), normalize(b.Args))
+}
+
+// normalize returns the JSON representation of args, so that the arguments
+// recorded in memory compare equal to the ones loaded from JSON.
+func normalize(args map[string]any) any {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return args
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return args
+	}
+	return v
+}
+
+// ResponseMatch returns a scorer scoring the similarity of the final
+// response with the expected one, as the ROUGE-1 F1 score of their words.
+func ResponseMatch() Scorer {
+	return ScorerFunc(func(_ context.Context, actual, expected *Invocation) (float64, error) {
+		return rouge1(contentText(actual.FinalResponse), contentText(expected.FinalResponse)), nil
+	})
+}
+
+// rouge1 returns the ROUGE-1 F1 score of the words of the candidate against
+// the reference.
+func rouge1(candidate, reference string) float64 {
+	got, want := words(candidate), words(reference)
+	if len(got) == 0 && len(want) == 0 {
+		return 1
+	}
+	if len(got) == 0 || len(want) == 0 {
+		return 0
+	}
+	counts := map[string]int{}
+	for _, w := range want {
+		counts[w]++
+	}
+	overlap := 0
+	for _, w := range got {
+		if counts[w] > 0 {
+			counts[w]--
+			overlap++
+		}
+	}
+	if overlap == 0 {
+		return 0
+	}
+	precision := float64(overlap) / float64(len(got))
+	recall := float64(overlap) / float64(len(want))
+	return 2 * precision * recall / (precision + recall)
+}
+
+// words returns the lowercase alphanumeric words of s.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func contentText(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
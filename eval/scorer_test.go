@@ -0,0 +1,259 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/internal/testutil"
+)
+
+func invocation(response string, calls ...*genai.FunctionCall) *eval.Invocation {
+	return &eval.Invocation{
+		UserContent:      genai.NewContentFromText("what is the weather?", genai.RoleUser),
+		FinalResponse:    genai.NewContentFromText(response, genai.RoleModel),
+		IntermediateData: &eval.IntermediateData{ToolUses: calls},
+	}
+}
+
+func call(name, city string) *genai.FunctionCall {
+	return &genai.FunctionCall{Name: name, Args: map[string]any{"city": city}}
+}
+
+func TestTrajectoryScorers(t *testing.T) {
+	expected := invocation("", call("geocode", "Paris"), call("weather", "Paris"))
+	tests := []struct {
+		name        string
+		calls       []*genai.FunctionCall
+		wantExact   float64
+		wantPartial float64
+	}{
+		{
+			name:        "same calls",
+			calls:       []*genai.FunctionCall{call("geocode", "Paris"), call("weather", "Paris")},
+			wantExact:   1,
+			wantPartial: 1,
+		},
+		{
+			name:        "extra call",
+			calls:       []*genai.FunctionCall{call("geocode", "Paris"), call("log", "Paris"), call("weather", "Paris")},
+			wantExact:   0,
+			wantPartial: 1,
+		},
+		{
+			name:        "missing call",
+			calls:       []*genai.FunctionCall{call("weather", "Paris")},
+			wantExact:   0,
+			wantPartial: 0.5,
+		},
+		{
+			name:        "wrong order",
+			calls:       []*genai.FunctionCall{call("weather", "Paris"), call("geocode", "Paris")},
+			wantExact:   0,
+			wantPartial: 0.5,
+		},
+		{
+			name:        "wrong arguments",
+			calls:       []*genai.FunctionCall{call("geocode", "London"), call("weather", "London")},
+			wantExact:   0,
+			wantPartial: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := invocation("", tt.calls...)
+			if got, err := eval.TrajectoryExactMatch().Score(t.Context(), actual, expected); err != nil || got != tt.wantExact {
+				t.Errorf("TrajectoryExactMatch() = %v, %v, want %v", got, err, tt.wantExact)
+			}
+			if got, err := eval.TrajectoryPartialMatch().Score(t.Context(), actual, expected); err != nil || got != tt.wantPartial {
+				t.Errorf("TrajectoryPartialMatch() = %v, %v, want %v", got, err, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestResponseMatch(t *testing.T) {
+	tests := []struct {
+		actual, expected string
+		want             float64
+	}{
+		{"It's sunny in Paris.", "it's SUNNY in Paris", 1},
+		{"sunny", "It's sunny in Paris.", 2 * 1 * 0.2 / 1.2},
+		{"rainy", "sunny", 0},
+		{"", "", 1},
+		{"", "sunny", 0},
+	}
+	for _, tt := range tests {
+		got, err := eval.ResponseMatch().Score(t.Context(), invocation(tt.actual), invocation(tt.expected))
+		if err != nil || got-tt.want > 1e-9 || tt.want-got > 1e-9 {
+			t.Errorf("ResponseMatch(%q, %q) = %v, %v, want %v", tt.actual, tt.expected, got, err, tt.want)
+		}
+	}
+}
+
+func TestLLMJudge(t *testing.T) {
+	tests := []struct {
+		name    string
+		verdict string
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			verdict: "Both say it's sunny.\n\"is_the_agent_response_valid\": \"valid\"",
+			want:    1,
+		},
+		{
+			name:    "invalid",
+			verdict: "The reference says sunny, the response rainy.\n\"is_the_agent_response_valid\": \"invalid\"",
+			want:    0,
+		},
+		{
+			name:    "last verdict wins",
+			verdict: "The format is \"is_the_agent_response_valid\": \"valid\".\n\"is_the_agent_response_valid\": \"invalid\"",
+			want:    0,
+		},
+		{
+			name:    "no verdict",
+			verdict: "I can't tell.",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			judge := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText(tt.verdict, genai.RoleModel)}}
+			got, err := eval.LLMJudge(judge).Score(t.Context(), invocation("It's sunny."), invocation("It's sunny in Paris."))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Score() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+			if len(judge.Requests) != 1 {
+				t.Fatalf("judge got %d requests, want 1", len(judge.Requests))
+			}
+			prompt := judge.Requests[0].Contents[0].Parts[0].Text
+			for _, s := range []string{"what is the weather?", "It's sunny in Paris.", "It's sunny."} {
+				if !strings.Contains(prompt, s) {
+					t.Errorf("judge prompt doesn't contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_Criteria(t *testing.T) {
+	c := &eval.Case{
+		ID:           "case",
+		Conversation: []*eval.Invocation{invocation("It's sunny in Paris.")},
+	}
+	errJudge := errors.New("judge unavailable")
+	tests := []struct {
+		name       string
+		criteria   []eval.Criterion
+		wantStatus eval.Status
+	}{
+		{
+			name: "custom scorer passes",
+			criteria: []eval.Criterion{{
+				Name:      "always_half",
+				Scorer:    eval.ScorerFunc(func(context.Context, *eval.Invocation, *eval.Invocation) (float64, error) { return 0.5, nil }),
+				Threshold: 0.5,
+			}},
+			wantStatus: eval.StatusPassed,
+		},
+		{
+			name: "judge fails the case",
+			criteria: []eval.Criterion{{
+				Name:      eval.MetricFinalResponseMatchV2,
+				Scorer:    eval.LLMJudge(&testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText(`"is_the_agent_response_valid": "invalid"`, genai.RoleModel)}}),
+				Threshold: 1,
+			}},
+			wantStatus: eval.StatusFailed,
+		},
+		{
+			name: "scorer error",
+			criteria: []eval.Criterion{{
+				Name:   "broken",
+				Scorer: eval.ScorerFunc(func(context.Context, *eval.Invocation, *eval.Invocation) (float64, error) { return 0, errJudge }),
+			}},
+			wantStatus: eval.StatusNotEvaluated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := weatherAgent(t, genai.NewContentFromText("It's rainy in Paris.", genai.RoleModel))
+			results, err := eval.Run(t.Context(), eval.Config{Agent: a, Criteria: tt.criteria}, []*eval.Case{c})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			got := results[0]
+			if got.FinalStatus != tt.wantStatus {
+				t.Errorf("FinalStatus = %v, want %v", got.FinalStatus, tt.wantStatus)
+			}
+			if len(got.OverallMetricResults) != 1 || got.OverallMetricResults[0].MetricName != tt.criteria[0].Name {
+				t.Errorf("OverallMetricResults = %v, want only %q", got.OverallMetricResults, tt.criteria[0].Name)
+			}
+			if tt.wantStatus == eval.StatusNotEvaluated && got.OverallMetricResults[0].Error != errJudge.Error() {
+				t.Errorf("metric error = %q, want %q", got.OverallMetricResults[0].Error, errJudge)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	score := func(s float64) *float64 { return &s }
+	results := []*eval.CaseResult{
+		{FinalStatus: eval.StatusPassed, OverallMetricResults: []*eval.MetricResult{{MetricName: "m", Score: score(1)}}},
+		{FinalStatus: eval.StatusFailed, OverallMetricResults: []*eval.MetricResult{{MetricName: "m", Score: score(0.5)}}},
+		{FinalStatus: eval.StatusNotEvaluated},
+		{FinalStatus: eval.StatusPassed, OverallMetricResults: []*eval.MetricResult{{MetricName: "m", Score: score(0.9)}}},
+	}
+	got := eval.Summarize(results)
+	if got.Total != 4 || got.Passed != 2 || got.Failed != 1 || got.NotEvaluated != 1 || got.PassRate != 0.5 {
+		t.Errorf("Summarize() = %+v, want 4 cases, 2 passed, 1 failed, 1 not evaluated", got)
+	}
+	if avg := got.MetricAverages["m"]; avg-0.8 > 1e-9 || 0.8-avg > 1e-9 {
+		t.Errorf("Summarize() average of m = %v, want 0.8", avg)
+	}
+}
+
+func TestRunInTest(t *testing.T) {
+	set, err := eval.LoadSet("testdata/weather.evalset.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The agent answers like the recorded conversation, with the same tool call.
+	a := weatherAgent(t,
+		&genai.Content{
+			Role: genai.RoleModel,
+			Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+				Name: "weather",
+				Args: map[string]any{"city": "Paris", "unit_system": "metric"},
+			}}},
+		},
+		genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel),
+	)
+	results := eval.RunInTest(t, a, set, nil)
+	if len(results) != 1 || results[0].FinalStatus != eval.StatusPassed {
+		t.Errorf("RunInTest() = %v, want the passed case", results)
+	}
+}
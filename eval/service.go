@@ -0,0 +1,273 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned by a [Service] when the eval set, case or
+	// result doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned by a [Service] when creating an eval set
+	// or case which already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInvalidID is returned by a [Service] when an app name or id is not
+	// a valid file name.
+	ErrInvalidID = errors.New("invalid id")
+)
+
+// Service stores the eval sets and the evaluation results of apps.
+type Service interface {
+	// CreateSet creates an eval set, and sets its creation timestamp if
+	// zero. It fails with ErrAlreadyExists if the app has an eval set with
+	// the same id.
+	CreateSet(ctx context.Context, appName string, set *Set) error
+	// GetSet returns an eval set with its cases.
+	GetSet(ctx context.Context, appName, setID string) (*Set, error)
+	// ListSets returns the ids of the eval sets of the app, sorted.
+	ListSets(ctx context.Context, appName string) ([]string, error)
+	// DeleteSet deletes an eval set with its cases.
+	DeleteSet(ctx context.Context, appName, setID string) error
+
+	// AddCase adds an eval case to an eval set, and sets its creation
+	// timestamp if zero. It fails with ErrAlreadyExists if the set has a case
+	// with the same id.
+	AddCase(ctx context.Context, appName, setID string, c *Case) error
+	// UpdateCase replaces the eval case with the same id in an eval set.
+	UpdateCase(ctx context.Context, appName, setID string, c *Case) error
+	// DeleteCase deletes an eval case from an eval set.
+	DeleteCase(ctx context.Context, appName, setID, caseID string) error
+
+	// SaveResult saves the result of an evaluation of an eval set.
+	SaveResult(ctx context.Context, appName string, result *SetResult) error
+	// GetResult returns a saved evaluation result.
+	GetResult(ctx context.Context, appName, resultID string) (*SetResult, error)
+	// ListResults returns the ids of the saved evaluation results of the
+	// app, sorted.
+	ListResults(ctx context.Context, appName string) ([]string, error)
+}
+
+// idRegexp matches the valid app names and ids of eval sets, cases and
+// results. They are used as file names by [LocalFileService].
+var idRegexp = regexp.MustCompile(`^[a-zA-Z0-9_\-.]+$`)
+
+// validateIDs checks that the ids are valid, see idRegexp.
+func validateIDs(ids ...string) error {
+	for _, id := range ids {
+		if !idRegexp.MatchString(id) || id == "." || id == ".." {
+			return fmt.Errorf("%w %q, want letters, digits, '_', '-' and '.'", ErrInvalidID, id)
+		}
+	}
+	return nil
+}
+
+// InMemoryService returns a new in-memory implementation of the eval
+// service. Thread-safe.
+func InMemoryService() Service {
+	return &inMemoryService{
+		sets:    map[appKey]*Set{},
+		results: map[appKey]*SetResult{},
+	}
+}
+
+type appKey struct {
+	appName, id string
+}
+
+type inMemoryService struct {
+	mu      sync.RWMutex
+	sets    map[appKey]*Set
+	results map[appKey]*SetResult
+}
+
+func (s *inMemoryService) CreateSet(ctx context.Context, appName string, set *Set) error {
+	if err := validateIDs(appName, set.ID); err != nil {
+		return err
+	}
+	for _, c := range set.Cases {
+		if err := validateIDs(c.ID); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := appKey{appName, set.ID}
+	if _, ok := s.sets[key]; ok {
+		return fmt.Errorf("eval set %q: %w", set.ID, ErrAlreadyExists)
+	}
+	if set.CreationTimestamp == 0 {
+		set.CreationTimestamp = timestamp(time.Now())
+	}
+	s.sets[key] = clone(set)
+	return nil
+}
+
+func (s *inMemoryService) GetSet(ctx context.Context, appName, setID string) (*Set, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set, ok := s.sets[appKey{appName, setID}]
+	if !ok {
+		return nil, fmt.Errorf("eval set %q: %w", setID, ErrNotFound)
+	}
+	return clone(set), nil
+}
+
+func (s *inMemoryService) ListSets(ctx context.Context, appName string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return listIDs(s.sets, appName), nil
+}
+
+func (s *inMemoryService) DeleteSet(ctx context.Context, appName, setID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := appKey{appName, setID}
+	if _, ok := s.sets[key]; !ok {
+		return fmt.Errorf("eval set %q: %w", setID, ErrNotFound)
+	}
+	delete(s.sets, key)
+	return nil
+}
+
+func (s *inMemoryService) AddCase(ctx context.Context, appName, setID string, c *Case) error {
+	return s.updateSet(appName, setID, func(set *Set) error {
+		return addCase(set, c)
+	})
+}
+
+func (s *inMemoryService) UpdateCase(ctx context.Context, appName, setID string, c *Case) error {
+	return s.updateSet(appName, setID, func(set *Set) error {
+		return updateCase(set, c)
+	})
+}
+
+func (s *inMemoryService) DeleteCase(ctx context.Context, appName, setID, caseID string) error {
+	return s.updateSet(appName, setID, func(set *Set) error {
+		return deleteCase(set, caseID)
+	})
+}
+
+// updateSet applies update to a copy of the eval set, and stores the copy if
+// update succeeds.
+func (s *inMemoryService) updateSet(appName, setID string, update func(*Set) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := appKey{appName, setID}
+	set, ok := s.sets[key]
+	if !ok {
+		return fmt.Errorf("eval set %q: %w", setID, ErrNotFound)
+	}
+	set = clone(set)
+	if err := update(set); err != nil {
+		return err
+	}
+	s.sets[key] = set
+	return nil
+}
+
+func (s *inMemoryService) SaveResult(ctx context.Context, appName string, result *SetResult) error {
+	if err := validateIDs(appName, result.ID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[appKey{appName, result.ID}] = clone(result)
+	return nil
+}
+
+func (s *inMemoryService) GetResult(ctx context.Context, appName, resultID string) (*SetResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result, ok := s.results[appKey{appName, resultID}]
+	if !ok {
+		return nil, fmt.Errorf("eval result %q: %w", resultID, ErrNotFound)
+	}
+	return clone(result), nil
+}
+
+func (s *inMemoryService) ListResults(ctx context.Context, appName string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return listIDs(s.results, appName), nil
+}
+
+// listIDs returns the sorted ids of the values of the app.
+func listIDs[V any](m map[appKey]V, appName string) []string {
+	ids := []string{}
+	for key := range maps.Keys(m) {
+		if key.appName == appName {
+			ids = append(ids, key.id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func addCase(set *Set, c *Case) error {
+	if err := validateIDs(c.ID); err != nil {
+		return err
+	}
+	if set.Case(c.ID) != nil {
+		return fmt.Errorf("eval case %q in eval set %q: %w", c.ID, set.ID, ErrAlreadyExists)
+	}
+	if c.CreationTimestamp == 0 {
+		c.CreationTimestamp = timestamp(time.Now())
+	}
+	set.Cases = append(set.Cases, c)
+	return nil
+}
+
+func updateCase(set *Set, c *Case) error {
+	i := slices.IndexFunc(set.Cases, func(other *Case) bool { return other.ID == c.ID })
+	if i < 0 {
+		return fmt.Errorf("eval case %q in eval set %q: %w", c.ID, set.ID, ErrNotFound)
+	}
+	set.Cases[i] = c
+	return nil
+}
+
+func deleteCase(set *Set, caseID string) error {
+	i := slices.IndexFunc(set.Cases, func(other *Case) bool { return other.ID == caseID })
+	if i < 0 {
+		return fmt.Errorf("eval case %q in eval set %q: %w", caseID, set.ID, ErrNotFound)
+	}
+	set.Cases = slices.Delete(set.Cases, i, i+1)
+	return nil
+}
+
+// clone returns a deep copy of v. Eval sets and results are copied in and
+// out of the in-memory service so that callers can't modify the stored
+// values.
+func clone[T any](v *T) *T {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("eval: failed to copy %T: %v", v, err))
+	}
+	var c T
+	if err := json.Unmarshal(data, &c); err != nil {
+		panic(fmt.Sprintf("eval: failed to copy %T: %v", v, err))
+	}
+	return &c
+}
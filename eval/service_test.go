@@ -0,0 +1,193 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/eval"
+)
+
+func TestService(t *testing.T) {
+	services := map[string]func(t *testing.T) eval.Service{
+		"in memory": func(t *testing.T) eval.Service {
+			return eval.InMemoryService()
+		},
+		"local file": func(t *testing.T) eval.Service {
+			s, err := eval.LocalFileService(t.TempDir())
+			if err != nil {
+				t.Fatalf("LocalFileService() error = %v", err)
+			}
+			return s
+		},
+	}
+	for name, newService := range services {
+		t.Run(name, func(t *testing.T) {
+			testService(t, newService(t))
+		})
+	}
+}
+
+func newCase(id, text string) *eval.Case {
+	return &eval.Case{
+		ID: id,
+		Conversation: []*eval.Invocation{{
+			UserContent:   genai.NewContentFromText(text, genai.RoleUser),
+			FinalResponse: genai.NewContentFromText("answer", genai.RoleModel),
+		}},
+	}
+}
+
+func testService(t *testing.T, s eval.Service) {
+	ctx := t.Context()
+
+	set := &eval.Set{ID: "set", Name: "Set", Cases: []*eval.Case{newCase("a", "hello")}}
+	if err := s.CreateSet(ctx, "app", set); err != nil {
+		t.Fatalf("CreateSet() error = %v", err)
+	}
+	if err := s.CreateSet(ctx, "app", set); !errors.Is(err, eval.ErrAlreadyExists) {
+		t.Errorf("CreateSet() of an existing set error = %v, want ErrAlreadyExists", err)
+	}
+	if err := s.CreateSet(ctx, "app", &eval.Set{ID: "../set"}); !errors.Is(err, eval.ErrInvalidID) {
+		t.Errorf("CreateSet() with an invalid id error = %v, want ErrInvalidID", err)
+	}
+	if err := s.CreateSet(ctx, "app", &eval.Set{ID: "other"}); err != nil {
+		t.Fatalf("CreateSet() error = %v", err)
+	}
+
+	if ids, err := s.ListSets(ctx, "app"); err != nil || !cmp.Equal(ids, []string{"other", "set"}) {
+		t.Errorf("ListSets() = %v, %v, want [other set]", ids, err)
+	}
+	if ids, err := s.ListSets(ctx, "other_app"); err != nil || len(ids) != 0 {
+		t.Errorf("ListSets() of another app = %v, %v, want none", ids, err)
+	}
+
+	if err := s.AddCase(ctx, "app", "set", newCase("b", "bye")); err != nil {
+		t.Fatalf("AddCase() error = %v", err)
+	}
+	if err := s.AddCase(ctx, "app", "set", newCase("b", "bye")); !errors.Is(err, eval.ErrAlreadyExists) {
+		t.Errorf("AddCase() of an existing case error = %v, want ErrAlreadyExists", err)
+	}
+	if err := s.AddCase(ctx, "app", "missing", newCase("b", "bye")); !errors.Is(err, eval.ErrNotFound) {
+		t.Errorf("AddCase() to a missing set error = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateCase(ctx, "app", "set", newCase("a", "hi")); err != nil {
+		t.Fatalf("UpdateCase() error = %v", err)
+	}
+	if err := s.UpdateCase(ctx, "app", "set", newCase("c", "hi")); !errors.Is(err, eval.ErrNotFound) {
+		t.Errorf("UpdateCase() of a missing case error = %v, want ErrNotFound", err)
+	}
+
+	got, err := s.GetSet(ctx, "app", "set")
+	if err != nil {
+		t.Fatalf("GetSet() error = %v", err)
+	}
+	want := &eval.Set{ID: "set", Name: "Set", Cases: []*eval.Case{newCase("a", "hi"), newCase("b", "bye")}}
+	ignoreTimestamps := cmp.Options{
+		cmpopts.IgnoreFields(eval.Set{}, "CreationTimestamp"),
+		cmpopts.IgnoreFields(eval.Case{}, "CreationTimestamp"),
+	}
+	if diff := cmp.Diff(want, got, ignoreTimestamps); diff != "" {
+		t.Errorf("GetSet() mismatch (-want +got):\n%s", diff)
+	}
+	if got.CreationTimestamp == 0 || got.Cases[1].CreationTimestamp == 0 {
+		t.Errorf("GetSet() = %+v, want the creation timestamps of the set and added case", got)
+	}
+
+	if err := s.DeleteCase(ctx, "app", "set", "a"); err != nil {
+		t.Fatalf("DeleteCase() error = %v", err)
+	}
+	if err := s.DeleteCase(ctx, "app", "set", "a"); !errors.Is(err, eval.ErrNotFound) {
+		t.Errorf("DeleteCase() of a missing case error = %v, want ErrNotFound", err)
+	}
+	if got, err := s.GetSet(ctx, "app", "set"); err != nil || len(got.Cases) != 1 || got.Cases[0].ID != "b" {
+		t.Errorf("GetSet() after DeleteCase() = %v, %v, want case b only", got, err)
+	}
+
+	if err := s.DeleteSet(ctx, "app", "set"); err != nil {
+		t.Fatalf("DeleteSet() error = %v", err)
+	}
+	if _, err := s.GetSet(ctx, "app", "set"); !errors.Is(err, eval.ErrNotFound) {
+		t.Errorf("GetSet() of a deleted set error = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteSet(ctx, "app", "set"); !errors.Is(err, eval.ErrNotFound) {
+		t.Errorf("DeleteSet() of a deleted set error = %v, want ErrNotFound", err)
+	}
+
+	score := 1.0
+	result := &eval.SetResult{
+		ID:    "result",
+		SetID: "set",
+		CaseResults: []*eval.CaseResult{{
+			SetID:       "set",
+			CaseID:      "a",
+			FinalStatus: eval.StatusPassed,
+			OverallMetricResults: []*eval.MetricResult{
+				{MetricName: eval.MetricResponseMatchScore, Threshold: 0.8, Score: &score, Status: eval.StatusPassed},
+			},
+		}},
+	}
+	if err := s.SaveResult(ctx, "app", result); err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+	if got, err := s.GetResult(ctx, "app", "result"); err != nil || !cmp.Equal(result, got) {
+		t.Errorf("GetResult() = %v, %v, want the saved result", got, err)
+	}
+	if _, err := s.GetResult(ctx, "app", "missing"); !errors.Is(err, eval.ErrNotFound) {
+		t.Errorf("GetResult() of a missing result error = %v, want ErrNotFound", err)
+	}
+	if ids, err := s.ListResults(ctx, "app"); err != nil || !cmp.Equal(ids, []string{"result"}) {
+		t.Errorf("ListResults() = %v, %v, want [result]", ids, err)
+	}
+}
+
+func TestLocalFileService_Files(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	s, err := eval.LocalFileService(dir)
+	if err != nil {
+		t.Fatalf("LocalFileService() error = %v", err)
+	}
+	if err := s.CreateSet(ctx, "app", &eval.Set{ID: "set", Cases: []*eval.Case{newCase("a", "hello")}}); err != nil {
+		t.Fatalf("CreateSet() error = %v", err)
+	}
+	if err := s.SaveResult(ctx, "app", &eval.SetResult{ID: "result", SetID: "set"}); err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+	for _, path := range []string{
+		filepath.Join(dir, "app", "set.evalset.json"),
+		filepath.Join(dir, "app", "eval_history", "result.evalset_result.json"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("missing file: %v", err)
+		}
+	}
+
+	// Another service on the same directory sees the stored sets.
+	other, err := eval.LocalFileService(dir)
+	if err != nil {
+		t.Fatalf("LocalFileService() error = %v", err)
+	}
+	if got, err := other.GetSet(ctx, "app", "set"); err != nil || got.Case("a") == nil {
+		t.Errorf("GetSet() = %v, %v, want the stored set", got, err)
+	}
+}
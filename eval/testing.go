@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/agent"
+)
+
+// RunInTest evaluates the agent on the cases of the eval set with the
+// criteria, or DefaultMetrics if there are none, see [Run]. Each case is
+// reported as a subtest of t named after its id, failing if the case doesn't
+// pass; the summary of the evaluation is logged. It returns the results of
+// the cases, e.g. to check the scores further.
+//
+// It is meant for evaluations in Go tests:
+//
+//	func TestAgentEval(t *testing.T) {
+//		set, err := eval.LoadSet("testdata/weather.evalset.json")
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		eval.RunInTest(t, newWeatherAgent(), set, nil)
+//	}
+func RunInTest(t *testing.T, a agent.Agent, set *Set, criteria []Criterion) []*CaseResult {
+	t.Helper()
+	cfg := Config{Agent: a, SetID: set.ID, Criteria: criteria}
+	results, err := Run(t.Context(), cfg, set.Cases)
+	if err != nil {
+		t.Fatalf("eval set %q: %v", set.ID, err)
+	}
+	for _, result := range results {
+		t.Run(result.CaseID, func(t *testing.T) {
+			switch result.FinalStatus {
+			case StatusPassed:
+			case StatusNotEvaluated:
+				t.Errorf("eval case %q was not evaluated: %s", result.CaseID, notEvaluatedReason(result))
+			default:
+				t.Errorf("eval case %q failed: %s", result.CaseID, failedMetrics(result))
+			}
+		})
+	}
+	summary := Summarize(results)
+	t.Logf("eval set %q: %d/%d cases passed, metric averages: %v", set.ID, summary.Passed, summary.Total, summary.MetricAverages)
+	return results
+}
+
+// failedMetrics describes the overall metrics of the result below their
+// thresholds.
+func failedMetrics(result *CaseResult) string {
+	var failed []string
+	for _, m := range result.OverallMetricResults {
+		if m.Status == StatusFailed {
+			failed = append(failed, fmt.Sprintf("%s = %.3f, want >= %.3f", m.MetricName, *m.Score, m.Threshold))
+		}
+	}
+	return strings.Join(failed, "; ")
+}
+
+// notEvaluatedReason describes why the case or its metrics weren't
+// evaluated.
+func notEvaluatedReason(result *CaseResult) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	var reasons []string
+	for _, m := range result.OverallMetricResults {
+		if m.Status != StatusNotEvaluated {
+			continue
+		}
+		reason := m.Error
+		if reason == "" {
+			reason = "no invocation to score"
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", m.MetricName, reason))
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clock registers the clock app, an agent defined in code.
+package clock
+
+import (
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/dirloader"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func init() {
+	dirloader.Register("clock", New)
+}
+
+type currentTimeArgs struct {
+	// Location is an IANA time zone, e.g. Europe/Paris.
+	Location string `json:"location"`
+}
+
+func currentTime(ctx tool.Context, args currentTimeArgs) (map[string]string, error) {
+	loc, err := time.LoadLocation(args.Location)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"time": time.Now().In(loc).Format(time.RFC1123)}, nil
+}
+
+// New creates the agent of the clock app.
+func New(dir string) (agent.Agent, error) {
+	currentTimeTool, err := functiontool.New(functiontool.Config{
+		Name:        "current_time",
+		Description: "Returns the current time in a time zone.",
+	}, currentTime)
+	if err != nil {
+		return nil, err
+	}
+	return llmagent.New(llmagent.Config{
+		Name:        "clock_agent",
+		ModelName:   "gemini-2.5-flash",
+		Description: "Agent to tell the current time in a time zone.",
+		Instruction: "Answer questions about the current time with the current_time tool.",
+		Tools:       []tool.Tool{currentTimeTool},
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main serves several apps discovered in a directory: each
+// subdirectory of agents/ is an app, defined either by a root_agent.yaml
+// config (see the agentconfig package) or by a factory registered in code.
+//
+// Run it from this directory, e.g. go run . web api webui
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"google.golang.org/adk/agent/dirloader"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/full"
+
+	// registers the clock app
+	_ "google.golang.org/adk/examples/agentsdir/agents/clock"
+)
+
+func main() {
+	ctx := context.Background()
+
+	dir := os.Getenv("AGENTS_DIR")
+	if dir == "" {
+		dir = "agents"
+	}
+	agentLoader, err := dirloader.New(dir, dirloader.WithRoot("weather"))
+	if err != nil {
+		log.Fatalf("Failed to load agents: %v", err)
+	}
+
+	config := &launcher.Config{
+		AgentLoader: agentLoader,
+	}
+
+	l := full.NewLauncher()
+	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+	}
+}
@@ -43,7 +43,7 @@ import (
 //   - register it as MCP tool in the in-memory MCP server, using mcp.NewServer and mcp.Tool
 //
 // 2. GitHub's remote MCP server (https://github.com/github/github-mcp-server):
-//   - create http.Client with authenticated transport. In this case it's oauth2 transport with GitHub personal access token.
+//   - connect with mcptoolset.NewStreamableHTTP, authenticating with an oauth2 token source. In this case it's a GitHub personal access token.
 //   - use `export GITHUB_PAT=...` to set GitHub personal access token.
 
 type Input struct {
@@ -74,14 +74,13 @@ func localMCPTransport(ctx context.Context) mcp.Transport {
 	return clientTransport
 }
 
-func githubMCPTransport(ctx context.Context) mcp.Transport {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: os.Getenv("GITHUB_PAT")},
-	)
-	return &mcp.StreamableClientTransport{
-		Endpoint:   "https://api.githubcopilot.com/mcp/",
-		HTTPClient: oauth2.NewClient(ctx, ts),
-	}
+func githubMCPToolSet() (mcptoolset.Toolset, error) {
+	return mcptoolset.NewStreamableHTTP(mcptoolset.HTTPConfig{
+		Endpoint: "https://api.githubcopilot.com/mcp/",
+		TokenSource: oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: os.Getenv("GITHUB_PAT")},
+		),
+	})
 }
 
 func main() {
@@ -96,19 +95,18 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
-	var transport mcp.Transport
+	var mcpToolSet mcptoolset.Toolset
 	if strings.ToLower(os.Getenv("AGENT_MODE")) == "github" {
-		transport = githubMCPTransport(ctx)
+		mcpToolSet, err = githubMCPToolSet()
 	} else {
-		transport = localMCPTransport(ctx)
+		mcpToolSet, err = mcptoolset.New(mcptoolset.Config{
+			Transport: localMCPTransport(ctx),
+		})
 	}
-
-	mcpToolSet, err := mcptoolset.New(mcptoolset.Config{
-		Transport: transport,
-	})
 	if err != nil {
 		log.Fatalf("Failed to create MCP tool set: %v", err)
 	}
+	defer mcpToolSet.Close()
 
 	// Create LLMAgent with MCP tool set
 	a, err := llmagent.New(llmagent.Config{
@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main demonstrates an agent backed by a model served locally with
+// Ollama through its OpenAI-compatible API.
+//
+// Start Ollama and pull a model supporting tools first, e.g.:
+//
+//	ollama pull llama3.2
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model/openai"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func main() {
+	ctx := context.Background()
+
+	baseURL := os.Getenv("OLLAMA_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:11434/v1"
+	}
+	modelName := os.Getenv("OLLAMA_MODEL")
+	if modelName == "" {
+		modelName = "llama3.2"
+	}
+	// Ollama does not require an API key.
+	model, err := openai.NewModel(baseURL, "", modelName)
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	type Input struct {
+		City string `json:"city"`
+	}
+	type Output struct {
+		Weather string `json:"weather"`
+	}
+	handler := func(ctx tool.Context, input Input) (Output, error) {
+		return Output{Weather: "sunny, 22°C"}, nil
+	}
+	weatherTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "Returns the current weather in a city.",
+	}, handler)
+	if err != nil {
+		log.Fatalf("Failed to create tool: %v", err)
+	}
+
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "weather_agent",
+		Model:       model,
+		Description: "Agent to answer questions about the weather in a city.",
+		Instruction: "Answer questions about the weather using the get_weather tool.",
+		Tools: []tool.Tool{
+			weatherTool,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
+	config := &launcher.Config{
+		AgentLoader: agent.NewSingleLoader(a),
+	}
+
+	l := full.NewLauncher()
+	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+	}
+}
@@ -58,18 +58,8 @@ func main() {
 		log.Fatalf("Failed to create agent: %v", err)
 	}
 
-	userID, appName := "test_user", "test_app"
+	userID, appName, sessionID := "test_user", "test_app", "test_session"
 	sessionService := session.InMemoryService()
-	// Create session.
-	resp, err := sessionService.Create(ctx, &session.CreateRequest{
-		AppName: appName,
-		UserID:  userID,
-	})
-	if err != nil {
-		log.Fatalf("Failed to create the session service: %v", err)
-	}
-
-	session := resp.Session
 	artifactService := artifact.InMemoryService()
 	// Populate artifacts that can be described later.
 	imageBytes, err := os.ReadFile("animal_picture.png")
@@ -81,7 +71,7 @@ func main() {
 	_, err = artifactService.Save(ctx, &artifact.SaveRequest{
 		AppName:   appName,
 		UserID:    userID,
-		SessionID: session.ID(),
+		SessionID: sessionID,
 		FileName:  "animal_picture.png",
 		Part:      genai.NewPartFromBytes(imageBytes, "image/png"),
 	})
@@ -92,7 +82,7 @@ func main() {
 	_, err = artifactService.Save(ctx, &artifact.SaveRequest{
 		AppName:   appName,
 		UserID:    userID,
-		SessionID: session.ID(),
+		SessionID: sessionID,
 		FileName:  "haiku.txt",
 		Part: genai.NewPartFromText(
 			"An old silent pond..." +
@@ -108,6 +98,8 @@ func main() {
 		Agent:           llmagent,
 		SessionService:  sessionService,
 		ArtifactService: artifactService,
+		// The session is created by the first run.
+		AutoCreateSession: true,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create runner: %v", err)
@@ -126,20 +118,18 @@ func main() {
 
 		fmt.Print("\nAgent -> ")
 		streamingMode := agent.StreamingModeSSE
-		for event, err := range r.Run(ctx, userID, session.ID(), userMsg, agent.RunConfig{
+		for event, err := range r.Run(ctx, userID, sessionID, userMsg, agent.RunConfig{
 			StreamingMode: streamingMode,
 		}) {
 			if err != nil {
 				fmt.Printf("\nAGENT_ERROR: %v\n", err)
 			} else {
-				if event.LLMResponse.Content == nil {
+				if event == nil {
 					continue
 				}
-				for _, p := range event.LLMResponse.Content.Parts {
-					// if its running in streaming mode, don't print the non partial llmResponses
-					if streamingMode != agent.StreamingModeSSE || event.LLMResponse.Partial {
-						fmt.Print(p.Text)
-					}
+				// if its running in streaming mode, don't print the non partial llmResponses
+				if streamingMode != agent.StreamingModeSSE || event.LLMResponse.Partial {
+					fmt.Print(event.Text())
 				}
 			}
 		}
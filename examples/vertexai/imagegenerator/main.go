@@ -39,7 +39,7 @@ import (
 func main() {
 	ctx := context.Background()
 
-	model, err := gemini.NewModel(ctx, "gemini-2.0-flash-001", nil)
+	model, err := gemini.NewVertexModel(ctx, "gemini-2.0-flash-001", os.Getenv("GOOGLE_CLOUD_PROJECT"), os.Getenv("GOOGLE_CLOUD_LOCATION"))
 	if err != nil {
 		log.Fatalf("Failed to create model: %v", err)
 	}
@@ -24,21 +24,28 @@ import (
 type Map map[string]agent.Agent
 
 // New creates parent map allowing to fetch agent's parent.
-// It ensures that agent can have at most one parent.
-// It ensures that the root node name is not referenced again in the agent tree
+// It ensures that the agent tree has no cycles, that an agent has at most one
+// parent, and that the agent names are unique in the tree, including the name
+// of the root agent. The errors name the parents of the offending agents.
 func New(root agent.Agent) (Map, error) {
 	res := make(map[string]agent.Agent)
 	rootName := root.Name()
-	pointerMap := map[agent.Agent]string{root: "is root agent"}
+	pointerMap := map[agent.Agent]string{root: ""}
 
 	var f func(cur agent.Agent) error
 	f = func(cur agent.Agent) error {
 		for _, subAgent := range cur.SubAgents() {
+			if isAncestor(res, subAgent, cur) {
+				return fmt.Errorf("agent tree has a cycle: %q agent is a sub-agent of its descendant %q", subAgent.Name(), cur.Name())
+			}
 			if p, ok := pointerMap[subAgent]; ok {
 				return fmt.Errorf("%q agent cannot have >1 parents, found: %q, %q", subAgent.Name(), p, cur.Name())
 			}
-			if _, ok := res[subAgent.Name()]; ok || subAgent.Name() == rootName {
-				return fmt.Errorf("agent names must be unique in the agent tree, found duplicate: %q", subAgent.Name())
+			if subAgent.Name() == rootName {
+				return fmt.Errorf("agent names must be unique in the agent tree, found duplicate: %q, used by the root agent and a sub-agent of %q", subAgent.Name(), cur.Name())
+			}
+			if p, ok := res[subAgent.Name()]; ok {
+				return fmt.Errorf("agent names must be unique in the agent tree, found duplicate: %q, used by sub-agents of %q and %q", subAgent.Name(), p.Name(), cur.Name())
 			}
 			res[subAgent.Name()] = cur
 			pointerMap[subAgent] = cur.Name()
@@ -53,6 +60,18 @@ func New(root agent.Agent) (Map, error) {
 	return res, f(root)
 }
 
+// isAncestor reports whether a is cur or one of its ancestors in the parent
+// map built so far.
+func isAncestor(parents Map, a, cur agent.Agent) bool {
+	for cur != nil {
+		if cur == a {
+			return true
+		}
+		cur = parents[cur.Name()]
+	}
+	return false
+}
+
 // RootAgent returns the root of the agent tree.
 func (m Map) RootAgent(cur agent.Agent) agent.Agent {
 	if cur == nil {
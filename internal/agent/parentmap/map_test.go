@@ -71,6 +71,71 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_Errors(t *testing.T) {
+	newAgent := func(name string, subAgents ...agent.Agent) agent.Agent {
+		return utils.Must(agent.New(agent.Config{Name: name, SubAgents: subAgents}))
+	}
+	shared := newAgent("shared")
+	// a and b are each other's sub-agent.
+	a := &mutableAgent{Agent: newAgent("a")}
+	b := &mutableAgent{Agent: newAgent("b"), subAgents: []agent.Agent{a}}
+	a.subAgents = []agent.Agent{b}
+
+	tests := []struct {
+		name    string
+		root    agent.Agent
+		wantErr string
+	}{
+		{
+			name:    "duplicate name",
+			root:    newAgent("root", newAgent("parent1", newAgent("child")), newAgent("parent2", newAgent("child"))),
+			wantErr: `agent names must be unique in the agent tree, found duplicate: "child", used by sub-agents of "parent1" and "parent2"`,
+		},
+		{
+			name:    "duplicate root name",
+			root:    newAgent("root", newAgent("parent", newAgent("root"))),
+			wantErr: `agent names must be unique in the agent tree, found duplicate: "root", used by the root agent and a sub-agent of "parent"`,
+		},
+		{
+			name:    "multiple parents",
+			root:    newAgent("root", newAgent("parent1", shared), newAgent("parent2", shared)),
+			wantErr: `"shared" agent cannot have >1 parents, found: "parent1", "parent2"`,
+		},
+		{
+			name:    "cycle",
+			root:    newAgent("root", a),
+			wantErr: `agent tree has a cycle: "a" agent is a sub-agent of its descendant "b"`,
+		},
+		{
+			name:    "root in a cycle",
+			root:    b,
+			wantErr: `agent tree has a cycle: "b" agent is a sub-agent of its descendant "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parentmap.New(tt.root)
+			if err == nil {
+				t.Fatalf("New() succeeded, want error %q", tt.wantErr)
+			}
+			if got := err.Error(); got != tt.wantErr {
+				t.Errorf("New() error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+// mutableAgent is an agent whose sub-agents can be set after it's created,
+// which allows building cyclic agent trees.
+type mutableAgent struct {
+	agent.Agent
+	subAgents []agent.Agent
+}
+
+func (a *mutableAgent) SubAgents() []agent.Agent {
+	return a.subAgents
+}
+
 func TestMap_RootAgent(t *testing.T) {
 	model := struct {
 		model.LLM
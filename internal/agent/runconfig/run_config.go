@@ -14,7 +14,16 @@
 
 package runconfig
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
 
 type StreamingMode string
 
@@ -26,6 +35,75 @@ const (
 
 type RunConfig struct {
 	StreamingMode StreamingMode
+	// LLMCalls limits the number of LLM calls. It is shared by all the
+	// agents of the invocation, including agents run by nested runners.
+	LLMCalls *LLMCallsLimiter
+	// Usage aggregates the token usage of the invocation, including
+	// nested runners.
+	Usage *model.Usage
+	// MaxTotalTokens is the token budget of the invocation, 0 means no limit.
+	MaxTotalTokens int64
+	// MaxParallelToolCalls limits the function calls of a model response
+	// run concurrently, 0 means no limit.
+	MaxParallelToolCalls int
+	// BeforeAgentCallbacks and AfterAgentCallbacks are the agent hooks of the
+	// runner plugins, called before the callbacks of every agent. They take
+	// an agent.CallbackContext, which can't be referenced from this package.
+	BeforeAgentCallbacks []func(context.Context) (*genai.Content, error)
+	AfterAgentCallbacks  []func(context.Context) (*genai.Content, error)
+	// Logger is the logger of the runner, see Logger.
+	Logger *slog.Logger
+}
+
+// Logger returns the logger of the run, or slog.Default() outside of runs.
+func Logger(ctx context.Context) *slog.Logger {
+	if cfg := FromContext(ctx); cfg != nil && cfg.Logger != nil {
+		return cfg.Logger
+	}
+	return slog.Default()
+}
+
+// CheckTokenBudget returns an error wrapping model.ErrMaxTotalTokensExceeded
+// if the invocation has used more tokens than its budget.
+func (c *RunConfig) CheckTokenBudget() error {
+	if c == nil || c.MaxTotalTokens <= 0 {
+		return nil
+	}
+	if used := c.Usage.TotalTokens(); used > c.MaxTotalTokens {
+		return fmt.Errorf("%w: used %d tokens, limit is %d", model.ErrMaxTotalTokensExceeded, used, c.MaxTotalTokens)
+	}
+	return nil
+}
+
+// DefaultMaxLLMCalls is the number of LLM calls allowed per invocation
+// if agent.RunConfig.MaxLLMCalls is not set.
+const DefaultMaxLLMCalls = 500
+
+// LLMCallsLimiter counts LLM calls and reports when the limit is exceeded.
+type LLMCallsLimiter struct {
+	max   int64
+	calls atomic.Int64
+}
+
+// NewLLMCallsLimiter creates a limiter for maxCalls LLM calls.
+// Zero means DefaultMaxLLMCalls, a negative value disables the limit.
+func NewLLMCallsLimiter(maxCalls int) *LLMCallsLimiter {
+	if maxCalls == 0 {
+		maxCalls = DefaultMaxLLMCalls
+	}
+	return &LLMCallsLimiter{max: int64(maxCalls)}
+}
+
+// Increment registers a new LLM call. It returns an error wrapping
+// model.ErrLLMCallsLimitExceeded if the call would exceed the limit.
+func (l *LLMCallsLimiter) Increment() error {
+	if l == nil || l.max < 0 {
+		return nil
+	}
+	if l.calls.Add(1) > l.max {
+		return fmt.Errorf("%w: limit of %d calls per invocation", model.ErrLLMCallsLimitExceeded, l.max)
+	}
+	return nil
 }
 
 func ToContext(ctx context.Context, cfg *RunConfig) context.Context {
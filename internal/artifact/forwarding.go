@@ -0,0 +1,172 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+)
+
+// Unwrapper is implemented by the agent.Artifacts wrapping other artifacts,
+// e.g. the artifacts of a tool context.
+type Unwrapper interface {
+	Unwrap() agent.Artifacts
+}
+
+// NewForwardingService returns an artifact service forwarding to the
+// artifacts of a parent invocation, e.g. for the sub-agents run by agenttool.
+// The application, user and session of the requests are ignored: the
+// artifacts are the ones of the parent session, and the saves are recorded in
+// the artifact delta of the parent if it is a tool context. The file names
+// are prefixed with prefix in the parent session, after the "user:"
+// namespace if any, and List only returns the files with the prefix. It
+// returns false if the parent has no artifacts.
+func NewForwardingService(parent agent.Artifacts, prefix string) (*ForwardingService, bool) {
+	if unwrap(parent) == nil {
+		return nil, false
+	}
+	return &ForwardingService{parent: parent, prefix: prefix}, true
+}
+
+// unwrap returns the Artifacts at the bottom of the wrappers of a.
+func unwrap(a agent.Artifacts) agent.Artifacts {
+	for {
+		u, ok := a.(Unwrapper)
+		if !ok {
+			return a
+		}
+		a = u.Unwrap()
+	}
+}
+
+// ForwardingService is an artifact.Service forwarding to the artifacts of a
+// parent invocation, see NewForwardingService.
+type ForwardingService struct {
+	parent agent.Artifacts
+	prefix string
+
+	mu    sync.Mutex
+	saved []string
+}
+
+// SavedFileNames returns the names of the files saved through the service,
+// as seen by the parent, in the order of their first save.
+func (s *ForwardingService) SavedFileNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.saved)
+}
+
+func (s *ForwardingService) Save(ctx context.Context, req *artifact.SaveRequest) (*artifact.SaveResponse, error) {
+	name := s.parentName(req.FileName)
+	resp, err := s.parent.Save(ctx, name, req.Part)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if !slices.Contains(s.saved, name) {
+		s.saved = append(s.saved, name)
+	}
+	s.mu.Unlock()
+	return resp, nil
+}
+
+func (s *ForwardingService) Load(ctx context.Context, req *artifact.LoadRequest) (*artifact.LoadResponse, error) {
+	name := s.parentName(req.FileName)
+	if req.Version > 0 {
+		return s.parent.LoadVersion(ctx, name, int(req.Version))
+	}
+	return s.parent.Load(ctx, name)
+}
+
+func (s *ForwardingService) List(ctx context.Context, req *artifact.ListRequest) (*artifact.ListResponse, error) {
+	resp, err := s.parent.List(ctx)
+	if err != nil || s.prefix == "" {
+		return resp, err
+	}
+	var names []string
+	for _, name := range resp.FileNames {
+		if name, ok := s.childName(name); ok {
+			names = append(names, name)
+		}
+	}
+	return &artifact.ListResponse{FileNames: names}, nil
+}
+
+func (s *ForwardingService) Delete(ctx context.Context, req *artifact.DeleteRequest) error {
+	parent, err := s.parentSession()
+	if err != nil {
+		return err
+	}
+	return parent.Service.Delete(ctx, &artifact.DeleteRequest{
+		AppName:   parent.AppName,
+		UserID:    parent.UserID,
+		SessionID: parent.SessionID,
+		FileName:  s.parentName(req.FileName),
+		Version:   req.Version,
+	})
+}
+
+func (s *ForwardingService) Versions(ctx context.Context, req *artifact.VersionsRequest) (*artifact.VersionsResponse, error) {
+	parent, err := s.parentSession()
+	if err != nil {
+		return nil, err
+	}
+	return parent.Service.Versions(ctx, &artifact.VersionsRequest{
+		AppName:   parent.AppName,
+		UserID:    parent.UserID,
+		SessionID: parent.SessionID,
+		FileName:  s.parentName(req.FileName),
+	})
+}
+
+const userNamespace = "user:"
+
+// parentName returns the name of the file in the parent session.
+func (s *ForwardingService) parentName(name string) string {
+	if rest, ok := strings.CutPrefix(name, userNamespace); ok {
+		return userNamespace + s.prefix + rest
+	}
+	return s.prefix + name
+}
+
+// childName returns the name of a file of the parent session as seen through
+// the service, and false if it doesn't have the prefix.
+func (s *ForwardingService) childName(name string) (string, bool) {
+	namespace := ""
+	if rest, ok := strings.CutPrefix(name, userNamespace); ok {
+		namespace, name = userNamespace, rest
+	}
+	name, ok := strings.CutPrefix(name, s.prefix)
+	return namespace + name, ok
+}
+
+// parentSession returns the artifacts of the parent session, which
+// agent.Artifacts doesn't expose the deletions and versions of.
+func (s *ForwardingService) parentSession() (*Artifacts, error) {
+	parent, ok := unwrap(s.parent).(*Artifacts)
+	if !ok {
+		return nil, fmt.Errorf("the parent artifacts %T don't support deletions and versions", unwrap(s.parent))
+	}
+	return parent, nil
+}
+
+var _ artifact.Service = (*ForwardingService)(nil)
@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	artifactinternal "google.golang.org/adk/internal/artifact"
+)
+
+func TestForwardingService(t *testing.T) {
+	ctx := t.Context()
+	parent := &artifactinternal.Artifacts{
+		Service:   artifact.InMemoryService(),
+		AppName:   "parentApp",
+		UserID:    "parentUser",
+		SessionID: "parentSession",
+	}
+	s, ok := artifactinternal.NewForwardingService(parent, "")
+	if !ok {
+		t.Fatal("NewForwardingService() = false, want a service")
+	}
+
+	// The requests are for the sub-agent session, they are forwarded to the
+	// parent session.
+	for _, text := range []string{"v1", "v2"} {
+		if _, err := s.Save(ctx, &artifact.SaveRequest{AppName: "sub", UserID: "u", SessionID: "s", FileName: "f", Part: genai.NewPartFromText(text)}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if resp, err := parent.Load(ctx, "f"); err != nil || resp.Part.Text != "v2" {
+		t.Errorf("parent Load() = %v, %v, want the saved artifact", resp, err)
+	}
+	if resp, err := s.Load(ctx, &artifact.LoadRequest{AppName: "sub", UserID: "u", SessionID: "s", FileName: "f", Version: 1}); err != nil || resp.Part.Text != "v1" {
+		t.Errorf("Load() of version 1 = %v, %v, want v1", resp, err)
+	}
+	if resp, err := s.List(ctx, &artifact.ListRequest{AppName: "sub", UserID: "u", SessionID: "s"}); err != nil || !cmp.Equal(resp.FileNames, []string{"f"}) {
+		t.Errorf("List() = %v, %v, want [f]", resp, err)
+	}
+	if resp, err := s.Versions(ctx, &artifact.VersionsRequest{AppName: "sub", UserID: "u", SessionID: "s", FileName: "f"}); err != nil || len(resp.Versions) != 2 {
+		t.Errorf("Versions() = %v, %v, want 2 versions", resp, err)
+	}
+	if err := s.Delete(ctx, &artifact.DeleteRequest{AppName: "sub", UserID: "u", SessionID: "s", FileName: "f"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if resp, err := parent.List(ctx); err != nil || len(resp.FileNames) != 0 {
+		t.Errorf("parent List() after Delete() = %v, %v, want no artifacts", resp, err)
+	}
+}
+
+func TestForwardingService_Prefix(t *testing.T) {
+	ctx := t.Context()
+	parent := &artifactinternal.Artifacts{
+		Service:   artifact.InMemoryService(),
+		AppName:   "parentApp",
+		UserID:    "parentUser",
+		SessionID: "parentSession",
+	}
+	if _, err := parent.Save(ctx, "other.txt", genai.NewPartFromText("other")); err != nil {
+		t.Fatal(err)
+	}
+	s, ok := artifactinternal.NewForwardingService(parent, "sub/")
+	if !ok {
+		t.Fatal("NewForwardingService() = false, want a service")
+	}
+
+	for _, name := range []string{"report.txt", "user:notes.txt", "report.txt"} {
+		if _, err := s.Save(ctx, &artifact.SaveRequest{FileName: name, Part: genai.NewPartFromText(name)}); err != nil {
+			t.Fatalf("Save(%q) error = %v", name, err)
+		}
+	}
+	if resp, err := parent.Load(ctx, "sub/report.txt"); err != nil || resp.Part.Text != "report.txt" {
+		t.Errorf("parent Load() = %v, %v, want the saved artifact", resp, err)
+	}
+	if resp, err := parent.Load(ctx, "user:sub/notes.txt"); err != nil || resp.Part.Text != "user:notes.txt" {
+		t.Errorf("parent Load() of the user artifact = %v, %v, want the saved artifact", resp, err)
+	}
+	if resp, err := s.Load(ctx, &artifact.LoadRequest{FileName: "report.txt"}); err != nil || resp.Part.Text != "report.txt" {
+		t.Errorf("Load() = %v, %v, want the saved artifact", resp, err)
+	}
+	resp, err := s.List(ctx, &artifact.ListRequest{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"report.txt", "user:notes.txt"}, resp.FileNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"sub/report.txt", "user:sub/notes.txt"}, s.SavedFileNames()); diff != "" {
+		t.Errorf("SavedFileNames() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestForwardingService_NoParentArtifacts(t *testing.T) {
+	if _, ok := artifactinternal.NewForwardingService(nil, ""); ok {
+		t.Error("NewForwardingService(nil) = true, want false")
+	}
+}
@@ -22,6 +22,8 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
 
@@ -92,6 +94,13 @@ func (c *callbackContext) State() session.State {
 	return &callbackContextState{ctx: c}
 }
 
+func (c *callbackContext) Usage() *model.Usage {
+	if cfg := runconfig.FromContext(c); cfg != nil {
+		return cfg.Usage
+	}
+	return nil
+}
+
 func (c *callbackContext) InvocationID() string {
 	return c.invocationCtx.InvocationID()
 }
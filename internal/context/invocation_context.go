@@ -16,6 +16,7 @@ package context
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/genai"
@@ -35,13 +36,19 @@ type InvocationContextParams struct {
 	UserContent   *genai.Content
 	RunConfig     *agent.RunConfig
 	EndInvocation bool
+	// InvocationID is generated if empty.
+	InvocationID string
 }
 
 func NewInvocationContext(ctx context.Context, params InvocationContextParams) agent.InvocationContext {
+	invocationID := params.InvocationID
+	if invocationID == "" {
+		invocationID = "e-" + uuid.NewString()
+	}
 	return &InvocationContext{
 		Context:      ctx,
 		params:       params,
-		invocationID: "e-" + uuid.NewString(),
+		invocationID: invocationID,
 	}
 }
 
@@ -91,3 +98,31 @@ func (c *InvocationContext) EndInvocation() {
 func (c *InvocationContext) Ended() bool {
 	return c.params.EndInvocation
 }
+
+// WithContext returns the invocation context using ctx, e.g. carrying a
+// tracing span, as its context.Context. Other values are taken from ictx.
+func WithContext(ictx agent.InvocationContext, ctx context.Context) agent.InvocationContext {
+	return &contextOverride{InvocationContext: ictx, ctx: ctx}
+}
+
+type contextOverride struct {
+	agent.InvocationContext
+
+	ctx context.Context
+}
+
+func (c *contextOverride) Deadline() (time.Time, bool) {
+	return c.ctx.Deadline()
+}
+
+func (c *contextOverride) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+func (c *contextOverride) Err() error {
+	return c.ctx.Err()
+}
+
+func (c *contextOverride) Value(key any) any {
+	return c.ctx.Value(key)
+}
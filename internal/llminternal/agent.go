@@ -15,6 +15,8 @@
 package llminternal
 
 import (
+	"context"
+
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
@@ -32,10 +34,16 @@ type State struct {
 
 	Tools    []tool.Tool
 	Toolsets []tool.Toolset
+	// ToolOverrides maps tool names to overridden descriptions.
+	ToolOverrides map[string]string
+	// ToolChoice is the function calling mode of the model, unset if empty.
+	ToolChoice           genai.FunctionCallingConfigMode
+	AllowedFunctionNames []string
 
 	IncludeContents string
 
 	GenerateContentConfig *genai.GenerateContentConfig
+	SafetySettings        []*genai.SafetySetting
 
 	Instruction               string
 	InstructionProvider       InstructionProvider
@@ -47,8 +55,34 @@ type State struct {
 
 	InputSchema  *genai.Schema
 	OutputSchema *genai.Schema
+	OutputJSON   bool
 
 	OutputKey string
+
+	ContextCompression *ContextCompression
+	HistoryTrimming    *HistoryTrimming
+}
+
+// ContextCompression configures summarization of older conversation history.
+type ContextCompression struct {
+	Compactor       HistoryCompactor
+	Summarizer      model.LLM
+	MaxTokens       int
+	MaxEvents       int
+	KeepRecentTurns int
+}
+
+// HistoryCompactor summarizes the older conversation history.
+type HistoryCompactor interface {
+	Summarize(ctx context.Context, contents []*genai.Content) (string, error)
+}
+
+// HistoryTrimming limits the conversation history sent to the model.
+type HistoryTrimming struct {
+	MaxTokens      int
+	MaxEvents      int
+	TokenCounter   model.TokenCounter
+	KeepWholeTurns bool
 }
 
 type InstructionProvider func(ctx agent.ReadonlyContext) (string, error)
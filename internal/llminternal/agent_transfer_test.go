@@ -409,8 +409,11 @@ func TestAgentTransferRequestProcessor(t *testing.T) {
 func TestAgentTransfer_ProcessRequest(t *testing.T) {
 	// First Tool
 	var req model.LLMRequest
-	handler := func(ctx tool.Context, x int) (int, error) {
-		return x, nil
+	type Args struct {
+		X int `json:"x"`
+	}
+	handler := func(ctx tool.Context, args Args) (int, error) {
+		return args.X, nil
 	}
 	identityTool, err := functiontool.New(functiontool.Config{
 		Name:        "identity",
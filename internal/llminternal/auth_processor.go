@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/internal/converters"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+// generateAuthEvent returns the event requesting from the client the
+// credentials requested by the tools in the function response event, or nil
+// if no credential was requested. The event has an adk_request_credential
+// function call per tool call, marked as long running, so it ends the
+// invocation.
+func generateAuthEvent(ctx agent.InvocationContext, fnResponseEvent *session.Event) (*session.Event, error) {
+	configs := fnResponseEvent.Actions.RequestedAuthConfigs
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	content := &genai.Content{Role: genai.RoleModel}
+	for _, functionCallID := range slices.Sorted(maps.Keys(configs)) {
+		args, err := converters.ToMapStructure(auth.RequestCredentialArgs{
+			FunctionCallID: functionCallID,
+			Config:         configs[functionCallID],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the %s arguments: %w", auth.RequestCredentialFunctionName, err)
+		}
+		content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			Name: auth.RequestCredentialFunctionName,
+			Args: args,
+		}})
+	}
+	utils.PopulateClientFunctionCallID(content)
+	ev.LLMResponse = model.LLMResponse{Content: content}
+	for _, fc := range utils.FunctionCalls(content) {
+		ev.LongRunningToolIDs = append(ev.LongRunningToolIDs, fc.ID)
+	}
+	return ev, nil
+}
+
+// authPreprocess handles the credentials which the client provided in
+// response to the adk_request_credential function calls, see
+// generateAuthEvent. It stores the credentials in the session state, where
+// tool.Context.Credential finds them, and calls again the tools which
+// requested them. It returns the event with the function responses of these
+// tools, or nil if the last event of the session provides no credentials.
+//
+// Unlike the request processors it produces an event, so it runs before
+// them, and the request contents include the function responses.
+func (f *Flow) authPreprocess(ctx agent.InvocationContext) (*session.Event, error) {
+	// reference: adk-python src/google/adk/auth/auth_preprocessor.py
+
+	events := ctx.Session().Events()
+	if events.Len() == 0 {
+		return nil, nil
+	}
+	lastEvent := events.At(events.Len() - 1)
+	if lastEvent.Author != "user" {
+		return nil, nil
+	}
+	responses := make(map[string]*genai.FunctionResponse)
+	for _, fr := range lastEvent.FunctionResponses() {
+		if fr.Name == auth.RequestCredentialFunctionName {
+			responses[fr.ID] = fr
+		}
+	}
+	if len(responses) == 0 {
+		return nil, nil
+	}
+
+	// Find the requests answered by the client, to know which tool calls
+	// requested the credentials. The credentials are stored with the keys of
+	// the requested configs, the client can't change them.
+	toolCallIDs := make(map[string]bool)
+	for i := events.Len() - 2; i >= 0 && len(toolCallIDs) < len(responses); i-- {
+		for _, fc := range events.At(i).FunctionCalls() {
+			resp, ok := responses[fc.ID]
+			if !ok || fc.Name != auth.RequestCredentialFunctionName {
+				continue
+			}
+			var args auth.RequestCredentialArgs
+			if err := fromMap(fc.Args, &args); err != nil || args.Config == nil {
+				return nil, fmt.Errorf("invalid %s function call %q: %v", auth.RequestCredentialFunctionName, fc.ID, err)
+			}
+			var provided auth.Config
+			if err := fromMap(resp.Response, &provided); err != nil {
+				return nil, fmt.Errorf("invalid %s function response %q: %w", auth.RequestCredentialFunctionName, fc.ID, err)
+			}
+			cfg := *args.Config
+			cfg.Credential = provided.Credential
+			if err := cfg.ValidateCredential(); err != nil {
+				return nil, fmt.Errorf("invalid credential in the %s function response %q: %w", auth.RequestCredentialFunctionName, fc.ID, err)
+			}
+			if err := ctx.Session().State().Set(toolinternal.CredentialStateKey(&cfg), cfg.Credential); err != nil {
+				return nil, fmt.Errorf("failed to store the credential: %w", err)
+			}
+			toolCallIDs[args.FunctionCallID] = true
+		}
+	}
+	if len(toolCallIDs) < len(responses) {
+		return nil, fmt.Errorf("no %s function call found for some of the function responses", auth.RequestCredentialFunctionName)
+	}
+
+	// Find the tool calls and call the tools again.
+	content := &genai.Content{Role: genai.RoleModel}
+	for i := events.Len() - 2; i >= 0 && len(content.Parts) < len(toolCallIDs); i-- {
+		for _, fc := range events.At(i).FunctionCalls() {
+			if toolCallIDs[fc.ID] {
+				content.Parts = append(content.Parts, &genai.Part{FunctionCall: fc})
+			}
+		}
+	}
+	if len(content.Parts) == 0 {
+		return nil, nil
+	}
+
+	llmAgent, ok := ctx.Agent().(Agent)
+	if !ok {
+		return nil, fmt.Errorf("agent %v is not an LLMAgent", ctx.Agent().Name())
+	}
+	tools, err := agentTools(ctx, llmAgent)
+	if err != nil {
+		return nil, err
+	}
+	toolsDict := make(map[string]tool.Tool)
+	for _, t := range tools {
+		toolsDict[t.Name()] = t
+	}
+	return f.handleFunctionCalls(ctx, toolsDict, &model.LLMResponse{Content: content}, nil)
+}
+
+// fromMap converts the function call arguments or response m to v.
+func fromMap(m map[string]any, v any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
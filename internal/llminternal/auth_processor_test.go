@@ -0,0 +1,180 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/internal/converters"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func TestAuthFlow(t *testing.T) {
+	authConfig := &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "header", Name: "X-API-Key"}}
+
+	type Args struct {
+		City string `json:"city"`
+	}
+
+	newRunner := func(t *testing.T) (*testutil.TestAgentRunner, *testutil.MockModel, *[]string) {
+		t.Helper()
+		var gotKeys []string
+		weatherTool, err := functiontool.New(functiontool.Config{
+			Name:        "get_weather",
+			Description: "returns the weather in a city",
+		}, func(ctx tool.Context, args Args) (map[string]any, error) {
+			cred := ctx.Credential(authConfig)
+			if cred == nil {
+				ctx.RequestCredential(authConfig)
+				return map[string]any{"status": "pending authorization"}, nil
+			}
+			gotKeys = append(gotKeys, cred.APIKey)
+			return map[string]any{"weather": "sunny in " + args.City}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		llm := &testutil.MockModel{Responses: []*genai.Content{
+			{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "london"}}}}},
+			genai.NewContentFromText("It's sunny in london.", genai.RoleModel),
+		}}
+		a, err := llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Tools: []tool.Tool{weatherTool}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return testutil.NewTestAgentRunner(t, a), llm, &gotKeys
+	}
+
+	// requestCredential runs the agent until it requests the credential, and
+	// returns the adk_request_credential function call.
+	requestCredential := func(t *testing.T, runner *testutil.TestAgentRunner) (*genai.FunctionCall, string) {
+		t.Helper()
+		events, err := testutil.CollectEvents(runner.Run(t, "session1", "what is the weather in london?"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("got %d events, want the function call, the function response and the credential request", len(events))
+		}
+		toolCallID := events[0].FunctionCalls()[0].ID
+		if got := events[1].Actions.RequestedAuthConfigs; !cmp.Equal(got, map[string]*auth.Config{toolCallID: authConfig}) {
+			t.Errorf("RequestedAuthConfigs = %v, want the config for %q", got, toolCallID)
+		}
+
+		authEvent := events[2]
+		calls := authEvent.FunctionCalls()
+		if len(calls) != 1 || calls[0].Name != auth.RequestCredentialFunctionName {
+			t.Fatalf("last event function calls = %v, want a %s function call", calls, auth.RequestCredentialFunctionName)
+		}
+		if diff := cmp.Diff([]string{calls[0].ID}, authEvent.LongRunningToolIDs); diff != "" {
+			t.Errorf("LongRunningToolIDs mismatch (-want +got):\n%s", diff)
+		}
+		wantArgs := map[string]any{
+			"functionCallId": toolCallID,
+			"authConfig": map[string]any{
+				"authScheme": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		}
+		if diff := cmp.Diff(wantArgs, calls[0].Args); diff != "" {
+			t.Errorf("%s arguments mismatch (-want +got):\n%s", auth.RequestCredentialFunctionName, diff)
+		}
+		return calls[0], toolCallID
+	}
+
+	credentialResponse := func(t *testing.T, call *genai.FunctionCall, cred *auth.Credential) *genai.Content {
+		t.Helper()
+		response, err := converters.ToMapStructure(&auth.Config{Scheme: authConfig.Scheme, Credential: cred})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &genai.Content{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:       call.ID,
+			Name:     auth.RequestCredentialFunctionName,
+			Response: response,
+		}}}}
+	}
+
+	t.Run("credential provided", func(t *testing.T) {
+		runner, llm, gotKeys := newRunner(t)
+		call, toolCallID := requestCredential(t, runner)
+
+		events, err := testutil.CollectEvents(runner.RunContent(t, "session1", credentialResponse(t, call, &auth.Credential{
+			Type:   auth.CredentialTypeAPIKey,
+			APIKey: "secret",
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"secret"}, *gotKeys); diff != "" {
+			t.Errorf("tool credentials mismatch (-want +got):\n%s", diff)
+		}
+		want := []*genai.Content{
+			{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+				ID:       toolCallID,
+				Name:     "get_weather",
+				Response: map[string]any{"weather": "sunny in london"},
+			}}}},
+			genai.NewContentFromText("It's sunny in london.", genai.RoleModel),
+		}
+		var got []*genai.Content
+		for _, ev := range events {
+			got = append(got, ev.Content)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("events mismatch (-want +got):\n%s", diff)
+		}
+
+		// The model gets the response of the resumed tool call, without the
+		// credential request.
+		wantContents := []*genai.Content{
+			genai.NewContentFromText("what is the weather in london?", genai.RoleUser),
+			{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "london"}}}}},
+			{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+				Name:     "get_weather",
+				Response: map[string]any{"weather": "sunny in london"},
+			}}}},
+		}
+		if len(llm.Requests) != 2 {
+			t.Fatalf("got %d model requests, want 2", len(llm.Requests))
+		}
+		if diff := cmp.Diff(wantContents, llm.Requests[1].Contents); diff != "" {
+			t.Errorf("model request contents mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid credential", func(t *testing.T) {
+		runner, _, gotKeys := newRunner(t)
+		call, _ := requestCredential(t, runner)
+
+		_, err := testutil.CollectEvents(runner.RunContent(t, "session1", credentialResponse(t, call, &auth.Credential{
+			Type: auth.CredentialTypeAPIKey,
+		})))
+		if err == nil || !strings.Contains(err.Error(), "API key is not set") {
+			t.Errorf("Run() error = %v, want an invalid credential error", err)
+		}
+		if len(*gotKeys) != 0 {
+			t.Errorf("tool called with credentials %v, want none", *gotKeys)
+		}
+	})
+}
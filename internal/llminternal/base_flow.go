@@ -17,12 +17,17 @@ package llminternal
 import (
 	"fmt"
 	"iter"
+	"log/slog"
 	"maps"
 	"slices"
+	"sync"
+	"time"
 
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
 	icontext "google.golang.org/adk/internal/context"
@@ -56,7 +61,8 @@ type Flow struct {
 var (
 	DefaultRequestProcessors = []func(ctx agent.InvocationContext, req *model.LLMRequest) error{
 		basicRequestProcessor,
-		authPreprocessor,
+		// The credentials requested by the tools are handled by Flow.authPreprocess,
+		// which runs before the request processors.
 		instructionsRequestProcessor,
 		identityRequestProcessor,
 		ContentsRequestProcessor,
@@ -78,6 +84,11 @@ var (
 func (f *Flow) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
 		for {
+			// Stop between the steps once the invocation is canceled.
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
 			var lastEvent *session.Event
 			for ev, err := range f.runOneStep(ctx) {
 				if err != nil {
@@ -107,6 +118,42 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 	return func(yield func(*session.Event, error) bool) {
 		req := &model.LLMRequest{}
 
+		// Summarize the older history if it grew too long. The summary event
+		// is stored in the session before the contents are built.
+		compactionEvent, err := compactHistory(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if compactionEvent != nil {
+			if !yield(compactionEvent, nil) {
+				return
+			}
+		}
+
+		// Call again the tools which waited for the credentials provided by
+		// the client. Their function responses are stored in the session
+		// before the contents are built.
+		resumedEvent, err := f.authPreprocess(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if resumedEvent != nil {
+			if !yield(resumedEvent, nil) {
+				return
+			}
+			authEvent, err := generateAuthEvent(ctx, resumedEvent)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if authEvent != nil {
+				yield(authEvent, nil)
+				return
+			}
+		}
+
 		// Preprocess before calling the LLM.
 		if err := f.preprocess(ctx, req); err != nil {
 			yield(nil, err)
@@ -115,12 +162,17 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 		if ctx.Ended() {
 			return
 		}
-		spans := telemetry.StartTrace(ctx, "call_llm")
+		spanCtx, spans := telemetry.StartTrace(ctx, "call_llm")
+		var spanErr error
+		defer func() { telemetry.EndTrace(spans, spanErr) }()
+		// The model and tool calls are traced as children of the call_llm span.
+		ctx := icontext.WithContext(ctx, spanCtx)
 		// Create event to pass to callback state delta
 		stateDelta := make(map[string]any)
 		// Calls the LLM.
 		for resp, err := range f.callLLM(ctx, req, stateDelta) {
 			if err != nil {
+				spanErr = err
 				yield(nil, err)
 				return
 			}
@@ -149,15 +201,35 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 
 			// Build the event and yield.
 			modelResponseEvent := f.finalizeModelResponseEvent(ctx, resp, tools, stateDelta)
-			telemetry.TraceLLMCall(spans, ctx, req, modelResponseEvent)
+			telemetry.TraceLLMCall(spans, ctx.Session().ID(), f.modelName(req), req, modelResponseEvent)
+			runCfg := runconfig.FromContext(ctx)
+			if !resp.Partial {
+				telemetry.RecordModelUsage(ctx, resp.UsageMetadata)
+				if runCfg != nil && runCfg.Usage != nil {
+					runCfg.Usage.Add(f.modelName(req), resp.UsageMetadata)
+					if modelResponseEvent.IsFinalResponse() {
+						modelResponseEvent.InvocationUsage = runCfg.Usage.Metadata()
+					}
+				}
+			}
 			if !yield(modelResponseEvent, nil) {
 				return
 			}
-			// TODO: generate and yield an auth event if needed.
-
+			if err := runCfg.CheckTokenBudget(); err != nil {
+				yield(nil, err)
+				return
+			}
 			// Handle function calls.
 
-			ev, err := f.handleFunctionCalls(ctx, tools, resp)
+			// The events emitted by the tools are yielded while they run.
+			stopped := false
+			ev, err := f.handleFunctionCalls(ctx, tools, resp, func(ev *session.Event) bool {
+				stopped = stopped || !yield(ev, nil)
+				return !stopped
+			})
+			if stopped {
+				return
+			}
 			if err != nil {
 				yield(nil, err)
 				return
@@ -169,6 +241,17 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 			if !yield(ev, nil) {
 				return
 			}
+			// End the invocation with a request to the client if the tools
+			// need credentials.
+			authEvent, err := generateAuthEvent(ctx, ev)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if authEvent != nil {
+				yield(authEvent, nil)
+				return
+			}
 
 			// Actually handle "transfer_to_agent" tool. The function call sets the ev.Actions.TransferToAgent field.
 			// We are following python's execution flow which is
@@ -183,6 +266,12 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 				yield(nil, fmt.Errorf("failed to find agent: %s", ev.Actions.TransferToAgent))
 				return
 			}
+			telemetry.RecordAgentTransfer(ctx, ctx.Agent().Name(), nextAgent.Name())
+			runconfig.Logger(ctx).LogAttrs(ctx, slog.LevelDebug, "Transferring to agent",
+				slog.String("invocation_id", ctx.InvocationID()),
+				slog.String("agent", ctx.Agent().Name()),
+				slog.String("to_agent", nextAgent.Name()),
+			)
 			for ev, err := range nextAgent.Run(ctx) {
 				if !yield(ev, err) || err != nil { // forward
 					return
@@ -206,17 +295,57 @@ func (f *Flow) preprocess(ctx agent.InvocationContext, req *model.LLMRequest) er
 	}
 
 	// run processors for tools.
-	tools := Reveal(llmAgent).Tools
+	tools, err := agentTools(ctx, llmAgent)
+	if err != nil {
+		return err
+	}
+
+	if err := toolPreprocess(ctx, req, tools); err != nil {
+		return err
+	}
+	overrideToolDescriptions(req, Reveal(llmAgent).ToolOverrides)
+	return nil
+}
+
+// agentTools returns the tools of the agent, including the tools of its tool sets.
+func agentTools(ctx agent.InvocationContext, llmAgent Agent) ([]tool.Tool, error) {
+	tools := slices.Clone(Reveal(llmAgent).Tools)
 	for _, toolSet := range Reveal(llmAgent).Toolsets {
 		tsTools, err := toolSet.Tools(icontext.NewReadonlyContext(ctx))
 		if err != nil {
-			return fmt.Errorf("failed to extract tools from the tool set %q: %w", toolSet.Name(), err)
+			return nil, fmt.Errorf("failed to extract tools from the tool set %q: %w", toolSet.Name(), err)
 		}
 
 		tools = append(tools, tsTools...)
 	}
+	return tools, nil
+}
 
-	return toolPreprocess(ctx, req, tools)
+// overrideToolDescriptions replaces the descriptions of the function
+// declarations in the request according to the agent's overrides.
+func overrideToolDescriptions(req *model.LLMRequest, overrides map[string]string) {
+	if len(overrides) == 0 || req.Config == nil {
+		return
+	}
+	for _, t := range req.Config.Tools {
+		if t == nil {
+			continue
+		}
+		for i, decl := range t.FunctionDeclarations {
+			if decl == nil {
+				continue
+			}
+			description, ok := overrides[decl.Name]
+			if !ok {
+				continue
+			}
+			// Copy the declaration, tools may return the same instance
+			// to all the agents that use them.
+			overridden := *decl
+			overridden.Description = description
+			t.FunctionDeclarations[i] = &overridden
+		}
+	}
 }
 
 // toolPreprocess runs tool preprocess on the given request
@@ -239,7 +368,7 @@ func toolPreprocess(ctx agent.InvocationContext, req *model.LLMRequest, tools []
 
 func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
-		for _, callback := range f.BeforeModelCallbacks {
+		for _, callback := range f.beforeModelCallbacks(ctx) {
 			cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
 			callbackResponse, callbackErr := callback(cctx, req)
 
@@ -254,13 +383,35 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 			return
 		}
 
+		runCfg := runconfig.FromContext(ctx)
+		if err := runCfg.CheckTokenBudget(); err != nil {
+			yield(nil, err)
+			return
+		}
+		if err := runCfg.LLMCalls.Increment(); err != nil {
+			yield(nil, err)
+			return
+		}
+
 		// TODO: Set _ADK_AGENT_NAME_LABEL_KEY in req.GenerateConfig.Labels
 		// to help with slicing the billing reports on a per-agent basis.
 
 		// TODO: RunLive mode when invocation_context.run_config.support_cfc is true.
 		useStream := runconfig.FromContext(ctx).StreamingMode == runconfig.StreamingModeSSE
 
+		// The duration excludes the time the caller spends on the yielded
+		// responses, e.g. running the tools.
+		start := time.Now()
+		var duration time.Duration
+		var modelErr error
+		defer func() {
+			telemetry.RecordModelRequest(ctx, f.modelName(req), duration, modelErr != nil)
+		}()
 		for resp, err := range f.Model.GenerateContent(ctx, req, useStream) {
+			duration = time.Since(start)
+			if err != nil {
+				modelErr = err
+			}
 			callbackResp, callbackErr := f.runAfterModelCallbacks(ctx, resp, stateDelta, err)
 			// TODO: check if we should stop iterator on the first error from stream or continue yielding next results.
 			if callbackErr != nil {
@@ -288,8 +439,19 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 	}
 }
 
+// modelName returns the name of the model used for the request.
+func (f *Flow) modelName(req *model.LLMRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	if f.Model != nil {
+		return f.Model.Name()
+	}
+	return ""
+}
+
 func (f *Flow) runAfterModelCallbacks(ctx agent.InvocationContext, llmResp *model.LLMResponse, stateDelta map[string]any, llmErr error) (*model.LLMResponse, error) {
-	for _, callback := range f.AfterModelCallbacks {
+	for _, callback := range f.afterModelCallbacks(ctx) {
 		cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
 		callbackResponse, callbackErr := callback(cctx, llmResp, llmErr)
 
@@ -359,14 +521,15 @@ func findLongRunningFunctionCallIDs(c *genai.Content, tools map[string]tool.Tool
 }
 
 // handleFunctionCalls calls the functions and returns the function response event.
+// The events emitted by the tools, see toolinternal.EmitEvent, are passed to
+// emit, if not nil, from the calling goroutine; once emit returns false, the
+// next events are dropped.
 //
 // TODO: accept filters to include/exclude function calls.
-// TODO: check feasibility of running tool.Run concurrently.
-func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse) (*session.Event, error) {
-	var fnResponseEvents []*session.Event
-
+func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse, emit func(*session.Event) bool) (*session.Event, error) {
 	fnCalls := utils.FunctionCalls(resp.Content)
-	for _, fnCall := range fnCalls {
+	funcTools := make([]toolinternal.FunctionTool, len(fnCalls))
+	for i, fnCall := range fnCalls {
 		curTool, ok := toolsDict[fnCall.Name]
 		if !ok {
 			return nil, fmt.Errorf("unknown tool: %q", fnCall.Name)
@@ -375,70 +538,150 @@ func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[st
 		if !ok {
 			return nil, fmt.Errorf("tool %q is not a function tool", curTool.Name())
 		}
-		toolCtx := toolinternal.NewToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)})
-		// toolCtx := tool.
-		spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
-
-		result := f.callTool(funcTool, fnCall.Args, toolCtx)
-
-		// TODO: agent.canonical_after_tool_callbacks
-		// TODO: handle long-running tool.
-		ev := session.NewEvent(ctx.InvocationID())
-		ev.LLMResponse = model.LLMResponse{
-			Content: &genai.Content{
-				Role: "user",
-				Parts: []*genai.Part{
-					{
-						FunctionResponse: &genai.FunctionResponse{
-							ID:       fnCall.ID,
-							Name:     fnCall.Name,
-							Response: result,
-						},
-					},
-				},
-			},
+		funcTools[i] = funcTool
+	}
+
+	// The calls of a response are independent, they run concurrently. Every
+	// call records its state changes in its own EventActions, and the
+	// responses keep the order of the calls. The tool callbacks, including
+	// the plugin ones, are called one at a time, so that they don't need to
+	// synchronize their state.
+	fnResponseEvents := make([]*session.Event, len(fnCalls))
+	var callbacksMu sync.Mutex
+	var group errgroup.Group
+	if cfg := runconfig.FromContext(ctx); cfg != nil && cfg.MaxParallelToolCalls > 0 {
+		group.SetLimit(cfg.MaxParallelToolCalls)
+	}
+	// The tools send their events to the calling goroutine, waiting until
+	// they are passed to emit, unless emit stopped accepting them.
+	events := make(chan *session.Event)
+	stop := make(chan struct{})
+	toolEmit := func(ev *session.Event) {
+		select {
+		case events <- ev:
+		case <-stop:
 		}
-		ev.Author = ctx.Agent().Name()
-		ev.Branch = ctx.Branch()
-		ev.Actions = *toolCtx.Actions()
-		telemetry.TraceToolCall(spans, curTool, fnCall.Args, ev)
-		fnResponseEvents = append(fnResponseEvents, ev)
 	}
+	if emit == nil {
+		toolEmit = nil
+	}
+	done := make(chan struct{})
+	go func() {
+		for i, fnCall := range fnCalls {
+			group.Go(func() error {
+				fnResponseEvents[i] = f.runFunctionCall(ctx, funcTools[i], fnCall, toolEmit, &callbacksMu)
+				return nil
+			})
+		}
+		_ = group.Wait()
+		close(done)
+	}()
+	for running, stopped := true, false; running; {
+		select {
+		case ev := <-events:
+			if !stopped && !emit(ev) {
+				stopped = true
+				close(stop)
+			}
+		case <-done:
+			running = false
+		}
+	}
+
 	mergedEvent, err := mergeParallelFunctionResponseEvents(fnResponseEvents)
 	if err != nil {
 		return mergedEvent, err
 	}
 	// this is needed for debug traces of parallel calls
-	spans := telemetry.StartTrace(ctx, "execute_tool (merged)")
+	_, spans := telemetry.StartTrace(ctx, "execute_tool (merged)")
 	telemetry.TraceMergedToolCalls(spans, mergedEvent)
 	return mergedEvent, nil
 }
 
-func (f *Flow) callTool(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context) map[string]any {
+// runFunctionCall calls the tool and returns the event with its function
+// response. The tool callbacks are called while holding callbacksMu.
+func (f *Flow) runFunctionCall(ctx agent.InvocationContext, funcTool toolinternal.FunctionTool, fnCall *genai.FunctionCall, emit func(*session.Event), callbacksMu *sync.Mutex) *session.Event {
+	spanCtx, spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
+	toolCtx := toolinternal.NewToolContext(icontext.WithContext(ctx, spanCtx), fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)})
+	if emit != nil {
+		toolCtx = toolinternal.WithEventEmitter(toolCtx, emit)
+	}
+
+	result := f.callTool(funcTool, fnCall.Args, toolCtx, callbacksMu)
+	_, failed := result["error"]
+	telemetry.RecordToolCall(ctx, fnCall.Name, failed)
+
+	// TODO: agent.canonical_after_tool_callbacks
+	// TODO: handle long-running tool.
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.LLMResponse = model.LLMResponse{
+		Content: &genai.Content{
+			Role: "user",
+			Parts: []*genai.Part{
+				{
+					FunctionResponse: &genai.FunctionResponse{
+						ID:       fnCall.ID,
+						Name:     fnCall.Name,
+						Response: result,
+					},
+				},
+			},
+		},
+	}
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Actions = *toolCtx.Actions()
+	if failed {
+		runconfig.Logger(ctx).LogAttrs(ctx, slog.LevelWarn, "Tool call failed",
+			slog.String("invocation_id", ctx.InvocationID()),
+			slog.String("agent", ctx.Agent().Name()),
+			slog.String("event_id", ev.ID),
+			slog.String("tool", fnCall.Name),
+			slog.Any("error", result["error"]),
+		)
+	}
+	telemetry.TraceToolCall(spans, funcTool.Name(), funcTool.Description(), fnCall.Args, ev)
+	return ev
+}
+
+func (f *Flow) callTool(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context, callbacksMu *sync.Mutex) map[string]any {
 	// If the result is present, it will be used instead of calling the actual tool.
+	callbacksMu.Lock()
 	result, err := f.invokeBeforeToolCallbacks(tool, fArgs, toolCtx)
+	callbacksMu.Unlock()
 	if err != nil {
-		return map[string]any{"error": fmt.Errorf("BeforeToolCallback failed: %w", err)}
+		return toolErrorResponse(fmt.Errorf("BeforeToolCallback failed: %w", err))
 	}
 	if result == nil {
 		result, err = tool.Run(toolCtx, fArgs)
-		if err != nil {
-			return map[string]any{"error": fmt.Errorf("tool %q failed: %w", tool.Name(), err)}
-		}
 	}
-	afterToolCallbackResult, err := f.invokeAfterToolCallbacks(tool, fArgs, toolCtx, result, err)
-	if err != nil {
-		return map[string]any{"error": fmt.Errorf("AfterToolCallback failed: %w", err)}
+	// The callbacks are called also when the tool failed, so that they can observe or replace the error.
+	callbacksMu.Lock()
+	afterToolCallbackResult, callbackErr := f.invokeAfterToolCallbacks(tool, fArgs, toolCtx, result, err)
+	callbacksMu.Unlock()
+	if callbackErr != nil {
+		return toolErrorResponse(fmt.Errorf("AfterToolCallback failed: %w", callbackErr))
 	}
 	// If the result is present, it will replace the result returned by the tool's Run method.
 	if afterToolCallbackResult != nil {
 		return afterToolCallbackResult
 	}
+	if err != nil {
+		return toolErrorResponse(fmt.Errorf("tool %q failed: %w", tool.Name(), err))
+	}
 	return result
 }
 
+// toolErrorResponse renders a tool error as the function response sent to
+// the model, so that it can react to the failure, e.g. retry with other
+// arguments. The error message is used, as error values don't serialize
+// to JSON.
+func toolErrorResponse(err error) map[string]any {
+	return map[string]any{"error": err.Error()}
+}
+
 func (f *Flow) invokeBeforeToolCallbacks(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context) (map[string]any, error) {
-	for _, callback := range f.BeforeToolCallbacks {
+	for _, callback := range f.beforeToolCallbacks(toolCtx) {
 		result, err := callback(toolCtx, tool, fArgs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute callback: %w", err)
@@ -453,7 +696,7 @@ func (f *Flow) invokeBeforeToolCallbacks(tool toolinternal.FunctionTool, fArgs m
 }
 
 func (f *Flow) invokeAfterToolCallbacks(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context, fResult map[string]any, fErr error) (map[string]any, error) {
-	for _, callback := range f.AfterToolCallbacks {
+	for _, callback := range f.afterToolCallbacks(toolCtx) {
 		result, err := callback(toolCtx, tool, fArgs, fResult, fErr)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute callback: %w", err)
@@ -498,9 +741,9 @@ func mergeParallelFunctionResponseEvents(events []*session.Event) (*session.Even
 func mergeEventActions(base, other *session.EventActions) *session.EventActions {
 	// flows/llm_flows/functions.py merge_parallel_function_response_events
 	//
-	// TODO: merge_parallel_function_response_events creates a "last one wins" scenario
-	// except parts and requested_auth_configs. Check with the ADK team about
-	// the intention.
+	// The deltas of the calls are merged, the calls of a response run
+	// concurrently and each records its changes separately. If several calls
+	// change the same key, the call listed last wins.
 	if other == nil {
 		return base
 	}
@@ -516,8 +759,23 @@ func mergeEventActions(base, other *session.EventActions) *session.EventActions
 	if other.Escalate {
 		base.Escalate = true
 	}
-	if other.StateDelta != nil {
-		base.StateDelta = other.StateDelta
+	if len(other.StateDelta) > 0 {
+		if base.StateDelta == nil {
+			base.StateDelta = make(map[string]any)
+		}
+		maps.Copy(base.StateDelta, other.StateDelta)
+	}
+	if len(other.ArtifactDelta) > 0 {
+		if base.ArtifactDelta == nil {
+			base.ArtifactDelta = make(map[string]int64)
+		}
+		maps.Copy(base.ArtifactDelta, other.ArtifactDelta)
+	}
+	if len(other.RequestedAuthConfigs) > 0 {
+		if base.RequestedAuthConfigs == nil {
+			base.RequestedAuthConfigs = make(map[string]*auth.Config)
+		}
+		maps.Copy(base.RequestedAuthConfigs, other.RequestedAuthConfigs)
 	}
 	return base
 }
@@ -15,12 +15,15 @@
 package llminternal
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
 )
 
@@ -37,15 +40,51 @@ func basicRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest) e
 	if req.Config == nil {
 		req.Config = &genai.GenerateContentConfig{}
 	}
-	if llmAgent.internal().OutputSchema != nil {
-		req.Config.ResponseSchema = llmAgent.internal().OutputSchema
-		req.Config.ResponseMIMEType = "application/json"
+	if settings := llmAgent.internal().SafetySettings; len(settings) > 0 {
+		req.Config.SafetySettings = clone(settings)
+	}
+	if schema := llmAgent.internal().OutputSchema; schema != nil || llmAgent.internal().OutputJSON {
+		if model.SupportsJSONMode(llmAgent.internal().Model) {
+			req.Config.ResponseSchema = schema
+			req.Config.ResponseMIMEType = "application/json"
+		} else {
+			// The model is only asked to reply with JSON, the response is
+			// validated by the agent either way.
+			instruction, err := jsonInstruction(schema)
+			if err != nil {
+				return err
+			}
+			utils.AppendInstructions(req, instruction)
+		}
+	}
+	if mode := llmAgent.internal().ToolChoice; mode != "" {
+		if req.Config.ToolConfig == nil {
+			req.Config.ToolConfig = &genai.ToolConfig{}
+		}
+		req.Config.ToolConfig.FunctionCallingConfig = &genai.FunctionCallingConfig{
+			Mode:                 mode,
+			AllowedFunctionNames: slices.Clone(llmAgent.internal().AllowedFunctionNames),
+		}
 	}
 	// TODO: missing features
 	//  populate LLMRequest LiveConnectConfig setting
 	return nil
 }
 
+// jsonInstruction returns the instruction asking a model without JSON mode to
+// reply with JSON matching the schema, if any.
+func jsonInstruction(schema *genai.Schema) (string, error) {
+	instruction := "Reply only with a JSON value, without any other text."
+	if schema == nil {
+		return instruction, nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize the output schema: %w", err)
+	}
+	return instruction + " The JSON value must match this schema:\n" + string(data), nil
+}
+
 // clone returns a deep copy of the src.
 // NOTE: this does not work for types with unexported fields.
 func clone[M any](src M) M {
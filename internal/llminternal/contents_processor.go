@@ -47,13 +47,27 @@ func ContentsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest
 	var events []*session.Event
 	if ctx.Session() != nil {
 		for e := range ctx.Session().Events().All() {
+			// The events forwarded from the agents run by tools only
+			// report their progress.
+			if e.ParentFunctionCallID != "" {
+				continue
+			}
 			events = append(events, e)
 		}
 	}
+	if llmAgent.internal().IncludeContents != "none" {
+		// Replace the summarized history with its summary.
+		events = applyCompaction(ctx.Branch(), events)
+	}
 	contents, err := fn(ctx.Agent().Name(), ctx.Branch(), events)
 	if err != nil {
 		return err
 	}
+	if cfg := llmAgent.internal().HistoryTrimming; cfg != nil && llmAgent.internal().IncludeContents != "none" {
+		if contents, err = trimHistory(ctx, cfg, ctx.Agent().Name(), ctx.Branch(), events, contents); err != nil {
+			return fmt.Errorf("failed to trim the conversation history: %w", err)
+		}
+	}
 	req.Contents = append(req.Contents, contents...)
 	return nil
 }
@@ -147,7 +161,7 @@ func rearrangeEventsForLatestFunctionResponse(events []*session.Event) ([]*sessi
 	}
 
 	lastEvent := events[len(events)-1]
-	lastResponses := listFunctionResponsesFromEvent(lastEvent)
+	lastResponses := lastEvent.FunctionResponses()
 	// No need to process, since the latest event is not function_response.
 	if len(lastResponses) == 0 {
 		return events, nil
@@ -161,7 +175,7 @@ func rearrangeEventsForLatestFunctionResponse(events []*session.Event) ([]*sessi
 
 	// Check if its already in the correct position
 	prevEvent := events[len(events)-2]
-	prevCalls := listFunctionCallsFromEvent(prevEvent)
+	prevCalls := prevEvent.FunctionCalls()
 	if len(prevCalls) > 0 {
 		for _, call := range prevCalls {
 			if _, found := responseIDs[call.ID]; found {
@@ -178,7 +192,7 @@ func rearrangeEventsForLatestFunctionResponse(events []*session.Event) ([]*sessi
 SearchLoop: // A label to allow breaking out of the nested loop
 	for idx := len(events) - 2; idx >= 0; idx-- {
 		event := events[idx]
-		calls := listFunctionCallsFromEvent(event)
+		calls := event.FunctionCalls()
 
 		if len(calls) > 0 {
 			for _, call := range calls {
@@ -225,7 +239,7 @@ SearchLoop: // A label to allow breaking out of the nested loop
 	var responseEventsToMerge []*session.Event
 	for i := functionCallEventIdx + 1; i < len(events)-1; i++ {
 		event := events[i]
-		responses := listFunctionResponsesFromEvent(event)
+		responses := event.FunctionResponses()
 		if len(responses) == 0 {
 			continue
 		}
@@ -276,7 +290,7 @@ func rearrangeEventsForFunctionResponsesInHistory(events []*session.Event) ([]*s
 	// Create a map to store the index of the event containing each function response.
 	callIDToResponseEventIndex := make(map[string]int)
 	for i, event := range events {
-		responses := listFunctionResponsesFromEvent(event)
+		responses := event.FunctionResponses()
 
 		if len(responses) > 0 {
 			for _, res := range responses {
@@ -291,11 +305,11 @@ func rearrangeEventsForFunctionResponsesInHistory(events []*session.Event) ([]*s
 	for _, event := range events {
 		// If the event contains responses, skip it. It will be handled
 		// when we process its corresponding call event.
-		if len(listFunctionResponsesFromEvent(event)) > 0 {
+		if len(event.FunctionResponses()) > 0 {
 			continue
 		}
 
-		calls := listFunctionCallsFromEvent(event)
+		calls := event.FunctionCalls()
 		if len(calls) == 0 {
 			// This is a regular event (e.g., user message). Just append it.
 			resultEvents = append(resultEvents, event)
@@ -522,30 +536,6 @@ func isAuthEvent(ev *session.Event) bool {
 	return false
 }
 
-func listFunctionCallsFromEvent(e *session.Event) []*genai.FunctionCall {
-	funcCalls := make([]*genai.FunctionCall, 0)
-	if e.LLMResponse.Content != nil && e.LLMResponse.Content.Parts != nil {
-		for _, part := range e.LLMResponse.Content.Parts {
-			if part.FunctionCall != nil {
-				funcCalls = append(funcCalls, part.FunctionCall)
-			}
-		}
-	}
-	return funcCalls
-}
-
-func listFunctionResponsesFromEvent(e *session.Event) []*genai.FunctionResponse {
-	funcResponses := make([]*genai.FunctionResponse, 0)
-	if e.LLMResponse.Content != nil && e.LLMResponse.Content.Parts != nil {
-		for _, part := range e.LLMResponse.Content.Parts {
-			if part.FunctionResponse != nil {
-				funcResponses = append(funcResponses, part.FunctionResponse)
-			}
-		}
-	}
-	return funcResponses
-}
-
 func cloneEvent(e *session.Event) *session.Event {
 	if e == nil {
 		return nil
@@ -0,0 +1,324 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+const (
+	defaultKeepRecentTurns = 2
+	// charsPerToken is used to estimate the number of tokens of the history.
+	charsPerToken = 4
+
+	summaryPrefix      = "Summary of the earlier conversation:\n"
+	summaryInstruction = "Summarize the conversation above. Keep all the facts, decisions, open questions " +
+		"and tool results which may be needed to continue the conversation. Reply with the summary only."
+)
+
+// compactHistory summarizes the older history of the session if it exceeds
+// the thresholds of the agent's context compression config. It returns the
+// event carrying the summary, which has to be stored in the session, or nil
+// if no compaction is needed.
+//
+// The most recent turns are kept verbatim. A function call and its response
+// are never split between the summary and the kept events.
+func compactHistory(ctx agent.InvocationContext) (*session.Event, error) {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil || ctx.Session() == nil {
+		return nil, nil
+	}
+	state := llmAgent.internal()
+	cfg := state.ContextCompression
+	if cfg == nil || state.IncludeContents == "none" {
+		return nil, nil
+	}
+
+	var events []*session.Event
+	for e := range ctx.Session().Events().All() {
+		events = append(events, e)
+	}
+	history := applyCompaction(ctx.Branch(), events)
+	contents, err := buildContentsDefault(ctx.Agent().Name(), ctx.Branch(), history)
+	if err != nil {
+		return nil, err
+	}
+	if !exceedsThresholds(cfg, contents) {
+		return nil, nil
+	}
+
+	keepRecentTurns := cfg.KeepRecentTurns
+	if keepRecentTurns <= 0 {
+		keepRecentTurns = defaultKeepRecentTurns
+	}
+	boundary := compactionBoundary(history, keepRecentTurns)
+	compacted := history[:boundary]
+	first, last := firstAndLastStoredEvent(compacted)
+	if last == nil {
+		// Only the previous summary precedes the kept turns.
+		return nil, nil
+	}
+
+	compactor := cfg.Compactor
+	if compactor == nil {
+		summarizer := cfg.Summarizer
+		if summarizer == nil {
+			summarizer = state.Model
+		}
+		if summarizer == nil {
+			return nil, fmt.Errorf("agent %q has no Summarizer or Model configured", ctx.Agent().Name())
+		}
+		compactor = &ModelCompactor{Model: summarizer}
+	}
+	contents, err = buildContentsDefault(ctx.Agent().Name(), ctx.Branch(), compacted)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := compactor.Summarize(ctx, contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize the conversation history: %w", err)
+	}
+	if summary == "" {
+		return nil, fmt.Errorf("failed to summarize the conversation history: empty summary")
+	}
+
+	start := first.Timestamp
+	if prev := latestCompaction(ctx.Branch(), events); prev != nil {
+		start = prev.Actions.Compaction.StartTimestamp
+	}
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.Actions.Compaction = &session.EventCompaction{
+		StartTimestamp:   start,
+		EndTimestamp:     last.Timestamp,
+		CompactedContent: genai.NewContentFromText(summaryPrefix+summary, genai.RoleUser),
+	}
+	return event, nil
+}
+
+// applyCompaction replaces the events covered by the latest compaction of the
+// branch with a single event carrying the summary.
+func applyCompaction(branch string, events []*session.Event) []*session.Event {
+	compactionEvent := latestCompaction(branch, events)
+	if compactionEvent == nil {
+		return events
+	}
+	compaction := compactionEvent.Actions.Compaction
+	result := []*session.Event{{ // made-up event. Don't go through types.NewEvent.
+		Timestamp:   compaction.EndTimestamp,
+		Author:      "user",
+		Branch:      compactionEvent.Branch,
+		LLMResponse: model.LLMResponse{Content: compaction.CompactedContent},
+	}}
+	for _, ev := range events {
+		if ev.Actions.Compaction != nil || !ev.Timestamp.After(compaction.EndTimestamp) {
+			continue
+		}
+		result = append(result, ev)
+	}
+	return result
+}
+
+func latestCompaction(branch string, events []*session.Event) *session.Event {
+	for i := len(events) - 1; i >= 0; i-- {
+		ev := events[i]
+		if ev.Actions.Compaction != nil && ev.Actions.Compaction.CompactedContent != nil && eventBelongsToBranch(branch, ev) {
+			return ev
+		}
+	}
+	return nil
+}
+
+func exceedsThresholds(cfg *ContextCompression, contents []*genai.Content) bool {
+	if cfg.MaxEvents > 0 && len(contents) > cfg.MaxEvents {
+		return true
+	}
+	return cfg.MaxTokens > 0 && estimateTokens(contents) > cfg.MaxTokens
+}
+
+// estimateTokens roughly estimates the number of tokens of the contents.
+func estimateTokens(contents []*genai.Content) int {
+	chars := 0
+	for _, content := range contents {
+		for _, part := range content.Parts {
+			switch {
+			case part.Text != "":
+				chars += len(part.Text)
+			case part.FunctionCall != nil:
+				chars += len(part.FunctionCall.Name) + len(stringify(part.FunctionCall.Args))
+			case part.FunctionResponse != nil:
+				chars += len(part.FunctionResponse.Name) + len(stringify(part.FunctionResponse.Response))
+			default:
+				b, _ := json.Marshal(part)
+				chars += len(b)
+			}
+		}
+	}
+	return chars / charsPerToken
+}
+
+// compactionBoundary returns the index of the first event which is kept
+// verbatim. Events before it are summarized.
+func compactionBoundary(events []*session.Event, keepRecentTurns int) int {
+	var turnStarts []int
+	for i, ev := range events {
+		if isTurnStart(ev) {
+			turnStarts = append(turnStarts, i)
+		}
+	}
+	if len(turnStarts) <= keepRecentTurns {
+		return 0
+	}
+	boundary := turnStarts[len(turnStarts)-keepRecentTurns]
+
+	// Move the boundary before any function call whose response is kept.
+	for {
+		callIndex := -1
+		for _, ev := range events[boundary:] {
+			for _, resp := range ev.FunctionResponses() {
+				if i := functionCallIndex(events[:boundary], resp.ID); i >= 0 && (callIndex < 0 || i < callIndex) {
+					callIndex = i
+				}
+			}
+		}
+		if callIndex < 0 {
+			return boundary
+		}
+		boundary = 0
+		for _, start := range turnStarts {
+			if start <= callIndex {
+				boundary = start
+			}
+		}
+		if boundary == 0 {
+			return 0
+		}
+	}
+}
+
+// isTurnStart reports whether the event is a user message starting a new turn.
+func isTurnStart(ev *session.Event) bool {
+	if ev.Author != "user" || ev.Actions.Compaction != nil {
+		return false
+	}
+	content := utils.Content(ev)
+	if content == nil {
+		return false
+	}
+	for _, part := range content.Parts {
+		if part.FunctionResponse != nil {
+			return false
+		}
+	}
+	return len(content.Parts) > 0
+}
+
+func functionCallIndex(events []*session.Event, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, ev := range events {
+		for _, call := range ev.FunctionCalls() {
+			if call.ID == id {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// firstAndLastStoredEvent returns the first and last events which are stored
+// in the session, skipping the made-up summary event.
+func firstAndLastStoredEvent(events []*session.Event) (first, last *session.Event) {
+	for _, ev := range events {
+		if ev.ID == "" {
+			continue
+		}
+		if first == nil {
+			first = ev
+		}
+		last = ev
+	}
+	return first, last
+}
+
+// ModelCompactor is the default [HistoryCompactor], which asks the model to
+// summarize the history. The model calls count towards the limits and the
+// usage of the invocation.
+type ModelCompactor struct {
+	Model model.LLM
+}
+
+// Summarize implements [HistoryCompactor].
+func (c *ModelCompactor) Summarize(ctx context.Context, contents []*genai.Content) (string, error) {
+	contents = append(slices.Clone(contents), genai.NewContentFromText(summaryInstruction, genai.RoleUser))
+
+	runCfg := runconfig.FromContext(ctx)
+	if runCfg != nil {
+		if err := runCfg.LLMCalls.Increment(); err != nil {
+			return "", err
+		}
+	}
+	req := &model.LLMRequest{
+		Model:    c.Model.Name(),
+		Contents: contents,
+		Config:   &genai.GenerateContentConfig{},
+	}
+	var summary string
+	for resp, err := range c.Model.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", err
+		}
+		if resp.Partial {
+			continue
+		}
+		if runCfg != nil {
+			runCfg.Usage.Add(req.Model, resp.UsageMetadata)
+		}
+		if text := responseText(resp); text != "" {
+			summary = text
+		}
+	}
+	if summary == "" {
+		return "", fmt.Errorf("summarizer returned an empty summary")
+	}
+	return summary, nil
+}
+
+func responseText(resp *model.LLMResponse) string {
+	if resp.Content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range resp.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
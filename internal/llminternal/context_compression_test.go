@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestCompactionBoundary(t *testing.T) {
+	newEvent := func(author string, parts ...*genai.Part) *session.Event {
+		role := genai.RoleModel
+		if author == "user" {
+			role = genai.RoleUser
+		}
+		return &session.Event{
+			ID:          "id",
+			Author:      author,
+			LLMResponse: model.LLMResponse{Content: &genai.Content{Role: role, Parts: parts}},
+		}
+	}
+	call := &genai.Part{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "long_task"}}
+	response := &genai.Part{FunctionResponse: &genai.FunctionResponse{ID: "call-1", Name: "long_task"}}
+
+	tests := []struct {
+		name            string
+		events          []*session.Event
+		keepRecentTurns int
+		want            int
+	}{
+		{
+			name: "keeps the last turn",
+			events: []*session.Event{
+				newEvent("user", genai.NewPartFromText("a")),
+				newEvent("agent", genai.NewPartFromText("A")),
+				newEvent("user", genai.NewPartFromText("b")),
+				newEvent("agent", genai.NewPartFromText("B")),
+			},
+			keepRecentTurns: 1,
+			want:            2,
+		},
+		{
+			name: "not enough turns",
+			events: []*session.Event{
+				newEvent("user", genai.NewPartFromText("a")),
+				newEvent("agent", genai.NewPartFromText("A")),
+			},
+			keepRecentTurns: 1,
+			want:            0,
+		},
+		{
+			name: "user function response doesn't start a turn",
+			events: []*session.Event{
+				newEvent("user", genai.NewPartFromText("a")),
+				newEvent("agent", call),
+				newEvent("user", response),
+				newEvent("agent", genai.NewPartFromText("A")),
+			},
+			keepRecentTurns: 1,
+			want:            0,
+		},
+		{
+			name: "function call is not split from its response",
+			events: []*session.Event{
+				newEvent("user", genai.NewPartFromText("a")),
+				newEvent("agent", genai.NewPartFromText("A")),
+				newEvent("user", genai.NewPartFromText("b")),
+				newEvent("agent", call),
+				newEvent("user", genai.NewPartFromText("c")),
+				newEvent("agent", response),
+				newEvent("agent", genai.NewPartFromText("C")),
+			},
+			keepRecentTurns: 1,
+			want:            2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compactionBoundary(tt.events, tt.keepRecentTurns); got != tt.want {
+				t.Errorf("compactionBoundary() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCompaction(t *testing.T) {
+	start := time.Now()
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * time.Second) }
+	summary := genai.NewContentFromText("summary", genai.RoleUser)
+	events := []*session.Event{
+		{ID: "1", Timestamp: at(1), Author: "user"},
+		{ID: "2", Timestamp: at(2), Author: "agent"},
+		{ID: "3", Timestamp: at(3), Author: "user"},
+		{ID: "4", Timestamp: at(4), Author: "agent", Actions: session.EventActions{Compaction: &session.EventCompaction{
+			StartTimestamp:   at(1),
+			EndTimestamp:     at(2),
+			CompactedContent: summary,
+		}}},
+		{ID: "5", Timestamp: at(5), Author: "agent"},
+	}
+
+	got := applyCompaction("", events)
+
+	var gotIDs []string
+	for _, ev := range got {
+		gotIDs = append(gotIDs, ev.ID)
+	}
+	// The made-up summary event has no ID.
+	if diff := cmp.Diff([]string{"", "3", "5"}, gotIDs); diff != "" {
+		t.Fatalf("applyCompaction() event IDs mismatch (-want +got):\n%s", diff)
+	}
+	if got[0].Content != summary {
+		t.Errorf("applyCompaction() first event content = %v, want the summary", got[0].Content)
+	}
+}
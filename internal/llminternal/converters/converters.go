@@ -20,10 +20,27 @@ import (
 	"google.golang.org/adk/model"
 )
 
+// FirstCandidate returns the candidate with index 0, or nil if there is no
+// such candidate.
+//
+// When multiple candidates are requested with
+// genai.GenerateContentConfig.CandidateCount, ADK deterministically uses the
+// first candidate and discards the others. In streaming mode a chunk may
+// contain only the other candidates, in which case nil is returned.
+func FirstCandidate(candidates []*genai.Candidate) *genai.Candidate {
+	for _, c := range candidates {
+		if c != nil && c.Index == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// Genai2LLMResponse converts the first candidate of the response (see
+// FirstCandidate) to model.LLMResponse.
 func Genai2LLMResponse(res *genai.GenerateContentResponse) *model.LLMResponse {
 	usageMetadata := res.UsageMetadata
-	if len(res.Candidates) > 0 && res.Candidates[0] != nil {
-		candidate := res.Candidates[0]
+	if candidate := FirstCandidate(res.Candidates); candidate != nil {
 		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
 			return &model.LLMResponse{
 				Content:           candidate.Content,
@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/session"
+)
+
+// trimHistory drops the oldest events of the history until their contents
+// are within the limits of cfg, and returns the contents of the kept events.
+//
+// The latest user message and the events following it are always kept. A
+// function call and its response are never split: the boundaries between
+// them aren't considered. With KeepWholeTurns, only the boundaries before
+// user messages are.
+func trimHistory(ctx context.Context, cfg *HistoryTrimming, agentName, branch string, events []*session.Event, contents []*genai.Content) ([]*genai.Content, error) {
+	ok, err := withinLimits(ctx, cfg, contents)
+	if err != nil || ok {
+		return contents, err
+	}
+	for _, boundary := range trimBoundaries(branch, events, cfg.KeepWholeTurns) {
+		contents, err = buildContentsDefault(agentName, branch, events[boundary:])
+		if err != nil {
+			return nil, err
+		}
+		ok, err := withinLimits(ctx, cfg, contents)
+		if err != nil || ok {
+			return contents, err
+		}
+	}
+	// Only the latest turn is left.
+	return contents, nil
+}
+
+func withinLimits(ctx context.Context, cfg *HistoryTrimming, contents []*genai.Content) (bool, error) {
+	if cfg.MaxEvents > 0 && len(contents) > cfg.MaxEvents {
+		return false, nil
+	}
+	if cfg.MaxTokens <= 0 {
+		return true, nil
+	}
+	tokens := estimateTokens(contents)
+	if cfg.TokenCounter != nil {
+		var err error
+		if tokens, err = cfg.TokenCounter.CountTokens(ctx, contents); err != nil {
+			return false, err
+		}
+	}
+	return tokens <= cfg.MaxTokens, nil
+}
+
+// trimBoundaries returns, in ascending order, the indexes of the events the
+// trimmed history can start with, up to the latest user message of the branch.
+func trimBoundaries(branch string, events []*session.Event, wholeTurns bool) []int {
+	last := -1
+	for i, ev := range events {
+		if isTurnStart(ev) && eventBelongsToBranch(branch, ev) {
+			last = i
+		}
+	}
+	if last <= 0 {
+		return nil
+	}
+
+	// A boundary between a function call and its response would split them.
+	split := make([]bool, last+1)
+	for j, ev := range events {
+		for _, resp := range ev.FunctionResponses() {
+			if c := functionCallIndex(events[:j], resp.ID); c >= 0 {
+				for b := c + 1; b <= j && b <= last; b++ {
+					split[b] = true
+				}
+			}
+		}
+	}
+
+	var boundaries []int
+	for b := 1; b <= last; b++ {
+		if split[b] || (wholeTurns && !(isTurnStart(events[b]) && eventBelongsToBranch(branch, events[b]))) {
+			continue
+		}
+		boundaries = append(boundaries, b)
+	}
+	return boundaries
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// wordCounter counts a token per word.
+type wordCounter struct {
+	calls int
+	err   error
+}
+
+func (c *wordCounter) CountTokens(ctx context.Context, contents []*genai.Content) (int, error) {
+	c.calls++
+	tokens := 0
+	for _, content := range contents {
+		for _, part := range content.Parts {
+			tokens += len(strings.Fields(part.Text))
+			if part.FunctionCall != nil || part.FunctionResponse != nil {
+				tokens++
+			}
+		}
+	}
+	return tokens, c.err
+}
+
+func TestTrimHistory(t *testing.T) {
+	newEvent := func(author string, parts ...*genai.Part) *session.Event {
+		role := genai.RoleModel
+		if author == "user" {
+			role = genai.RoleUser
+		}
+		return &session.Event{
+			ID:          "id",
+			Author:      author,
+			LLMResponse: model.LLMResponse{Content: &genai.Content{Role: role, Parts: parts}},
+		}
+	}
+	text := genai.NewPartFromText
+	call := &genai.Part{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "lookup"}}
+	response := &genai.Part{FunctionResponse: &genai.FunctionResponse{ID: "call-1", Name: "lookup"}}
+
+	tests := []struct {
+		name   string
+		events []*session.Event
+		cfg    HistoryTrimming
+		want   []string
+	}{
+		{
+			name: "within limits",
+			events: []*session.Event{
+				newEvent("user", text("a")),
+				newEvent("agent", text("A")),
+				newEvent("user", text("b")),
+			},
+			cfg:  HistoryTrimming{MaxEvents: 3},
+			want: []string{"a", "A", "b"},
+		},
+		{
+			name: "drops the oldest events",
+			events: []*session.Event{
+				newEvent("user", text("a")),
+				newEvent("agent", text("A")),
+				newEvent("user", text("b")),
+				newEvent("agent", text("B")),
+				newEvent("user", text("c")),
+			},
+			cfg:  HistoryTrimming{MaxEvents: 2},
+			want: []string{"B", "c"},
+		},
+		{
+			name: "keeps whole turns",
+			events: []*session.Event{
+				newEvent("user", text("a")),
+				newEvent("agent", text("A")),
+				newEvent("user", text("b")),
+				newEvent("agent", text("B")),
+				newEvent("user", text("c")),
+			},
+			cfg:  HistoryTrimming{MaxEvents: 2, KeepWholeTurns: true},
+			want: []string{"c"},
+		},
+		{
+			name: "keeps the latest user message over the limits",
+			events: []*session.Event{
+				newEvent("user", text("a")),
+				newEvent("agent", text("A")),
+				newEvent("user", text("a very long question")),
+			},
+			cfg:  HistoryTrimming{MaxTokens: 1, TokenCounter: &wordCounter{}},
+			want: []string{"a very long question"},
+		},
+		{
+			name: "keeps the function call with its response",
+			events: []*session.Event{
+				newEvent("user", text("a")),
+				newEvent("agent", call),
+				newEvent("user", response),
+				newEvent("agent", text("A")),
+				newEvent("user", text("b")),
+			},
+			// Dropping the call without its response would fit.
+			cfg:  HistoryTrimming{MaxEvents: 3},
+			want: []string{"A", "b"},
+		},
+		{
+			name: "counts tokens with the token counter",
+			events: []*session.Event{
+				newEvent("user", text("one two three")),
+				newEvent("agent", text("four five")),
+				newEvent("user", text("six")),
+			},
+			cfg:  HistoryTrimming{MaxTokens: 3, TokenCounter: &wordCounter{}},
+			want: []string{"four five", "six"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := buildContentsDefault("agent", "", tt.events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := trimHistory(t.Context(), &tt.cfg, "agent", "", tt.events, contents)
+			if err != nil {
+				t.Fatalf("trimHistory() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, contentTexts(got)); diff != "" {
+				t.Errorf("trimHistory() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTrimHistory_TokenCounterError(t *testing.T) {
+	events := []*session.Event{
+		{Author: "user", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("a", genai.RoleUser)}},
+	}
+	contents, err := buildContentsDefault("agent", "", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counterErr := errors.New("quota exceeded")
+	cfg := &HistoryTrimming{MaxTokens: 10, TokenCounter: &wordCounter{err: counterErr}}
+	if _, err := trimHistory(t.Context(), cfg, "agent", "", events, contents); !errors.Is(err, counterErr) {
+		t.Errorf("trimHistory() error = %v, want %v", err, counterErr)
+	}
+}
+
+func contentTexts(contents []*genai.Content) []string {
+	var texts []string
+	for _, content := range contents {
+		for _, part := range content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				texts = append(texts, part.FunctionCall.Name+"()")
+			case part.FunctionResponse != nil:
+				texts = append(texts, part.FunctionResponse.Name+" response")
+			default:
+				texts = append(texts, part.Text)
+			}
+		}
+	}
+	return texts
+}
@@ -42,21 +42,19 @@ func instructionsRequestProcessor(ctx agent.InvocationContext, req *model.LLMReq
 		return nil // do nothing.
 	}
 
+	// Only the global instruction of the root agent applies, to all the
+	// agents of the tree. The global instructions of the other agents are
+	// ignored, also when the root isn't an LLM agent.
 	parents := parentmap.FromContext(ctx)
-
-	rootAgent := asLLMAgent(parents.RootAgent(ctx.Agent()))
-	if rootAgent == nil {
-		rootAgent = llmAgent
-	}
-
-	// Append global instructions.
-	if err := appendGlobalInstructions(ctx, req, rootAgent.internal()); err != nil {
-		return fmt.Errorf("failed to append global instructions: %w", err)
+	if rootAgent := asLLMAgent(parents.RootAgent(ctx.Agent())); rootAgent != nil {
+		if err := appendGlobalInstructions(ctx, req, rootAgent.internal()); err != nil {
+			return fmt.Errorf("failed to append global instructions: %w", err)
+		}
 	}
 
 	// Append agent's instruction
 	if err := appendInstructions(ctx, req, llmAgent.internal()); err != nil {
-		return fmt.Errorf("failed to append instructions: %w", err)
+		return fmt.Errorf("failed to append instructions of agent %q: %w", ctx.Agent().Name(), err)
 	}
 
 	return nil
@@ -69,7 +67,7 @@ func appendInstructions(ctx agent.InvocationContext, req *model.LLMRequest, agen
 	if agentState.InstructionProvider != nil {
 		instruction, err := agentState.InstructionProvider(icontext.NewReadonlyContext(ctx))
 		if err != nil {
-			return fmt.Errorf("failed to evaluate global instruction provider: %w", err)
+			return fmt.Errorf("failed to evaluate instruction provider: %w", err)
 		}
 
 		utils.AppendInstructions(req, instruction)
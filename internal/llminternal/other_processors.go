@@ -56,11 +56,6 @@ func codeExecutionRequestProcessor(ctx agent.InvocationContext, req *model.LLMRe
 	return nil
 }
 
-func authPreprocessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
-	// TODO: implement (adk-python src/google/adk/auth/auth_preprocessor.py)
-	return nil
-}
-
 func nlPlanningResponseProcessor(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
 	// TODO: implement (adk-python src/google/adk/_nl_planning.py)
 	return nil
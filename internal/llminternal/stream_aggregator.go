@@ -30,10 +30,14 @@ import (
 // It aggregates content from partial responses, and generates LlmResponses for
 // individual (partial) model responses, as well as for aggregated content.
 type streamingResponseAggregator struct {
-	text        string
-	thoughtText string
-	response    *model.LLMResponse
-	role        string
+	// parts holds the aggregated text, consecutive thought and answer
+	// chunks are merged into one part each.
+	parts    []*genai.Part
+	response *model.LLMResponse
+	role     string
+	// usage is the usage metadata of the last chunk having it, which may
+	// have no candidate.
+	usage *genai.GenerateContentResponseUsageMetadata
 }
 
 // NewStreamingResponseAggregator creates a new, initialized streamingResponseAggregator.
@@ -45,12 +49,23 @@ func NewStreamingResponseAggregator() *streamingResponseAggregator {
 // also yielding an aggregated response if the GenerateContentResponse has zero parts or is audio data
 func (s *streamingResponseAggregator) ProcessResponse(ctx context.Context, genResp *genai.GenerateContentResponse) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
+		// Some providers send the usage in a last chunk without candidates,
+		// it is kept for the aggregated response.
+		if genResp.UsageMetadata != nil {
+			s.usage = genResp.UsageMetadata
+		}
 		if len(genResp.Candidates) == 0 {
-			// shouldn't happen?
-			yield(nil, fmt.Errorf("empty response"))
+			if genResp.UsageMetadata == nil {
+				// shouldn't happen?
+				yield(nil, fmt.Errorf("empty response"))
+			}
+			return
+		}
+		candidate := converters.FirstCandidate(genResp.Candidates)
+		if candidate == nil {
+			// The chunk contains only other candidates, which are discarded.
 			return
 		}
-		candidate := genResp.Candidates[0]
 		resp := converters.Genai2LLMResponse(genResp)
 		resp.TurnComplete = candidate.FinishReason != ""
 		// Aggregate the response and check if an intermediate event to yield was created
@@ -71,31 +86,18 @@ func (s *streamingResponseAggregator) ProcessResponse(ctx context.Context, genRe
 func (s *streamingResponseAggregator) aggregateResponse(llmResponse *model.LLMResponse) *model.LLMResponse {
 	s.response = llmResponse
 
-	var part0 *genai.Part
-	if llmResponse.Content != nil && len(llmResponse.Content.Parts) > 0 {
-		part0 = llmResponse.Content.Parts[0]
+	// If all parts are text append them
+	if isTextContent(llmResponse.Content) {
 		s.role = llmResponse.Content.Role
-	}
-
-	// If part is text append it
-	if part0 != nil && part0.Text != "" {
-		if part0.Thought {
-			s.thoughtText += part0.Text
-		} else {
-			s.text += part0.Text
+		for _, part := range llmResponse.Content.Parts {
+			s.appendText(part)
 		}
 		llmResponse.Partial = true
 		return nil
 	}
 
-	// gemini 3 in streaming returns a last response with an empty part. We need to filter it out.
-	if part0 != nil && reflect.ValueOf(*part0).IsZero() {
-		llmResponse.Partial = true
-		return nil
-	}
-
 	// If there is aggregated text and there is no content or parts return aggregated response
-	if (s.thoughtText != "" || s.text != "") &&
+	if len(s.parts) > 0 &&
 		(llmResponse.Content == nil ||
 			len(llmResponse.Content.Parts) == 0 ||
 			// don't yield the merged text event when receiving audio data
@@ -106,6 +108,39 @@ func (s *streamingResponseAggregator) aggregateResponse(llmResponse *model.LLMRe
 	return nil
 }
 
+// isTextContent returns whether the content has parts and all of them are
+// text, thought or not. Empty parts are allowed: gemini 3 in streaming
+// returns a last response with an empty part, which needs to be filtered out.
+func isTextContent(content *genai.Content) bool {
+	if content == nil || len(content.Parts) == 0 {
+		return false
+	}
+	for _, part := range content.Parts {
+		if part == nil || (part.Text == "" && !reflect.ValueOf(*part).IsZero()) {
+			return false
+		}
+	}
+	return true
+}
+
+// appendText merges the text part into the last aggregated part if both are
+// thoughts or both are answer text, or appends a new part otherwise, keeping
+// the order in which the model produced them.
+func (s *streamingResponseAggregator) appendText(part *genai.Part) {
+	if part.Text == "" {
+		return
+	}
+	if n := len(s.parts); n > 0 && s.parts[n-1].Thought == part.Thought {
+		last := s.parts[n-1]
+		last.Text += part.Text
+		if last.ThoughtSignature == nil {
+			last.ThoughtSignature = part.ThoughtSignature
+		}
+		return
+	}
+	s.parts = append(s.parts, &genai.Part{Text: part.Text, Thought: part.Thought, ThoughtSignature: part.ThoughtSignature})
+}
+
 // Close generates an aggregated response at the end, if needed,
 // this should be called after all the model responses are processed.
 func (s *streamingResponseAggregator) Close() *model.LLMResponse {
@@ -113,20 +148,12 @@ func (s *streamingResponseAggregator) Close() *model.LLMResponse {
 }
 
 func (s *streamingResponseAggregator) createAggregateResponse() *model.LLMResponse {
-	if (s.text != "" || s.thoughtText != "") && s.response != nil {
-		var parts []*genai.Part
-		if s.thoughtText != "" {
-			parts = append(parts, &genai.Part{Text: s.thoughtText, Thought: true})
-		}
-		if s.text != "" {
-			parts = append(parts, &genai.Part{Text: s.text, Thought: false})
-		}
-
+	if len(s.parts) > 0 && s.response != nil {
 		response := &model.LLMResponse{
-			Content:           &genai.Content{Parts: parts, Role: s.role},
+			Content:           &genai.Content{Parts: s.parts, Role: s.role},
 			ErrorCode:         s.response.ErrorCode,
 			ErrorMessage:      s.response.ErrorMessage,
-			UsageMetadata:     s.response.UsageMetadata,
+			UsageMetadata:     s.usage,
 			GroundingMetadata: s.response.GroundingMetadata,
 			FinishReason:      s.response.FinishReason,
 		}
@@ -139,7 +166,7 @@ func (s *streamingResponseAggregator) createAggregateResponse() *model.LLMRespon
 
 func (s *streamingResponseAggregator) clear() {
 	s.response = nil
-	s.text = ""
-	s.thoughtText = ""
+	s.parts = nil
 	s.role = ""
+	s.usage = nil
 }
@@ -20,6 +20,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/llminternal/converters"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 )
@@ -205,3 +207,170 @@ func TestStreamAggregator(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamAggregator_MultipleCandidates(t *testing.T) {
+	candidate := func(index int32, text string) *genai.Candidate {
+		return &genai.Candidate{Index: index, Content: genai.NewContentFromText(text, genai.RoleModel)}
+	}
+	// Chunks of a stream requested with CandidateCount: 3.
+	chunks := []*genai.GenerateContentResponse{
+		{Candidates: []*genai.Candidate{candidate(1, "b1"), candidate(0, "a1"), candidate(2, "c1")}},
+		{Candidates: []*genai.Candidate{candidate(2, "c2")}},
+		{Candidates: []*genai.Candidate{candidate(0, "a2")}},
+		{Candidates: []*genai.Candidate{candidate(1, "b2")}},
+	}
+
+	aggregator := llminternal.NewStreamingResponseAggregator()
+	var got []string
+	for _, chunk := range chunks {
+		for resp, err := range aggregator.ProcessResponse(t.Context(), chunk) {
+			if err != nil {
+				t.Fatalf("ProcessResponse() error = %v", err)
+			}
+			got = append(got, resp.Content.Parts[0].Text)
+		}
+	}
+	if resp := aggregator.Close(); resp != nil {
+		got = append(got, resp.Content.Parts[0].Text)
+	}
+
+	// Only the first candidate (index 0) is used.
+	want := []string{"a1", "a2", "a1a2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected responses (-want +got):\n%s", diff)
+	}
+}
+
+func TestStreamAggregator_UsageOnlyChunk(t *testing.T) {
+	usage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15}
+	chunks := []*genai.GenerateContentResponse{
+		{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText("Hello", genai.RoleModel)}}},
+		{Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(" world", genai.RoleModel), FinishReason: genai.FinishReasonStop}}},
+		// The usage comes in a last chunk without candidates.
+		{UsageMetadata: usage},
+		// And with a candidate other than the first one.
+		{Candidates: []*genai.Candidate{{Index: 1, Content: genai.NewContentFromText("other", genai.RoleModel)}}, UsageMetadata: usage},
+	}
+
+	aggregator := llminternal.NewStreamingResponseAggregator()
+	for _, chunk := range chunks {
+		for _, err := range aggregator.ProcessResponse(t.Context(), chunk) {
+			if err != nil {
+				t.Fatalf("ProcessResponse() error = %v", err)
+			}
+		}
+	}
+	got := aggregator.Close()
+	if got == nil {
+		t.Fatal("Close() = nil, want the aggregated response")
+	}
+	if diff := cmp.Diff(usage, got.UsageMetadata); diff != "" {
+		t.Errorf("Close() usage mismatch (-want +got):\n%s", diff)
+	}
+	if text := got.Content.Parts[0].Text; text != "Hello world" {
+		t.Errorf("Close() text = %q, want %q", text, "Hello world")
+	}
+}
+
+func TestGenai2LLMResponse_MultipleCandidates(t *testing.T) {
+	resp := converters.Genai2LLMResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{Index: 2, Content: genai.NewContentFromText("third", genai.RoleModel)},
+			{Index: 0, Content: genai.NewContentFromText("first", genai.RoleModel), FinishReason: genai.FinishReasonStop},
+			{Index: 1, Content: genai.NewContentFromText("second", genai.RoleModel)},
+		},
+	})
+	want := &model.LLMResponse{
+		Content:      genai.NewContentFromText("first", genai.RoleModel),
+		FinishReason: genai.FinishReasonStop,
+	}
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("Genai2LLMResponse() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStreamAggregator_ThoughtAndAnswerParts(t *testing.T) {
+	thought := func(text string) *genai.Part { return &genai.Part{Text: text, Thought: true} }
+	answer := genai.NewPartFromText
+	chunk := func(parts ...*genai.Part) *genai.GenerateContentResponse {
+		return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: genai.NewContentFromParts(parts, genai.RoleModel)}}}
+	}
+	signed := &genai.Part{Text: "plan. ", Thought: true, ThoughtSignature: []byte("signature")}
+	chunks := []*genai.GenerateContentResponse{
+		chunk(thought("Let me "), signed),
+		chunk(thought("Checking "), answer("The answer")),
+		chunk(answer(" is 42")),
+		chunk(&genai.Part{}),
+		chunk(thought("Double-check.")),
+		chunk(answer(" Done.")),
+	}
+
+	aggregator := llminternal.NewStreamingResponseAggregator()
+	for _, c := range chunks {
+		for resp, err := range aggregator.ProcessResponse(t.Context(), c) {
+			if err != nil {
+				t.Fatalf("ProcessResponse() error = %v", err)
+			}
+			if !resp.Partial {
+				t.Errorf("ProcessResponse() yielded a non-partial response %v for a text chunk", resp.Content)
+			}
+		}
+	}
+	got := aggregator.Close()
+	if got == nil {
+		t.Fatal("Close() = nil, want the aggregated response")
+	}
+
+	want := genai.NewContentFromParts([]*genai.Part{
+		{Text: "Let me plan. Checking ", Thought: true, ThoughtSignature: []byte("signature")},
+		{Text: "The answer is 42"},
+		{Text: "Double-check.", Thought: true},
+		{Text: " Done."},
+	}, genai.RoleModel)
+	if diff := cmp.Diff(want, got.Content); diff != "" {
+		t.Errorf("Close() content mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStreamAggregator_MockModelStreamChunks(t *testing.T) {
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromParts([]*genai.Part{
+				{Text: "Thinking hard", Thought: true},
+				{Text: "Hello, world!"},
+			}, genai.RoleModel),
+		},
+		StreamChunks: 3,
+	}
+
+	var deltas []*genai.Part
+	var final *genai.Content
+	for resp, err := range mockModel.GenerateStream(t.Context(), &model.LLMRequest{}) {
+		if err != nil {
+			t.Fatalf("GenerateStream() error = %v", err)
+		}
+		if resp.Partial {
+			deltas = append(deltas, resp.Content.Parts...)
+			continue
+		}
+		if final != nil {
+			t.Fatalf("GenerateStream() yielded a second non-partial response %v", resp.Content)
+		}
+		final = resp.Content
+	}
+
+	wantDeltas := []*genai.Part{
+		{Text: "Thin", Thought: true}, {Text: "king", Thought: true}, {Text: " hard", Thought: true},
+		{Text: "Hell"}, {Text: "o, w"}, {Text: "orld!"},
+	}
+	if diff := cmp.Diff(wantDeltas, deltas); diff != "" {
+		t.Errorf("partial responses mismatch (-want +got):\n%s", diff)
+	}
+	wantFinal := genai.NewContentFromParts([]*genai.Part{
+		{Text: "Thinking hard", Thought: true},
+		{Text: "Hello, world!"},
+	}, genai.RoleModel)
+	if diff := cmp.Diff(wantFinal, final); diff != "" {
+		t.Errorf("aggregated response mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -17,6 +17,7 @@ package memory
 import (
 	"context"
 
+	"google.golang.org/adk/agent"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
 )
@@ -39,3 +40,27 @@ func (a *Memory) Search(ctx context.Context, query string) (*memory.SearchRespon
 		Query:   query,
 	})
 }
+
+// ForwardingService returns a memory service forwarding to the memory of a
+// parent invocation, e.g. for the sub-agents run by agenttool. The
+// application and user of the search requests are ignored: the searches are
+// scoped to the ones of the parent. It returns false if the parent has no
+// memory.
+func ForwardingService(parent agent.Memory) (memory.Service, bool) {
+	if parent == nil {
+		return nil, false
+	}
+	return &forwardingService{parent: parent}, true
+}
+
+type forwardingService struct {
+	parent agent.Memory
+}
+
+func (s *forwardingService) AddSession(ctx context.Context, session session.Session) error {
+	return s.parent.AddSession(ctx, session)
+}
+
+func (s *forwardingService) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
+	return s.parent.Search(ctx, req.Query)
+}
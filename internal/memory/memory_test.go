@@ -252,3 +252,43 @@ func TestMemory_Search_Isolation(t *testing.T) {
 		t.Errorf("memory2.Search returned diff (-want +got):\n%s", diff)
 	}
 }
+
+func TestForwardingService(t *testing.T) {
+	parent := &imemory.Memory{
+		Service: memory.InMemoryService(),
+		UserID:  "parentUser",
+		AppName: "parentApp",
+	}
+	sessionService := session.InMemoryService()
+	createResponse, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "parentApp", UserID: "parentUser", SessionID: "sess1"})
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if err := sessionService.AppendEvent(t.Context(), createResponse.Session, &session.Event{
+		Timestamp:   time.Now(),
+		Author:      "user",
+		LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("the parent remembers", genai.RoleUser)},
+	}); err != nil {
+		t.Fatalf("Failed to append event: %v", err)
+	}
+	if err := parent.AddSession(t.Context(), sessioninternal.NewMutableSession(sessionService, createResponse.Session)); err != nil {
+		t.Fatalf("AddSession failed: %v", err)
+	}
+
+	s, ok := imemory.ForwardingService(parent)
+	if !ok {
+		t.Fatal("ForwardingService() = false, want a service")
+	}
+	// The search of the sub-agent app and user is scoped to the parent.
+	got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "subApp", UserID: "subUser", Query: "remembers"})
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if len(got.Memories) != 1 {
+		t.Errorf("Search() returned %d items, want the memory of the parent", len(got.Memories))
+	}
+
+	if _, ok := imemory.ForwardingService(nil); ok {
+		t.Error("ForwardingService(nil) = true, want false")
+	}
+}
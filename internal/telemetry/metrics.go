@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/genai"
+)
+
+// instruments holds the metric instruments created from a single meter.
+type instruments struct {
+	invocations      metric.Int64Counter
+	agentErrors      metric.Int64Counter
+	agentRunDuration metric.Float64Histogram
+	agentTransfers   metric.Int64Counter
+	llmRequests      metric.Int64Counter
+	llmLatency       metric.Float64Histogram
+	tokens           metric.Int64Counter
+	toolCalls        metric.Int64Counter
+	httpRequests     metric.Int64Counter
+	httpDuration     metric.Float64Histogram
+}
+
+var (
+	// globalInstruments are created from the global meter provider, which
+	// forwards them to the provider set with otel.SetMeterProvider, even
+	// after they are created.
+	globalInstruments = sync.OnceValue(func() *instruments {
+		return newInstruments(otel.GetMeterProvider().Meter(systemName))
+	})
+
+	// readerInstruments are created from a meter provider of each reader
+	// added with AddMetricReader.
+	readersMu         sync.RWMutex
+	readerInstruments []*instruments
+)
+
+const (
+	adkAppName       = "adk.app.name"
+	adkAgentName     = "adk.agent.name"
+	adkAgentFrom     = "adk.agent.from"
+	adkAgentTo       = "adk.agent.to"
+	metricModel      = "model"
+	metricStatus     = "status"
+	metricTokenType  = "type"
+	metricTool       = "tool"
+	statusOK         = "ok"
+	statusError      = "error"
+	httpMethod       = "http.request.method"
+	httpRoute        = "http.route"
+	httpStatusCode   = "http.response.status_code"
+	tokenTypeInput   = "input"
+	tokenTypeOutput  = "output"
+	tokenTypeCached  = "cached"
+	tokenTypeThought = "thought"
+)
+
+// AddMetricReader makes the metrics recorded from now on available to the
+// reader, in addition to the global meter provider.
+func AddMetricReader(reader sdkmetric.Reader) {
+	addMetricReader(reader)
+}
+
+// addMetricReader adds the reader and returns a function removing it.
+func addMetricReader(reader sdkmetric.Reader) (remove func()) {
+	// Each reader gets its own provider, as a reader can be registered with
+	// one provider only.
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	i := newInstruments(provider.Meter(systemName))
+
+	readersMu.Lock()
+	defer readersMu.Unlock()
+	readerInstruments = append(readerInstruments, i)
+	return func() {
+		readersMu.Lock()
+		defer readersMu.Unlock()
+		readerInstruments = slices.DeleteFunc(readerInstruments, func(other *instruments) bool { return other == i })
+	}
+}
+
+func getInstruments() []*instruments {
+	readersMu.RLock()
+	defer readersMu.RUnlock()
+	return append([]*instruments{globalInstruments()}, readerInstruments...)
+}
+
+// newInstruments creates all ADK instruments. Instrument creation only fails
+// on invalid names, in which case the returned no-op instrument is used.
+//
+// The names are exported to Prometheus with dots replaced by underscores, a
+// _total suffix for counters and a _seconds suffix for durations, e.g.
+// adk_llm_requests_total and adk_llm_latency_seconds.
+func newInstruments(meter metric.Meter) *instruments {
+	i := &instruments{}
+	i.invocations, _ = meter.Int64Counter("adk.invocations",
+		metric.WithDescription("Number of agent invocations."))
+	i.agentErrors, _ = meter.Int64Counter("adk.agent.errors",
+		metric.WithDescription("Number of agent invocations that returned an error."))
+	i.agentRunDuration, _ = meter.Float64Histogram("adk.agent.run.duration",
+		metric.WithDescription("Duration of agent invocations."), metric.WithUnit("s"))
+	i.agentTransfers, _ = meter.Int64Counter("adk.agent.transfers",
+		metric.WithDescription("Number of transfers between agents."))
+	i.llmRequests, _ = meter.Int64Counter("adk.llm.requests",
+		metric.WithDescription("Number of model calls."))
+	i.llmLatency, _ = meter.Float64Histogram("adk.llm.latency",
+		metric.WithDescription("Duration of model calls, until the last response is received."), metric.WithUnit("s"))
+	i.tokens, _ = meter.Int64Counter("adk.tokens",
+		metric.WithDescription("Number of tokens used by model calls."))
+	i.toolCalls, _ = meter.Int64Counter("adk.tool.calls",
+		metric.WithDescription("Number of tool calls."))
+	i.httpRequests, _ = meter.Int64Counter("adk.http.server.requests",
+		metric.WithDescription("Number of HTTP requests served."))
+	i.httpDuration, _ = meter.Float64Histogram("adk.http.server.duration",
+		metric.WithDescription("Duration of HTTP requests."), metric.WithUnit("s"))
+	return i
+}
+
+// status returns the value of the status attribute.
+func status(failed bool) attribute.KeyValue {
+	if failed {
+		return attribute.String(metricStatus, statusError)
+	}
+	return attribute.String(metricStatus, statusOK)
+}
+
+// RecordAgentRun records a finished agent invocation.
+func RecordAgentRun(ctx context.Context, appName, agentName string, duration time.Duration, failed bool) {
+	attrs := metric.WithAttributes(
+		attribute.String(adkAppName, appName),
+		attribute.String(adkAgentName, agentName),
+	)
+	for _, i := range getInstruments() {
+		i.invocations.Add(ctx, 1, attrs)
+		i.agentRunDuration.Record(ctx, duration.Seconds(), attrs)
+		if failed {
+			i.agentErrors.Add(ctx, 1, attrs)
+		}
+	}
+}
+
+// RecordAgentTransfer records a transfer of control from one agent to another.
+func RecordAgentTransfer(ctx context.Context, from, to string) {
+	attrs := metric.WithAttributes(
+		attribute.String(adkAgentFrom, from),
+		attribute.String(adkAgentTo, to),
+	)
+	for _, i := range getInstruments() {
+		i.agentTransfers.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordModelRequest records a finished model call.
+func RecordModelRequest(ctx context.Context, modelName string, duration time.Duration, failed bool) {
+	attrs := metric.WithAttributes(
+		attribute.String(metricModel, modelName),
+		status(failed),
+	)
+	for _, i := range getInstruments() {
+		i.llmRequests.Add(ctx, 1, attrs)
+		i.llmLatency.Record(ctx, duration.Seconds(), attrs)
+	}
+}
+
+// RecordModelUsage records the token usage reported by a model response.
+func RecordModelUsage(ctx context.Context, usage *genai.GenerateContentResponseUsageMetadata) {
+	if usage == nil {
+		return
+	}
+	counts := map[string]int32{
+		tokenTypeInput:   usage.PromptTokenCount,
+		tokenTypeOutput:  usage.CandidatesTokenCount,
+		tokenTypeCached:  usage.CachedContentTokenCount,
+		tokenTypeThought: usage.ThoughtsTokenCount,
+	}
+	for _, i := range getInstruments() {
+		for tokenType, count := range counts {
+			if count == 0 {
+				continue
+			}
+			i.tokens.Add(ctx, int64(count), metric.WithAttributes(attribute.String(metricTokenType, tokenType)))
+		}
+	}
+}
+
+// RecordToolCall records a single tool call.
+func RecordToolCall(ctx context.Context, toolName string, failed bool) {
+	attrs := metric.WithAttributes(
+		attribute.String(metricTool, toolName),
+		status(failed),
+	)
+	for _, i := range getInstruments() {
+		i.toolCalls.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordHTTPRequest records a served HTTP request.
+func RecordHTTPRequest(ctx context.Context, method, route string, statusCode int, duration time.Duration) {
+	attrs := metric.WithAttributes(
+		attribute.String(httpMethod, method),
+		attribute.String(httpRoute, route),
+		attribute.Int(httpStatusCode, statusCode),
+	)
+	for _, i := range getInstruments() {
+		i.httpRequests.Add(ctx, 1, attrs)
+		i.httpDuration.Record(ctx, duration.Seconds(), attrs)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/genai"
+)
+
+// newTestReader returns a reader getting the metrics recorded during the
+// test.
+func newTestReader(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	t.Cleanup(addMetricReader(reader))
+	return reader
+}
+
+// collect returns the values of the counters and the counts of the
+// histograms read by the reader, keyed by metric name and attributes.
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]map[string]float64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	key := func(attrs attribute.Set) string {
+		return attrs.Encoded(attribute.DefaultEncoder())
+	}
+	got := make(map[string]map[string]float64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			values := make(map[string]float64)
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					values[key(dp.Attributes)] = float64(dp.Value)
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					values[key(dp.Attributes)] = dp.Sum
+				}
+			}
+			got[m.Name] = values
+		}
+	}
+	return got
+}
+
+func TestRecordModelRequest(t *testing.T) {
+	reader := newTestReader(t)
+
+	RecordModelRequest(t.Context(), "gemini-2.5-flash", 2*time.Second, false)
+	RecordModelRequest(t.Context(), "gemini-2.5-flash", time.Second, true)
+	RecordModelRequest(t.Context(), "gemini-2.5-flash", 3*time.Second, false)
+
+	got := collect(t, reader)
+	want := map[string]float64{
+		"model=gemini-2.5-flash,status=ok":    2,
+		"model=gemini-2.5-flash,status=error": 1,
+	}
+	if diff := cmp.Diff(want, got["adk.llm.requests"]); diff != "" {
+		t.Errorf("adk.llm.requests mismatch (-want +got):\n%s", diff)
+	}
+	wantLatency := map[string]float64{
+		"model=gemini-2.5-flash,status=ok":    5,
+		"model=gemini-2.5-flash,status=error": 1,
+	}
+	if diff := cmp.Diff(wantLatency, got["adk.llm.latency"]); diff != "" {
+		t.Errorf("adk.llm.latency sum mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecordModelUsage(t *testing.T) {
+	reader := newTestReader(t)
+
+	RecordModelUsage(t.Context(), &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5})
+	RecordModelUsage(t.Context(), &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 7, ThoughtsTokenCount: 3})
+	RecordModelUsage(t.Context(), nil)
+
+	want := map[string]float64{"type=input": 17, "type=output": 5, "type=thought": 3}
+	if diff := cmp.Diff(want, collect(t, reader)["adk.tokens"]); diff != "" {
+		t.Errorf("adk.tokens mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecordToolCall(t *testing.T) {
+	reader := newTestReader(t)
+
+	RecordToolCall(t.Context(), "get_weather", false)
+	RecordToolCall(t.Context(), "get_weather", true)
+
+	want := map[string]float64{"status=ok,tool=get_weather": 1, "status=error,tool=get_weather": 1}
+	if diff := cmp.Diff(want, collect(t, reader)["adk.tool.calls"]); diff != "" {
+		t.Errorf("adk.tool.calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddMetricReader_AfterRecording(t *testing.T) {
+	// Metrics recorded before a reader is added don't prevent it from getting
+	// the metrics recorded later.
+	RecordAgentRun(t.Context(), "app", "agent", time.Second, false)
+	reader := newTestReader(t)
+	RecordAgentRun(t.Context(), "app", "agent", time.Second, true)
+
+	got := collect(t, reader)
+	want := map[string]float64{"adk.agent.name=agent,adk.app.name=app": 1}
+	if diff := cmp.Diff(want, got["adk.invocations"]); diff != "" {
+		t.Errorf("adk.invocations mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, got["adk.agent.errors"]); diff != "" {
+		t.Errorf("adk.agent.errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddMetricReader_Remove(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	remove := addMetricReader(reader)
+	RecordToolCall(t.Context(), "get_weather", false)
+	remove()
+	RecordToolCall(t.Context(), "get_weather", false)
+
+	want := map[string]float64{"status=ok,tool=get_weather": 1}
+	if diff := cmp.Diff(want, collect(t, reader)["adk.tool.calls"]); diff != "" {
+		t.Errorf("adk.tool.calls mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -22,14 +22,14 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"google.golang.org/genai"
 
-	"google.golang.org/adk/agent"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
-	"google.golang.org/adk/tool"
 )
 
 type tracerProviderHolder struct {
@@ -51,13 +51,18 @@ var (
 )
 
 const (
-	systemName            = "gcp.vertex.agent"
-	genAiOperationName    = "gen_ai.operation.name"
-	genAiToolDescription  = "gen_ai.tool.description"
-	genAiToolName         = "gen_ai.tool.name"
-	genAiToolCallID       = "gen_ai.tool.call.id"
-	genAiSystemName       = "gen_ai.system"
-	genAiRequestModelName = "gen_ai.request.model"
+	systemName             = "gcp.vertex.agent"
+	genAiOperationName     = "gen_ai.operation.name"
+	genAiToolDescription   = "gen_ai.tool.description"
+	genAiToolName          = "gen_ai.tool.name"
+	genAiToolCallID        = "gen_ai.tool.call.id"
+	genAiSystemName        = "gen_ai.system"
+	genAiRequestModelName  = "gen_ai.request.model"
+	genAiAgentName         = "gen_ai.agent.name"
+	genAiAgentDescription  = "gen_ai.agent.description"
+	genAiUsageInputTokens  = "gen_ai.usage.input_tokens"
+	genAiUsageOutputTokens = "gen_ai.usage.output_tokens"
+	adkUserID              = "adk.user.id"
 
 	gcpVertexAgentLLMRequestName   = "gcp.vertex.agent.llm_request"
 	gcpVertexAgentToolCallArgsName = "gcp.vertex.agent.tool_call_args"
@@ -66,9 +71,14 @@ const (
 	gcpVertexAgentLLMResponseName  = "gcp.vertex.agent.llm_response"
 	gcpVertexAgentInvocationID     = "gcp.vertex.agent.invocation_id"
 	gcpVertexAgentSessionID        = "gcp.vertex.agent.session_id"
+	gcpVertexAgentCachedContent    = "gcp.vertex.agent.cached_content"
 
 	executeToolName = "execute_tool"
+	invokeAgentName = "invoke_agent"
 	mergeToolName   = "(merged tools)"
+
+	// maxToolCallArgsLength limits the size of the tool call args attribute.
+	maxToolCallArgsLength = 1024
 )
 
 // AddSpanProcessor adds a span processor to the local tracer config.
@@ -82,10 +92,15 @@ func AddSpanProcessor(processor sdktrace.SpanProcessor) {
 // We use local tracer to respect the global tracer configurations.
 func RegisterTelemetry() {
 	once.Do(func() {
-		traceProvider := sdktrace.NewTracerProvider()
 		localTracerConfig.mu.RLock()
 		spanProcessors := localTracerConfig.spanProcessors
 		localTracerConfig.mu.RUnlock()
+		if len(spanProcessors) == 0 {
+			// Nothing would consume the spans, don't record them.
+			localTracer = tracerProviderHolder{tp: noop.NewTracerProvider()}
+			return
+		}
+		traceProvider := sdktrace.NewTracerProvider()
 		for _, processor := range spanProcessors {
 			traceProvider.RegisterSpanProcessor(processor)
 		}
@@ -95,11 +110,10 @@ func RegisterTelemetry() {
 
 // If the global tracer is not set, the default NoopTracerProvider will be used.
 // That means that the spans are NOT recording/exporting
-// If the local tracer is not set, we'll set up tracer with all registered span processors.
+// If the local tracer is not set, we'll set up tracer with all registered span processors,
+// or a no-op tracer if there are none.
 func getTracers() []trace.Tracer {
-	if localTracer.tp == nil {
-		RegisterTelemetry()
-	}
+	RegisterTelemetry()
 	return []trace.Tracer{
 		localTracer.tp.Tracer(systemName),
 		otel.GetTracerProvider().Tracer(systemName),
@@ -107,14 +121,67 @@ func getTracers() []trace.Tracer {
 }
 
 // StartTrace returns two spans to start emitting events, one from global tracer and second from the local.
-func StartTrace(ctx context.Context, traceName string) []trace.Span {
+// The returned context carries the spans, so that spans started from it are
+// their children.
+func StartTrace(ctx context.Context, traceName string) (context.Context, []trace.Span) {
 	tracers := getTracers()
+	parents, _ := ctx.Value(spansKey{}).([]trace.Span)
 	spans := make([]trace.Span, len(tracers))
 	for i, tracer := range tracers {
-		_, span := tracer.Start(ctx, traceName)
+		parentCtx := ctx
+		if i < len(parents) {
+			parentCtx = trace.ContextWithSpan(ctx, parents[i])
+		}
+		_, span := tracer.Start(parentCtx, traceName)
 		spans[i] = span
 	}
-	return spans
+	ctx = context.WithValue(ctx, spansKey{}, spans)
+	// Instrumentation outside of ADK, e.g. of HTTP clients, uses the global tracer.
+	return trace.ContextWithSpan(ctx, spans[len(spans)-1]), spans
+}
+
+// spansKey is the context key of the spans started by StartTrace.
+type spansKey struct{}
+
+// EndTrace records the error, if any, and ends the spans.
+func EndTrace(spans []trace.Span, err error) {
+	for _, span := range spans {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// TraceInvocation fills the invocation details.
+func TraceInvocation(spans []trace.Span, appName, userID, sessionID, invocationID string) {
+	for _, span := range spans {
+		if !span.IsRecording() {
+			continue
+		}
+		span.SetAttributes(
+			attribute.String(adkAppName, appName),
+			attribute.String(adkUserID, userID),
+			attribute.String(gcpVertexAgentSessionID, sessionID),
+			attribute.String(gcpVertexAgentInvocationID, invocationID),
+		)
+	}
+}
+
+// TraceAgentRun fills the agent_run details.
+func TraceAgentRun(spans []trace.Span, agentName, agentDescription, invocationID string) {
+	for _, span := range spans {
+		if !span.IsRecording() {
+			continue
+		}
+		span.SetAttributes(
+			attribute.String(genAiOperationName, invokeAgentName),
+			attribute.String(genAiAgentName, agentName),
+			attribute.String(genAiAgentDescription, agentDescription),
+			attribute.String(gcpVertexAgentInvocationID, invocationID),
+		)
+	}
 }
 
 // TraceMergedToolCalls traces the tool execution events.
@@ -123,6 +190,10 @@ func TraceMergedToolCalls(spans []trace.Span, fnResponseEvent *session.Event) {
 		return
 	}
 	for _, span := range spans {
+		if !span.IsRecording() {
+			span.End()
+			continue
+		}
 		attributes := []attribute.KeyValue{
 			attribute.String(genAiOperationName, executeToolName),
 			attribute.String(genAiToolName, mergeToolName),
@@ -141,22 +212,27 @@ func TraceMergedToolCalls(spans []trace.Span, fnResponseEvent *session.Event) {
 }
 
 // TraceToolCall traces the tool execution events.
-func TraceToolCall(spans []trace.Span, tool tool.Tool, fnArgs map[string]any, fnResponseEvent *session.Event) {
+func TraceToolCall(spans []trace.Span, toolName, toolDescription string, fnArgs map[string]any, fnResponseEvent *session.Event) {
 	if fnResponseEvent == nil {
 		return
 	}
 	for _, span := range spans {
+		if !span.IsRecording() {
+			span.End()
+			continue
+		}
 		attributes := []attribute.KeyValue{
 			attribute.String(genAiOperationName, executeToolName),
-			attribute.String(genAiToolName, tool.Name()),
-			attribute.String(genAiToolDescription, tool.Description()),
+			attribute.String(genAiToolName, toolName),
+			attribute.String(genAiToolDescription, toolDescription),
 			// TODO: add tool type
 
 			// Setting empty llm request and response (as UI expect these) while not
 			// applicable for tool_response.
 			attribute.String(gcpVertexAgentLLMRequestName, "{}"),
 			attribute.String(gcpVertexAgentLLMRequestName, "{}"),
-			attribute.String(gcpVertexAgentToolCallArgsName, safeSerialize(fnArgs)),
+			attribute.String(genAiAgentName, fnResponseEvent.Author),
+			attribute.String(gcpVertexAgentToolCallArgsName, truncate(safeSerialize(fnArgs), maxToolCallArgsLength)),
 			attribute.String(gcpVertexAgentEventID, fnResponseEvent.ID),
 		}
 
@@ -187,14 +263,19 @@ func TraceToolCall(spans []trace.Span, tool tool.Tool, fnArgs map[string]any, fn
 	}
 }
 
-// TraceLLMCall fills the call_llm event details.
-func TraceLLMCall(spans []trace.Span, agentCtx agent.InvocationContext, llmRequest *model.LLMRequest, event *session.Event) {
+// TraceLLMCall fills the call_llm event details. It is called for each
+// response of the model, the spans are ended by the caller with [EndTrace].
+func TraceLLMCall(spans []trace.Span, sessionID, modelName string, llmRequest *model.LLMRequest, event *session.Event) {
 	for _, span := range spans {
+		if !span.IsRecording() {
+			continue
+		}
 		attributes := []attribute.KeyValue{
 			attribute.String(genAiSystemName, systemName),
-			attribute.String(genAiRequestModelName, llmRequest.Model),
+			attribute.String(genAiRequestModelName, modelName),
+			attribute.String(genAiAgentName, event.Author),
 			attribute.String(gcpVertexAgentInvocationID, event.InvocationID),
-			attribute.String(gcpVertexAgentSessionID, agentCtx.Session().ID()),
+			attribute.String(gcpVertexAgentSessionID, sessionID),
 			attribute.String(gcpVertexAgentEventID, event.ID),
 			attribute.String(gcpVertexAgentLLMRequestName, safeSerialize(llmRequestToTrace(llmRequest))),
 			attribute.String(gcpVertexAgentLLMResponseName, safeSerialize(event.LLMResponse)),
@@ -208,13 +289,32 @@ func TraceLLMCall(spans []trace.Span, agentCtx agent.InvocationContext, llmReque
 			attributes = append(attributes, attribute.Int("gen_ai.request.max_tokens", int(llmRequest.Config.MaxOutputTokens)))
 		}
 
-		// TODO: add usage_metadata and finish_reason once ADK has them.
+		// Set by models using context caching, e.g. gemini.WithContextCache.
+		if name, ok := event.LLMResponse.CustomMetadata["cached_content"].(string); ok {
+			attributes = append(attributes, attribute.String(gcpVertexAgentCachedContent, name))
+		}
+
+		if usage := event.LLMResponse.UsageMetadata; usage != nil {
+			attributes = append(attributes,
+				attribute.Int(genAiUsageInputTokens, int(usage.PromptTokenCount)),
+				attribute.Int(genAiUsageOutputTokens, int(usage.CandidatesTokenCount)),
+			)
+		}
+
+		// TODO: add finish_reason once ADK has it.
 
 		span.SetAttributes(attributes...)
-		span.End()
 	}
 }
 
+// truncate shortens s to at most n bytes, marking it as truncated.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...(truncated)"
+}
+
 func safeSerialize(obj any) string {
 	dump, err := json.Marshal(obj)
 	if err != nil {
@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The tests don't register span processors, so the local tracer is a no-op.
+
+func TestStartTrace_NoopWithoutTracer(t *testing.T) {
+	ctx, spans := StartTrace(t.Context(), "invocation")
+	for _, span := range spans {
+		if span.IsRecording() {
+			t.Errorf("span %v is recording, want a no-op span without configured tracers", span)
+		}
+	}
+	_, childSpans := StartTrace(ctx, "agent_run")
+	TraceAgentRun(childSpans, "agent", "description", "invocation_id")
+	EndTrace(childSpans, nil)
+	EndTrace(spans, nil)
+}
+
+func TestStartTrace_Nesting(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prevProvider) })
+
+	ctx, parent := StartTrace(t.Context(), "parent")
+	childCtx, child := StartTrace(ctx, "child")
+	EndTrace(child, errors.New("failed"))
+	EndTrace(parent, nil)
+
+	if got := trace.SpanFromContext(childCtx).SpanContext(); !got.Equal(child[len(child)-1].SpanContext()) {
+		t.Errorf("context carries span %v, want the global child span", got)
+	}
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(ended))
+	}
+	childSpan, parentSpan := ended[0], ended[1]
+	if childSpan.Parent().SpanID() != parentSpan.SpanContext().SpanID() {
+		t.Errorf("child span parent = %v, want %v", childSpan.Parent().SpanID(), parentSpan.SpanContext().SpanID())
+	}
+	if childSpan.Status().Description != "failed" {
+		t.Errorf("child span status = %v, want the error", childSpan.Status())
+	}
+}
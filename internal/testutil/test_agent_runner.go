@@ -20,59 +20,33 @@ import (
 	"fmt"
 	"iter"
 	"testing"
+	"time"
 
 	"google.golang.org/genai"
 
+	"google.golang.org/adk"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/model"
-	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 )
 
 type TestAgentRunner struct {
-	agent            agent.Agent
-	sessionService   session.Service
-	lastSession      session.Session
-	initSessionState map[string]any
-	appName          string
-	// TODO: move runner definition to the adk package and it's a part of public api, but the logic to the internal runner
-	runner *runner.Runner
-}
-
-func (r *TestAgentRunner) session(t *testing.T, appName, userID, sessionID string) (session.Session, error) {
-	ctx := t.Context()
-	if last := r.lastSession; last != nil && last.ID() == sessionID {
-		resp, err := r.sessionService.Get(ctx, &session.GetRequest{
-			AppName:   "test_app",
-			UserID:    "test_user",
-			SessionID: sessionID,
-		})
-		r.lastSession = resp.Session
-		return resp.Session, err
-	}
-	resp, err := r.sessionService.Create(ctx, &session.CreateRequest{
-		AppName:   "test_app",
-		UserID:    "test_user",
-		SessionID: sessionID,
-		State:     r.initSessionState,
-	})
-	r.lastSession = resp.Session
-	return resp.Session, err
+	agent          agent.Agent
+	sessionService session.Service
+	appName        string
+	runner         *adk.Runner
 }
 
-func (r *TestAgentRunner) SetInitSessionState(state map[string]any) {
-	r.initSessionState = state
+// SetInitSessionState sets the state of sessions created afterwards.
+func (r *TestAgentRunner) SetInitSessionState(t *testing.T, state map[string]any) {
+	t.Helper()
+	r.runner = newRunner(t, r.appName, r.agent, r.sessionService, state)
 }
 
 func (r *TestAgentRunner) Run(t *testing.T, sessionID, newMessage string) iter.Seq2[*session.Event, error] {
 	t.Helper()
-
-	var content *genai.Content
-	if newMessage != "" {
-		content = genai.NewContentFromText(newMessage, genai.RoleUser)
-	}
-	return r.RunContent(t, sessionID, content)
+	return r.runner.RunText(t.Context(), "test_user", sessionID, newMessage)
 }
 
 func (r *TestAgentRunner) RunContent(t *testing.T, sessionID string, content *genai.Content) iter.Seq2[*session.Event, error] {
@@ -82,45 +56,49 @@ func (r *TestAgentRunner) RunContent(t *testing.T, sessionID string, content *ge
 
 func (r *TestAgentRunner) RunContentWithConfig(t *testing.T, sessionID string, content *genai.Content, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
 	t.Helper()
-	ctx := t.Context()
-
-	userID := "test_user"
-
-	session, err := r.session(t, r.appName, userID, sessionID)
-	if err != nil {
-		t.Fatalf("failed to get/create session: %v", err)
-	}
-
-	return r.runner.Run(ctx, userID, session.ID(), content, cfg)
+	return r.runner.Run(t.Context(), "test_user", sessionID, content, cfg)
 }
 
-// NewTestAgentRunner creates a new TestAgentRunner for the given agent as root
-// initSessionState will be used to init all sessions created by this runner.
+// NewTestAgentRunner creates a new TestAgentRunner for the given agent as root.
 func NewTestAgentRunner(t *testing.T, agent agent.Agent) *TestAgentRunner {
 	appName := "test_app"
 	sessionService := session.InMemoryService()
 
-	runner, err := runner.New(runner.Config{
+	return &TestAgentRunner{
+		agent:          agent,
+		sessionService: sessionService,
+		appName:        appName,
+		runner:         newRunner(t, appName, agent, sessionService, nil),
+	}
+}
+
+func newRunner(t *testing.T, appName string, agent agent.Agent, sessionService session.Service, state map[string]any) *adk.Runner {
+	t.Helper()
+	r, err := adk.NewRunner(adk.Config{
 		AppName:        appName,
 		Agent:          agent,
 		SessionService: sessionService,
+		InitialState:   state,
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	return &TestAgentRunner{
-		agent:          agent,
-		sessionService: sessionService,
-		appName:        appName,
-		runner:         runner,
-	}
+	return r
 }
 
 type MockModel struct {
 	Requests             []*model.LLMRequest
 	Responses            []*genai.Content
 	StreamResponsesCount int
+	// StreamChunks, if greater than 1, makes GenerateStream split the text
+	// of every part of a streamed response into that many partial deltas,
+	// streamed one per chunk and followed by the aggregated response.
+	StreamChunks int
+	// UsageMetadata, if set, is reported with every response.
+	UsageMetadata *genai.GenerateContentResponseUsageMetadata
+	// Delay, if set, is waited before every response, unless the context is
+	// done earlier, in which case the context error is returned.
+	Delay time.Duration
 }
 
 var errNoModelData = errors.New("no data")
@@ -138,12 +116,16 @@ func (m *MockModel) GenerateContent(ctx context.Context, req *model.LLMRequest,
 // GenerateContent implements llm.Model.
 func (m *MockModel) Generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
 	m.Requests = append(m.Requests, req)
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
 	if len(m.Responses) == 0 {
 		return nil, errNoModelData
 	}
 
 	resp := &model.LLMResponse{
-		Content: m.Responses[0],
+		Content:       m.Responses[0],
+		UsageMetadata: m.UsageMetadata,
 	}
 
 	m.Responses = m.Responses[1:]
@@ -162,11 +144,18 @@ func (m *MockModel) GenerateStream(ctx context.Context, req *model.LLMRequest) i
 			if len(m.Responses) == 0 {
 				break
 			}
-			resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: m.Responses[0]}}}
+			if err := m.wait(ctx); err != nil {
+				yield(nil, err)
+				return
+			}
+			chunks := splitContent(m.Responses[0], m.StreamChunks)
 			m.Responses = m.Responses[1:]
-			for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
-				if !yield(llmResponse, err) {
-					return // Consumer stopped
+			for _, chunk := range chunks {
+				resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: chunk}}, UsageMetadata: m.UsageMetadata}
+				for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
+					if !yield(llmResponse, err) {
+						return // Consumer stopped
+					}
 				}
 			}
 		}
@@ -176,6 +165,45 @@ func (m *MockModel) GenerateStream(ctx context.Context, req *model.LLMRequest) i
 	}
 }
 
+// splitContent splits the text of every part of the content into n deltas,
+// returning a content per delta. Other parts are returned in their own
+// content. The content is returned as is if n is less than 2.
+func splitContent(content *genai.Content, n int) []*genai.Content {
+	if n < 2 || content == nil {
+		return []*genai.Content{content}
+	}
+	var chunks []*genai.Content
+	for _, part := range content.Parts {
+		if part == nil || part.Text == "" {
+			chunks = append(chunks, &genai.Content{Role: content.Role, Parts: []*genai.Part{part}})
+			continue
+		}
+		text := []rune(part.Text)
+		for i := range n {
+			delta := string(text[i*len(text)/n : (i+1)*len(text)/n])
+			if delta == "" {
+				continue
+			}
+			chunk := *part
+			chunk.Text = delta
+			chunks = append(chunks, &genai.Content{Role: content.Role, Parts: []*genai.Part{&chunk}})
+		}
+	}
+	return chunks
+}
+
+func (m *MockModel) wait(ctx context.Context) error {
+	if m.Delay == 0 {
+		return nil
+	}
+	select {
+	case <-time.After(m.Delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Name implements llm.Model.
 func (m *MockModel) Name() string {
 	return "mock"
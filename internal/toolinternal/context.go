@@ -22,6 +22,7 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/auth"
 	contextinternal "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
@@ -48,6 +49,11 @@ func (ia *internalArtifacts) Save(ctx context.Context, name string, data *genai.
 	return resp, nil
 }
 
+// Unwrap returns the artifacts of the invocation.
+func (ia *internalArtifacts) Unwrap() agent.Artifacts {
+	return ia.Artifacts
+}
+
 func NewToolContext(ctx agent.InvocationContext, functionCallID string, actions *session.EventActions) tool.Context {
 	if functionCallID == "" {
 		functionCallID = uuid.NewString()
@@ -78,6 +84,7 @@ type toolContext struct {
 	functionCallID    string
 	eventActions      *session.EventActions
 	artifacts         *internalArtifacts
+	emit              func(*session.Event)
 }
 
 func (c *toolContext) Artifacts() agent.Artifacts {
@@ -99,3 +106,59 @@ func (c *toolContext) AgentName() string {
 func (c *toolContext) SearchMemory(ctx context.Context, query string) (*memory.SearchResponse, error) {
 	return c.invocationContext.Memory().Search(ctx, query)
 }
+
+func (c *toolContext) RequestCredential(cfg *auth.Config) {
+	if c.eventActions.RequestedAuthConfigs == nil {
+		c.eventActions.RequestedAuthConfigs = make(map[string]*auth.Config)
+	}
+	c.eventActions.RequestedAuthConfigs[c.functionCallID] = cfg
+}
+
+func (c *toolContext) Credential(cfg *auth.Config) *auth.Credential {
+	value, err := c.State().Get(CredentialStateKey(cfg))
+	if err != nil {
+		return nil
+	}
+	cred, _ := value.(*auth.Credential)
+	return cred
+}
+
+// InvocationMemory returns the memory of the invocation in which the tool
+// runs, or nil if the invocation has no memory or ctx isn't a context
+// created by NewToolContext.
+func InvocationMemory(ctx tool.Context) agent.Memory {
+	c, ok := ctx.(*toolContext)
+	if !ok {
+		return nil
+	}
+	return c.invocationContext.Memory()
+}
+
+// WithEventEmitter makes EmitEvent pass the events of the tool running with
+// ctx, created by NewToolContext, to emit. It returns ctx.
+func WithEventEmitter(ctx tool.Context, emit func(*session.Event)) tool.Context {
+	if c, ok := ctx.(*toolContext); ok {
+		c.emit = emit
+	}
+	return ctx
+}
+
+// EmitEvent adds the event to the event stream of the invocation in which the
+// tool runs, before the function response of the tool. It reports whether the
+// event was emitted, which is not the case if ctx has no event emitter, see
+// WithEventEmitter.
+func EmitEvent(ctx tool.Context, event *session.Event) bool {
+	c, ok := ctx.(*toolContext)
+	if !ok || c.emit == nil {
+		return false
+	}
+	c.emit(event)
+	return true
+}
+
+// CredentialStateKey returns the session state key of the credential
+// described by cfg. The credentials are kept in the temporary state, so they
+// are available only in the invocation in which the client provided them.
+func CredentialStateKey(cfg *auth.Config) string {
+	return session.KeyPrefixTemp + cfg.Key()
+}
@@ -0,0 +1,262 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/genai"
+)
+
+// Cache stores encoded LLM responses by the key of the request.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for the key. It returns false if the key
+	// is not in the cache.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Put stores the value for the key, overwriting any existing value.
+	Put(ctx context.Context, key string, value []byte) error
+}
+
+// CachingModel is an [LLM] which serves identical requests from a [Cache]
+// instead of calling the wrapped model. It is meant for evaluation runs and
+// deterministic demos.
+//
+// Requests are keyed by the model name, the contents and the generation
+// config, which includes the system instruction and the tool declarations.
+// Responses are cached only if the wrapped model didn't return an error.
+// In the streaming mode partial responses are not cached, so a cached
+// response is replayed as the final aggregated response only.
+type CachingModel struct {
+	inner LLM
+	cache Cache
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingModel returns a [CachingModel] wrapping the inner model.
+func NewCachingModel(inner LLM, cache Cache) *CachingModel {
+	return &CachingModel{inner: inner, cache: cache}
+}
+
+// Name returns the name of the wrapped model.
+func (m *CachingModel) Name() string {
+	return m.inner.Name()
+}
+
+// Hits returns the number of requests served from the cache.
+func (m *CachingModel) Hits() int64 {
+	return m.hits.Load()
+}
+
+// Misses returns the number of requests passed to the wrapped model.
+func (m *CachingModel) Misses() int64 {
+	return m.misses.Load()
+}
+
+// GenerateContent returns the cached responses for the request if present,
+// otherwise it calls the wrapped model and caches its responses.
+func (m *CachingModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		key, err := cacheKey(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		value, ok, err := m.cache.Get(ctx, key)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to read from cache: %w", err))
+			return
+		}
+		if ok {
+			var resps []*LLMResponse
+			if err := json.Unmarshal(value, &resps); err != nil {
+				yield(nil, fmt.Errorf("failed to decode cached responses: %w", err))
+				return
+			}
+			m.hits.Add(1)
+			for _, resp := range resps {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			return
+		}
+
+		m.misses.Add(1)
+		// Responses are encoded before they are yielded, as callers may modify them.
+		var encoded []json.RawMessage
+		for resp, err := range m.inner.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				yield(resp, err)
+				return
+			}
+			if !resp.Partial {
+				b, err := json.Marshal(resp)
+				if err != nil {
+					yield(nil, fmt.Errorf("failed to encode response: %w", err))
+					return
+				}
+				encoded = append(encoded, b)
+			}
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		if len(encoded) == 0 {
+			return
+		}
+		value, err = json.Marshal(encoded)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to encode responses: %w", err))
+			return
+		}
+		if err := m.cache.Put(ctx, key, value); err != nil {
+			yield(nil, fmt.Errorf("failed to write to cache: %w", err))
+		}
+	}
+}
+
+// cacheKey returns the hash of the parts of the request affecting the response.
+func cacheKey(req *LLMRequest) (string, error) {
+	b, err := json.Marshal(struct {
+		Model    string                       `json:"model"`
+		Contents []*genai.Content             `json:"contents"`
+		Config   *genai.GenerateContentConfig `json:"config"`
+	}{req.Model, req.Contents, req.Config})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cache key: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NewInMemoryCache returns a [Cache] keeping up to size most recently used
+// entries in memory.
+func NewInMemoryCache(size int) (Cache, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("cache size must be positive, got %d", size)
+	}
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}, nil
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+}
+
+func (c *lruCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true, nil
+}
+
+func (c *lruCache) Put(ctx context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.order.MoveToFront(e)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// NewFileCache returns a [Cache] storing each entry as a file in the given
+// directory, which is created if it doesn't exist. The cache can be shared
+// between runs, e.g. checked in together with evaluation sets.
+func NewFileCache(dir string) (Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &fileCache{dir: dir}, nil
+}
+
+type fileCache struct {
+	dir string
+}
+
+func (c *fileCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *fileCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := os.ReadFile(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+func (c *fileCache) Put(ctx context.Context, key string, value []byte) error {
+	// Write to a temporary file first, so that concurrent readers never see partial entries.
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"iter"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// countingModel replies with the number of the call, streamed as two partial
+// responses followed by the aggregated one in the streaming mode.
+type countingModel struct {
+	calls int
+}
+
+func (m *countingModel) Name() string {
+	return "counting-model"
+}
+
+func (m *countingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	m.calls++
+	n := strconv.Itoa(m.calls)
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if stream {
+			for _, text := range []string{"answer ", n} {
+				if !yield(&model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel), Partial: true}, nil) {
+					return
+				}
+			}
+		}
+		yield(&model.LLMResponse{
+			Content:      genai.NewContentFromText("answer "+n, genai.RoleModel),
+			TurnComplete: true,
+		}, nil)
+	}
+}
+
+func newRequest(instruction string) *model.LLMRequest {
+	return &model.LLMRequest{
+		Model:    "counting-model",
+		Contents: []*genai.Content{genai.NewContentFromText("question", genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(instruction, genai.RoleUser),
+			Tools:             []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "lookup"}}}},
+		},
+	}
+}
+
+func generate(t *testing.T, m model.LLM, req *model.LLMRequest, stream bool) []*model.LLMResponse {
+	t.Helper()
+	var resps []*model.LLMResponse
+	for resp, err := range m.GenerateContent(t.Context(), req, stream) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		resps = append(resps, resp)
+	}
+	return resps
+}
+
+func TestCachingModel(t *testing.T) {
+	newFileCache := func(t *testing.T) model.Cache {
+		c, err := model.NewFileCache(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewFileCache() error = %v", err)
+		}
+		return c
+	}
+	newInMemoryCache := func(t *testing.T) model.Cache {
+		c, err := model.NewInMemoryCache(10)
+		if err != nil {
+			t.Fatalf("NewInMemoryCache() error = %v", err)
+		}
+		return c
+	}
+
+	for name, newCache := range map[string]func(t *testing.T) model.Cache{
+		"in memory": newInMemoryCache,
+		"file":      newFileCache,
+	} {
+		t.Run(name, func(t *testing.T) {
+			inner := &countingModel{}
+			m := model.NewCachingModel(inner, newCache(t))
+
+			first := generate(t, m, newRequest("Be brief."), false)
+			second := generate(t, m, newRequest("Be brief."), false)
+			if inner.calls != 1 {
+				t.Errorf("inner model called %d times for identical requests, want 1", inner.calls)
+			}
+			if diff := cmp.Diff(first, second); diff != "" {
+				t.Errorf("cached responses mismatch (-want +got):\n%s", diff)
+			}
+
+			generate(t, m, newRequest("Be verbose."), false)
+			if inner.calls != 2 {
+				t.Errorf("inner model called %d times after changing the instruction, want 2", inner.calls)
+			}
+
+			if m.Hits() != 1 || m.Misses() != 2 {
+				t.Errorf("hits = %d, misses = %d, want 1 and 2", m.Hits(), m.Misses())
+			}
+		})
+	}
+}
+
+func TestCachingModel_Stream(t *testing.T) {
+	cache, err := model.NewInMemoryCache(10)
+	if err != nil {
+		t.Fatalf("NewInMemoryCache() error = %v", err)
+	}
+	inner := &countingModel{}
+	m := model.NewCachingModel(inner, cache)
+
+	if got := generate(t, m, newRequest("Be brief."), true); len(got) != 3 {
+		t.Fatalf("got %d responses from the model, want 3", len(got))
+	}
+	got := generate(t, m, newRequest("Be brief."), true)
+	want := []*model.LLMResponse{{
+		Content:      genai.NewContentFromText("answer 1", genai.RoleModel),
+		TurnComplete: true,
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("replayed responses mismatch (-want +got):\n%s", diff)
+	}
+	if inner.calls != 1 {
+		t.Errorf("inner model called %d times, want 1", inner.calls)
+	}
+}
+
+func TestInMemoryCache_Eviction(t *testing.T) {
+	ctx := t.Context()
+	c, err := model.NewInMemoryCache(2)
+	if err != nil {
+		t.Fatalf("NewInMemoryCache() error = %v", err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+	// Using "a" makes "b" the least recently used entry.
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatalf("Get(%q) missed", "a")
+	}
+	if err := c.Put(ctx, "c", []byte("c")); err != nil {
+		t.Fatalf("Put(%q) error = %v", "c", err)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestFileCache_Persistent(t *testing.T) {
+	dir := t.TempDir()
+	inner := &countingModel{}
+	for range 2 {
+		c, err := model.NewFileCache(dir)
+		if err != nil {
+			t.Fatalf("NewFileCache() error = %v", err)
+		}
+		generate(t, model.NewCachingModel(inner, c), newRequest("Be brief."), false)
+	}
+	if inner.calls != 1 {
+		t.Errorf("inner model called %d times with a shared cache directory, want 1", inner.calls)
+	}
+}
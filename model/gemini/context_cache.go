@@ -0,0 +1,206 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// cachedContentMetadataKey is the key of model.LLMResponse.CustomMetadata
+// holding the name of the cached content used for the request.
+const cachedContentMetadataKey = "cached_content"
+
+// WithContextCache makes the model use Gemini explicit context caching for
+// the static part of requests: the system instruction, the tools and the
+// contents configured in cfg. The cached content is created on the first
+// request, its TTL is extended automatically while it is used, and it is
+// replaced when the static part of requests changes, e.g. the instruction.
+//
+// If the model doesn't support caching, e.g. the static part is smaller
+// than the minimal cacheable size, requests are sent without the cache.
+func WithContextCache(cfg ContextCacheConfig) Option {
+	return func(o *options) {
+		o.contextCache = &cfg
+	}
+}
+
+// ContextCacheConfig controls the context caching of the model.
+type ContextCacheConfig struct {
+	// TTL of the cached content. It is extended when less than half of it
+	// remains. Defaults to 1 hour.
+	TTL time.Duration
+	// Contents are static contents, e.g. large files, prepended to the
+	// conversation of every request and cached together with the system
+	// instruction.
+	Contents []*genai.Content
+}
+
+const defaultCacheTTL = time.Hour
+
+// contextCache tracks the cached content of the static part of requests.
+type contextCache struct {
+	ttl      time.Duration
+	contents []*genai.Content
+	now      func() time.Time
+
+	mu         sync.Mutex
+	key        string
+	name       string
+	expireTime time.Time
+	// unsupported holds the keys for which the cached content can't be created.
+	unsupported map[string]bool
+}
+
+func newContextCache(cfg ContextCacheConfig) (*contextCache, error) {
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("context cache TTL can't be negative")
+	}
+	ttl := cfg.TTL
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	return &contextCache{
+		ttl:         ttl,
+		contents:    cfg.Contents,
+		now:         time.Now,
+		unsupported: make(map[string]bool),
+	}, nil
+}
+
+// apply returns the contents and the config of the request to send. If the
+// cached content is available, the static part of the request is replaced
+// with the reference to it and its name is returned.
+func (c *contextCache) apply(ctx context.Context, client *genai.Client, modelName string, contents []*genai.Content, cfg *genai.GenerateContentConfig) ([]*genai.Content, *genai.GenerateContentConfig, string) {
+	uncached := append(append([]*genai.Content{}, c.contents...), contents...)
+	if cfg.CachedContent != "" {
+		// The caller manages the cached content.
+		return contents, cfg, cfg.CachedContent
+	}
+
+	name, err := c.cachedContent(ctx, client, modelName, cfg)
+	if err != nil {
+		slog.WarnContext(ctx, "Context caching is not used", slog.String("model", modelName), slog.Any("error", err))
+	}
+	if name == "" {
+		return uncached, cfg, ""
+	}
+
+	cached := *cfg
+	cached.SystemInstruction = nil
+	cached.Tools = nil
+	cached.ToolConfig = nil
+	cached.CachedContent = name
+	return contents, &cached, name
+}
+
+// cachedContent returns the name of the cached content for the static part
+// of the request config, creating or refreshing it if needed. It returns an
+// empty name if caching is not possible.
+func (c *contextCache) cachedContent(ctx context.Context, client *genai.Client, modelName string, cfg *genai.GenerateContentConfig) (string, error) {
+	key, err := c.cacheKey(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key == c.key && c.name != "" {
+		remaining := c.expireTime.Sub(c.now())
+		if remaining > c.ttl/2 {
+			return c.name, nil
+		}
+		if remaining > 0 {
+			updated, err := client.Caches.Update(ctx, c.name, &genai.UpdateCachedContentConfig{
+				HTTPOptions: cfg.HTTPOptions,
+				TTL:         c.ttl,
+			})
+			if err == nil {
+				c.expireTime = c.expiry(updated)
+				return c.name, nil
+			}
+			slog.WarnContext(ctx, "Failed to extend TTL of cached content", slog.String("cached_content", c.name), slog.Any("error", err))
+		}
+		// The cached content expired, or it's about to expire, create a new one.
+	}
+
+	if c.name != "" {
+		if key != c.key {
+			// The static part of requests changed, the cached content won't be used anymore.
+			if _, err := client.Caches.Delete(ctx, c.name, &genai.DeleteCachedContentConfig{HTTPOptions: cfg.HTTPOptions}); err != nil {
+				slog.WarnContext(ctx, "Failed to delete cached content", slog.String("cached_content", c.name), slog.Any("error", err))
+			}
+		}
+		c.key, c.name, c.expireTime = "", "", time.Time{}
+	}
+
+	if c.unsupported[key] {
+		return "", nil
+	}
+
+	created, err := client.Caches.Create(ctx, modelName, &genai.CreateCachedContentConfig{
+		HTTPOptions:       cfg.HTTPOptions,
+		TTL:               c.ttl,
+		Contents:          c.contents,
+		SystemInstruction: cfg.SystemInstruction,
+		Tools:             cfg.Tools,
+		ToolConfig:        cfg.ToolConfig,
+	})
+	if err != nil {
+		// Client errors, e.g. a model not supporting caching or too little content
+		// to cache, won't go away, so the creation isn't retried for this key.
+		var apiErr genai.APIError
+		if errors.As(err, &apiErr) && apiErr.Code >= http.StatusBadRequest && apiErr.Code < http.StatusInternalServerError && apiErr.Code != http.StatusTooManyRequests {
+			c.unsupported[key] = true
+		}
+		return "", fmt.Errorf("failed to create cached content: %w", err)
+	}
+
+	c.key, c.name, c.expireTime = key, created.Name, c.expiry(created)
+	return c.name, nil
+}
+
+func (c *contextCache) expiry(cc *genai.CachedContent) time.Time {
+	if cc.ExpireTime.IsZero() {
+		return c.now().Add(c.ttl)
+	}
+	return cc.ExpireTime
+}
+
+// cacheKey returns the hash of the static part of the request.
+func (c *contextCache) cacheKey(cfg *genai.GenerateContentConfig) (string, error) {
+	b, err := json.Marshal(struct {
+		SystemInstruction *genai.Content    `json:"systemInstruction"`
+		Tools             []*genai.Tool     `json:"tools"`
+		ToolConfig        *genai.ToolConfig `json:"toolConfig"`
+		Contents          []*genai.Content  `json:"contents"`
+	}{cfg.SystemInstruction, cfg.Tools, cfg.ToolConfig, c.contents})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute context cache key: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// cacheBackend is a http.RoundTripper serving the cachedContents and
+// generateContent methods of the Gemini API.
+type cacheBackend struct {
+	// createStatus, if set, is the status code of failing create requests.
+	createStatus int
+
+	mu       sync.Mutex
+	created  int
+	calls    []string         // method and path of requests
+	generate []map[string]any // bodies of generateContent requests
+}
+
+func (b *cacheBackend) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := req.URL.Path[strings.Index(req.URL.Path, "/v1beta/")+len("/v1beta/"):]
+	call := req.Method + " " + path
+	b.calls = append(b.calls, call)
+
+	var body map[string]any
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch {
+	case call == "POST cachedContents":
+		if b.createStatus != 0 {
+			return newResponse(req, b.createStatus, errorBody(b.createStatus)), nil
+		}
+		b.created++
+		return newResponse(req, http.StatusOK, fmt.Sprintf(`{"name": "cachedContents/cache%d"}`, b.created)), nil
+	case req.Method == http.MethodPatch:
+		return newResponse(req, http.StatusOK, fmt.Sprintf(`{"name": %q}`, path)), nil
+	case req.Method == http.MethodDelete:
+		return newResponse(req, http.StatusOK, `{}`), nil
+	case strings.HasSuffix(path, ":streamGenerateContent"):
+		b.generate = append(b.generate, body)
+		return newResponse(req, http.StatusOK, "data: "+okResponse+"\n\n"), nil
+	case strings.HasSuffix(path, ":generateContent"):
+		b.generate = append(b.generate, body)
+		return newResponse(req, http.StatusOK, okResponse), nil
+	}
+	return newResponse(req, http.StatusNotFound, errorBody(http.StatusNotFound)), nil
+}
+
+func (b *cacheBackend) takeCalls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	calls := b.calls
+	b.calls = nil
+	return calls
+}
+
+func newCachingModel(t *testing.T, backend *cacheBackend, cfg ContextCacheConfig) *geminiModel {
+	t.Helper()
+	m, err := NewModel(t.Context(), "gemini-2.5-flash", &genai.ClientConfig{
+		HTTPClient: &http.Client{Transport: backend},
+		APIKey:     "fakekey",
+		Backend:    genai.BackendGeminiAPI,
+	}, WithContextCache(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.(*geminiModel)
+}
+
+func cacheRequest(instruction string) *model.LLMRequest {
+	return &model.LLMRequest{
+		Contents: genai.Text("What is in the document?"),
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(instruction, genai.RoleUser),
+		},
+	}
+}
+
+func generateCached(t *testing.T, m model.LLM, req *model.LLMRequest, stream bool) string {
+	t.Helper()
+	var cachedContent string
+	for resp, err := range m.GenerateContent(t.Context(), req, stream) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		name, _ := resp.CustomMetadata[cachedContentMetadataKey].(string)
+		cachedContent = name
+	}
+	return cachedContent
+}
+
+func TestContextCache(t *testing.T) {
+	document := genai.NewContentFromText("A very long document.", genai.RoleUser)
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			backend := &cacheBackend{}
+			m := newCachingModel(t, backend, ContextCacheConfig{TTL: time.Hour, Contents: []*genai.Content{document}})
+			now := time.Now()
+			m.cache.now = func() time.Time { return now }
+			generateMethod := "models/gemini-2.5-flash:generateContent"
+			if stream {
+				generateMethod = "models/gemini-2.5-flash:streamGenerateContent"
+			}
+
+			if got := generateCached(t, m, cacheRequest("Summarize the document."), stream); got != "cachedContents/cache1" {
+				t.Errorf("cached content of the first response = %q, want %q", got, "cachedContents/cache1")
+			}
+			if got := generateCached(t, m, cacheRequest("Summarize the document."), stream); got != "cachedContents/cache1" {
+				t.Errorf("cached content of the second response = %q, want %q", got, "cachedContents/cache1")
+			}
+			want := []string{"POST cachedContents", "POST " + generateMethod, "POST " + generateMethod}
+			if diff := cmp.Diff(want, backend.takeCalls()); diff != "" {
+				t.Errorf("calls with the same instruction mismatch (-want +got):\n%s", diff)
+			}
+			for _, body := range backend.generate {
+				if body["cachedContent"] != "cachedContents/cache1" || body["systemInstruction"] != nil {
+					t.Errorf("generate request = %v, want cached content reference without system instruction", body)
+				}
+				if contents := body["contents"].([]any); len(contents) != 1 {
+					t.Errorf("generate request has %d contents, want only the conversation", len(contents))
+				}
+			}
+
+			// Less than half of the TTL remains.
+			now = now.Add(40 * time.Minute)
+			generateCached(t, m, cacheRequest("Summarize the document."), stream)
+			want = []string{"PATCH cachedContents/cache1", "POST " + generateMethod}
+			if diff := cmp.Diff(want, backend.takeCalls()); diff != "" {
+				t.Errorf("calls after TTL refresh mismatch (-want +got):\n%s", diff)
+			}
+
+			if got := generateCached(t, m, cacheRequest("Translate the document."), stream); got != "cachedContents/cache2" {
+				t.Errorf("cached content after instruction change = %q, want %q", got, "cachedContents/cache2")
+			}
+			want = []string{"DELETE cachedContents/cache1", "POST cachedContents", "POST " + generateMethod}
+			if diff := cmp.Diff(want, backend.takeCalls()); diff != "" {
+				t.Errorf("calls after instruction change mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContextCache_Unsupported(t *testing.T) {
+	document := genai.NewContentFromText("A short document.", genai.RoleUser)
+	backend := &cacheBackend{createStatus: http.StatusBadRequest}
+	m := newCachingModel(t, backend, ContextCacheConfig{Contents: []*genai.Content{document}})
+
+	for range 2 {
+		if got := generateCached(t, m, cacheRequest("Summarize the document."), false); got != "" {
+			t.Errorf("cached content = %q, want none", got)
+		}
+	}
+	// The creation is not retried for the same instruction.
+	want := []string{"POST cachedContents", "POST models/gemini-2.5-flash:generateContent", "POST models/gemini-2.5-flash:generateContent"}
+	if diff := cmp.Diff(want, backend.takeCalls()); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+	for _, body := range backend.generate {
+		if body["cachedContent"] != nil || body["systemInstruction"] == nil {
+			t.Errorf("generate request = %v, want system instruction without cached content", body)
+		}
+		if contents := body["contents"].([]any); len(contents) != 2 {
+			t.Errorf("generate request has %d contents, want the static contents and the conversation", len(contents))
+		}
+	}
+}
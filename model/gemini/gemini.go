@@ -36,6 +36,8 @@ type geminiModel struct {
 	client             *genai.Client
 	name               string
 	versionHeaderValue string
+	retry              *RetryConfig
+	cache              *contextCache
 }
 
 // NewModel returns [model.LLM], backed by the Gemini API.
@@ -44,9 +46,35 @@ type geminiModel struct {
 // [genai.Client]. The modelName specifies which Gemini model to target
 // (e.g., "gemini-2.5-flash").
 //
+// Options, e.g. [WithRetry] or [WithContextCache], customize the behavior
+// of the model.
+//
 // An error is returned if the [genai.Client] fails to initialize.
-func NewModel(ctx context.Context, modelName string, cfg *genai.ClientConfig) (model.LLM, error) {
-	client, err := genai.NewClient(ctx, cfg)
+func NewModel(ctx context.Context, modelName string, cfg *genai.ClientConfig, opts ...Option) (model.LLM, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var cache *contextCache
+	if o.contextCache != nil {
+		var err error
+		if cache, err = newContextCache(*o.contextCache); err != nil {
+			return nil, err
+		}
+	}
+
+	var retry *RetryConfig
+	var client *genai.Client
+	var err error
+	if o.retry != nil {
+		if retry, err = o.retry.withDefaults(); err != nil {
+			return nil, err
+		}
+		client, err = newClientWithRetryAfter(ctx, cfg)
+	} else {
+		client, err = genai.NewClient(ctx, cfg)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -59,13 +87,39 @@ func NewModel(ctx context.Context, modelName string, cfg *genai.ClientConfig) (m
 		name:               modelName,
 		client:             client,
 		versionHeaderValue: headerValue,
+		retry:              retry,
+		cache:              cache,
 	}, nil
 }
 
+// NewVertexModel returns [model.LLM], backed by the Gemini API of Vertex AI
+// in the given Google Cloud project and location (e.g., "us-central1").
+// An empty project or location is read from the GOOGLE_CLOUD_PROJECT and
+// GOOGLE_CLOUD_LOCATION environment variables, the location defaults to
+// "global". The client authenticates with the Application Default
+// Credentials.
+//
+// It is a shortcut for [NewModel] with a [genai.ClientConfig] using
+// [genai.BackendVertexAI], and takes the same options.
+func NewVertexModel(ctx context.Context, modelName, project, location string, opts ...Option) (model.LLM, error) {
+	return NewModel(ctx, modelName, &genai.ClientConfig{
+		Backend:  genai.BackendVertexAI,
+		Project:  project,
+		Location: location,
+	}, opts...)
+}
+
 func (m *geminiModel) Name() string {
 	return m.name
 }
 
+// SupportsJSONMode implements model.JSONModeSupporter. The Gemini 1.0
+// models don't support JSON mode.
+func (m *geminiModel) SupportsJSONMode() bool {
+	name := strings.TrimPrefix(m.name, "models/")
+	return !strings.HasPrefix(name, "gemini-1.0") && name != "gemini-pro" && name != "gemini-pro-vision"
+}
+
 // GenerateContent calls the underlying model.
 func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
 	m.maybeAppendUserContent(req)
@@ -80,14 +134,51 @@ func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest
 	}
 	m.addHeaders(req.Config.HTTPOptions.Headers)
 
-	if stream {
-		return m.generateStream(ctx, req)
+	return func(yield func(*model.LLMResponse, error) bool) {
+		contents, config := req.Contents, req.Config
+		var cachedContent string
+		if m.cache != nil {
+			contents, config, cachedContent = m.cache.apply(ctx, m.client, m.name, contents, config)
+		}
+
+		if !stream {
+			resp, err := m.generate(ctx, contents, config)
+			yield(withCachedContent(resp, cachedContent), err)
+			return
+		}
+		for resp, err := range m.generateStream(ctx, contents, config) {
+			if !yield(withCachedContent(resp, cachedContent), err) {
+				return
+			}
+		}
 	}
+}
 
-	return func(yield func(*model.LLMResponse, error) bool) {
-		resp, err := m.generate(ctx, req)
-		yield(resp, err)
+// CountTokens implements [model.TokenCounter] with the CountTokens API of
+// the model.
+func (m *geminiModel) CountTokens(ctx context.Context, contents []*genai.Content) (int, error) {
+	headers := make(http.Header)
+	m.addHeaders(headers)
+	resp, err := m.client.Models.CountTokens(ctx, m.name, contents, &genai.CountTokensConfig{
+		HTTPOptions: &genai.HTTPOptions{Headers: headers},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
 	}
+	return int(resp.TotalTokens), nil
+}
+
+// withCachedContent records the name of the cached content used for the
+// response in its custom metadata, so that it shows up in traces.
+func withCachedContent(resp *model.LLMResponse, name string) *model.LLMResponse {
+	if resp == nil || name == "" {
+		return resp
+	}
+	if resp.CustomMetadata == nil {
+		resp.CustomMetadata = make(map[string]any)
+	}
+	resp.CustomMetadata[cachedContentMetadataKey] = name
+	return resp
 }
 
 // addHeaders sets the x-goog-api-client and user-agent headers
@@ -97,10 +188,22 @@ func (m *geminiModel) addHeaders(headers http.Header) {
 }
 
 // generate calls the model synchronously returning result from the first candidate.
-func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
-	resp, err := m.client.Models.GenerateContent(ctx, m.name, req.Contents, req.Config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call model: %w", err)
+// Other candidates, if requested with CandidateCount, are discarded.
+func (m *geminiModel) generate(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*model.LLMResponse, error) {
+	ctx, retrier := m.newRetrier(ctx)
+	var resp *genai.GenerateContentResponse
+	for attempt := 1; ; attempt++ {
+		var err error
+		resp, err = m.client.Models.GenerateContent(ctx, m.name, contents, config)
+		if err == nil {
+			break
+		}
+		if err := retrier.wait(ctx, attempt, err); err != nil {
+			return nil, fmt.Errorf("failed to call model: %w", err)
+		}
+	}
+	if err := blockedError(resp); err != nil {
+		return nil, err
 	}
 	if len(resp.Candidates) == 0 {
 		// shouldn't happen?
@@ -109,24 +212,96 @@ func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*mod
 	return converters.Genai2LLMResponse(resp), nil
 }
 
-// generateStream returns a stream of responses from the model.
-func (m *geminiModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
-	aggregator := llminternal.NewStreamingResponseAggregator()
+// blockedFinishReasons are the finish reasons of the candidates blocked by
+// the model.
+var blockedFinishReasons = map[genai.FinishReason]bool{
+	genai.FinishReasonSafety:                 true,
+	genai.FinishReasonBlocklist:              true,
+	genai.FinishReasonProhibitedContent:      true,
+	genai.FinishReasonSPII:                   true,
+	genai.FinishReasonImageSafety:            true,
+	genai.FinishReasonImageProhibitedContent: true,
+}
 
+// blockedError returns a [*model.BlockedError] if the model blocked the
+// prompt or the first candidate of the response, nil otherwise.
+func blockedError(resp *genai.GenerateContentResponse) error {
+	if len(resp.Candidates) == 0 {
+		if fb := resp.PromptFeedback; fb != nil && fb.BlockReason != "" {
+			return &model.BlockedError{
+				Prompt:        true,
+				Reason:        string(fb.BlockReason),
+				Message:       fb.BlockReasonMessage,
+				Categories:    blockedCategories(fb.SafetyRatings),
+				SafetyRatings: fb.SafetyRatings,
+			}
+		}
+		return nil
+	}
+	candidate := converters.FirstCandidate(resp.Candidates)
+	if candidate == nil || !blockedFinishReasons[candidate.FinishReason] {
+		return nil
+	}
+	return &model.BlockedError{
+		Reason:        string(candidate.FinishReason),
+		Message:       candidate.FinishMessage,
+		Categories:    blockedCategories(candidate.SafetyRatings),
+		SafetyRatings: candidate.SafetyRatings,
+	}
+}
+
+// blockedCategories returns the categories of the ratings which caused the
+// block.
+func blockedCategories(ratings []*genai.SafetyRating) []genai.HarmCategory {
+	var categories []genai.HarmCategory
+	for _, r := range ratings {
+		if r != nil && r.Blocked {
+			categories = append(categories, r.Category)
+		}
+	}
+	return categories
+}
+
+// generateStream returns a stream of responses from the model.
+// The stream is re-established on a retryable error only if no response has
+// been yielded yet.
+func (m *geminiModel) generateStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
-		for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, req.Contents, req.Config) {
-			if err != nil {
-				yield(nil, err)
-				return
+		ctx, retrier := m.newRetrier(ctx)
+		for attempt := 1; ; attempt++ {
+			aggregator := llminternal.NewStreamingResponseAggregator()
+			yielded := false
+			var streamErr error
+			for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, contents, config) {
+				if err != nil {
+					streamErr = err
+					break
+				}
+				if err := blockedError(resp); err != nil {
+					yield(nil, err)
+					return
+				}
+				for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
+					yielded = true
+					if !yield(llmResponse, err) {
+						return // Consumer stopped
+					}
+				}
 			}
-			for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
-				if !yield(llmResponse, err) {
-					return // Consumer stopped
+			if streamErr == nil {
+				if closeResult := aggregator.Close(); closeResult != nil {
+					yield(closeResult, nil)
 				}
+				return
+			}
+			if yielded {
+				yield(nil, retrier.final(attempt, streamErr))
+				return
+			}
+			if err := retrier.wait(ctx, attempt, streamErr); err != nil {
+				yield(nil, err)
+				return
 			}
-		}
-		if closeResult := aggregator.Close(); closeResult != nil {
-			yield(closeResult, nil)
 		}
 	}
 }
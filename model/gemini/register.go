@@ -0,0 +1,32 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// init registers Gemini models in the model registry, so that they can be
+// referred to by name, e.g. in llmagent.Config.ModelName. The client is
+// configured from the environment (GOOGLE_API_KEY, GOOGLE_GENAI_USE_VERTEXAI, etc.).
+func init() {
+	model.Register(`(models/)?gemini-.*`, func(ctx context.Context, name string) (model.LLM, error) {
+		return NewModel(ctx, name, &genai.ClientConfig{})
+	})
+}
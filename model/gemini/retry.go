@@ -0,0 +1,250 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Option configures a model created with [NewModel].
+type Option func(*options)
+
+type options struct {
+	retry        *RetryConfig
+	contextCache *ContextCacheConfig
+}
+
+// WithRetry makes the model retry calls failing with a transient error,
+// e.g. 429 Too Many Requests or 503 Service Unavailable, using exponential
+// backoff. Zero fields of cfg take their default values.
+func WithRetry(cfg RetryConfig) Option {
+	return func(o *options) {
+		o.retry = &cfg
+	}
+}
+
+// RetryConfig controls how the model retries failed calls.
+//
+// A streaming call is retried only if the stream fails before any response
+// has been yielded.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	// Defaults to 3.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every following retry, up to MaxBackoff. Defaults to 1s.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries. Defaults to 32s.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to the given fraction of it, in both
+	// directions, e.g. 0.2 gives delays within ±20% of the backoff. It must be
+	// between 0 and 1. Zero disables jitter.
+	Jitter float64
+	// RetryOn lists the HTTP status codes of the errors to retry.
+	// Defaults to 429, 500, 502, 503 and 504.
+	RetryOn []int
+}
+
+// withDefaults returns a copy of the config with zero fields set to their
+// default values, or an error if the config is invalid.
+func (c RetryConfig) withDefaults() (*RetryConfig, error) {
+	if c.MaxRetries < 0 || c.InitialBackoff < 0 || c.MaxBackoff < 0 {
+		return nil, fmt.Errorf("invalid retry config: MaxRetries, InitialBackoff and MaxBackoff must not be negative")
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		return nil, fmt.Errorf("invalid retry config: Jitter must be between 0 and 1, got %v", c.Jitter)
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = 3
+	}
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = 32 * time.Second
+	}
+	if len(c.RetryOn) == 0 {
+		c.RetryOn = []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	return &c, nil
+}
+
+// backoff returns the delay before the given retry, starting from 1.
+func (c *RetryConfig) backoff(retry int) time.Duration {
+	d := c.InitialBackoff
+	for i := 1; i < retry && d < c.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, c.MaxBackoff)
+	if c.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + c.Jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
+
+func (c *RetryConfig) retryable(err error) bool {
+	var apiErr genai.APIError
+	return errors.As(err, &apiErr) && slices.Contains(c.RetryOn, apiErr.Code)
+}
+
+// retrier tracks the retries of a single model call.
+type retrier struct {
+	cfg  *RetryConfig
+	hint *retryAfterHint
+}
+
+// newRetrier returns the retrier for a model call and the context carrying
+// the Retry-After hint to be filled by [retryAfterTransport].
+func (m *geminiModel) newRetrier(ctx context.Context) (context.Context, *retrier) {
+	if m.retry == nil {
+		return ctx, &retrier{}
+	}
+	hint := &retryAfterHint{}
+	return context.WithValue(ctx, retryAfterKey{}, hint), &retrier{cfg: m.retry, hint: hint}
+}
+
+// wait decides whether the failed attempt should be retried. If so, it sleeps
+// until the next attempt and returns nil. Otherwise it returns the error to
+// report to the caller.
+func (r *retrier) wait(ctx context.Context, attempt int, err error) error {
+	if r.cfg == nil || attempt > r.cfg.MaxRetries || !r.cfg.retryable(err) {
+		return r.final(attempt, err)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("retry canceled after %d attempts: %w (last error: %v)", attempt, ctx.Err(), err)
+	}
+	delay := r.cfg.backoff(attempt)
+	if d, ok := r.serverDelay(err); ok {
+		delay = d
+	}
+	*r.hint = retryAfterHint{}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("retry canceled after %d attempts: %w (last error: %v)", attempt, ctx.Err(), err)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// final returns the error of the last attempt, annotated with the number of
+// attempts if the call has been retried.
+func (r *retrier) final(attempt int, err error) error {
+	if attempt == 1 {
+		return err
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+}
+
+// serverDelay returns the delay requested by the server, either with the
+// Retry-After header or with the RetryInfo error detail.
+func (r *retrier) serverDelay(err error) (time.Duration, bool) {
+	if r.hint.ok {
+		return r.hint.delay, true
+	}
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	for _, detail := range apiErr.Details {
+		if t, _ := detail["@type"].(string); !strings.HasSuffix(t, "google.rpc.RetryInfo") {
+			continue
+		}
+		if s, ok := detail["retryDelay"].(string); ok {
+			if d, err := time.ParseDuration(s); err == nil && d > 0 {
+				return d, true
+			}
+		}
+	}
+	return 0, false
+}
+
+type retryAfterKey struct{}
+
+// retryAfterHint holds the delay requested by the Retry-After header of the
+// last response. genai.APIError does not expose the response headers, so they
+// are captured by [retryAfterTransport].
+type retryAfterHint struct {
+	delay time.Duration
+	ok    bool
+}
+
+// retryAfterTransport records the Retry-After header of responses into the
+// hint found in the request context.
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if hint, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHint); ok {
+		hint.delay, hint.ok = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return resp, nil
+}
+
+// parseRetryAfter parses the Retry-After header value, given either in
+// seconds or as an HTTP date. It returns false if the value is missing or invalid.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// newClientWithRetryAfter creates a genai client whose HTTP client records
+// Retry-After headers. A user provided HTTP client is copied, not modified.
+func newClientWithRetryAfter(ctx context.Context, cfg *genai.ClientConfig) (*genai.Client, error) {
+	if cfg != nil && cfg.HTTPClient != nil {
+		cfgCopy := *cfg
+		httpClient := *cfg.HTTPClient
+		httpClient.Transport = &retryAfterTransport{base: httpClient.Transport}
+		cfgCopy.HTTPClient = &httpClient
+		return genai.NewClient(ctx, &cfgCopy)
+	}
+	client, err := genai.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// The HTTP client has been created by genai and is not shared.
+	httpClient := client.ClientConfig().HTTPClient
+	httpClient.Transport = &retryAfterTransport{base: httpClient.Transport}
+	return client, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+const (
+	okResponse   = `{"candidates": [{"content": {"role": "model", "parts": [{"text": "Paris"}]}, "finishReason": "STOP"}]}`
+	partialChunk = `{"candidates": [{"content": {"role": "model", "parts": [{"text": "Par"}]}}]}`
+)
+
+// flakyBackend is a http.RoundTripper failing the first requests with the
+// given status code before serving successful responses.
+type flakyBackend struct {
+	failures   int // number of failing requests, -1 to fail all of them
+	status     int
+	retryAfter string
+	// brokenStream makes successful streaming responses fail after the first chunk.
+	brokenStream bool
+	onRequest    func()
+
+	requests int
+}
+
+func (b *flakyBackend) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.requests++
+	if b.onRequest != nil {
+		b.onRequest()
+	}
+	if b.failures < 0 || b.requests <= b.failures {
+		resp := newResponse(req, b.status, errorBody(b.status))
+		if b.retryAfter != "" {
+			resp.Header.Set("Retry-After", b.retryAfter)
+		}
+		return resp, nil
+	}
+	if strings.Contains(req.URL.Path, "streamGenerateContent") {
+		if b.brokenStream {
+			return newResponse(req, http.StatusOK, "data: "+partialChunk+"\n\n"+errorBody(http.StatusServiceUnavailable)+"\n\n"), nil
+		}
+		return newResponse(req, http.StatusOK, "data: "+okResponse+"\n\n"), nil
+	}
+	return newResponse(req, http.StatusOK, okResponse), nil
+}
+
+func errorBody(status int) string {
+	return fmt.Sprintf(`{"error": {"code": %d, "message": %q}}`, status, http.StatusText(status))
+}
+
+func newResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func newFlakyModel(t *testing.T, backend *flakyBackend, retry RetryConfig) model.LLM {
+	t.Helper()
+	m, err := NewModel(t.Context(), "gemini-2.0-flash", &genai.ClientConfig{
+		HTTPClient: &http.Client{Transport: backend},
+		APIKey:     "fakekey",
+		Backend:    genai.BackendGeminiAPI,
+	}, WithRetry(retry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// generate returns the text of the final response and the first error.
+func generate(ctx context.Context, m model.LLM, stream bool) (string, error) {
+	req := &model.LLMRequest{Contents: genai.Text("What is the capital of France?")}
+	var text string
+	for resp, err := range m.GenerateContent(ctx, req, stream) {
+		if err != nil {
+			return text, err
+		}
+		if !resp.Partial && resp.Content != nil && len(resp.Content.Parts) > 0 {
+			text = resp.Content.Parts[0].Text
+		}
+	}
+	return text, nil
+}
+
+func TestModel_Retry(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			t.Run("succeeds_after_transient_errors", func(t *testing.T) {
+				backend := &flakyBackend{failures: 2, status: http.StatusServiceUnavailable}
+				m := newFlakyModel(t, backend, RetryConfig{InitialBackoff: time.Millisecond, Jitter: 0.5})
+
+				got, err := generate(t.Context(), m, stream)
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				if got != "Paris" {
+					t.Errorf("GenerateContent() = %q, want %q", got, "Paris")
+				}
+				if backend.requests != 3 {
+					t.Errorf("backend got %d requests, want 3", backend.requests)
+				}
+			})
+
+			t.Run("respects_retry_after", func(t *testing.T) {
+				// The backoff would time out the test unless Retry-After is used.
+				backend := &flakyBackend{failures: 1, status: http.StatusTooManyRequests, retryAfter: "0"}
+				m := newFlakyModel(t, backend, RetryConfig{InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+
+				ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+				defer cancel()
+				if _, err := generate(ctx, m, stream); err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				if backend.requests != 2 {
+					t.Errorf("backend got %d requests, want 2", backend.requests)
+				}
+			})
+
+			t.Run("gives_up_after_max_retries", func(t *testing.T) {
+				backend := &flakyBackend{failures: -1, status: http.StatusServiceUnavailable}
+				m := newFlakyModel(t, backend, RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond})
+
+				_, err := generate(t.Context(), m, stream)
+				var apiErr genai.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != http.StatusServiceUnavailable {
+					t.Fatalf("GenerateContent() error = %v, want APIError with code 503", err)
+				}
+				if !strings.Contains(err.Error(), "after 3 attempts") {
+					t.Errorf("GenerateContent() error = %v, want the number of attempts", err)
+				}
+				if backend.requests != 3 {
+					t.Errorf("backend got %d requests, want 3", backend.requests)
+				}
+			})
+
+			t.Run("does_not_retry_other_errors", func(t *testing.T) {
+				backend := &flakyBackend{failures: -1, status: http.StatusBadRequest}
+				m := newFlakyModel(t, backend, RetryConfig{InitialBackoff: time.Millisecond})
+
+				if _, err := generate(t.Context(), m, stream); err == nil {
+					t.Fatal("GenerateContent() error = nil, want error")
+				}
+				if backend.requests != 1 {
+					t.Errorf("backend got %d requests, want 1", backend.requests)
+				}
+			})
+
+			t.Run("stops_on_context_cancellation", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(t.Context())
+				backend := &flakyBackend{failures: -1, status: http.StatusServiceUnavailable, onRequest: cancel}
+				m := newFlakyModel(t, backend, RetryConfig{InitialBackoff: time.Hour})
+
+				_, err := generate(ctx, m, stream)
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("GenerateContent() error = %v, want %v", err, context.Canceled)
+				}
+				if backend.requests != 1 {
+					t.Errorf("backend got %d requests, want 1", backend.requests)
+				}
+			})
+		})
+	}
+}
+
+func TestModel_RetryStreamAfterPartialResponse(t *testing.T) {
+	backend := &flakyBackend{status: http.StatusServiceUnavailable, brokenStream: true}
+	m := newFlakyModel(t, backend, RetryConfig{InitialBackoff: time.Millisecond})
+
+	_, err := generate(t.Context(), m, true)
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GenerateContent() error = %v, want APIError with code 503", err)
+	}
+	if backend.requests != 1 {
+		t.Errorf("backend got %d requests, want 1: the stream must not be retried after a partial response", backend.requests)
+	}
+}
+
+func TestNewModel_InvalidRetryConfig(t *testing.T) {
+	for _, cfg := range []RetryConfig{
+		{MaxRetries: -1},
+		{InitialBackoff: -time.Second},
+		{Jitter: 1.5},
+	} {
+		_, err := NewModel(t.Context(), "gemini-2.0-flash", &genai.ClientConfig{APIKey: "fakekey", Backend: genai.BackendGeminiAPI}, WithRetry(cfg))
+		if err == nil {
+			t.Errorf("NewModel(%+v) error = nil, want error", cfg)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "invalid", wantOK: false},
+		{value: "0", want: 0, wantOK: true},
+		{value: "120", want: 2 * time.Minute, wantOK: true},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second, wantOK: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -17,17 +17,96 @@ package model
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"strings"
 
 	"google.golang.org/genai"
 )
 
+// ErrLLMCallsLimitExceeded is returned when an invocation exceeds the number
+// of LLM calls allowed by agent.RunConfig.MaxLLMCalls.
+var ErrLLMCallsLimitExceeded = errors.New("max number of LLM calls exceeded")
+
+// ErrMaxTotalTokensExceeded is returned when an invocation uses more tokens
+// than allowed by agent.RunConfig.MaxTotalTokens.
+var ErrMaxTotalTokensExceeded = errors.New("max number of total tokens exceeded")
+
+// ErrBlocked is matched by the errors returned when the model blocks the
+// prompt or the response, e.g. by its safety filters. The errors are of type
+// [*BlockedError].
+var ErrBlocked = errors.New("blocked by the model")
+
+// BlockedError is returned when the model blocks the prompt or the response.
+// It is passed to the AfterModelCallbacks of an LLM agent, which can handle
+// it, e.g. by replacing it with a response explaining the refusal.
+type BlockedError struct {
+	// Prompt reports whether the prompt was blocked, rather than the response.
+	Prompt bool
+	// Reason is the finish reason of the response, e.g. SAFETY, or the block
+	// reason of the prompt.
+	Reason string
+	// Message is the explanation of the model, if any.
+	Message string
+	// Categories are the harm categories that caused the block.
+	Categories []genai.HarmCategory
+	// SafetyRatings are all the safety ratings of the prompt or response.
+	SafetyRatings []*genai.SafetyRating
+}
+
+func (e *BlockedError) Error() string {
+	what := "response"
+	if e.Prompt {
+		what = "prompt"
+	}
+	msg := fmt.Sprintf("model blocked the %s: %s", what, e.Reason)
+	if len(e.Categories) > 0 {
+		categories := make([]string, len(e.Categories))
+		for i, c := range e.Categories {
+			categories[i] = string(c)
+		}
+		msg += " (" + strings.Join(categories, ", ") + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is reports whether target is [ErrBlocked].
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
 // LLM provides the access to the underlying LLM.
 type LLM interface {
 	Name() string
 	GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error]
 }
 
+// TokenCounter counts the tokens of contents as a model does, e.g. to keep
+// the conversation history within its context window. The Gemini models
+// implement it.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, contents []*genai.Content) (int, error)
+}
+
+// JSONModeSupporter is implemented by the models which can tell whether they
+// support JSON mode, i.e. replying with JSON when the request sets
+// genai.GenerateContentConfig.ResponseMIMEType to "application/json", and
+// with the ResponseSchema if set.
+type JSONModeSupporter interface {
+	SupportsJSONMode() bool
+}
+
+// SupportsJSONMode reports whether llm supports JSON mode. The models which
+// don't implement [JSONModeSupporter] are assumed to support it.
+func SupportsJSONMode(llm LLM) bool {
+	s, ok := llm.(JSONModeSupporter)
+	return !ok || s.SupportsJSONMode()
+}
+
 // LLMRequest is the raw LLM request.
 type LLMRequest struct {
 	Model    string
@@ -39,6 +118,10 @@ type LLMRequest struct {
 
 // LLMResponse is the raw LLM response.
 // It provides the first candidate response from the model if available.
+//
+// If multiple candidates were requested (e.g. with
+// genai.GenerateContentConfig.CandidateCount), only the first one (with index 0)
+// is used in both streaming and non-streaming modes, the others are discarded.
 type LLMResponse struct {
 	Content           *genai.Content
 	CitationMetadata  *genai.CitationMetadata
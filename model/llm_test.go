@@ -15,6 +15,8 @@
 package model_test
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -242,3 +244,29 @@ func TestCreateResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockedError(t *testing.T) {
+	tests := []struct {
+		err  *model.BlockedError
+		want string
+	}{
+		{
+			err:  &model.BlockedError{Reason: "SAFETY", Categories: []genai.HarmCategory{genai.HarmCategoryDangerousContent, genai.HarmCategoryHarassment}},
+			want: "model blocked the response: SAFETY (HARM_CATEGORY_DANGEROUS_CONTENT, HARM_CATEGORY_HARASSMENT)",
+		},
+		{
+			err:  &model.BlockedError{Prompt: true, Reason: "PROHIBITED_CONTENT", Message: "The prompt was blocked."},
+			want: "model blocked the prompt: PROHIBITED_CONTENT: The prompt was blocked.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+			if err := fmt.Errorf("model call failed: %w", tt.err); !errors.Is(err, model.ErrBlocked) {
+				t.Errorf("errors.Is(%v, ErrBlocked) = false, want true", err)
+			}
+		})
+	}
+}
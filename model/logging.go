@@ -0,0 +1,322 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// LoggingConfig configures the model created with [NewLoggingModel].
+type LoggingConfig struct {
+	// MaxTextLength limits the length of texts in the log records.
+	// Defaults to 200 characters, a negative value disables the limit.
+	MaxTextLength int
+	// IncludeBlobData keeps the data of inline blobs, e.g. images, in the
+	// dumped JSON. By default it is replaced with its size.
+	IncludeBlobData bool
+	// DumpDir, if set, is a directory where the full JSON of each LLM call,
+	// i.e. the request and the responses, is written. Calls are grouped in
+	// subdirectories by the invocation id.
+	DumpDir string
+}
+
+const defaultMaxTextLength = 200
+
+type loggingModel struct {
+	inner  LLM
+	logger *slog.Logger
+	cfg    LoggingConfig
+	calls  atomic.Int64
+}
+
+// NewLoggingModel returns an [LLM] logging requests and responses of the
+// inner model at the debug level, which helps to debug prompt construction.
+//
+// Requests are logged with their system instruction, a summary of the
+// contents and the tool names. Responses are logged with their finish
+// reason, token usage and latency. Partial responses are not logged.
+func NewLoggingModel(inner LLM, logger *slog.Logger, cfg LoggingConfig) LLM {
+	if cfg.MaxTextLength == 0 {
+		cfg.MaxTextLength = defaultMaxTextLength
+	}
+	return &loggingModel{inner: inner, logger: logger, cfg: cfg}
+}
+
+func (m *loggingModel) Name() string {
+	return m.inner.Name()
+}
+
+func (m *loggingModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		call := m.calls.Add(1)
+		invocationID := invocationID(ctx)
+		attrs := []slog.Attr{slog.String("model", m.inner.Name()), slog.Int64("call", call)}
+		if invocationID != "" {
+			attrs = append(attrs, slog.String("invocation_id", invocationID))
+		}
+		logger := m.logger.With(attrsToAny(attrs)...)
+
+		logger.LogAttrs(ctx, slog.LevelDebug, "LLM request",
+			slog.String("system_instruction", m.truncate(contentText(systemInstruction(req)))),
+			slog.Any("contents", m.summarizeContents(req.Contents)),
+			slog.Any("tools", toolNames(req)),
+			slog.Bool("stream", stream),
+		)
+
+		start := time.Now()
+		var resps []*LLMResponse
+		var respErr error
+		defer func() {
+			if m.cfg.DumpDir != "" {
+				if err := m.dump(invocationID, call, req, resps, respErr); err != nil {
+					logger.LogAttrs(ctx, slog.LevelWarn, "Failed to dump LLM call", slog.Any("error", err))
+				}
+			}
+		}()
+
+		for resp, err := range m.inner.GenerateContent(ctx, req, stream) {
+			latency := time.Since(start)
+			if err != nil {
+				respErr = err
+				logger.LogAttrs(ctx, slog.LevelDebug, "LLM error", slog.Duration("latency", latency), slog.Any("error", err))
+			} else if !resp.Partial {
+				resps = append(resps, resp)
+				logger.LogAttrs(ctx, slog.LevelDebug, "LLM response", m.responseAttrs(resp, latency)...)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+func (m *loggingModel) responseAttrs(resp *LLMResponse, latency time.Duration) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("finish_reason", string(resp.FinishReason)),
+		slog.Duration("latency", latency),
+	}
+	if u := resp.UsageMetadata; u != nil {
+		attrs = append(attrs,
+			slog.Int("prompt_tokens", int(u.PromptTokenCount)),
+			slog.Int("candidate_tokens", int(u.CandidatesTokenCount)),
+			slog.Int("total_tokens", int(u.TotalTokenCount)),
+		)
+	}
+	if resp.Content != nil {
+		attrs = append(attrs, slog.String("content", m.summarizeContent(resp.Content)))
+	}
+	if resp.ErrorCode != "" {
+		attrs = append(attrs, slog.String("error_code", resp.ErrorCode), slog.String("error_message", resp.ErrorMessage))
+	}
+	return attrs
+}
+
+func (m *loggingModel) truncate(s string) string {
+	if m.cfg.MaxTextLength < 0 {
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= m.cfg.MaxTextLength {
+		return s
+	}
+	return string(r[:m.cfg.MaxTextLength]) + "..."
+}
+
+func (m *loggingModel) summarizeContents(contents []*genai.Content) []string {
+	summary := make([]string, 0, len(contents))
+	for _, c := range contents {
+		summary = append(summary, m.summarizeContent(c))
+	}
+	return summary
+}
+
+// summarizeContent describes the content in one line, e.g.
+// `user: text("What is on the picture?"), inline_data(image/png, 1024 bytes)`.
+func (m *loggingModel) summarizeContent(c *genai.Content) string {
+	if c == nil {
+		return "<nil>"
+	}
+	parts := make([]string, 0, len(c.Parts))
+	for _, p := range c.Parts {
+		if p == nil {
+			continue
+		}
+		switch {
+		case p.Text != "" && p.Thought:
+			parts = append(parts, fmt.Sprintf("thought(%q)", m.truncate(p.Text)))
+		case p.Text != "":
+			parts = append(parts, fmt.Sprintf("text(%q)", m.truncate(p.Text)))
+		case p.FunctionCall != nil:
+			parts = append(parts, fmt.Sprintf("function_call(%s)", p.FunctionCall.Name))
+		case p.FunctionResponse != nil:
+			parts = append(parts, fmt.Sprintf("function_response(%s)", p.FunctionResponse.Name))
+		case p.InlineData != nil:
+			parts = append(parts, fmt.Sprintf("inline_data(%s, %d bytes)", p.InlineData.MIMEType, len(p.InlineData.Data)))
+		case p.FileData != nil:
+			parts = append(parts, fmt.Sprintf("file_data(%s, %s)", p.FileData.MIMEType, p.FileData.FileURI))
+		case p.ExecutableCode != nil:
+			parts = append(parts, "executable_code")
+		case p.CodeExecutionResult != nil:
+			parts = append(parts, "code_execution_result")
+		default:
+			parts = append(parts, "other")
+		}
+	}
+	return c.Role + ": " + strings.Join(parts, ", ")
+}
+
+// dump writes the request and the responses of the call as JSON.
+func (m *loggingModel) dump(invocationID string, call int64, req *LLMRequest, resps []*LLMResponse, respErr error) error {
+	if invocationID == "" {
+		invocationID = "no_invocation"
+	}
+	dir := filepath.Join(m.cfg.DumpDir, invocationID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	record := map[string]any{
+		"request": map[string]any{
+			"model":    req.Model,
+			"contents": req.Contents,
+			"config":   req.Config,
+		},
+		"responses": resps,
+	}
+	if respErr != nil {
+		record["error"] = respErr.Error()
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if !m.cfg.IncludeBlobData {
+		if b, err = redactBlobs(b); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("%04d_llm_call.json", call)), b, 0o644)
+}
+
+// redactBlobs replaces the data of inline blobs in the JSON with its size.
+func redactBlobs(b []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	var redact func(v any)
+	redact = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				if blob, ok := child.(map[string]any); ok && k == "inlineData" {
+					if data, ok := blob["data"].(string); ok {
+						size := base64.StdEncoding.DecodedLen(len(data))
+						if decoded, err := base64.StdEncoding.DecodeString(data); err == nil {
+							size = len(decoded)
+						}
+						blob["data"] = fmt.Sprintf("<redacted %d bytes>", size)
+					}
+					continue
+				}
+				redact(child)
+			}
+		case []any:
+			for _, child := range v {
+				redact(child)
+			}
+		}
+	}
+	redact(v)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func systemInstruction(req *LLMRequest) *genai.Content {
+	if req.Config == nil {
+		return nil
+	}
+	return req.Config.SystemInstruction
+}
+
+func contentText(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range c.Parts {
+		if p != nil && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// toolNames returns the sorted names of the tools available to the model.
+func toolNames(req *LLMRequest) []string {
+	var names []string
+	for name := range req.Tools {
+		names = append(names, name)
+	}
+	if req.Config != nil {
+		for _, t := range req.Config.Tools {
+			if t == nil {
+				continue
+			}
+			for _, fd := range t.FunctionDeclarations {
+				if fd != nil && !slices.Contains(names, fd.Name) {
+					names = append(names, fd.Name)
+				}
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// invocationID returns the id of the invocation if ctx is an agent context.
+func invocationID(ctx context.Context) string {
+	if c, ok := ctx.(interface{ InvocationID() string }); ok {
+		return c.InvocationID()
+	}
+	return ""
+}
+
+func attrsToAny(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
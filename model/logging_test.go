@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"encoding/base64"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// recordingHandler is a slog.Handler keeping the records with their attributes.
+type recordingHandler struct {
+	mu      *sync.Mutex
+	attrs   []slog.Attr
+	records *[]map[string]any
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{mu: &sync.Mutex{}, records: &[]map[string]any{}}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	rec := map[string]any{"msg": r.Message, "level": r.Level}
+	for _, a := range h.attrs {
+		rec[a.Key] = a.Value.Any()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		rec[a.Key] = a.Value.Any()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, rec)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recordingHandler{mu: h.mu, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...), records: h.records}
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// fixedModel replies with a text response reporting token usage.
+type fixedModel struct{}
+
+func (fixedModel) Name() string {
+	return "fixed-model"
+}
+
+func (fixedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if stream && !yield(&model.LLMResponse{Content: genai.NewContentFromText("A ", genai.RoleModel), Partial: true}, nil) {
+			return
+		}
+		yield(&model.LLMResponse{
+			Content:       genai.NewContentFromText("A cat.", genai.RoleModel),
+			FinishReason:  genai.FinishReasonStop,
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 3, TotalTokenCount: 13},
+		}, nil)
+	}
+}
+
+var imageData = []byte("\x89PNG")
+
+func imageRequest() *model.LLMRequest {
+	return &model.LLMRequest{
+		Contents: []*genai.Content{{
+			Role: genai.RoleUser,
+			Parts: []*genai.Part{
+				genai.NewPartFromText("What is on the picture?"),
+				genai.NewPartFromBytes(imageData, "image/png"),
+			},
+		}},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("Describe pictures.", genai.RoleUser),
+			Tools:             []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "zoom"}, {Name: "crop"}}}},
+		},
+	}
+}
+
+func TestLoggingModel(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		handler := newRecordingHandler()
+		m := model.NewLoggingModel(fixedModel{}, slog.New(handler), model.LoggingConfig{})
+		for _, err := range m.GenerateContent(t.Context(), imageRequest(), stream) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+		}
+
+		records := *handler.records
+		if len(records) != 2 {
+			t.Fatalf("got %d log records, want request and response records: %v", len(records), records)
+		}
+		for _, rec := range records {
+			if rec["level"] != slog.LevelDebug || rec["model"] != "fixed-model" {
+				t.Errorf("record %v, want debug level and the model name", rec)
+			}
+		}
+
+		req := records[0]
+		if req["msg"] != "LLM request" || req["system_instruction"] != "Describe pictures." || req["stream"] != stream {
+			t.Errorf("request record = %v", req)
+		}
+		wantContents := []string{`user: text("What is on the picture?"), inline_data(image/png, 4 bytes)`}
+		if diff := cmp.Diff(wantContents, req["contents"]); diff != "" {
+			t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"crop", "zoom"}, req["tools"]); diff != "" {
+			t.Errorf("request tools mismatch (-want +got):\n%s", diff)
+		}
+
+		resp := records[1]
+		if resp["msg"] != "LLM response" || resp["finish_reason"] != "STOP" || resp["total_tokens"] != int64(13) {
+			t.Errorf("response record = %v", resp)
+		}
+		if _, ok := resp["latency"]; !ok {
+			t.Errorf("response record = %v, want latency", resp)
+		}
+	}
+}
+
+func TestLoggingModel_Dump(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(imageData)
+	for _, includeBlobData := range []bool{false, true} {
+		dir := t.TempDir()
+		m := model.NewLoggingModel(fixedModel{}, slog.New(newRecordingHandler()), model.LoggingConfig{DumpDir: dir, IncludeBlobData: includeBlobData})
+		for _, err := range m.GenerateContent(t.Context(), imageRequest(), false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, "no_invocation", "0001_llm_call.json"))
+		if err != nil {
+			t.Fatalf("failed to read the dump: %v", err)
+		}
+		dump := string(b)
+		if !strings.Contains(dump, "Describe pictures.") || !strings.Contains(dump, "A cat.") {
+			t.Errorf("dump = %s, want the request and the response", dump)
+		}
+		if got := strings.Contains(dump, encoded); got != includeBlobData {
+			t.Errorf("IncludeBlobData = %v: dump contains blob data = %v", includeBlobData, got)
+		}
+		if got := strings.Contains(dump, "<redacted 4 bytes>"); got == includeBlobData {
+			t.Errorf("IncludeBlobData = %v: dump contains redaction marker = %v", includeBlobData, got)
+		}
+	}
+}
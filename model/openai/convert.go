@@ -0,0 +1,376 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// toChatRequest translates the request to the chat completions format.
+func toChatRequest(modelName string, req *model.LLMRequest) (*chatRequest, error) {
+	chatReq := &chatRequest{Model: modelName}
+	cfg := req.Config
+	if cfg == nil {
+		cfg = &genai.GenerateContentConfig{}
+	}
+
+	if system := contentText(cfg.SystemInstruction, "\n\n"); system != "" {
+		chatReq.Messages = append(chatReq.Messages, chatMessage{Role: "system", Content: system})
+	}
+	messages, err := toChatMessages(req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	chatReq.Messages = append(chatReq.Messages, messages...)
+
+	for _, t := range cfg.Tools {
+		if t == nil {
+			continue
+		}
+		if len(t.FunctionDeclarations) == 0 {
+			return nil, fmt.Errorf("unsupported tool: only function declarations are supported by OpenAI-compatible models")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			chatReq.Tools = append(chatReq.Tools, chatTool{
+				Type: "function",
+				Function: functionDecl{
+					Name:        decl.Name,
+					Description: decl.Description,
+					Parameters:  parameters(decl),
+				},
+			})
+		}
+	}
+
+	chatReq.Temperature = cfg.Temperature
+	chatReq.TopP = cfg.TopP
+	chatReq.MaxTokens = cfg.MaxOutputTokens
+	chatReq.Stop = cfg.StopSequences
+	chatReq.Seed = cfg.Seed
+	chatReq.PresencePenalty = cfg.PresencePenalty
+	chatReq.FrequencyPenalty = cfg.FrequencyPenalty
+	if cfg.ResponseMIMEType == "application/json" {
+		switch {
+		case cfg.ResponseJsonSchema != nil:
+			chatReq.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: &jsonSchema{Name: "response", Schema: cfg.ResponseJsonSchema}}
+		case cfg.ResponseSchema != nil:
+			chatReq.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: &jsonSchema{Name: "response", Schema: schemaToJSON(cfg.ResponseSchema)}}
+		default:
+			chatReq.ResponseFormat = &responseFormat{Type: "json_object"}
+		}
+	}
+	return chatReq, nil
+}
+
+// toChatMessages translates the conversation history. Function calls become
+// tool calls of assistant messages and function responses become tool messages.
+func toChatMessages(contents []*genai.Content) ([]chatMessage, error) {
+	var messages []chatMessage
+	// IDs of the tool calls made without an ID, by function name, so that the
+	// responses can refer to them.
+	generatedIDs := make(map[string][]string)
+	nextID := 0
+
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		if c.Role == genai.RoleModel {
+			msg := chatMessage{Role: "assistant"}
+			for _, p := range c.Parts {
+				if p == nil || p.FunctionCall == nil {
+					continue
+				}
+				fc := p.FunctionCall
+				id := fc.ID
+				if id == "" {
+					id = fmt.Sprintf("call_%d", nextID)
+					nextID++
+					generatedIDs[fc.Name] = append(generatedIDs[fc.Name], id)
+				}
+				args, err := json.Marshal(fc.Args)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal arguments of function call %q: %w", fc.Name, err)
+				}
+				if fc.Args == nil {
+					args = []byte("{}")
+				}
+				msg.ToolCalls = append(msg.ToolCalls, toolCall{ID: id, Type: "function", Function: functionCall{Name: fc.Name, Arguments: string(args)}})
+			}
+			if text := contentText(c, ""); text != "" {
+				msg.Content = text
+			}
+			if msg.Content != nil || len(msg.ToolCalls) > 0 {
+				messages = append(messages, msg)
+			}
+			continue
+		}
+
+		var parts []contentPart
+		hasImage := false
+		for _, p := range c.Parts {
+			switch {
+			case p == nil || p.Thought:
+			case p.FunctionResponse != nil:
+				fr := p.FunctionResponse
+				id := fr.ID
+				if id == "" && len(generatedIDs[fr.Name]) > 0 {
+					id = generatedIDs[fr.Name][0]
+					generatedIDs[fr.Name] = generatedIDs[fr.Name][1:]
+				}
+				response, err := json.Marshal(fr.Response)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response of function %q: %w", fr.Name, err)
+				}
+				messages = append(messages, chatMessage{Role: "tool", ToolCallID: id, Content: string(response)})
+			case p.Text != "":
+				parts = append(parts, contentPart{Type: "text", Text: p.Text})
+			case p.InlineData != nil:
+				if !strings.HasPrefix(p.InlineData.MIMEType, "image/") {
+					return nil, fmt.Errorf("unsupported inline data MIME type %q: only images are supported", p.InlineData.MIMEType)
+				}
+				url := "data:" + p.InlineData.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.InlineData.Data)
+				parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}})
+				hasImage = true
+			case p.FileData != nil:
+				if !strings.HasPrefix(p.FileData.MIMEType, "image/") {
+					return nil, fmt.Errorf("unsupported file data MIME type %q: only images are supported", p.FileData.MIMEType)
+				}
+				parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: p.FileData.FileURI}})
+				hasImage = true
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		if hasImage {
+			messages = append(messages, chatMessage{Role: "user", Content: parts})
+			continue
+		}
+		var texts []string
+		for _, p := range parts {
+			texts = append(texts, p.Text)
+		}
+		messages = append(messages, chatMessage{Role: "user", Content: strings.Join(texts, "")})
+	}
+	return messages, nil
+}
+
+// contentText joins the text of all non-thought parts.
+func contentText(c *genai.Content, sep string) string {
+	if c == nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range c.Parts {
+		if p != nil && !p.Thought && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, sep)
+}
+
+// parameters returns the JSON schema of the function parameters.
+func parameters(decl *genai.FunctionDeclaration) any {
+	switch {
+	case decl.ParametersJsonSchema != nil:
+		return decl.ParametersJsonSchema
+	case decl.Parameters != nil:
+		return schemaToJSON(decl.Parameters)
+	default:
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+}
+
+// schemaToJSON translates the schema to JSON schema. Gemini types are upper
+// case, e.g. OBJECT, while JSON schema types are lower case.
+func schemaToJSON(s *genai.Schema) map[string]any {
+	if s == nil {
+		return nil
+	}
+	m := make(map[string]any)
+	if s.Type != "" {
+		typ := strings.ToLower(string(s.Type))
+		if s.Nullable != nil && *s.Nullable {
+			m["type"] = []string{typ, "null"}
+		} else {
+			m["type"] = typ
+		}
+	}
+	if s.Title != "" {
+		m["title"] = s.Title
+	}
+	if s.Description != "" {
+		m["description"] = s.Description
+	}
+	if s.Format != "" {
+		m["format"] = s.Format
+	}
+	if s.Pattern != "" {
+		m["pattern"] = s.Pattern
+	}
+	if len(s.Enum) > 0 {
+		m["enum"] = s.Enum
+	}
+	if s.Default != nil {
+		m["default"] = s.Default
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			props[name] = schemaToJSON(prop)
+		}
+		m["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		m["required"] = s.Required
+	}
+	if s.Items != nil {
+		m["items"] = schemaToJSON(s.Items)
+	}
+	if len(s.AnyOf) > 0 {
+		anyOf := make([]any, len(s.AnyOf))
+		for i, sub := range s.AnyOf {
+			anyOf[i] = schemaToJSON(sub)
+		}
+		m["anyOf"] = anyOf
+	}
+	if s.MinItems != nil {
+		m["minItems"] = *s.MinItems
+	}
+	if s.MaxItems != nil {
+		m["maxItems"] = *s.MaxItems
+	}
+	if s.MinLength != nil {
+		m["minLength"] = *s.MinLength
+	}
+	if s.MaxLength != nil {
+		m["maxLength"] = *s.MaxLength
+	}
+	if s.Minimum != nil {
+		m["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		m["maximum"] = *s.Maximum
+	}
+	return m
+}
+
+// toLLMResponse translates a chat completion to the model response.
+func toLLMResponse(resp *chatResponse) (*model.LLMResponse, error) {
+	c := firstChoice(resp.Choices)
+	if c == nil {
+		return nil, fmt.Errorf("empty response")
+	}
+	msg := c.Message
+	calls, err := toFunctionCalls(msg.ToolCalls)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LLMResponse{
+		Content:       toContent(reasoning(msg), msg.Content, calls),
+		FinishReason:  toFinishReason(c.FinishReason),
+		UsageMetadata: toUsageMetadata(resp.Usage),
+	}, nil
+}
+
+// toContent returns the model content made of the thought, the text and the
+// function calls, or nil if all of them are empty.
+func toContent(thought, text string, calls []*genai.FunctionCall) *genai.Content {
+	var parts []*genai.Part
+	if thought != "" {
+		parts = append(parts, &genai.Part{Text: thought, Thought: true})
+	}
+	if text != "" {
+		parts = append(parts, &genai.Part{Text: text})
+	}
+	for _, fc := range calls {
+		parts = append(parts, &genai.Part{FunctionCall: fc})
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &genai.Content{Role: genai.RoleModel, Parts: parts}
+}
+
+func toFunctionCalls(calls []toolCall) ([]*genai.FunctionCall, error) {
+	var fcs []*genai.FunctionCall
+	for _, call := range calls {
+		args := make(map[string]any)
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				return nil, fmt.Errorf("failed to parse arguments of tool call %q: %w", call.Function.Name, err)
+			}
+		}
+		fcs = append(fcs, &genai.FunctionCall{ID: call.ID, Name: call.Function.Name, Args: args})
+	}
+	return fcs, nil
+}
+
+func firstChoice(choices []choice) *choice {
+	for i := range choices {
+		if choices[i].Index == 0 {
+			return &choices[i]
+		}
+	}
+	return nil
+}
+
+func reasoning(msg responseMessage) string {
+	if msg.ReasoningContent != "" {
+		return msg.ReasoningContent
+	}
+	return msg.Reasoning
+}
+
+func toFinishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "stop", "tool_calls", "function_call":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+func toUsageMetadata(u *usage) *genai.GenerateContentResponseUsageMetadata {
+	if u == nil {
+		return nil
+	}
+	metadata := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     u.PromptTokens,
+		CandidatesTokenCount: u.CompletionTokens,
+		TotalTokenCount:      u.TotalTokens,
+	}
+	if u.PromptTokensDetails != nil {
+		metadata.CachedContentTokenCount = u.PromptTokensDetails.CachedTokens
+	}
+	if u.CompletionTokensDetails != nil {
+		metadata.ThoughtsTokenCount = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return metadata
+}
@@ -0,0 +1,292 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openai implements the [model.LLM] interface for models served with
+// an OpenAI-compatible chat completions API, e.g. OpenAI, vLLM or Ollama.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+// Option configures a model created with [NewModel].
+type Option func(*openaiModel)
+
+// WithHTTPClient sets the HTTP client used to call the API.
+// Defaults to [http.DefaultClient].
+func WithHTTPClient(client *http.Client) Option {
+	return func(m *openaiModel) {
+		m.httpClient = client
+	}
+}
+
+type openaiModel struct {
+	baseURL    string
+	apiKey     string
+	name       string
+	httpClient *http.Client
+	userAgent  string
+}
+
+// NewModel returns [model.LLM], backed by an OpenAI-compatible chat
+// completions API.
+//
+// The baseURL is the URL of the API without the /chat/completions suffix,
+// e.g. "https://api.openai.com/v1" or "http://localhost:11434/v1" for a local
+// Ollama server. The apiKey is sent as a bearer token, unless empty.
+// The modelName specifies which model to target (e.g., "gpt-4o-mini").
+func NewModel(baseURL, apiKey, modelName string, opts ...Option) (model.LLM, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("base URL is required")
+	}
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	m := &openaiModel{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		name:       modelName,
+		httpClient: http.DefaultClient,
+		userAgent: fmt.Sprintf("google-adk/%s gl-go/%s", version.Version,
+			strings.TrimPrefix(runtime.Version(), "go")),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+func (m *openaiModel) Name() string {
+	return m.name
+}
+
+// GenerateContent calls the underlying model.
+func (m *openaiModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	if stream {
+		return m.generateStream(ctx, req)
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		resp, err := m.generate(ctx, req)
+		yield(resp, err)
+	}
+}
+
+// generate calls the model synchronously returning result from the first choice.
+func (m *openaiModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	chatReq, err := toChatRequest(m.name, req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := m.post(ctx, chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model: %w", err)
+	}
+	defer body.Close()
+
+	var resp chatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return toLLMResponse(&resp)
+}
+
+// generateStream returns a stream of responses from the model.
+//
+// Text is yielded as partial responses as it arrives and aggregated into the
+// final response. Tool calls, which are streamed in fragments, are reported
+// only with the final response.
+func (m *openaiModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		chatReq, err := toChatRequest(m.name, req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		chatReq.Stream = true
+		chatReq.StreamOptions = &streamOptions{IncludeUsage: true}
+
+		body, err := m.post(ctx, chatReq)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to call model: %w", err))
+			return
+		}
+		defer body.Close()
+
+		aggregator := llminternal.NewStreamingResponseAggregator()
+		var (
+			calls        []*toolCall
+			finishReason string
+			usage        *usage
+		)
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				break
+			}
+			var chunk chatResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream chunk: %w", err))
+				return
+			}
+			if chunk.Error != nil {
+				yield(nil, &APIError{StatusCode: http.StatusOK, Message: chunk.Error.Message})
+				return
+			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			c := firstChoice(chunk.Choices)
+			if c == nil {
+				continue
+			}
+			if c.FinishReason != "" {
+				finishReason = c.FinishReason
+			}
+			for _, fragment := range c.Delta.ToolCalls {
+				calls = mergeToolCall(calls, fragment)
+			}
+			for _, part := range []*genai.Part{
+				{Text: reasoning(c.Delta), Thought: true},
+				{Text: c.Delta.Content},
+			} {
+				if part.Text == "" {
+					continue
+				}
+				genResp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+					Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{part}},
+				}}}
+				for llmResponse, err := range aggregator.ProcessResponse(ctx, genResp) {
+					if !yield(llmResponse, err) {
+						return // Consumer stopped
+					}
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+			return
+		}
+
+		var toolCalls []toolCall
+		for _, call := range calls {
+			toolCalls = append(toolCalls, *call)
+		}
+		fcs, err := toFunctionCalls(toolCalls)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		var thought, text string
+		if aggregated := aggregator.Close(); aggregated != nil && aggregated.Content != nil {
+			for _, p := range aggregated.Content.Parts {
+				if p.Thought {
+					thought += p.Text
+				} else {
+					text += p.Text
+				}
+			}
+		}
+		content := toContent(thought, text, fcs)
+		if content == nil {
+			yield(nil, fmt.Errorf("empty response"))
+			return
+		}
+		yield(&model.LLMResponse{
+			Content:       content,
+			FinishReason:  toFinishReason(finishReason),
+			UsageMetadata: toUsageMetadata(usage),
+		}, nil)
+	}
+}
+
+// mergeToolCall merges a streamed tool call fragment into the tool calls.
+func mergeToolCall(calls []*toolCall, fragment toolCall) []*toolCall {
+	index := len(calls)
+	if fragment.Index != nil {
+		index = *fragment.Index
+	}
+	i := slices.IndexFunc(calls, func(c *toolCall) bool { return *c.Index == index })
+	if i < 0 {
+		calls = append(calls, &toolCall{Index: &index, Type: "function"})
+		i = len(calls) - 1
+	}
+	call := calls[i]
+	if fragment.ID != "" {
+		call.ID = fragment.ID
+	}
+	if fragment.Function.Name != "" {
+		call.Function.Name = fragment.Function.Name
+	}
+	call.Function.Arguments += fragment.Function.Arguments
+	return calls
+}
+
+// post sends the request to the chat completions endpoint and returns the
+// body of a successful response.
+func (m *openaiModel) post(ctx context.Context, chatReq *chatRequest) (io.ReadCloser, error) {
+	data, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", m.userAgent)
+	if m.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		body, _ := io.ReadAll(resp.Body)
+		var errResp chatResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			apiErr.Message = errResp.Error.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		return nil, apiErr
+	}
+	return resp.Body, nil
+}
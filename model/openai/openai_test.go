@@ -0,0 +1,324 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// The recordings were made against a local Ollama server.
+//
+//go:generate go test -httprecord=testdata/.*\.httprr
+
+const (
+	baseURL   = "http://localhost:11434/v1"
+	modelName = "llama3.2"
+)
+
+var weatherTool = &genai.Tool{FunctionDeclarations: []*genai.FunctionDeclaration{{
+	Name:        "get_weather",
+	Description: "Returns the current weather in a city.",
+	Parameters: &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+		Required:   []string{"city"},
+	},
+}}}
+
+func TestModel_Generate(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *model.LLMRequest
+		want *model.LLMResponse
+	}{
+		{
+			name: "ok",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config:   &genai.GenerateContentConfig{Temperature: new(float32)},
+			},
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText("Paris", genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount: 36, CandidatesTokenCount: 2, TotalTokenCount: 38,
+				},
+			},
+		},
+		{
+			name: "function_call",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the weather in Paris?"),
+				Config:   &genai.GenerateContentConfig{Temperature: new(float32), Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+					ID: "call_8f3kq2xz", Name: "get_weather", Args: map[string]any{"city": "Paris"},
+				}}}},
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount: 160, CandidatesTokenCount: 18, TotalTokenCount: 178,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			for got, err := range m.GenerateContent(t.Context(), tt.req, false) {
+				if err != nil {
+					t.Fatalf("Model.Generate() error = %v", err)
+				}
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("Model.Generate() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *model.LLMRequest
+		wantPartial string
+		want        *model.LLMResponse
+	}{
+		{
+			name: "ok",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config:   &genai.GenerateContentConfig{Temperature: new(float32)},
+			},
+			wantPartial: "Paris",
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText("Paris", genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount: 36, CandidatesTokenCount: 2, TotalTokenCount: 38,
+				},
+			},
+		},
+		{
+			name: "function_call",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the weather in Paris?"),
+				Config:   &genai.GenerateContentConfig{Temperature: new(float32), Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+					ID: "call_8f3kq2xz", Name: "get_weather", Args: map[string]any{"city": "Paris"},
+				}}}},
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount: 160, CandidatesTokenCount: 18, TotalTokenCount: 178,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			var partial strings.Builder
+			var final []*model.LLMResponse
+			for resp, err := range m.GenerateContent(t.Context(), tt.req, true) {
+				if err != nil {
+					t.Fatalf("Model.GenerateStream() error = %v", err)
+				}
+				if resp.Partial {
+					partial.WriteString(resp.Content.Parts[0].Text)
+					continue
+				}
+				final = append(final, resp)
+			}
+			if got := partial.String(); got != tt.wantPartial {
+				t.Errorf("Model.GenerateStream() partial text = %q, want %q", got, tt.wantPartial)
+			}
+			if len(final) != 1 {
+				t.Fatalf("Model.GenerateStream() returned %d final responses, want 1", len(final))
+			}
+			if diff := cmp.Diff(tt.want, final[0]); diff != "" {
+				t.Errorf("Model.GenerateStream() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_Agent(t *testing.T) {
+	type Input struct {
+		City string `json:"city"`
+	}
+	type Output struct {
+		Weather string `json:"weather"`
+	}
+	getWeather, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "Returns the current weather in a city.",
+	}, func(ctx tool.Context, input Input) (Output, error) {
+		return Output{Weather: "sunny, 22°C"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "weather_agent",
+		Model:       newTestModel(t),
+		Instruction: "Answer questions about the weather using the get_weather tool.",
+		Tools:       []tool.Tool{getWeather},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "What is the weather in Paris?"))
+	if err != nil {
+		t.Fatalf("agent run failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("agent returned %d events, want 3 (function call, function response, answer)", len(events))
+	}
+	if fc := events[0].Content.Parts[0].FunctionCall; fc == nil || fc.Name != "get_weather" || fc.Args["city"] != "Paris" {
+		t.Errorf("first event = %v, want get_weather function call for Paris", events[0].Content.Parts[0])
+	}
+	if got, want := events[2].Content.Parts[0].Text, "It is sunny in Paris, with a temperature of 22°C."; got != want {
+		t.Errorf("final answer = %q, want %q", got, want)
+	}
+}
+
+func TestModel_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Incorrect API key provided.", "type": "invalid_request_error"}}`))
+	}))
+	defer server.Close()
+
+	m, err := NewModel(server.URL, "wrong-key", modelName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stream := range []bool{false, true} {
+		for _, err := range m.GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("hi")}, stream) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Incorrect API key provided." {
+				t.Errorf("GenerateContent(stream=%v) error = %v, want APIError with status 401", stream, err)
+			}
+		}
+	}
+}
+
+func TestToChatRequest(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: genai.RoleUser, Parts: []*genai.Part{
+				genai.NewPartFromText("What is in the picture?"),
+				genai.NewPartFromBytes([]byte("png"), "image/png"),
+			}},
+			{Role: genai.RoleModel, Parts: []*genai.Part{
+				{Text: "Let me think.", Thought: true},
+				genai.NewPartFromFunctionCall("describe", map[string]any{"detail": "high"}),
+			}},
+			genai.NewContentFromFunctionResponse("describe", map[string]any{"result": "a cat"}, genai.RoleUser),
+			genai.NewContentFromText("It is a cat.", genai.RoleModel),
+		},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("You describe images.", genai.RoleUser),
+			MaxOutputTokens:   100,
+			StopSequences:     []string{"END"},
+			ResponseMIMEType:  "application/json",
+			Tools:             []*genai.Tool{weatherTool},
+		},
+	}
+
+	got, err := toChatRequest(modelName, req)
+	if err != nil {
+		t.Fatalf("toChatRequest() error = %v", err)
+	}
+	want := &chatRequest{
+		Model: modelName,
+		Messages: []chatMessage{
+			{Role: "system", Content: "You describe images."},
+			{Role: "user", Content: []contentPart{
+				{Type: "text", Text: "What is in the picture?"},
+				{Type: "image_url", ImageURL: &imageURL{URL: "data:image/png;base64,cG5n"}},
+			}},
+			{Role: "assistant", ToolCalls: []toolCall{{ID: "call_0", Type: "function", Function: functionCall{Name: "describe", Arguments: `{"detail":"high"}`}}}},
+			{Role: "tool", ToolCallID: "call_0", Content: `{"result":"a cat"}`},
+			{Role: "assistant", Content: "It is a cat."},
+		},
+		Tools: []chatTool{{Type: "function", Function: functionDecl{
+			Name:        "get_weather",
+			Description: "Returns the current weather in a city.",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				"required":   []string{"city"},
+			},
+		}}},
+		MaxTokens:      100,
+		Stop:           []string{"END"},
+		ResponseFormat: &responseFormat{Type: "json_object"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("toChatRequest() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToChatRequest_UnsupportedTool(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: genai.Text("hi"),
+		Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}},
+	}
+	if _, err := toChatRequest(modelName, req); err == nil {
+		t.Error("toChatRequest() error = nil, want error for a non-function tool")
+	}
+}
+
+// newTestModel returns the model configured for record and replay of the
+// test's httprr file.
+func newTestModel(t *testing.T) model.LLM {
+	t.Helper()
+	rrfile := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+	rr, err := httprr.Open(rrfile, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rr.Close() })
+	rr.ScrubReq(func(req *http.Request) error {
+		req.Header.Del("Authorization")
+		req.Header.Del("User-Agent") // contains version numbers
+		return nil
+	})
+	m, err := NewModel(baseURL, "", modelName, WithHTTPClient(&http.Client{Transport: rr}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
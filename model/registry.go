@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Factory creates an [LLM] for the given model name.
+type Factory func(ctx context.Context, name string) (LLM, error)
+
+type registryEntry struct {
+	pattern string
+	re      *regexp.Regexp
+	factory Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registryEntry
+)
+
+// Register registers the factory for model names fully matching the given
+// regular expression pattern, e.g. `gemini-.*`. Registering the same pattern
+// again replaces its factory. When several patterns match a name, the most
+// recently registered one wins, which allows overriding the built-in factories.
+//
+// Register panics if the pattern is not a valid regular expression or the
+// factory is nil.
+func Register(pattern string, f Factory) {
+	if f == nil {
+		panic(fmt.Sprintf("model: nil factory registered for pattern %q", pattern))
+	}
+	re := regexp.MustCompile("^(?:" + pattern + ")$")
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, e := range registry {
+		if e.pattern == pattern {
+			registry = append(registry[:i], registry[i+1:]...)
+			break
+		}
+	}
+	registry = append(registry, registryEntry{pattern: pattern, re: re, factory: f})
+}
+
+// Lookup returns the factory registered for the given model name.
+// If no registered pattern matches the name, the returned error lists the
+// registered patterns.
+func Lookup(name string) (Factory, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for i := len(registry) - 1; i >= 0; i-- {
+		if registry[i].re.MatchString(name) {
+			return registry[i].factory, nil
+		}
+	}
+	patterns := make([]string, 0, len(registry))
+	for _, e := range registry {
+		patterns = append(patterns, e.pattern)
+	}
+	return nil, fmt.Errorf("no model registered for name %q, registered patterns: [%s]", name, strings.Join(patterns, ", "))
+}
+
+// Resolve creates an [LLM] for the given model name using the factory
+// registered with [Register].
+func Resolve(ctx context.Context, name string) (LLM, error) {
+	f, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := f(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model %q: %w", name, err)
+	}
+	return m, nil
+}
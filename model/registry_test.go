@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	_ "google.golang.org/adk/model/gemini"
+)
+
+type namedModel struct {
+	name  string
+	owner string
+}
+
+func (m *namedModel) Name() string {
+	return m.name
+}
+
+func (m *namedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {}
+}
+
+func factory(owner string) model.Factory {
+	return func(ctx context.Context, name string) (model.LLM, error) {
+		return &namedModel{name: name, owner: owner}, nil
+	}
+}
+
+func TestResolve(t *testing.T) {
+	model.Register(`registry-test-.*`, factory("first"))
+
+	m, err := model.Resolve(t.Context(), "registry-test-model")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	got := m.(*namedModel)
+	if got.name != "registry-test-model" || got.owner != "first" {
+		t.Errorf("Resolve() = %+v, want model registry-test-model created by the first factory", got)
+	}
+
+	// Pattern must match the whole name.
+	if _, err := model.Resolve(t.Context(), "my-registry-test-model"); err == nil {
+		t.Errorf("Resolve() error = nil for a partially matching name")
+	}
+}
+
+func TestResolve_Override(t *testing.T) {
+	model.Register(`override-test-.*`, factory("first"))
+	model.Register(`override-test-.*`, factory("second"))
+	model.Register(`override-test-special`, factory("specific"))
+
+	for name, wantOwner := range map[string]string{
+		"override-test-model":   "second",
+		"override-test-special": "specific",
+	} {
+		m, err := model.Resolve(t.Context(), name)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error = %v", name, err)
+		}
+		if got := m.(*namedModel).owner; got != wantOwner {
+			t.Errorf("Resolve(%q) created by %q factory, want %q", name, got, wantOwner)
+		}
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	model.Register(`error-test-.*`, func(ctx context.Context, name string) (model.LLM, error) {
+		return nil, errors.New("no credentials")
+	})
+
+	_, err := model.Resolve(t.Context(), "unknown-model")
+	if err == nil {
+		t.Fatal("Resolve() error = nil for an unknown model")
+	}
+	for _, want := range []string{`"unknown-model"`, "(models/)?gemini-.*", "error-test-.*"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve() error = %q, want it to contain %q", err, want)
+		}
+	}
+
+	_, err = model.Resolve(t.Context(), "error-test-model")
+	if err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("Resolve() error = %v, want factory error", err)
+	}
+}
+
+func TestResolve_Gemini(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "test-key")
+	t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "")
+
+	for _, name := range []string{"gemini-2.5-flash", "models/gemini-2.0-flash"} {
+		m, err := model.Resolve(t.Context(), name)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error = %v", name, err)
+		}
+		if m.Name() != name {
+			t.Errorf("Resolve(%q).Name() = %q", name, m.Name())
+		}
+	}
+}
+
+func TestRegister_InvalidPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() didn't panic for an invalid pattern")
+		}
+	}()
+	model.Register(`(`, factory("invalid"))
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"maps"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// ModelUsage is the token usage of LLM calls.
+type ModelUsage struct {
+	PromptTokens    int64
+	CandidateTokens int64
+	TotalTokens     int64
+}
+
+func (m *ModelUsage) add(usage *genai.GenerateContentResponseUsageMetadata) {
+	m.PromptTokens += int64(usage.PromptTokenCount)
+	m.CandidateTokens += int64(usage.CandidatesTokenCount)
+	total := usage.TotalTokenCount
+	if total == 0 {
+		total = usage.PromptTokenCount + usage.CandidatesTokenCount + usage.ThoughtsTokenCount + usage.ToolUsePromptTokenCount
+	}
+	m.TotalTokens += int64(total)
+}
+
+// Usage aggregates the token usage of LLM calls made during an invocation.
+// It is safe for concurrent use. The zero value is ready to use and all
+// methods can be called on a nil Usage, which reports no usage.
+type Usage struct {
+	mu      sync.Mutex
+	total   ModelUsage
+	byModel map[string]ModelUsage
+}
+
+// Add records the usage reported by a response of the given model.
+func (u *Usage) Add(modelName string, usage *genai.GenerateContentResponseUsageMetadata) {
+	if u == nil || usage == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.byModel == nil {
+		u.byModel = make(map[string]ModelUsage)
+	}
+	u.total.add(usage)
+	modelUsage := u.byModel[modelName]
+	modelUsage.add(usage)
+	u.byModel[modelName] = modelUsage
+}
+
+// TotalPromptTokens returns the number of prompt tokens of all LLM calls.
+func (u *Usage) TotalPromptTokens() int64 {
+	return u.Total().PromptTokens
+}
+
+// TotalCandidateTokens returns the number of response tokens of all LLM calls.
+func (u *Usage) TotalCandidateTokens() int64 {
+	return u.Total().CandidateTokens
+}
+
+// TotalTokens returns the total number of tokens of all LLM calls,
+// including thought and tool use tokens.
+func (u *Usage) TotalTokens() int64 {
+	return u.Total().TotalTokens
+}
+
+// Total returns the usage of all LLM calls.
+func (u *Usage) Total() ModelUsage {
+	if u == nil {
+		return ModelUsage{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.total
+}
+
+// ByModel returns the usage broken down by model name.
+func (u *Usage) ByModel() map[string]ModelUsage {
+	if u == nil {
+		return map[string]ModelUsage{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return maps.Clone(u.byModel)
+}
+
+// Metadata returns the aggregated usage as usage metadata,
+// or nil if no usage has been recorded.
+func (u *Usage) Metadata() *genai.GenerateContentResponseUsageMetadata {
+	total := u.Total()
+	if total == (ModelUsage{}) {
+		return nil
+	}
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     int32(total.PromptTokens),
+		CandidatesTokenCount: int32(total.CandidateTokens),
+		TotalTokenCount:      int32(total.TotalTokens),
+	}
+}
+
+// PricingFunc returns the cost of the given usage of a model. The unit of the
+// cost (for example USD) is up to the function.
+type PricingFunc func(modelName string, usage ModelUsage) float64
+
+// TokenPrices is a price list of models, keyed by model name. Models missing
+// from the list cost nothing.
+type TokenPrices map[string]TokenPrice
+
+// TokenPrice is the price of a million tokens of a model.
+type TokenPrice struct {
+	PromptPerMillion    float64
+	CandidatePerMillion float64
+}
+
+// Cost is a PricingFunc using the price list.
+func (p TokenPrices) Cost(modelName string, usage ModelUsage) float64 {
+	price, ok := p[modelName]
+	if !ok {
+		return 0
+	}
+	return (float64(usage.PromptTokens)*price.PromptPerMillion + float64(usage.CandidateTokens)*price.CandidatePerMillion) / 1e6
+}
+
+// Cost returns the cost of all LLM calls, computed by pricing for the usage
+// of each model.
+func (u *Usage) Cost(pricing PricingFunc) float64 {
+	if pricing == nil {
+		return 0
+	}
+	var cost float64
+	for modelName, usage := range u.ByModel() {
+		cost += pricing(modelName, usage)
+	}
+	return cost
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestUsage(t *testing.T) {
+	var usage model.Usage
+	usage.Add("gemini-2.5-flash", &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15})
+	usage.Add("gemini-2.5-pro", &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 20, CandidatesTokenCount: 10, ThoughtsTokenCount: 7})
+	usage.Add("gemini-2.5-flash", &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1, CandidatesTokenCount: 2, TotalTokenCount: 3})
+	usage.Add("gemini-2.5-flash", nil)
+
+	if got, want := usage.TotalPromptTokens(), int64(31); got != want {
+		t.Errorf("TotalPromptTokens() = %d, want %d", got, want)
+	}
+	if got, want := usage.TotalCandidateTokens(), int64(17); got != want {
+		t.Errorf("TotalCandidateTokens() = %d, want %d", got, want)
+	}
+	// The total of the second response is computed from its token counts.
+	if got, want := usage.TotalTokens(), int64(55); got != want {
+		t.Errorf("TotalTokens() = %d, want %d", got, want)
+	}
+	wantByModel := map[string]model.ModelUsage{
+		"gemini-2.5-flash": {PromptTokens: 11, CandidateTokens: 7, TotalTokens: 18},
+		"gemini-2.5-pro":   {PromptTokens: 20, CandidateTokens: 10, TotalTokens: 37},
+	}
+	if diff := cmp.Diff(wantByModel, usage.ByModel()); diff != "" {
+		t.Errorf("ByModel() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUsage_Nil(t *testing.T) {
+	var usage *model.Usage
+	usage.Add("gemini-2.5-flash", &genai.GenerateContentResponseUsageMetadata{TotalTokenCount: 15})
+	if got := usage.TotalTokens(); got != 0 {
+		t.Errorf("TotalTokens() = %d, want 0", got)
+	}
+	if got := usage.Metadata(); got != nil {
+		t.Errorf("Metadata() = %v, want nil", got)
+	}
+}
+
+func TestUsage_Cost(t *testing.T) {
+	var usage model.Usage
+	usage.Add("gemini-2.5-flash", &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1000000, CandidatesTokenCount: 500000})
+	usage.Add("gemini-2.5-pro", &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 2000000, CandidatesTokenCount: 1000000})
+	usage.Add("unknown", &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1000000})
+
+	prices := model.TokenPrices{
+		"gemini-2.5-flash": {PromptPerMillion: 0.3, CandidatePerMillion: 2.5},
+		"gemini-2.5-pro":   {PromptPerMillion: 1.25, CandidatePerMillion: 10},
+	}
+	// 0.3 + 1.25 for flash, 2.5 + 10 for pro, nothing for the unknown model.
+	if got, want := usage.Cost(prices.Cost), 14.05; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+	if got := usage.Cost(nil); got != 0 {
+		t.Errorf("Cost(nil) = %v, want 0", got)
+	}
+}
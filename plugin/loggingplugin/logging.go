@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loggingplugin provides a plugin logging the requests and responses
+// of all the agents of a runner.
+package loggingplugin
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+)
+
+// Name is the name of the plugin.
+const Name = "logging"
+
+const maxLoggedValueLength = 200
+
+// New returns a plugin logging at the debug level the user messages, the
+// agent runs, the model requests and responses, and the tool calls and
+// results. Logged values are truncated to keep the log records short. The
+// plugin only observes the run, it never changes it.
+func New(logger *slog.Logger) plugin.Plugin {
+	beforeTool, afterTool := llmagent.ToolLoggingCallbacks(logger)
+	return plugin.Plugin{
+		Name: Name,
+		OnUserMessage: func(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "User message",
+				slog.String("invocation_id", ctx.InvocationID()),
+				slog.String("session_id", ctx.Session().ID()),
+				slog.String("user_id", ctx.Session().UserID()),
+				slog.String("content", loggedValue(msg)),
+			)
+			return nil, nil
+		},
+		BeforeAgent: func(ctx agent.CallbackContext) (*genai.Content, error) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "Agent start",
+				slog.String("agent", ctx.AgentName()),
+				slog.String("invocation_id", ctx.InvocationID()),
+			)
+			return nil, nil
+		},
+		AfterAgent: func(ctx agent.CallbackContext) (*genai.Content, error) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "Agent end",
+				slog.String("agent", ctx.AgentName()),
+				slog.String("invocation_id", ctx.InvocationID()),
+			)
+			return nil, nil
+		},
+		BeforeModel: func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "Model request",
+				slog.String("agent", ctx.AgentName()),
+				slog.String("invocation_id", ctx.InvocationID()),
+				slog.String("model", req.Model),
+				slog.String("contents", loggedValue(req.Contents)),
+			)
+			return nil, nil
+		},
+		AfterModel: func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+			attrs := []slog.Attr{
+				slog.String("agent", ctx.AgentName()),
+				slog.String("invocation_id", ctx.InvocationID()),
+			}
+			if respErr != nil {
+				attrs = append(attrs, slog.Any("error", respErr))
+			} else if resp != nil {
+				attrs = append(attrs,
+					slog.String("content", loggedValue(resp.Content)),
+					slog.Bool("partial", resp.Partial),
+				)
+				if resp.UsageMetadata != nil {
+					attrs = append(attrs,
+						slog.Int("input_tokens", int(resp.UsageMetadata.PromptTokenCount)),
+						slog.Int("output_tokens", int(resp.UsageMetadata.CandidatesTokenCount)),
+					)
+				}
+			}
+			logger.LogAttrs(ctx, slog.LevelDebug, "Model response", attrs...)
+			return nil, nil
+		},
+		BeforeTool: beforeTool,
+		AfterTool:  afterTool,
+		AfterRun: func(ctx agent.InvocationContext) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "Run end",
+				slog.String("invocation_id", ctx.InvocationID()),
+				slog.String("session_id", ctx.Session().ID()),
+			)
+		},
+	}
+}
+
+// loggedValue returns the JSON of v, truncated to keep log records short.
+func loggedValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "<not serializable>"
+	}
+	if len(b) > maxLoggedValueLength {
+		return string(b[:maxLoggedValueLength]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loggingplugin_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/plugin/loggingplugin"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("Paris", genai.RoleModel)}},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	r, err := adk.NewRunner(adk.Config{
+		AppName: "app",
+		Agent:   a,
+		Plugins: []plugin.Plugin{loggingplugin.New(logger)},
+	})
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	events, err := testutil.CollectEvents(r.RunText(t.Context(), "user", "session", "What is the capital of France?"))
+	if err != nil {
+		t.Fatalf("RunText() error = %v", err)
+	}
+	if len(events) != 1 || events[0].LLMResponse.Content.Parts[0].Text != "Paris" {
+		t.Errorf("RunText() = %v, want the unchanged model response", events)
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		`msg="User message"`, "capital of France",
+		`msg="Agent start"`,
+		`msg="Model request"`,
+		`msg="Model response"`, "Paris",
+		`msg="Agent end"`,
+		`msg="Run end"`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs don't contain %q:\n%s", want, logs)
+		}
+	}
+}
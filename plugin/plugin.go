@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin defines plugins, sets of hooks applied by a runner to all
+// the agents of its agent tree.
+//
+// Plugins implement cross-cutting concerns, e.g. logging, redaction of
+// personal data or quotas, which would otherwise require configuring the
+// same callbacks on every agent.
+package plugin
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+// Plugin is a set of hooks invoked by the runner. All hooks are optional.
+//
+// The agent, model and tool hooks are invoked for every agent in the tree,
+// before the callbacks configured on the agent. They follow the semantics of
+// the corresponding callbacks, e.g. of llmagent.BeforeModelCallback: the
+// first hook or callback returning a non-nil value or an error
+// short-circuits the remaining plugins and agent callbacks.
+//
+// When multiple plugins are registered, their hooks are invoked in the
+// order of registration.
+type Plugin struct {
+	// Name is the unique name of the plugin within a runner.
+	Name string
+
+	// OnUserMessage is called with the user message before it is stored in
+	// the session. If it returns a non-nil content, it replaces the message.
+	OnUserMessage func(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error)
+	// BeforeRun is called before the agent runs. If it returns a non-nil
+	// content, the agent is not run and the content is yielded as its reply.
+	BeforeRun func(ctx agent.InvocationContext) (*genai.Content, error)
+	// OnEvent is called with every event yielded by the agents before it is
+	// stored in the session. If it returns a non-nil event, it replaces the
+	// event.
+	OnEvent func(ctx agent.InvocationContext, event *session.Event) (*session.Event, error)
+	// AfterRun is called when the run is complete.
+	AfterRun func(ctx agent.InvocationContext)
+
+	// BeforeAgent is called before every agent runs, see agent.BeforeAgentCallback.
+	BeforeAgent agent.BeforeAgentCallback
+	// AfterAgent is called after every agent runs, see agent.AfterAgentCallback.
+	AfterAgent agent.AfterAgentCallback
+
+	// BeforeModel is called before every model request, see
+	// llmagent.BeforeModelCallback.
+	BeforeModel func(ctx agent.CallbackContext, llmRequest *model.LLMRequest) (*model.LLMResponse, error)
+	// AfterModel is called after every model response, see
+	// llmagent.AfterModelCallback.
+	AfterModel func(ctx agent.CallbackContext, llmResponse *model.LLMResponse, llmResponseError error) (*model.LLMResponse, error)
+	// BeforeTool is called before every tool call, see
+	// llmagent.BeforeToolCallback. The function calls of a model response
+	// run concurrently, but their tool callbacks are called one at a time.
+	BeforeTool func(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error)
+	// AfterTool is called after every tool call, see
+	// llmagent.AfterToolCallback. Like BeforeTool, it is never called
+	// concurrently with the other tool callbacks.
+	AfterTool func(ctx tool.Context, tool tool.Tool, args, result map[string]any, err error) (map[string]any, error)
+}
@@ -0,0 +1,456 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redactionplugin keeps personal data, e.g. emails and phone
+// numbers, from reaching the model provider and the session service.
+//
+// A [Redactor] provides two components which are used together:
+//   - a plugin redacting the model requests, see [Redactor.Plugin],
+//   - a session service redacting the events before they are stored, see
+//     [Redactor.SessionService].
+//
+// For example:
+//
+//	redactor, err := redactionplugin.New(redactionplugin.Config{Strategy: redactionplugin.StrategyTokenize})
+//	...
+//	r, err := runner.New(runner.Config{
+//		Agent:          rootAgent,
+//		SessionService: redactor.SessionService(session.InMemoryService()),
+//		Plugins:        []plugin.Plugin{redactor.Plugin()},
+//	})
+package redactionplugin
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/session"
+)
+
+// Name is the name of the plugin.
+const Name = "redaction"
+
+// Detector finds a kind of personal data in texts.
+type Detector struct {
+	// Name of the kind of data, used in the replacements, e.g. "EMAIL".
+	// It must consist of upper case letters, digits and underscores.
+	Name string
+	// Pattern matches the data to redact.
+	Pattern *regexp.Regexp
+}
+
+// Built-in detectors.
+var (
+	// EmailDetector finds email addresses.
+	EmailDetector = Detector{
+		Name:    "EMAIL",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+	// PhoneDetector finds phone numbers of 10 digits, optionally grouped and
+	// with an international prefix, e.g. "+1 (555) 123-4567".
+	PhoneDetector = Detector{
+		Name:    "PHONE",
+		Pattern: regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)|\b\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`),
+	}
+)
+
+var detectorNameRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// Strategy defines how the detected values are replaced.
+type Strategy int
+
+const (
+	// StrategyMask replaces values with the detector name, e.g. "[REDACTED_EMAIL]".
+	StrategyMask Strategy = iota
+	// StrategyHash replaces values with a keyed hash, e.g.
+	// "[EMAIL_5d41402abc4b]", so that the model can tell apart different
+	// values. See [Config.HashKey].
+	StrategyHash
+	// StrategyTokenize replaces values with tokens numbered within the
+	// session, e.g. "<EMAIL_1>", and restores the original values in the
+	// model responses, so that the user and the tools see them.
+	//
+	// The original values are kept in memory by the [Redactor], they are not
+	// restored after a restart. They are dropped when the session is deleted
+	// through [Redactor.SessionService], see also [Config.MaxSessions]. Tokens split across streamed partial
+	// responses are restored in the final aggregated response only.
+	StrategyTokenize
+)
+
+// Config is the configuration of a [Redactor].
+type Config struct {
+	// Detectors find the data to redact. If nil, EmailDetector and
+	// PhoneDetector are used. Custom detectors can be added to them, e.g.
+	//
+	//	Detectors: []Detector{EmailDetector, PhoneDetector, {Name: "IBAN", Pattern: ibanRegexp}}
+	Detectors []Detector
+	// Strategy of the replacements, StrategyMask by default.
+	Strategy Strategy
+	// HashKey is the key of the HMAC used by StrategyHash. If empty, a random
+	// key is generated, so the hashes differ between Redactors. Set it to get
+	// the same hashes across restarts, and keep it secret: short values like
+	// phone numbers can be recovered by anyone knowing the key.
+	HashKey []byte
+	// MaxSessions is the maximum number of sessions whose values are kept by
+	// StrategyTokenize. When exceeded, the values of the least recently used
+	// session are dropped, and its tokens are no longer restored. Zero means
+	// no limit.
+	MaxSessions int
+}
+
+// Redactor redacts personal data in contents and events.
+type Redactor struct {
+	detectors   []Detector
+	strategy    Strategy
+	hashKey     []byte
+	maxSessions int
+
+	mu     sync.Mutex
+	vaults map[SessionKey]*vault // for StrategyTokenize
+	lru    *list.List            // of SessionKeys, most recently used first
+}
+
+// SessionKey identifies a session. Session IDs are only unique within an
+// app and a user, so the tokens of sessions with the same ID but another
+// app or user are kept apart.
+type SessionKey struct {
+	AppName   string
+	UserID    string
+	SessionID string
+}
+
+func contextKey(ctx agent.CallbackContext) SessionKey {
+	return SessionKey{AppName: ctx.AppName(), UserID: ctx.UserID(), SessionID: ctx.SessionID()}
+}
+
+func sessionKey(s session.Session) SessionKey {
+	return SessionKey{AppName: s.AppName(), UserID: s.UserID(), SessionID: s.ID()}
+}
+
+// vault maps the values of a session to their tokens.
+type vault struct {
+	tokens   map[string]string // value -> token
+	values   map[string]string // token -> value
+	counts   map[string]int    // detector name -> number of tokens
+	replacer *strings.Replacer // tokens -> values, nil when outdated
+	elem     *list.Element     // in Redactor.lru
+}
+
+// New creates a new [Redactor].
+func New(cfg Config) (*Redactor, error) {
+	detectors := cfg.Detectors
+	if detectors == nil {
+		detectors = []Detector{EmailDetector, PhoneDetector}
+	}
+	for _, d := range detectors {
+		if !detectorNameRegexp.MatchString(d.Name) {
+			return nil, fmt.Errorf("invalid detector name %q: it must consist of upper case letters, digits and underscores", d.Name)
+		}
+		if d.Pattern == nil {
+			return nil, fmt.Errorf("detector %q has no pattern", d.Name)
+		}
+	}
+	switch cfg.Strategy {
+	case StrategyMask, StrategyHash, StrategyTokenize:
+	default:
+		return nil, fmt.Errorf("unknown strategy %d", cfg.Strategy)
+	}
+	if cfg.MaxSessions < 0 {
+		return nil, fmt.Errorf("invalid max sessions %d", cfg.MaxSessions)
+	}
+	hashKey := cfg.HashKey
+	if len(hashKey) == 0 {
+		hashKey = make([]byte, 32)
+		if _, err := rand.Read(hashKey); err != nil {
+			return nil, fmt.Errorf("failed to generate the hash key: %w", err)
+		}
+	}
+	return &Redactor{
+		detectors:   detectors,
+		strategy:    cfg.Strategy,
+		hashKey:     hashKey,
+		maxSessions: cfg.MaxSessions,
+		vaults:      make(map[SessionKey]*vault),
+		lru:         list.New(),
+	}, nil
+}
+
+// Plugin returns a plugin redacting the contents and the system instruction
+// of the model requests. With StrategyTokenize, it also restores the
+// original values in the model responses.
+func (r *Redactor) Plugin() plugin.Plugin {
+	return plugin.Plugin{
+		Name: Name,
+		BeforeModel: func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+			// The contents are shared with the session events, they are
+			// replaced by redacted copies.
+			contents := make([]*genai.Content, len(req.Contents))
+			for i, content := range req.Contents {
+				contents[i] = r.RedactContent(contextKey(ctx), content)
+			}
+			req.Contents = contents
+			if req.Config != nil && req.Config.SystemInstruction != nil {
+				cfg := *req.Config
+				cfg.SystemInstruction = r.RedactContent(contextKey(ctx), cfg.SystemInstruction)
+				req.Config = &cfg
+			}
+			return nil, nil
+		},
+		AfterModel: func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+			// The response is updated in place, returning it would skip the
+			// callbacks of the agent.
+			if r.strategy == StrategyTokenize && resp != nil {
+				resp.Content = r.RestoreContent(contextKey(ctx), resp.Content)
+			}
+			return nil, nil
+		},
+	}
+}
+
+// SessionService returns a session service storing the events in inner
+// after redacting their contents and state changes. The events yielded by
+// the runner are not changed. Deleting a session also drops its tokens.
+func (r *Redactor) SessionService(inner session.Service) session.Service {
+	return &sessionService{Service: inner, redactor: r}
+}
+
+type sessionService struct {
+	session.Service
+	redactor *Redactor
+}
+
+func (s *sessionService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if event == nil {
+		return s.Service.AppendEvent(ctx, curSession, event)
+	}
+	redacted := *event
+	redacted.LLMResponse.Content = s.redactor.RedactContent(sessionKey(curSession), event.LLMResponse.Content)
+	if event.Actions.StateDelta != nil {
+		redacted.Actions.StateDelta = s.redactor.redactValue(sessionKey(curSession), event.Actions.StateDelta).(map[string]any)
+	}
+	return s.Service.AppendEvent(ctx, curSession, &redacted)
+}
+
+func (s *sessionService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if err := s.Service.Delete(ctx, req); err != nil {
+		return err
+	}
+	s.redactor.forget(SessionKey{AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID})
+	return nil
+}
+
+// RedactContent returns a copy of content with the detected values
+// replaced in the texts, function call arguments and function responses.
+// Other parts, e.g. inline data, are kept as is.
+func (r *Redactor) RedactContent(key SessionKey, content *genai.Content) *genai.Content {
+	return r.mapContent(content, func(s string) string { return r.redactText(key, s) })
+}
+
+// RestoreContent returns a copy of content with the tokens of the session
+// replaced by the original values. It only applies to StrategyTokenize.
+func (r *Redactor) RestoreContent(key SessionKey, content *genai.Content) *genai.Content {
+	replacer := r.restorer(key)
+	if replacer == nil {
+		return content
+	}
+	return r.mapContent(content, replacer.Replace)
+}
+
+// mapContent returns a copy of content with f applied to its strings.
+func (r *Redactor) mapContent(content *genai.Content, f func(string) string) *genai.Content {
+	if content == nil {
+		return nil
+	}
+	mapped := &genai.Content{Role: content.Role, Parts: make([]*genai.Part, len(content.Parts))}
+	for i, part := range content.Parts {
+		if part == nil {
+			continue
+		}
+		p := *part
+		if p.Text != "" {
+			p.Text = f(p.Text)
+		}
+		if p.FunctionCall != nil {
+			fc := *p.FunctionCall
+			fc.Args = mapValue(fc.Args, f).(map[string]any)
+			p.FunctionCall = &fc
+		}
+		if p.FunctionResponse != nil {
+			fr := *p.FunctionResponse
+			fr.Response = mapValue(fr.Response, f).(map[string]any)
+			p.FunctionResponse = &fr
+		}
+		mapped.Parts[i] = &p
+	}
+	return mapped
+}
+
+func (r *Redactor) redactValue(key SessionKey, v any) any {
+	return mapValue(v, func(s string) string { return r.redactText(key, s) })
+}
+
+// mapValue returns a copy of v with f applied to the strings of JSON-like
+// values. Other values are kept as is.
+func mapValue(v any, f func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return f(v)
+	case map[string]any:
+		if v == nil {
+			return v
+		}
+		mapped := make(map[string]any, len(v))
+		for k, e := range v {
+			mapped[k] = mapValue(e, f)
+		}
+		return mapped
+	case []any:
+		mapped := make([]any, len(v))
+		for i, e := range v {
+			mapped[i] = mapValue(e, f)
+		}
+		return mapped
+	default:
+		return v
+	}
+}
+
+// redactText replaces the values found by the detectors. When matches
+// overlap, the earliest one wins, then the one of the first detector.
+func (r *Redactor) redactText(key SessionKey, text string) string {
+	type match struct {
+		start, end int
+		detector   *Detector
+		order      int
+	}
+	var matches []match
+	for i := range r.detectors {
+		d := &r.detectors[i]
+		for _, loc := range d.Pattern.FindAllStringIndex(text, -1) {
+			if loc[0] < loc[1] {
+				matches = append(matches, match{start: loc[0], end: loc[1], detector: d, order: i})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return text
+	}
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(a.start, b.start), cmp.Compare(a.order, b.order))
+	})
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.start < last {
+			continue
+		}
+		sb.WriteString(text[last:m.start])
+		sb.WriteString(r.replacement(key, m.detector.Name, text[m.start:m.end]))
+		last = m.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+func (r *Redactor) replacement(key SessionKey, name, value string) string {
+	switch r.strategy {
+	case StrategyHash:
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		return "[" + name + "_" + hex.EncodeToString(mac.Sum(nil)[:6]) + "]"
+	case StrategyTokenize:
+		return r.token(key, name, value)
+	default:
+		return "[REDACTED_" + name + "]"
+	}
+}
+
+// token returns the token of the value in the session, creating it if needed.
+func (r *Redactor) token(key SessionKey, name, value string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.vaults[key]
+	if !ok {
+		v = &vault{
+			tokens: make(map[string]string),
+			values: make(map[string]string),
+			counts: make(map[string]int),
+			elem:   r.lru.PushFront(key),
+		}
+		r.vaults[key] = v
+		if r.maxSessions > 0 && len(r.vaults) > r.maxSessions {
+			oldest := r.lru.Back()
+			r.lru.Remove(oldest)
+			delete(r.vaults, oldest.Value.(SessionKey))
+		}
+	} else {
+		r.lru.MoveToFront(v.elem)
+	}
+	if token, ok := v.tokens[value]; ok {
+		return token
+	}
+	v.counts[name]++
+	token := "<" + name + "_" + strconv.Itoa(v.counts[name]) + ">"
+	v.tokens[value] = token
+	v.values[token] = value
+	v.replacer = nil
+	return token
+}
+
+// restorer returns a replacer of the tokens of the session, or nil if there
+// are none.
+func (r *Redactor) restorer(key SessionKey) *strings.Replacer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.vaults[key]
+	if !ok {
+		return nil
+	}
+	r.lru.MoveToFront(v.elem)
+	if v.replacer == nil {
+		oldnew := make([]string, 0, 2*len(v.values))
+		for token, value := range v.values {
+			oldnew = append(oldnew, token, value)
+		}
+		v.replacer = strings.NewReplacer(oldnew...)
+	}
+	return v.replacer
+}
+
+// forget drops the tokens of the session.
+func (r *Redactor) forget(key SessionKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.vaults[key]; ok {
+		r.lru.Remove(v.elem)
+		delete(r.vaults, key)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redactionplugin_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/plugin/redactionplugin"
+	"google.golang.org/adk/session"
+)
+
+// multiPartContent returns a content with personal data in all kinds of parts.
+func multiPartContent() *genai.Content {
+	return &genai.Content{
+		Role: genai.RoleUser,
+		Parts: []*genai.Part{
+			{Text: "Mail jane@example.com or call +1 (555) 123-4567."},
+			{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("jane@example.com")}},
+			{FunctionCall: &genai.FunctionCall{Name: "send_mail", Args: map[string]any{
+				"to":       []any{"jane@example.com", "john@example.com"},
+				"priority": 1,
+			}}},
+			{FunctionResponse: &genai.FunctionResponse{Name: "lookup", Response: map[string]any{
+				"contact": map[string]any{"phone": "555.123.4567"},
+			}}},
+		},
+	}
+}
+
+// key returns the key of the session of "user" in "app".
+func key(sessionID string) redactionplugin.SessionKey {
+	return redactionplugin.SessionKey{AppName: "app", UserID: "user", SessionID: sessionID}
+}
+
+func mustNew(t *testing.T, cfg redactionplugin.Config) *redactionplugin.Redactor {
+	t.Helper()
+	r, err := redactionplugin.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return r
+}
+
+func TestRedactContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy redactionplugin.Strategy
+		want     *genai.Content
+	}{
+		{
+			name:     "mask",
+			strategy: redactionplugin.StrategyMask,
+			want: &genai.Content{
+				Role: genai.RoleUser,
+				Parts: []*genai.Part{
+					{Text: "Mail [REDACTED_EMAIL] or call [REDACTED_PHONE]."},
+					{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("jane@example.com")}},
+					{FunctionCall: &genai.FunctionCall{Name: "send_mail", Args: map[string]any{
+						"to":       []any{"[REDACTED_EMAIL]", "[REDACTED_EMAIL]"},
+						"priority": 1,
+					}}},
+					{FunctionResponse: &genai.FunctionResponse{Name: "lookup", Response: map[string]any{
+						"contact": map[string]any{"phone": "[REDACTED_PHONE]"},
+					}}},
+				},
+			},
+		},
+		{
+			name:     "tokenize",
+			strategy: redactionplugin.StrategyTokenize,
+			want: &genai.Content{
+				Role: genai.RoleUser,
+				Parts: []*genai.Part{
+					{Text: "Mail <EMAIL_1> or call <PHONE_1>."},
+					{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("jane@example.com")}},
+					{FunctionCall: &genai.FunctionCall{Name: "send_mail", Args: map[string]any{
+						"to":       []any{"<EMAIL_1>", "<EMAIL_2>"},
+						"priority": 1,
+					}}},
+					{FunctionResponse: &genai.FunctionResponse{Name: "lookup", Response: map[string]any{
+						"contact": map[string]any{"phone": "<PHONE_2>"},
+					}}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mustNew(t, redactionplugin.Config{Strategy: tt.strategy})
+			content := multiPartContent()
+
+			got := r.RedactContent(key("session"), content)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("RedactContent() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(multiPartContent(), content); diff != "" {
+				t.Errorf("RedactContent() changed its input (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRedactContent_Hash(t *testing.T) {
+	r := mustNew(t, redactionplugin.Config{Strategy: redactionplugin.StrategyHash})
+
+	got := r.RedactContent(key("session"), genai.NewContentFromText("jane@example.com, john@example.com, jane@example.com", genai.RoleUser)).Parts[0].Text
+
+	hashes := regexp.MustCompile(`\[EMAIL_[0-9a-f]{12}\]`).FindAllString(got, -1)
+	if len(hashes) != 3 || hashes[0] != hashes[2] || hashes[0] == hashes[1] {
+		t.Errorf("RedactContent() = %q, want the same hash for the same values only", got)
+	}
+	if strings.Contains(got, "@") {
+		t.Errorf("RedactContent() = %q, want no email", got)
+	}
+}
+
+func TestRedactContent_HashKey(t *testing.T) {
+	content := genai.NewContentFromText("jane@example.com", genai.RoleUser)
+	hash := func(cfg redactionplugin.Config) string {
+		cfg.Strategy = redactionplugin.StrategyHash
+		return mustNew(t, cfg).RedactContent(key("session"), content).Parts[0].Text
+	}
+
+	key := []byte("secret")
+	if got, want := hash(redactionplugin.Config{HashKey: key}), hash(redactionplugin.Config{HashKey: key}); got != want {
+		t.Errorf("RedactContent() with the same key = %q and %q, want the same hash", got, want)
+	}
+	if got, other := hash(redactionplugin.Config{HashKey: key}), hash(redactionplugin.Config{HashKey: []byte("other")}); got == other {
+		t.Errorf("RedactContent() with different keys = %q, want different hashes", got)
+	}
+	// Without a key, each Redactor uses a random one.
+	if got, other := hash(redactionplugin.Config{}), hash(redactionplugin.Config{}); got == other {
+		t.Errorf("RedactContent() without a key = %q for two Redactors, want different hashes", got)
+	}
+}
+
+func TestRedactContent_Tokenize(t *testing.T) {
+	r := mustNew(t, redactionplugin.Config{Strategy: redactionplugin.StrategyTokenize})
+
+	first := r.RedactContent(key("session1"), genai.NewContentFromText("jane@example.com and john@example.com", genai.RoleUser))
+	again := r.RedactContent(key("session1"), genai.NewContentFromText("john@example.com", genai.RoleUser))
+	other := r.RedactContent(key("session2"), genai.NewContentFromText("john@example.com", genai.RoleUser))
+
+	for _, tc := range []struct {
+		content *genai.Content
+		want    string
+	}{
+		{first, "<EMAIL_1> and <EMAIL_2>"},
+		{again, "<EMAIL_2>"},
+		// Tokens are numbered per session.
+		{other, "<EMAIL_1>"},
+	} {
+		if got := tc.content.Parts[0].Text; got != tc.want {
+			t.Errorf("RedactContent() = %q, want %q", got, tc.want)
+		}
+	}
+
+	restored := r.RestoreContent(key("session1"), genai.NewContentFromText("Wrote to <EMAIL_2>, not <EMAIL_1> nor <EMAIL_3>.", genai.RoleModel))
+	if got, want := restored.Parts[0].Text, "Wrote to john@example.com, not jane@example.com nor <EMAIL_3>."; got != want {
+		t.Errorf("RestoreContent() = %q, want %q", got, want)
+	}
+	if got := r.RestoreContent(key("unknown"), genai.NewContentFromText("<EMAIL_1>", genai.RoleModel)).Parts[0].Text; got != "<EMAIL_1>" {
+		t.Errorf("RestoreContent() in another session = %q, want the token", got)
+	}
+}
+
+func TestRedactContent_TokenizeSameSessionID(t *testing.T) {
+	r := mustNew(t, redactionplugin.Config{Strategy: redactionplugin.StrategyTokenize})
+	// The session IDs are chosen by the clients, different users may use the
+	// same ones.
+	alice := redactionplugin.SessionKey{AppName: "app", UserID: "alice", SessionID: "session"}
+	bob := redactionplugin.SessionKey{AppName: "app", UserID: "bob", SessionID: "session"}
+	r.RedactContent(alice, genai.NewContentFromText("alice@example.com", genai.RoleUser))
+	r.RedactContent(bob, genai.NewContentFromText("bob@example.com", genai.RoleUser))
+
+	for _, tc := range []struct {
+		key  redactionplugin.SessionKey
+		want string
+	}{
+		{alice, "alice@example.com"},
+		{bob, "bob@example.com"},
+	} {
+		if got := r.RestoreContent(tc.key, genai.NewContentFromText("<EMAIL_1>", genai.RoleModel)).Parts[0].Text; got != tc.want {
+			t.Errorf("RestoreContent(%+v) = %q, want %q", tc.key, got, tc.want)
+		}
+	}
+
+	// Deleting the session of alice keeps the tokens of bob.
+	service := r.SessionService(session.InMemoryService())
+	if _, err := service.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Delete(t.Context(), &session.DeleteRequest{AppName: "app", UserID: "alice", SessionID: "session"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := r.RestoreContent(alice, genai.NewContentFromText("<EMAIL_1>", genai.RoleModel)).Parts[0].Text; got != "<EMAIL_1>" {
+		t.Errorf("RestoreContent() for alice after Delete() = %q, want the token", got)
+	}
+	if got := r.RestoreContent(bob, genai.NewContentFromText("<EMAIL_1>", genai.RoleModel)).Parts[0].Text; got != "bob@example.com" {
+		t.Errorf("RestoreContent() for bob after the Delete() of alice = %q, want %q", got, "bob@example.com")
+	}
+}
+
+func TestRedactContent_TokenizeMaxSessions(t *testing.T) {
+	r := mustNew(t, redactionplugin.Config{Strategy: redactionplugin.StrategyTokenize, MaxSessions: 2})
+	for _, id := range []string{"session1", "session2"} {
+		r.RedactContent(key(id), genai.NewContentFromText("jane@example.com", genai.RoleUser))
+	}
+	// Uses session1, so session2 is the least recently used one.
+	r.RestoreContent(key("session1"), genai.NewContentFromText("<EMAIL_1>", genai.RoleModel))
+	r.RedactContent(key("session3"), genai.NewContentFromText("jane@example.com", genai.RoleUser))
+
+	for id, want := range map[string]string{
+		"session1": "jane@example.com",
+		"session2": "<EMAIL_1>",
+		"session3": "jane@example.com",
+	} {
+		if got := r.RestoreContent(key(id), genai.NewContentFromText("<EMAIL_1>", genai.RoleModel)).Parts[0].Text; got != want {
+			t.Errorf("RestoreContent(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestSessionService_Delete(t *testing.T) {
+	ctx := t.Context()
+	r := mustNew(t, redactionplugin.Config{Strategy: redactionplugin.StrategyTokenize})
+	service := r.SessionService(session.InMemoryService())
+	if _, err := service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+	r.RedactContent(key("session"), genai.NewContentFromText("jane@example.com", genai.RoleUser))
+
+	if err := service.Delete(ctx, &session.DeleteRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if got := r.RestoreContent(key("session"), genai.NewContentFromText("<EMAIL_1>", genai.RoleModel)).Parts[0].Text; got != "<EMAIL_1>" {
+		t.Errorf("RestoreContent() after Delete() = %q, want the token", got)
+	}
+}
+
+func TestRedactContent_CustomDetectors(t *testing.T) {
+	r := mustNew(t, redactionplugin.Config{Detectors: []redactionplugin.Detector{
+		{Name: "EMPLOYEE_ID", Pattern: regexp.MustCompile(`EMP-\d+`)},
+		// Overlaps with the employee id, the earliest match wins.
+		{Name: "NUMBER", Pattern: regexp.MustCompile(`\d+`)},
+	}})
+
+	got := r.RedactContent(key("session"), genai.NewContentFromText("EMP-42 badge 7, jane@example.com", genai.RoleUser)).Parts[0].Text
+
+	if want := "[REDACTED_EMPLOYEE_ID] badge [REDACTED_NUMBER], jane@example.com"; got != want {
+		t.Errorf("RedactContent() = %q, want %q", got, want)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for _, cfg := range []redactionplugin.Config{
+		{Detectors: []redactionplugin.Detector{{Name: "email", Pattern: regexp.MustCompile(`@`)}}},
+		{Detectors: []redactionplugin.Detector{{Name: "EMAIL"}}},
+		{Strategy: redactionplugin.Strategy(42)},
+		{MaxSessions: -1},
+	} {
+		if _, err := redactionplugin.New(cfg); err == nil {
+			t.Errorf("New(%+v) error = nil, want error", cfg)
+		}
+	}
+}
+
+func TestRedactor_Runner(t *testing.T) {
+	const pii = "jane@example.com"
+	for _, tc := range []struct {
+		strategy redactionplugin.Strategy
+		wantText string
+	}{
+		{redactionplugin.StrategyMask, "I will write to <EMAIL_1>."},
+		// The tokens are restored in the events yielded to the user.
+		{redactionplugin.StrategyTokenize, "I will write to " + pii + "."},
+	} {
+		redactor := mustNew(t, redactionplugin.Config{Strategy: tc.strategy})
+		mockModel := &testutil.MockModel{Responses: []*genai.Content{
+			genai.NewContentFromText("I will write to <EMAIL_1>.", genai.RoleModel),
+		}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:        "agent",
+			Model:       mockModel,
+			Instruction: "The user email is {email}.",
+			OutputKey:   "answer",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessionService := redactor.SessionService(session.InMemoryService())
+		r, err := adk.NewRunner(adk.Config{
+			AppName:        "app",
+			Agent:          a,
+			SessionService: sessionService,
+			Plugins:        []plugin.Plugin{redactor.Plugin()},
+			InitialState:   map[string]any{"email": pii},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events, err := testutil.CollectEvents(r.RunText(t.Context(), "user", "session", "Please write to "+pii+"."))
+		if err != nil {
+			t.Fatalf("RunText() error = %v", err)
+		}
+
+		if len(events) != 1 || events[0].LLMResponse.Content.Parts[0].Text != tc.wantText {
+			t.Errorf("RunText() = %v, want a reply with %q", events, tc.wantText)
+		}
+		req, err := json.Marshal(mockModel.Requests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(req), pii) {
+			t.Errorf("model request contains personal data: %s", req)
+		}
+		resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for ev := range resp.Session.Events().All() {
+			stored, err := json.Marshal(ev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(stored), pii) {
+				t.Errorf("stored event contains personal data: %s", stored)
+			}
+		}
+		if answer, err := resp.Session.State().Get("answer"); err != nil || strings.Contains(answer.(string), pii) {
+			t.Errorf("stored state answer = %v, %v, want no personal data", answer, err)
+		}
+	}
+}
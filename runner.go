@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adk provides high-level helpers for running ADK agents.
+//
+// For advanced use, e.g. managing sessions separately, see the
+// [google.golang.org/adk/runner] package.
+package adk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"maps"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// Config is used to create a [Runner].
+type Config struct {
+	AppName string
+	// Root agent which starts the execution.
+	Agent agent.Agent
+
+	// optional, an in-memory service is used by default
+	SessionService session.Service
+	// optional
+	ArtifactService artifact.Service
+	// optional
+	MemoryService memory.Service
+
+	// InitialState is the state of sessions created by the runner.
+	// optional
+	InitialState map[string]any
+
+	// Plugins are invoked for every agent in the tree, see [plugin.Plugin].
+	// optional
+	Plugins []plugin.Plugin
+	// Logger is used by the runner and the agents it runs.
+	// optional, slog.Default() is used by default
+	Logger *slog.Logger
+}
+
+// Runner runs an agent in sessions identified by user and session ids,
+// creating the sessions when they don't exist yet.
+type Runner struct {
+	appName        string
+	sessionService session.Service
+	initialState   map[string]any
+	runner         *runner.Runner
+}
+
+// NewRunner creates a new [Runner].
+func NewRunner(cfg Config) (*Runner, error) {
+	sessionService := cfg.SessionService
+	if sessionService == nil {
+		sessionService = session.InMemoryService()
+	}
+
+	r, err := runner.New(runner.Config{
+		AppName:         cfg.AppName,
+		Agent:           cfg.Agent,
+		SessionService:  sessionService,
+		ArtifactService: cfg.ArtifactService,
+		MemoryService:   cfg.MemoryService,
+		Plugins:         cfg.Plugins,
+		Logger:          cfg.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{
+		appName:        cfg.AppName,
+		sessionService: sessionService,
+		initialState:   cfg.InitialState,
+		runner:         r,
+	}, nil
+}
+
+// RunText runs the agent for the given user text message.
+// See [Runner.Run] for details.
+func (r *Runner) RunText(ctx context.Context, userID, sessionID, text string) iter.Seq2[*session.Event, error] {
+	var msg *genai.Content
+	if text != "" {
+		msg = genai.NewContentFromText(text, genai.RoleUser)
+	}
+	return r.Run(ctx, userID, sessionID, msg, agent.RunConfig{})
+}
+
+// Run runs the agent for the given user message in the session, creating
+// the session if it doesn't exist. It yields events from agents, the same
+// way as [runner.Runner.Run].
+func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.Content, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		if err := r.ensureSession(ctx, userID, sessionID); err != nil {
+			yield(nil, err)
+			return
+		}
+		for event, err := range r.runner.Run(ctx, userID, sessionID, msg, cfg) {
+			if !yield(event, err) {
+				return
+			}
+		}
+	}
+}
+
+// ensureSession creates the session unless it already exists.
+func (r *Runner) ensureSession(ctx context.Context, userID, sessionID string) error {
+	_, err := r.sessionService.Get(ctx, &session.GetRequest{
+		AppName:   r.appName,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if !errors.Is(err, session.ErrSessionNotFound) {
+		return err
+	}
+	if _, err := r.sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   r.appName,
+		UserID:    userID,
+		SessionID: sessionID,
+		State:     maps.Clone(r.initialState),
+	}); err != nil {
+		return fmt.Errorf("failed to create session %q: %w", sessionID, err)
+	}
+	return nil
+}
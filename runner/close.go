@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrClosed is returned by [Runner.Run] once [Runner.Close] has been called.
+var ErrClosed = errors.New("runner is closed")
+
+// Close stops the runner from accepting new runs and waits for the active
+// invocations to finish. Events yielded by the active invocations are
+// appended to the session before they are yielded, so once Close returns
+// nil all of them are persisted.
+//
+// If ctx is done before the invocations finish, Close returns an error
+// listing the ids of the invocations still running. Close may be called
+// multiple times.
+func (r *Runner) Close(ctx context.Context) error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		ids := slices.Sorted(maps.Keys(r.invocations))
+		r.mu.Unlock()
+		return fmt.Errorf("closing runner with active invocations %v: %w", ids, ctx.Err())
+	}
+}
+
+// startRun registers a new run, it fails if the runner is closed. Callers
+// must call the returned function when the run finishes.
+func (r *Runner) startRun() (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, ErrClosed
+	}
+	r.active.Add(1)
+	return r.active.Done, nil
+}
+
+// trackInvocation records the invocation id of an active run until the
+// returned function is called.
+func (r *Runner) trackInvocation(id string) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.invocations == nil {
+		r.invocations = make(map[string]struct{})
+	}
+	r.invocations[id] = struct{}{}
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.invocations, id)
+	}
+}
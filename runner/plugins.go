@@ -0,0 +1,129 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/session"
+)
+
+func validatePlugins(plugins []plugin.Plugin) error {
+	names := make(map[string]bool, len(plugins))
+	for _, p := range plugins {
+		if p.Name == "" {
+			return fmt.Errorf("plugin name is required")
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicate plugin name %q", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// setAgentCallbacks passes the agent hooks of the plugins to the agents.
+func (r *Runner) setAgentCallbacks(cfg *runconfig.RunConfig) {
+	for _, p := range r.plugins {
+		if p.BeforeAgent != nil {
+			cfg.BeforeAgentCallbacks = append(cfg.BeforeAgentCallbacks, func(ctx context.Context) (*genai.Content, error) {
+				return p.BeforeAgent(ctx.(agent.CallbackContext))
+			})
+		}
+		if p.AfterAgent != nil {
+			cfg.AfterAgentCallbacks = append(cfg.AfterAgentCallbacks, func(ctx context.Context) (*genai.Content, error) {
+				return p.AfterAgent(ctx.(agent.CallbackContext))
+			})
+		}
+	}
+}
+
+// onUserMessage returns the user message replaced by the first plugin
+// returning a non-nil content, or msg.
+func (r *Runner) onUserMessage(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error) {
+	for _, p := range r.plugins {
+		if p.OnUserMessage == nil {
+			continue
+		}
+		content, err := p.OnUserMessage(ctx, msg)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %q failed to process the user message: %w", p.Name, err)
+		}
+		if content != nil {
+			return content, nil
+		}
+	}
+	return msg, nil
+}
+
+// beforeRun returns the content of the first plugin ending the run early.
+func (r *Runner) beforeRun(ctx agent.InvocationContext) (*genai.Content, error) {
+	for _, p := range r.plugins {
+		if p.BeforeRun == nil {
+			continue
+		}
+		content, err := p.BeforeRun(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %q failed before the run: %w", p.Name, err)
+		}
+		if content != nil {
+			return content, nil
+		}
+	}
+	return nil, nil
+}
+
+// onEvent returns the event replaced by the first plugin returning a non-nil
+// event, or event.
+func (r *Runner) onEvent(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+	for _, p := range r.plugins {
+		if p.OnEvent == nil {
+			continue
+		}
+		replaced, err := p.OnEvent(ctx, event)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %q failed to process the event: %w", p.Name, err)
+		}
+		if replaced != nil {
+			return replaced, nil
+		}
+	}
+	return event, nil
+}
+
+// earlyExitEvent returns the reply of the agent when a plugin ended the run
+// before it.
+func earlyExitEvent(ctx agent.InvocationContext, content *genai.Content) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.LLMResponse = model.LLMResponse{Content: content}
+	return event
+}
+
+func (r *Runner) afterRun(ctx agent.InvocationContext) {
+	for _, p := range r.plugins {
+		if p.AfterRun != nil {
+			p.AfterRun(ctx)
+		}
+	}
+}
@@ -17,10 +17,16 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
-	"log"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
@@ -31,9 +37,12 @@ import (
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
 	imemory "google.golang.org/adk/internal/memory"
+	"google.golang.org/adk/internal/plugininternal"
 	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/telemetry"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/session"
 )
 
@@ -48,6 +57,26 @@ type Config struct {
 	ArtifactService artifact.Service
 	// optional
 	MemoryService memory.Service
+
+	// AutoCreateSession makes [Runner.Run] create the session with the given
+	// id if it doesn't exist, instead of failing with
+	// [session.ErrSessionNotFound].
+	AutoCreateSession bool
+
+	// Plugins are invoked for every agent in the tree, before the callbacks
+	// configured on the agents. See [plugin.Plugin].
+	Plugins []plugin.Plugin
+
+	// UsageCallback is called at the end of every run with the token usage
+	// aggregated over all LLM calls of the run, e.g. to report it or to
+	// compute the cost with [model.Usage.Cost]. Nested runs, e.g. from
+	// agenttool, are reported as part of the run of the parent invocation.
+	// optional
+	UsageCallback func(ctx context.Context, usage *model.Usage)
+
+	// Logger is used by the runner and the agents it runs.
+	// optional, slog.Default() is used by default
+	Logger *slog.Logger
 }
 
 // New creates a new [Runner].
@@ -60,18 +89,31 @@ func New(cfg Config) (*Runner, error) {
 		return nil, fmt.Errorf("session service is required")
 	}
 
+	if err := validatePlugins(cfg.Plugins); err != nil {
+		return nil, err
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
 	parents, err := parentmap.New(cfg.Agent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent tree: %w", err)
 	}
 
 	return &Runner{
-		appName:         cfg.AppName,
-		rootAgent:       cfg.Agent,
-		sessionService:  cfg.SessionService,
-		artifactService: cfg.ArtifactService,
-		memoryService:   cfg.MemoryService,
-		parents:         parents,
+		appName:           cfg.AppName,
+		rootAgent:         cfg.Agent,
+		sessionService:    cfg.SessionService,
+		artifactService:   cfg.ArtifactService,
+		memoryService:     cfg.MemoryService,
+		autoCreateSession: cfg.AutoCreateSession,
+		plugins:           cfg.Plugins,
+		usageCallback:     cfg.UsageCallback,
+		logger:            logger,
+		parents:           parents,
 	}, nil
 }
 
@@ -85,7 +127,16 @@ type Runner struct {
 	artifactService artifact.Service
 	memoryService   memory.Service
 
-	parents parentmap.Map
+	autoCreateSession bool
+	plugins           []plugin.Plugin
+	usageCallback     func(context.Context, *model.Usage)
+	logger            *slog.Logger
+	parents           parentmap.Map
+
+	mu          sync.Mutex
+	closed      bool
+	active      sync.WaitGroup
+	invocations map[string]struct{} // ids of the active invocations
 }
 
 // Run runs the agent for the given user input, yielding events from agents.
@@ -94,30 +145,79 @@ type Runner struct {
 func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.Content, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
 	// TODO(hakim): we need to validate whether cfg is compatible with the Agent.
 	//   see adk-python/src/google/adk/runners.py Runner._new_invocation_context.
-	// TODO: setup tracer.
 	return func(yield func(*session.Event, error) bool) {
-		resp, err := r.sessionService.Get(ctx, &session.GetRequest{
-			AppName:   r.appName,
-			UserID:    userID,
-			SessionID: sessionID,
-		})
+		if _, ok := cfg.Values[nil]; ok {
+			yield(nil, errors.New("RunConfig.Values has a nil key"))
+			return
+		}
+		finishRun, err := r.startRun()
 		if err != nil {
 			yield(nil, err)
 			return
 		}
+		defer finishRun()
+
+		start := time.Now()
+		agentName := r.rootAgent.Name()
+		failed := false
+		defer func() {
+			telemetry.RecordAgentRun(ctx, r.appName, agentName, time.Since(start), failed)
+		}()
+		var spans []trace.Span
+		ctx, spans = telemetry.StartTrace(ctx, "invocation")
+		var spanErr error
+		defer func() { telemetry.EndTrace(spans, spanErr) }()
+		yieldEvent := yield
+		yield = func(event *session.Event, err error) bool {
+			if err != nil {
+				failed = true
+				spanErr = err
+			}
+			return yieldEvent(event, err)
+		}
 
-		session := resp.Session
+		session, err := r.getSession(ctx, userID, sessionID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
 
 		agentToRun, err := r.findAgentToRun(session)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
+		agentName = agentToRun.Name()
 
+		if cfg.MaxDuration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.MaxDuration)
+			defer cancel()
+		}
+		if len(cfg.Values) > 0 {
+			ctx = valuesContext{Context: ctx, values: maps.Clone(cfg.Values)}
+		}
 		ctx = parentmap.ToContext(ctx, r.parents)
-		ctx = runconfig.ToContext(ctx, &runconfig.RunConfig{
-			StreamingMode: runconfig.StreamingMode(cfg.StreamingMode),
-		})
+		internalCfg := &runconfig.RunConfig{
+			StreamingMode:        runconfig.StreamingMode(cfg.StreamingMode),
+			LLMCalls:             runconfig.NewLLMCallsLimiter(cfg.MaxLLMCalls),
+			Usage:                &model.Usage{},
+			MaxTotalTokens:       cfg.MaxTotalTokens,
+			Logger:               r.logger,
+			MaxParallelToolCalls: cfg.MaxParallelToolCalls,
+		}
+		// Nested runs, e.g. from agenttool, share the limits and usage of the parent invocation.
+		if parentCfg := runconfig.FromContext(ctx); parentCfg != nil && parentCfg.LLMCalls != nil {
+			internalCfg.LLMCalls = parentCfg.LLMCalls
+			internalCfg.Usage = parentCfg.Usage
+			internalCfg.MaxTotalTokens = parentCfg.MaxTotalTokens
+			internalCfg.MaxParallelToolCalls = parentCfg.MaxParallelToolCalls
+		} else if r.usageCallback != nil {
+			defer func() { r.usageCallback(ctx, internalCfg.Usage) }()
+		}
+		r.setAgentCallbacks(internalCfg)
+		ctx = runconfig.ToContext(ctx, internalCfg)
+		ctx = plugininternal.ToContext(ctx, r.plugins)
 
 		var artifacts agent.Artifacts
 		if r.artifactService != nil {
@@ -139,30 +239,83 @@ func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.C
 			}
 		}
 
-		ctx := icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
+		params := icontext.InvocationContextParams{
 			Artifacts:   artifacts,
 			Memory:      memoryImpl,
 			Session:     sessioninternal.NewMutableSession(r.sessionService, session),
 			Agent:       agentToRun,
 			UserContent: msg,
 			RunConfig:   &cfg,
-		})
+		}
+		ctx := icontext.NewInvocationContext(ctx, params)
+		telemetry.TraceInvocation(spans, r.appName, userID, sessionID, ctx.InvocationID())
+		defer r.trackInvocation(ctx.InvocationID())()
+
+		if msg != nil {
+			newMsg, err := r.onUserMessage(ctx, msg)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if newMsg != msg {
+				msg = newMsg
+				params.UserContent = msg
+				params.InvocationID = ctx.InvocationID()
+				ctx = icontext.NewInvocationContext(ctx, params)
+			}
+		}
+		defer r.afterRun(ctx)
 
 		if err := r.appendMessageToSession(ctx, session, msg, cfg.SaveInputBlobsAsArtifacts); err != nil {
 			yield(nil, err)
 			return
 		}
 
+		content, err := r.beforeRun(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if content != nil {
+			event := earlyExitEvent(ctx, content)
+			if err := r.sessionService.AppendEvent(ctx, session, event); err != nil {
+				yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+				return
+			}
+			yield(event, nil)
+			return
+		}
+
 		for event, err := range agentToRun.Run(ctx) {
 			if err != nil {
 				if !yield(event, err) {
 					return
 				}
+				if cfg.MaxDuration > 0 && ctx.Err() != nil {
+					return
+				}
 				continue
 			}
 
+			// Agents not observing the context are stopped at the next event.
+			if cfg.MaxDuration > 0 && ctx.Err() != nil {
+				yield(nil, fmt.Errorf("run exceeded MaxDuration of %v: %w", cfg.MaxDuration, ctx.Err()))
+				return
+			}
+
+			event, err = r.onEvent(ctx, event)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
 			// only commit non-partial event to a session service
 			if !event.LLMResponse.Partial {
+				r.logger.LogAttrs(ctx, slog.LevelDebug, "Event",
+					slog.String("invocation_id", ctx.InvocationID()),
+					slog.String("agent", event.Author),
+					slog.String("event_id", event.ID),
+				)
 				if err := r.sessionService.AppendEvent(ctx, session, event); err != nil {
 					yield(nil, fmt.Errorf("failed to add event to session: %w", err))
 					return
@@ -176,6 +329,84 @@ func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.C
 	}
 }
 
+// valuesContext adds the RunConfig.Values to a context with a single layer,
+// rather than one context.WithValue per value.
+type valuesContext struct {
+	context.Context
+	values map[any]any
+}
+
+func (c valuesContext) Value(key any) any {
+	if v, ok := c.values[key]; ok {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// getSession returns the session, creating it if it doesn't exist and
+// AutoCreateSession is set.
+func (r *Runner) getSession(ctx context.Context, userID, sessionID string) (session.Session, error) {
+	resp, err := r.sessionService.Get(ctx, &session.GetRequest{
+		AppName:   r.appName,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err == nil {
+		return resp.Session, nil
+	}
+	if !r.autoCreateSession || !errors.Is(err, session.ErrSessionNotFound) {
+		return nil, err
+	}
+	created, err := r.sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   r.appName,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return created.Session, nil
+}
+
+// RunSync runs the agent for the given user input and waits for it to finish.
+// It returns the text of the final response, all non-partial events yielded by
+// the agents and the first error that occurred. Partial events produced in the
+// streaming mode are dropped, since their content is repeated by the
+// aggregated event that follows them.
+func (r *Runner) RunSync(ctx context.Context, userID, sessionID string, msg *genai.Content, cfg agent.RunConfig) (string, []*session.Event, error) {
+	var events []*session.Event
+	var finalText string
+	for event, err := range r.Run(ctx, userID, sessionID, msg, cfg) {
+		if err != nil {
+			return finalText, events, err
+		}
+		if event == nil || event.LLMResponse.Partial {
+			continue
+		}
+		events = append(events, event)
+		if event.IsFinalResponse() {
+			if text := contentText(event.LLMResponse.Content); text != "" {
+				finalText = text
+			}
+		}
+	}
+	return finalText, events, nil
+}
+
+// contentText joins the text of all non-thought parts.
+func contentText(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range content.Parts {
+		if part != nil && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
+
 func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSession session.Session, msg *genai.Content, saveInputBlobsAsArtifacts bool) error {
 	if msg == nil {
 		return nil
@@ -220,14 +451,19 @@ func (r *Runner) findAgentToRun(session session.Session) (agent.Agent, error) {
 
 		// TODO: findMatchingFunctionCall.
 
-		if event.Author == "user" {
+		// The events of the agents run by tools aren't from the agent tree.
+		if event.Author == "user" || event.ParentFunctionCallID != "" {
 			continue
 		}
 
 		subAgent := findAgent(r.rootAgent, event.Author)
 		// Agent not found, continue looking for the other event.
 		if subAgent == nil {
-			log.Printf("Event from an unknown agent: %s, event id: %s", event.Author, event.ID)
+			r.logger.Warn("Event from an unknown agent",
+				slog.String("session_id", session.ID()),
+				slog.String("agent", event.Author),
+				slog.String("event_id", event.ID),
+			)
 			continue
 		}
 
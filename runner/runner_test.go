@@ -17,17 +17,33 @@ package runner
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
 )
 
 func TestRunner_findAgentToRun(t *testing.T) {
@@ -341,6 +357,104 @@ type agentTreeStruct struct {
 	root, noTransferAgent, allowsTransferAgent agent.Agent
 }
 
+func TestRunner_RunSync(t *testing.T) {
+	ctx := t.Context()
+	textEvent := func(ctx agent.InvocationContext, text string, partial bool) *session.Event {
+		ev := session.NewEvent(ctx.InvocationID())
+		ev.Author = ctx.Agent().Name()
+		ev.LLMResponse = model.LLMResponse{
+			Content: genai.NewContentFromText(text, genai.RoleModel),
+			Partial: partial,
+		}
+		return ev
+	}
+
+	tests := []struct {
+		name       string
+		run        func(ctx agent.InvocationContext, yield func(*session.Event, error) bool)
+		wantText   string
+		wantEvents []string
+		wantErr    bool
+	}{
+		{
+			name: "streamed response is coalesced",
+			run: func(ctx agent.InvocationContext, yield func(*session.Event, error) bool) {
+				_ = yield(textEvent(ctx, "Hello, ", true), nil) &&
+					yield(textEvent(ctx, "world!", true), nil) &&
+					yield(textEvent(ctx, "Hello, world!", false), nil)
+			},
+			wantText:   "Hello, world!",
+			wantEvents: []string{"Hello, world!"},
+		},
+		{
+			name: "last final response wins",
+			run: func(ctx agent.InvocationContext, yield func(*session.Event, error) bool) {
+				_ = yield(textEvent(ctx, "first", false), nil) &&
+					yield(textEvent(ctx, "second", false), nil)
+			},
+			wantText:   "second",
+			wantEvents: []string{"first", "second"},
+		},
+		{
+			name: "first error is returned",
+			run: func(ctx agent.InvocationContext, yield func(*session.Event, error) bool) {
+				_ = yield(textEvent(ctx, "partial answer", false), nil) &&
+					yield(nil, fmt.Errorf("model failed")) &&
+					yield(textEvent(ctx, "not reached", false), nil)
+			},
+			wantText:   "partial answer",
+			wantEvents: []string{"partial answer"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testAgent := must(agent.New(agent.Config{
+				Name: "test_agent",
+				Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+					return func(yield func(*session.Event, error) bool) {
+						tc.run(ctx, yield)
+					}
+				},
+			}))
+			sessionService := session.InMemoryService()
+			r, err := New(Config{
+				AppName:        "testApp",
+				Agent:          testAgent,
+				SessionService: sessionService,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, err := sessionService.Create(ctx, &session.CreateRequest{
+				AppName:   "testApp",
+				UserID:    "testUser",
+				SessionID: "testSession",
+			}); err != nil {
+				t.Fatalf("sessionService.Create() error = %v", err)
+			}
+
+			text, events, err := r.RunSync(ctx, "testUser", "testSession", genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{
+				StreamingMode: agent.StreamingModeSSE,
+			})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("RunSync() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if text != tc.wantText {
+				t.Errorf("RunSync() text = %q, want %q", text, tc.wantText)
+			}
+			var gotEvents []string
+			for _, ev := range events {
+				gotEvents = append(gotEvents, ev.LLMResponse.Content.Parts[0].Text)
+			}
+			if diff := cmp.Diff(tc.wantEvents, gotEvents); diff != "" {
+				t.Errorf("RunSync() events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func must[T agent.Agent](a T, err error) T {
 	if err != nil {
 		panic(err)
@@ -370,3 +484,688 @@ func createSession(t *testing.T, ctx context.Context, sessionID, appName, userID
 
 	return resp.Session
 }
+
+func TestRunner_MaxDuration(t *testing.T) {
+	ctx := t.Context()
+	// The agent ignores the context, so the runner stops it at the next event.
+	testAgent := must(agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				time.Sleep(100 * time.Millisecond)
+				ev := session.NewEvent(ctx.InvocationID())
+				ev.Author = ctx.Agent().Name()
+				ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("late answer", genai.RoleModel)}
+				yield(ev, nil)
+			}
+		},
+	}))
+	sessionService := session.InMemoryService()
+	r, err := New(Config{
+		AppName:        "testApp",
+		Agent:          testAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   "testApp",
+		UserID:    "testUser",
+		SessionID: "testSession",
+	}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+
+	_, events, err := r.RunSync(ctx, "testUser", "testSession", genai.NewContentFromText("question", genai.RoleUser), agent.RunConfig{MaxDuration: 10 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunSync() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(events) != 0 {
+		t.Errorf("RunSync() returned %d events, want none after the deadline", len(events))
+	}
+}
+
+func TestRunner_Values(t *testing.T) {
+	type tenantKey struct{}
+	type requestKey struct{}
+	ctx := context.WithValue(t.Context(), requestKey{}, "request-1")
+	var gotTenant, gotRequest any
+	testAgent := must(agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				gotTenant, gotRequest = ctx.Value(tenantKey{}), ctx.Value(requestKey{})
+			}
+		},
+	}))
+	r, err := New(Config{
+		AppName:           "testApp",
+		Agent:             testAgent,
+		SessionService:    session.InMemoryService(),
+		AutoCreateSession: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cfg := agent.RunConfig{Values: map[any]any{tenantKey{}: "tenant-1"}}
+	if _, _, err := r.RunSync(ctx, "testUser", "testSession", genai.NewContentFromText("question", genai.RoleUser), cfg); err != nil {
+		t.Fatalf("RunSync() error = %v", err)
+	}
+	// The values of the context passed to the runner are kept.
+	if gotTenant != "tenant-1" || gotRequest != "request-1" {
+		t.Errorf("agent read tenant %v and request %v, want %q and %q", gotTenant, gotRequest, "tenant-1", "request-1")
+	}
+
+	cfg = agent.RunConfig{Values: map[any]any{nil: "value"}}
+	if _, _, err := r.RunSync(ctx, "testUser", "testSession", genai.NewContentFromText("question", genai.RoleUser), cfg); err == nil || !strings.Contains(err.Error(), "nil key") {
+		t.Errorf("RunSync() with a nil key error = %v, want a nil key error", err)
+	}
+}
+
+func TestRunner_AutoCreateSession(t *testing.T) {
+	ctx := t.Context()
+	testAgent := must(agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				ev := session.NewEvent(ctx.InvocationID())
+				ev.Author = ctx.Agent().Name()
+				ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("answer", genai.RoleModel)}
+				yield(ev, nil)
+			}
+		},
+	}))
+
+	for _, autoCreate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("AutoCreateSession=%v", autoCreate), func(t *testing.T) {
+			sessionService := session.InMemoryService()
+			r, err := New(Config{
+				AppName:           "testApp",
+				Agent:             testAgent,
+				SessionService:    sessionService,
+				AutoCreateSession: autoCreate,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			for range 2 {
+				text, _, err := r.RunSync(ctx, "testUser", "newSession", genai.NewContentFromText("question", genai.RoleUser), agent.RunConfig{})
+				if !autoCreate {
+					if !errors.Is(err, session.ErrSessionNotFound) {
+						t.Fatalf("RunSync() error = %v, want %v", err, session.ErrSessionNotFound)
+					}
+					return
+				}
+				if err != nil || text != "answer" {
+					t.Fatalf("RunSync() = %q, %v, want %q", text, err, "answer")
+				}
+			}
+
+			resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "testApp", UserID: "testUser", SessionID: "newSession"})
+			if err != nil {
+				t.Fatalf("session was not created: %v", err)
+			}
+			// The second run continues the created session.
+			if got := resp.Session.Events().Len(); got != 4 {
+				t.Errorf("session has %d events, want 4", got)
+			}
+		})
+	}
+}
+
+func TestRunner_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prevProvider) })
+
+	var toolSpan trace.SpanContext
+	weatherTool, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(ctx tool.Context, args struct{ City string }) (string, error) {
+			toolSpan = trace.SpanContextFromContext(ctx)
+			return "sunny", nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &scriptedModel{responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("get_weather", map[string]any{"City": "Paris"}, genai.RoleModel),
+		genai.NewContentFromText("It is sunny.", genai.RoleModel),
+	}}
+	testAgent := must(llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Tools: []tool.Tool{weatherTool}}))
+	r, err := New(Config{AppName: "testApp", Agent: testAgent, SessionService: session.InMemoryService(), AutoCreateSession: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, _, err := r.RunSync(t.Context(), "testUser", "testSession", genai.NewContentFromText("weather in Paris?", genai.RoleUser), agent.RunConfig{}); err != nil {
+		t.Fatalf("RunSync() error = %v", err)
+	}
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	if len(spans["invocation"]) != 1 || len(spans["agent_run [weather_agent]"]) != 1 || len(spans["call_llm"]) != 2 || len(spans["execute_tool get_weather"]) != 1 {
+		t.Fatalf("unexpected ended spans: %v", slices.Collect(maps.Keys(spans)))
+	}
+	invocation := spans["invocation"][0]
+	agentRun := spans["agent_run [weather_agent]"][0]
+	executeTool := spans["execute_tool get_weather"][0]
+
+	if invocation.Parent().IsValid() {
+		t.Errorf("invocation span has parent %v, want a root span", invocation.Parent())
+	}
+	wantParent := func(child, parent sdktrace.ReadOnlySpan) {
+		t.Helper()
+		if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %q has parent %v, want %q", child.Name(), child.Parent().SpanID(), parent.Name())
+		}
+	}
+	wantParent(agentRun, invocation)
+	for _, callLLM := range spans["call_llm"] {
+		wantParent(callLLM, agentRun)
+	}
+	wantParent(executeTool, spans["call_llm"][0])
+	if toolSpan.SpanID() != executeTool.SpanContext().SpanID() {
+		t.Errorf("tool context carries span %v, want the execute_tool span", toolSpan.SpanID())
+	}
+
+	wantAttrs := map[sdktrace.ReadOnlySpan][]attribute.KeyValue{
+		invocation: {
+			attribute.String("adk.user.id", "testUser"),
+			attribute.String("gcp.vertex.agent.session_id", "testSession"),
+		},
+		spans["call_llm"][1]: {
+			attribute.String("gen_ai.request.model", "scripted-model"),
+			attribute.String("gen_ai.agent.name", "weather_agent"),
+			attribute.Int("gen_ai.usage.input_tokens", 10),
+			attribute.Int("gen_ai.usage.output_tokens", 5),
+		},
+		executeTool: {
+			attribute.String("gen_ai.tool.name", "get_weather"),
+			attribute.String("gen_ai.agent.name", "weather_agent"),
+			attribute.String("gcp.vertex.agent.tool_call_args", `{"City":"Paris"}`),
+		},
+	}
+	for span, want := range wantAttrs {
+		for _, attr := range want {
+			if !slices.Contains(span.Attributes(), attr) {
+				t.Errorf("span %q attributes = %v, want %v", span.Name(), span.Attributes(), attr)
+			}
+		}
+	}
+}
+
+func TestRunner_UsageCallback(t *testing.T) {
+	weatherTool, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(ctx tool.Context, args struct{ City string }) (string, error) {
+			return "sunny", nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &scriptedModel{responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("get_weather", map[string]any{"City": "Paris"}, genai.RoleModel),
+		genai.NewContentFromText("It is sunny.", genai.RoleModel),
+	}}
+	testAgent := must(llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Tools: []tool.Tool{weatherTool}}))
+	var reports []model.ModelUsage
+	r, err := New(Config{
+		AppName:           "testApp",
+		Agent:             testAgent,
+		SessionService:    session.InMemoryService(),
+		AutoCreateSession: true,
+		UsageCallback: func(ctx context.Context, usage *model.Usage) {
+			reports = append(reports, usage.Total())
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, _, err := r.RunSync(t.Context(), "testUser", "testSession", genai.NewContentFromText("weather in Paris?", genai.RoleUser), agent.RunConfig{}); err != nil {
+		t.Fatalf("RunSync() error = %v", err)
+	}
+	// The usage of both LLM calls is reported once, at the end of the run.
+	want := []model.ModelUsage{{PromptTokens: 20, CandidateTokens: 10, TotalTokens: 30}}
+	if diff := cmp.Diff(want, reports); diff != "" {
+		t.Errorf("UsageCallback reports mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// scriptedModel returns the responses in order, one per request.
+type scriptedModel struct {
+	responses []*genai.Content
+}
+
+func (m *scriptedModel) Name() string {
+	return "scripted-model"
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if len(m.responses) == 0 {
+			yield(nil, errors.New("no more responses"))
+			return
+		}
+		content := m.responses[0]
+		m.responses = m.responses[1:]
+		yield(&model.LLMResponse{
+			Content:       content,
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5},
+		}, nil)
+	}
+}
+
+func TestRunner_Plugins(t *testing.T) {
+	var calls []string
+	record := func(call string) { calls = append(calls, call) }
+
+	weatherTool, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(ctx tool.Context, args struct{ City string }) (string, error) {
+			record("tool")
+			return "sunny", nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAgent := func(t *testing.T, llm model.LLM) agent.Agent {
+		t.Helper()
+		a, err := llmagent.New(llmagent.Config{
+			Name:  "weather_agent",
+			Model: llm,
+			Tools: []tool.Tool{weatherTool},
+			BeforeAgentCallbacks: []agent.BeforeAgentCallback{func(agent.CallbackContext) (*genai.Content, error) {
+				record("agent.BeforeAgent")
+				return nil, nil
+			}},
+			AfterAgentCallbacks: []agent.AfterAgentCallback{func(agent.CallbackContext) (*genai.Content, error) {
+				record("agent.AfterAgent")
+				return nil, nil
+			}},
+			BeforeModelCallbacks: []llmagent.BeforeModelCallback{func(agent.CallbackContext, *model.LLMRequest) (*model.LLMResponse, error) {
+				record("agent.BeforeModel")
+				return nil, nil
+			}},
+			AfterModelCallbacks: []llmagent.AfterModelCallback{func(agent.CallbackContext, *model.LLMResponse, error) (*model.LLMResponse, error) {
+				record("agent.AfterModel")
+				return nil, nil
+			}},
+			BeforeToolCallbacks: []llmagent.BeforeToolCallback{func(tool.Context, tool.Tool, map[string]any) (map[string]any, error) {
+				record("agent.BeforeTool")
+				return nil, nil
+			}},
+			AfterToolCallbacks: []llmagent.AfterToolCallback{func(tool.Context, tool.Tool, map[string]any, map[string]any, error) (map[string]any, error) {
+				record("agent.AfterTool")
+				return nil, nil
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	// recordingPlugin records all its hooks, which return nil.
+	recordingPlugin := func(name string) plugin.Plugin {
+		return plugin.Plugin{
+			Name: name,
+			OnUserMessage: func(agent.InvocationContext, *genai.Content) (*genai.Content, error) {
+				record(name + ".OnUserMessage")
+				return nil, nil
+			},
+			BeforeRun: func(agent.InvocationContext) (*genai.Content, error) {
+				record(name + ".BeforeRun")
+				return nil, nil
+			},
+			OnEvent: func(agent.InvocationContext, *session.Event) (*session.Event, error) {
+				record(name + ".OnEvent")
+				return nil, nil
+			},
+			AfterRun: func(agent.InvocationContext) {
+				record(name + ".AfterRun")
+			},
+			BeforeAgent: func(agent.CallbackContext) (*genai.Content, error) {
+				record(name + ".BeforeAgent")
+				return nil, nil
+			},
+			AfterAgent: func(agent.CallbackContext) (*genai.Content, error) {
+				record(name + ".AfterAgent")
+				return nil, nil
+			},
+			BeforeModel: func(agent.CallbackContext, *model.LLMRequest) (*model.LLMResponse, error) {
+				record(name + ".BeforeModel")
+				return nil, nil
+			},
+			AfterModel: func(agent.CallbackContext, *model.LLMResponse, error) (*model.LLMResponse, error) {
+				record(name + ".AfterModel")
+				return nil, nil
+			},
+			BeforeTool: func(tool.Context, tool.Tool, map[string]any) (map[string]any, error) {
+				record(name + ".BeforeTool")
+				return nil, nil
+			},
+			AfterTool: func(tool.Context, tool.Tool, map[string]any, map[string]any, error) (map[string]any, error) {
+				record(name + ".AfterTool")
+				return nil, nil
+			},
+		}
+	}
+	run := func(t *testing.T, llm model.LLM, plugins ...plugin.Plugin) (string, []*session.Event) {
+		t.Helper()
+		calls = nil
+		r, err := New(Config{AppName: "testApp", Agent: newAgent(t, llm), SessionService: session.InMemoryService(), AutoCreateSession: true, Plugins: plugins})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		text, events, err := r.RunSync(t.Context(), "testUser", "testSession", genai.NewContentFromText("weather in Paris?", genai.RoleUser), agent.RunConfig{})
+		if err != nil {
+			t.Fatalf("RunSync() error = %v", err)
+		}
+		return text, events
+	}
+	weatherModel := func() *scriptedModel {
+		return &scriptedModel{responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("get_weather", map[string]any{"City": "Paris"}, genai.RoleModel),
+			genai.NewContentFromText("It is sunny.", genai.RoleModel),
+		}}
+	}
+
+	t.Run("plugins_before_agent_callbacks", func(t *testing.T) {
+		text, _ := run(t, weatherModel(), recordingPlugin("p1"), recordingPlugin("p2"))
+		if text != "It is sunny." {
+			t.Errorf("RunSync() = %q, want %q", text, "It is sunny.")
+		}
+		want := []string{
+			"p1.OnUserMessage", "p2.OnUserMessage",
+			"p1.BeforeRun", "p2.BeforeRun",
+			"p1.BeforeAgent", "p2.BeforeAgent", "agent.BeforeAgent",
+			"p1.BeforeModel", "p2.BeforeModel", "agent.BeforeModel",
+			"p1.AfterModel", "p2.AfterModel", "agent.AfterModel",
+			"p1.OnEvent", "p2.OnEvent",
+			"p1.BeforeTool", "p2.BeforeTool", "agent.BeforeTool",
+			"tool",
+			"p1.AfterTool", "p2.AfterTool", "agent.AfterTool",
+			"p1.OnEvent", "p2.OnEvent",
+			"p1.BeforeModel", "p2.BeforeModel", "agent.BeforeModel",
+			"p1.AfterModel", "p2.AfterModel", "agent.AfterModel",
+			"p1.OnEvent", "p2.OnEvent",
+			"p1.AfterAgent", "p2.AfterAgent", "agent.AfterAgent",
+			"p1.AfterRun", "p2.AfterRun",
+		}
+		if diff := cmp.Diff(want, calls); diff != "" {
+			t.Errorf("calls mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("before_model_short_circuits", func(t *testing.T) {
+		llm := weatherModel()
+		p := recordingPlugin("p1")
+		p.BeforeModel = func(agent.CallbackContext, *model.LLMRequest) (*model.LLMResponse, error) {
+			record("p1.BeforeModel")
+			return &model.LLMResponse{Content: genai.NewContentFromText("cached answer", genai.RoleModel)}, nil
+		}
+		text, _ := run(t, llm, p, recordingPlugin("p2"))
+		if text != "cached answer" {
+			t.Errorf("RunSync() = %q, want %q", text, "cached answer")
+		}
+		if len(llm.responses) != 2 {
+			t.Errorf("the model was called")
+		}
+		if slices.Contains(calls, "p2.BeforeModel") || slices.Contains(calls, "agent.BeforeModel") {
+			t.Errorf("calls = %v, want the remaining BeforeModel hooks and callbacks skipped", calls)
+		}
+	})
+
+	t.Run("before_tool_short_circuits", func(t *testing.T) {
+		p := recordingPlugin("p1")
+		p.BeforeTool = func(tool.Context, tool.Tool, map[string]any) (map[string]any, error) {
+			record("p1.BeforeTool")
+			return map[string]any{"result": "rainy"}, nil
+		}
+		_, events := run(t, weatherModel(), p)
+		if slices.Contains(calls, "tool") || slices.Contains(calls, "agent.BeforeTool") {
+			t.Errorf("calls = %v, want the tool and its callbacks skipped", calls)
+		}
+		var got any
+		for _, ev := range events {
+			for _, part := range ev.LLMResponse.Content.Parts {
+				if part.FunctionResponse != nil {
+					got = part.FunctionResponse.Response["result"]
+				}
+			}
+		}
+		if got != "rainy" {
+			t.Errorf("tool result = %v, want %q", got, "rainy")
+		}
+	})
+
+	t.Run("before_agent_short_circuits", func(t *testing.T) {
+		llm := weatherModel()
+		p := recordingPlugin("p1")
+		p.BeforeAgent = func(agent.CallbackContext) (*genai.Content, error) {
+			record("p1.BeforeAgent")
+			return genai.NewContentFromText("agent skipped", genai.RoleModel), nil
+		}
+		text, _ := run(t, llm, p)
+		if text != "agent skipped" {
+			t.Errorf("RunSync() = %q, want %q", text, "agent skipped")
+		}
+		if len(llm.responses) != 2 || slices.Contains(calls, "agent.BeforeAgent") {
+			t.Errorf("calls = %v, want the agent skipped", calls)
+		}
+	})
+
+	t.Run("before_run_short_circuits", func(t *testing.T) {
+		p := recordingPlugin("p1")
+		p.BeforeRun = func(agent.InvocationContext) (*genai.Content, error) {
+			record("p1.BeforeRun")
+			return genai.NewContentFromText("quota exceeded", genai.RoleModel), nil
+		}
+		text, events := run(t, weatherModel(), p)
+		if text != "quota exceeded" || len(events) != 1 || events[0].Author != "weather_agent" {
+			t.Errorf("RunSync() = %q, %v, want a single event with %q", text, events, "quota exceeded")
+		}
+		want := []string{"p1.OnUserMessage", "p1.BeforeRun", "p1.AfterRun"}
+		if diff := cmp.Diff(want, calls); diff != "" {
+			t.Errorf("calls mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("replaces_user_message_and_events", func(t *testing.T) {
+		var requests []*model.LLMRequest
+		p := plugin.Plugin{
+			Name: "redact",
+			OnUserMessage: func(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error) {
+				return genai.NewContentFromText(strings.ReplaceAll(msg.Parts[0].Text, "Paris", "<city>"), genai.RoleUser), nil
+			},
+			BeforeModel: func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+				requests = append(requests, req)
+				return nil, nil
+			},
+			OnEvent: func(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+				if event.LLMResponse.Content == nil || event.LLMResponse.Content.Parts[0].Text == "" {
+					return nil, nil
+				}
+				replaced := *event
+				replaced.LLMResponse.Content = genai.NewContentFromText("redacted", genai.RoleModel)
+				return &replaced, nil
+			},
+		}
+		text, _ := run(t, weatherModel(), p)
+		if text != "redacted" {
+			t.Errorf("RunSync() = %q, want %q", text, "redacted")
+		}
+		if got := requests[0].Contents[0].Parts[0].Text; got != "weather in <city>?" {
+			t.Errorf("model got user message %q, want %q", got, "weather in <city>?")
+		}
+	})
+}
+
+func TestNew_InvalidPlugins(t *testing.T) {
+	testAgent := must(agent.New(agent.Config{Name: "test_agent"}))
+	for _, plugins := range [][]plugin.Plugin{
+		{{}},
+		{{Name: "p"}, {Name: "p"}},
+	} {
+		if _, err := New(Config{Agent: testAgent, SessionService: session.InMemoryService(), Plugins: plugins}); err == nil {
+			t.Errorf("New() with plugins %v error = nil, want error", plugins)
+		}
+	}
+}
+
+func TestRunner_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	failingTool, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(ctx tool.Context, args struct{ City string }) (string, error) {
+			return "", errors.New("weather service unavailable")
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &scriptedModel{responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("get_weather", map[string]any{"City": "Paris"}, genai.RoleModel),
+		genai.NewContentFromText("I don't know.", genai.RoleModel),
+	}}
+	testAgent := must(llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Tools: []tool.Tool{failingTool}}))
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownEvent := session.NewEvent("old_invocation")
+	unknownEvent.Author = "removed_agent"
+	if err := sessionService.AppendEvent(t.Context(), created.Session, unknownEvent); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(Config{AppName: "testApp", Agent: testAgent, SessionService: sessionService, Logger: logger})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, events, err := r.RunSync(t.Context(), "testUser", "testSession", genai.NewContentFromText("weather in Paris?", genai.RoleUser), agent.RunConfig{})
+	if err != nil {
+		t.Fatalf("RunSync() error = %v", err)
+	}
+
+	var records []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		delete(record, "time")
+		records = append(records, record)
+	}
+	want := []map[string]any{
+		{"level": "WARN", "msg": "Event from an unknown agent", "session_id": "testSession", "agent": "removed_agent", "event_id": unknownEvent.ID},
+		{"level": "DEBUG", "msg": "Event", "invocation_id": events[0].InvocationID, "agent": "weather_agent", "event_id": events[0].ID},
+		{"level": "WARN", "msg": "Tool call failed", "invocation_id": events[0].InvocationID, "agent": "weather_agent", "event_id": events[1].ID, "tool": "get_weather", "error": `tool "get_weather" failed: weather service unavailable`},
+		{"level": "DEBUG", "msg": "Event", "invocation_id": events[0].InvocationID, "agent": "weather_agent", "event_id": events[1].ID},
+		{"level": "DEBUG", "msg": "Event", "invocation_id": events[0].InvocationID, "agent": "weather_agent", "event_id": events[2].ID},
+	}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Errorf("log records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunner_Close(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	testAgent := must(agent.New(agent.Config{
+		Name: "slow_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				close(started)
+				<-release
+				ev := session.NewEvent(ctx.InvocationID())
+				ev.Author = ctx.Agent().Name()
+				ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("final answer", genai.RoleModel)}
+				yield(ev, nil)
+			}
+		},
+	}))
+	sessionService := session.InMemoryService()
+	r, err := New(Config{AppName: "testApp", Agent: testAgent, SessionService: sessionService, AutoCreateSession: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	runErr := make(chan error, 1)
+	go func() {
+		_, _, err := r.RunSync(t.Context(), "testUser", "testSession", genai.NewContentFromText("question", genai.RoleUser), agent.RunConfig{})
+		runErr <- err
+	}()
+	<-started
+
+	closeErr := make(chan error, 1)
+	go func() { closeErr <- r.Close(t.Context()) }()
+
+	// The active invocation keeps Close waiting.
+	select {
+	case err := <-closeErr:
+		t.Fatalf("Close() = %v before the active invocation finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// New runs are rejected while closing.
+	if _, _, err := r.RunSync(t.Context(), "testUser", "otherSession", genai.NewContentFromText("question", genai.RoleUser), agent.RunConfig{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("RunSync() after Close() error = %v, want %v", err, ErrClosed)
+	}
+
+	close(release)
+	if err := <-closeErr; err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The final event is persisted by the time Close returns.
+	resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"})
+	if err != nil {
+		t.Fatalf("sessionService.Get() error = %v", err)
+	}
+	events := resp.Session.Events()
+	if got := events.Len(); got != 2 {
+		t.Fatalf("session has %d events, want 2", got)
+	}
+	if got := events.At(1).Content.Parts[0].Text; got != "final answer" {
+		t.Errorf("last session event text = %q, want %q", got, "final answer")
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("RunSync() error = %v", err)
+	}
+}
+
+func TestRunner_CloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	testAgent := must(agent.New(agent.Config{
+		Name: "stuck_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				close(started)
+				<-release
+			}
+		},
+	}))
+	r, err := New(Config{AppName: "testApp", Agent: testAgent, SessionService: session.InMemoryService(), AutoCreateSession: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	go func() {
+		for range r.Run(t.Context(), "testUser", "testSession", nil, agent.RunConfig{}) {
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adk_test
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/session"
+)
+
+func TestRunner_RunText(t *testing.T) {
+	ctx := t.Context()
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("first answer", genai.RoleModel),
+			genai.NewContentFromText("second answer", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "agent",
+		Model:       mockModel,
+		Instruction: "Reply in {language}.",
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	sessionService := session.InMemoryService()
+	r, err := adk.NewRunner(adk.Config{
+		AppName:        "app",
+		Agent:          a,
+		SessionService: sessionService,
+		InitialState:   map[string]any{"language": "English"},
+	})
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	for _, msg := range []string{"first question", "second question"} {
+		events, err := testutil.CollectEvents(r.RunText(ctx, "user", "session", msg))
+		if err != nil {
+			t.Fatalf("RunText(%q) error = %v", msg, err)
+		}
+		if len(events) != 1 {
+			t.Errorf("RunText(%q) yielded %d events, want 1", msg, len(events))
+		}
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("session was not created: %v", err)
+	}
+	// Both messages and answers are in the same session.
+	if got := resp.Session.Events().Len(); got != 4 {
+		t.Errorf("session has %d events, want 4", got)
+	}
+	if got, err := resp.Session.State().Get("language"); err != nil || got != "English" {
+		t.Errorf("session state language = %v, %v, want the initial state", got, err)
+	}
+	if si := mockModel.Requests[0].Config.SystemInstruction; si == nil || si.Parts[0].Text != "Reply in English." {
+		t.Errorf("system instruction = %v, want the instruction with the initial state", si)
+	}
+}
+
+func TestNewRunner_Error(t *testing.T) {
+	if _, err := adk.NewRunner(adk.Config{AppName: "app"}); err == nil {
+		t.Error("NewRunner() error = nil, want error for missing agent")
+	}
+}
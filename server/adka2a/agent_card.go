@@ -28,6 +28,15 @@ import (
 	"google.golang.org/adk/internal/llminternal"
 )
 
+var (
+	// DefaultInputModes are the media types an agent served by [Executor] accepts. A2A file parts
+	// are passed to the agent as inline data or file data.
+	DefaultInputModes = []string{"text/plain", "application/json", "image/*", "audio/*", "video/*", "application/pdf"}
+	// DefaultOutputModes are the media types an agent served by [Executor] can produce. Inline data
+	// and saved artifacts are sent to the client as A2A file parts.
+	DefaultOutputModes = []string{"text/plain", "application/json", "image/*", "audio/*", "video/*", "application/pdf"}
+)
+
 // BuildAgentSkills attempts to create a list of [a2a.AgentSkill]s based on agent descriptions and types.
 // This information can be used in [a2a.AgentCard] to help clients understand agent capabilities.
 func BuildAgentSkills(agent agent.Agent) []a2a.AgentSkill {
@@ -53,6 +62,8 @@ func buildSubAgentSkills(agent agent.Agent) []a2a.AgentSkill {
 				Name:        fmt.Sprintf("%s: %s", sub.Name(), subSkill.Name),
 				Description: subSkill.Description,
 				Tags:        slices.Concat([]string{fmt.Sprintf("sub_agent:%s", sub.Name())}, subSkill.Tags),
+				InputModes:  subSkill.InputModes,
+				OutputModes: subSkill.OutputModes,
 			}
 			result = append(result, skill)
 		}
@@ -69,6 +80,9 @@ func buildLLMAgentSkills(agent agent.Agent, llmState *llminternal.State) []a2a.A
 			Tags:        []string{"llm"},
 		},
 	}
+	if llmState.OutputSchema != nil {
+		skills[0].OutputModes = []string{"application/json"}
+	}
 
 	if len(llmState.Tools) > 0 {
 		for _, tool := range llmState.Tools {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
@@ -69,6 +70,21 @@ func TestGetAgentSkills_LLMAgent(t *testing.T) {
 				Tags:        []string{"llm"},
 			}},
 		},
+		{
+			name: "llm with output schema",
+			agent: must(llmagent.New(llmagent.Config{
+				Name:         "Test LLM",
+				Description:  "Test llm.",
+				OutputSchema: &genai.Schema{Type: genai.TypeObject},
+			})),
+			want: []a2a.AgentSkill{{
+				ID:          "Test LLM",
+				Description: "Test llm.",
+				Name:        "model",
+				Tags:        []string{"llm"},
+				OutputModes: []string{"application/json"},
+			}},
+		},
 		{
 			name: "llm with tools",
 			agent: must(llmagent.New(llmagent.Config{
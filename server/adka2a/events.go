@@ -30,6 +30,9 @@ var (
 
 	metadataEscalateKey        = ToA2AMetaKey("escalate")
 	metadataTransferToAgentKey = ToA2AMetaKey("transfer_to_agent")
+	// metadataIntermediateKey marks the working status updates which carry
+	// an intermediate event of an ADK agent, rather than a progress message.
+	metadataIntermediateKey = ToA2AMetaKey("intermediate")
 )
 
 // NewRemoteAgentEvent create a new Event authored by the agent running in the provided invocation context.
@@ -100,8 +103,10 @@ func ToSessionEvent(ctx agent.InvocationContext, event a2a.Event) (*session.Even
 		if len(event.Content.Parts) == 0 {
 			return nil, nil
 		}
-		for _, part := range event.Content.Parts {
-			part.Thought = true
+		if intermediate, _ := v.Metadata[metadataIntermediateKey].(bool); !intermediate {
+			for _, part := range event.Content.Parts {
+				part.Thought = true
+			}
 		}
 		event.Partial = true
 		return event, nil
@@ -326,6 +326,32 @@ func TestToSessionEvent(t *testing.T) {
 				Branch: branch,
 			},
 		},
+		{
+			name: "intermediate ADK event in a non final task status update is not a thought",
+			input: &a2a.TaskStatusUpdateEvent{
+				TaskID:    taskID,
+				ContextID: contextID,
+				Status: a2a.TaskStatus{
+					State: a2a.TaskStateWorking,
+					Message: &a2a.Message{
+						Parts: []a2a.Part{a2a.TextPart{Text: "foo"}},
+					},
+				},
+				Metadata: map[string]any{metadataIntermediateKey: true},
+			},
+			want: &session.Event{
+				LLMResponse: model.LLMResponse{
+					Content: genai.NewContentFromParts([]*genai.Part{{Text: "foo"}}, genai.RoleModel),
+					CustomMetadata: map[string]any{
+						customMetaTaskIDKey:    string(taskID),
+						customMetaContextIDKey: contextID,
+					},
+					Partial: true,
+				},
+				Author: agentName,
+				Branch: branch,
+			},
+		},
 		{
 			name:  "non-final task status update without message is skipped",
 			input: &a2a.TaskStatusUpdateEvent{TaskID: taskID, ContextID: contextID},
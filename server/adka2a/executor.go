@@ -17,6 +17,9 @@ package adka2a
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
@@ -24,6 +27,7 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 )
@@ -33,6 +37,10 @@ type ExecutorConfig struct {
 	// RunnerConfig is the configuration which will be used for [runner.New] during A2A Execute invocation.
 	RunnerConfig runner.Config
 	// RunConfig is the configuration which will be passed to [runner.Runner.Run] during A2A Execute invocation.
+	//
+	// The A2A file parts of the incoming messages are passed to the agent as inline data or file data parts.
+	// With SaveInputBlobsAsArtifacts, the inline data is saved with the artifact service of RunnerConfig instead
+	// and replaced by a placeholder.
 	RunConfig agent.RunConfig
 }
 
@@ -41,19 +49,38 @@ var _ a2asrv.AgentExecutor = (*Executor)(nil)
 // Executor invokes an ADK agent and translates [session.Event]s to [a2a.Event]s according to the following rules:
 //   - If the input doesn't reference any a2a.Task, produce a TaskStatusUpdateEvent with TaskStateSubmitted.
 //   - Right before runner.Runner invocation, produce TaskStatusUpdateEvent with TaskStateWorking.
-//   - For every session.Event produce a TaskArtifactUpdateEvent{Append=true} with transformed parts.
+//   - For every final response session.Event produce a TaskArtifactUpdateEvent{Append=true} with transformed parts.
+//   - For every intermediate session.Event, e.g. a function call, a function response or a partial response,
+//     produce a TaskStatusUpdateEvent with TaskStateWorking and a message with transformed parts.
+//   - For every artifact saved by a session.Event (see [session.EventActions].ArtifactDelta) produce a
+//     TaskArtifactUpdateEvent{LastChunk=true} with a new artifact named after the file, carrying it as a file part.
 //   - After the last session.Event is processed produce an empty TaskArtifactUpdateEvent{Append=true} with LastChunk=true,
 //     if at least one artifact update was produced during the run.
 //   - If there was an LLMResponse with non-zero error code, produce a TaskStatusUpdateEvent with TaskStateFailed.
 //     Else if there was an LLMResponse with long-running tool invocation, produce a TaskStatusUpdateEvent with TaskStateInputRequired.
 //     Else produce a TaskStatusUpdateEvent with TaskStateCompleted.
+//   - If the runner fails, produce a TaskStatusUpdateEvent with TaskStateFailed and the error message.
+//   - If the task is canceled, the context of the runner invocation is canceled and no more events are produced,
+//     Cancel produces a TaskStatusUpdateEvent with TaskStateCanceled.
+//
+// Push notifications are sent by the request handler for every produced event. To enable them,
+// create the handler with [a2asrv.WithPushNotifications] and advertise the capability in the agent card.
 type Executor struct {
 	config ExecutorConfig
+
+	mu      sync.Mutex
+	running map[a2a.TaskID]*execution
+}
+
+// execution is a runner invocation of a task.
+type execution struct {
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 // NewExecutor creates an initialized [Executor] instance.
 func NewExecutor(config ExecutorConfig) *Executor {
-	return &Executor{config: config}
+	return &Executor{config: config, running: make(map[a2a.TaskID]*execution)}
 }
 
 func (e *Executor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
@@ -93,26 +120,66 @@ func (e *Executor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, q
 		return err
 	}
 
+	runCtx, cancel := context.WithCancel(ctx)
+	exec := &execution{cancel: cancel, done: make(chan struct{})}
+	e.setRunning(reqCtx.TaskID, exec)
+	defer func() {
+		cancel()
+		e.setRunning(reqCtx.TaskID, nil)
+		close(exec.done)
+	}()
+
 	processor := newEventProcessor(reqCtx, invocationMeta)
-	if err := e.process(ctx, r, processor, content, queue); err != nil {
+	if err := e.process(runCtx, r, processor, content, queue); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Cancel stops the runner invocation of the task if it is being executed
+// and transitions the task to the canceled state.
 func (e *Executor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	e.mu.Lock()
+	exec := e.running[reqCtx.TaskID]
+	e.mu.Unlock()
+	if exec != nil {
+		exec.cancel()
+		// Wait for the invocation to stop, so that no events are produced after the cancelation.
+		select {
+		case <-exec.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
 	event := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCanceled, nil)
+	event.Final = true
 	if err := queue.Write(ctx, event); err != nil {
 		return err
 	}
 	return nil
 }
 
+// setRunning registers the execution of a task, nil execution removes the registration.
+func (e *Executor) setRunning(taskID a2a.TaskID, exec *execution) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if exec == nil {
+		delete(e.running, taskID)
+		return
+	}
+	e.running[taskID] = exec
+}
+
 // Processing failures should be delivered as Task failed events. An error is returned from this method if an event write fails.
 func (e *Executor) process(ctx context.Context, r *runner.Runner, processor *eventProcessor, content *genai.Content, q eventqueue.Queue) error {
 	meta := processor.meta
 	for event, err := range r.Run(ctx, meta.userID, meta.sessionID, content, e.config.RunConfig) {
+		if ctx.Err() != nil {
+			// The task was canceled, the canceled status is reported by Cancel.
+			return nil
+		}
 		if err != nil {
 			event := processor.makeTaskFailedEvent(fmt.Errorf("agent run failed: %w", err), nil)
 			if eventSendErr := q.Write(ctx, event); eventSendErr != nil {
@@ -135,8 +202,25 @@ func (e *Executor) process(ctx context.Context, r *runner.Runner, processor *eve
 				return fmt.Errorf("send event failed: %w", err)
 			}
 		}
+
+		artifactEvents, err := e.artifactEvents(ctx, processor, event)
+		if err != nil {
+			event := processor.makeTaskFailedEvent(err, event)
+			if eventSendErr := q.Write(ctx, event); eventSendErr != nil {
+				return fmt.Errorf("artifact error event write failed: %w, %w", err, eventSendErr)
+			}
+			return nil
+		}
+		for _, ev := range artifactEvents {
+			if err := q.Write(ctx, ev); err != nil {
+				return fmt.Errorf("send artifact event failed: %w", err)
+			}
+		}
 	}
 
+	if ctx.Err() != nil {
+		return nil
+	}
 	for _, ev := range processor.makeTerminalEvents() {
 		if err := q.Write(ctx, ev); err != nil {
 			return fmt.Errorf("terminal event send failed: %w", err)
@@ -146,6 +230,39 @@ func (e *Executor) process(ctx context.Context, r *runner.Runner, processor *eve
 	return nil
 }
 
+// artifactEvents loads the artifacts saved by the event and returns an artifact update for each of them,
+// carrying the artifact as a file part.
+func (e *Executor) artifactEvents(ctx context.Context, processor *eventProcessor, event *session.Event) ([]a2a.Event, error) {
+	service := e.config.RunnerConfig.ArtifactService
+	if service == nil || len(event.Actions.ArtifactDelta) == 0 {
+		return nil, nil
+	}
+	var result []a2a.Event
+	for _, name := range slices.Sorted(maps.Keys(event.Actions.ArtifactDelta)) {
+		version := event.Actions.ArtifactDelta[name]
+		resp, err := service.Load(ctx, &artifact.LoadRequest{
+			AppName:   e.config.RunnerConfig.AppName,
+			UserID:    processor.meta.userID,
+			SessionID: processor.meta.sessionID,
+			FileName:  name,
+			Version:   version,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load artifact %q: %w", name, err)
+		}
+		parts, err := ToA2AParts([]*genai.Part{resp.Part}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert artifact %q: %w", name, err)
+		}
+		ev := a2a.NewArtifactEvent(processor.reqCtx, parts...)
+		ev.Artifact.Name = name
+		ev.Artifact.Metadata = map[string]any{ToA2AMetaKey("artifact_version"): version}
+		ev.LastChunk = true
+		result = append(result, ev)
+	}
+	return result, nil
+}
+
 func (e *Executor) prepareSession(ctx context.Context, meta invocationMeta) error {
 	service := e.config.RunnerConfig.SessionService
 
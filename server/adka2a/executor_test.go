@@ -16,9 +16,13 @@ package adka2a
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"iter"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
@@ -28,6 +32,7 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
@@ -147,6 +152,32 @@ func TestExecutor_Execute(t *testing.T) {
 				newFinalStatusUpdate(task, a2a.TaskStateCompleted, nil),
 			},
 		},
+		{
+			name:    "intermediate events",
+			request: &a2a.MessageSendParams{Message: hiMsgForTask},
+			events: []*session.Event{
+				{LLMResponse: modelResponseFromParts(genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Warsaw"}))},
+				{LLMResponse: modelResponseFromParts(genai.NewPartFromFunctionResponse("get_weather", map[string]any{"weather": "sunny"}))},
+				{LLMResponse: modelResponseFromParts(genai.NewPartFromText("It's sunny"))},
+			},
+			wantEvents: []a2a.Event{
+				a2a.NewStatusUpdateEvent(task, a2a.TaskStateWorking, nil),
+				newIntermediateStatusUpdate(task, a2a.DataPart{
+					Data: map[string]any{"name": "get_weather", "args": map[string]any{"city": "Warsaw"}},
+					Metadata: map[string]any{
+						a2aDataPartMetaTypeKey:        a2aDataPartTypeFunctionCall,
+						a2aDataPartMetaLongRunningKey: false,
+					},
+				}),
+				newIntermediateStatusUpdate(task, a2a.DataPart{
+					Data:     map[string]any{"name": "get_weather", "response": map[string]any{"weather": "sunny"}},
+					Metadata: map[string]any{a2aDataPartMetaTypeKey: a2aDataPartTypeFunctionResponse},
+				}),
+				a2a.NewArtifactEvent(task, a2a.TextPart{Text: "It's sunny"}),
+				newArtifactLastChunkEvent(task),
+				newFinalStatusUpdate(task, a2a.TaskStateCompleted, nil),
+			},
+		},
 		{
 			name:            "queue write fails",
 			request:         &a2a.MessageSendParams{Message: hiMsgForTask},
@@ -264,6 +295,205 @@ func TestExecutor_Cancel(t *testing.T) {
 	}
 }
 
+// syncQueue is a queue safe for concurrent writes, which notifies about every written event.
+type syncQueue struct {
+	eventqueue.Queue
+	mu      sync.Mutex
+	events  []a2a.Event
+	written chan a2a.Event
+}
+
+func (q *syncQueue) Write(_ context.Context, e a2a.Event) error {
+	q.mu.Lock()
+	q.events = append(q.events, e)
+	q.mu.Unlock()
+	select {
+	case q.written <- e:
+	default:
+	}
+	return nil
+}
+
+func TestExecutor_CancelRunning(t *testing.T) {
+	ctx := t.Context()
+	runCanceled := make(chan struct{})
+	agent, err := agent.New(agent.Config{
+		Name: "test",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for i := 0; ; i++ {
+					select {
+					case <-ctx.Done():
+						close(runCanceled)
+						yield(nil, ctx.Err())
+						return
+					case <-time.After(5 * time.Millisecond):
+					}
+					event := session.NewEvent(ctx.InvocationID())
+					event.Content = genai.NewContentFromText(fmt.Sprintf("chunk %d", i), genai.RoleModel)
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+
+	task := &a2a.Task{ID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
+	msg := a2a.NewMessageForTask(a2a.MessageRoleUser, task, a2a.TextPart{Text: "hi"})
+	reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID, Message: msg}
+	executor := NewExecutor(ExecutorConfig{
+		RunnerConfig: runner.Config{AppName: agent.Name(), Agent: agent, SessionService: session.InMemoryService()},
+	})
+	queue := &syncQueue{written: make(chan a2a.Event, 100)}
+
+	done := make(chan error)
+	go func() { done <- executor.Execute(ctx, reqCtx, queue) }()
+
+	artifacts := 0
+	for artifacts < 2 {
+		select {
+		case ev := <-queue.written:
+			if _, ok := ev.(*a2a.TaskArtifactUpdateEvent); ok {
+				artifacts++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for agent events")
+		}
+	}
+
+	reqCtx.StoredTask = task
+	if err := executor.Cancel(ctx, reqCtx, queue); err != nil {
+		t.Fatalf("executor.Cancel() error = %v, want nil", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("executor.Execute() error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("executor.Execute() did not stop after cancelation")
+	}
+	select {
+	case <-runCanceled:
+	default:
+		t.Error("the context of the agent run was not canceled")
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	canceledAt := -1
+	for i, ev := range queue.events {
+		if update, ok := ev.(*a2a.TaskStatusUpdateEvent); ok && update.Status.State == a2a.TaskStateCanceled {
+			canceledAt = i
+			if !update.Final {
+				t.Error("canceled status update is not final")
+			}
+		}
+	}
+	if canceledAt == -1 {
+		t.Fatalf("no TaskStateCanceled update in %v", queue.events)
+	}
+	if extra := queue.events[canceledAt+1:]; len(extra) > 0 {
+		t.Errorf("got %d events after cancelation, want none: %v", len(extra), extra)
+	}
+}
+
+func TestExecutor_Artifacts(t *testing.T) {
+	image := []byte{0x89, 'P', 'N', 'G'}
+	task := &a2a.Task{ID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
+	filePart := a2a.FilePart{File: a2a.FileBytes{
+		FileMeta: a2a.FileMeta{Name: "cat.png", MimeType: "image/png"},
+		Bytes:    base64.StdEncoding.EncodeToString(image),
+	}}
+
+	testCases := []struct {
+		name          string
+		saveInputs    bool
+		wantUserParts []*genai.Part
+	}{
+		{
+			name:          "inline data",
+			wantUserParts: []*genai.Part{{InlineData: &genai.Blob{DisplayName: "cat.png", MIMEType: "image/png", Data: image}}},
+		},
+		{
+			name:          "input saved as artifact",
+			saveInputs:    true,
+			wantUserParts: []*genai.Part{genai.NewPartFromText("Uploaded file: artifact_")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotUserParts []*genai.Part
+			echoAgent, err := agent.New(agent.Config{
+				Name: "echo",
+				Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+					return func(yield func(*session.Event, error) bool) {
+						gotUserParts = ctx.UserContent().Parts
+						part := &genai.Part{InlineData: &genai.Blob{DisplayName: "cat.png", MIMEType: "image/png", Data: image}}
+						resp, err := ctx.Artifacts().Save(ctx, "cat.png", part)
+						if err != nil {
+							yield(nil, err)
+							return
+						}
+						event := session.NewEvent(ctx.InvocationID())
+						event.Content = genai.NewContentFromParts([]*genai.Part{part}, genai.RoleModel)
+						event.Actions.ArtifactDelta = map[string]int64{"cat.png": resp.Version}
+						yield(event, nil)
+					}
+				},
+			})
+			if err != nil {
+				t.Fatalf("agent.New() error = %v", err)
+			}
+			runnerConfig := runner.Config{
+				AppName:         echoAgent.Name(),
+				Agent:           echoAgent,
+				SessionService:  session.InMemoryService(),
+				ArtifactService: artifact.InMemoryService(),
+			}
+			executor := NewExecutor(ExecutorConfig{
+				RunnerConfig: runnerConfig,
+				RunConfig:    agent.RunConfig{SaveInputBlobsAsArtifacts: tc.saveInputs},
+			})
+			queue := &testQueue{Queue: eventqueue.NewInMemoryQueue(10)}
+			msg := a2a.NewMessageForTask(a2a.MessageRoleUser, task, filePart)
+			reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID, Message: msg}
+
+			if err := executor.Execute(t.Context(), reqCtx, queue); err != nil {
+				t.Fatalf("executor.Execute() error = %v", err)
+			}
+
+			if tc.saveInputs {
+				if len(gotUserParts) != 1 || !strings.HasPrefix(gotUserParts[0].Text, tc.wantUserParts[0].Text) {
+					t.Errorf("agent got user parts %v, want a placeholder starting with %q", gotUserParts, tc.wantUserParts[0].Text)
+				}
+			} else if diff := cmp.Diff(tc.wantUserParts, gotUserParts); diff != "" {
+				t.Errorf("agent got wrong user parts (-want +got):\n%s", diff)
+			}
+
+			var gotArtifacts []*a2a.Artifact
+			for _, event := range queue.events {
+				if ev, ok := event.(*a2a.TaskArtifactUpdateEvent); ok {
+					gotArtifacts = append(gotArtifacts, ev.Artifact)
+				}
+			}
+			wantArtifacts := []*a2a.Artifact{
+				{Parts: a2a.ContentParts{filePart}},
+				{Name: "cat.png", Parts: a2a.ContentParts{filePart}, Metadata: map[string]any{ToA2AMetaKey("artifact_version"): int64(1)}},
+				{},
+			}
+			if diff := cmp.Diff(wantArtifacts, gotArtifacts, cmpopts.IgnoreFields(a2a.Artifact{}, "ID")); diff != "" {
+				t.Errorf("executor.Execute() wrong artifacts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestExecutor_SessionReuse(t *testing.T) {
 	ctx := t.Context()
 	agent, err := newEventReplayAgent([]*session.Event{}, nil)
@@ -55,7 +55,11 @@ func newEventProcessor(reqCtx *a2asrv.RequestContext, meta invocationMeta) *even
 	}
 }
 
-func (p *eventProcessor) process(_ context.Context, event *session.Event) (*a2a.TaskArtifactUpdateEvent, error) {
+// process converts an ADK event to an A2A event. The final responses are
+// appended to the response artifact, the intermediate events, e.g. function
+// calls and responses or partial responses, are reported as working status
+// updates carrying the event parts in a message.
+func (p *eventProcessor) process(_ context.Context, event *session.Event) (a2a.Event, error) {
 	if event == nil {
 		return nil, nil
 	}
@@ -93,6 +97,14 @@ func (p *eventProcessor) process(_ context.Context, event *session.Event) (*a2a.
 		return nil, err
 	}
 
+	if !event.IsFinalResponse() {
+		msg := a2a.NewMessageForTask(a2a.MessageRoleAgent, p.reqCtx, parts...)
+		result := a2a.NewStatusUpdateEvent(p.reqCtx, a2a.TaskStateWorking, msg)
+		eventMeta[metadataIntermediateKey] = true
+		result.Metadata = eventMeta
+		return result, nil
+	}
+
 	var result *a2a.TaskArtifactUpdateEvent
 	if p.responseID == "" {
 		result = a2a.NewArtifactEvent(p.reqCtx, parts...)
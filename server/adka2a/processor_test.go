@@ -43,6 +43,12 @@ func newFinalStatusUpdate(task *a2a.Task, state a2a.TaskState, msg *a2a.Message)
 	return ev
 }
 
+func newIntermediateStatusUpdate(task *a2a.Task, parts ...a2a.Part) *a2a.TaskStatusUpdateEvent {
+	ev := a2a.NewStatusUpdateEvent(task, a2a.TaskStateWorking, a2a.NewMessageForTask(a2a.MessageRoleAgent, task, parts...))
+	ev.Metadata = map[string]any{metadataIntermediateKey: true}
+	return ev
+}
+
 func TestEventProcessor_Process(t *testing.T) {
 	artifactIDPlaceholder := a2a.NewArtifactID()
 	task := &a2a.Task{ID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
@@ -50,7 +56,7 @@ func TestEventProcessor_Process(t *testing.T) {
 	testCases := []struct {
 		name      string
 		events    []*session.Event
-		processed []*a2a.TaskArtifactUpdateEvent
+		processed []a2a.Event
 		terminal  []a2a.Event
 	}{
 		{
@@ -74,7 +80,7 @@ func TestEventProcessor_Process(t *testing.T) {
 			events: []*session.Event{{
 				LLMResponse: modelResponseFromParts(genai.NewPartFromText("Hello"), genai.NewPartFromText(", world!")),
 			}},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.TextPart{Text: "Hello"}, a2a.TextPart{Text: ", world!"}),
 			},
 			terminal: []a2a.Event{
@@ -95,12 +101,13 @@ func TestEventProcessor_Process(t *testing.T) {
 					LLMResponse: modelResponseFromParts(genai.NewPartFromText("The answer is 42")),
 				},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.DataPart{
 					Data:     map[string]any{"code": "get_the_answer()", "language": string(genai.LanguagePython)},
 					Metadata: map[string]any{a2aDataPartMetaTypeKey: a2aDataPartTypeCodeExecutableCode},
 				}),
-				a2a.NewArtifactUpdateEvent(task, artifactIDPlaceholder, a2a.DataPart{
+				// A code execution result isn't a final response.
+				newIntermediateStatusUpdate(task, a2a.DataPart{
 					Data:     map[string]any{"outcome": string(genai.OutcomeOK), "output": "42"},
 					Metadata: map[string]any{a2aDataPartMetaTypeKey: a2aDataPartTypeCodeExecResult},
 				}),
@@ -143,7 +150,7 @@ func TestEventProcessor_Process(t *testing.T) {
 				{LLMResponse: modelResponseFromParts(genai.NewPartFromText("42"))},
 				{LLMResponse: model.LLMResponse{ErrorCode: "1", ErrorMessage: "failed"}},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.TextPart{Text: "The answer is"}),
 				a2a.NewArtifactUpdateEvent(task, artifactIDPlaceholder, a2a.TextPart{Text: "42"}),
 			},
@@ -162,7 +169,7 @@ func TestEventProcessor_Process(t *testing.T) {
 				{LLMResponse: model.LLMResponse{ErrorCode: "1", ErrorMessage: "failed"}},
 				{LLMResponse: modelResponseFromParts(genai.NewPartFromText("42"))},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.TextPart{Text: "The answer is"}),
 				a2a.NewArtifactUpdateEvent(task, artifactIDPlaceholder, a2a.TextPart{Text: "42"}),
 			},
@@ -179,8 +186,8 @@ func TestEventProcessor_Process(t *testing.T) {
 			events: []*session.Event{
 				{LLMResponse: modelResponseFromParts(genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Warsaw"}))},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
-				a2a.NewArtifactEvent(task, a2a.DataPart{
+			processed: []a2a.Event{
+				newIntermediateStatusUpdate(task, a2a.DataPart{
 					Data: map[string]any{"name": "get_weather", "args": map[string]any{"city": "Warsaw"}},
 					Metadata: map[string]any{
 						a2aDataPartMetaTypeKey:        a2aDataPartTypeFunctionCall,
@@ -189,7 +196,6 @@ func TestEventProcessor_Process(t *testing.T) {
 				}),
 			},
 			terminal: []a2a.Event{
-				newArtifactLastChunkEvent(task),
 				newFinalStatusUpdate(task, a2a.TaskStateCompleted, nil),
 			},
 		},
@@ -203,7 +209,7 @@ func TestEventProcessor_Process(t *testing.T) {
 					}),
 				},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.DataPart{
 					Data: map[string]any{"id": "get_weather", "name": "weather", "args": map[string]any{"city": "Warsaw"}},
 					Metadata: map[string]any{
@@ -230,7 +236,7 @@ func TestEventProcessor_Process(t *testing.T) {
 					LLMResponse: modelResponseFromParts(genai.NewPartFromText("This will take a while")),
 				},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.DataPart{
 					Data: map[string]any{"id": "get_weather", "name": "weather", "args": map[string]any{"city": "Warsaw"}},
 					Metadata: map[string]any{
@@ -285,7 +291,7 @@ func TestEventProcessor_Process(t *testing.T) {
 				},
 				{LLMResponse: model.LLMResponse{ErrorCode: "1", ErrorMessage: "failed"}},
 			},
-			processed: []*a2a.TaskArtifactUpdateEvent{
+			processed: []a2a.Event{
 				a2a.NewArtifactEvent(task, a2a.TextPart{Text: "The answer is"}),
 			},
 			terminal: []a2a.Event{
@@ -308,7 +314,7 @@ func TestEventProcessor_Process(t *testing.T) {
 			reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID}
 			processor := newEventProcessor(reqCtx, invocationMeta{})
 
-			var gotEvents []*a2a.TaskArtifactUpdateEvent
+			var gotEvents []a2a.Event
 			for _, event := range tc.events {
 				got, err := processor.process(t.Context(), event)
 				if err != nil {
@@ -354,19 +360,27 @@ func TestEventProcessor_ArtifactUpdates(t *testing.T) {
 
 	reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID}
 	processor := newEventProcessor(reqCtx, invocationMeta{})
-	got := make([]*a2a.TaskArtifactUpdateEvent, len(events))
+	var got []*a2a.TaskArtifactUpdateEvent
 	for i, event := range events {
 		processed, err := processor.process(t.Context(), event)
 		if err != nil {
 			t.Fatalf("processor.process() error for %d-th = %v, want nil", i, err)
 		}
-		if processed != nil {
-			got[i] = processed
+		switch ev := processed.(type) {
+		case *a2a.TaskArtifactUpdateEvent:
+			got = append(got, ev)
+		case *a2a.TaskStatusUpdateEvent:
+			// The function call and response are intermediate events.
+			if ev.Status.State != a2a.TaskStateWorking || ev.Final {
+				t.Fatalf("processor.process()[%d] = %+v, want a working status update", i, ev)
+			}
+		default:
+			t.Fatalf("processor.process()[%d] = %T, want an artifact or status update", i, processed)
 		}
 	}
 
-	if len(events) != len(got) {
-		t.Fatalf("processor.process() returned %d events, want %d\nevents = %v", len(got), len(events), got)
+	if len(got) != 2 {
+		t.Fatalf("processor.process() returned %d artifact updates, want 2\nevents = %v", len(got), got)
 	}
 	if got[0].Append || got[0].LastChunk {
 		t.Fatalf("processor.process()[0] = %+v, want {Append=false, LastChunk=false}", got[0])
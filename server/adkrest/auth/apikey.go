@@ -0,0 +1,58 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+// APIKeyHeader is the header carrying the API key, as an alternative to the
+// Authorization: Bearer header.
+const APIKeyHeader = "X-API-Key"
+
+type apiKeys map[string]string
+
+// APIKeys returns an [Authenticator] accepting the given static API keys,
+// mapped to the subjects they identify. A key may map to an empty subject
+// if it doesn't identify a user, [SameUser] then denies its accesses to the
+// data of users. The key is read from the Authorization:
+// Bearer header or from the [APIKeyHeader] header.
+func APIKeys(keys map[string]string) Authenticator {
+	return apiKeys(keys)
+}
+
+func (k apiKeys) Authenticate(req *http.Request) (string, error) {
+	key, ok := bearerToken(req)
+	if !ok {
+		key = req.Header.Get(APIKeyHeader)
+	}
+	if key == "" {
+		return "", fmt.Errorf("%w: missing API key", ErrUnauthenticated)
+	}
+	// Compare with all the keys in constant time not to leak which ones exist.
+	var subject string
+	found := false
+	for candidate, s := range k {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			subject, found = s, true
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+	return subject, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth provides authentication and authorization of the ADK REST API
+// requests.
+//
+// Requests are authenticated with an [Authenticator], e.g. [APIKeys] or
+// [JWT], by the [Middleware]. The authenticated subject is stored in the
+// request context and used as the effective user id of the request, see
+// [UserID]. The API handlers check the access to the data of a user with
+// [Authorize], which applies the configured [Authorizer].
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+var (
+	// ErrUnauthenticated is returned by an [Authenticator] when the request
+	// credentials are missing or invalid.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden is returned by an [Authorizer] when the principal may not
+	// access the requested data.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authenticator verifies the credentials of a request.
+type Authenticator interface {
+	// Authenticate returns the subject identified by the request
+	// credentials, or an empty string if they don't identify a user. It
+	// returns an error wrapping [ErrUnauthenticated] if the credentials are
+	// missing or invalid.
+	Authenticate(r *http.Request) (string, error)
+}
+
+// Config configures the [Middleware].
+type Config struct {
+	Authenticator Authenticator
+	// Authorizer decides which users' data the authenticated principals may
+	// access.
+	// optional, [SameUser] by default
+	Authorizer Authorizer
+}
+
+type identityKey struct{}
+
+type identity struct {
+	subject    string
+	authorizer Authorizer
+}
+
+// Middleware returns a middleware rejecting the requests which fail the
+// authentication with a JSON error response: 401 Unauthorized, or 500
+// Internal Server Error if the credentials couldn't be verified, e.g. the
+// JWKS is unavailable. CORS preflight (OPTIONS) requests are let through
+// unauthenticated.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	authorizer := cfg.Authorizer
+	if authorizer == nil {
+		authorizer = SameUser()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodOptions {
+				next.ServeHTTP(rw, req)
+				return
+			}
+			subject, err := cfg.Authenticator.Authenticate(req)
+			if errors.Is(err, ErrUnauthenticated) {
+				rw.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(rw, err, http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				writeError(rw, fmt.Errorf("authentication failed: %w", err), http.StatusInternalServerError)
+				return
+			}
+			ctx := context.WithValue(req.Context(), identityKey{}, identity{subject: subject, authorizer: authorizer})
+			next.ServeHTTP(rw, req.WithContext(ctx))
+		})
+	}
+}
+
+// Subject returns the subject authenticated by the [Middleware]. It returns
+// false if the request wasn't authenticated or the credentials don't
+// identify a user.
+func Subject(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(identityKey{}).(identity)
+	if !ok || id.subject == "" {
+		return "", false
+	}
+	return id.subject, true
+}
+
+// UserID returns the effective user id of a request asking for the given
+// user id: the authenticated subject if userID is empty, userID otherwise.
+// Whether the principal may act as userID is checked by [Authorize].
+func UserID(ctx context.Context, userID string) string {
+	if subject, ok := Subject(ctx); ok && userID == "" {
+		return subject
+	}
+	return userID
+}
+
+// writeError writes err as a JSON error response, like the API handlers.
+func writeError(rw http.ResponseWriter, err error, status int) {
+	rw.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(models.ErrorResponse{Error: models.Error{
+		Code:    models.ErrorCodeFromStatus(status),
+		Message: err.Error(),
+	}})
+}
+
+// bearerToken returns the token of the Authorization: Bearer header.
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
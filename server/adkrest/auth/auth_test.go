@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/auth"
+)
+
+// newRouter returns a router echoing the authenticated subject.
+func newRouter(cfg auth.Config) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(cfg))
+	echo := func(rw http.ResponseWriter, req *http.Request) {
+		subject, _ := auth.Subject(req.Context())
+		rw.Write([]byte(subject))
+	}
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions", echo).Methods(http.MethodGet, http.MethodOptions)
+	router.HandleFunc("/run", echo).Methods(http.MethodPost)
+	return router
+}
+
+func TestMiddleware_APIKeys(t *testing.T) {
+	keys := auth.APIKeys(map[string]string{"alice-key": "alice", "service-key": ""})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		header      http.Header
+		wantCode    int
+		wantSubject string
+	}{
+		{name: "missing credentials", method: http.MethodPost, path: "/run", wantCode: http.StatusUnauthorized},
+		{name: "invalid bearer key", method: http.MethodPost, path: "/run", header: http.Header{"Authorization": {"Bearer wrong-key"}}, wantCode: http.StatusUnauthorized},
+		{name: "invalid scheme", method: http.MethodPost, path: "/run", header: http.Header{"Authorization": {"Basic alice-key"}}, wantCode: http.StatusUnauthorized},
+		{name: "valid bearer key", method: http.MethodPost, path: "/run", header: http.Header{"Authorization": {"Bearer alice-key"}}, wantCode: http.StatusOK, wantSubject: "alice"},
+		{name: "valid key header", method: http.MethodPost, path: "/run", header: http.Header{auth.APIKeyHeader: {"alice-key"}}, wantCode: http.StatusOK, wantSubject: "alice"},
+		{name: "key without subject", method: http.MethodPost, path: "/run", header: http.Header{auth.APIKeyHeader: {"service-key"}}, wantCode: http.StatusOK},
+		{name: "preflight without credentials", method: http.MethodOptions, path: "/apps/app/users/bob/sessions", wantCode: http.StatusOK},
+		{name: "other user", method: http.MethodGet, path: "/apps/app/users/bob/sessions", header: http.Header{auth.APIKeyHeader: {"alice-key"}}, wantCode: http.StatusOK, wantSubject: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(auth.Config{Authenticator: keys})
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v[0])
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("%s %s status = %d, want %d (body %q)", tt.method, tt.path, rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusUnauthorized {
+				if rr.Header().Get("WWW-Authenticate") == "" {
+					t.Error("401 response without the WWW-Authenticate header")
+				}
+				if body := rr.Body.String(); !strings.Contains(body, `"code":"UNAUTHENTICATED"`) {
+					t.Errorf("401 response body = %q, want a JSON error with code UNAUTHENTICATED", body)
+				}
+			}
+			if tt.wantCode == http.StatusOK && rr.Body.String() != tt.wantSubject {
+				t.Errorf("authenticated subject = %q, want %q", rr.Body.String(), tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestUserID(t *testing.T) {
+	keys := auth.APIKeys(map[string]string{"alice-key": "alice"})
+	tests := []struct {
+		name          string
+		authenticated bool
+		userID        string
+		want          string
+	}{
+		{name: "unauthenticated", userID: "bob", want: "bob"},
+		{name: "defaults to subject", authenticated: true, userID: "", want: "alice"},
+		{name: "other user", authenticated: true, userID: "bob", want: "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/run", nil)
+			if tt.authenticated {
+				req.Header.Set(auth.APIKeyHeader, "alice-key")
+			}
+			var got string
+			var handler http.Handler = http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				got = auth.UserID(req.Context(), tt.userID)
+			})
+			if tt.authenticated {
+				handler = auth.Middleware(auth.Config{Authenticator: keys})(handler)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("UserID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
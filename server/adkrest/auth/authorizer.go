@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Operation is the kind of access to the data of a user.
+type Operation string
+
+const (
+	// OperationRead reads sessions, events, artifacts, traces or evals.
+	OperationRead Operation = "read"
+	// OperationWrite creates or deletes sessions, artifacts or evals.
+	OperationWrite Operation = "write"
+	// OperationRun runs an agent in a session, or an evaluation.
+	OperationRun Operation = "run"
+)
+
+// AccessRequest describes an access to the data of a user.
+type AccessRequest struct {
+	// Principal is the authenticated subject, empty if the credentials
+	// don't identify a user.
+	Principal string
+	AppName   string
+	// UserID is the user whose data is accessed. It is empty for data not
+	// scoped to a user, e.g. debug traces or eval sets.
+	UserID    string
+	Operation Operation
+}
+
+// Authorizer decides whether a principal may access the data of a user.
+type Authorizer interface {
+	// Authorize returns nil if the access is allowed, or an error wrapping
+	// [ErrForbidden] otherwise.
+	Authorize(ctx context.Context, req AccessRequest) error
+}
+
+// AuthorizerFunc is an adapter to use functions as an [Authorizer].
+type AuthorizerFunc func(ctx context.Context, req AccessRequest) error
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(ctx context.Context, req AccessRequest) error {
+	return f(ctx, req)
+}
+
+// SameUser returns the default [Authorizer], which allows principals to
+// access only their own data. The admins may access the data of all users,
+// and the data shared by all users. Principals whose credentials don't
+// identify a user, e.g. API keys without a subject, are denied.
+func SameUser(admins ...string) Authorizer {
+	admins = slices.DeleteFunc(slices.Clone(admins), func(a string) bool { return a == "" })
+	return AuthorizerFunc(func(_ context.Context, req AccessRequest) error {
+		if req.Principal == "" {
+			return fmt.Errorf("%w: the credentials don't identify a user", ErrForbidden)
+		}
+		if req.Principal == req.UserID || slices.Contains(admins, req.Principal) {
+			return nil
+		}
+		if req.UserID == "" {
+			return fmt.Errorf("%w: %q may not %s data shared by all users", ErrForbidden, req.Principal, req.Operation)
+		}
+		return fmt.Errorf("%w: %q may not %s the data of user %q", ErrForbidden, req.Principal, req.Operation, req.UserID)
+	})
+}
+
+// Authorize checks that the principal authenticated by the [Middleware] may
+// perform the operation on the data of the user in the app, with the
+// [Config.Authorizer]. It allows all accesses to requests which didn't go
+// through the middleware, i.e. when authentication is not configured.
+func Authorize(ctx context.Context, appName, userID string, op Operation) error {
+	id, ok := ctx.Value(identityKey{}).(identity)
+	if !ok {
+		return nil
+	}
+	return id.authorizer.Authorize(ctx, AccessRequest{
+		Principal: id.subject,
+		AppName:   appName,
+		UserID:    userID,
+		Operation: op,
+	})
+}
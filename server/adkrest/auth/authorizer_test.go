@@ -0,0 +1,104 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/adk/server/adkrest/auth"
+)
+
+func TestAuthorize(t *testing.T) {
+	keys := auth.APIKeys(map[string]string{"alice-key": "alice", "admin-key": "admin", "service-key": ""})
+	adminOverride := auth.AuthorizerFunc(func(ctx context.Context, req auth.AccessRequest) error {
+		if req.Principal == "admin" && req.Operation == auth.OperationRead {
+			return nil
+		}
+		return auth.SameUser().Authorize(ctx, req)
+	})
+
+	tests := []struct {
+		name       string
+		key        string
+		authorizer auth.Authorizer
+		userID     string
+		op         auth.Operation
+		wantErr    bool
+	}{
+		{name: "unauthenticated", userID: "bob", op: auth.OperationWrite},
+		{name: "same user", key: "alice-key", userID: "alice", op: auth.OperationWrite},
+		{name: "other user", key: "alice-key", userID: "bob", op: auth.OperationRead, wantErr: true},
+		{name: "shared data", key: "alice-key", op: auth.OperationRead, wantErr: true},
+		{name: "key without subject", key: "service-key", userID: "bob", op: auth.OperationRun, wantErr: true},
+		{name: "key without subject shared data", key: "service-key", op: auth.OperationRead, wantErr: true},
+		{name: "key without subject and empty admin", key: "service-key", authorizer: auth.SameUser(""), userID: "bob", op: auth.OperationRead, wantErr: true},
+		{name: "admin", key: "admin-key", authorizer: auth.SameUser("admin"), userID: "bob", op: auth.OperationWrite},
+		{name: "admin shared data", key: "admin-key", authorizer: auth.SameUser("admin"), op: auth.OperationRead},
+		{name: "user with admins", key: "alice-key", authorizer: auth.SameUser("admin"), userID: "bob", op: auth.OperationRead, wantErr: true},
+		{name: "admin without override", key: "admin-key", userID: "bob", op: auth.OperationRead, wantErr: true},
+		{name: "admin override", key: "admin-key", authorizer: adminOverride, userID: "bob", op: auth.OperationRead},
+		{name: "admin override shared data", key: "admin-key", authorizer: adminOverride, op: auth.OperationRead},
+		{name: "admin override write", key: "admin-key", authorizer: adminOverride, userID: "bob", op: auth.OperationWrite, wantErr: true},
+		{name: "user with override", key: "alice-key", authorizer: adminOverride, userID: "bob", op: auth.OperationRead, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			var err error
+			var handler http.Handler = http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				err = auth.Authorize(req.Context(), "app", tt.userID, tt.op)
+			})
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+				handler = auth.Middleware(auth.Config{Authenticator: keys, Authorizer: tt.authorizer})(handler)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, auth.ErrForbidden) {
+				t.Errorf("Authorize() error = %v, want %v", err, auth.ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestAuthorize_AccessRequest(t *testing.T) {
+	var got auth.AccessRequest
+	authorizer := auth.AuthorizerFunc(func(_ context.Context, req auth.AccessRequest) error {
+		got = req
+		return fmt.Errorf("%w: denied", auth.ErrForbidden)
+	})
+	handler := auth.Middleware(auth.Config{
+		Authenticator: auth.APIKeys(map[string]string{"alice-key": "alice"}),
+		Authorizer:    authorizer,
+	})(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		_ = auth.Authorize(req.Context(), "app", "bob", auth.OperationRun)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/run", nil)
+	req.Header.Set(auth.APIKeyHeader, "alice-key")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := auth.AccessRequest{Principal: "alice", AppName: "app", UserID: "bob", Operation: auth.OperationRun}
+	if got != want {
+		t.Errorf("AccessRequest = %+v, want %+v", got, want)
+	}
+}
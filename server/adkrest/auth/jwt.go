@@ -0,0 +1,167 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// minJWKSRefreshInterval limits refetching the key set for tokens signed
+	// with unknown keys.
+	minJWKSRefreshInterval = time.Minute
+)
+
+var jwtAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTConfig configures the [JWT] authenticator.
+type JWTConfig struct {
+	// JWKSURL is the URL of the JSON Web Key Set used to verify the tokens.
+	JWKSURL string
+	// Issuer is the expected "iss" claim.
+	// optional, not checked if empty
+	Issuer string
+	// Audience is the expected "aud" claim, the token must contain one of
+	// them.
+	// optional, not checked if empty
+	Audience []string
+	// AllowMissingExpiry accepts tokens without an "exp" claim. Such tokens
+	// never expire, so they are rejected by default.
+	AllowMissingExpiry bool
+
+	// optional, http.DefaultClient is used by default
+	HTTPClient *http.Client
+	// RefreshInterval is how often the key set is fetched again, to pick up
+	// rotated keys. Key sets are also fetched when a token is signed with an
+	// unknown key, at most once a minute.
+	// optional, one hour by default
+	RefreshInterval time.Duration
+}
+
+type jwtAuthenticator struct {
+	cfg JWTConfig
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// JWT returns an [Authenticator] accepting JSON Web Tokens from the
+// Authorization: Bearer header, signed with a key of the key set at
+// [JWTConfig.JWKSURL]. The subject is the "sub" claim of the token, tokens
+// without it are rejected.
+func JWT(cfg JWTConfig) (Authenticator, error) {
+	if cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("JWKS URL is required")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultJWKSRefreshInterval
+	}
+	return &jwtAuthenticator{cfg: cfg}, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(req *http.Request) (string, error) {
+	raw, ok := bearerToken(req)
+	if !ok {
+		return "", fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+	token, err := jwt.ParseSigned(raw, jwtAlgorithms)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed token: %v", ErrUnauthenticated, err)
+	}
+	if len(token.Headers) == 0 || token.Headers[0].KeyID == "" {
+		return "", fmt.Errorf("%w: token without key id", ErrUnauthenticated)
+	}
+	keys, err := a.keySet(req.Context(), token.Headers[0].KeyID)
+	if err != nil {
+		return "", err
+	}
+
+	var claims jwt.Claims
+	if err := token.Claims(keys, &claims); err != nil {
+		return "", fmt.Errorf("%w: invalid token signature: %v", ErrUnauthenticated, err)
+	}
+	expected := jwt.Expected{Issuer: a.cfg.Issuer, AnyAudience: a.cfg.Audience, Time: time.Now()}
+	if err := claims.Validate(expected); err != nil {
+		return "", fmt.Errorf("%w: invalid token claims: %v", ErrUnauthenticated, err)
+	}
+	if claims.Subject == "" {
+		return "", fmt.Errorf("%w: token without subject", ErrUnauthenticated)
+	}
+	if claims.Expiry == nil && !a.cfg.AllowMissingExpiry {
+		return "", fmt.Errorf("%w: token without expiry", ErrUnauthenticated)
+	}
+	return claims.Subject, nil
+}
+
+// keySet returns the cached key set, fetching it if it is stale or doesn't
+// have the key with the given id.
+func (a *jwtAuthenticator) keySet(ctx context.Context, kid string) (*jose.JSONWebKeySet, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	age := time.Since(a.fetchedAt)
+	stale := a.keys == nil || age > a.cfg.RefreshInterval
+	unknownKey := a.keys != nil && len(a.keys.Key(kid)) == 0 && age > minJWKSRefreshInterval
+	if stale || unknownKey {
+		keys, err := a.fetchKeySet(ctx)
+		if err != nil {
+			if a.keys == nil {
+				return nil, err
+			}
+			// Keep using the previous keys until the key set is available.
+		} else {
+			a.keys, a.fetchedAt = keys, time.Now()
+		}
+	}
+	return a.keys, nil
+}
+
+func (a *jwtAuthenticator) fetchKeySet(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the JWKS request: %w", err)
+	}
+	resp, err := a.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the JWKS: status %s", resp.Status)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to decode the JWKS: %w", err)
+	}
+	return &keys, nil
+}
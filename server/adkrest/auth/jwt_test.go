@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"google.golang.org/adk/server/adkrest/auth"
+)
+
+func newSigner(t *testing.T, kid string) (jose.Signer, jose.JSONWebKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: string(jose.RS256), Use: "sig"}
+}
+
+func signToken(t *testing.T, signer jose.Signer, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWT(t *testing.T) {
+	signer, publicKey := newSigner(t, "key-1")
+	unknownSigner, _ := newSigner(t, "key-2")
+	jwks := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{publicKey}})
+	}))
+	defer jwks.Close()
+
+	authenticator, err := auth.JWT(auth.JWTConfig{JWKSURL: jwks.URL, Issuer: "https://issuer.example.com", Audience: []string{"adk-api"}})
+	if err != nil {
+		t.Fatalf("JWT() error = %v", err)
+	}
+
+	now := time.Now()
+	valid := jwt.Claims{
+		Subject:  "alice",
+		Issuer:   "https://issuer.example.com",
+		Audience: jwt.Audience{"adk-api"},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+	withClaims := func(modify func(*jwt.Claims)) jwt.Claims {
+		c := valid
+		modify(&c)
+		return c
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantSubject   string
+		wantErr       bool
+	}{
+		{name: "valid", authorization: "Bearer " + signToken(t, signer, valid), wantSubject: "alice"},
+		{name: "missing", authorization: "", wantErr: true},
+		{name: "malformed", authorization: "Bearer not-a-token", wantErr: true},
+		{name: "unknown key", authorization: "Bearer " + signToken(t, unknownSigner, valid), wantErr: true},
+		{name: "expired", authorization: "Bearer " + signToken(t, signer, withClaims(func(c *jwt.Claims) { c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour)) })), wantErr: true},
+		{name: "wrong audience", authorization: "Bearer " + signToken(t, signer, withClaims(func(c *jwt.Claims) { c.Audience = jwt.Audience{"other-api"} })), wantErr: true},
+		{name: "wrong issuer", authorization: "Bearer " + signToken(t, signer, withClaims(func(c *jwt.Claims) { c.Issuer = "https://evil.example.com" })), wantErr: true},
+		{name: "no subject", authorization: "Bearer " + signToken(t, signer, withClaims(func(c *jwt.Claims) { c.Subject = "" })), wantErr: true},
+		{name: "no expiry", authorization: "Bearer " + signToken(t, signer, withClaims(func(c *jwt.Claims) { c.Expiry = nil })), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/run", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			subject, err := authenticator.Authenticate(req)
+			if tt.wantErr {
+				if !errors.Is(err, auth.ErrUnauthenticated) {
+					t.Errorf("Authenticate() error = %v, want %v", err, auth.ErrUnauthenticated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("Authenticate() = %q, want %q", subject, tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestJWT_AllowMissingExpiry(t *testing.T) {
+	signer, publicKey := newSigner(t, "key-1")
+	jwks := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{publicKey}})
+	}))
+	defer jwks.Close()
+	authenticator, err := auth.JWT(auth.JWTConfig{JWKSURL: jwks.URL, AllowMissingExpiry: true})
+	if err != nil {
+		t.Fatalf("JWT() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/run", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, signer, jwt.Claims{Subject: "alice"}))
+	subject, err := authenticator.Authenticate(req)
+	if err != nil || subject != "alice" {
+		t.Errorf("Authenticate() = %q, %v, want %q", subject, err, "alice")
+	}
+}
+
+func TestJWT_UnavailableKeySet(t *testing.T) {
+	signer, _ := newSigner(t, "key-1")
+	jwks := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+	authenticator, err := auth.JWT(auth.JWTConfig{JWKSURL: jwks.URL})
+	if err != nil {
+		t.Fatalf("JWT() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/run", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, signer, jwt.Claims{Subject: "alice"}))
+	rr := httptest.NewRecorder()
+	auth.Middleware(auth.Config{Authenticator: authenticator})(http.NotFoundHandler()).ServeHTTP(rr, req)
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d when the key set is unavailable", rr.Code, http.StatusInternalServerError)
+	}
+}
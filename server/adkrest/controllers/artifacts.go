@@ -15,12 +15,14 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/auth"
 	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
@@ -38,11 +40,15 @@ func (c *ArtifactsAPIController) ListArtifactsHandler(rw http.ResponseWriter, re
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	resp, err := c.artifactService.List(req.Context(), &artifact.ListRequest{
@@ -51,7 +57,7 @@ func (c *ArtifactsAPIController) ListArtifactsHandler(rw http.ResponseWriter, re
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	files := resp.FileNames
@@ -66,16 +72,20 @@ func (c *ArtifactsAPIController) LoadArtifactHandler(rw http.ResponseWriter, req
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	artifactName := vars["artifact_name"]
 	if artifactName == "" {
-		http.Error(rw, "artifact_name parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("artifact_name parameter is required"), http.StatusBadRequest)
 		return
 	}
 	loadReq := &artifact.LoadRequest{
@@ -90,7 +100,7 @@ func (c *ArtifactsAPIController) LoadArtifactHandler(rw http.ResponseWriter, req
 	if version != "" {
 		versionInt, err := strconv.Atoi(version)
 		if err != nil {
-			http.Error(rw, "version parameter must be an integer", http.StatusBadRequest)
+			writeError(rw, errors.New("version parameter must be an integer"), http.StatusBadRequest)
 			return
 		}
 		loadReq.Version = int64(versionInt)
@@ -98,7 +108,7 @@ func (c *ArtifactsAPIController) LoadArtifactHandler(rw http.ResponseWriter, req
 
 	resp, err := c.artifactService.Load(req.Context(), loadReq)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(resp.Part, http.StatusOK, rw)
@@ -109,28 +119,32 @@ func (c *ArtifactsAPIController) LoadArtifactVersionHandler(rw http.ResponseWrit
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	artifactName := vars["artifact_name"]
 	if artifactName == "" {
-		http.Error(rw, "artifact_name parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("artifact_name parameter is required"), http.StatusBadRequest)
 		return
 	}
 	version := vars["version"]
 
 	if version == "" {
-		http.Error(rw, "version parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("version parameter is required"), http.StatusBadRequest)
 		return
 	}
 
 	versionInt, err := strconv.Atoi(version)
 	if err != nil {
-		http.Error(rw, "version parameter must be an integer", http.StatusBadRequest)
+		writeError(rw, errors.New("version parameter must be an integer"), http.StatusBadRequest)
 		return
 	}
 
@@ -144,7 +158,7 @@ func (c *ArtifactsAPIController) LoadArtifactVersionHandler(rw http.ResponseWrit
 
 	resp, err := c.artifactService.Load(req.Context(), loadReq)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(resp.Part, http.StatusOK, rw)
@@ -155,16 +169,20 @@ func (c *ArtifactsAPIController) DeleteArtifactHandler(rw http.ResponseWriter, r
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationWrite); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	artifactName := vars["artifact_name"]
 	if artifactName == "" {
-		http.Error(rw, "artifact_name parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("artifact_name parameter is required"), http.StatusBadRequest)
 		return
 	}
 	err = c.artifactService.Delete(req.Context(), &artifact.DeleteRequest{
@@ -174,7 +192,7 @@ func (c *ArtifactsAPIController) DeleteArtifactHandler(rw http.ResponseWriter, r
 		FileName:  artifactName,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(nil, http.StatusOK, rw)
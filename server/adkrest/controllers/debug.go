@@ -15,13 +15,14 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
-	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/server/adkrest/auth"
 	"google.golang.org/adk/server/adkrest/internal/models"
 	"google.golang.org/adk/server/adkrest/internal/services"
 	"google.golang.org/adk/session"
@@ -48,24 +49,79 @@ func (c *DebugAPIController) TraceDictHandler(rw http.ResponseWriter, req *http.
 	params := mux.Vars(req)
 	eventID := params["event_id"]
 	if eventID == "" {
-		http.Error(rw, "event_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("event_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, "", "", auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	traceDict := c.spansExporter.GetTraceDict()
 	eventDict, ok := traceDict[eventID]
 	if !ok {
-		http.Error(rw, fmt.Sprintf("event not found: %s", eventID), http.StatusNotFound)
+		writeError(rw, fmt.Errorf("event not found: %s", eventID), http.StatusNotFound)
 		return
 	}
 	EncodeJSONResponse(eventDict, http.StatusOK, rw)
 }
 
+// SessionTraceHandler returns the spans of all the invocations of the
+// session, ordered by start time, as a JSON list of models.Span.
+//
+// GET /debug/trace/session/{session_id}
+//
+// The spans include the invocation, agent runs, model calls and tool calls.
+// Their invocation_id field correlates them with the events of the session.
+// The list is empty if no spans were recorded for the session.
+func (c *DebugAPIController) SessionTraceHandler(rw http.ResponseWriter, req *http.Request) {
+	sessionID := mux.Vars(req)["session_id"]
+	if sessionID == "" {
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, "", "", auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
+		return
+	}
+	EncodeJSONResponse(c.spansExporter.SessionSpans(sessionID), http.StatusOK, rw)
+}
+
+// InvocationTraceHandler returns the spans of the invocation, ordered by
+// start time, as a JSON list of models.Span.
+//
+// GET /debug/trace/invocation/{invocation_id}
+//
+// The spans of invocations nested in the invocation, e.g. by agent tools,
+// are included. It responds with 404 if no spans were recorded for the
+// invocation.
+func (c *DebugAPIController) InvocationTraceHandler(rw http.ResponseWriter, req *http.Request) {
+	invocationID := mux.Vars(req)["invocation_id"]
+	if invocationID == "" {
+		writeError(rw, errors.New("invocation_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, "", "", auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
+		return
+	}
+	spans, ok := c.spansExporter.InvocationSpans(invocationID)
+	if !ok {
+		writeError(rw, fmt.Errorf("invocation not found: %s", invocationID), http.StatusNotFound)
+		return
+	}
+	EncodeJSONResponse(spans, http.StatusOK, rw)
+}
+
 // EventGraphHandler returns the debug information for the session and session events in form of graph.
 func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
@@ -74,12 +130,12 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	eventID := vars["event_id"]
 	if eventID == "" {
-		http.Error(rw, "event_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("event_id parameter is required"), http.StatusBadRequest)
 		return
 	}
 
@@ -92,13 +148,13 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 	}
 
 	if event == nil {
-		http.Error(rw, "event not found", http.StatusNotFound)
+		writeError(rw, errors.New("event not found"), http.StatusNotFound)
 		return
 	}
 
 	highlightedPairs := [][]string{}
-	fc := functionalCalls(event)
-	fr := functionalResponses(event)
+	fc := event.FunctionCalls()
+	fr := event.FunctionResponses()
 
 	if len(fc) > 0 {
 		for _, f := range fc {
@@ -118,39 +174,13 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 
 	agent, err := c.agentloader.LoadAgent(sessionID.AppName)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	graph, err := services.GetAgentGraph(req.Context(), agent, highlightedPairs)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(map[string]string{"dotSrc": graph}, http.StatusOK, rw)
 }
-
-func functionalCalls(event *session.Event) []*genai.FunctionCall {
-	if event.LLMResponse.Content == nil || event.LLMResponse.Content.Parts == nil {
-		return nil
-	}
-	fc := []*genai.FunctionCall{}
-	for _, part := range event.LLMResponse.Content.Parts {
-		if part.FunctionCall != nil {
-			fc = append(fc, part.FunctionCall)
-		}
-	}
-	return fc
-}
-
-func functionalResponses(event *session.Event) []*genai.FunctionResponse {
-	if event.LLMResponse.Content == nil || event.LLMResponse.Content.Parts == nil {
-		return nil
-	}
-	fr := []*genai.FunctionResponse{}
-	for _, part := range event.LLMResponse.Content.Parts {
-		if part.FunctionResponse != nil {
-			fr = append(fr, part.FunctionResponse)
-		}
-	}
-	return fr
-}
@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/internal/services"
+)
+
+func TestTraceHandlers(t *testing.T) {
+	exporter := services.NewAPIServerSpanExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test-tracer")
+	ctx, invSpan := tracer.Start(t.Context(), "invocation", trace.WithAttributes(
+		attribute.String("gcp.vertex.agent.session_id", "session"),
+		attribute.String("gcp.vertex.agent.invocation_id", "invocation"),
+	))
+	_, llmSpan := tracer.Start(ctx, "call_llm")
+	llmSpan.End()
+	invSpan.End()
+	controller := controllers.NewDebugAPIController(nil, nil, exporter)
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		vars      map[string]string
+		wantCode  int
+		wantSpans int
+	}{
+		{"session", controller.SessionTraceHandler, map[string]string{"session_id": "session"}, http.StatusOK, 2},
+		{"unknown_session", controller.SessionTraceHandler, map[string]string{"session_id": "unknown"}, http.StatusOK, 0},
+		{"invocation", controller.InvocationTraceHandler, map[string]string{"invocation_id": "invocation"}, http.StatusOK, 2},
+		{"unknown_invocation", controller.InvocationTraceHandler, map[string]string{"invocation_id": "unknown"}, http.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/debug/trace", nil), tt.vars)
+			rr := httptest.NewRecorder()
+
+			tt.handler(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantCode, rr.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var spans []models.Span
+			if err := json.Unmarshal(rr.Body.Bytes(), &spans); err != nil {
+				t.Fatalf("invalid response %s: %v", rr.Body, err)
+			}
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("got %d spans, want %d", len(spans), tt.wantSpans)
+			}
+			for _, span := range spans {
+				if span.InvocationID != "invocation" {
+					t.Errorf("span %q invocation_id = %q, want %q", span.Name, span.InvocationID, "invocation")
+				}
+			}
+		})
+	}
+}
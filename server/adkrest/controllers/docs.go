@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"io"
+	"net/http"
+)
+
+// DocsAPIController is the controller for the API documentation.
+type DocsAPIController struct {
+	spec any
+}
+
+// NewDocsAPIController creates a controller serving the OpenAPI document spec.
+func NewDocsAPIController(spec any) *DocsAPIController {
+	return &DocsAPIController{spec: spec}
+}
+
+// OpenAPIHandler returns the OpenAPI document of the API.
+func (c *DocsAPIController) OpenAPIHandler(rw http.ResponseWriter, req *http.Request) {
+	EncodeJSONResponse(c.spec, http.StatusOK, rw)
+}
+
+// swaggerUIPage is the Swagger UI page rendering the OpenAPI document served
+// next to it. The Swagger UI assets are loaded from a CDN.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>ADK REST API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+// SwaggerUIHandler returns a Swagger UI page documenting the API.
+func (c *DocsAPIController) SwaggerUIHandler(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(rw, swaggerUIPage)
+}
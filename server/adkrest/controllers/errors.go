@@ -14,6 +14,18 @@
 
 package controllers
 
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/server/adkrest/auth"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
 type statusError struct {
 	Err  error
 	Code int
@@ -28,7 +40,62 @@ func (se statusError) Error() string {
 	return se.Err.Error()
 }
 
+// Unwrap returns the associated error
+func (se statusError) Unwrap() error {
+	return se.Err
+}
+
 // Status returns an associated status code
 func (se statusError) Status() int {
 	return se.Code
 }
+
+// authorize checks that the principal of the request may perform the
+// operation on the data of the user, see [auth.Authorize]. It must be called
+// before the services are accessed.
+func authorize(req *http.Request, appName, userID string, op auth.Operation) error {
+	if err := auth.Authorize(req.Context(), appName, userID, op); err != nil {
+		return newStatusError(err, http.StatusForbidden)
+	}
+	return nil
+}
+
+// apiError returns the HTTP status code and the body of the error response
+// for err. The status code of a statusError takes precedence over the given
+// one, and well-known errors, e.g. a missing session, are mapped to their
+// own status codes.
+func apiError(err error, status int) (int, models.ErrorResponse) {
+	var statusErr statusError
+	if errors.As(err, &statusErr) {
+		status = statusErr.Code
+	}
+	var details map[string]any
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, session.ErrSessionNotFound), errors.Is(err, agent.ErrAgentNotFound), errors.Is(err, eval.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, eval.ErrAlreadyExists):
+		status = http.StatusConflict
+	case errors.Is(err, eval.ErrInvalidID):
+		status = http.StatusBadRequest
+	case errors.As(err, &syntaxErr):
+		status = http.StatusBadRequest
+		details = map[string]any{"offset": syntaxErr.Offset}
+	case errors.As(err, &typeErr):
+		status = http.StatusBadRequest
+		details = map[string]any{"field": typeErr.Field, "type": typeErr.Type.String()}
+	}
+	return status, models.ErrorResponse{Error: models.Error{
+		Code:    models.ErrorCodeFromStatus(status),
+		Message: err.Error(),
+		Details: details,
+	}}
+}
+
+// writeError writes err as a JSON models.ErrorResponse, with the given status
+// code unless err carries its own, see apiError.
+func writeError(rw http.ResponseWriter, err error, status int) {
+	status, resp := apiError(err, status)
+	EncodeJSONResponse(resp, status, rw)
+}
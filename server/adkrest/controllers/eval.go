@@ -0,0 +1,375 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/server/adkrest/auth"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// EvalAPIController is the controller for the Eval API. Eval sets and
+// results are shared by the users of an app, their accesses are authorized
+// without a user.
+type EvalAPIController struct {
+	evalService    eval.Service
+	sessionService session.Service
+	agentLoader    agent.Loader
+}
+
+// NewEvalAPIController creates the controller for the Eval API.
+func NewEvalAPIController(evalService eval.Service, sessionService session.Service, agentLoader agent.Loader) *EvalAPIController {
+	return &EvalAPIController{
+		evalService:    evalService,
+		sessionService: sessionService,
+		agentLoader:    agentLoader,
+	}
+}
+
+// evalParams returns the app name and the eval set id path parameters,
+// after checking that the request may perform the operation on the eval
+// sets of the app.
+func evalParams(req *http.Request, op auth.Operation) (appName, setID string, err error) {
+	vars := mux.Vars(req)
+	appName = vars["app_name"]
+	if appName == "" {
+		return "", "", newStatusError(errors.New("app_name parameter is required"), http.StatusBadRequest)
+	}
+	if err := authorize(req, appName, "", op); err != nil {
+		return "", "", err
+	}
+	return appName, vars["eval_set_id"], nil
+}
+
+// decodeJSONBody decodes the JSON body of the request into v. An empty body
+// leaves v unchanged if optional is true.
+func decodeJSONBody(req *http.Request, v any, optional bool) error {
+	defer req.Body.Close()
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		if optional && errors.Is(err, io.EOF) {
+			return nil
+		}
+		return newStatusError(fmt.Errorf("decode request: %w", err), http.StatusBadRequest)
+	}
+	return nil
+}
+
+// ListEvalSetsHandler lists the ids of the eval sets of an app.
+func (c *EvalAPIController) ListEvalSetsHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, _, err := evalParams(req, auth.OperationRead)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	ids, err := c.evalService.ListSets(req.Context(), appName)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(ids, http.StatusOK, rw)
+}
+
+// CreateEvalSetHandler creates an eval set. The optional body sets its name,
+// description and cases.
+func (c *EvalAPIController) CreateEvalSetHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationWrite)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	set := &eval.Set{}
+	if err := decodeJSONBody(req, set, true); err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if set.ID != "" && set.ID != setID {
+		writeError(rw, fmt.Errorf("evalSetId %q doesn't match the eval_set_id parameter %q", set.ID, setID), http.StatusBadRequest)
+		return
+	}
+	set.ID = setID
+	if set.Cases == nil {
+		set.Cases = []*eval.Case{}
+	}
+	if err := c.evalService.CreateSet(req.Context(), appName, set); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(set, http.StatusOK, rw)
+}
+
+// GetEvalSetHandler returns an eval set with its cases.
+func (c *EvalAPIController) GetEvalSetHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationRead)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	set, err := c.evalService.GetSet(req.Context(), appName, setID)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(set, http.StatusOK, rw)
+}
+
+// DeleteEvalSetHandler deletes an eval set with its cases.
+func (c *EvalAPIController) DeleteEvalSetHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationWrite)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if err := c.evalService.DeleteSet(req.Context(), appName, setID); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(nil, http.StatusOK, rw)
+}
+
+// AddSessionHandler records the conversation of a session as a new eval
+// case of an eval set, see [eval.CaseFromSession].
+func (c *EvalAPIController) AddSessionHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationWrite)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	var addReq models.AddSessionToEvalSetRequest
+	if err := decodeJSONBody(req, &addReq, false); err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if addReq.EvalID == "" || addReq.SessionID == "" || addReq.UserID == "" {
+		writeError(rw, errors.New("evalId, sessionId and userId are required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, appName, addReq.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
+		return
+	}
+	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
+		AppName:   appName,
+		UserID:    addReq.UserID,
+		SessionID: addReq.SessionID,
+	})
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	evalCase := eval.CaseFromSession(addReq.EvalID, resp.Session)
+	if err := c.evalService.AddCase(req.Context(), appName, setID, evalCase); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(evalCase, http.StatusOK, rw)
+}
+
+// ListEvalsHandler lists the ids of the eval cases of an eval set.
+func (c *EvalAPIController) ListEvalsHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationRead)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	set, err := c.evalService.GetSet(req.Context(), appName, setID)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	ids := make([]string, 0, len(set.Cases))
+	for _, c := range set.Cases {
+		ids = append(ids, c.ID)
+	}
+	EncodeJSONResponse(ids, http.StatusOK, rw)
+}
+
+// CreateEvalHandler adds an eval case to an eval set.
+func (c *EvalAPIController) CreateEvalHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationWrite)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	evalCase := &eval.Case{}
+	if err := decodeJSONBody(req, evalCase, false); err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if evalCase.ID == "" {
+		writeError(rw, errors.New("evalId is required"), http.StatusBadRequest)
+		return
+	}
+	if err := c.evalService.AddCase(req.Context(), appName, setID, evalCase); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(evalCase, http.StatusOK, rw)
+}
+
+// GetEvalHandler returns an eval case of an eval set.
+func (c *EvalAPIController) GetEvalHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationRead)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	caseID := mux.Vars(req)["eval_case_id"]
+	set, err := c.evalService.GetSet(req.Context(), appName, setID)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	evalCase := set.Case(caseID)
+	if evalCase == nil {
+		writeError(rw, fmt.Errorf("eval case %q in eval set %q: %w", caseID, setID, eval.ErrNotFound), http.StatusNotFound)
+		return
+	}
+	EncodeJSONResponse(evalCase, http.StatusOK, rw)
+}
+
+// UpdateEvalHandler replaces an eval case of an eval set.
+func (c *EvalAPIController) UpdateEvalHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationWrite)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	caseID := mux.Vars(req)["eval_case_id"]
+	evalCase := &eval.Case{}
+	if err := decodeJSONBody(req, evalCase, false); err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if evalCase.ID != "" && evalCase.ID != caseID {
+		writeError(rw, fmt.Errorf("evalId %q doesn't match the eval_case_id parameter %q", evalCase.ID, caseID), http.StatusBadRequest)
+		return
+	}
+	evalCase.ID = caseID
+	if err := c.evalService.UpdateCase(req.Context(), appName, setID, evalCase); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(evalCase, http.StatusOK, rw)
+}
+
+// DeleteEvalHandler deletes an eval case of an eval set.
+func (c *EvalAPIController) DeleteEvalHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationWrite)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if err := c.evalService.DeleteCase(req.Context(), appName, setID, mux.Vars(req)["eval_case_id"]); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(nil, http.StatusOK, rw)
+}
+
+// RunEvalHandler replays eval cases of an eval set through the agent of the
+// app, see [eval.Run]. It saves the result of the evaluation, and returns the
+// results of the cases.
+func (c *EvalAPIController) RunEvalHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, setID, err := evalParams(req, auth.OperationRun)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	var runReq models.RunEvalRequest
+	if err := decodeJSONBody(req, &runReq, true); err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	set, err := c.evalService.GetSet(req.Context(), appName, setID)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	cases := set.Cases
+	if len(runReq.EvalIDs) > 0 {
+		cases = make([]*eval.Case, 0, len(runReq.EvalIDs))
+		for _, id := range runReq.EvalIDs {
+			evalCase := set.Case(id)
+			if evalCase == nil {
+				writeError(rw, fmt.Errorf("eval case %q in eval set %q: %w", id, setID, eval.ErrNotFound), http.StatusNotFound)
+				return
+			}
+			cases = append(cases, evalCase)
+		}
+	}
+	rootAgent, err := c.agentLoader.LoadAgent(appName)
+	if err != nil {
+		writeError(rw, fmt.Errorf("load agent: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	results, err := eval.Run(req.Context(), eval.Config{
+		AppName: appName,
+		Agent:   rootAgent,
+		SetID:   setID,
+		Metrics: runReq.EvalMetrics,
+	}, cases)
+	if err != nil {
+		// The configuration comes from the request.
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if err := c.evalService.SaveResult(req.Context(), appName, eval.NewSetResult(appName, setID, results)); err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(results, http.StatusOK, rw)
+}
+
+// ListEvalResultsHandler lists the ids of the saved evaluation results of an
+// app.
+func (c *EvalAPIController) ListEvalResultsHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, _, err := evalParams(req, auth.OperationRead)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	ids, err := c.evalService.ListResults(req.Context(), appName)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(ids, http.StatusOK, rw)
+}
+
+// GetEvalResultHandler returns a saved evaluation result.
+func (c *EvalAPIController) GetEvalResultHandler(rw http.ResponseWriter, req *http.Request) {
+	appName, _, err := evalParams(req, auth.OperationRead)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	result, err := c.evalService.GetResult(req.Context(), appName, mux.Vars(req)["eval_result_id"])
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(result, http.StatusOK, rw)
+}
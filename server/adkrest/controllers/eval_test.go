@@ -0,0 +1,208 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/fakes"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const evalApp = "weather_agent"
+
+// weatherAgent returns an agent answering with the responses, with a
+// weather tool.
+func weatherAgent(t *testing.T, responses ...*genai.Content) agent.Agent {
+	t.Helper()
+	type Args struct {
+		City string `json:"city"`
+	}
+	weather, err := functiontool.New(functiontool.Config{Name: "weather", Description: "returns the weather"}, func(_ tool.Context, args Args) (map[string]any, error) {
+		return map[string]any{"forecast": "sunny"}, nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  evalApp,
+		Model: &testutil.MockModel{Responses: responses},
+		Tools: []tool.Tool{weather},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	return a
+}
+
+func weatherCall(city string) *genai.Content {
+	return &genai.Content{
+		Role:  genai.RoleModel,
+		Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "weather", Args: map[string]any{"city": city}}}},
+	}
+}
+
+func newTestEvent(invocationID, author string, content *genai.Content) *session.Event {
+	event := session.NewEvent(invocationID)
+	event.Author = author
+	event.LLMResponse = model.LLMResponse{Content: content}
+	return event
+}
+
+// serveEval calls the handler with the path parameters and JSON body, and
+// decodes the JSON response into resp.
+func serveEval(t *testing.T, handler http.HandlerFunc, vars map[string]string, body, resp any) int {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatalf("encode request: %v", err)
+		}
+	}
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", &reqBody), vars)
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	if resp != nil && rr.Code == http.StatusOK {
+		if err := json.NewDecoder(rr.Body).Decode(resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return rr.Code
+}
+
+func TestEvalAPIController(t *testing.T) {
+	id := fakes.SessionKey{AppName: evalApp, UserID: "testUser", SessionID: "testSession"}
+	sessionService := &fakes.FakeSessionService{Sessions: map[fakes.SessionKey]fakes.TestSession{
+		id: {
+			Id:           id,
+			SessionState: fakes.TestState{},
+			SessionEvents: fakes.TestEvents{
+				newTestEvent("inv1", "user", genai.NewContentFromText("what is the weather in Paris?", genai.RoleUser)),
+				newTestEvent("inv1", evalApp, weatherCall("Paris")),
+				newTestEvent("inv1", evalApp, &genai.Content{
+					Role:  genai.RoleUser,
+					Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{Name: "weather", Response: map[string]any{"forecast": "sunny"}}}},
+				}),
+				newTestEvent("inv1", evalApp, genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel)),
+			},
+			UpdatedAt: time.Now(),
+		},
+	}}
+	// The agent answers the replayed conversation the same way.
+	testAgent := weatherAgent(t, weatherCall("Paris"), genai.NewContentFromText("It's sunny in Paris.", genai.RoleModel))
+	evalService := eval.InMemoryService()
+	controller := controllers.NewEvalAPIController(evalService, sessionService, agent.NewSingleLoader(testAgent))
+	setVars := map[string]string{"app_name": evalApp, "eval_set_id": "set"}
+
+	var set eval.Set
+	if code := serveEval(t, controller.CreateEvalSetHandler, setVars, nil, &set); code != http.StatusOK {
+		t.Fatalf("CreateEvalSetHandler() status = %d, want 200", code)
+	}
+	if set.ID != "set" {
+		t.Errorf("created eval set id = %q, want %q", set.ID, "set")
+	}
+	if code := serveEval(t, controller.CreateEvalSetHandler, setVars, nil, nil); code != http.StatusConflict {
+		t.Errorf("CreateEvalSetHandler() of an existing set status = %d, want 409", code)
+	}
+
+	var evalCase eval.Case
+	addReq := models.AddSessionToEvalSetRequest{EvalID: "case", SessionID: "testSession", UserID: "testUser"}
+	if code := serveEval(t, controller.AddSessionHandler, setVars, addReq, &evalCase); code != http.StatusOK {
+		t.Fatalf("AddSessionHandler() status = %d, want 200", code)
+	}
+	if len(evalCase.Conversation) != 1 || len(evalCase.Conversation[0].IntermediateData.ToolUses) != 1 {
+		t.Fatalf("recorded eval case = %+v, want one invocation with a tool call", evalCase)
+	}
+	missingReq := models.AddSessionToEvalSetRequest{EvalID: "other", SessionID: "missing", UserID: "testUser"}
+	if code := serveEval(t, controller.AddSessionHandler, setVars, missingReq, nil); code != http.StatusNotFound {
+		t.Errorf("AddSessionHandler() of a missing session status = %d, want 404", code)
+	}
+
+	var ids []string
+	if code := serveEval(t, controller.ListEvalsHandler, setVars, nil, &ids); code != http.StatusOK || !cmp.Equal(ids, []string{"case"}) {
+		t.Errorf("ListEvalsHandler() = %d, %v, want [case]", code, ids)
+	}
+
+	var results []*eval.CaseResult
+	if code := serveEval(t, controller.RunEvalHandler, setVars, models.RunEvalRequest{}, &results); code != http.StatusOK {
+		t.Fatalf("RunEvalHandler() status = %d, want 200", code)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d case results, want 1", len(results))
+	}
+	if got := results[0]; got.CaseID != "case" || got.FinalStatus != eval.StatusPassed || got.Error != "" {
+		t.Errorf("case result = %+v, want passed", got)
+	}
+	for _, m := range results[0].OverallMetricResults {
+		if m.Score == nil || *m.Score != 1 {
+			t.Errorf("metric %q score = %v, want 1", m.MetricName, m.Score)
+		}
+	}
+
+	var resultIDs []string
+	appVars := map[string]string{"app_name": evalApp}
+	if code := serveEval(t, controller.ListEvalResultsHandler, appVars, nil, &resultIDs); code != http.StatusOK || len(resultIDs) != 1 {
+		t.Fatalf("ListEvalResultsHandler() = %d, %v, want 1 result", code, resultIDs)
+	}
+	var saved eval.SetResult
+	resultVars := map[string]string{"app_name": evalApp, "eval_result_id": resultIDs[0]}
+	if code := serveEval(t, controller.GetEvalResultHandler, resultVars, nil, &saved); code != http.StatusOK {
+		t.Fatalf("GetEvalResultHandler() status = %d, want 200", code)
+	}
+	if saved.SetID != "set" || len(saved.CaseResults) != 1 || saved.CaseResults[0].FinalStatus != eval.StatusPassed {
+		t.Errorf("saved result = %+v, want the passed case of the set", saved)
+	}
+
+	unknownCase := models.RunEvalRequest{EvalIDs: []string{"missing"}}
+	if code := serveEval(t, controller.RunEvalHandler, setVars, unknownCase, nil); code != http.StatusNotFound {
+		t.Errorf("RunEvalHandler() of an unknown case status = %d, want 404", code)
+	}
+	unknownMetric := models.RunEvalRequest{EvalMetrics: []eval.Metric{{Name: "unknown"}}}
+	if code := serveEval(t, controller.RunEvalHandler, setVars, unknownMetric, nil); code != http.StatusBadRequest {
+		t.Errorf("RunEvalHandler() with an unknown metric status = %d, want 400", code)
+	}
+
+	caseVars := map[string]string{"app_name": evalApp, "eval_set_id": "set", "eval_case_id": "case"}
+	if code := serveEval(t, controller.DeleteEvalHandler, caseVars, nil, nil); code != http.StatusOK {
+		t.Errorf("DeleteEvalHandler() status = %d, want 200", code)
+	}
+	if code := serveEval(t, controller.GetEvalHandler, caseVars, nil, nil); code != http.StatusNotFound {
+		t.Errorf("GetEvalHandler() of a deleted case status = %d, want 404", code)
+	}
+	if code := serveEval(t, controller.DeleteEvalSetHandler, setVars, nil, nil); code != http.StatusOK {
+		t.Errorf("DeleteEvalSetHandler() status = %d, want 200", code)
+	}
+	if code := serveEval(t, controller.GetEvalSetHandler, setVars, nil, nil); code != http.StatusNotFound {
+		t.Errorf("GetEvalSetHandler() of a deleted set status = %d, want 404", code)
+	}
+}
@@ -17,6 +17,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
@@ -39,21 +40,18 @@ func EncodeJSONResponse(i any, status int, w http.ResponseWriter) {
 
 type errorHandler func(http.ResponseWriter, *http.Request) error
 
-// NewErrorHandler writes the error code returned from the http handler.
+// NewErrorHandler writes the error returned from the http handler as a JSON
+// error response.
 func NewErrorHandler(fn errorHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		err := fn(w, r)
 		if err != nil {
-			if statusErr, ok := err.(statusError); ok {
-				http.Error(w, statusErr.Error(), statusErr.Status())
-			} else {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
+			writeError(w, err, http.StatusInternalServerError)
 		}
 	}
 }
 
 // Unimplemented returns 501 - Status Not Implemented error
 func Unimplemented(rw http.ResponseWriter, req *http.Request) {
-	rw.WriteHeader(http.StatusNotImplemented)
+	writeError(rw, errors.New("not implemented"), http.StatusNotImplemented)
 }
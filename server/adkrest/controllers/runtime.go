@@ -17,12 +17,18 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"sync"
+	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/auth"
 	"google.golang.org/adk/server/adkrest/internal/models"
 	"google.golang.org/adk/session"
 )
@@ -32,11 +38,39 @@ type RuntimeAPIController struct {
 	sessionService  session.Service
 	artifactService artifact.Service
 	agentLoader     agent.Loader
+
+	// SSEKeepAliveInterval is the interval of the ": ping" comments written
+	// to SSE streams, keeping proxies from dropping the connections of long
+	// agent runs. Zero disables them. It defaults to
+	// [DefaultSSEKeepAliveInterval].
+	SSEKeepAliveInterval time.Duration
+
+	mu      sync.Mutex
+	runners map[string]*runner.Runner // by app name
+	closed  bool
+
+	// shutdown is canceled with errShutdown to interrupt the active runs
+	// when they don't finish in time during Close.
+	shutdown      context.Context
+	interruptRuns context.CancelCauseFunc
 }
 
+// errShutdown interrupts the active runs when the server shuts down.
+var errShutdown = errors.New("server is shutting down")
+
 // NewRuntimeAPIController creates the controller for the Runtime API.
 func NewRuntimeAPIController(sessionService session.Service, agentLoader agent.Loader, artifactService artifact.Service) *RuntimeAPIController {
-	return &RuntimeAPIController{sessionService: sessionService, agentLoader: agentLoader, artifactService: artifactService}
+	shutdown, interruptRuns := context.WithCancelCause(context.Background())
+	return &RuntimeAPIController{
+		sessionService:  sessionService,
+		agentLoader:     agentLoader,
+		artifactService: artifactService,
+
+		SSEKeepAliveInterval: DefaultSSEKeepAliveInterval,
+
+		shutdown:      shutdown,
+		interruptRuns: interruptRuns,
+	}
 }
 
 // RunAgent executes a non-streaming agent run for a given session and message.
@@ -45,6 +79,9 @@ func (c *RuntimeAPIController) RunHandler(rw http.ResponseWriter, req *http.Requ
 	if err != nil {
 		return err
 	}
+	if runAgentRequest.UserId, err = effectiveUserID(req, runAgentRequest.AppName, runAgentRequest.UserId); err != nil {
+		return err
+	}
 	sessionEvents, err := c.runAgent(req.Context(), runAgentRequest)
 	if err != nil {
 		return err
@@ -69,10 +106,15 @@ func (c *RuntimeAPIController) runAgent(ctx context.Context, runAgentRequest mod
 		return nil, err
 	}
 
+	ctx, cancel := c.runContext(ctx)
+	defer cancel()
 	resp := r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg)
 
 	var events []*session.Event
 	for event, err := range resp {
+		if errors.Is(err, runner.ErrClosed) {
+			return nil, newStatusError(fmt.Errorf("run agent: %w", err), http.StatusServiceUnavailable)
+		}
 		if err != nil {
 			return nil, newStatusError(fmt.Errorf("run agent: %w", err), http.StatusInternalServerError)
 		}
@@ -88,6 +130,11 @@ func (c *RuntimeAPIController) RunSSEHandler(rw http.ResponseWriter, req *http.R
 		return newStatusError(fmt.Errorf("streaming not supported"), http.StatusInternalServerError)
 	}
 
+	// Agent runs may stream for much longer than the server write timeout
+	// allows, so it is lifted for this response. Errors are ignored, as not
+	// all response writers support deadlines.
+	_ = http.NewResponseController(rw).SetWriteDeadline(time.Time{})
+
 	rw.Header().Set("Content-Type", "text/event-stream")
 	rw.Header().Set("Cache-Control", "no-cache")
 	rw.Header().Set("Connection", "keep-alive")
@@ -96,6 +143,9 @@ func (c *RuntimeAPIController) RunSSEHandler(rw http.ResponseWriter, req *http.R
 	if err != nil {
 		return err
 	}
+	if runAgentRequest.UserId, err = effectiveUserID(req, runAgentRequest.AppName, runAgentRequest.UserId); err != nil {
+		return err
+	}
 
 	err = c.validateSessionExists(req.Context(), runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId)
 	if err != nil {
@@ -107,26 +157,55 @@ func (c *RuntimeAPIController) RunSSEHandler(rw http.ResponseWriter, req *http.R
 		return err
 	}
 
-	resp := r.Run(req.Context(), runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg)
+	ctx, cancel := c.runContext(req.Context())
+	defer cancel()
+	resp := r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg)
 
 	rw.WriteHeader(http.StatusOK)
+	stream := &sseStream{rw: rw, flusher: flusher}
+	stopKeepAlive := stream.keepAlive(c.SSEKeepAliveInterval)
+	defer stopKeepAlive()
 	for event, err := range resp {
 		if err != nil {
-			_, err := fmt.Fprintf(rw, "Error while running agent: %v\n", err)
-			if err != nil {
-				return newStatusError(fmt.Errorf("write response: %w", err), http.StatusInternalServerError)
+			if errors.Is(context.Cause(ctx), errShutdown) {
+				break
 			}
-			flusher.Flush()
-			continue
+			// The run failed, end the stream with an error event.
+			return stream.write(func(rw http.ResponseWriter, flusher http.Flusher) error {
+				return flashError(flusher, rw, fmt.Errorf("run agent: %w", err))
+			})
 		}
-		err := flashEvent(flusher, rw, *event)
+		err := stream.write(func(rw http.ResponseWriter, flusher http.Flusher) error {
+			return flashEvent(flusher, rw, *event)
+		})
 		if err != nil {
 			return err
 		}
 	}
+	if errors.Is(context.Cause(ctx), errShutdown) {
+		// Let the client tell an interrupted stream from a completed one.
+		return stream.write(func(rw http.ResponseWriter, flusher http.Flusher) error {
+			if _, err := fmt.Fprintf(rw, "event: shutdown\ndata: %s\n\n", errShutdown); err != nil {
+				return newStatusError(fmt.Errorf("write response: %w", err), http.StatusInternalServerError)
+			}
+			flusher.Flush()
+			return nil
+		})
+	}
 	return nil
 }
 
+// runContext returns the context of an agent run, canceled when the run
+// is interrupted by Close.
+func (c *RuntimeAPIController) runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(c.shutdown, func() { cancel(context.Cause(c.shutdown)) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
 func flashEvent(flusher http.Flusher, rw http.ResponseWriter, event session.Event) error {
 	_, err := fmt.Fprintf(rw, "data: ")
 	if err != nil {
@@ -144,6 +223,21 @@ func flashEvent(flusher http.Flusher, rw http.ResponseWriter, event session.Even
 	return nil
 }
 
+// flashError writes err as an SSE error event, with a JSON
+// models.ErrorResponse as data.
+func flashError(flusher http.Flusher, rw http.ResponseWriter, err error) error {
+	_, resp := apiError(err, http.StatusInternalServerError)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return newStatusError(fmt.Errorf("encode response: %w", err), http.StatusInternalServerError)
+	}
+	if _, err := fmt.Fprintf(rw, "event: error\ndata: %s\n\n", data); err != nil {
+		return newStatusError(fmt.Errorf("write response: %w", err), http.StatusInternalServerError)
+	}
+	flusher.Flush()
+	return nil
+}
+
 func (c *RuntimeAPIController) validateSessionExists(ctx context.Context, appName, userID, sessionID string) error {
 	_, err := c.sessionService.Get(ctx, &session.GetRequest{
 		AppName:   appName,
@@ -157,20 +251,32 @@ func (c *RuntimeAPIController) validateSessionExists(ctx context.Context, appNam
 }
 
 func (c *RuntimeAPIController) getRunner(req models.RunAgentRequest) (*runner.Runner, *agent.RunConfig, error) {
-	curAgent, err := c.agentLoader.LoadAgent(req.AppName)
-	if err != nil {
-		return nil, nil, newStatusError(fmt.Errorf("load agent: %w", err), http.StatusInternalServerError)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, nil, newStatusError(fmt.Errorf("create runner: %w", runner.ErrClosed), http.StatusServiceUnavailable)
 	}
+	r, ok := c.runners[req.AppName]
+	if !ok {
+		curAgent, err := c.agentLoader.LoadAgent(req.AppName)
+		if err != nil {
+			return nil, nil, newStatusError(fmt.Errorf("load agent: %w", err), http.StatusInternalServerError)
+		}
 
-	r, err := runner.New(runner.Config{
-		AppName:         req.AppName,
-		Agent:           curAgent,
-		SessionService:  c.sessionService,
-		ArtifactService: c.artifactService,
-	},
-	)
-	if err != nil {
-		return nil, nil, newStatusError(fmt.Errorf("create runner: %w", err), http.StatusInternalServerError)
+		r, err = runner.New(runner.Config{
+			AppName:         req.AppName,
+			Agent:           curAgent,
+			SessionService:  c.sessionService,
+			ArtifactService: c.artifactService,
+		},
+		)
+		if err != nil {
+			return nil, nil, newStatusError(fmt.Errorf("create runner: %w", err), http.StatusInternalServerError)
+		}
+		if c.runners == nil {
+			c.runners = make(map[string]*runner.Runner)
+		}
+		c.runners[req.AppName] = r
 	}
 
 	streamingMode := agent.StreamingModeNone
@@ -182,10 +288,45 @@ func (c *RuntimeAPIController) getRunner(req models.RunAgentRequest) (*runner.Ru
 	}, nil
 }
 
+// Close stops the runners from accepting new runs and waits until the active
+// runs finish or ctx is done. See [runner.Runner.Close]. The runs still active
+// when ctx is done are interrupted, the streamed ones end with a shutdown
+// event.
+func (c *RuntimeAPIController) Close(ctx context.Context) error {
+	c.mu.Lock()
+	c.closed = true
+	runners := slices.Collect(maps.Values(c.runners))
+	c.mu.Unlock()
+
+	var errs []error
+	for _, r := range runners {
+		if err := r.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		c.interruptRuns(errShutdown)
+	}
+	return errors.Join(errs...)
+}
+
+// effectiveUserID returns the user id to run the agent for, taking the
+// authenticated subject of the request into account, after checking that
+// the principal may run the agent for the user.
+func effectiveUserID(req *http.Request, appName, userID string) (string, error) {
+	userID = auth.UserID(req.Context(), userID)
+	if err := authorize(req, appName, userID, auth.OperationRun); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
 func decodeRequestBody(req *http.Request) (decodedReq models.RunAgentRequest, err error) {
 	var runAgentRequest models.RunAgentRequest
 	defer func() {
-		err = req.Body.Close()
+		if closeErr := req.Body.Close(); err == nil {
+			err = closeErr
+		}
 	}()
 	d := json.NewDecoder(req.Body)
 	d.DisallowUnknownFields()
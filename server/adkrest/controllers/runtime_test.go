@@ -0,0 +1,313 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestRuntimeAPIController_Close(t *testing.T) {
+	started := make(chan struct{})
+	// The agent runs until it is interrupted.
+	testAgent, err := agent.New(agent.Config{
+		Name: "stuck_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				close(started)
+				<-ctx.Done()
+				yield(nil, ctx.Err())
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "stuck_agent", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatal(err)
+	}
+	controller := controllers.NewRuntimeAPIController(sessionService, agent.NewSingleLoader(testAgent), nil)
+
+	newRequest := func() *http.Request {
+		body, err := json.Marshal(models.RunAgentRequest{
+			AppName:    "stuck_agent",
+			UserId:     "testUser",
+			SessionId:  "testSession",
+			NewMessage: *genai.NewContentFromText("question", genai.RoleUser),
+			Streaming:  true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return httptest.NewRequest(http.MethodPost, "/run_sse", bytes.NewReader(body))
+	}
+
+	rr := httptest.NewRecorder()
+	handlerErr := make(chan error, 1)
+	go func() { handlerErr <- controller.RunSSEHandler(rr, newRequest()) }()
+	select {
+	case <-started:
+	case err := <-handlerErr:
+		t.Fatalf("RunSSEHandler() returned before the run started: %v, body %q", err, rr.Body.String())
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := controller.Close(ctx); err == nil {
+		t.Error("Close() = nil, want an error for the interrupted run")
+	}
+	if err := <-handlerErr; err != nil {
+		t.Fatalf("RunSSEHandler() error = %v", err)
+	}
+	if body := rr.Body.String(); !strings.HasSuffix(body, "event: shutdown\ndata: server is shutting down\n\n") {
+		t.Errorf("RunSSEHandler() body = %q, want it to end with the shutdown event", body)
+	}
+
+	err = controller.RunSSEHandler(httptest.NewRecorder(), newRequest())
+	if se, ok := err.(interface{ Status() int }); !ok || se.Status() != http.StatusServiceUnavailable {
+		t.Errorf("RunSSEHandler() after Close() error = %v, want status %d", err, http.StatusServiceUnavailable)
+	}
+}
+
+func TestRuntimeAPIController_RunSSEHandler_SlowAgent(t *testing.T) {
+	// The agent pauses between its events for longer than both the server
+	// write timeout and the keep-alive interval.
+	testAgent, err := agent.New(agent.Config{
+		Name: "slow_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for _, text := range []string{"first", "second"} {
+					ev := session.NewEvent(ctx.InvocationID())
+					ev.Author = ctx.Agent().Name()
+					ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)}
+					if !yield(ev, nil) {
+						return
+					}
+					time.Sleep(200 * time.Millisecond)
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "slow_agent", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatal(err)
+	}
+	controller := controllers.NewRuntimeAPIController(sessionService, agent.NewSingleLoader(testAgent), nil)
+	controller.SSEKeepAliveInterval = 50 * time.Millisecond
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if err := controller.RunSSEHandler(rw, req); err != nil {
+			t.Errorf("RunSSEHandler() error = %v", err)
+		}
+	}))
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    "slow_agent",
+		UserId:     "testUser",
+		SessionId:  "testSession",
+		NewMessage: *genai.NewContentFromText("question", genai.RoleUser),
+		Streaming:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	stream, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading the stream failed: %v", err)
+	}
+
+	var texts []string
+	pings := 0
+	for line := range strings.Lines(string(stream)) {
+		switch {
+		case line == ": ping\n":
+			pings++
+		case strings.HasPrefix(line, "data: "):
+			var event models.Event
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				t.Fatalf("invalid event %q: %v", line, err)
+			}
+			texts = append(texts, event.Content.Parts[0].Text)
+		}
+	}
+	if diff := cmp.Diff([]string{"first", "second"}, texts); diff != "" {
+		t.Errorf("streamed events mismatch (-want +got):\n%s", diff)
+	}
+	if pings == 0 {
+		t.Errorf("stream %q has no keep-alive comments", stream)
+	}
+}
+
+func TestRuntimeAPIController_RunSSEHandler_Error(t *testing.T) {
+	testAgent, err := agent.New(agent.Config{
+		Name: "failing_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				ev := session.NewEvent(ctx.InvocationID())
+				ev.Author = ctx.Agent().Name()
+				ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("partial", genai.RoleModel)}
+				if !yield(ev, nil) {
+					return
+				}
+				if !yield(nil, errors.New("model unavailable")) {
+					return
+				}
+				t.Error("the run continued after the error")
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "failing_agent", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatal(err)
+	}
+	controller := controllers.NewRuntimeAPIController(sessionService, agent.NewSingleLoader(testAgent), nil)
+
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    "failing_agent",
+		UserId:     "testUser",
+		SessionId:  "testSession",
+		NewMessage: *genai.NewContentFromText("question", genai.RoleUser),
+		Streaming:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	if err := controller.RunSSEHandler(rr, httptest.NewRequest(http.MethodPost, "/run_sse", bytes.NewReader(body))); err != nil {
+		t.Fatalf("RunSSEHandler() error = %v", err)
+	}
+
+	frames := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n\n"), "\n\n")
+	if len(frames) != 2 {
+		t.Fatalf("RunSSEHandler() body = %q, want an event and an error event", rr.Body.String())
+	}
+	data, ok := strings.CutPrefix(frames[1], "event: error\ndata: ")
+	if !ok {
+		t.Fatalf("last SSE frame = %q, want an error event", frames[1])
+	}
+	var got models.ErrorResponse
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("decode error event: %v", err)
+	}
+	want := models.Error{Code: models.ErrorCodeInternal, Message: "run agent: model unavailable"}
+	if diff := cmp.Diff(want, got.Error); diff != "" {
+		t.Errorf("error event mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRuntimeAPIController_RunHandler_Errors(t *testing.T) {
+	testAgent, err := agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "test_agent", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "other_agent", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatal(err)
+	}
+	handler := controllers.NewErrorHandler(controllers.NewRuntimeAPIController(sessionService, agent.NewSingleLoader(testAgent), nil).RunHandler)
+
+	tc := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   models.ErrorCode
+	}{
+		{
+			name:       "malformed body",
+			body:       `{"appName": "test_agent",`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   models.ErrorCodeInvalidArgument,
+		},
+		{
+			name:       "wrong field type",
+			body:       `{"appName": 1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   models.ErrorCodeInvalidArgument,
+		},
+		{
+			name:       "session not found",
+			body:       `{"appName": "test_agent", "userId": "testUser", "sessionId": "missing"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   models.ErrorCodeNotFound,
+		},
+		{
+			name:       "agent not found",
+			body:       `{"appName": "other_agent", "userId": "testUser", "sessionId": "testSession"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   models.ErrorCodeNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(tt.body)))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			var got models.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if got.Error.Code != tt.wantCode || got.Error.Message == "" {
+				t.Errorf("error = %+v, want code %s and a message", got.Error, tt.wantCode)
+			}
+		})
+	}
+}
@@ -17,14 +17,25 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"google.golang.org/adk/server/adkrest/auth"
 	"google.golang.org/adk/server/adkrest/internal/models"
 	"google.golang.org/adk/session"
 )
 
+const (
+	defaultEventsPageSize = 100
+	maxEventsPageSize     = 1000
+)
+
 // TODO: Confirm error handling and target semantic for REST API.
 
 // SessionsAPIController is the controller for the Sessions API.
@@ -42,7 +53,11 @@ func (c *SessionsAPIController) CreateSessionHandler(rw http.ResponseWriter, req
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationWrite); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	createSessionRequest := models.CreateSessionRequest{}
@@ -50,13 +65,13 @@ func (c *SessionsAPIController) CreateSessionHandler(rw http.ResponseWriter, req
 	if req.ContentLength > 0 {
 		err := json.NewDecoder(req.Body).Decode(&createSessionRequest)
 		if err != nil {
-			http.Error(rw, err.Error(), http.StatusBadRequest)
+			writeError(rw, err, http.StatusBadRequest)
 			return
 		}
 	}
 	respSession, err := c.createSession(req.Context(), sessionID, createSessionRequest)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(respSession, http.StatusOK, rw)
@@ -86,11 +101,15 @@ func (c *SessionsAPIController) DeleteSessionHandler(rw http.ResponseWriter, req
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationWrite); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 
@@ -100,7 +119,7 @@ func (c *SessionsAPIController) DeleteSessionHandler(rw http.ResponseWriter, req
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(nil, http.StatusOK, rw)
@@ -111,11 +130,15 @@ func (c *SessionsAPIController) GetSessionHandler(rw http.ResponseWriter, req *h
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	storedSession, err := c.service.Get(req.Context(), &session.GetRequest{
@@ -124,12 +147,12 @@ func (c *SessionsAPIController) GetSessionHandler(rw http.ResponseWriter, req *h
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	session, err := models.FromSession(storedSession.Session)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(session, http.StatusOK, rw)
@@ -140,7 +163,11 @@ func (c *SessionsAPIController) ListSessionsHandler(rw http.ResponseWriter, req
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
 		return
 	}
 	var sessions []models.Session
@@ -149,16 +176,154 @@ func (c *SessionsAPIController) ListSessionsHandler(rw http.ResponseWriter, req
 		UserID:  sessionID.UserID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		writeError(rw, err, http.StatusInternalServerError)
 		return
 	}
 	for _, session := range resp.Sessions {
 		respSession, err := models.FromSession(session)
 		if err != nil {
-			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			writeError(rw, err, http.StatusInternalServerError)
 			return
 		}
 		sessions = append(sessions, respSession)
 	}
 	EncodeJSONResponse(sessions, http.StatusOK, rw)
 }
+
+// ListEventsHandler lists the events of a session annotated with their types.
+// The optional "types" query parameter is a comma separated list of event types
+// to return, e.g. "text,tool_call". The optional "after" query parameter, an
+// RFC 3339 timestamp, returns only later events, and the "order" query
+// parameter, "asc" (default) or "desc", sets the order of events. Results are
+// paginated using the "page_size" and "page_token" query parameters.
+func (c *SessionsAPIController) ListEventsHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		writeError(rw, errors.New("session_id parameter is required"), http.StatusBadRequest)
+		return
+	}
+	if err := authorize(req, sessionID.AppName, sessionID.UserID, auth.OperationRead); err != nil {
+		writeError(rw, err, http.StatusForbidden)
+		return
+	}
+	query := req.URL.Query()
+	types, err := models.ParseEventTypes(query.Get("types"))
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	pageSize, offset, err := parsePagination(query.Get("page_size"), query.Get("page_token"))
+	if err != nil {
+		writeError(rw, err, http.StatusBadRequest)
+		return
+	}
+	listReq := &session.ListEventsRequest{
+		AppName:        sessionID.AppName,
+		UserID:         sessionID.UserID,
+		SessionID:      sessionID.ID,
+		AscendingOrder: true,
+	}
+	if after := query.Get("after"); after != "" {
+		if listReq.AfterTimestamp, err = time.Parse(time.RFC3339Nano, after); err != nil {
+			writeError(rw, fmt.Errorf("invalid after %q, want RFC 3339 timestamp", after), http.StatusBadRequest)
+			return
+		}
+	}
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		listReq.AscendingOrder = false
+	default:
+		writeError(rw, fmt.Errorf("invalid order %q, want asc or desc", order), http.StatusBadRequest)
+		return
+	}
+	// Without the types filter, only the events up to the end of the page are needed.
+	if len(types) == 0 {
+		listReq.Limit = offset + pageSize + 1
+	}
+
+	events, err := c.listEvents(req.Context(), listReq)
+	if err != nil {
+		writeError(rw, err, http.StatusInternalServerError)
+		return
+	}
+
+	page := models.EventsPage{Events: []models.TypedEvent{}}
+	matched := 0
+	for _, event := range events {
+		eventType := models.ClassifyEvent(event)
+		if len(types) > 0 && !types[eventType] {
+			continue
+		}
+		matched++
+		if matched <= offset {
+			continue
+		}
+		if len(page.Events) == pageSize {
+			page.NextPageToken = strconv.Itoa(offset + pageSize)
+			break
+		}
+		page.Events = append(page.Events, models.TypedEvent{
+			Event: models.FromSessionEvent(*event),
+			Type:  eventType,
+		})
+	}
+	EncodeJSONResponse(page, http.StatusOK, rw)
+}
+
+// listEvents returns the events of the session using [session.EventLister]
+// if the service implements it, otherwise it loads the whole session.
+func (c *SessionsAPIController) listEvents(ctx context.Context, req *session.ListEventsRequest) ([]*session.Event, error) {
+	if lister, ok := c.service.(session.EventLister); ok {
+		resp, err := lister.ListEvents(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Events, nil
+	}
+
+	storedSession, err := c.service.Get(ctx, &session.GetRequest{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		SessionID: req.SessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var events []*session.Event
+	for event := range storedSession.Session.Events().All() {
+		if req.AfterTimestamp.IsZero() || event.Timestamp.After(req.AfterTimestamp) {
+			events = append(events, event)
+		}
+	}
+	if !req.AscendingOrder {
+		slices.Reverse(events)
+	}
+	return events, nil
+}
+
+// parsePagination returns the page size and the offset encoded in the page token.
+func parsePagination(pageSizeParam, pageToken string) (int, int, error) {
+	pageSize := defaultEventsPageSize
+	if pageSizeParam != "" {
+		size, err := strconv.Atoi(pageSizeParam)
+		if err != nil || size <= 0 {
+			return 0, 0, fmt.Errorf("invalid page_size %q", pageSizeParam)
+		}
+		pageSize = min(size, maxEventsPageSize)
+	}
+	offset := 0
+	if pageToken != "" {
+		token, err := strconv.Atoi(pageToken)
+		if err != nil || token < 0 {
+			return 0, 0, fmt.Errorf("invalid page_token %q", pageToken)
+		}
+		offset = token
+	}
+	return pageSize, offset, nil
+}
@@ -16,11 +16,11 @@ package controllers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 	"time"
 
@@ -28,9 +28,14 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/gorilla/mux"
 
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adkrest/auth"
 	"google.golang.org/adk/server/adkrest/controllers"
 	"google.golang.org/adk/server/adkrest/internal/fakes"
 	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
 )
 
 func TestGetSession(t *testing.T) {
@@ -45,7 +50,7 @@ func TestGetSession(t *testing.T) {
 		storedSessions map[fakes.SessionKey]fakes.TestSession
 		sessionID      fakes.SessionKey
 		wantSession    models.Session
-		wantErr        error
+		wantErr        *models.Error
 		wantStatus     int
 	}{
 		{
@@ -75,8 +80,8 @@ func TestGetSession(t *testing.T) {
 			name:           "session does not exist",
 			storedSessions: map[fakes.SessionKey]fakes.TestSession{},
 			sessionID:      id,
-			wantErr:        fmt.Errorf("not found"),
-			wantStatus:     http.StatusInternalServerError,
+			wantErr:        &models.Error{Code: models.ErrorCodeNotFound, Message: `session "testSession": session not found`},
+			wantStatus:     http.StatusNotFound,
 		},
 		{
 			name: "user ID is missing in input",
@@ -92,7 +97,7 @@ func TestGetSession(t *testing.T) {
 				AppName:   "testApp",
 				SessionID: "testSession",
 			},
-			wantErr:    fmt.Errorf("user_id parameter is required"),
+			wantErr:    &models.Error{Code: models.ErrorCodeInvalidArgument, Message: "user_id parameter is required"},
 			wantStatus: http.StatusBadRequest,
 		},
 		{
@@ -109,7 +114,7 @@ func TestGetSession(t *testing.T) {
 				},
 			},
 			sessionID:  id,
-			wantErr:    fmt.Errorf("session_id is empty in received session"),
+			wantErr:    &models.Error{Code: models.ErrorCodeInternal, Message: "session_id is empty in received session"},
 			wantStatus: http.StatusInternalServerError,
 		},
 	}
@@ -132,9 +137,12 @@ func TestGetSession(t *testing.T) {
 				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
 			}
 			if tt.wantErr != nil {
-				respErr := strings.Trim(rr.Body.String(), "\n")
-				if tt.wantErr.Error() != respErr {
-					t.Errorf("CreateSession() mismatch (-want +got):\n%v, %v", tt.wantErr.Error(), respErr)
+				var gotErr models.ErrorResponse
+				if err := json.NewDecoder(rr.Body).Decode(&gotErr); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+				if diff := cmp.Diff(*tt.wantErr, gotErr.Error); diff != "" {
+					t.Errorf("error response mismatch (-want +got):\n%s", diff)
 				}
 				return
 			}
@@ -163,7 +171,7 @@ func TestCreateSession(t *testing.T) {
 		sessionID        fakes.SessionKey
 		createRequestObj models.CreateSessionRequest
 		wantSession      models.Session
-		wantErr          error
+		wantErr          *models.Error
 		wantStatus       int
 	}{
 		{
@@ -177,7 +185,7 @@ func TestCreateSession(t *testing.T) {
 				},
 			},
 			sessionID:  id,
-			wantErr:    fmt.Errorf("session already exists"),
+			wantErr:    &models.Error{Code: models.ErrorCodeInternal, Message: "session already exists"},
 			wantStatus: http.StatusInternalServerError,
 		},
 		{
@@ -223,7 +231,7 @@ func TestCreateSession(t *testing.T) {
 			},
 			createRequestObj: models.CreateSessionRequest{},
 			wantStatus:       http.StatusBadRequest,
-			wantErr:          fmt.Errorf("user_id parameter is required"),
+			wantErr:          &models.Error{Code: models.ErrorCodeInvalidArgument, Message: "user_id parameter is required"},
 		},
 	}
 
@@ -249,9 +257,12 @@ func TestCreateSession(t *testing.T) {
 				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
 			}
 			if tt.wantErr != nil {
-				respErr := strings.Trim(rr.Body.String(), "\n")
-				if tt.wantErr.Error() != respErr {
-					t.Errorf("CreateSession() mismatch (-want +got):\n%v, %v", tt.wantErr.Error(), respErr)
+				var gotErr models.ErrorResponse
+				if err := json.NewDecoder(rr.Body).Decode(&gotErr); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+				if diff := cmp.Diff(*tt.wantErr, gotErr.Error); diff != "" {
+					t.Errorf("error response mismatch (-want +got):\n%s", diff)
 				}
 				return
 			}
@@ -297,7 +308,7 @@ func TestDeleteSession(t *testing.T) {
 			name:           "session does not exist",
 			storedSessions: map[fakes.SessionKey]fakes.TestSession{},
 			sessionID:      id,
-			wantStatus:     http.StatusInternalServerError,
+			wantStatus:     http.StatusNotFound,
 		},
 	}
 
@@ -436,6 +447,306 @@ func TestListSessions(t *testing.T) {
 	}
 }
 
+func TestListEvents(t *testing.T) {
+	id := fakes.SessionKey{
+		AppName:   "testApp",
+		UserID:    "testUser",
+		SessionID: "testSession",
+	}
+	newEvent := func(eventID string, parts ...*genai.Part) *session.Event {
+		return &session.Event{
+			ID:          eventID,
+			Author:      "agent",
+			LLMResponse: model.LLMResponse{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}},
+		}
+	}
+	transfer := newEvent("transfer", genai.NewPartFromFunctionResponse("transfer_to_agent", map[string]any{}))
+	transfer.Actions.TransferToAgent = "helper"
+	events := fakes.TestEvents{
+		newEvent("text1", genai.NewPartFromText("hello")),
+		newEvent("thought", &genai.Part{Text: "thinking", Thought: true}),
+		newEvent("call", genai.NewPartFromFunctionCall("get_weather", map[string]any{})),
+		newEvent("response", genai.NewPartFromFunctionResponse("get_weather", map[string]any{})),
+		transfer,
+		newEvent("text2", genai.NewPartFromText("sunny")),
+		{ID: "state", Author: "agent"},
+		newEvent("text3", genai.NewPartFromText("bye")),
+	}
+
+	tc := []struct {
+		name          string
+		query         string
+		wantIDs       []string
+		wantTypes     []models.EventType
+		wantNextToken string
+		wantStatus    int
+	}{
+		{
+			name:       "all events",
+			wantIDs:    []string{"text1", "thought", "call", "response", "transfer", "text2", "state", "text3"},
+			wantTypes:  []models.EventType{"text", "thought", "tool_call", "tool_response", "transfer", "text", "other", "text"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "filter by types",
+			query:      "types=text,tool_call",
+			wantIDs:    []string{"text1", "call", "text2", "text3"},
+			wantTypes:  []models.EventType{"text", "tool_call", "text", "text"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "first page",
+			query:         "types=text&page_size=2",
+			wantIDs:       []string{"text1", "text2"},
+			wantTypes:     []models.EventType{"text", "text"},
+			wantNextToken: "2",
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "last page",
+			query:      "types=text&page_size=2&page_token=2",
+			wantIDs:    []string{"text3"},
+			wantTypes:  []models.EventType{"text"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "page size equal to the number of events",
+			query:      "types=tool_call,tool_response&page_size=2",
+			wantIDs:    []string{"call", "response"},
+			wantTypes:  []models.EventType{"tool_call", "tool_response"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown type",
+			query:      "types=text,image",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid page size",
+			query:      "page_size=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid page token",
+			query:      "page_token=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "descending order",
+			query:      "types=text&order=desc",
+			wantIDs:    []string{"text3", "text2", "text1"},
+			wantTypes:  []models.EventType{"text", "text", "text"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid order",
+			query:      "order=random",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid after",
+			query:      "after=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionService := fakes.FakeSessionService{Sessions: map[fakes.SessionKey]fakes.TestSession{
+				id: {
+					Id:            id,
+					SessionState:  fakes.TestState{},
+					SessionEvents: events,
+					UpdatedAt:     time.Now(),
+				},
+			}}
+			apiController := controllers.NewSessionsAPIController(&sessionService)
+			req, err := http.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/events?"+tt.query, nil)
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			req = mux.SetURLVars(req, sessionVars(id))
+			rr := httptest.NewRecorder()
+
+			apiController.ListEventsHandler(rr, req)
+
+			if status := rr.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var page models.EventsPage
+			if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			var gotIDs []string
+			var gotTypes []models.EventType
+			for _, event := range page.Events {
+				gotIDs = append(gotIDs, event.ID)
+				gotTypes = append(gotTypes, event.Type)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTypes, gotTypes); diff != "" {
+				t.Errorf("event types mismatch (-want +got):\n%s", diff)
+			}
+			if page.NextPageToken != tt.wantNextToken {
+				t.Errorf("NextPageToken = %q, want %q", page.NextPageToken, tt.wantNextToken)
+			}
+		})
+	}
+}
+
+func TestListEvents_EventLister(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	start := time.Now().Truncate(time.Second)
+	for i := range 5 {
+		event := session.NewEvent("invocation")
+		event.ID = fmt.Sprintf("event%d", i+1)
+		event.Author = "agent"
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("hello", genai.RoleModel)}
+		if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+			t.Fatalf("AppendEvent() error = %v", err)
+		}
+	}
+
+	tc := []struct {
+		name          string
+		query         string
+		wantIDs       []string
+		wantNextToken string
+	}{
+		{
+			name:          "first page",
+			query:         "page_size=2",
+			wantIDs:       []string{"event1", "event2"},
+			wantNextToken: "2",
+		},
+		{
+			name:          "second page",
+			query:         "page_size=2&page_token=2",
+			wantIDs:       []string{"event3", "event4"},
+			wantNextToken: "4",
+		},
+		{
+			name:    "after timestamp",
+			query:   "after=" + start.Add(2*time.Second).Format(time.RFC3339Nano),
+			wantIDs: []string{"event4", "event5"},
+		},
+		{
+			name:          "most recent first",
+			query:         "order=desc&page_size=2",
+			wantIDs:       []string{"event5", "event4"},
+			wantNextToken: "2",
+		},
+	}
+
+	apiController := controllers.NewSessionsAPIController(sessionService)
+	id := fakes.SessionKey{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/events?"+tt.query, nil)
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			req = mux.SetURLVars(req, sessionVars(id))
+			rr := httptest.NewRecorder()
+
+			apiController.ListEventsHandler(rr, req)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusOK, rr.Body)
+			}
+			var page models.EventsPage
+			if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			var gotIDs []string
+			for _, event := range page.Events {
+				gotIDs = append(gotIDs, event.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+			}
+			if page.NextPageToken != tt.wantNextToken {
+				t.Errorf("NextPageToken = %q, want %q", page.NextPageToken, tt.wantNextToken)
+			}
+		})
+	}
+}
+
+func TestSessionsAuthorization(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	for _, userID := range []string{"alice", "bob"} {
+		if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: userID, SessionID: "testSession"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	keys := auth.APIKeys(map[string]string{"alice-key": "alice", "admin-key": "admin"})
+	adminOverride := auth.AuthorizerFunc(func(ctx context.Context, req auth.AccessRequest) error {
+		if req.Principal == "admin" && req.Operation == auth.OperationRead {
+			return nil
+		}
+		return auth.SameUser().Authorize(ctx, req)
+	})
+
+	tc := []struct {
+		name       string
+		key        string
+		authorizer auth.Authorizer
+		method     string
+		userID     string
+		wantStatus int
+	}{
+		{name: "own session", key: "alice-key", method: http.MethodGet, userID: "alice", wantStatus: http.StatusOK},
+		{name: "other user's session", key: "alice-key", method: http.MethodGet, userID: "bob", wantStatus: http.StatusForbidden},
+		{name: "delete other user's session", key: "alice-key", method: http.MethodDelete, userID: "bob", wantStatus: http.StatusForbidden},
+		{name: "admin without override", key: "admin-key", method: http.MethodGet, userID: "bob", wantStatus: http.StatusForbidden},
+		{name: "admin override", key: "admin-key", authorizer: adminOverride, method: http.MethodGet, userID: "bob", wantStatus: http.StatusOK},
+		{name: "admin override delete", key: "admin-key", authorizer: adminOverride, method: http.MethodDelete, userID: "bob", wantStatus: http.StatusForbidden},
+	}
+	apiController := controllers.NewSessionsAPIController(sessionService)
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			router.Use(auth.Middleware(auth.Config{Authenticator: keys, Authorizer: tt.authorizer}))
+			router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}", apiController.GetSessionHandler).Methods(http.MethodGet)
+			router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}", apiController.DeleteSessionHandler).Methods(http.MethodDelete)
+			req := httptest.NewRequest(tt.method, "/apps/testApp/users/"+tt.userID+"/sessions/testSession", nil)
+			req.Header.Set(auth.APIKeyHeader, tt.key)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, tt.wantStatus, rr.Body)
+			}
+			if tt.wantStatus != http.StatusForbidden {
+				return
+			}
+			var body models.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if body.Error.Code != models.ErrorCodePermissionDenied || body.Error.Message == "" {
+				t.Errorf("error response = %+v, want code %s and a message", body.Error, models.ErrorCodePermissionDenied)
+			}
+			if _, err := sessionService.Get(ctx, &session.GetRequest{AppName: "testApp", UserID: "bob", SessionID: "testSession"}); err != nil {
+				t.Errorf("session of bob after a denied request: %v", err)
+			}
+		})
+	}
+}
+
 func sessionVars(sessionID fakes.SessionKey) map[string]string {
 	return map[string]string{
 		"app_name":   sessionID.AppName,
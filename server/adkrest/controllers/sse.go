@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultSSEKeepAliveInterval is the default interval of the keep-alive
+// comments written to idle SSE streams.
+const DefaultSSEKeepAliveInterval = 15 * time.Second
+
+// sseStream serializes the writes to an SSE response, done by the handler
+// and by the keep-alive pings.
+type sseStream struct {
+	mu      sync.Mutex
+	rw      http.ResponseWriter
+	flusher http.Flusher
+}
+
+// write calls f holding the stream lock.
+func (s *sseStream) write(f func(rw http.ResponseWriter, flusher http.Flusher) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return f(s.rw, s.flusher)
+}
+
+// keepAlive writes a ": ping" comment to the stream every interval, so that
+// proxies don't drop the connection while the agent is busy, e.g. waiting
+// for a slow model. Clients ignore comments. The returned function stops the
+// pings, no writes happen after it returns. A non-positive interval disables
+// the pings.
+func (s *sseStream) keepAlive(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := s.write(func(rw http.ResponseWriter, flusher http.Flusher) error {
+					if _, err := fmt.Fprint(rw, ": ping\n\n"); err != nil {
+						return err
+					}
+					flusher.Flush()
+					return nil
+				})
+				if err != nil {
+					// The client is gone, the handler notices it on its next write.
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
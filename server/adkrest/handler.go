@@ -15,35 +15,131 @@
 package adkrest
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/eval"
 	"google.golang.org/adk/internal/telemetry"
+	"google.golang.org/adk/server/adkrest/auth"
 	"google.golang.org/adk/server/adkrest/controllers"
 	"google.golang.org/adk/server/adkrest/internal/routers"
 	"google.golang.org/adk/server/adkrest/internal/services"
 )
 
-// NewHandler creates and returns an http.Handler for the ADK REST API.
-func NewHandler(config *launcher.Config) http.Handler {
+// Handler serves the ADK REST API.
+type Handler struct {
+	http.Handler
+	runtime *controllers.RuntimeAPIController
+}
+
+// Option configures a [Handler].
+type Option func(*options)
+
+type options struct {
+	sseKeepAliveInterval time.Duration
+	auth                 *auth.Config
+	docs                 routers.DocsConfig
+	evalService          eval.Service
+}
+
+// WithEvalService sets the service storing the eval sets and results of the
+// Eval API. The default is an in-memory service, see [eval.InMemoryService].
+func WithEvalService(service eval.Service) Option {
+	return func(o *options) {
+		o.evalService = service
+	}
+}
+
+// WithSwaggerUI serves a Swagger UI page documenting the API at /docs, next
+// to the OpenAPI document served at /openapi.json.
+func WithSwaggerUI() Option {
+	return func(o *options) {
+		o.docs.SwaggerUI = true
+	}
+}
+
+// WithBasePath sets the path the handler is served under, e.g. "/api" if it
+// is mounted with http.StripPrefix("/api", handler). It is the server URL of
+// the OpenAPI document.
+func WithBasePath(path string) Option {
+	return func(o *options) {
+		o.docs.ServerURL = path
+	}
+}
+
+// WithAuth makes the handler authenticate the requests, see
+// [auth.Middleware]. The runs are executed for the effective user id of the
+// request, see [auth.UserID], and the accesses to the data of a user are
+// checked with the [auth.Config.Authorizer].
+func WithAuth(cfg auth.Config) Option {
+	return func(o *options) {
+		o.auth = &cfg
+	}
+}
+
+// WithSSEKeepAliveInterval sets the interval of the keep-alive comments
+// written to the SSE streams of agent runs, so that proxies don't drop them
+// while the agent is busy. Zero disables them. The default is
+// [controllers.DefaultSSEKeepAliveInterval].
+func WithSSEKeepAliveInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.sseKeepAliveInterval = interval
+	}
+}
+
+// NewHandler creates and returns a [Handler] for the ADK REST API.
+func NewHandler(config *launcher.Config, opts ...Option) *Handler {
+	o := options{sseKeepAliveInterval: controllers.DefaultSSEKeepAliveInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.evalService == nil {
+		o.evalService = eval.InMemoryService()
+	}
+
 	adkExporter := services.NewAPIServerSpanExporter()
 	telemetry.AddSpanProcessor(sdktrace.NewSimpleSpanProcessor(adkExporter))
 
-	router := mux.NewRouter().StrictSlash(true)
-	// TODO: Allow taking a prefix to allow customizing the path
-	// where the ADK REST API will be served.
-	setupRouter(router,
+	runtime := controllers.NewRuntimeAPIController(config.SessionService, config.AgentLoader, config.ArtifactService)
+	runtime.SSEKeepAliveInterval = o.sseKeepAliveInterval
+	apiRouters := []routers.Router{
 		routers.NewSessionsAPIRouter(controllers.NewSessionsAPIController(config.SessionService)),
-		routers.NewRuntimeAPIRouter(controllers.NewRuntimeAPIController(config.SessionService, config.AgentLoader, config.ArtifactService)),
+		routers.NewRuntimeAPIRouter(runtime),
 		routers.NewAppsAPIRouter(controllers.NewAppsAPIController(config.AgentLoader)),
 		routers.NewDebugAPIRouter(controllers.NewDebugAPIController(config.SessionService, config.AgentLoader, adkExporter)),
 		routers.NewArtifactsAPIRouter(controllers.NewArtifactsAPIController(config.ArtifactService)),
-		&routers.EvalAPIRouter{},
-	)
-	return router
+		routers.NewEvalAPIRouter(controllers.NewEvalAPIController(o.evalService, config.SessionService, config.AgentLoader)),
+	}
+	docsRouter, err := routers.NewDocsAPIRouter(o.docs, apiRouters...)
+	if err != nil {
+		// The routes are static, this is a programming error.
+		panic(fmt.Sprintf("document the ADK REST API: %v", err))
+	}
+
+	router := mux.NewRouter().StrictSlash(true)
+	// The documentation is served without authentication.
+	setupRouter(router, docsRouter)
+	// TODO: Allow taking a prefix to allow customizing the path
+	// where the ADK REST API will be served.
+	api := router.NewRoute().Subrouter()
+	if o.auth != nil {
+		api.Use(auth.Middleware(*o.auth))
+	}
+	setupRouter(api, apiRouters...)
+	return &Handler{Handler: router, runtime: runtime}
+}
+
+// Close gracefully shuts down the agent runs: new runs are rejected and Close
+// waits until the active runs finish or ctx is done. Call it when the server
+// shuts down, after http.Server.Shutdown stopped accepting requests.
+func (h *Handler) Close(ctx context.Context) error {
+	return h.runtime.Close(ctx)
 }
 
 func setupRouter(router *mux.Router, subrouters ...routers.Router) *mux.Router {
@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest_test
+
+import (
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/server/adkrest"
+	"google.golang.org/adk/server/adkrest/auth"
+	"google.golang.org/adk/session"
+)
+
+func newTestHandler(t *testing.T, opts ...adkrest.Option) *adkrest.Handler {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(func(*session.Event, error) bool) {}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adkrest.NewHandler(&launcher.Config{
+		SessionService:  session.InMemoryService(),
+		ArtifactService: artifact.InMemoryService(),
+		AgentLoader:     agent.NewSingleLoader(a),
+	}, opts...)
+}
+
+func TestNewHandler_OpenAPI(t *testing.T) {
+	keys := auth.APIKeys(map[string]string{"key": "alice"})
+	handler := newTestHandler(t, adkrest.WithAuth(auth.Config{Authenticator: keys}), adkrest.WithBasePath("/api"), adkrest.WithSwaggerUI())
+
+	// The documentation is served without credentials.
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name     string `json:"name"`
+				In       string `json:"in"`
+				Required bool   `json:"required"`
+			} `json:"parameters"`
+			Responses map[string]struct {
+				Content map[string]json.RawMessage `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	body := rr.Body.Bytes()
+	if err := json.Unmarshal(body, &spec); err != nil {
+		t.Fatalf("decode OpenAPI document: %v", err)
+	}
+	if spec.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q, want 3.1.0", spec.OpenAPI)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "/api" {
+		t.Errorf("servers = %+v, want /api", spec.Servers)
+	}
+
+	// Every operation declares its path parameters and the error responses.
+	operations := map[string]string{}
+	pathParam := regexp.MustCompile(`\{([^}]+)\}`)
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			if other, ok := operations[op.OperationID]; ok {
+				t.Errorf("operationId %q of %s %s is already used by %s", op.OperationID, method, path, other)
+			}
+			operations[op.OperationID] = method + " " + path
+			declared := map[string]bool{}
+			for _, p := range op.Parameters {
+				if p.In == "path" && p.Required {
+					declared[p.Name] = true
+				}
+			}
+			for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+				if !declared[m[1]] {
+					t.Errorf("%s %s doesn't declare the required path parameter %q", method, path, m[1])
+				}
+			}
+			if _, ok := op.Responses["default"].Content["application/json"]; !ok {
+				t.Errorf("%s %s doesn't document the error responses", method, path)
+			}
+		}
+	}
+
+	// Every registered route is documented.
+	err := handler.Handler.(*mux.Router).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if name := route.GetName(); name != "" {
+			if _, ok := operations[name]; !ok {
+				t.Errorf("route %q is not documented", name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The references resolve and the schemas are valid.
+	for _, ref := range regexp.MustCompile(`"\$ref":"([^"]*)"`).FindAllSubmatch(body, -1) {
+		name, ok := strings.CutPrefix(string(ref[1]), "#/components/schemas/")
+		if _, found := spec.Components.Schemas[name]; !ok || !found {
+			t.Errorf("unresolved reference %q", ref[1])
+		}
+	}
+	for name, raw := range spec.Components.Schemas {
+		var schema jsonschema.Schema
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			t.Errorf("decode schema %s: %v", name, err)
+			continue
+		}
+		if _, err := schema.Resolve(nil); err != nil {
+			t.Errorf("invalid schema %s: %v", name, err)
+		}
+	}
+	if _, ok := spec.Components.Schemas["ErrorResponse"]; !ok {
+		t.Error("the error response schema is missing")
+	}
+	if _, ok := spec.Paths["/run_sse"]["post"].Responses["200"].Content["text/event-stream"]; !ok {
+		t.Error("POST /run_sse doesn't document the text/event-stream response")
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "openapi.json") {
+		t.Errorf("GET /docs = %d %q, want the Swagger UI page", rr.Code, rr.Body)
+	}
+
+	// The API itself still requires credentials.
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/list-apps", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("GET /list-apps without credentials status = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestNewHandler_WithoutSwaggerUI(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("GET /docs status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("GET /openapi.json status = %d, want %d", rr.Code, http.StatusOK)
+	}
+}
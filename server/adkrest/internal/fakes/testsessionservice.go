@@ -145,7 +145,7 @@ func (s *FakeSessionService) Get(ctx context.Context, req *session.GetRequest) (
 			Session: &sess,
 		}, nil
 	}
-	return nil, fmt.Errorf("not found")
+	return nil, fmt.Errorf("session %q: %w", req.SessionID, session.ErrSessionNotFound)
 }
 
 func (s *FakeSessionService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
@@ -168,7 +168,7 @@ func (s *FakeSessionService) Delete(ctx context.Context, req *session.DeleteRequ
 		SessionID: req.SessionID,
 	}
 	if _, ok := s.Sessions[id]; !ok {
-		return fmt.Errorf("not found")
+		return fmt.Errorf("session %q: %w", req.SessionID, session.ErrSessionNotFound)
 	}
 	delete(s.Sessions, id)
 	return nil
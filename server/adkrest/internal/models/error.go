@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "net/http"
+
+// ErrorCode is the stable, machine-readable code of an API error.
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidArgument  ErrorCode = "INVALID_ARGUMENT"
+	ErrorCodeUnauthenticated  ErrorCode = "UNAUTHENTICATED"
+	ErrorCodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	ErrorCodeNotFound         ErrorCode = "NOT_FOUND"
+	ErrorCodeAlreadyExists    ErrorCode = "ALREADY_EXISTS"
+	ErrorCodeUnimplemented    ErrorCode = "UNIMPLEMENTED"
+	ErrorCodeUnavailable      ErrorCode = "UNAVAILABLE"
+	ErrorCodeInternal         ErrorCode = "INTERNAL"
+)
+
+// ErrorCodes lists all the error codes.
+var ErrorCodes = []ErrorCode{
+	ErrorCodeInvalidArgument,
+	ErrorCodeUnauthenticated,
+	ErrorCodePermissionDenied,
+	ErrorCodeNotFound,
+	ErrorCodeAlreadyExists,
+	ErrorCodeUnimplemented,
+	ErrorCodeUnavailable,
+	ErrorCodeInternal,
+}
+
+// ErrorCodeFromStatus returns the error code of an HTTP status code.
+func ErrorCodeFromStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeInvalidArgument
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthenticated
+	case http.StatusForbidden:
+		return ErrorCodePermissionDenied
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeAlreadyExists
+	case http.StatusNotImplemented:
+		return ErrorCodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return ErrorCodeUnavailable
+	default:
+		return ErrorCodeInternal
+	}
+}
+
+// ErrorResponse is the body of the API error responses, and the data of the
+// SSE error events.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Error describes an API error.
+type Error struct {
+	Code    ErrorCode      `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
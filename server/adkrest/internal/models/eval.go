@@ -0,0 +1,35 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "google.golang.org/adk/eval"
+
+// AddSessionToEvalSetRequest records a session as an eval case.
+type AddSessionToEvalSetRequest struct {
+	// EvalID is the id of the new eval case.
+	EvalID    string `json:"evalId"`
+	SessionID string `json:"sessionId"`
+	UserID    string `json:"userId"`
+}
+
+// RunEvalRequest runs the evaluation of an eval set.
+type RunEvalRequest struct {
+	// EvalIDs are the ids of the eval cases to run. Optional: all the cases
+	// of the set if empty.
+	EvalIDs []string `json:"evalIds,omitempty"`
+	// EvalMetrics are the metrics to score. Optional: eval.DefaultMetrics if
+	// empty.
+	EvalMetrics []eval.Metric `json:"evalMetrics,omitempty"`
+}
@@ -15,18 +15,22 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
 
 // EventActions represent a data model for session.EventActions
 type EventActions struct {
-	StateDelta    map[string]any   `json:"stateDelta"`
-	ArtifactDelta map[string]int64 `json:"artifactDelta"`
+	StateDelta           map[string]any          `json:"stateDelta"`
+	ArtifactDelta        map[string]int64        `json:"artifactDelta"`
+	RequestedAuthConfigs map[string]*auth.Config `json:"requestedAuthConfigs,omitempty"`
 }
 
 // Event represents a single event in a session.
@@ -45,20 +49,31 @@ type Event struct {
 	ErrorCode          string                   `json:"errorCode"`
 	ErrorMessage       string                   `json:"errorMessage"`
 	Actions            EventActions             `json:"actions"`
+	// UsageMetadata is the token usage of the LLM call producing the event.
+	UsageMetadata *genai.GenerateContentResponseUsageMetadata `json:"usageMetadata,omitempty"`
+	// InvocationUsage is the token usage aggregated over the invocation,
+	// set on the final response of each agent.
+	InvocationUsage *genai.GenerateContentResponseUsageMetadata `json:"invocationUsage,omitempty"`
+	// ParentFunctionCallID is the ID of the function call of the tool
+	// which ran the agent authoring the event, e.g. an agent tool.
+	ParentFunctionCallID string `json:"parentFunctionCallId,omitempty"`
 }
 
 // ToSessionEvent maps Event data struct to session.Event
 func ToSessionEvent(event Event) *session.Event {
 	return &session.Event{
-		ID:                 event.ID,
-		Timestamp:          time.Unix(event.Time, 0),
-		InvocationID:       event.InvocationID,
-		Branch:             event.Branch,
-		Author:             event.Author,
-		LongRunningToolIDs: event.LongRunningToolIDs,
+		ID:                   event.ID,
+		Timestamp:            time.Unix(event.Time, 0),
+		InvocationID:         event.InvocationID,
+		Branch:               event.Branch,
+		Author:               event.Author,
+		LongRunningToolIDs:   event.LongRunningToolIDs,
+		InvocationUsage:      event.InvocationUsage,
+		ParentFunctionCallID: event.ParentFunctionCallID,
 		LLMResponse: model.LLMResponse{
 			Content:           event.Content,
 			GroundingMetadata: event.GroundingMetadata,
+			UsageMetadata:     event.UsageMetadata,
 			Partial:           event.Partial,
 			TurnComplete:      event.TurnComplete,
 			Interrupted:       event.Interrupted,
@@ -66,8 +81,9 @@ func ToSessionEvent(event Event) *session.Event {
 			ErrorMessage:      event.ErrorMessage,
 		},
 		Actions: session.EventActions{
-			StateDelta:    event.Actions.StateDelta,
-			ArtifactDelta: event.Actions.ArtifactDelta,
+			StateDelta:           event.Actions.StateDelta,
+			ArtifactDelta:        event.Actions.ArtifactDelta,
+			RequestedAuthConfigs: event.Actions.RequestedAuthConfigs,
 		},
 	}
 }
@@ -75,22 +91,117 @@ func ToSessionEvent(event Event) *session.Event {
 // FromSessionEvent maps session.Event to Event data struct
 func FromSessionEvent(event session.Event) Event {
 	return Event{
-		ID:                 event.ID,
-		Time:               event.Timestamp.Unix(),
-		InvocationID:       event.InvocationID,
-		Branch:             event.Branch,
-		Author:             event.Author,
-		Partial:            event.Partial,
-		LongRunningToolIDs: event.LongRunningToolIDs,
-		Content:            event.LLMResponse.Content,
-		GroundingMetadata:  event.LLMResponse.GroundingMetadata,
-		TurnComplete:       event.LLMResponse.TurnComplete,
-		Interrupted:        event.LLMResponse.Interrupted,
-		ErrorCode:          event.LLMResponse.ErrorCode,
-		ErrorMessage:       event.LLMResponse.ErrorMessage,
+		ID:                   event.ID,
+		Time:                 event.Timestamp.Unix(),
+		InvocationID:         event.InvocationID,
+		Branch:               event.Branch,
+		Author:               event.Author,
+		Partial:              event.Partial,
+		LongRunningToolIDs:   event.LongRunningToolIDs,
+		Content:              event.LLMResponse.Content,
+		GroundingMetadata:    event.LLMResponse.GroundingMetadata,
+		TurnComplete:         event.LLMResponse.TurnComplete,
+		Interrupted:          event.LLMResponse.Interrupted,
+		ErrorCode:            event.LLMResponse.ErrorCode,
+		ErrorMessage:         event.LLMResponse.ErrorMessage,
+		UsageMetadata:        event.LLMResponse.UsageMetadata,
+		InvocationUsage:      event.InvocationUsage,
+		ParentFunctionCallID: event.ParentFunctionCallID,
 		Actions: EventActions{
-			StateDelta:    event.Actions.StateDelta,
-			ArtifactDelta: event.Actions.ArtifactDelta,
+			StateDelta:           event.Actions.StateDelta,
+			ArtifactDelta:        event.Actions.ArtifactDelta,
+			RequestedAuthConfigs: event.Actions.RequestedAuthConfigs,
 		},
 	}
 }
+
+// EventType classifies an event for rendering transcripts.
+type EventType string
+
+const (
+	// EventTypeText is an event with a text response.
+	EventTypeText EventType = "text"
+	// EventTypeThought is an event with only thought text.
+	EventTypeThought EventType = "thought"
+	// EventTypeToolCall is an event requesting function calls.
+	EventTypeToolCall EventType = "tool_call"
+	// EventTypeToolResponse is an event with function responses.
+	EventTypeToolResponse EventType = "tool_response"
+	// EventTypeTransfer is an event transferring control to another agent.
+	EventTypeTransfer EventType = "transfer"
+	// EventTypeOther is an event not matching any other type, e.g. a state update.
+	EventTypeOther EventType = "other"
+)
+
+var knownEventTypes = map[EventType]bool{
+	EventTypeText:         true,
+	EventTypeThought:      true,
+	EventTypeToolCall:     true,
+	EventTypeToolResponse: true,
+	EventTypeTransfer:     true,
+	EventTypeOther:        true,
+}
+
+// ParseEventTypes parses a comma separated list of event types.
+// An empty string results in an empty set, which matches all events.
+func ParseEventTypes(s string) (map[EventType]bool, error) {
+	types := map[EventType]bool{}
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		eventType := EventType(name)
+		if !knownEventTypes[eventType] {
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+		types[eventType] = true
+	}
+	return types, nil
+}
+
+// ClassifyEvent returns the type of the event. Transfers take precedence
+// over tool calls, which take precedence over tool responses and text.
+func ClassifyEvent(event *session.Event) EventType {
+	if event.Actions.TransferToAgent != "" {
+		return EventTypeTransfer
+	}
+	if event.Content == nil {
+		return EventTypeOther
+	}
+	hasText, hasThought, hasResponse := false, false, false
+	for _, part := range event.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			return EventTypeToolCall
+		case part.FunctionResponse != nil:
+			hasResponse = true
+		case part.Text != "" && part.Thought:
+			hasThought = true
+		case part.Text != "":
+			hasText = true
+		}
+	}
+	switch {
+	case hasResponse:
+		return EventTypeToolResponse
+	case hasText:
+		return EventTypeText
+	case hasThought:
+		return EventTypeThought
+	}
+	return EventTypeOther
+}
+
+// TypedEvent is an event annotated with its type.
+type TypedEvent struct {
+	Event
+	Type EventType `json:"type"`
+}
+
+// EventsPage is a page of typed events.
+type EventsPage struct {
+	Events []TypedEvent `json:"events"`
+	// NextPageToken is used to fetch the next page, empty if there are no more events.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// Span is a trace span returned by the debug API. The JSON field names
+// follow the format expected by the ADK Web UI.
+type Span struct {
+	Name         string `json:"name"`
+	TraceID      string `json:"trace_id"`
+	SpanID       string `json:"span_id"`
+	ParentSpanID string `json:"parent_span_id,omitempty"`
+	// StartTime and EndTime are Unix timestamps in nanoseconds.
+	StartTime  int64          `json:"start_time"`
+	EndTime    int64          `json:"end_time"`
+	Attributes map[string]any `json:"attributes"`
+	// InvocationID is the id of the invocation the span belongs to. It
+	// correlates the span with the events of the invocation.
+	InvocationID string `json:"invocation_id,omitempty"`
+}
@@ -38,6 +38,10 @@ func (r *AppsAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/list-apps",
 			HandlerFunc: r.appsController.ListAppsHandler,
+			Doc: RouteDoc{
+				Summary:  "List the names of the loaded apps",
+				Response: []string{},
+			},
 		},
 	}
 }
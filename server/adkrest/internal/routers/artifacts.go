@@ -17,6 +17,8 @@ package routers
 import (
 	"net/http"
 
+	"google.golang.org/genai"
+
 	"google.golang.org/adk/server/adkrest/controllers"
 )
 
@@ -38,24 +40,42 @@ func (r *ArtifactsAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts",
 			HandlerFunc: r.artifactsController.ListArtifactsHandler,
+			Doc: RouteDoc{
+				Summary:  "List the artifact file names of a session",
+				Response: []string{},
+			},
 		},
 		Route{
 			Name:        "LoadArtifact",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}",
 			HandlerFunc: r.artifactsController.LoadArtifactHandler,
+			Doc: RouteDoc{
+				Summary: "Load the latest version of an artifact",
+				Query: []QueryParam{
+					{Name: "version", Description: "Version of the artifact to load instead of the latest", Type: "integer"},
+				},
+				Response: genai.Part{},
+			},
 		},
 		Route{
-			Name:        "LoadArtifact",
+			Name:        "LoadArtifactVersion",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}/versions/{version}",
 			HandlerFunc: r.artifactsController.LoadArtifactVersionHandler,
+			Doc: RouteDoc{
+				Summary:  "Load a version of an artifact",
+				Response: genai.Part{},
+			},
 		},
 		Route{
 			Name:        "DeleteArtifact",
 			Methods:     []string{http.MethodDelete, http.MethodOptions},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}",
 			HandlerFunc: r.artifactsController.DeleteArtifactHandler,
+			Doc: RouteDoc{
+				Summary: "Delete all the versions of an artifact",
+			},
 		},
 	}
 }
@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
 // DebugAPIRouter defines the routes for the Debug API.
@@ -38,18 +39,40 @@ func (r *DebugAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/debug/trace/{event_id}",
 			HandlerFunc: r.runtimeController.TraceDictHandler,
+			Doc: RouteDoc{
+				Summary:  "Get the trace attributes of the model call of an event",
+				Response: map[string]string{},
+			},
 		},
 		Route{
 			Name:        "GetEventGraph",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/events/{event_id}/graph",
 			HandlerFunc: r.runtimeController.EventGraphHandler,
+			Doc: RouteDoc{
+				Summary:  "Get the agent graph highlighting an event, in the DOT language",
+				Response: map[string]string{},
+			},
 		},
 		Route{
 			Name:        "GetSessionTrace",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/debug/trace/session/{session_id}",
-			HandlerFunc: controllers.Unimplemented,
+			HandlerFunc: r.runtimeController.SessionTraceHandler,
+			Doc: RouteDoc{
+				Summary:  "Get the spans of all the invocations of a session",
+				Response: []models.Span{},
+			},
+		},
+		Route{
+			Name:        "GetInvocationTrace",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/debug/trace/invocation/{invocation_id}",
+			HandlerFunc: r.runtimeController.InvocationTraceHandler,
+			Doc: RouteDoc{
+				Summary:  "Get the spans of an invocation",
+				Response: []models.Span{},
+			},
 		},
 	}
 }
@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routers
+
+import (
+	"net/http"
+	"slices"
+
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/server/adkrest/controllers"
+)
+
+// DocsConfig configures the API documentation.
+type DocsConfig struct {
+	// ServerURL is the URL the API is served at, e.g. "/api".
+	ServerURL string
+	// SwaggerUI enables the Swagger UI page.
+	SwaggerUI bool
+}
+
+// DocsAPIRouter serves the OpenAPI document of the API and, optionally, a
+// Swagger UI page.
+type DocsAPIRouter struct {
+	docsController *controllers.DocsAPIController
+	swaggerUI      bool
+}
+
+// NewDocsAPIRouter creates a router documenting the routes of the routers
+// and of the docs router itself.
+func NewDocsAPIRouter(cfg DocsConfig, routers ...Router) (*DocsAPIRouter, error) {
+	// The routes are documented before the controller exists, the handlers
+	// aren't called.
+	r := &DocsAPIRouter{swaggerUI: cfg.SwaggerUI}
+	spec, err := OpenAPI("ADK REST API", version.Version, cfg.ServerURL, append(slices.Clip(routers), r)...)
+	if err != nil {
+		return nil, err
+	}
+	r.docsController = controllers.NewDocsAPIController(spec)
+	return r, nil
+}
+
+func (r *DocsAPIRouter) Routes() Routes {
+	routes := Routes{
+		Route{
+			Name:        "GetOpenAPISpec",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/openapi.json",
+			HandlerFunc: r.docsController.OpenAPIHandler,
+			Doc: RouteDoc{
+				Summary:  "Get the OpenAPI document of the API",
+				Response: map[string]any{},
+			},
+		},
+	}
+	if r.swaggerUI {
+		routes = append(routes, Route{
+			Name:        "GetAPIDocs",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/docs",
+			HandlerFunc: r.docsController.SwaggerUIHandler,
+			Doc: RouteDoc{
+				Summary: "Get the Swagger UI page documenting the API",
+			},
+		})
+	}
+	return routes
+}
@@ -17,32 +17,156 @@ package routers
 import (
 	"net/http"
 
+	"google.golang.org/adk/eval"
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
 // EvalAPIRouter defines the routes for the Eval API.
-type EvalAPIRouter struct{}
+type EvalAPIRouter struct {
+	evalController *controllers.EvalAPIController
+}
+
+// NewEvalAPIRouter creates a new EvalAPIRouter.
+func NewEvalAPIRouter(controller *controllers.EvalAPIController) *EvalAPIRouter {
+	return &EvalAPIRouter{evalController: controller}
+}
 
-// Routes returns the routes for the Apps API.
+// Routes returns the routes for the Eval API.
 func (r *EvalAPIRouter) Routes() Routes {
 	return Routes{
 		Route{
 			Name:        "ListEvalSets",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/eval_sets",
-			HandlerFunc: controllers.Unimplemented,
+			HandlerFunc: r.evalController.ListEvalSetsHandler,
+			Doc: RouteDoc{
+				Summary:  "List the ids of the eval sets of an app",
+				Response: []string{},
+			},
 		},
 		Route{
-			Name:        "ListEvalSets",
+			Name:        "CreateEvalSet",
 			Methods:     []string{http.MethodPost, http.MethodOptions},
-			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_name}",
-			HandlerFunc: controllers.Unimplemented,
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}",
+			HandlerFunc: r.evalController.CreateEvalSetHandler,
+			Doc: RouteDoc{
+				Summary:  "Create an eval set, optionally with its cases",
+				Request:  eval.Set{},
+				Response: eval.Set{},
+			},
+		},
+		Route{
+			Name:        "GetEvalSet",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}",
+			HandlerFunc: r.evalController.GetEvalSetHandler,
+			Doc: RouteDoc{
+				Summary:  "Get an eval set with its cases",
+				Response: eval.Set{},
+			},
+		},
+		Route{
+			Name:        "DeleteEvalSet",
+			Methods:     []string{http.MethodDelete},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}",
+			HandlerFunc: r.evalController.DeleteEvalSetHandler,
+			Doc: RouteDoc{
+				Summary: "Delete an eval set with its cases",
+			},
+		},
+		Route{
+			Name:        "AddSessionToEvalSet",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/add_session",
+			HandlerFunc: r.evalController.AddSessionHandler,
+			Doc: RouteDoc{
+				Summary:  "Record the conversation of a session as a new eval case",
+				Request:  models.AddSessionToEvalSetRequest{},
+				Response: eval.Case{},
+			},
+		},
+		Route{
+			Name:        "ListEvalsInEvalSet",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals",
+			HandlerFunc: r.evalController.ListEvalsHandler,
+			Doc: RouteDoc{
+				Summary:  "List the ids of the eval cases of an eval set",
+				Response: []string{},
+			},
+		},
+		Route{
+			Name:        "CreateEval",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals",
+			HandlerFunc: r.evalController.CreateEvalHandler,
+			Doc: RouteDoc{
+				Summary:  "Add an eval case to an eval set",
+				Request:  eval.Case{},
+				Response: eval.Case{},
+			},
+		},
+		Route{
+			Name:        "GetEval",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals/{eval_case_id}",
+			HandlerFunc: r.evalController.GetEvalHandler,
+			Doc: RouteDoc{
+				Summary:  "Get an eval case",
+				Response: eval.Case{},
+			},
+		},
+		Route{
+			Name:        "UpdateEval",
+			Methods:     []string{http.MethodPut},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals/{eval_case_id}",
+			HandlerFunc: r.evalController.UpdateEvalHandler,
+			Doc: RouteDoc{
+				Summary:  "Replace an eval case",
+				Request:  eval.Case{},
+				Response: eval.Case{},
+			},
+		},
+		Route{
+			Name:        "DeleteEval",
+			Methods:     []string{http.MethodDelete},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals/{eval_case_id}",
+			HandlerFunc: r.evalController.DeleteEvalHandler,
+			Doc: RouteDoc{
+				Summary: "Delete an eval case",
+			},
+		},
+		Route{
+			Name:        "RunEval",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/run_eval",
+			HandlerFunc: r.evalController.RunEvalHandler,
+			Doc: RouteDoc{
+				Summary:  "Replay eval cases through the agent and score them, saving the result",
+				Request:  models.RunEvalRequest{},
+				Response: []eval.CaseResult{},
+			},
 		},
 		Route{
 			Name:        "ListEvalResults",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/eval_results",
-			HandlerFunc: controllers.Unimplemented,
+			HandlerFunc: r.evalController.ListEvalResultsHandler,
+			Doc: RouteDoc{
+				Summary:  "List the ids of the saved eval results of an app",
+				Response: []string{},
+			},
+		},
+		Route{
+			Name:        "GetEvalResult",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_results/{eval_result_id}",
+			HandlerFunc: r.evalController.GetEvalResultHandler,
+			Doc: RouteDoc{
+				Summary:  "Get a saved eval result",
+				Response: eval.SetResult{},
+			},
 		},
 	}
 }
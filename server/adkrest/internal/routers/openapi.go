@@ -0,0 +1,276 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification of the
+// documents returned by [OpenAPI].
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIDocument is an OpenAPI document describing the API.
+type OpenAPIDocument struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       OpenAPIInfo         `json:"info"`
+	Servers    []OpenAPIServer     `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components OpenAPIComponents   `json:"components"`
+}
+
+// OpenAPIInfo is the metadata of the API.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIServer is a server serving the API.
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps the lowercase HTTP methods of a path to their operations.
+type PathItem map[string]*Operation
+
+// Operation describes an api endpoint.
+type Operation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Parameters  []Parameter                 `json:"parameters,omitempty"`
+	RequestBody *RequestBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// Parameter describes a path or query parameter of an operation.
+type Parameter struct {
+	Name        string             `json:"name"`
+	In          string             `json:"in"`
+	Description string             `json:"description,omitempty"`
+	Required    bool               `json:"required,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema"`
+}
+
+// RequestBody describes the request body of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a response of an operation.
+type OpenAPIResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the content of a request or response body.
+type MediaType struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+// OpenAPIComponents holds the schemas referenced by the operations.
+type OpenAPIComponents struct {
+	Schemas map[string]*jsonschema.Schema `json:"schemas"`
+}
+
+// errorSchemaName is the name of the schema of the error responses.
+const errorSchemaName = "ErrorResponse"
+
+var pathParamRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+// OpenAPI returns the OpenAPI document of the routes of the routers, served
+// at serverURL. The schemas of the bodies are inferred from the types in the
+// route docs, named struct types become components.
+func OpenAPI(title, version, serverURL string, routers ...Router) (*OpenAPIDocument, error) {
+	g := &openAPIGenerator{schemas: map[string]*jsonschema.Schema{}, types: map[string]reflect.Type{}}
+	doc := &OpenAPIDocument{
+		OpenAPI:    OpenAPIVersion,
+		Info:       OpenAPIInfo{Title: title, Version: version},
+		Paths:      map[string]PathItem{},
+		Components: OpenAPIComponents{Schemas: g.schemas},
+	}
+	if serverURL != "" {
+		doc.Servers = []OpenAPIServer{{URL: serverURL}}
+	}
+	errorSchema, err := g.schema(models.ErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+	operationIDs := map[string]bool{}
+	for _, router := range routers {
+		for _, route := range router.Routes() {
+			if operationIDs[route.Name] {
+				return nil, fmt.Errorf("duplicate route name %q", route.Name)
+			}
+			operationIDs[route.Name] = true
+			op, err := g.operation(route, errorSchema)
+			if err != nil {
+				return nil, fmt.Errorf("route %s: %w", route.Name, err)
+			}
+			item := doc.Paths[route.Pattern]
+			if item == nil {
+				item = PathItem{}
+				doc.Paths[route.Pattern] = item
+			}
+			for _, method := range route.Methods {
+				if method == http.MethodOptions {
+					// CORS preflight requests aren't API operations.
+					continue
+				}
+				item[strings.ToLower(method)] = op
+			}
+		}
+	}
+	return doc, nil
+}
+
+type openAPIGenerator struct {
+	schemas map[string]*jsonschema.Schema
+	types   map[string]reflect.Type
+}
+
+func (g *openAPIGenerator) operation(route Route, errorSchema *jsonschema.Schema) (*Operation, error) {
+	op := &Operation{
+		OperationID: route.Name,
+		Summary:     route.Doc.Summary,
+		Responses: map[string]*OpenAPIResponse{
+			"default": {
+				Description: "Error",
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			},
+		},
+	}
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(route.Pattern, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &jsonschema.Schema{Type: "string"},
+		})
+	}
+	for _, param := range route.Doc.Query {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Schema:      &jsonschema.Schema{Type: paramType},
+		})
+	}
+	if route.Doc.Request != nil {
+		schema, err := g.schema(route.Doc.Request)
+		if err != nil {
+			return nil, err
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: schema}},
+		}
+	}
+	ok := &OpenAPIResponse{Description: "OK"}
+	if route.Doc.Response != nil {
+		schema, err := g.schema(route.Doc.Response)
+		if err != nil {
+			return nil, err
+		}
+		if route.Doc.Streaming {
+			ok.Description = "A stream of Server-Sent Events. The data of the message events is a JSON " + refName(schema) +
+				". The stream ends with an error event, whose data is a JSON " + errorSchemaName + ", if the run fails," +
+				" or a shutdown event if the server shuts down."
+			ok.Content = map[string]MediaType{"text/event-stream": {Schema: &jsonschema.Schema{
+				Type:             "string",
+				ContentMediaType: "application/json",
+				ContentSchema:    schema,
+			}}}
+		} else {
+			ok.Content = map[string]MediaType{"application/json": {Schema: schema}}
+		}
+	}
+	op.Responses["200"] = ok
+	return op, nil
+}
+
+// schema returns the schema of the type of v. Named struct types are added
+// to the components and referenced.
+func (g *openAPIGenerator) schema(v any) (*jsonschema.Schema, error) {
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+func (g *openAPIGenerator) schemaFor(t reflect.Type) (*jsonschema.Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Slice:
+		items, err := g.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &jsonschema.Schema{Type: "array", Items: items}, nil
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := t.Name()
+		if other, ok := g.types[name]; ok {
+			if other != t {
+				return nil, fmt.Errorf("schema name %q is used by both %v and %v", name, other, t)
+			}
+			return &jsonschema.Schema{Ref: "#/components/schemas/" + name}, nil
+		}
+		schema, err := inferSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		g.types[name] = t
+		g.schemas[name] = schema
+		return &jsonschema.Schema{Ref: "#/components/schemas/" + name}, nil
+	default:
+		return inferSchema(t)
+	}
+}
+
+// inferSchema infers the schema of t, ignoring the fields which can't be
+// represented in JSON schema.
+func inferSchema(t reflect.Type) (*jsonschema.Schema, error) {
+	codes := []any{}
+	for _, code := range models.ErrorCodes {
+		codes = append(codes, string(code))
+	}
+	return jsonschema.ForType(t, &jsonschema.ForOptions{
+		IgnoreInvalidTypes: true,
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+			reflect.TypeFor[models.ErrorCode](): {Type: "string", Enum: codes},
+		},
+	})
+}
+
+// refName returns the name of the component referenced by schema, or of the
+// component referenced by its items.
+func refName(schema *jsonschema.Schema) string {
+	if schema.Items != nil {
+		return refName(schema.Items)
+	}
+	name, _ := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+	return name
+}
@@ -27,6 +27,32 @@ type Route struct {
 	Methods     []string
 	Pattern     string
 	HandlerFunc http.HandlerFunc
+	// Doc documents the endpoint in the OpenAPI document, see [OpenAPI].
+	Doc RouteDoc
+}
+
+// RouteDoc documents an api endpoint.
+type RouteDoc struct {
+	Summary string
+	// Query lists the query parameters of the endpoint.
+	Query []QueryParam
+	// Request is a value of the type of the JSON request body, nil if the
+	// endpoint takes no body.
+	Request any
+	// Response is a value of the type of the JSON response body, nil if the
+	// endpoint responds with no body. For streaming endpoints, it is the type
+	// of the data of the Server-Sent Events.
+	Response any
+	// Streaming is true if the endpoint responds with Server-Sent Events.
+	Streaming bool
+}
+
+// QueryParam documents a query parameter of an api endpoint.
+type QueryParam struct {
+	Name        string
+	Description string
+	// Type is the JSON schema type of the parameter, "string" by default.
+	Type string
 }
 
 // Routes is a list of defined api endpoints
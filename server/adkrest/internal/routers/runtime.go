@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
 // RuntimeAPIRouter defines the routes for the Runtime API.
@@ -38,12 +39,23 @@ func (r *RuntimeAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodPost, http.MethodOptions},
 			Pattern:     "/run",
 			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.RunHandler),
+			Doc: RouteDoc{
+				Summary:  "Run an agent and return the events of the run",
+				Request:  models.RunAgentRequest{},
+				Response: []models.Event{},
+			},
 		},
 		Route{
 			Name:        "RunAgentSse",
 			Methods:     []string{http.MethodPost, http.MethodOptions},
 			Pattern:     "/run_sse",
 			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.RunSSEHandler),
+			Doc: RouteDoc{
+				Summary:   "Run an agent and stream the events of the run as Server-Sent Events",
+				Request:   models.RunAgentRequest{},
+				Response:  models.Event{},
+				Streaming: true,
+			},
 		},
 	}
 }
@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
 // SessionsAPIRouter defines the routes for the Sessions API.
@@ -38,30 +39,68 @@ func (r *SessionsAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}",
 			HandlerFunc: r.sessionController.GetSessionHandler,
+			Doc: RouteDoc{
+				Summary:  "Get a session with its state and events",
+				Response: models.Session{},
+			},
 		},
 		Route{
 			Name:        "CreateSession",
 			Methods:     []string{http.MethodPost},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions",
 			HandlerFunc: r.sessionController.CreateSessionHandler,
+			Doc: RouteDoc{
+				Summary:  "Create a session with a generated id",
+				Request:  models.CreateSessionRequest{},
+				Response: models.Session{},
+			},
 		},
 		Route{
 			Name:        "CreateSessionWithId",
 			Methods:     []string{http.MethodPost},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}",
 			HandlerFunc: r.sessionController.CreateSessionHandler,
+			Doc: RouteDoc{
+				Summary:  "Create a session with the given id",
+				Request:  models.CreateSessionRequest{},
+				Response: models.Session{},
+			},
 		},
 		Route{
 			Name:        "DeleteSession",
 			Methods:     []string{http.MethodDelete, http.MethodOptions},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}",
 			HandlerFunc: r.sessionController.DeleteSessionHandler,
+			Doc: RouteDoc{
+				Summary: "Delete a session",
+			},
+		},
+		Route{
+			Name:        "ListSessionEvents",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/events",
+			HandlerFunc: r.sessionController.ListEventsHandler,
+			Doc: RouteDoc{
+				Summary: "List the events of a session annotated with their types",
+				Query: []QueryParam{
+					{Name: "types", Description: "Comma-separated event types to return, e.g. text,tool_call"},
+					{Name: "after", Description: "Return only the events after this RFC 3339 timestamp"},
+					{Name: "order", Description: "Order of the events, asc (default) or desc"},
+					{Name: "page_size", Description: "Maximum number of events to return", Type: "integer"},
+					{Name: "page_token", Description: "The next_page_token of the previous page"},
+				},
+				Response: models.EventsPage{},
+			},
 		},
 		Route{
 			Name:        "ListSessions",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions",
 			HandlerFunc: r.sessionController.ListSessionsHandler,
+			Doc: RouteDoc{
+				Summary:  "List the sessions of a user",
+				Response: []models.Session{},
+			},
 		},
 	}
 }
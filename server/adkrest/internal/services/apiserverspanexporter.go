@@ -15,35 +15,128 @@
 package services
 
 import (
+	"cmp"
 	"context"
+	"slices"
 	"strings"
+	"sync"
 
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+const (
+	sessionIDAttribute    = "gcp.vertex.agent.session_id"
+	invocationIDAttribute = "gcp.vertex.agent.invocation_id"
 )
 
 // APIServerSpanExporter is a custom SpanExporter that stores relevant span data.
 // Stores attributes of specific spans (call_llm, send_data, execute_tool) keyed by `gcp.vertex.agent.event_id`.
 // This is used for debugging individual events.
+// It also stores all the spans by trace, to return the traces of sessions and invocations.
 // APIServerSpanExporter implements sdktrace.SpanExporter interface.
 type APIServerSpanExporter struct {
+	mu        sync.RWMutex
 	traceDict map[string]map[string]string
+
+	traces           map[string][]models.Span // by trace id
+	sessionTraces    map[string][]string      // session id -> trace ids
+	invocationTraces map[string]string        // invocation id -> trace id
 }
 
 // NewAPIServerSpanExporter returns a APIServerSpanExporter instance
 func NewAPIServerSpanExporter() *APIServerSpanExporter {
 	return &APIServerSpanExporter{
-		traceDict: make(map[string]map[string]string),
+		traceDict:        make(map[string]map[string]string),
+		traces:           make(map[string][]models.Span),
+		sessionTraces:    make(map[string][]string),
+		invocationTraces: make(map[string]string),
 	}
 }
 
 // GetTraceDict returns stored trace informations
 func (s *APIServerSpanExporter) GetTraceDict() map[string]map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.traceDict
 }
 
+// SessionSpans returns the spans of the traces of the session, ordered by
+// start time.
+func (s *APIServerSpanExporter) SessionSpans(sessionID string) []models.Span {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	spans := []models.Span{}
+	for _, traceID := range s.sessionTraces[sessionID] {
+		spans = append(spans, resolveInvocations(s.traces[traceID])...)
+	}
+	sortByStartTime(spans)
+	return spans
+}
+
+// InvocationSpans returns the spans of the invocation, including the spans
+// of nested invocations, ordered by start time. It returns false if there
+// are no spans for the invocation.
+func (s *APIServerSpanExporter) InvocationSpans(invocationID string) ([]models.Span, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	traceID, ok := s.invocationTraces[invocationID]
+	if !ok {
+		return nil, false
+	}
+	traceSpans := resolveInvocations(s.traces[traceID])
+	bySpanID := make(map[string]models.Span, len(traceSpans))
+	for _, span := range traceSpans {
+		bySpanID[span.SpanID] = span
+	}
+	var spans []models.Span
+	for _, span := range traceSpans {
+		// Walk up to the span of the invocation, if the span belongs to it.
+		for ancestor, ok := span, true; ok; ancestor, ok = bySpanID[ancestor.ParentSpanID] {
+			if ancestor.Attributes[invocationIDAttribute] == invocationID {
+				spans = append(spans, span)
+				break
+			}
+		}
+	}
+	sortByStartTime(spans)
+	return spans, true
+}
+
+// resolveInvocations returns a copy of the spans of a trace with their
+// InvocationID set from the closest span with an invocation id.
+func resolveInvocations(traceSpans []models.Span) []models.Span {
+	bySpanID := make(map[string]models.Span, len(traceSpans))
+	for _, span := range traceSpans {
+		bySpanID[span.SpanID] = span
+	}
+	resolved := make([]models.Span, len(traceSpans))
+	for i, span := range traceSpans {
+		for ancestor, ok := span, true; ok; ancestor, ok = bySpanID[ancestor.ParentSpanID] {
+			if id, isString := ancestor.Attributes[invocationIDAttribute].(string); isString && id != "" {
+				span.InvocationID = id
+				break
+			}
+		}
+		resolved[i] = span
+	}
+	return resolved
+}
+
+func sortByStartTime(spans []models.Span) {
+	slices.SortStableFunc(spans, func(a, b models.Span) int {
+		return cmp.Compare(a.StartTime, b.StartTime)
+	})
+}
+
 // ExportSpans implements custom export function for sdktrace.SpanExporter.
 func (s *APIServerSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	for _, span := range spans {
+		s.storeSpan(span)
+
 		if span.Name() == "call_llm" || span.Name() == "send_data" || strings.HasPrefix(span.Name(), "execute_tool") {
 			spanAttributes := span.Attributes()
 			attributes := make(map[string]string)
@@ -61,6 +154,34 @@ func (s *APIServerSpanExporter) ExportSpans(ctx context.Context, spans []sdktrac
 	return nil
 }
 
+// storeSpan indexes the span by trace, and its trace by session and
+// invocation.
+func (s *APIServerSpanExporter) storeSpan(span sdktrace.ReadOnlySpan) {
+	traceID := span.SpanContext().TraceID().String()
+	stored := models.Span{
+		Name:       span.Name(),
+		TraceID:    traceID,
+		SpanID:     span.SpanContext().SpanID().String(),
+		StartTime:  span.StartTime().UnixNano(),
+		EndTime:    span.EndTime().UnixNano(),
+		Attributes: make(map[string]any, len(span.Attributes())),
+	}
+	if span.Parent().IsValid() {
+		stored.ParentSpanID = span.Parent().SpanID().String()
+	}
+	for _, attr := range span.Attributes() {
+		stored.Attributes[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	s.traces[traceID] = append(s.traces[traceID], stored)
+
+	if sessionID, ok := stored.Attributes[sessionIDAttribute].(string); ok && sessionID != "" && !slices.Contains(s.sessionTraces[sessionID], traceID) {
+		s.sessionTraces[sessionID] = append(s.sessionTraces[sessionID], traceID)
+	}
+	if invocationID, ok := stored.Attributes[invocationIDAttribute].(string); ok && invocationID != "" {
+		s.invocationTraces[invocationID] = traceID
+	}
+}
+
 // Shutdown is a function that sdktrace.SpanExporter has, should close the span exporter connections.
 // Since APIServerSpanExporter holds only in-memory dictionary, no additional logic required.
 func (s *APIServerSpanExporter) Shutdown(ctx context.Context) error {
@@ -18,9 +18,12 @@ import (
 	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"go.opentelemetry.io/otel/attribute"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
 // capturingExporter is a custom exporter that captures spans for testing.
@@ -148,3 +151,78 @@ func TestAPIServerSpanExporterShutdown(t *testing.T) {
 		t.Errorf("Shutdown() error = %v, wantErr nil", err)
 	}
 }
+
+func TestAPIServerSpanExporterSessionAndInvocationSpans(t *testing.T) {
+	ctx := t.Context()
+	exporter := NewAPIServerSpanExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := tp.Tracer("test-tracer")
+	start := func(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+		return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	}
+	session := func(id string) attribute.KeyValue { return attribute.String("gcp.vertex.agent.session_id", id) }
+	invocation := func(id string) attribute.KeyValue { return attribute.String("gcp.vertex.agent.invocation_id", id) }
+
+	// An invocation running a nested invocation from a tool.
+	invCtx, invSpan := start(ctx, "invocation", session("s1"), invocation("inv1"))
+	agentCtx, agentSpan := start(invCtx, "agent_run [root]", invocation("inv1"))
+	_, llmSpan := start(agentCtx, "call_llm", session("s1"), invocation("inv1"))
+	llmSpan.End()
+	toolCtx, toolSpan := start(agentCtx, "execute_tool helper")
+	nestedCtx, nestedSpan := start(toolCtx, "invocation", session("s2"), invocation("inv2"))
+	_, nestedLLMSpan := start(nestedCtx, "call_llm", session("s2"), invocation("inv2"))
+	nestedLLMSpan.End()
+	nestedSpan.End()
+	toolSpan.End()
+	agentSpan.End()
+	invSpan.End()
+	// A second invocation of the session.
+	_, secondSpan := start(ctx, "invocation", session("s1"), invocation("inv3"))
+	secondSpan.End()
+	// Unrelated spans.
+	_, otherSpan := start(ctx, "invocation", session("other"), invocation("inv4"))
+	otherSpan.End()
+
+	type spanInfo struct{ Name, InvocationID string }
+	infos := func(spans []models.Span) []spanInfo {
+		var got []spanInfo
+		for _, span := range spans {
+			got = append(got, spanInfo{span.Name, span.InvocationID})
+		}
+		return got
+	}
+
+	wantSession := []spanInfo{
+		{"invocation", "inv1"},
+		{"agent_run [root]", "inv1"},
+		{"call_llm", "inv1"},
+		{"execute_tool helper", "inv1"},
+		{"invocation", "inv2"},
+		{"call_llm", "inv2"},
+		{"invocation", "inv3"},
+	}
+	if diff := cmp.Diff(wantSession, infos(exporter.SessionSpans("s1"))); diff != "" {
+		t.Errorf("SessionSpans() mismatch (-want +got):\n%s", diff)
+	}
+	if got := exporter.SessionSpans("unknown"); got == nil || len(got) != 0 {
+		t.Errorf("SessionSpans() for an unknown session = %v, want an empty list", got)
+	}
+
+	spans, ok := exporter.InvocationSpans("inv2")
+	if !ok {
+		t.Fatal("InvocationSpans() found no spans for the nested invocation")
+	}
+	wantNested := []spanInfo{{"invocation", "inv2"}, {"call_llm", "inv2"}}
+	if diff := cmp.Diff(wantNested, infos(spans)); diff != "" {
+		t.Errorf("InvocationSpans() mismatch (-want +got):\n%s", diff)
+	}
+	if spans[1].ParentSpanID != spans[0].SpanID || spans[0].StartTime > spans[1].StartTime || spans[1].EndTime == 0 {
+		t.Errorf("InvocationSpans() = %+v, want the nested call_llm span as child of the invocation", spans)
+	}
+	if spans, _ := exporter.InvocationSpans("inv1"); len(spans) != 6 {
+		t.Errorf("InvocationSpans() returned %d spans, want 6 including the nested invocation", len(spans))
+	}
+	if _, ok := exporter.InvocationSpans("unknown"); ok {
+		t.Error("InvocationSpans() found spans for an unknown invocation")
+	}
+}
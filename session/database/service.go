@@ -154,8 +154,10 @@ func (s *databaseService) Get(ctx context.Context, req *session.GetRequest) (*se
 			ID:      sessionID,
 		}).
 		First(&foundSession).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("session %q: %w", sessionID, session.ErrSessionNotFound)
+	}
 	if err != nil {
-		// For any error including ErrRecordNotFound, return it as a system error.
 		return nil, fmt.Errorf("database error while fetching session: %w", err)
 	}
 
@@ -218,6 +220,61 @@ func (s *databaseService) Get(ctx context.Context, req *session.GetRequest) (*se
 	}, nil
 }
 
+// ListEvents retrieves a page of the events of a session, without loading the session state.
+func (s *databaseService) ListEvents(ctx context.Context, req *session.ListEventsRequest) (*session.ListEventsResponse, error) {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	if req.Limit < 0 {
+		return nil, fmt.Errorf("limit can't be negative, got %d", req.Limit)
+	}
+
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&storageSession{}).
+		Where(&storageSession{AppName: appName, UserID: userID, ID: sessionID}).
+		Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error while fetching session: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("session %q: %w", sessionID, session.ErrSessionNotFound)
+	}
+
+	eventQuery := s.db.WithContext(ctx).
+		Model(&storageEvent{}).
+		Where("app_name = ?", appName).
+		Where("user_id = ?", userID).
+		Where("session_id = ?", sessionID)
+	if !req.AfterTimestamp.IsZero() {
+		eventQuery = eventQuery.Where("timestamp > ?", req.AfterTimestamp)
+	}
+	if req.AscendingOrder {
+		eventQuery = eventQuery.Order("timestamp ASC")
+	} else {
+		eventQuery = eventQuery.Order("timestamp DESC")
+	}
+	if req.Limit > 0 {
+		eventQuery = eventQuery.Limit(req.Limit)
+	}
+
+	var storageEvents []storageEvent
+	if err := eventQuery.Find(&storageEvents).Error; err != nil {
+		return nil, fmt.Errorf("database error while fetching events: %w", err)
+	}
+
+	events := make([]*session.Event, 0, len(storageEvents))
+	for i := range storageEvents {
+		evt, err := createEventFromStorageEvent(&storageEvents[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to map storage event: %w", err)
+		}
+		events = append(events, evt)
+	}
+	return &session.ListEventsResponse{Events: events}, nil
+}
+
 // List retrieves sessions from the database using its appName and optional UserID
 func (s *databaseService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
 	appName, userID := req.AppName, req.UserID
@@ -15,6 +15,7 @@
 package database
 
 import (
+	"errors"
 	"maps"
 	"strconv"
 	"testing"
@@ -367,6 +368,98 @@ func Test_databaseService_Get(t *testing.T) {
 	}
 }
 
+func Test_databaseService_Get_NotFound(t *testing.T) {
+	s := serviceDbWithData(t)
+	_, err := s.Get(t.Context(), &session.GetRequest{
+		AppName:   "app1",
+		UserID:    "user1",
+		SessionID: "missing",
+	})
+	if !errors.Is(err, session.ErrSessionNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, session.ErrSessionNotFound)
+	}
+}
+
+func Test_databaseService_ListEvents(t *testing.T) {
+	ctx := t.Context()
+	s := emptyService(t)
+	created, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	start := time.Now().Truncate(time.Second)
+	for i := range 5 {
+		event := &session.Event{
+			ID:          strconv.Itoa(i + 1),
+			Author:      "user",
+			Timestamp:   start.Add(time.Duration(i) * time.Second),
+			Actions:     session.EventActions{StateDelta: map[string]any{}},
+			LLMResponse: model.LLMResponse{},
+		}
+		if err := s.AppendEvent(ctx, created.Session, event); err != nil {
+			t.Fatalf("AppendEvent() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     *session.ListEventsRequest
+		wantIDs []string
+	}{
+		{
+			name:    "all events most recent first",
+			req:     &session.ListEventsRequest{},
+			wantIDs: []string{"5", "4", "3", "2", "1"},
+		},
+		{
+			name:    "ascending order",
+			req:     &session.ListEventsRequest{AscendingOrder: true},
+			wantIDs: []string{"1", "2", "3", "4", "5"},
+		},
+		{
+			name:    "first page",
+			req:     &session.ListEventsRequest{AscendingOrder: true, Limit: 2},
+			wantIDs: []string{"1", "2"},
+		},
+		{
+			name:    "next page after the last event",
+			req:     &session.ListEventsRequest{AscendingOrder: true, Limit: 2, AfterTimestamp: start.Add(time.Second)},
+			wantIDs: []string{"3", "4"},
+		},
+		{
+			name:    "most recent events",
+			req:     &session.ListEventsRequest{Limit: 2},
+			wantIDs: []string{"5", "4"},
+		},
+		{
+			name:    "after the last event",
+			req:     &session.ListEventsRequest{AfterTimestamp: start.Add(4 * time.Second)},
+			wantIDs: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.AppName, tt.req.UserID, tt.req.SessionID = "app", "user", "s1"
+			resp, err := s.ListEvents(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ListEvents() error = %v", err)
+			}
+			var gotIDs []string
+			for _, event := range resp.Events {
+				gotIDs = append(gotIDs, event.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ListEvents() event IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	_, err = s.ListEvents(ctx, &session.ListEventsRequest{AppName: "app", UserID: "user", SessionID: "missing"})
+	if !errors.Is(err, session.ErrSessionNotFound) {
+		t.Errorf("ListEvents() error = %v, want %v", err, session.ErrSessionNotFound)
+	}
+}
+
 func Test_databaseService_List(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -80,6 +80,7 @@ type storageEvent struct {
 	Actions                []byte
 	LongRunningToolIDsJSON dynamicJSON
 	Branch                 *string
+	ParentFunctionCallID   *string
 	Timestamp              time.Time
 
 	// Fields from llm_response
@@ -88,6 +89,7 @@ type storageEvent struct {
 	CustomMetadata    dynamicJSON
 	UsageMetadata     dynamicJSON
 	CitationMetadata  dynamicJSON
+	InvocationUsage   dynamicJSON
 
 	Partial      *bool
 	TurnComplete *bool
@@ -140,6 +142,9 @@ func createStorageEvent(session session.Session, event *session.Event) (*storage
 	if event.Branch != "" {
 		storageEv.Branch = &event.Branch
 	}
+	if event.ParentFunctionCallID != "" {
+		storageEv.ParentFunctionCallID = &event.ParentFunctionCallID
+	}
 	if event.ErrorCode != "" {
 		storageEv.ErrorCode = &event.ErrorCode
 	}
@@ -183,6 +188,12 @@ func createStorageEvent(session session.Session, event *session.Event) (*storage
 			return nil, fmt.Errorf("failed to marshal citation metadata: %w", err)
 		}
 	}
+	if event.InvocationUsage != nil {
+		storageEv.InvocationUsage, err = json.Marshal(event.InvocationUsage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal invocation usage: %w", err)
+		}
+	}
 
 	return storageEv, nil
 }
@@ -242,6 +253,13 @@ func createEventFromStorageEvent(se *storageEvent) (*session.Event, error) {
 		}
 	}
 
+	var invocationUsage *genai.GenerateContentResponseUsageMetadata
+	if len(se.InvocationUsage) > 0 {
+		if err := json.Unmarshal(se.InvocationUsage, &invocationUsage); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal invocation usage: %w", err)
+		}
+	}
+
 	// --- Handle JSON-encoded *string field ---
 	var toolIDs []string
 	if se.LongRunningToolIDsJSON != nil {
@@ -261,13 +279,15 @@ func createEventFromStorageEvent(se *storageEvent) (*session.Event, error) {
 
 	// --- Assemble the final Event struct ---
 	event := &session.Event{
-		ID:                 se.ID,
-		InvocationID:       se.InvocationID,
-		Author:             se.Author,
-		Timestamp:          se.Timestamp,
-		Actions:            actions,
-		LongRunningToolIDs: toolIDs,
-		Branch:             branch,
+		ID:                   se.ID,
+		InvocationID:         se.InvocationID,
+		Author:               se.Author,
+		Timestamp:            se.Timestamp,
+		Actions:              actions,
+		LongRunningToolIDs:   toolIDs,
+		Branch:               branch,
+		ParentFunctionCallID: derefOrZero(se.ParentFunctionCallID),
+		InvocationUsage:      invocationUsage,
 		LLMResponse: model.LLMResponse{
 			Content:           content,
 			GroundingMetadata: groundingMetadata,
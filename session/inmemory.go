@@ -15,6 +15,7 @@
 package session
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"iter"
@@ -37,10 +38,20 @@ type stateMap map[string]any
 // inMemoryService is an in-memory implementation of sessionService.Service.
 // Thread-safe.
 type inMemoryService struct {
+	opts inMemoryOptions
+
 	mu        sync.RWMutex
 	sessions  omap.Map[string, *session] // session.ID) -> storedSession
 	userState map[string]map[string]stateMap
 	appState  map[string]stateMap
+
+	// lru orders the encoded session keys from the most to the least recently
+	// used, when the number of sessions is capped. It is guarded by lruMu, as
+	// well as by mu held for writing, so that reads holding mu for reading can
+	// update it.
+	lruMu    sync.Mutex
+	lru      *list.List
+	lruElems map[string]*list.Element
 }
 
 func (s *inMemoryService) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
@@ -59,10 +70,24 @@ func (s *inMemoryService) Create(ctx context.Context, req *CreateRequest) (*Crea
 		sessionID: sessionID,
 	}
 
+	s.mu.Lock()
+	evicted, resp, err := s.create(key, req)
+	s.mu.Unlock()
+	if s.opts.onEvict != nil {
+		for _, sess := range evicted {
+			s.opts.onEvict(sess)
+		}
+	}
+	return resp, err
+}
+
+// create creates the session and returns the sessions evicted to make room
+// for it. The caller must hold s.mu for writing.
+func (s *inMemoryService) create(key id, req *CreateRequest) ([]Session, *CreateResponse, error) {
 	encodedKey := key.Encode()
 	_, ok := s.sessions.Get(encodedKey)
 	if ok {
-		return nil, fmt.Errorf("session %s already exists", req.SessionID)
+		return nil, nil, fmt.Errorf("session %s already exists", req.SessionID)
 	}
 
 	state := req.State
@@ -75,10 +100,9 @@ func (s *inMemoryService) Create(ctx context.Context, req *CreateRequest) (*Crea
 		updatedAt: time.Now(),
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	s.sessions.Set(encodedKey, val)
+	s.touch(encodedKey)
+	evicted := s.evict()
 	appDelta, userDelta, _ := sessionutils.ExtractStateDeltas(req.State)
 	appState := s.updateAppState(appDelta, req.AppName)
 	userState := s.updateUserState(userDelta, req.AppName, req.UserID)
@@ -88,7 +112,7 @@ func (s *inMemoryService) Create(ctx context.Context, req *CreateRequest) (*Crea
 	copiedSession.state = maps.Clone(val.state)
 	copiedSession.events = slices.Clone(val.events)
 
-	return &CreateResponse{
+	return evicted, &CreateResponse{
 		Session: copiedSession,
 	}, nil
 }
@@ -110,8 +134,9 @@ func (s *inMemoryService) Get(ctx context.Context, req *GetRequest) (*GetRespons
 
 	res, ok := s.sessions.Get(id.Encode())
 	if !ok {
-		return nil, fmt.Errorf("session %+v not found", req.SessionID)
+		return nil, fmt.Errorf("session %q: %w", req.SessionID, ErrSessionNotFound)
 	}
+	s.touch(id.Encode())
 
 	copiedSession := copySessionWithoutStateAndEvents(res)
 	copiedSession.state = s.mergeStates(res.state, appName, userID)
@@ -139,6 +164,53 @@ func (s *inMemoryService) Get(ctx context.Context, req *GetRequest) (*GetRespons
 	}, nil
 }
 
+func (s *inMemoryService) ListEvents(ctx context.Context, req *ListEventsRequest) (*ListEventsResponse, error) {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	if req.Limit < 0 {
+		return nil, fmt.Errorf("limit can't be negative, got %d", req.Limit)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id := id{
+		appName:   appName,
+		userID:    userID,
+		sessionID: sessionID,
+	}
+	res, ok := s.sessions.Get(id.Encode())
+	if !ok {
+		return nil, fmt.Errorf("session %q: %w", req.SessionID, ErrSessionNotFound)
+	}
+	s.touch(id.Encode())
+
+	// apply timestamp filter, assuming list is sorted
+	events := res.events
+	if !req.AfterTimestamp.IsZero() {
+		firstIndexToKeep := sort.Search(len(events), func(i int) bool {
+			return events[i].Timestamp.After(req.AfterTimestamp)
+		})
+		events = events[firstIndexToKeep:]
+	}
+
+	if req.Limit > 0 && len(events) > req.Limit {
+		if req.AscendingOrder {
+			events = events[:req.Limit]
+		} else {
+			events = events[len(events)-req.Limit:]
+		}
+	}
+
+	result := slices.Clone(events)
+	if !req.AscendingOrder {
+		slices.Reverse(result)
+	}
+	return &ListEventsResponse{Events: result}, nil
+}
+
 func (s *inMemoryService) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
 	appName, userID := req.AppName, req.UserID
 	if appName == "" {
@@ -192,6 +264,7 @@ func (s *inMemoryService) Delete(ctx context.Context, req *DeleteRequest) error
 	}
 
 	s.sessions.Delete(id.Encode())
+	s.forget(id.Encode())
 	return nil
 }
 
@@ -216,7 +289,7 @@ func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, e
 
 	stored_session, ok := s.sessions.Get(sess.id.Encode())
 	if !ok {
-		return fmt.Errorf("session not found, cannot apply event")
+		return fmt.Errorf("session %q: cannot apply event: %w", sess.id.sessionID, ErrSessionNotFound)
 	}
 
 	// update the in-memory session
@@ -226,7 +299,13 @@ func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, e
 
 	// update the in-memory session service
 	stored_session.events = append(stored_session.events, event)
+	if s.opts.maxEvents > 0 && len(stored_session.events) > s.opts.maxEvents {
+		// Copy the kept events, so that the backing array doesn't hold on to
+		// the dropped ones.
+		stored_session.events = slices.Clone(stored_session.events[len(stored_session.events)-s.opts.maxEvents:])
+	}
 	stored_session.updatedAt = event.Timestamp
+	s.touch(sess.id.Encode())
 	if len(event.Actions.StateDelta) > 0 {
 		appDelta, userDelta, sessionDelta := sessionutils.ExtractStateDeltas(event.Actions.StateDelta)
 		s.updateAppState(appDelta, curSession.AppName())
@@ -236,6 +315,52 @@ func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, e
 	return nil
 }
 
+// touch marks the session with the encoded key as the most recently used.
+// The caller must hold s.mu.
+func (s *inMemoryService) touch(key string) {
+	if s.lru == nil {
+		return
+	}
+	s.lruMu.Lock()
+	defer s.lruMu.Unlock()
+
+	if elem, ok := s.lruElems[key]; ok {
+		s.lru.MoveToFront(elem)
+		return
+	}
+	s.lruElems[key] = s.lru.PushFront(key)
+}
+
+// forget removes the session with the encoded key from the LRU list.
+// The caller must hold s.mu for writing.
+func (s *inMemoryService) forget(key string) {
+	if s.lru == nil {
+		return
+	}
+	if elem, ok := s.lruElems[key]; ok {
+		s.lru.Remove(elem)
+		delete(s.lruElems, key)
+	}
+}
+
+// evict deletes the least recently used sessions above the maximum number
+// of sessions and returns them. The caller must hold s.mu for writing.
+func (s *inMemoryService) evict() []Session {
+	if s.lru == nil {
+		return nil
+	}
+	var evicted []Session
+	for s.lru.Len() > s.opts.maxSessions {
+		key := s.lru.Remove(s.lru.Back()).(string)
+		delete(s.lruElems, key)
+		if sess, ok := s.sessions.Get(key); ok {
+			s.sessions.Delete(key)
+			evicted = append(evicted, sess)
+		}
+	}
+	return evicted
+}
+
 func (s *inMemoryService) updateAppState(appDelta stateMap, appName string) stateMap {
 	innerMap, ok := s.appState[appName]
 	if !ok {
@@ -314,8 +439,13 @@ func (s *session) State() State {
 	}
 }
 
+// Events returns a snapshot of the events, so that it can be used while
+// events are appended to the session concurrently, e.g. by parallel agents.
 func (s *session) Events() Events {
-	return events(s.events)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return events(slices.Clone(s.events))
 }
 
 func (s *session) LastUpdateTime() time.Time {
@@ -331,10 +461,13 @@ func (s *session) appendEvent(event *Event) error {
 	}
 
 	processedEvent := trimTempDeltaState(event)
+	// The state guards itself with s.mu.
 	if err := updateSessionState(s, processedEvent); err != nil {
 		return fmt.Errorf("error on appendEvent: %w", err)
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.events = append(s.events, event)
 	s.updatedAt = event.Timestamp
 	return nil
@@ -431,9 +564,11 @@ func updateSessionState(session *session, event *Event) error {
 	}
 
 	// ensure the session state map is initialized
+	session.mu.Lock()
 	if session.state == nil {
 		session.state = make(map[string]any)
 	}
+	session.mu.Unlock()
 
 	state := session.State()
 	for key, value := range event.Actions.StateDelta {
@@ -460,3 +595,4 @@ func copySessionWithoutStateAndEvents(sess *session) *session {
 }
 
 var _ Service = (*inMemoryService)(nil)
+var _ EventLister = (*inMemoryService)(nil)
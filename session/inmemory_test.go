@@ -15,10 +15,13 @@
 package session
 
 import (
+	"errors"
 	"maps"
+	"runtime"
 	"strconv"
 	"testing"
 	"time"
+	"weak"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -369,6 +372,98 @@ func Test_databaseService_Get(t *testing.T) {
 	}
 }
 
+func Test_databaseService_Get_NotFound(t *testing.T) {
+	s := serviceDbWithData(t)
+	_, err := s.Get(t.Context(), &GetRequest{
+		AppName:   "app1",
+		UserID:    "user1",
+		SessionID: "missing",
+	})
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func Test_databaseService_ListEvents(t *testing.T) {
+	ctx := t.Context()
+	s := emptyService(t).(*inMemoryService)
+	created, err := s.Create(ctx, &CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	start := time.Now().Truncate(time.Second)
+	for i := range 5 {
+		event := &Event{
+			ID:          strconv.Itoa(i + 1),
+			Author:      "user",
+			Timestamp:   start.Add(time.Duration(i) * time.Second),
+			Actions:     EventActions{StateDelta: map[string]any{}},
+			LLMResponse: model.LLMResponse{},
+		}
+		if err := s.AppendEvent(ctx, created.Session, event); err != nil {
+			t.Fatalf("AppendEvent() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     *ListEventsRequest
+		wantIDs []string
+	}{
+		{
+			name:    "all events most recent first",
+			req:     &ListEventsRequest{},
+			wantIDs: []string{"5", "4", "3", "2", "1"},
+		},
+		{
+			name:    "ascending order",
+			req:     &ListEventsRequest{AscendingOrder: true},
+			wantIDs: []string{"1", "2", "3", "4", "5"},
+		},
+		{
+			name:    "first page",
+			req:     &ListEventsRequest{AscendingOrder: true, Limit: 2},
+			wantIDs: []string{"1", "2"},
+		},
+		{
+			name:    "next page after the last event",
+			req:     &ListEventsRequest{AscendingOrder: true, Limit: 2, AfterTimestamp: start.Add(time.Second)},
+			wantIDs: []string{"3", "4"},
+		},
+		{
+			name:    "most recent events",
+			req:     &ListEventsRequest{Limit: 2},
+			wantIDs: []string{"5", "4"},
+		},
+		{
+			name:    "after the last event",
+			req:     &ListEventsRequest{AfterTimestamp: start.Add(4 * time.Second)},
+			wantIDs: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.AppName, tt.req.UserID, tt.req.SessionID = "app", "user", "s1"
+			resp, err := s.ListEvents(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ListEvents() error = %v", err)
+			}
+			var gotIDs []string
+			for _, event := range resp.Events {
+				gotIDs = append(gotIDs, event.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ListEvents() event IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	_, err = s.ListEvents(ctx, &ListEventsRequest{AppName: "app", UserID: "user", SessionID: "missing"})
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ListEvents() error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
 func Test_databaseService_List(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1001,3 +1096,173 @@ func emptyService(t *testing.T) Service {
 }
 
 // TODO: test concurrency
+
+func TestInMemoryService_MaxSessions(t *testing.T) {
+	var evicted []string
+	s := InMemoryService(WithMaxSessions(2), WithEvictionCallback(func(sess Session) {
+		evicted = append(evicted, sess.ID())
+	}))
+	create := func(id string) Session {
+		t.Helper()
+		resp, err := s.Create(t.Context(), &CreateRequest{AppName: "app", UserID: "user", SessionID: id})
+		if err != nil {
+			t.Fatalf("Create(%q) error = %v", id, err)
+		}
+		return resp.Session
+	}
+
+	create("s1")
+	s2 := create("s2")
+	// Using s1 makes s2 the least recently used session.
+	if _, err := s.Get(t.Context(), &GetRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err != nil {
+		t.Fatalf("Get(s1) error = %v", err)
+	}
+	create("s3")
+	if diff := cmp.Diff([]string{"s2"}, evicted); diff != "" {
+		t.Errorf("evicted sessions mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.Get(t.Context(), &GetRequest{AppName: "app", UserID: "user", SessionID: "s2"}); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Get(s2) error = %v, want %v", err, ErrSessionNotFound)
+	}
+	if err := s.AppendEvent(t.Context(), s2, &Event{Timestamp: time.Now()}); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("AppendEvent() to an evicted session error = %v, want %v", err, ErrSessionNotFound)
+	}
+
+	// Appending an event to s1 makes s3 the least recently used session.
+	resp, err := s.Get(t.Context(), &GetRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Get(s1) error = %v", err)
+	}
+	if err := s.AppendEvent(t.Context(), resp.Session, &Event{Timestamp: time.Now()}); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+	// Deleting a session makes room for another one.
+	if err := s.Delete(t.Context(), &DeleteRequest{AppName: "app", UserID: "user", SessionID: "s3"}); err != nil {
+		t.Fatalf("Delete(s3) error = %v", err)
+	}
+	create("s4")
+	create("s5")
+	if diff := cmp.Diff([]string{"s2", "s1"}, evicted); diff != "" {
+		t.Errorf("evicted sessions mismatch (-want +got):\n%s", diff)
+	}
+
+	list, err := s.List(t.Context(), &ListRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var got []string
+	for _, sess := range list.Sessions {
+		got = append(got, sess.ID())
+	}
+	if diff := cmp.Diff([]string{"s4", "s5"}, got); diff != "" {
+		t.Errorf("listed sessions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInMemoryService_MaxEventsPerSession(t *testing.T) {
+	s := InMemoryService(WithMaxEventsPerSession(2))
+	resp, err := s.Create(t.Context(), &CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for i := range 3 {
+		event := &Event{
+			ID:        strconv.Itoa(i),
+			Timestamp: time.Now(),
+			Actions:   EventActions{StateDelta: map[string]any{"k" + strconv.Itoa(i): i}},
+		}
+		if err := s.AppendEvent(t.Context(), resp.Session, event); err != nil {
+			t.Fatalf("AppendEvent() error = %v", err)
+		}
+	}
+
+	got, err := s.Get(t.Context(), &GetRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var ids []string
+	for event := range got.Session.Events().All() {
+		ids = append(ids, event.ID)
+	}
+	if diff := cmp.Diff([]string{"1", "2"}, ids); diff != "" {
+		t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+	}
+	// The state changes of the dropped event are kept.
+	if v, err := got.Session.State().Get("k0"); err != nil || v != 0 {
+		t.Errorf("State().Get(k0) = %v, %v, want 0", v, err)
+	}
+}
+
+func TestInMemoryService_MaxEventsPerSessionReleasesEvents(t *testing.T) {
+	s := InMemoryService(WithMaxEventsPerSession(2))
+	// The events are appended in another function, so that the session it
+	// uses, which keeps all its events, is unreachable afterwards.
+	dropped := func() weak.Pointer[Event] {
+		resp, err := s.Create(t.Context(), &CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		first := &Event{ID: "0", Timestamp: time.Now()}
+		for _, event := range []*Event{first, {ID: "1", Timestamp: time.Now()}, {ID: "2", Timestamp: time.Now()}} {
+			if err := s.AppendEvent(t.Context(), resp.Session, event); err != nil {
+				t.Fatalf("AppendEvent() error = %v", err)
+			}
+		}
+		return weak.Make(first)
+	}()
+
+	runtime.GC()
+	if dropped.Value() != nil {
+		t.Error("dropped event is still reachable, want it garbage collected")
+	}
+	runtime.KeepAlive(s)
+}
+
+func TestInMemoryService_ConcurrentAppendEvent(t *testing.T) {
+	const n = 100
+	s := InMemoryService()
+	resp, err := s.Create(t.Context(), &CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	sess := resp.Session
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range n {
+			event := &Event{
+				ID:        strconv.Itoa(i),
+				Timestamp: time.Now(),
+				Actions:   EventActions{StateDelta: map[string]any{"k": i}},
+			}
+			if err := s.AppendEvent(t.Context(), sess, event); err != nil {
+				t.Errorf("AppendEvent() error = %v", err)
+				return
+			}
+		}
+	}()
+	// The session is read while the events are appended, the race detector
+	// reports unguarded accesses.
+	snapshot := sess.Events()
+	before := snapshot.Len()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		for range sess.Events().All() {
+		}
+		_ = sess.LastUpdateTime()
+		_, _ = sess.State().Get("k")
+	}
+
+	if got := sess.Events().Len(); got != n {
+		t.Errorf("Events().Len() = %d, want %d", got, n)
+	}
+	// Events returns a copy, which the next events don't change.
+	if got := snapshot.Len(); got != before {
+		t.Errorf("Events().Len() of an earlier copy = %d, want %d", got, before)
+	}
+}
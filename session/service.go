@@ -15,6 +15,7 @@
 package session
 
 import (
+	"container/list"
 	"context"
 	"time"
 )
@@ -32,11 +33,73 @@ type Service interface {
 }
 
 // InMemoryService returns an in-memory implementation of the session service.
-func InMemoryService() Service {
-	return &inMemoryService{
+//
+// By default it keeps all the sessions and events until they are deleted.
+// Use [WithMaxSessions] and [WithMaxEventsPerSession] to bound its memory use
+// in long-running servers.
+func InMemoryService(opts ...Option) Service {
+	s := &inMemoryService{
 		appState:  make(map[string]stateMap),
 		userState: make(map[string]map[string]stateMap),
 	}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	if s.opts.maxSessions > 0 {
+		s.lru = list.New()
+		s.lruElems = make(map[string]*list.Element)
+	}
+	return s
+}
+
+// Option configures a service created with [InMemoryService].
+type Option func(*inMemoryOptions)
+
+type inMemoryOptions struct {
+	maxSessions int
+	maxEvents   int
+	onEvict     func(Session)
+}
+
+// WithMaxSessions caps the number of sessions kept by the service. When a
+// new session exceeds the limit, the least recently used session is
+// evicted, as if it was deleted. Creating a session, getting it, listing its
+// events and appending an event to it count as uses. Zero or less means no
+// limit.
+//
+// A session may be evicted while a run still uses it, e.g. when many
+// sessions are created during a long run. Appending an event to an evicted
+// session then fails with an error wrapping [ErrSessionNotFound], and the
+// run fails. Pick a limit well above the number of concurrent runs.
+func WithMaxSessions(n int) Option {
+	return func(o *inMemoryOptions) {
+		o.maxSessions = n
+	}
+}
+
+// WithMaxEventsPerSession caps the number of events kept for each session.
+// When an appended event exceeds the limit, the oldest events of the session
+// are dropped. The state changes made by the dropped events are kept. Zero
+// or less means no limit.
+func WithMaxEventsPerSession(n int) Option {
+	return func(o *inMemoryOptions) {
+		o.maxEvents = n
+	}
+}
+
+// WithEvictionCallback sets a function called with each session evicted
+// because of [WithMaxSessions], e.g. to log it. It is called after the
+// eviction, so it may use the service.
+func WithEvictionCallback(f func(Session)) Option {
+	return func(o *inMemoryOptions) {
+		o.onEvict = f
+	}
+}
+
+// EventLister is implemented by session services which can list the events
+// of a session page by page, without loading all of them like [Service.Get].
+type EventLister interface {
+	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
 }
 
 // CreateRequest represents a request to create a session.
@@ -85,6 +148,31 @@ type ListResponse struct {
 	Sessions []Session
 }
 
+// ListEventsRequest represents a request to list the events of a session.
+//
+// To page through the events, pass the timestamp of the last returned event
+// as AfterTimestamp of the next request with AscendingOrder set.
+type ListEventsRequest struct {
+	AppName   string
+	UserID    string
+	SessionID string
+
+	// AfterTimestamp returns events with timestamp > the given time.
+	// Optional: if zero, the filter is not applied.
+	AfterTimestamp time.Time
+	// Limit returns at most Limit events.
+	// Optional: if zero, the limit is not applied.
+	Limit int
+	// AscendingOrder returns the oldest events first. By default the most
+	// recent events are returned first.
+	AscendingOrder bool
+}
+
+// ListEventsResponse represents a response from [EventLister.ListEvents].
+type ListEventsResponse struct {
+	Events []*Event
+}
+
 // DeleteRequest represents a request to delete a session.
 type DeleteRequest struct {
 	AppName   string
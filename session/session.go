@@ -17,10 +17,13 @@ package session
 import (
 	"errors"
 	"iter"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/genai"
 
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/model"
 )
 
@@ -114,6 +117,15 @@ type Event struct {
 	// Agent client will know from this field about which function call is long running.
 	// Only valid for function call event.
 	LongRunningToolIDs []string
+	// InvocationUsage is the token usage aggregated over the invocation up to
+	// and including this event. It is set on the final response of each agent.
+	InvocationUsage *genai.GenerateContentResponseUsageMetadata
+	// ParentFunctionCallID is set on the events of an agent run by a tool,
+	// e.g. agenttool, forwarded to the invocation calling the tool. It is the
+	// ID of the function call of the tool. These events report the progress
+	// of the tool, they aren't part of the conversation history of the
+	// calling agent.
+	ParentFunctionCallID string
 }
 
 // IsFinalResponse returns whether the event is the final response of an agent.
@@ -128,6 +140,52 @@ func (e *Event) IsFinalResponse() bool {
 	return !hasFunctionCalls(&e.LLMResponse) && !hasFunctionResponses(&e.LLMResponse) && !e.LLMResponse.Partial && !hasTrailingCodeExecutionResult(&e.LLMResponse)
 }
 
+// Text returns the concatenated text of the event content parts. It returns
+// an empty string for a nil event or an event without content, e.g. one
+// carrying only state changes.
+func (e *Event) Text() string {
+	if e == nil || e.LLMResponse.Content == nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, p := range e.LLMResponse.Content.Parts {
+		if p != nil {
+			text.WriteString(p.Text)
+		}
+	}
+	return text.String()
+}
+
+// FunctionCalls returns the function calls in the event content parts, or nil
+// if there are none.
+func (e *Event) FunctionCalls() []*genai.FunctionCall {
+	if e == nil || e.LLMResponse.Content == nil {
+		return nil
+	}
+	var calls []*genai.FunctionCall
+	for _, p := range e.LLMResponse.Content.Parts {
+		if p != nil && p.FunctionCall != nil {
+			calls = append(calls, p.FunctionCall)
+		}
+	}
+	return calls
+}
+
+// FunctionResponses returns the function responses in the event content
+// parts, or nil if there are none.
+func (e *Event) FunctionResponses() []*genai.FunctionResponse {
+	if e == nil || e.LLMResponse.Content == nil {
+		return nil
+	}
+	var responses []*genai.FunctionResponse
+	for _, p := range e.LLMResponse.Content.Parts {
+		if p != nil && p.FunctionResponse != nil {
+			responses = append(responses, p.FunctionResponse)
+		}
+	}
+	return responses
+}
+
 // NewEvent creates a new event defining now as the timestamp.
 func NewEvent(invocationID string) *Event {
 	return &Event{
@@ -154,6 +212,22 @@ type EventActions struct {
 	TransferToAgent string
 	// The agent is escalating to a higher level agent.
 	Escalate bool
+	// Compaction, if set, summarizes earlier events of the session. When
+	// building model requests, the summary replaces the compacted events.
+	Compaction *EventCompaction
+	// RequestedAuthConfigs holds the credentials requested by tools, keyed by
+	// the ID of the function call. Only valid for function response events.
+	RequestedAuthConfigs map[string]*auth.Config
+}
+
+// EventCompaction is a summary of a range of session events.
+type EventCompaction struct {
+	// StartTimestamp is the timestamp of the first compacted event.
+	StartTimestamp time.Time
+	// EndTimestamp is the timestamp of the last compacted event.
+	EndTimestamp time.Time
+	// CompactedContent is the summary replacing the compacted events.
+	CompactedContent *genai.Content
 }
 
 // Prefixes for defining session's state scopes
@@ -175,6 +249,11 @@ const (
 // ErrStateKeyNotExist is the error thrown when key does not exist.
 var ErrStateKeyNotExist = errors.New("state key does not exist")
 
+// ErrSessionNotFound is the error returned by [Service.Get] when the session
+// does not exist. The in-memory service also returns it from
+// [Service.AppendEvent] when the session was deleted or evicted.
+var ErrSessionNotFound = errors.New("session not found")
+
 func hasFunctionCalls(resp *model.LLMResponse) bool {
 	if resp == nil || resp.Content == nil {
 		return false
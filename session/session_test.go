@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestEvent_Text(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *Event
+		want  string
+	}{
+		{name: "nil event", event: nil, want: ""},
+		{name: "no content", event: &Event{}, want: ""},
+		{
+			name: "function call only",
+			event: &Event{LLMResponse: model.LLMResponse{Content: &genai.Content{Parts: []*genai.Part{
+				genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Paris"}),
+			}}}},
+			want: "",
+		},
+		{
+			name: "text parts",
+			event: &Event{LLMResponse: model.LLMResponse{Content: &genai.Content{Parts: []*genai.Part{
+				genai.NewPartFromText("Hello, "),
+				nil,
+				genai.NewPartFromFunctionCall("get_weather", nil),
+				genai.NewPartFromText("world"),
+			}}}},
+			want: "Hello, world",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvent_FunctionCallsAndResponses(t *testing.T) {
+	call := &genai.FunctionCall{ID: "call_1", Name: "get_weather", Args: map[string]any{"city": "Paris"}}
+	response := &genai.FunctionResponse{ID: "call_0", Name: "get_time", Response: map[string]any{"time": "10:00"}}
+	mixed := &Event{LLMResponse: model.LLMResponse{Content: &genai.Content{Parts: []*genai.Part{
+		genai.NewPartFromText("Let me check."),
+		{FunctionResponse: response},
+		nil,
+		{FunctionCall: call},
+	}}}}
+
+	tests := []struct {
+		name          string
+		event         *Event
+		wantCalls     []*genai.FunctionCall
+		wantResponses []*genai.FunctionResponse
+	}{
+		{name: "nil event", event: nil},
+		{name: "nil content", event: &Event{}},
+		{name: "text only", event: &Event{LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hi", genai.RoleModel)}}},
+		{name: "mixed parts", event: mixed, wantCalls: []*genai.FunctionCall{call}, wantResponses: []*genai.FunctionResponse{response}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.wantCalls, tt.event.FunctionCalls()); diff != "" {
+				t.Errorf("FunctionCalls() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantResponses, tt.event.FunctionResponses()); diff != "" {
+				t.Errorf("FunctionResponses() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
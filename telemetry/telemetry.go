@@ -17,6 +17,7 @@
 package telemetry
 
 import (
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	internaltelemetry "google.golang.org/adk/internal/telemetry"
@@ -30,3 +31,12 @@ import (
 func RegisterSpanProcessor(processor sdktrace.SpanProcessor) {
 	internaltelemetry.AddSpanProcessor(processor)
 }
+
+// RegisterMetricReader registers the metric reader to a local meter provider instance.
+// ADK records agent invocations, errors and transfers, model calls and their latency,
+// token usage and tool calls. The reader gets the metrics recorded after it's registered.
+// In addition to the RegisterMetricReader function, global meter provider configs
+// are respected.
+func RegisterMetricReader(reader sdkmetric.Reader) {
+	internaltelemetry.AddMetricReader(reader)
+}
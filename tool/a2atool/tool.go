@@ -0,0 +1,279 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package a2atool provides a tool that allows an agent to call a remote agent
+// over the A2A protocol.
+//
+// The remote agent is described by its agent card, which is fetched when the
+// tool is created. The tool is declared to the model with a single "request"
+// parameter, similar to agenttool, and returns the text of the last response
+// of the remote agent.
+package a2atool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/toolinternal/toolutils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adka2a"
+	"google.golang.org/adk/tool"
+)
+
+const defaultCardTimeout = 30 * time.Second
+
+// Config holds the configuration for an A2A tool.
+type Config struct {
+	// Name overrides the tool name. By default the sanitized agent card name is used.
+	Name string
+	// Description overrides the tool description. By default it is built from
+	// the agent card description and skills.
+	Description string
+	// SkipSummarization, if true, will cause the agent to skip summarization
+	// after the remote agent responds.
+	SkipSummarization bool
+	// HTTPClient is used to fetch the agent card. Defaults to a client with 30 seconds timeout.
+	HTTPClient *http.Client
+	// ClientOptions are passed to the A2A client factory, e.g. to configure
+	// gRPC dial options or call interceptors.
+	ClientOptions []a2aclient.FactoryOption
+}
+
+// a2aTool implements a tool that delegates requests to a remote A2A agent.
+type a2aTool struct {
+	name              string
+	description       string
+	card              *a2a.AgentCard
+	skipSummarization bool
+	clientOptions     []a2aclient.FactoryOption
+}
+
+// New fetches the agent card served under agentCardURL and creates a tool
+// calling the described remote agent. agentCardURL is the base URL of the
+// remote agent, the well-known agent card path is appended to it.
+// If cfg is nil, default configuration is used.
+func New(agentCardURL string, cfg *Config) (tool.Tool, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultCardTimeout}
+	}
+	card, err := agentcard.NewResolver(httpClient).Resolve(context.Background(), agentCardURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve agent card from %q: %w", agentCardURL, err)
+	}
+	return NewFromCard(card, cfg)
+}
+
+// NewFromCard creates a tool calling the remote agent described by the card.
+// If cfg is nil, default configuration is used.
+func NewFromCard(card *a2a.AgentCard, cfg *Config) (tool.Tool, error) {
+	if card == nil {
+		return nil, fmt.Errorf("agent card is required")
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	name := cfg.Name
+	if name == "" {
+		name = sanitizeName(card.Name)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("tool name is required: agent card has no name")
+	}
+	description := cfg.Description
+	if description == "" {
+		description = buildDescription(card)
+	}
+	return &a2aTool{
+		name:              name,
+		description:       description,
+		card:              card,
+		skipSummarization: cfg.SkipSummarization,
+		clientOptions:     cfg.ClientOptions,
+	}, nil
+}
+
+// Name implements tool.Tool.
+func (t *a2aTool) Name() string {
+	return t.name
+}
+
+// Description implements tool.Tool.
+func (t *a2aTool) Description() string {
+	return t.description
+}
+
+// IsLongRunning implements tool.Tool.
+func (t *a2aTool) IsLongRunning() bool {
+	return false
+}
+
+// Declaration returns the function declaration for the remote agent.
+func (t *a2aTool) Declaration() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &genai.Schema{
+			Type: "OBJECT",
+			Properties: map[string]*genai.Schema{
+				"request": {Type: "STRING"},
+			},
+			Required: []string{"request"},
+		},
+	}
+}
+
+// Run sends the request to the remote agent, consumes the streamed response
+// and returns the text of the last response of the remote agent.
+func (t *a2aTool) Run(toolCtx tool.Context, args any) (map[string]any, error) {
+	margs, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("a2aTool expects map[string]any arguments, got %T", args)
+	}
+	input, ok := margs["request"]
+	if !ok {
+		return nil, fmt.Errorf("missing required argument 'request' for remote agent %s", t.name)
+	}
+	inputText, ok := input.(string)
+	if !ok {
+		inputText = fmt.Sprint(input)
+	}
+
+	if t.skipSummarization {
+		if actions := toolCtx.Actions(); actions != nil {
+			actions.SkipSummarization = true
+		}
+	}
+
+	client, err := a2aclient.NewFromCard(toolCtx, t.card, t.clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create A2A client for remote agent %s: %w", t.name, err)
+	}
+	defer func() { _ = client.Destroy() }()
+
+	msg := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: inputText})
+	var outputText string
+	for event, err := range client.SendStreamingMessage(toolCtx, &a2a.MessageSendParams{Message: msg}) {
+		if err != nil {
+			return nil, fmt.Errorf("error during execution of remote agent %s: %w", t.name, err)
+		}
+		text, err := eventText(event)
+		if err != nil {
+			return nil, fmt.Errorf("remote agent %s failed: %w", t.name, err)
+		}
+		if text != "" {
+			outputText = text
+		}
+	}
+
+	if outputText == "" {
+		return map[string]any{}, nil
+	}
+	return map[string]any{"result": outputText}, nil
+}
+
+// ProcessRequest adds the A2A tool's function declaration to the LLM request.
+func (t *a2aTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	return toolutils.PackTool(req, t)
+}
+
+// eventText returns the text carried by the event. An error is returned if
+// the event reports that the remote task has failed.
+func eventText(event a2a.Event) (string, error) {
+	switch v := event.(type) {
+	case *a2a.Message:
+		return partsText(v.Parts)
+	case *a2a.TaskArtifactUpdateEvent:
+		return partsText(v.Artifact.Parts)
+	case *a2a.TaskStatusUpdateEvent:
+		return statusText(v.Status)
+	case *a2a.Task:
+		text, err := statusText(v.Status)
+		if err != nil || text != "" {
+			return text, err
+		}
+		if len(v.Artifacts) == 0 {
+			return "", nil
+		}
+		return partsText(v.Artifacts[len(v.Artifacts)-1].Parts)
+	}
+	return "", nil
+}
+
+func statusText(status a2a.TaskStatus) (string, error) {
+	var text string
+	if status.Message != nil {
+		var err error
+		if text, err = partsText(status.Message.Parts); err != nil {
+			return "", err
+		}
+	}
+	switch status.State {
+	case a2a.TaskStateFailed, a2a.TaskStateRejected, a2a.TaskStateCanceled:
+		if text == "" {
+			text = "no details provided"
+		}
+		return "", fmt.Errorf("task %s: %s", status.State, text)
+	}
+	return text, nil
+}
+
+func partsText(parts []a2a.Part) (string, error) {
+	genaiParts, err := adka2a.ToGenAIParts(parts)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert parts: %w", err)
+	}
+	var textParts []string
+	for _, part := range genaiParts {
+		if part != nil && part.Text != "" && !part.Thought {
+			textParts = append(textParts, part.Text)
+		}
+	}
+	return strings.Join(textParts, "\n"), nil
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// sanitizeName turns an agent name into a valid function name.
+func sanitizeName(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+}
+
+func buildDescription(card *a2a.AgentCard) string {
+	description := card.Description
+	if len(card.Skills) == 0 {
+		return description
+	}
+	skills := make([]string, 0, len(card.Skills))
+	for _, skill := range card.Skills {
+		if skill.Description != "" {
+			skills = append(skills, fmt.Sprintf("%s: %s", skill.Name, skill.Description))
+		} else {
+			skills = append(skills, skill.Name)
+		}
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s\nSkills:\n- %s", description, strings.Join(skills, "\n- ")))
+}
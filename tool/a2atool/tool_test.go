@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package a2atool_test
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adka2a"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/a2atool"
+)
+
+const invokePath = "/invoke"
+
+// startRemoteAgent serves the agent over A2A JSON-RPC and returns the server URL.
+func startRemoteAgent(t *testing.T, a agent.Agent) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	card := &a2a.AgentCard{
+		Name:               a.Name(),
+		Description:        a.Description(),
+		URL:                server.URL + invokePath,
+		PreferredTransport: a2a.TransportProtocolJSONRPC,
+		Skills:             adka2a.BuildAgentSkills(a),
+		Capabilities:       a2a.AgentCapabilities{Streaming: true},
+	}
+	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
+		RunnerConfig: runner.Config{
+			AppName:        a.Name(),
+			Agent:          a,
+			SessionService: session.InMemoryService(),
+		},
+	})
+	mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(card))
+	mux.Handle(invokePath, a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(executor)))
+	return server.URL
+}
+
+func runTool(t *testing.T, tl tool.Tool, args map[string]any) (map[string]any, error) {
+	t.Helper()
+	toolCtx := toolinternal.NewToolContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}), "", nil)
+	return tl.(toolinternal.FunctionTool).Run(toolCtx, args)
+}
+
+func TestA2ATool(t *testing.T) {
+	model := &testutil.MockModel{
+		Responses: []*genai.Content{genai.NewContentFromText("It is sunny in London.", genai.RoleModel)},
+	}
+	remote, err := llmagent.New(llmagent.Config{
+		Name:        "weather agent",
+		Description: "Answers questions about the weather.",
+		Model:       model,
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	url := startRemoteAgent(t, remote)
+
+	weatherTool, err := a2atool.New(url, nil)
+	if err != nil {
+		t.Fatalf("a2atool.New() error = %v", err)
+	}
+	if got, want := weatherTool.Name(), "weather_agent"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(weatherTool.Description(), "Answers questions about the weather.") {
+		t.Errorf("Description() = %q, want agent card description", weatherTool.Description())
+	}
+
+	got, err := runTool(t, weatherTool, map[string]any{"request": "What is the weather in London?"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"result": "It is sunny in London."}, got); diff != "" {
+		t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(model.Requests) != 1 {
+		t.Fatalf("remote model got %d requests, want 1", len(model.Requests))
+	}
+	contents := model.Requests[0].Contents
+	if text := contents[len(contents)-1].Parts[0].Text; text != "What is the weather in London?" {
+		t.Errorf("remote model got request %q, want the tool request", text)
+	}
+}
+
+func TestA2ATool_RemoteFailure(t *testing.T) {
+	remote, err := agent.New(agent.Config{
+		Name: "failing_agent",
+		Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				yield(nil, errors.New("boom"))
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	url := startRemoteAgent(t, remote)
+
+	failingTool, err := a2atool.New(url, &a2atool.Config{Name: "remote"})
+	if err != nil {
+		t.Fatalf("a2atool.New() error = %v", err)
+	}
+	if got := failingTool.Name(); got != "remote" {
+		t.Errorf("Name() = %q, want %q", got, "remote")
+	}
+
+	_, err = runTool(t, failingTool, map[string]any{"request": "hi"})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Run() error = %v, want error containing %q", err, "boom")
+	}
+}
+
+func TestNew_CardNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := a2atool.New(server.URL, nil); err == nil {
+		t.Error("a2atool.New() error = nil, want error")
+	}
+}
@@ -14,19 +14,28 @@
 
 // Package agenttool provides a tool that allows an agent to call another agent.
 // This enables composition of agents, which can be useful for scenarios where
-// different types of `genai` tools cannot be used together.
+// different types of `genai` tools cannot be used together. The sub-agent
+// shares the state, the artifacts and the memory of the calling session.
 package agenttool
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/agent/runconfig"
+	artifactinternal "google.golang.org/adk/internal/artifact"
 	"google.golang.org/adk/internal/llminternal"
+	imemory "google.golang.org/adk/internal/memory"
+	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
@@ -39,6 +48,12 @@ import (
 type agentTool struct {
 	agent             agent.Agent
 	skipSummarization bool
+	timeout           time.Duration
+	artifactPrefix    string
+	streamEvents      bool
+	// sessionService keeps the sessions of the sub-agent when they are
+	// reused, nil otherwise.
+	sessionService session.Service
 }
 
 // Config holds the configuration for an agent tool.
@@ -46,10 +61,42 @@ type Config struct {
 	// SkipSummarization, if true, will cause the agent to skip summarization
 	// after the sub-agent finishes execution.
 	SkipSummarization bool
+	// Timeout limits how long a single call of the sub-agent may take. When
+	// exceeded, the sub-agent is canceled and the tool fails with an error
+	// wrapping context.DeadlineExceeded. Zero means no limit other than the
+	// deadline of the parent run.
+	Timeout time.Duration
+	// ArtifactPrefix is prepended to the names of the artifacts the sub-agent
+	// saves and loads in the calling session, e.g. "research/", so that the
+	// files of different sub-agents don't collide. The sub-agent only sees
+	// the artifacts with the prefix. For the artifacts of the "user:"
+	// namespace, the prefix comes after the namespace.
+	ArtifactPrefix string
+	// ReuseSession makes the sub-agent continue the same session in every
+	// call from a parent session, instead of starting a new session in each
+	// call, so that it sees the history of its previous calls. The session is
+	// kept in SessionService, keyed by the parent session ID and the tool
+	// name. Wrap the session service of the parent with [SessionService] to
+	// delete it with the parent session.
+	ReuseSession bool
+	// SessionService keeps the sessions of the sub-agent with ReuseSession.
+	// If nil, they are kept in memory, at most 1000 of them: the least
+	// recently used ones are evicted, and the sub-agent then starts a new
+	// session in the next call.
+	SessionService session.Service
+	// StreamEvents forwards the complete events of the sub-agent, e.g. its
+	// function calls, to the event stream of the parent invocation while the
+	// sub-agent runs, so that they can be observed, e.g. in the Web UI. The
+	// forwarded events keep their author, are on the branch of the parent
+	// followed by the tool name, e.g. "parent.tool", and have the ID of the
+	// function call of the tool in ParentFunctionCallID. They aren't part of
+	// the conversation history of the parent, and their actions are removed:
+	// the tool merges the state changes of the sub-agent as usual.
+	StreamEvents bool
 }
 
 // New creates a new agent tool.
-// If cfg is nil, skipSummarization defaults to false.
+// If cfg is nil, skipSummarization defaults to false and there is no timeout.
 func New(agent agent.Agent, cfg *Config) tool.Tool {
 	if cfg == nil {
 		return &agentTool{
@@ -57,10 +104,61 @@ func New(agent agent.Agent, cfg *Config) tool.Tool {
 			skipSummarization: false,
 		}
 	}
-	return &agentTool{
+	t := &agentTool{
 		agent:             agent,
 		skipSummarization: cfg.SkipSummarization,
+		timeout:           cfg.Timeout,
+		artifactPrefix:    cfg.ArtifactPrefix,
+		streamEvents:      cfg.StreamEvents,
 	}
+	if cfg.ReuseSession {
+		t.sessionService = cfg.SessionService
+		if t.sessionService == nil {
+			t.sessionService = session.InMemoryService(session.WithMaxSessions(maxReusedSessions))
+		}
+	}
+	return t
+}
+
+// maxReusedSessions is the number of sub-agent sessions kept in memory with
+// Config.ReuseSession, when no session service is configured.
+const maxReusedSessions = 1000
+
+// SessionService returns a session service storing the sessions in inner,
+// which also deletes the sessions of the sub-agents of tools, created with
+// Config.ReuseSession, when their parent session is deleted. The other
+// tools are ignored.
+func SessionService(inner session.Service, tools ...tool.Tool) session.Service {
+	var reusing []*agentTool
+	for _, t := range tools {
+		if at, ok := t.(*agentTool); ok && at.sessionService != nil {
+			reusing = append(reusing, at)
+		}
+	}
+	return &sessionService{Service: inner, tools: reusing}
+}
+
+type sessionService struct {
+	session.Service
+	tools []*agentTool
+}
+
+func (s *sessionService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if err := s.Service.Delete(ctx, req); err != nil {
+		return err
+	}
+	var errs []error
+	for _, t := range s.tools {
+		err := t.sessionService.Delete(ctx, &session.DeleteRequest{
+			AppName:   t.agent.Name(),
+			UserID:    req.UserID,
+			SessionID: subSessionID(req.SessionID, t.Name()),
+		})
+		if err != nil && !errors.Is(err, session.ErrSessionNotFound) {
+			errs = append(errs, fmt.Errorf("failed to delete the session of sub-agent %s: %w", t.agent.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // Name implements tool.Tool.
@@ -115,8 +213,20 @@ func (t *agentTool) Declaration() *genai.FunctionDeclaration {
 }
 
 // Run executes the wrapped agent with the provided arguments.
-// It creates a new session for the sub-agent, runs the agent, and returns
+// It creates a new session for the sub-agent, or continues the session of
+// the previous calls with Config.ReuseSession, runs the agent, and returns
 // the final result.
+//
+// The session of the sub-agent starts with a copy of the state of the
+// parent session, without the internal keys prefixed with "_adk". When the
+// sub-agent completes, the state changes it made, e.g. its output key, are
+// merged back into the parent state, except the internal and the temporary
+// keys: the value written last by the sub-agent overrides the value of the
+// parent. The changes are discarded if the sub-agent fails.
+//
+// The sub-agent runs with the streaming mode of the parent run, and uses the
+// artifacts and the memory of the parent session. The names of the artifacts
+// it saved, as seen by the parent, are listed under "artifacts" in the result.
 func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error) {
 	margs, ok := args.(map[string]any)
 	if !ok {
@@ -164,15 +274,30 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 		content = genai.NewContentFromText(inputText, genai.RoleUser)
 	}
 
-	sessionService := session.InMemoryService()
+	sessionService := t.sessionService
+	if sessionService == nil {
+		sessionService = session.InMemoryService()
+	}
+
+	// The sub-agent shares the artifacts and the memory of the parent session.
+	var artifactService artifact.Service
+	forwardingArtifacts, ok := artifactinternal.NewForwardingService(toolCtx.Artifacts(), t.artifactPrefix)
+	if ok {
+		artifactService = forwardingArtifacts
+	} else {
+		artifactService = artifact.InMemoryService()
+	}
+	memoryService, ok := imemory.ForwardingService(toolinternal.InvocationMemory(toolCtx))
+	if !ok {
+		memoryService = memory.InMemoryService()
+	}
 
 	r, err := runner.New(runner.Config{
-		AppName:        t.agent.Name(),
-		Agent:          t.agent,
-		SessionService: sessionService,
-		// TODO - use forwarding_artifact_service as in python.
-		ArtifactService: artifact.InMemoryService(),
-		MemoryService:   memory.InMemoryService(),
+		AppName:         t.agent.Name(),
+		Agent:           t.agent,
+		SessionService:  sessionService,
+		ArtifactService: artifactService,
+		MemoryService:   memoryService,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create runner")
@@ -181,36 +306,171 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 	stateMap := make(map[string]any)
 
 	for k, v := range toolCtx.State().All() {
-		// Filter out adk internal states.
-		if !strings.HasPrefix(k, "_adk") {
+		if !isInternalStateKey(k) {
 			stateMap[k] = v
 		}
 	}
 
-	subSession, err := sessionService.Create(toolCtx, &session.CreateRequest{
-		AppName: t.agent.Name(),
-		UserID:  toolCtx.UserID(),
-		State:   stateMap,
-	})
+	subSession, err := t.subSession(toolCtx, sessionService, stateMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session for sub-agent %s: %w", t.agent.Name(), err)
 	}
 
-	// TODO(dpasiukevich): verify agent loop termination.
-	eventCh := r.Run(toolCtx, subSession.Session.UserID(), subSession.Session.ID(), content, agent.RunConfig{
-		StreamingMode: agent.StreamingModeSSE,
-	})
+	// The sub-agent shares the RunConfig.MaxLLMCalls limit of the parent
+	// invocation, read by the runner from toolCtx, which stops loops of
+	// agents calling each other. It is also bounded by the deadline of toolCtx,
+	// e.g. set by the parent RunConfig.MaxDuration, and canceled with it.
+	ctx := context.Context(toolCtx)
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	// The sub-agent uses the streaming mode of the parent run.
+	var runConfig agent.RunConfig
+	if parentCfg := runconfig.FromContext(toolCtx); parentCfg != nil {
+		runConfig.StreamingMode = agent.StreamingMode(parentCfg.StreamingMode)
+	}
+	eventCh := r.Run(ctx, subSession.UserID(), subSession.ID(), content, runConfig)
 
 	var lastEvent *session.Event
+	stateDelta := make(map[string]any)
 	for event, err := range eventCh {
+		// Sub-agents not observing the context are stopped at the next event.
+		if ctx.Err() != nil {
+			return nil, t.interrupted(toolCtx, ctx)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("error during execution of sub-agent %s: %w", t.agent.Name(), err)
 		}
-		if event.LLMResponse.Content != nil {
+		// The events forwarded by nested agent tools aren't the output.
+		if event.LLMResponse.Content != nil && event.ParentFunctionCallID == "" {
 			lastEvent = event
 		}
+		// Like the session, only the deltas of complete events are kept.
+		if !event.LLMResponse.Partial {
+			if t.streamEvents {
+				t.forwardEvent(toolCtx, event)
+			}
+			for k, v := range event.Actions.StateDelta {
+				if !isInternalStateKey(k) && !strings.HasPrefix(k, session.KeyPrefixTemp) {
+					stateDelta[k] = v
+				}
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, t.interrupted(toolCtx, ctx)
+	}
+
+	for k, v := range stateDelta {
+		if err := toolCtx.State().Set(k, v); err != nil {
+			return nil, fmt.Errorf("failed to merge state of sub-agent %s: %w", t.agent.Name(), err)
+		}
+	}
+
+	result, err := t.output(lastEvent, isLllmAgent)
+	if err != nil {
+		return nil, err
+	}
+	// The parent can load the artifacts saved by the sub-agent, e.g. with
+	// loadartifactstool.
+	if forwardingArtifacts != nil {
+		if names := forwardingArtifacts.SavedFileNames(); len(names) > 0 {
+			result["artifacts"] = names
+		}
+	}
+	return result, nil
+}
+
+// forwardEvent emits a copy of the event of the sub-agent in the parent
+// invocation.
+func (t *agentTool) forwardEvent(toolCtx tool.Context, event *session.Event) {
+	forwarded := *event
+	forwarded.InvocationID = toolCtx.InvocationID()
+	branch := toolCtx.Branch()
+	if branch == "" {
+		branch = toolCtx.AgentName()
+	}
+	forwarded.Branch = branch + "." + t.Name()
+	if event.Branch != "" {
+		forwarded.Branch += "." + event.Branch
+	}
+	// Events forwarded by a nested agent tool keep their function call.
+	if forwarded.ParentFunctionCallID == "" {
+		forwarded.ParentFunctionCallID = toolCtx.FunctionCallID()
+	}
+	forwarded.Actions = session.EventActions{}
+	toolinternal.EmitEvent(toolCtx, &forwarded)
+}
+
+// subSessionID returns the id of the session reused by the tool in the
+// calls from the parent session.
+func subSessionID(parentSessionID, toolName string) string {
+	return parentSessionID + "/" + toolName
+}
+
+// subSession returns the session in which the sub-agent runs, with the given
+// state. A reused session is created by the first call from the parent
+// session, the next calls bring its state up to date with the parent state.
+func (t *agentTool) subSession(toolCtx tool.Context, service session.Service, state map[string]any) (session.Session, error) {
+	if t.sessionService == nil {
+		resp, err := service.Create(toolCtx, &session.CreateRequest{
+			AppName: t.agent.Name(),
+			UserID:  toolCtx.UserID(),
+			State:   state,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Session, nil
+	}
+
+	sessionID := subSessionID(toolCtx.SessionID(), t.Name())
+	resp, err := service.Get(toolCtx, &session.GetRequest{
+		AppName:   t.agent.Name(),
+		UserID:    toolCtx.UserID(),
+		SessionID: sessionID,
+	})
+	if errors.Is(err, session.ErrSessionNotFound) {
+		created, err := service.Create(toolCtx, &session.CreateRequest{
+			AppName:   t.agent.Name(),
+			UserID:    toolCtx.UserID(),
+			SessionID: sessionID,
+			State:     state,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return created.Session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subSession := resp.Session
+	delta := make(map[string]any)
+	for k, v := range state {
+		if old, err := subSession.State().Get(k); err != nil || !reflect.DeepEqual(old, v) {
+			delta[k] = v
+		}
+	}
+	if len(delta) > 0 {
+		// The event has no content, so it isn't part of the history the
+		// sub-agent sees.
+		event := session.NewEvent(toolCtx.InvocationID())
+		event.Author = "user"
+		event.Actions.StateDelta = delta
+		if err := service.AppendEvent(toolCtx, subSession, event); err != nil {
+			return nil, err
+		}
 	}
+	return subSession, nil
+}
 
+// output returns the tool response for the last event with content of the
+// sub-agent: its text, parsed with the output schema of an LLM agent.
+func (t *agentTool) output(lastEvent *session.Event, isLllmAgent bool) (map[string]any, error) {
 	if lastEvent == nil {
 		return map[string]any{}, nil
 	}
@@ -246,6 +506,21 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 	return map[string]any{"result": outputText}, nil
 }
 
+// isInternalStateKey reports whether the state key is internal to ADK. The
+// internal keys are not shared between the parent and the sub-agent.
+func isInternalStateKey(key string) bool {
+	return strings.HasPrefix(key, "_adk")
+}
+
+// interrupted returns the error of a sub-agent run interrupted by the
+// cancellation of the parent run, or by the timeout of the tool.
+func (t *agentTool) interrupted(parent, ctx context.Context) error {
+	if parent.Err() != nil {
+		return fmt.Errorf("sub-agent %s was canceled: %w", t.agent.Name(), context.Cause(parent))
+	}
+	return fmt.Errorf("sub-agent %s timed out after %v: %w", t.agent.Name(), t.timeout, context.Cause(ctx))
+}
+
 // ProcessRequest adds the agent tool's function declaration to the LLM request.
 func (t *agentTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
 	// TODO extract this function somewhere else, simillar operations are done for
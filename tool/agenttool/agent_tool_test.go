@@ -15,23 +15,35 @@
 package agenttool_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"log"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/agent/runconfig"
+	artifactinternal "google.golang.org/adk/internal/artifact"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/sessioninternal"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/agenttool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/loadartifactstool"
 )
 
 func TestAgentTool_Declaration(t *testing.T) {
@@ -213,8 +225,8 @@ func TestAgentTool_Run_WithoutSchema(t *testing.T) {
 		Responses: []*genai.Content{
 			{
 				Parts: []*genai.Part{
-					{Text: "First text part is returned"},
-					{Text: "This should be ignored"},
+					{Text: "Text parts of a streamed chunk "},
+					{Text: "are returned together"},
 				},
 				Role: genai.RoleModel,
 			},
@@ -224,7 +236,9 @@ func TestAgentTool_Run_WithoutSchema(t *testing.T) {
 
 	agent := createAgentWithModel(t, nil, nil, testLLM)
 	agentTool := agenttool.New(agent, nil)
-	toolCtx := createToolContext(t, agent)
+	// The sub-agent streams like the parent run.
+	parent := runconfig.ToContext(t.Context(), &runconfig.RunConfig{StreamingMode: runconfig.StreamingModeSSE})
+	toolCtx := createToolContextWithParent(t, parent, nil)
 	toolImpl, ok := agentTool.(toolinternal.FunctionTool)
 	if !ok {
 		t.Fatal("agentTool does not implement FunctionTool")
@@ -234,7 +248,7 @@ func TestAgentTool_Run_WithoutSchema(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Run() failed unexpectedly: %v", err)
 	}
-	want := map[string]any{"result": "First text part is returned"}
+	want := map[string]any{"result": "Text parts of a streamed chunk are returned together"}
 	if diff := cmp.Diff(want, result); diff != "" {
 		t.Errorf("Run() result diff (-want +got):\n%s", diff)
 	}
@@ -309,6 +323,320 @@ func TestAgentTool_Run_SkipSummarization(t *testing.T) {
 	}
 }
 
+// newEndlessAgent returns an agent which never terminates. It keeps yielding
+// events without observing its context.
+func newEndlessAgent(t *testing.T) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name:        "endless_agent",
+		Description: "Never stops.",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for {
+					time.Sleep(5 * time.Millisecond)
+					event := session.NewEvent(ctx.InvocationID())
+					event.Author = "endless_agent"
+					event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("still working", genai.RoleModel)}
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	return a
+}
+
+func TestAgentTool_Run_Timeout(t *testing.T) {
+	endless := newEndlessAgent(t)
+	toolImpl, ok := agenttool.New(endless, &agenttool.Config{Timeout: 50 * time.Millisecond}).(toolinternal.FunctionTool)
+	if !ok {
+		t.Fatal("agentTool does not implement FunctionTool")
+	}
+
+	start := time.Now()
+	_, err := toolImpl.Run(createToolContext(t, endless), map[string]any{"request": "work"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Run() error = %q, want a timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %v, want it stopped by the timeout", elapsed)
+	}
+}
+
+func TestAgentTool_Run_ParentCanceled(t *testing.T) {
+	endless := newEndlessAgent(t)
+	toolImpl, ok := agenttool.New(endless, nil).(toolinternal.FunctionTool)
+	if !ok {
+		t.Fatal("agentTool does not implement FunctionTool")
+	}
+
+	parent, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := toolImpl.Run(createToolContextWithParent(t, parent, nil), map[string]any{"request": "work"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestAgentTool_Run_ForwardsArtifacts(t *testing.T) {
+	subAgent, err := agent.New(agent.Config{
+		Name:        "report_agent",
+		Description: "Writes a report.",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				input, err := ctx.Artifacts().Load(ctx, "input.txt")
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				report := genai.NewPartFromText("report of " + input.Part.Text)
+				if _, err := ctx.Artifacts().Save(ctx, "report.txt", report); err != nil {
+					yield(nil, err)
+					return
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "report_agent"
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	toolImpl, ok := agenttool.New(subAgent, nil).(toolinternal.FunctionTool)
+	if !ok {
+		t.Fatal("agentTool does not implement FunctionTool")
+	}
+
+	artifacts := &artifactinternal.Artifacts{
+		Service:   artifact.InMemoryService(),
+		AppName:   "testApp",
+		UserID:    "testUser",
+		SessionID: "testSession",
+	}
+	toolCtx := createToolContextWithParent(t, t.Context(), artifacts)
+	// The sub-agent reads the artifacts of the parent.
+	if _, err := toolCtx.Artifacts().Save(t.Context(), "input.txt", genai.NewPartFromText("sales")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := toolImpl.Run(toolCtx, map[string]any{"request": "write the report"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The parent reads the artifacts of the sub-agent.
+	resp, err := toolCtx.Artifacts().Load(t.Context(), "report.txt")
+	if err != nil {
+		t.Fatalf("Load() of the artifact saved by the sub-agent error = %v", err)
+	}
+	if resp.Part.Text != "report of sales" {
+		t.Errorf("loaded artifact = %q, want %q", resp.Part.Text, "report of sales")
+	}
+	if _, ok := toolCtx.Actions().ArtifactDelta["report.txt"]; !ok {
+		t.Errorf("ArtifactDelta = %v, want the artifact saved by the sub-agent", toolCtx.Actions().ArtifactDelta)
+	}
+}
+
+func TestAgentTool_Run_ParentLoadsArtifacts(t *testing.T) {
+	chartAgent, err := agent.New(agent.Config{
+		Name:        "chart_agent",
+		Description: "Draws charts.",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				if _, err := ctx.Artifacts().Save(ctx, "chart.txt", genai.NewPartFromText("growth chart")); err != nil {
+					yield(nil, err)
+					return
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "chart_agent"
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("chart saved", genai.RoleModel)}
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	parentModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("chart_agent", map[string]any{"request": "draw the sales"}, genai.RoleModel),
+			genai.NewContentFromFunctionCall("load_artifacts", map[string]any{"artifact_names": []any{"charts/chart.txt"}}, genai.RoleModel),
+			genai.NewContentFromText("the sales grow", genai.RoleModel),
+		},
+	}
+	parent, err := llmagent.New(llmagent.Config{
+		Name:  "parent",
+		Model: parentModel,
+		Tools: []tool.Tool{
+			agenttool.New(chartAgent, &agenttool.Config{ArtifactPrefix: "charts/"}),
+			loadartifactstool.New(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:         "testApp",
+		Agent:           parent,
+		SessionService:  sessionService,
+		ArtifactService: artifact.InMemoryService(),
+	})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var toolResult map[string]any
+	for event, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("chart the sales", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		for _, part := range event.Content.Parts {
+			if part.FunctionResponse != nil && part.FunctionResponse.Name == "chart_agent" {
+				toolResult = part.FunctionResponse.Response
+			}
+		}
+	}
+
+	wantResult := map[string]any{"result": "chart saved", "artifacts": []string{"charts/chart.txt"}}
+	if diff := cmp.Diff(wantResult, toolResult); diff != "" {
+		t.Errorf("agent tool result mismatch (-want +got):\n%s", diff)
+	}
+	// The parent model gets the artifact loaded by load_artifacts.
+	if len(parentModel.Requests) != 3 {
+		t.Fatalf("parent model got %d requests, want 3", len(parentModel.Requests))
+	}
+	var loaded bool
+	for _, content := range parentModel.Requests[2].Contents {
+		for _, part := range content.Parts {
+			if part.Text == "growth chart" {
+				loaded = true
+			}
+		}
+	}
+	if !loaded {
+		t.Errorf("the last parent request doesn't contain the artifact saved by the sub-agent")
+	}
+}
+
+func TestAgentTool_Run_StreamEvents(t *testing.T) {
+	weatherTool, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "Returns the weather."},
+		func(ctx tool.Context, args struct{ City string }) (string, error) {
+			return "sunny", nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	weatherAgent, err := llmagent.New(llmagent.Config{
+		Name:        "weather_agent",
+		Description: "Tells the weather.",
+		Model: &testutil.MockModel{
+			Responses: []*genai.Content{
+				genai.NewContentFromFunctionCall("get_weather", map[string]any{"City": "Paris"}, genai.RoleModel),
+				genai.NewContentFromText("It is sunny in Paris.", genai.RoleModel),
+			},
+		},
+		Tools:     []tool.Tool{weatherTool},
+		OutputKey: "weather",
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	parentModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("weather_agent", map[string]any{"request": "weather in Paris?"}, genai.RoleModel),
+			genai.NewContentFromText("Take sunglasses.", genai.RoleModel),
+		},
+	}
+	parent, err := llmagent.New(llmagent.Config{
+		Name:  "parent",
+		Model: parentModel,
+		Tools: []tool.Tool{agenttool.New(weatherAgent, &agenttool.Config{StreamEvents: true})},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "testApp", Agent: parent, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+
+	type eventSummary struct {
+		Author, Branch, Call string
+		Nested               bool
+	}
+	var got []eventSummary
+	var callID string
+	for event, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("what to wear?", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		summary := eventSummary{Author: event.Author, Branch: event.Branch, Nested: event.ParentFunctionCallID != ""}
+		for _, fc := range event.FunctionCalls() {
+			summary.Call = fc.Name
+			if fc.Name == "weather_agent" {
+				callID = fc.ID
+			}
+		}
+		if summary.Nested && event.ParentFunctionCallID != callID {
+			t.Errorf("event ParentFunctionCallID = %q, want %q", event.ParentFunctionCallID, callID)
+		}
+		if len(event.Actions.StateDelta) > 0 && summary.Nested {
+			t.Errorf("forwarded event has state delta %v", event.Actions.StateDelta)
+		}
+		got = append(got, summary)
+	}
+
+	want := []eventSummary{
+		{Author: "parent", Call: "weather_agent"},
+		{Author: "weather_agent", Branch: "parent.weather_agent", Call: "get_weather", Nested: true},
+		{Author: "weather_agent", Branch: "parent.weather_agent", Nested: true}, // function response
+		{Author: "weather_agent", Branch: "parent.weather_agent", Nested: true}, // answer
+		{Author: "parent"}, // function response
+		{Author: "parent"}, // answer
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parent events mismatch (-want +got):\n%s", diff)
+	}
+
+	// The forwarded events aren't part of the history of the parent model.
+	if len(parentModel.Requests) != 2 {
+		t.Fatalf("parent model got %d requests, want 2", len(parentModel.Requests))
+	}
+	for _, content := range parentModel.Requests[1].Contents {
+		for _, part := range content.Parts {
+			if part.FunctionCall != nil && part.FunctionCall.Name == "get_weather" {
+				t.Errorf("parent request contains the function call of the sub-agent")
+			}
+		}
+	}
+	// The state is merged by the tool.
+	resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "testApp", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := resp.Session.State().Get("weather"); err != nil || got != "It is sunny in Paris." {
+		t.Errorf("parent state weather = %v, %v, want the sub-agent output", got, err)
+	}
+}
+
 func createAgent(t *testing.T, inputSchema, outputSchema *genai.Schema) agent.Agent {
 	t.Helper()
 
@@ -350,6 +678,208 @@ func createAgentWithModel(t *testing.T, inputSchema, outputSchema *genai.Schema,
 
 func createToolContext(t *testing.T, testAgent agent.Agent) tool.Context {
 	t.Helper()
+	return createToolContextWithParent(t, t.Context(), nil)
+}
+
+func TestAgentTool_Run_MergesState(t *testing.T) {
+	subAgent, err := agent.New(agent.Config{
+		Name:        "planner",
+		Description: "Plans a trip.",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				city, err := ctx.Session().State().Get("city")
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "planner"
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("planned", genai.RoleModel)}
+				event.Actions.StateDelta = map[string]any{
+					"plan":          fmt.Sprintf("visit %s", city),
+					"city":          "Lyon",
+					"_adk_internal": true,
+					"temp:scratch":  "notes",
+				}
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	toolImpl, ok := agenttool.New(subAgent, nil).(toolinternal.FunctionTool)
+	if !ok {
+		t.Fatal("agentTool does not implement FunctionTool")
+	}
+	toolCtx := createToolContext(t, subAgent)
+	if err := toolCtx.State().Set("city", "Paris"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := toolImpl.Run(toolCtx, map[string]any{"request": "plan a trip"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for key, want := range map[string]any{
+		"plan": "visit Paris", // computed from the parent state
+		"city": "Lyon",        // the sub-agent overrides the parent
+	} {
+		if got, err := toolCtx.State().Get(key); err != nil || got != want {
+			t.Errorf("State().Get(%q) = %v, %v, want %v", key, got, err, want)
+		}
+		if got := toolCtx.Actions().StateDelta[key]; got != want {
+			t.Errorf("StateDelta[%q] = %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := toolCtx.Actions().StateDelta["_adk_internal"]; ok {
+		t.Error("the internal state of the sub-agent was merged into the parent")
+	}
+	if _, ok := toolCtx.Actions().StateDelta["temp:scratch"]; ok {
+		t.Error("the temporary state of the sub-agent was merged into the parent")
+	}
+}
+
+func TestAgentTool_Run_ReuseSession(t *testing.T) {
+	for _, reuse := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ReuseSession=%v", reuse), func(t *testing.T) {
+			translatorModel := &testutil.MockModel{
+				Responses: []*genai.Content{
+					genai.NewContentFromText("bonjour", genai.RoleModel),
+					genai.NewContentFromText("au revoir", genai.RoleModel),
+				},
+			}
+			translator, err := llmagent.New(llmagent.Config{
+				Name:        "translator",
+				Description: "Translates to French.",
+				Model:       translatorModel,
+				OutputKey:   "translation",
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+			parent, err := llmagent.New(llmagent.Config{
+				Name: "parent",
+				Model: &testutil.MockModel{
+					Responses: []*genai.Content{
+						genai.NewContentFromFunctionCall("translator", map[string]any{"request": "hello"}, genai.RoleModel),
+						genai.NewContentFromFunctionCall("translator", map[string]any{"request": "goodbye"}, genai.RoleModel),
+						genai.NewContentFromText("done", genai.RoleModel),
+					},
+				},
+				Tools: []tool.Tool{agenttool.New(translator, &agenttool.Config{ReuseSession: reuse})},
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+
+			sessionService := session.InMemoryService()
+			r, err := runner.New(runner.Config{AppName: "testApp", Agent: parent, SessionService: sessionService})
+			if err != nil {
+				t.Fatalf("runner.New() error = %v", err)
+			}
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatal(err)
+			}
+			for _, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("translate", genai.RoleUser), agent.RunConfig{}) {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+			}
+
+			if len(translatorModel.Requests) != 2 {
+				t.Fatalf("translator model got %d requests, want 2", len(translatorModel.Requests))
+			}
+			var texts []string
+			for _, content := range translatorModel.Requests[1].Contents {
+				for _, part := range content.Parts {
+					texts = append(texts, part.Text)
+				}
+			}
+			wantTexts := []string{"goodbye"}
+			if reuse {
+				// The second call continues the conversation of the first one.
+				wantTexts = []string{"hello", "bonjour", "goodbye"}
+			}
+			if diff := cmp.Diff(wantTexts, texts); diff != "" {
+				t.Errorf("second translator request mismatch (-want +got):\n%s", diff)
+			}
+
+			// The output of the sub-agent is committed to the parent session.
+			resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "testApp", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := resp.Session.State().Get("translation"); err != nil || got != "au revoir" {
+				t.Errorf("parent state translation = %v, %v, want %q", got, err, "au revoir")
+			}
+		})
+	}
+}
+
+func TestSessionService_DeletesReusedSessions(t *testing.T) {
+	ctx := t.Context()
+	translator, err := llmagent.New(llmagent.Config{
+		Name:        "translator",
+		Description: "Translates to French.",
+		Model:       &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("bonjour", genai.RoleModel)}},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	subSessions := session.InMemoryService()
+	translatorTool := agenttool.New(translator, &agenttool.Config{ReuseSession: true, SessionService: subSessions})
+	parent, err := llmagent.New(llmagent.Config{
+		Name: "parent",
+		Model: &testutil.MockModel{
+			Responses: []*genai.Content{
+				genai.NewContentFromFunctionCall("translator", map[string]any{"request": "hello"}, genai.RoleModel),
+				genai.NewContentFromText("done", genai.RoleModel),
+			},
+		},
+		Tools: []tool.Tool{translatorTool},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	sessionService := agenttool.SessionService(session.InMemoryService(), translatorTool)
+	r, err := runner.New(runner.Config{AppName: "testApp", Agent: parent, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range r.Run(ctx, "user", "session", genai.NewContentFromText("translate", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	list := func() int {
+		resp, err := subSessions.List(ctx, &session.ListRequest{AppName: "translator", UserID: "user"})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		return len(resp.Sessions)
+	}
+	if got := list(); got != 1 {
+		t.Fatalf("sub-agent has %d sessions after the run, want 1", got)
+	}
+
+	if err := sessionService.Delete(ctx, &session.DeleteRequest{AppName: "testApp", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if got := list(); got != 0 {
+		t.Errorf("sub-agent has %d sessions after the parent session was deleted, want 0", got)
+	}
+}
+
+// createToolContextWithParent returns a tool context canceled with parent,
+// of an invocation with the artifacts.
+func createToolContextWithParent(t *testing.T, parent context.Context, artifacts agent.Artifacts) tool.Context {
+	t.Helper()
 
 	sessionService := session.InMemoryService()
 	createResponse, err := sessionService.Create(t.Context(), &session.CreateRequest{
@@ -363,8 +893,9 @@ func createToolContext(t *testing.T, testAgent agent.Agent) tool.Context {
 	s := createResponse.Session
 	sessionImpl := sessioninternal.NewMutableSession(sessionService, s)
 
-	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
-		Session: sessionImpl,
+	ctx := icontext.NewInvocationContext(parent, icontext.InvocationContextParams{
+		Artifacts: artifacts,
+		Session:   sessionImpl,
 	})
 
 	return toolinternal.NewToolContext(ctx, "", &session.EventActions{})
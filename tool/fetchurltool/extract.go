@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchurltool
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// skippedElements are the elements whose content is not readable text.
+var skippedElements = map[string]bool{
+	"head":     true,
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"nav":      true,
+	"footer":   true,
+	"iframe":   true,
+}
+
+// blockElements are the elements which start a new line of text.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "main": true, "header": true,
+	"blockquote": true, "pre": true, "table": true, "ul": true, "ol": true,
+}
+
+// extractText returns the readable text of the HTML document, skipping
+// scripts, styles and navigation elements.
+func extractText(doc string) (string, error) {
+	var b strings.Builder
+	var skipDepth int
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return "", err
+			}
+			return normalizeSpace(b.String()), nil
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skippedElements[tag] {
+				skipDepth++
+			}
+			if blockElements[tag] {
+				b.WriteString("\n")
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skippedElements[tag] && skipDepth > 0 {
+				skipDepth--
+			}
+			if blockElements[tag] {
+				b.WriteString("\n")
+			}
+		case html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if blockElements[string(name)] {
+				b.WriteString("\n")
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				b.Write(z.Text())
+			}
+		}
+	}
+}
+
+// normalizeSpace collapses the whitespace within lines and drops empty lines.
+func normalizeSpace(s string) string {
+	var lines []string
+	for line := range strings.Lines(s) {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fetchurltool provides a tool that downloads a web page and returns
+// its text content to the model.
+//
+// It is a fallback for models that do not support the Gemini built-in
+// geminitool.URLContext tool.
+package fetchurltool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	defaultMaxBytes = 1 << 20
+	defaultTimeout  = 30 * time.Second
+)
+
+var defaultContentTypes = []string{
+	"text/html",
+	"text/plain",
+	"text/markdown",
+	"text/csv",
+	"text/xml",
+	"application/json",
+	"application/xml",
+	"application/xhtml+xml",
+}
+
+// Config contains the configuration of the fetch_url tool.
+type Config struct {
+	// MaxBytes is the maximum number of bytes read from the response body.
+	// Longer responses are truncated. Optional: defaults to 1 MiB.
+	MaxBytes int64
+	// Timeout limits the duration of the whole request, including reading
+	// the response body. Optional: defaults to 30 seconds.
+	Timeout time.Duration
+	// AllowedContentTypes is the list of accepted media types (e.g.
+	// "text/html"). Responses of other types are rejected.
+	// Optional: defaults to common text formats.
+	AllowedContentTypes []string
+	// ExtractText makes the tool strip the markup from HTML pages and return
+	// only their readable text.
+	ExtractText bool
+	// AllowPrivateNetworks allows fetching URLs which resolve to loopback,
+	// private, link-local or otherwise non-public addresses.
+	//
+	// By default such requests are rejected to prevent server-side request
+	// forgery. Enable it only if the model is trusted to access the internal
+	// network.
+	AllowPrivateNetworks bool
+}
+
+// Args are the arguments of the fetch_url tool.
+type Args struct {
+	// URL is the address of the web page to fetch.
+	URL string `json:"url" jsonschema:"The http or https URL of the web page to fetch."`
+}
+
+// Result is the result of the fetch_url tool.
+type Result struct {
+	// URL is the final URL of the page after following redirects.
+	URL string `json:"url"`
+	// ContentType is the media type of the page.
+	ContentType string `json:"content_type"`
+	// Content is the text content of the page.
+	Content string `json:"content"`
+	// Truncated reports whether the content was cut at the size limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// New creates a fetch_url tool which downloads the given URL and returns its
+// text content.
+func New(cfg Config) (tool.Tool, error) {
+	f := &fetcher{
+		maxBytes:     cfg.MaxBytes,
+		contentTypes: cfg.AllowedContentTypes,
+		extractText:  cfg.ExtractText,
+	}
+	if f.maxBytes <= 0 {
+		f.maxBytes = defaultMaxBytes
+	}
+	if len(f.contentTypes) == 0 {
+		f.contentTypes = defaultContentTypes
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !cfg.AllowPrivateNetworks {
+		// The address is checked right before connecting, so it also covers
+		// redirects and DNS names resolving to private addresses.
+		dialer.Control = rejectPrivateAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+
+	fetchTool, err := functiontool.New(functiontool.Config{
+		Name:        "fetch_url",
+		Description: "Fetches the web page at the given URL and returns its text content.",
+	}, f.fetch)
+	if err != nil {
+		return nil, fmt.Errorf("error creating fetch_url tool: %w", err)
+	}
+	return fetchTool, nil
+}
+
+type fetcher struct {
+	client       *http.Client
+	maxBytes     int64
+	contentTypes []string
+	extractText  bool
+}
+
+func (f *fetcher) fetch(ctx tool.Context, args Args) (Result, error) {
+	u, err := url.Parse(args.URL)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid url %q: %w", args.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Result{}, fmt.Errorf("unsupported url scheme %q, only http and https are allowed", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to fetch %q: %w", args.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Result{}, fmt.Errorf("failed to fetch %q: unexpected status %s", args.URL, resp.Status)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse content type of %q: %w", args.URL, err)
+	}
+	if !f.allowedContentType(mediaType) {
+		return Result{}, fmt.Errorf("content type %q of %q is not allowed", mediaType, args.URL)
+	}
+
+	// Read one more byte than allowed to detect truncation.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read response of %q: %w", args.URL, err)
+	}
+	truncated := int64(len(body)) > f.maxBytes
+	if truncated {
+		body = body[:f.maxBytes]
+	}
+
+	content := string(body)
+	if f.extractText && (mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+		content, err = extractText(content)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to extract text from %q: %w", args.URL, err)
+		}
+	}
+
+	return Result{
+		URL:         resp.Request.URL.String(),
+		ContentType: mediaType,
+		Content:     content,
+		Truncated:   truncated,
+	}, nil
+}
+
+func (f *fetcher) allowedContentType(mediaType string) bool {
+	for _, t := range f.contentTypes {
+		if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+var errPrivateAddress = errors.New("access to private network addresses is not allowed")
+
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid ip address %q", host)
+	}
+	if isPrivate(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, ip)
+	}
+	return nil
+}
+
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchurltool_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/fetchurltool"
+)
+
+func TestFetchURLTool(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, "hello world")
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("a", 100))
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>T</title><style>p{}</style></head>`+
+			`<body><script>alert(1)</script><h1>Title</h1><p>First   paragraph.</p><p>Second</p></body></html>`)
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, "png")
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		cfg     fetchurltool.Config
+		url     string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "plain text",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true},
+			url:  server.URL + "/text",
+			want: map[string]any{
+				"url":          server.URL + "/text",
+				"content_type": "text/plain",
+				"content":      "hello world",
+			},
+		},
+		{
+			name: "truncated at size limit",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true, MaxBytes: 10},
+			url:  server.URL + "/large",
+			want: map[string]any{
+				"url":          server.URL + "/large",
+				"content_type": "text/plain",
+				"content":      strings.Repeat("a", 10),
+				"truncated":    true,
+			},
+		},
+		{
+			name: "exact size limit is not truncated",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true, MaxBytes: 100},
+			url:  server.URL + "/large",
+			want: map[string]any{
+				"url":          server.URL + "/large",
+				"content_type": "text/plain",
+				"content":      strings.Repeat("a", 100),
+			},
+		},
+		{
+			name: "html text extraction",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true, ExtractText: true},
+			url:  server.URL + "/html",
+			want: map[string]any{
+				"url":          server.URL + "/html",
+				"content_type": "text/html",
+				"content":      "Title\nFirst paragraph.\nSecond",
+			},
+		},
+		{
+			name:    "content type not allowed",
+			cfg:     fetchurltool.Config{AllowPrivateNetworks: true},
+			url:     server.URL + "/image",
+			wantErr: `content type "image/png"`,
+		},
+		{
+			name:    "custom content type allowlist",
+			cfg:     fetchurltool.Config{AllowPrivateNetworks: true, AllowedContentTypes: []string{"application/json"}},
+			url:     server.URL + "/text",
+			wantErr: `content type "text/plain"`,
+		},
+		{
+			name:    "error status",
+			cfg:     fetchurltool.Config{AllowPrivateNetworks: true},
+			url:     server.URL + "/missing",
+			wantErr: "unexpected status 404",
+		},
+		{
+			name:    "private network rejected by default",
+			url:     server.URL + "/text",
+			wantErr: "private network",
+		},
+		{
+			name:    "unsupported scheme",
+			url:     "file:///etc/passwd",
+			wantErr: "unsupported url scheme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetchTool := newFetchTool(t, tt.cfg)
+
+			got, err := fetchTool.Run(newToolContext(t.Context()), map[string]any{"url": tt.url})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFetchURLTool_Cancellation(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	fetchTool := newFetchTool(t, fetchurltool.Config{AllowPrivateNetworks: true})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := fetchTool.Run(newToolContext(ctx), map[string]any{"url": server.URL}); err == nil {
+		t.Fatal("Run() succeeded, want error for cancelled context")
+	}
+}
+
+func newFetchTool(t *testing.T, cfg fetchurltool.Config) toolinternal.FunctionTool {
+	t.Helper()
+	fetchTool, err := fetchurltool.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	funcTool, ok := fetchTool.(toolinternal.FunctionTool)
+	if !ok {
+		t.Fatal("fetch_url tool does not implement FunctionTool")
+	}
+	return funcTool
+}
+
+func newToolContext(ctx context.Context) tool.Context {
+	return toolinternal.NewToolContext(icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{}), "", nil)
+}
@@ -16,7 +16,10 @@
 package functiontool
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"reflect"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"google.golang.org/genai"
@@ -37,6 +40,17 @@ type Config struct {
 	Description string
 	// An optional JSON schema object defining the expected parameters for the tool.
 	// If it is nil, FunctionTool tries to infer the schema based on the handler type.
+	//
+	// The inferred schema follows the fields of the argument struct, including
+	// the nested structs, slices and maps: the json tags set the property
+	// names, the fields without omitempty or omitzero are required, the
+	// jsonschema tags set the property descriptions and the enum tags list
+	// the comma-separated allowed values, e.g.
+	//
+	//	type Args struct {
+	//		City string `json:"city" jsonschema:"the city name"`
+	//		Unit string `json:"unit,omitempty" jsonschema:"the temperature unit" enum:"celsius,fahrenheit"`
+	//	}
 	InputSchema *jsonschema.Schema
 	// An optional JSON schema object defining the structure of the tool's output.
 	// If it is nil, FunctionTool tries to infer the schema based on the handler type.
@@ -47,11 +61,30 @@ type Config struct {
 
 // Func represents a Go function that can be wrapped in a tool.
 // It takes a tool.Context and a generic argument type, and returns a generic result type.
+//
+// An error returned by the function doesn't stop the agent run: it is sent
+// to the model as the function response {"error": "tool \"<name>\" failed:
+// <message>"}, so that the model can react to it.
 type Func[TArgs, TResults any] func(tool.Context, TArgs) (TResults, error)
 
 // New creates a new tool with a name, description, and the provided handler.
 // Input schema is automatically inferred from the input and output types.
+//
+// The model sends the arguments of function calls as a JSON object, so
+// TArgs must be a struct, a pointer to a struct or a map with string keys.
+// TResults can be any type that can be encoded to JSON, results which
+// aren't JSON objects are sent to the model as {"result": <result>}. New
+// returns an error if the handler doesn't satisfy these constraints.
 func New[TArgs, TResults any](cfg Config, handler Func[TArgs, TResults]) (tool.Tool, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("invalid handler for tool %q: handler is nil", cfg.Name)
+	}
+	if err := validateArgsType(reflect.TypeFor[TArgs]()); err != nil {
+		return nil, fmt.Errorf("invalid handler for tool %q: %w", cfg.Name, err)
+	}
+	if err := validateResultsType(reflect.TypeFor[TResults]()); err != nil {
+		return nil, fmt.Errorf("invalid handler for tool %q: %w", cfg.Name, err)
+	}
 	// TODO: How can we improve UX for functions that does not require an argument, returns a simple type value, or returns a no result?
 	//  https://github.com/modelcontextprotocol/go-sdk/discussions/37
 	ischema, err := resolvedSchema[TArgs](cfg.InputSchema)
@@ -181,12 +214,91 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (map[str
 //  [1] MCP SDK https://pkg.go.dev/github.com/modelcontextprotocol/go-sdk@v0.0.0-20250625213837-ff0d746521c4/mcp#ToolHandler
 //  [2] ADK Python https://github.com/google/adk-python/blob/04de3e197d7a57935488eb7bfa647c7ab62cd9d9/src/google/adk/tools/function_tool.py#L110-L112
 
+// validateArgsType checks that the function call arguments, a JSON object,
+// can be decoded into t.
+func validateArgsType(t reflect.Type) error {
+	switch {
+	case t.Kind() == reflect.Interface:
+		return nil
+	case t.Kind() == reflect.Struct,
+		t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct,
+		t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return validateJSONType(t, "argument")
+	}
+	return fmt.Errorf("argument type %v is not supported, want a struct, a pointer to a struct or a map with string keys", t)
+}
+
+// validateResultsType checks that t can be encoded to JSON.
+func validateResultsType(t reflect.Type) error {
+	return validateJSONType(t, "result")
+}
+
+// validateJSONType checks that values of t can be encoded to and decoded
+// from JSON, following the struct fields, elements and pointers.
+func validateJSONType(t reflect.Type, what string) error {
+	root := t
+	seen := map[reflect.Type]bool{}
+	var validate func(t reflect.Type, field string) error
+	validate = func(t reflect.Type, field string) error {
+		if seen[t] || t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+			return nil
+		}
+		seen[t] = true
+		switch t.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+			if field == "" {
+				return fmt.Errorf("%s type %v is not supported, it can't be encoded to JSON", what, root)
+			}
+			return fmt.Errorf("%s type %v is not supported, field %s has type %v, which can't be encoded to JSON", what, root, field, t)
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			return validate(t.Elem(), field)
+		case reflect.Map:
+			if !isJSONMapKey(t.Key()) {
+				return fmt.Errorf("%s type %v is not supported, map key type %v can't be encoded to JSON", what, root, t.Key())
+			}
+			return validate(t.Elem(), field)
+		case reflect.Struct:
+			for i := range t.NumField() {
+				f := t.Field(i)
+				if !f.IsExported() || f.Tag.Get("json") == "-" {
+					continue
+				}
+				name := f.Name
+				if field != "" {
+					name = field + "." + f.Name
+				}
+				if err := validate(f.Type, name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return validate(t, "")
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// isJSONMapKey reports whether encoding/json supports maps with keys of type t.
+func isJSONMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(textMarshalerType)
+}
+
 func resolvedSchema[T any](override *jsonschema.Schema) (*jsonschema.Resolved, error) {
 	// TODO: check if override schema is compatible with T.
 	if override != nil {
 		return override.Resolve(nil)
 	}
-	schema, err := jsonschema.For[T](nil)
+	schema, err := inferSchema[T]()
 	if err != nil {
 		return nil, err
 	}
@@ -24,9 +24,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/jsonschema-go/jsonschema"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
@@ -184,9 +186,16 @@ func TestFunctionTool_Simple(t *testing.T) {
 }
 
 func TestFunctionTool_DifferentFunctionDeclarations_ConsolidatedInOneGenAiTool(t *testing.T) {
+	type IntArgs struct {
+		X int `json:"x"`
+	}
+	type StringArgs struct {
+		Input string `json:"input"`
+	}
+
 	// First tool
-	identityFunc := func(ctx tool.Context, x int) (int, error) {
-		return x, nil
+	identityFunc := func(ctx tool.Context, args IntArgs) (int, error) {
+		return args.X, nil
 	}
 	identityTool, err := functiontool.New(functiontool.Config{
 		Name:        "identity",
@@ -197,8 +206,8 @@ func TestFunctionTool_DifferentFunctionDeclarations_ConsolidatedInOneGenAiTool(t
 	}
 
 	// Second tool
-	stringIdentityFunc := func(ctx tool.Context, input string) (string, error) {
-		return input, nil
+	stringIdentityFunc := func(ctx tool.Context, args StringArgs) (string, error) {
+		return args.Input, nil
 	}
 	stringIdentityTool, err := functiontool.New(
 		functiontool.Config{
@@ -508,3 +517,252 @@ func stringify(v any) string {
 	}
 	return string(x)
 }
+
+func TestFunctionTool_HandlerError(t *testing.T) {
+	type WeatherArgs struct {
+		City string `json:"city"`
+	}
+	type WeatherResult struct {
+		Forecast string `json:"forecast"`
+	}
+	weatherTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "returns the weather in a city",
+	}, func(ctx tool.Context, args WeatherArgs) (WeatherResult, error) {
+		return WeatherResult{}, fmt.Errorf("unknown city %q", args.City)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Atlantis"}, genai.RoleModel),
+			genai.NewContentFromText("I don't know the weather in Atlantis.", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{Name: "weather_agent", Model: mockModel, Tools: []tool.Tool{weatherTool}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "weather in Atlantis?"))
+	if err != nil {
+		t.Fatalf("the run failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want the function call, its response and the answer", len(events))
+	}
+	responses := events[1].FunctionResponses()
+	if len(responses) != 1 {
+		t.Fatalf("got %d function responses, want 1", len(responses))
+	}
+	want := map[string]any{"error": `tool "get_weather" failed: unknown city "Atlantis"`}
+	if diff := cmp.Diff(want, responses[0].Response); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+	// The model gets the error to react to.
+	lastRequest := mockModel.Requests[len(mockModel.Requests)-1]
+	sent := lastRequest.Contents[len(lastRequest.Contents)-1].Parts[0].FunctionResponse
+	if sent == nil {
+		t.Fatalf("last request content = %v, want the function response", lastRequest.Contents[len(lastRequest.Contents)-1])
+	}
+	if diff := cmp.Diff(want, sent.Response); diff != "" {
+		t.Errorf("function response sent to the model mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_InvalidHandler(t *testing.T) {
+	type Args struct {
+		City string `json:"city"`
+	}
+	type ArgsWithCallback struct {
+		City     string `json:"city"`
+		Callback func() `json:"callback"`
+	}
+	type Nested struct {
+		Updates chan string
+	}
+	type ResultWithChannel struct {
+		Nested Nested `json:"nested"`
+	}
+
+	tests := []struct {
+		name    string
+		newTool func() (tool.Tool, error)
+		wantErr string
+	}{
+		{
+			name: "nil handler",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New[Args, string](functiontool.Config{Name: "tool"}, nil)
+			},
+			wantErr: `invalid handler for tool "tool": handler is nil`,
+		},
+		{
+			name: "basic type argument",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, string) (string, error) { return "", nil })
+			},
+			wantErr: `invalid handler for tool "tool": argument type string is not supported, want a struct, a pointer to a struct or a map with string keys`,
+		},
+		{
+			name: "slice argument",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, []Args) (string, error) { return "", nil })
+			},
+			wantErr: `invalid handler for tool "tool": argument type []functiontool_test.Args is not supported, want a struct, a pointer to a struct or a map with string keys`,
+		},
+		{
+			name: "map argument with int keys",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, map[int]string) (string, error) { return "", nil })
+			},
+			wantErr: `invalid handler for tool "tool": argument type map[int]string is not supported, want a struct, a pointer to a struct or a map with string keys`,
+		},
+		{
+			name: "function field in argument",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, ArgsWithCallback) (string, error) { return "", nil })
+			},
+			wantErr: `invalid handler for tool "tool": argument type functiontool_test.ArgsWithCallback is not supported, field Callback has type func(), which can't be encoded to JSON`,
+		},
+		{
+			name: "channel result",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, Args) (chan string, error) { return nil, nil })
+			},
+			wantErr: `invalid handler for tool "tool": result type chan string is not supported, it can't be encoded to JSON`,
+		},
+		{
+			name: "nested channel in result",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, Args) (*ResultWithChannel, error) { return nil, nil })
+			},
+			wantErr: `invalid handler for tool "tool": result type *functiontool_test.ResultWithChannel is not supported, field Nested.Updates has type chan string, which can't be encoded to JSON`,
+		},
+		{
+			name: "result map with struct keys",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, Args) (map[Args]string, error) { return nil, nil })
+			},
+			wantErr: `invalid handler for tool "tool": result type map[functiontool_test.Args]string is not supported, map key type functiontool_test.Args can't be encoded to JSON`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.newTool()
+			if err == nil {
+				t.Fatalf("New() = %v, want error %q", got, tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("New() error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_ValidHandler(t *testing.T) {
+	type Args struct {
+		City    string         `json:"city"`
+		Options map[string]any `json:"options,omitempty"`
+		hook    func()
+	}
+	tests := []struct {
+		name    string
+		newTool func() (tool.Tool, error)
+	}{
+		{
+			name: "struct argument",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, Args) (string, error) { return "", nil })
+			},
+		},
+		{
+			name: "pointer argument",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, *Args) ([]Args, error) { return nil, nil })
+			},
+		},
+		{
+			name: "map argument",
+			newTool: func() (tool.Tool, error) {
+				return functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, map[string]any) (map[string]any, error) { return nil, nil })
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.newTool(); err != nil {
+				t.Errorf("New() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFunctionTool_InferredSchema(t *testing.T) {
+	type Location struct {
+		City    string `json:"city" jsonschema:"the city name"`
+		Country string `json:"country,omitempty" jsonschema:"the ISO 3166 country code"`
+	}
+	type Args struct {
+		Locations []Location `json:"locations" jsonschema:"the locations to report on"`
+		Unit      string     `json:"unit,omitempty" jsonschema:"the temperature unit" enum:"celsius,fahrenheit"`
+		Days      *int       `json:"days,omitempty" enum:"1,3,7"`
+		Metrics   []string   `json:"metrics,omitempty" enum:"temperature, humidity, wind"`
+	}
+	weatherTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "returns the weather",
+	}, func(ctx tool.Context, args Args) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := weatherTool.(toolinternal.FunctionTool).Declaration().ParametersJsonSchema
+	want := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"locations": {
+				Type:        "array",
+				Description: "the locations to report on",
+				Items: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"city":    {Type: "string", Description: "the city name"},
+						"country": {Type: "string", Description: "the ISO 3166 country code"},
+					},
+					Required:             []string{"city"},
+					AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+				},
+			},
+			"unit":    {Type: "string", Description: "the temperature unit", Enum: []any{"celsius", "fahrenheit"}},
+			"days":    {Types: []string{"null", "integer"}, Enum: []any{int64(1), int64(3), int64(7), nil}},
+			"metrics": {Type: "array", Items: &jsonschema.Schema{Type: "string", Enum: []any{"temperature", "humidity", "wind"}}},
+		},
+		Required:             []string{"locations"},
+		AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(jsonschema.Schema{})); diff != "" {
+		t.Errorf("ParametersJsonSchema mismatch (-want +got):\n%s", diff)
+	}
+
+	// The model can only pick the allowed values.
+	if _, err := weatherTool.(toolinternal.FunctionTool).Run(nil, map[string]any{
+		"locations": []any{map[string]any{"city": "Paris"}},
+		"unit":      "kelvin",
+	}); err == nil {
+		t.Error("Run() with a value not in the enum succeeded, want an error")
+	}
+}
+
+func TestNew_InvalidEnumTag(t *testing.T) {
+	type Args struct {
+		Days int `json:"days" enum:"1,week"`
+	}
+	_, err := functiontool.New(functiontool.Config{Name: "tool"}, func(tool.Context, Args) (string, error) { return "", nil })
+	if err == nil || !strings.Contains(err.Error(), "enum tag on struct field functiontool_test.Args.Days") {
+		t.Errorf("New() error = %v, want an error about the enum tag of Args.Days", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functiontool
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// enumTag is the struct tag listing the comma-separated allowed values of a
+// field, e.g. `enum:"celsius,fahrenheit"`. For slices and arrays, it applies
+// to the elements.
+const enumTag = "enum"
+
+// inferSchema returns the JSON schema of T, see [jsonschema.For], with the
+// allowed values from the enum tags of the struct fields.
+func inferSchema[T any]() (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyEnumTags(reflect.TypeFor[T](), schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// applyEnumTags sets the allowed values of the properties of schema, the
+// schema inferred for t, from the enum tags of the struct fields.
+func applyEnumTags(t reflect.Type, schema *jsonschema.Schema) error {
+	if schema == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return applyEnumTags(t.Elem(), schema.Items)
+	case reflect.Map:
+		return applyEnumTags(t.Elem(), schema.AdditionalProperties)
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(t) {
+			if field.Anonymous {
+				continue
+			}
+			fieldSchema := schema.Properties[jsonFieldName(field)]
+			if fieldSchema == nil {
+				continue
+			}
+			if tag, ok := field.Tag.Lookup(enumTag); ok {
+				if err := setEnum(field.Type, fieldSchema, tag); err != nil {
+					return fmt.Errorf("enum tag on struct field %s.%s: %w", t, field.Name, err)
+				}
+			}
+			if err := applyEnumTags(field.Type, fieldSchema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setEnum sets the allowed values listed in tag on schema, the schema of
+// values of type t, or of their elements if t is a slice or an array.
+func setEnum(t reflect.Type, schema *jsonschema.Schema, tag string) error {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if schema.Items == nil {
+			return fmt.Errorf("no schema for the elements of %v", t)
+		}
+		return setEnum(t.Elem(), schema.Items, tag)
+	}
+	if tag == "" {
+		return fmt.Errorf("no values")
+	}
+	var enum []any
+	for value := range strings.SplitSeq(tag, ",") {
+		v, err := parseEnumValue(t, strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		enum = append(enum, v)
+	}
+	if nullable {
+		enum = append(enum, nil)
+	}
+	schema.Enum = enum
+	return nil
+}
+
+// parseEnumValue parses an enum tag value of a field of type t.
+func parseEnumValue(t reflect.Type, value string) (any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, t.Bits())
+	}
+	return nil, fmt.Errorf("enums of type %v are not supported", t)
+}
+
+// jsonFieldName returns the name of the struct field in JSON, as
+// encoding/json does.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
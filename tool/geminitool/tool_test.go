@@ -91,3 +91,14 @@ func TestGeminiTool_ProcessRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestURLContext_ProcessRequest(t *testing.T) {
+	req := &model.LLMRequest{}
+	if err := (geminitool.URLContext{}).ProcessRequest(nil, req); err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+	want := []*genai.Tool{{URLContext: &genai.URLContext{}}}
+	if diff := cmp.Diff(want, req.Config.Tools); diff != "" {
+		t.Errorf("ProcessRequest returned unexpected tools (-want +got):\n%s", diff)
+	}
+}
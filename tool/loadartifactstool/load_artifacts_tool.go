@@ -39,6 +39,10 @@ type artifactsTool struct {
 }
 
 // New creates a new loadArtifactsTool.
+//
+// When the model calls the tool, the content of the requested artifacts,
+// e.g. images as inline data parts, is attached to the next model request so
+// that the model can see it.
 func New() tool.Tool {
 	return &artifactsTool{
 		name:        "load_artifacts",
@@ -156,26 +160,21 @@ func (t *artifactsTool) processLoadArtifactsFunctionCall(ctx tool.Context, req *
 		return nil
 	}
 	lastContent := req.Contents[len(req.Contents)-1]
-	if lastContent == nil || len(lastContent.Parts) == 0 {
-		return nil
-	}
-	firstPart := lastContent.Parts[0]
-	if firstPart.FunctionResponse == nil {
-		return nil
-	}
-
-	functionResponse := firstPart.FunctionResponse
-
-	if functionResponse.Name != "load_artifacts" {
-		return nil
-	}
-	artifactNamesRaw, ok := functionResponse.Response["artifact_names"]
-	if !ok {
+	if lastContent == nil {
 		return nil
 	}
-	artifactNames, ok := artifactNamesRaw.([]string)
-	if !ok {
-		return fmt.Errorf("invalid artifact names type: %T, expected []string", artifactNamesRaw)
+	// The load_artifacts response may be merged with responses of other
+	// tools called in parallel.
+	var artifactNames []string
+	for _, part := range lastContent.Parts {
+		if part == nil || part.FunctionResponse == nil || part.FunctionResponse.Name != t.name {
+			continue
+		}
+		names, err := responseArtifactNames(part.FunctionResponse.Response)
+		if err != nil {
+			return err
+		}
+		artifactNames = append(artifactNames, names...)
 	}
 	if len(artifactNames) == 0 {
 		return nil
@@ -205,6 +204,30 @@ func (t *artifactsTool) processLoadArtifactsFunctionCall(ctx tool.Context, req *
 	return nil
 }
 
+// responseArtifactNames returns the artifact names of a load_artifacts
+// function response. Responses restored from a persisted session hold the
+// names as []any.
+func responseArtifactNames(response map[string]any) ([]string, error) {
+	switch names := response["artifact_names"].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return names, nil
+	case []any:
+		artifactNames := make([]string, 0, len(names))
+		for _, name := range names {
+			s, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid artifact name type: %T, expected string", name)
+			}
+			artifactNames = append(artifactNames, s)
+		}
+		return artifactNames, nil
+	default:
+		return nil, fmt.Errorf("invalid artifact names type: %T, expected []string", names)
+	}
+}
+
 func (t *artifactsTool) loadIndividualArtifact(ctx context.Context, artifactsService agent.Artifacts, artifactName string) (*genai.Content, error) {
 	resp, err := artifactsService.Load(ctx, artifactName)
 	if err != nil {
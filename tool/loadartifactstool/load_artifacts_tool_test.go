@@ -15,17 +15,23 @@
 package loadartifactstool_test
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/artifact"
 	artifactinternal "google.golang.org/adk/internal/artifact"
 	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/loadartifactstool"
 )
@@ -277,6 +283,107 @@ func TestLoadArtifactsTool_ProcessRequest_Artifacts_OtherFunctionCall(t *testing
 	}
 }
 
+func TestLoadArtifactsTool_ProcessRequest_ParallelFunctionResponses(t *testing.T) {
+	loadArtifactsTool := loadartifactstool.New()
+
+	tc := createToolContext(t)
+	if _, err := tc.Artifacts().Save(t.Context(), "doc1.txt", &genai.Part{Text: "content1"}); err != nil {
+		t.Fatalf("Failed to save artifact: %v", err)
+	}
+
+	// Function responses restored from a persisted session hold []any values,
+	// and the load_artifacts response is not necessarily the first part.
+	llmRequest := &model.LLMRequest{
+		Contents: []*genai.Content{
+			{
+				Role: "user",
+				Parts: []*genai.Part{
+					genai.NewPartFromFunctionResponse("other_function", map[string]any{"some_key": "some_value"}),
+					genai.NewPartFromFunctionResponse("load_artifacts", map[string]any{"artifact_names": []any{"doc1.txt"}}),
+				},
+			},
+		},
+	}
+
+	if err := loadArtifactsTool.(toolinternal.RequestProcessor).ProcessRequest(tc, llmRequest); err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+	if len(llmRequest.Contents) != 2 {
+		t.Fatalf("Expected 2 contents, but got: %v", llmRequest.Contents)
+	}
+	if got := llmRequest.Contents[1].Parts[1].Text; got != "content1" {
+		t.Errorf("Loaded artifact: got %q, want %q", got, "content1")
+	}
+}
+
+func TestLoadArtifactsTool_Image(t *testing.T) {
+	const (
+		appName   = "app"
+		userID    = "user"
+		sessionID = "session"
+	)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	artifactService := artifact.InMemoryService()
+	if _, err := artifactService.Save(t.Context(), &artifact.SaveRequest{
+		AppName: appName, UserID: userID, SessionID: sessionID, FileName: "chart.png",
+		Part: genai.NewPartFromBytes(png, "image/png"),
+	}); err != nil {
+		t.Fatalf("Failed to save artifact: %v", err)
+	}
+
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("load_artifacts", map[string]any{"artifact_names": []any{"chart.png"}}, genai.RoleModel),
+			genai.NewContentFromText("The chart shows a rising trend.", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "image_agent",
+		Model: mockModel,
+		Tools: []tool.Tool{loadartifactstool.New()},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID}); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	r, err := runner.New(runner.Config{
+		AppName:         appName,
+		Agent:           a,
+		SessionService:  sessionService,
+		ArtifactService: artifactService,
+	})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	if _, _, err := r.RunSync(t.Context(), userID, sessionID, genai.NewContentFromText("What does chart.png show?", genai.RoleUser), agent.RunConfig{}); err != nil {
+		t.Fatalf("RunSync() error = %v", err)
+	}
+
+	if len(mockModel.Requests) != 2 {
+		t.Fatalf("model got %d requests, want 2", len(mockModel.Requests))
+	}
+	var instruction strings.Builder
+	for _, part := range mockModel.Requests[0].Config.SystemInstruction.Parts {
+		instruction.WriteString(part.Text)
+	}
+	if !strings.Contains(instruction.String(), `"chart.png"`) {
+		t.Errorf("first request instruction = %q, want it to list chart.png", instruction.String())
+	}
+	contents := mockModel.Requests[1].Contents
+	last := contents[len(contents)-1]
+	if last.Role != genai.RoleUser || len(last.Parts) != 2 {
+		t.Fatalf("last content of the second request = %v, want the loaded artifact", last)
+	}
+	if got := last.Parts[1].InlineData; got == nil || got.MIMEType != "image/png" || !bytes.Equal(got.Data, png) {
+		t.Errorf("loaded artifact part = %v, want inline PNG data", got)
+	}
+}
+
 func createToolContext(t *testing.T) tool.Context {
 	t.Helper()
 
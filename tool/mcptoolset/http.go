@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/oauth2"
+
+	"google.golang.org/adk/tool"
+)
+
+// HTTPConfig provides configuration for a MCP ToolSet which connects to a
+// remote MCP server with the streamable HTTP transport.
+type HTTPConfig struct {
+	// Endpoint is the URL of the MCP endpoint of the server, e.g.
+	// "https://example.com/mcp".
+	Endpoint string
+	// Headers are added to every HTTP request to the server.
+	Headers map[string]string
+	// TokenSource, if set, provides the OAuth2 token sent in the
+	// Authorization header of every HTTP request. The token is refreshed by
+	// the TokenSource when it expires.
+	TokenSource oauth2.TokenSource
+	// TLSConfig, if set, configures the TLS connections to the server, e.g.
+	// with a custom root CA or a client certificate.
+	TLSConfig *tls.Config
+	// HTTPClient is an optional base HTTP client, e.g. with a timeout. Its
+	// transport is wrapped to add the headers and the token. It can't be
+	// used together with TLSConfig.
+	HTTPClient *http.Client
+
+	// Client is an optional custom MCP client to use. If nil, a default client will be created.
+	Client *mcp.Client
+	// ToolFilter selects tools for which tool.Predicate returns true.
+	// See Config.ToolFilter.
+	ToolFilter tool.Predicate
+	// NamePrefix is prepended to the names of the tools exposed to the LLM.
+	// See Config.NamePrefix.
+	NamePrefix string
+	// MCPToolFilter selects the tools of the MCP server for which it returns
+	// true. See Config.MCPToolFilter.
+	MCPToolFilter func(*mcp.Tool) bool
+	// ToolNames renames tools. See Config.ToolNames.
+	ToolNames map[string]string
+	// Resources adds a tool reading the resources of the MCP server.
+	// See Config.Resources.
+	Resources bool
+	// SaveResourceBlobs saves the binary resources as artifacts.
+	// See Config.SaveResourceBlobs.
+	SaveResourceBlobs bool
+}
+
+// NewStreamableHTTP returns a MCP ToolSet connected to the remote MCP server
+// at cfg.Endpoint with the streamable HTTP transport. Like New, it connects
+// lazily on the first request to LLM and reconnects when the connection
+// fails.
+//
+// The caller should call Close to end the MCP session.
+//
+// Example:
+//
+//	ts, err := mcptoolset.NewStreamableHTTP(mcptoolset.HTTPConfig{
+//		Endpoint:    "https://api.githubcopilot.com/mcp/",
+//		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: os.Getenv("GITHUB_PAT")}),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer ts.Close()
+func NewStreamableHTTP(cfg HTTPConfig) (Toolset, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+	if _, err := url.ParseRequestURI(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if cfg.TLSConfig != nil && cfg.HTTPClient != nil {
+		return nil, errors.New("only one of TLSConfig and HTTPClient can be set")
+	}
+
+	var httpClient http.Client
+	if cfg.HTTPClient != nil {
+		httpClient = *cfg.HTTPClient
+	}
+	base := httpClient.Transport
+	if cfg.TLSConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg.TLSConfig
+		base = transport
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cfg.TokenSource != nil {
+		base = &oauth2.Transport{Source: cfg.TokenSource, Base: base}
+	}
+	if len(cfg.Headers) > 0 {
+		base = &headerTransport{headers: cfg.Headers, base: base}
+	}
+	httpClient.Transport = base
+
+	return New(Config{
+		Client: cfg.Client,
+		Transport: &mcp.StreamableClientTransport{
+			Endpoint:   cfg.Endpoint,
+			HTTPClient: &httpClient,
+		},
+		ToolFilter:        cfg.ToolFilter,
+		MCPToolFilter:     cfg.MCPToolFilter,
+		NamePrefix:        cfg.NamePrefix,
+		ToolNames:         cfg.ToolNames,
+		Resources:         cfg.Resources,
+		SaveResourceBlobs: cfg.SaveResourceBlobs,
+	})
+}
+
+// headerTransport adds the headers to the requests.
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request.
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/oauth2"
+
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool/mcptoolset"
+)
+
+// headerRecorder records the headers of the requests to the MCP server.
+type headerRecorder struct {
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (r *headerRecorder) wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		r.headers = append(r.headers, req.Header.Clone())
+		r.mu.Unlock()
+		h.ServeHTTP(w, req)
+	})
+}
+
+func (r *headerRecorder) get() []http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers
+}
+
+func newHTTPWeatherServer(t *testing.T) (*httptest.Server, *headerRecorder) {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_weather", Description: "returns weather in the given city"}, weatherFunc)
+	recorder := &headerRecorder{}
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	httpServer := httptest.NewTLSServer(recorder.wrap(handler))
+	t.Cleanup(httpServer.Close)
+	return httpServer, recorder
+}
+
+func TestNewStreamableHTTP(t *testing.T) {
+	httpServer, recorder := newHTTPWeatherServer(t)
+	tlsConfig := &tls.Config{RootCAs: httpServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+
+	ts, err := mcptoolset.NewStreamableHTTP(mcptoolset.HTTPConfig{
+		Endpoint:    httpServer.URL,
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}),
+		TLSConfig:   tlsConfig,
+	})
+	if err != nil {
+		t.Fatalf("NewStreamableHTTP() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	weatherTool := getFunctionTool(t, ts, "get_weather")
+	toolCtx := toolinternal.NewToolContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}), "", nil)
+	got, err := weatherTool.Run(toolCtx, map[string]any{"city": "london"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := map[string]any{"output": map[string]any{"weather_summary": `Today in "london" is sunny`}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+	}
+
+	headers := recorder.get()
+	if len(headers) == 0 {
+		t.Fatal("the server received no requests")
+	}
+	for i, h := range headers {
+		if got := h.Get("X-Api-Key"); got != "secret" {
+			t.Errorf("request %d: X-Api-Key = %q, want %q", i, got, "secret")
+		}
+		if got := h.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("request %d: Authorization = %q, want %q", i, got, "Bearer token")
+		}
+	}
+}
+
+func TestNewStreamableHTTP_UntrustedCertificate(t *testing.T) {
+	httpServer, _ := newHTTPWeatherServer(t)
+
+	ts, err := mcptoolset.NewStreamableHTTP(mcptoolset.HTTPConfig{Endpoint: httpServer.URL})
+	if err != nil {
+		t.Fatalf("NewStreamableHTTP() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	if _, err := ts.Tools(newReadonlyContext(t)); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("Tools() error = %v, want a certificate error", err)
+	}
+}
+
+func TestNewStreamableHTTP_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     mcptoolset.HTTPConfig
+		wantErr string
+	}{
+		{
+			name:    "no endpoint",
+			wantErr: "endpoint is required",
+		},
+		{
+			name:    "invalid endpoint",
+			cfg:     mcptoolset.HTTPConfig{Endpoint: "example.com/mcp"},
+			wantErr: "invalid endpoint",
+		},
+		{
+			name: "TLS config and HTTP client",
+			cfg: mcptoolset.HTTPConfig{
+				Endpoint:   "https://example.com/mcp",
+				TLSConfig:  &tls.Config{},
+				HTTPClient: &http.Client{},
+			},
+			wantErr: "only one of TLSConfig and HTTPClient",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mcptoolset.NewStreamableHTTP(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewStreamableHTTP() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
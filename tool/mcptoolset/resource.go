@@ -0,0 +1,267 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/internal/toolinternal/toolutils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// readResourceToolName is the name of the tool reading the resources of the
+// MCP server, before NamePrefix.
+const readResourceToolName = "read_resource"
+
+// Resources lists the resources and the resource templates of the MCP server.
+func (s *set) Resources(ctx context.Context) ([]*mcp.Resource, []*mcp.ResourceTemplate, error) {
+	var resources []*mcp.Resource
+	var templates []*mcp.ResourceTemplate
+	err := s.call(ctx, func(session *mcp.ClientSession) error {
+		resources, templates = nil, nil
+		for r, err := range session.Resources(ctx, nil) {
+			if err != nil {
+				return err
+			}
+			resources = append(resources, r)
+		}
+		for t, err := range session.ResourceTemplates(ctx, nil) {
+			if err != nil {
+				return err
+			}
+			templates = append(templates, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list MCP resources: %w", err)
+	}
+	return resources, templates, nil
+}
+
+// ReadResource reads the resource with the given URI from the MCP server.
+func (s *set) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	var res *mcp.ReadResourceResult
+	err := s.call(ctx, func(session *mcp.ClientSession) (err error) {
+		res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP resource %q: %w", uri, err)
+	}
+	return res, nil
+}
+
+// Prompt gets the prompt with the given name and arguments from the MCP
+// server and returns the text of its messages, separated by blank lines.
+func (s *set) Prompt(ctx context.Context, name string, args map[string]string) (string, error) {
+	var res *mcp.GetPromptResult
+	err := s.call(ctx, func(session *mcp.ClientSession) (err error) {
+		res, err = session.GetPrompt(ctx, &mcp.GetPromptParams{Name: name, Arguments: args})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get MCP prompt %q: %w", name, err)
+	}
+	var texts []string
+	for _, msg := range res.Messages {
+		switch c := msg.Content.(type) {
+		case *mcp.TextContent:
+			texts = append(texts, c.Text)
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil && c.Resource.Text != "" {
+				texts = append(texts, c.Resource.Text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// supportsResources reports whether the MCP server declares the resources
+// capability.
+func (s *set) supportsResources(ctx context.Context) (bool, error) {
+	var supported bool
+	err := s.call(ctx, func(session *mcp.ClientSession) error {
+		res := session.InitializeResult()
+		supported = res != nil && res.Capabilities != nil && res.Capabilities.Resources != nil
+		return nil
+	})
+	return supported, err
+}
+
+// newReadResourceTool returns the tool reading the resources of the MCP
+// server, or nil if the server has no resources.
+func newReadResourceTool(ctx context.Context, s *set) (*readResourceTool, error) {
+	if ok, err := s.supportsResources(ctx); err != nil || !ok {
+		return nil, err
+	}
+	resources, templates, err := s.Resources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 && len(templates) == 0 {
+		return nil, nil
+	}
+
+	description := "Reads a resource of the MCP server by its URI and returns its contents."
+	return &readResourceTool{
+		name:        readResourceToolName,
+		description: description,
+		funcDeclaration: &genai.FunctionDeclaration{
+			Name:        readResourceToolName,
+			Description: description + "\n\n" + describeResources(resources, templates),
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"uri": {
+						Type:        genai.TypeString,
+						Description: "The URI of the resource: one of the listed resource URIs, or a URI matching one of the listed URI templates with the {variables} replaced.",
+					},
+				},
+				Required: []string{"uri"},
+			},
+		},
+		set: s,
+	}, nil
+}
+
+// describeResources returns the list of the resources and the resource
+// templates for the declaration of the read_resource tool.
+func describeResources(resources []*mcp.Resource, templates []*mcp.ResourceTemplate) string {
+	var b strings.Builder
+	describe := func(uri, name, description, mimeType string) {
+		fmt.Fprintf(&b, "- %s", uri)
+		if name != "" {
+			fmt.Fprintf(&b, " (%s)", name)
+		}
+		if description != "" {
+			fmt.Fprintf(&b, ": %s", description)
+		}
+		if mimeType != "" {
+			fmt.Fprintf(&b, " [%s]", mimeType)
+		}
+		b.WriteString("\n")
+	}
+	if len(resources) > 0 {
+		b.WriteString("Resources:\n")
+		for _, r := range resources {
+			describe(r.URI, r.Name, r.Description, r.MIMEType)
+		}
+	}
+	if len(templates) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Resource URI templates (RFC 6570):\n")
+		for _, t := range templates {
+			describe(t.URITemplate, t.Name, t.Description, t.MIMEType)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+type readResourceTool struct {
+	name            string
+	description     string
+	funcDeclaration *genai.FunctionDeclaration
+
+	// set provides the MCP session for the tool calls.
+	set *set
+}
+
+// setName sets the name exposed to the LLM.
+func (t *readResourceTool) setName(name string) {
+	t.name = name
+	t.funcDeclaration.Name = name
+}
+
+// Name implements the tool.Tool.
+func (t *readResourceTool) Name() string {
+	return t.name
+}
+
+// Description implements the tool.Tool.
+func (t *readResourceTool) Description() string {
+	return t.description
+}
+
+// IsLongRunning implements the tool.Tool.
+func (t *readResourceTool) IsLongRunning() bool {
+	return false
+}
+
+func (t *readResourceTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	return toolutils.PackTool(req, t)
+}
+
+func (t *readResourceTool) Declaration() *genai.FunctionDeclaration {
+	return t.funcDeclaration
+}
+
+// Run reads the resource. Text contents are returned as text. Binary
+// contents are saved as artifacts named after their URI if the tool set is
+// configured with SaveResourceBlobs, and returned base64-encoded otherwise.
+func (t *readResourceTool) Run(ctx tool.Context, args any) (map[string]any, error) {
+	m, _ := args.(map[string]any)
+	uri, _ := m["uri"].(string)
+	if uri == "" {
+		return nil, errors.New("uri is required")
+	}
+	res, err := t.set.ReadResource(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]any, 0, len(res.Contents))
+	for _, c := range res.Contents {
+		content := map[string]any{"uri": c.URI}
+		if c.MIMEType != "" {
+			content["mime_type"] = c.MIMEType
+		}
+		switch {
+		case c.Blob != nil && t.set.saveResourceBlobs:
+			mimeType := c.MIMEType
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			resp, err := ctx.Artifacts().Save(ctx, c.URI, genai.NewPartFromBytes(c.Blob, mimeType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to save MCP resource %q as artifact: %w", c.URI, err)
+			}
+			content["artifact"] = c.URI
+			content["artifact_version"] = resp.Version
+		case c.Blob != nil:
+			content["blob"] = base64.StdEncoding.EncodeToString(c.Blob)
+		default:
+			content["text"] = c.Text
+		}
+		contents = append(contents, content)
+	}
+	return map[string]any{"contents": contents}, nil
+}
+
+var (
+	_ toolinternal.FunctionTool     = (*readResourceTool)(nil)
+	_ toolinternal.RequestProcessor = (*readResourceTool)(nil)
+)
@@ -0,0 +1,288 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/artifact"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/mcptoolset"
+)
+
+var mapPNG = []byte("\x89PNG map")
+
+// newDocsServer returns a MCP server with a static resource, a templated
+// resource and a prompt.
+func newDocsServer(t *testing.T) mcp.Transport {
+	t.Helper()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	server := mcp.NewServer(&mcp.Implementation{Name: "docs_server", Version: "v1.0.0"}, nil)
+	server.AddResource(&mcp.Resource{
+		URI:         "docs://readme",
+		Name:        "readme",
+		Description: "how to use the service",
+		MIMEType:    "text/markdown",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "text/markdown", Text: "# Weather service"},
+		}}, nil
+	})
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "maps://{city}",
+		Name:        "map",
+		Description: "map of the city",
+		MIMEType:    "image/png",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "image/png", Blob: mapPNG},
+		}}, nil
+	})
+	server.AddPrompt(&mcp.Prompt{
+		Name:      "forecaster",
+		Arguments: []*mcp.PromptArgument{{Name: "city", Required: true}},
+	}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: "You forecast the weather."}},
+			{Role: "user", Content: &mcp.TextContent{Text: "Focus on " + req.Params.Arguments["city"] + "."}},
+		}}, nil
+	})
+	if _, err := server.Connect(t.Context(), serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	return clientTransport
+}
+
+func TestReadResourceTool(t *testing.T) {
+	ts, err := mcptoolset.New(mcptoolset.Config{
+		Transport:  newDocsServer(t),
+		Resources:  true,
+		NamePrefix: "docs_",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	readTool := getFunctionTool(t, ts, "docs_read_resource")
+	decl := readTool.Declaration()
+	if decl.Name != "docs_read_resource" {
+		t.Errorf("declaration name = %q, want %q", decl.Name, "docs_read_resource")
+	}
+	for _, want := range []string{
+		"- docs://readme (readme): how to use the service [text/markdown]",
+		"- maps://{city} (map): map of the city [image/png]",
+	} {
+		if !strings.Contains(decl.Description, want) {
+			t.Errorf("declaration description = %q, want it to contain %q", decl.Description, want)
+		}
+	}
+
+	toolCtx := toolinternal.NewToolContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}), "", nil)
+	tests := []struct {
+		uri  string
+		want map[string]any
+	}{
+		{
+			uri: "docs://readme",
+			want: map[string]any{"contents": []any{
+				map[string]any{"uri": "docs://readme", "mime_type": "text/markdown", "text": "# Weather service"},
+			}},
+		},
+		{
+			uri: "maps://london",
+			want: map[string]any{"contents": []any{
+				map[string]any{"uri": "maps://london", "mime_type": "image/png", "blob": base64.StdEncoding.EncodeToString(mapPNG)},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := readTool.Run(toolCtx, map[string]any{"uri": tt.uri})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := readTool.Run(toolCtx, map[string]any{"uri": "docs://missing"}); err == nil {
+		t.Error("Run() with an unknown resource succeeded, want error")
+	}
+}
+
+func TestReadResourceTool_SaveResourceBlobs(t *testing.T) {
+	ts, err := mcptoolset.New(mcptoolset.Config{
+		Transport:         newDocsServer(t),
+		Resources:         true,
+		SaveResourceBlobs: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "read_resource", Args: map[string]any{"uri": "maps://london"}}}}},
+		genai.NewContentFromText("here is the map", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "map_agent", Model: llm, Toolsets: []tool.Toolset{ts}})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	sessionService := session.InMemoryService()
+	artifactService := artifact.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:         "testApp",
+		Agent:           a,
+		SessionService:  sessionService,
+		ArtifactService: artifactService,
+	})
+	if err != nil {
+		t.Fatalf("runner.New() error = %v", err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotResponse map[string]any
+	for event, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("show me london", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		for _, part := range event.Content.Parts {
+			if part.FunctionResponse != nil {
+				gotResponse = part.FunctionResponse.Response
+			}
+		}
+	}
+	wantResponse := map[string]any{"contents": []any{
+		map[string]any{"uri": "maps://london", "mime_type": "image/png", "artifact": "maps://london", "artifact_version": int64(1)},
+	}}
+	if diff := cmp.Diff(wantResponse, gotResponse); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+
+	resp, err := artifactService.Load(t.Context(), &artifact.LoadRequest{AppName: "testApp", UserID: "user", SessionID: "session", FileName: "maps://london"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := genai.NewPartFromBytes(mapPNG, "image/png")
+	if diff := cmp.Diff(want, resp.Part); diff != "" {
+		t.Errorf("artifact mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadResourceTool_NotAdded(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  mcptoolset.Config
+	}{
+		{
+			name: "resources not enabled",
+			cfg:  mcptoolset.Config{Transport: newDocsServer(t)},
+		},
+		{
+			name: "server without resources",
+			cfg:  mcptoolset.Config{Transport: newWeatherServer(t, "get_weather"), Resources: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := mcptoolset.New(tt.cfg)
+			if err != nil {
+				t.Fatalf("Failed to create MCP tool set: %v", err)
+			}
+			t.Cleanup(func() { _ = ts.Close() })
+
+			tools, err := ts.Tools(newReadonlyContext(t))
+			if err != nil {
+				t.Fatalf("Tools() error = %v", err)
+			}
+			for _, tl := range tools {
+				if tl.Name() == "read_resource" {
+					t.Errorf("Tools() returned the read_resource tool")
+				}
+			}
+		})
+	}
+}
+
+func TestResources(t *testing.T) {
+	ts, err := mcptoolset.New(mcptoolset.Config{Transport: newDocsServer(t)})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	resources, templates, err := ts.Resources(t.Context())
+	if err != nil {
+		t.Fatalf("Resources() error = %v", err)
+	}
+	var got []string
+	for _, r := range resources {
+		got = append(got, r.URI)
+	}
+	for _, tmpl := range templates {
+		got = append(got, tmpl.URITemplate)
+	}
+	if diff := cmp.Diff([]string{"docs://readme", "maps://{city}"}, got); diff != "" {
+		t.Errorf("Resources() mismatch (-want +got):\n%s", diff)
+	}
+
+	res, err := ts.ReadResource(t.Context(), "docs://readme")
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if len(res.Contents) != 1 || res.Contents[0].Text != "# Weather service" {
+		t.Errorf("ReadResource() contents = %+v, want the readme", res.Contents)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	ts, err := mcptoolset.New(mcptoolset.Config{Transport: newDocsServer(t)})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	got, err := ts.Prompt(t.Context(), "forecaster", map[string]string{"city": "london"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if want := "You forecast the weather.\n\nFocus on london."; got != want {
+		t.Errorf("Prompt() = %q, want %q", got, want)
+	}
+
+	if _, err := ts.Prompt(t.Context(), "missing", nil); err == nil {
+		t.Error("Prompt() with an unknown prompt succeeded, want error")
+	}
+}
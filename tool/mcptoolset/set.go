@@ -17,12 +17,21 @@ package mcptoolset
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
 	"google.golang.org/adk/internal/version"
 	"google.golang.org/adk/tool"
 )
@@ -32,35 +41,77 @@ import (
 // passes them to the LLM.
 // It uses https://github.com/modelcontextprotocol/go-sdk for MCP communication.
 // MCP session is created lazily on the first request to LLM.
+// If the connection to the MCP server fails, the ToolSet reconnects with a
+// capped exponential backoff, and tool calls are retried a bounded number of
+// times before they return an error. After reconnecting, the tools of the
+// server are listed again, and a warning is logged if they changed.
 //
 // Usage: create MCP ToolSet with mcptoolset.New() and provide it to the
-// LLMAgent in the llmagent.Config.
+// LLMAgent in the llmagent.Config. Callers should defer Close() to release
+// the MCP session.
 //
 // Example:
 //
+//	ts, err := mcptoolset.New(mcptoolset.Config{
+//		Transport: &mcp.StreamableClientTransport{Endpoint: "http://localhost:8080/mcp"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer ts.Close()
+//
 //	llmagent.New(llmagent.Config{
 //		Name:        "agent_name",
 //		Model:       model,
 //		Description: "...",
 //		Instruction: "...",
-//		Toolsets: []tool.Set{
-//			mcptoolset.New(mcptoolset.Config{
-//				Transport: &mcp.CommandTransport{Command: exec.Command("myserver")}
-//			}),
-//		},
+//		Toolsets:    []tool.Toolset{ts},
 //	})
-func New(cfg Config) (tool.Toolset, error) {
+func New(cfg Config) (Toolset, error) {
 	client := cfg.Client
 	if client == nil {
 		client = mcp.NewClient(&mcp.Implementation{Name: "adk-mcp-client", Version: version.Version}, nil)
 	}
 	return &set{
-		client:     client,
-		transport:  cfg.Transport,
-		toolFilter: cfg.ToolFilter,
+		connect: func(ctx context.Context) (*mcp.ClientSession, error) {
+			return client.Connect(ctx, cfg.Transport, nil)
+		},
+		toolFilter:        cfg.ToolFilter,
+		mcpToolFilter:     cfg.MCPToolFilter,
+		namePrefix:        cfg.NamePrefix,
+		toolNames:         cfg.ToolNames,
+		resources:         cfg.Resources,
+		saveResourceBlobs: cfg.SaveResourceBlobs,
 	}, nil
 }
 
+// Toolset is a MCP ToolSet. Close closes the MCP session, after which the
+// ToolSet can't be used.
+//
+// Besides the tools, it gives access to the resources and the prompts of the
+// MCP server. For example, a prompt can be used as the instruction of an
+// agent:
+//
+//	llmagent.Config{
+//		InstructionProvider: func(ctx agent.ReadonlyContext) (string, error) {
+//			return ts.Prompt(ctx, "code_review", map[string]string{"language": "go"})
+//		},
+//		...
+//	}
+type Toolset interface {
+	tool.Toolset
+	io.Closer
+
+	// Resources lists the resources and the resource templates of the MCP
+	// server.
+	Resources(ctx context.Context) ([]*mcp.Resource, []*mcp.ResourceTemplate, error)
+	// ReadResource reads the resource with the given URI from the MCP server.
+	ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error)
+	// Prompt gets the prompt with the given name and arguments from the MCP
+	// server and returns the text of its messages, separated by blank lines.
+	Prompt(ctx context.Context, name string, args map[string]string) (string, error)
+}
+
 // Config provides initial configuration for the MCP ToolSet.
 type Config struct {
 	// Client is an optional custom MCP client to use. If nil, a default client will be created.
@@ -69,17 +120,55 @@ type Config struct {
 	Transport mcp.Transport
 	// ToolFilter selects tools for which tool.Predicate returns true.
 	// If ToolFilter is nil, then all tools are returned.
-	// tool.StringPredicate can be convenient if there's a known fixed list of tool names.
+	// tool.StringPredicate can be convenient if there's a known fixed list of tool names,
+	// and tool.DenyPredicate if there's a list of tool names to exclude.
+	// The filter is applied to the tool names of the MCP server, without NamePrefix.
 	ToolFilter tool.Predicate
+	// NamePrefix is prepended to the names of the tools exposed to the LLM, e.g. "github_",
+	// to avoid collisions with the tools of other tool sets. The tools are still called by
+	// their names on the MCP server. Tools with the same name in several tool sets of an
+	// agent are reported as duplicate tools.
+	NamePrefix string
+	// MCPToolFilter selects the tools of the MCP server for which it returns
+	// true, e.g. by their annotations. It is applied together with ToolFilter.
+	// If MCPToolFilter is nil, then all tools are returned.
+	MCPToolFilter func(*mcp.Tool) bool
+	// ToolNames renames tools: it maps the names of the tools on the MCP
+	// server to the names exposed to the LLM, which are used instead of the
+	// names with NamePrefix.
+	ToolNames map[string]string
+	// Resources adds a "read_resource" tool, which reads the resources of the
+	// MCP server, if the server has any. Its declaration lists the resources
+	// and the resource templates of the server. ToolFilter, NamePrefix and
+	// ToolNames apply to it like to the tools of the server.
+	Resources bool
+	// SaveResourceBlobs makes the "read_resource" tool save the binary
+	// contents of the resources as artifacts named after the resource URIs,
+	// instead of returning them base64-encoded to the LLM. It requires an
+	// artifact service.
+	SaveResourceBlobs bool
 }
 
 type set struct {
-	client     *mcp.Client
-	transport  mcp.Transport
-	toolFilter tool.Predicate
+	// connect creates a new MCP session.
+	connect       func(ctx context.Context) (*mcp.ClientSession, error)
+	toolFilter    tool.Predicate
+	mcpToolFilter func(*mcp.Tool) bool
+	namePrefix    string
+	toolNames     map[string]string
+	// resources adds the read_resource tool.
+	resources         bool
+	saveResourceBlobs bool
 
 	mu      sync.Mutex
 	session *mcp.ClientSession
+	closed  bool
+	// connected reports whether a session was created before, so that a new
+	// session is a reconnection.
+	connected bool
+	// serverTools are the definitions of the tools of the server, keyed by
+	// name, as last listed.
+	serverTools map[string]string
 }
 
 func (*set) Name() string {
@@ -94,35 +183,36 @@ func (*set) IsLongRunning() bool {
 	return false
 }
 
-// Tools fetch MCP tools from the server, convert to adk tool.Tool and filter by name.
+// Tools fetch MCP tools from the server, convert to adk tool.Tool, filter them and rename or prefix their names.
 func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
-	session, err := s.getSession(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get MCP session: %w", err)
-	}
-
 	var adkTools []tool.Tool
 
 	cursor := ""
 	for {
-		resp, err := session.ListTools(ctx, &mcp.ListToolsParams{
-			Cursor: cursor,
+		var resp *mcp.ListToolsResult
+		err := s.call(ctx, func(session *mcp.ClientSession) (err error) {
+			resp, err = session.ListTools(ctx, &mcp.ListToolsParams{
+				Cursor: cursor,
+			})
+			return err
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list MCP tools: %w", err)
 		}
 
+		s.recordTools(cursor == "", resp.Tools)
 		for _, mcpTool := range resp.Tools {
-			t, err := convertTool(mcpTool, s.getSession)
+			if s.mcpToolFilter != nil && !s.mcpToolFilter(mcpTool) {
+				continue
+			}
+			t, err := convertTool(mcpTool, s)
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert MCP tool %q to adk tool: %w", mcpTool.Name, err)
 			}
 
-			if s.toolFilter != nil && !s.toolFilter(ctx, t) {
-				continue
+			if s.expose(ctx, t, mcpTool.Name) {
+				adkTools = append(adkTools, t)
 			}
-
-			adkTools = append(adkTools, t)
 		}
 
 		if resp.NextCursor == "" {
@@ -131,22 +221,241 @@ func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
 		cursor = resp.NextCursor
 	}
 
+	if s.resources {
+		t, err := newReadResourceTool(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil && s.expose(ctx, t, readResourceToolName) {
+			adkTools = append(adkTools, t)
+		}
+	}
+
 	return adkTools, nil
 }
 
-func (s *set) getSession(ctx context.Context) (*mcp.ClientSession, error) {
+// renamableTool is a tool whose name exposed to the LLM can be changed.
+type renamableTool interface {
+	tool.Tool
+	setName(name string)
+}
+
+// expose applies the tool filter to the tool named name on the MCP server and
+// renames or prefixes its name. It reports whether the tool passes the filter.
+func (s *set) expose(ctx agent.ReadonlyContext, t renamableTool, name string) bool {
+	if s.toolFilter != nil && !s.toolFilter(ctx, t) {
+		return false
+	}
+	if newName, ok := s.toolNames[name]; ok {
+		t.setName(newName)
+	} else if s.namePrefix != "" {
+		t.setName(s.namePrefix + name)
+	}
+	return true
+}
+
+// maxReconnects bounds how many times a request is retried with a new
+// session after the connection to the MCP server fails.
+const maxReconnects = 2
+
+// The delay before reconnecting doubles with every attempt, from
+// minReconnectDelay up to maxReconnectDelay.
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 2 * time.Second
+)
+
+// call calls fn with the MCP session. If the connection to the MCP server
+// fails, call reconnects and retries fn up to maxReconnects times.
+func (s *set) call(ctx context.Context, fn func(session *mcp.ClientSession) error) error {
+	for attempt := 0; ; attempt++ {
+		session, reconnected, err := s.getSession(ctx)
+		if err == nil {
+			if reconnected {
+				s.checkTools(ctx, session)
+			}
+			err = fn(session)
+			if err == nil || !isConnectionError(err) {
+				return err
+			}
+			s.dropSession(session)
+		} else if attempt == 0 || errors.Is(err, errClosed) {
+			// Only reconnections are retried.
+			return err
+		}
+		if attempt == maxReconnects || ctx.Err() != nil {
+			return fmt.Errorf("lost connection to MCP server: %w", err)
+		}
+		if err := sleep(ctx, reconnectDelay(attempt)); err != nil {
+			return fmt.Errorf("lost connection to MCP server: %w", err)
+		}
+	}
+}
+
+// reconnectDelay returns the delay before the given reconnection attempt,
+// starting from 0.
+func reconnectDelay(attempt int) time.Duration {
+	delay := minReconnectDelay << attempt
+	if delay <= 0 || delay > maxReconnectDelay {
+		return maxReconnectDelay
+	}
+	return delay
+}
+
+// sleep waits for d or until ctx is done, in which case it returns the
+// context error.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recordTools records the definitions of the tools listed from the server.
+// The first page of a listing replaces the previous definitions.
+func (s *set) recordTools(firstPage bool, tools []*mcp.Tool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if firstPage || s.serverTools == nil {
+		s.serverTools = make(map[string]string)
+	}
+	for _, t := range tools {
+		s.serverTools[t.Name] = toolDefinition(t)
+	}
+}
+
+// checkTools lists the tools of the server after reconnecting, and logs a
+// warning if they changed since they were last listed, e.g. because a new
+// version of the server was started.
+func (s *set) checkTools(ctx context.Context, session *mcp.ClientSession) {
+	s.mu.Lock()
+	before := s.serverTools
+	s.mu.Unlock()
+	if before == nil {
+		return
+	}
+	after := make(map[string]string)
+	for t, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			// The call itself reports the connection errors.
+			return
+		}
+		after[t.Name] = toolDefinition(t)
+	}
+	s.mu.Lock()
+	s.serverTools = after
+	s.mu.Unlock()
 
+	var added, removed, changed []string
+	for name, def := range after {
+		if beforeDef, ok := before[name]; !ok {
+			added = append(added, name)
+		} else if def != beforeDef {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	if len(added)+len(removed)+len(changed) == 0 {
+		return
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	slices.Sort(changed)
+	runconfig.Logger(ctx).LogAttrs(ctx, slog.LevelWarn, "MCP server tools changed after reconnecting",
+		slog.Any("added", added),
+		slog.Any("removed", removed),
+		slog.Any("changed", changed),
+	)
+}
+
+// toolDefinition returns the definition of the tool to compare it with
+// the previous definitions.
+func toolDefinition(t *mcp.Tool) string {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// isConnectionError reports whether err means that the connection to the MCP
+// server is broken.
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, mcp.ErrConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.As(err, &opErr)
+}
+
+// getSession returns the MCP session, creating it if needed. It reports
+// whether the session replaces a previous one.
+func (s *set) getSession(ctx context.Context) (session *mcp.ClientSession, reconnected bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, false, errClosed
+	}
 	if s.session != nil {
-		return s.session, nil
+		return s.session, false, nil
 	}
 
-	session, err := s.client.Connect(ctx, s.transport, nil)
+	session, err = s.connect(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init MCP session: %w", err)
+		return nil, false, fmt.Errorf("failed to init MCP session: %w", err)
 	}
 
+	reconnected = s.connected
+	s.connected = true
 	s.session = session
-	return s.session, nil
+	go s.watch(session)
+	return s.session, reconnected, nil
+}
+
+// watch waits for the server to close the session, e.g. because the server
+// process exited, and forgets the session so that the next request creates
+// a new one.
+func (s *set) watch(session *mcp.ClientSession) {
+	_ = session.Wait()
+	s.dropSession(session)
 }
+
+// dropSession closes the session and forgets it, if it's still the current
+// one, so that the next request creates a new session.
+func (s *set) dropSession(session *mcp.ClientSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == session {
+		s.session = nil
+		_ = session.Close()
+	}
+}
+
+// Close implements io.Closer.
+func (s *set) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.session == nil {
+		return nil
+	}
+	session := s.session
+	s.session = nil
+	return session.Close()
+}
+
+var errClosed = errors.New("MCP tool set is closed")
@@ -15,14 +15,17 @@
 package mcptoolset_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"iter"
 	"log"
+	"log/slog"
 	"net/http"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -31,12 +34,13 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/agent/runconfig"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
-	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/mcptoolset"
@@ -97,7 +101,7 @@ func TestMCPToolSet(t *testing.T) {
 	}
 
 	prompt := "what is the weather in london?"
-	runner := newTestAgentRunner(t, agent)
+	runner := testutil.NewTestAgentRunner(t, agent)
 
 	var gotEvents []*session.Event
 	for event, err := range runner.Run(t, "session1", prompt) {
@@ -197,113 +201,394 @@ func newGeminiModel(t *testing.T, modelName string) model.LLM {
 	return model
 }
 
-func newTestAgentRunner(t *testing.T, agent agent.Agent) *testAgentRunner {
-	appName := "test_app"
-	sessionService := session.InMemoryService()
-
-	runner, err := runner.New(runner.Config{
-		AppName:        appName,
-		Agent:          agent,
-		SessionService: sessionService,
-	})
-	if err != nil {
-		t.Fatal(err)
+// newWeatherServer runs an in-memory MCP server with the weather tools, and
+// returns the transport to connect to it.
+func newWeatherServer(t *testing.T, toolNames ...string) mcp.Transport {
+	t.Helper()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
+	for _, name := range toolNames {
+		mcp.AddTool(server, &mcp.Tool{Name: name, Description: "returns weather in the given city"}, weatherFunc)
 	}
-
-	return &testAgentRunner{
-		agent:          agent,
-		sessionService: sessionService,
-		appName:        appName,
-		runner:         runner,
+	if _, err := server.Connect(t.Context(), serverTransport, nil); err != nil {
+		t.Fatal(err)
 	}
+	return clientTransport
 }
 
-type testAgentRunner struct {
-	agent          agent.Agent
-	sessionService session.Service
-	lastSession    session.Session
-	appName        string
-	// TODO: move runner definition to the adk package and it's a part of public api, but the logic to the internal runner
-	runner *runner.Runner
+func TestToolFilter(t *testing.T) {
+	serverTools := []string{"get_weather", "get_forecast", "delete_city"}
+
+	tests := []struct {
+		name       string
+		filter     tool.Predicate
+		mcpFilter  func(*mcp.Tool) bool
+		namePrefix string
+		toolNames  map[string]string
+		want       []string
+	}{
+		{
+			name: "all tools",
+			want: serverTools,
+		},
+		{
+			name:   "allow list",
+			filter: tool.StringPredicate([]string{"get_weather"}),
+			want:   []string{"get_weather"},
+		},
+		{
+			name:   "deny list",
+			filter: tool.DenyPredicate([]string{"delete_city"}),
+			want:   []string{"get_weather", "get_forecast"},
+		},
+		{
+			name: "predicate",
+			filter: func(ctx agent.ReadonlyContext, t tool.Tool) bool {
+				return strings.HasPrefix(t.Name(), "get_")
+			},
+			want: []string{"get_weather", "get_forecast"},
+		},
+		{
+			name:       "name prefix",
+			namePrefix: "weather_",
+			want:       []string{"weather_get_weather", "weather_get_forecast", "weather_delete_city"},
+		},
+		{
+			name:       "filter on server names with name prefix",
+			filter:     tool.StringPredicate([]string{"get_forecast"}),
+			namePrefix: "weather_",
+			want:       []string{"weather_get_forecast"},
+		},
+		{
+			name: "MCP tool predicate",
+			mcpFilter: func(t *mcp.Tool) bool {
+				return t.Name == "get_forecast"
+			},
+			want: []string{"get_forecast"},
+		},
+		{
+			name:       "renamed tools",
+			namePrefix: "weather_",
+			toolNames:  map[string]string{"get_weather": "current_weather"},
+			want:       []string{"current_weather", "weather_get_forecast", "weather_delete_city"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := mcptoolset.New(mcptoolset.Config{
+				Transport:     newWeatherServer(t, serverTools...),
+				ToolFilter:    tt.filter,
+				MCPToolFilter: tt.mcpFilter,
+				NamePrefix:    tt.namePrefix,
+				ToolNames:     tt.toolNames,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create MCP tool set: %v", err)
+			}
+
+			tools, err := ts.Tools(icontext.NewReadonlyContext(
+				icontext.NewInvocationContext(
+					t.Context(),
+					icontext.InvocationContextParams{},
+				),
+			))
+			if err != nil {
+				t.Fatalf("Failed to get tools: %v", err)
+			}
+
+			gotToolNames := make([]string, len(tools))
+			for i, tool := range tools {
+				gotToolNames[i] = tool.Name()
+				if decl := tool.(interface {
+					Declaration() *genai.FunctionDeclaration
+				}).Declaration(); decl.Name != tool.Name() {
+					t.Errorf("declaration name = %q, want %q", decl.Name, tool.Name())
+				}
+			}
+			if diff := cmp.Diff(tt.want, gotToolNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("tools mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
 }
 
-func (r *testAgentRunner) session(t *testing.T, appName, userID, sessionID string) (session.Session, error) {
-	ctx := t.Context()
-	if last := r.lastSession; last != nil && last.ID() == sessionID {
-		resp, err := r.sessionService.Get(ctx, &session.GetRequest{
-			AppName:   "test_app",
-			UserID:    "test_user",
-			SessionID: sessionID,
-		})
-		r.lastSession = resp.Session
-		return resp.Session, err
+func TestNamePrefix(t *testing.T) {
+	newAgent := func(t *testing.T, llm model.LLM, prefixes ...string) agent.Agent {
+		t.Helper()
+		var toolsets []tool.Toolset
+		for _, prefix := range prefixes {
+			ts, err := mcptoolset.New(mcptoolset.Config{
+				Transport:  newWeatherServer(t, "get_weather"),
+				NamePrefix: prefix,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create MCP tool set: %v", err)
+			}
+			toolsets = append(toolsets, ts)
+		}
+		a, err := llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Toolsets: toolsets})
+		if err != nil {
+			t.Fatalf("Failed to create agent: %v", err)
+		}
+		return a
 	}
-	resp, err := r.sessionService.Create(ctx, &session.CreateRequest{
-		AppName:   "test_app",
-		UserID:    "test_user",
-		SessionID: sessionID,
+
+	t.Run("collision", func(t *testing.T) {
+		llm := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("hi", genai.RoleModel)}}
+		runner := testutil.NewTestAgentRunner(t, newAgent(t, llm, "", ""))
+		var gotErr error
+		for _, err := range runner.Run(t, "session1", "what is the weather in london?") {
+			if err != nil {
+				gotErr = err
+				break
+			}
+		}
+		if gotErr == nil || !strings.Contains(gotErr.Error(), "duplicate tool") {
+			t.Errorf("Run() error = %v, want a duplicate tool error", gotErr)
+		}
 	})
-	r.lastSession = resp.Session
-	return resp.Session, err
-}
 
-func (r *testAgentRunner) Run(t *testing.T, sessionID, newMessage string) iter.Seq2[*session.Event, error] {
-	t.Helper()
-	ctx := t.Context()
+	t.Run("prefixed", func(t *testing.T) {
+		llm := &testutil.MockModel{Responses: []*genai.Content{
+			{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "b_get_weather", Args: map[string]any{"city": "london"}}}}},
+			genai.NewContentFromText("sunny", genai.RoleModel),
+		}}
+		runner := testutil.NewTestAgentRunner(t, newAgent(t, llm, "a_", "b_"))
+		var gotResponse *genai.FunctionResponse
+		for event, err := range runner.Run(t, "session1", "what is the weather in london?") {
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, part := range event.Content.Parts {
+				if part.FunctionResponse != nil {
+					gotResponse = part.FunctionResponse
+				}
+			}
+		}
 
-	userID := "test_user"
+		var gotTools []string
+		for name := range llm.Requests[0].Tools {
+			gotTools = append(gotTools, name)
+		}
+		if diff := cmp.Diff([]string{"a_get_weather", "b_get_weather"}, gotTools, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+			t.Errorf("request tools mismatch (-want +got):\n%s", diff)
+		}
+		want := &genai.FunctionResponse{
+			Name:     "b_get_weather",
+			Response: map[string]any{"output": map[string]any{"weather_summary": `Today in "london" is sunny`}},
+		}
+		if diff := cmp.Diff(want, gotResponse, cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID")); diff != "" {
+			t.Errorf("function response mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
 
-	session, err := r.session(t, r.appName, userID, sessionID)
+func TestToolNames(t *testing.T) {
+	ts, err := mcptoolset.New(mcptoolset.Config{
+		Transport:  newWeatherServer(t, "get_weather", "get_forecast", "delete_city"),
+		ToolFilter: tool.StringPredicate([]string{"get_weather"}),
+		ToolNames:  map[string]string{"get_weather": "weather"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "weather", Args: map[string]any{"city": "london"}}}}},
+		genai.NewContentFromText("sunny", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Toolsets: []tool.Toolset{ts}})
 	if err != nil {
-		t.Fatalf("failed to get/create session: %v", err)
+		t.Fatalf("Failed to create agent: %v", err)
 	}
 
-	var content *genai.Content
-	if newMessage != "" {
-		content = genai.NewContentFromText(newMessage, genai.RoleUser)
+	var gotResponse *genai.FunctionResponse
+	for event, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session1", "what is the weather in london?") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, part := range event.Content.Parts {
+			if part.FunctionResponse != nil {
+				gotResponse = part.FunctionResponse
+			}
+		}
 	}
 
-	return r.runner.Run(ctx, userID, session.ID(), content, agent.RunConfig{})
+	// Only the allowed tool is declared, with its new name.
+	var gotDecls []string
+	for _, genaiTool := range llm.Requests[0].Config.Tools {
+		for _, decl := range genaiTool.FunctionDeclarations {
+			gotDecls = append(gotDecls, decl.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"weather"}, gotDecls); diff != "" {
+		t.Errorf("function declarations mismatch (-want +got):\n%s", diff)
+	}
+	// The call of the renamed tool is dispatched to the tool of the server.
+	want := &genai.FunctionResponse{
+		Name:     "weather",
+		Response: map[string]any{"output": map[string]any{"weather_summary": `Today in "london" is sunny`}},
+	}
+	if diff := cmp.Diff(want, gotResponse, cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID")); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
 }
 
-func TestToolFilter(t *testing.T) {
-	const toolDescription = "returns weather in the given city"
-
+func TestServerDisconnect(t *testing.T) {
 	clientTransport, serverTransport := mcp.NewInMemoryTransports()
-
 	server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
-	mcp.AddTool(server, &mcp.Tool{Name: "get_weather", Description: toolDescription}, weatherFunc)
-	mcp.AddTool(server, &mcp.Tool{Name: "get_weather1", Description: toolDescription}, weatherFunc)
-	_, err := server.Connect(t.Context(), serverTransport, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_weather", Description: "returns weather in the given city"}, weatherFunc)
+	serverSession, err := server.Connect(t.Context(), serverTransport, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	ts, err := mcptoolset.New(mcptoolset.Config{
-		Transport:  clientTransport,
-		ToolFilter: tool.StringPredicate([]string{"get_weather"}),
-	})
+	ts, err := mcptoolset.New(mcptoolset.Config{Transport: clientTransport})
 	if err != nil {
 		t.Fatalf("Failed to create MCP tool set: %v", err)
 	}
+	defer ts.Close()
+	weatherTool := getFunctionTool(t, ts, "get_weather")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	toolCtx := toolinternal.NewToolContext(icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{}), "", nil)
+	if _, err := weatherTool.Run(toolCtx, map[string]any{"city": "london"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if err := serverSession.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The in-memory transport can't reconnect, so the call fails after the
+	// server disconnects.
+	_, err = weatherTool.Run(toolCtx, map[string]any{"city": "london"})
+	if err == nil {
+		t.Fatal("Run() after the server disconnected succeeded, want error")
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("Run() after the server disconnected didn't return until the deadline, error = %v", err)
+	}
+	if !strings.Contains(err.Error(), `failed to call MCP tool "get_weather"`) {
+		t.Errorf("Run() error = %v, want a failed MCP tool call error", err)
+	}
+
+	if err := ts.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := weatherTool.Run(toolCtx, map[string]any{"city": "london"}); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("Run() after Close() error = %v, want a closed error", err)
+	}
+}
+
+// reconnectingTransport connects every client session to a new in-memory MCP
+// server, with the tools returned by toolNames for the number of the session.
+type reconnectingTransport struct {
+	toolNames func(session int) []string
+
+	mu       sync.Mutex
+	sessions []*mcp.ServerSession
+}
 
-	tools, err := ts.Tools(icontext.NewReadonlyContext(
-		icontext.NewInvocationContext(
-			t.Context(),
-			icontext.InvocationContextParams{},
-		),
-	))
+func (tr *reconnectingTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
+	for _, name := range tr.toolNames(len(tr.sessions)) {
+		mcp.AddTool(server, &mcp.Tool{Name: name, Description: "returns weather in the given city"}, weatherFunc)
+	}
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
 	if err != nil {
-		t.Fatalf("Failed to get tools: %v", err)
+		return nil, err
+	}
+	tr.sessions = append(tr.sessions, serverSession)
+	return clientTransport.Connect(ctx)
+}
+
+// drop closes the last session on the server side.
+func (tr *reconnectingTransport) drop(t *testing.T) {
+	t.Helper()
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if err := tr.sessions[len(tr.sessions)-1].Close(); err != nil {
+		t.Fatal(err)
 	}
+}
 
-	gotToolNames := make([]string, len(tools))
-	for i, tool := range tools {
-		gotToolNames[i] = tool.Name()
+func TestServerReconnect(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		toolNames   func(session int) []string
+		wantWarning bool
+	}{
+		{
+			name:      "same tools",
+			toolNames: func(int) []string { return []string{"get_weather"} },
+		},
+		{
+			name: "changed tools",
+			toolNames: func(session int) []string {
+				if session == 0 {
+					return []string{"get_weather"}
+				}
+				return []string{"get_weather", "get_forecast"}
+			},
+			wantWarning: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &reconnectingTransport{toolNames: tt.toolNames}
+			ts, err := mcptoolset.New(mcptoolset.Config{Transport: transport})
+			if err != nil {
+				t.Fatalf("Failed to create MCP tool set: %v", err)
+			}
+			defer ts.Close()
+			weatherTool := getFunctionTool(t, ts, "get_weather")
+
+			var logs bytes.Buffer
+			ctx := runconfig.ToContext(t.Context(), &runconfig.RunConfig{Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+			toolCtx := toolinternal.NewToolContext(icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{}), "", nil)
+			if _, err := weatherTool.Run(toolCtx, map[string]any{"city": "london"}); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			// The server drops the connection, the next call reconnects.
+			transport.drop(t)
+			got, err := weatherTool.Run(toolCtx, map[string]any{"city": "paris"})
+			if err != nil {
+				t.Fatalf("Run() after the connection was dropped error = %v", err)
+			}
+			want := map[string]any{"output": map[string]any{"weather_summary": `Today in "paris" is sunny`}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+			}
+			if got := len(transport.sessions); got != 2 {
+				t.Errorf("server got %d sessions, want 2", got)
+			}
+
+			gotWarning := strings.Contains(logs.String(), "MCP server tools changed after reconnecting")
+			if gotWarning != tt.wantWarning {
+				t.Errorf("logs = %q, want tools changed warning: %v", logs.String(), tt.wantWarning)
+			}
+			if tt.wantWarning && !strings.Contains(logs.String(), "added=[get_forecast]") {
+				t.Errorf("logs = %q, want the added tool", logs.String())
+			}
+		})
 	}
-	wantToolNames := []string{"get_weather"}
+}
 
-	if diff := cmp.Diff(wantToolNames, gotToolNames); diff != "" {
-		t.Errorf("tools mismatch (-want +got):\n%s", diff)
+// getFunctionTool returns the tool with the given name from the tool set.
+func getFunctionTool(t *testing.T, ts tool.Toolset, name string) toolinternal.FunctionTool {
+	t.Helper()
+	tools, err := ts.Tools(icontext.NewReadonlyContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})))
+	if err != nil {
+		t.Fatalf("Failed to get tools: %v", err)
+	}
+	for _, tl := range tools {
+		if tl.Name() == name {
+			return tl.(toolinternal.FunctionTool)
+		}
 	}
+	t.Fatalf("tool %q not found", name)
+	return nil
 }
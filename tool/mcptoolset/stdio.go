@@ -0,0 +1,230 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/tool"
+)
+
+// StdioConfig provides configuration for a MCP ToolSet which runs the MCP
+// server as a subprocess.
+type StdioConfig struct {
+	// Command is the name or path of the MCP server executable.
+	Command string
+	// Args are the command line arguments passed to the command.
+	Args []string
+	// Env holds additional environment variables for the process in the
+	// "KEY=value" form. The process inherits the environment of the current
+	// process.
+	Env []string
+	// Dir is the working directory of the process. If empty, the process runs
+	// in the current directory.
+	Dir string
+	// Stderr, if set, receives the standard error of the process. Otherwise,
+	// each line the process writes to its standard error is logged at the
+	// info level with Logger.
+	Stderr io.Writer
+	// Logger logs the standard error of the process when Stderr is nil. If
+	// nil, slog.Default is used.
+	Logger *slog.Logger
+	// TerminateDuration controls how long Close waits for the process to exit
+	// after closing its stdin, before terminating it. If zero, the MCP SDK
+	// default is used.
+	TerminateDuration time.Duration
+
+	// Client is an optional custom MCP client to use. If nil, a default client will be created.
+	Client *mcp.Client
+	// ToolFilter selects tools for which tool.Predicate returns true.
+	// See Config.ToolFilter.
+	ToolFilter tool.Predicate
+	// NamePrefix is prepended to the names of the tools exposed to the LLM.
+	// See Config.NamePrefix.
+	NamePrefix string
+	// MCPToolFilter selects the tools of the MCP server for which it returns
+	// true. See Config.MCPToolFilter.
+	MCPToolFilter func(*mcp.Tool) bool
+	// ToolNames renames tools. See Config.ToolNames.
+	ToolNames map[string]string
+	// Resources adds a tool reading the resources of the MCP server.
+	// See Config.Resources.
+	Resources bool
+	// SaveResourceBlobs saves the binary resources as artifacts.
+	// See Config.SaveResourceBlobs.
+	SaveResourceBlobs bool
+}
+
+// NewStdio starts the MCP server command and returns a MCP ToolSet which
+// communicates with it over the process stdin and stdout.
+//
+// Unlike New, NewStdio connects eagerly, so failures to start the process or
+// to initialize the MCP session, e.g. because the process exited right away,
+// are returned from NewStdio along with the tail of the process stderr.
+// The ctx bounds the start up only, not the lifetime of the process.
+// If the process exits later, the next request to the ToolSet starts it again.
+//
+// The caller must call Close to shut the process down.
+//
+// Example:
+//
+//	ts, err := mcptoolset.NewStdio(ctx, mcptoolset.StdioConfig{
+//		Command: "npx",
+//		Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "/tmp"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer ts.Close()
+func NewStdio(ctx context.Context, cfg StdioConfig) (Toolset, error) {
+	if cfg.Command == "" {
+		return nil, errors.New("command is required")
+	}
+	client := cfg.Client
+	if client == nil {
+		client = mcp.NewClient(&mcp.Implementation{Name: "adk-mcp-client", Version: version.Version}, nil)
+	}
+	s := &set{
+		connect: func(ctx context.Context) (*mcp.ClientSession, error) {
+			return connectStdio(ctx, client, cfg)
+		},
+		toolFilter:        cfg.ToolFilter,
+		mcpToolFilter:     cfg.MCPToolFilter,
+		namePrefix:        cfg.NamePrefix,
+		toolNames:         cfg.ToolNames,
+		resources:         cfg.Resources,
+		saveResourceBlobs: cfg.SaveResourceBlobs,
+	}
+	if _, _, err := s.getSession(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server %q: %w", cfg.Command, err)
+	}
+	return s, nil
+}
+
+// connectStdio starts a new server process and connects to it.
+func connectStdio(ctx context.Context, client *mcp.Client, cfg StdioConfig) (*mcp.ClientSession, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Dir = cfg.Dir
+	if len(cfg.Env) > 0 {
+		cmd.Env = append(os.Environ(), cfg.Env...)
+	}
+	stderr := &tailWriter{max: maxStderrTail}
+	cmd.Stderr = stderr
+	if cfg.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(stderr, cfg.Stderr)
+	} else {
+		logger := cfg.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		cmd.Stderr = io.MultiWriter(stderr, &logWriter{logger: logger.With("command", cfg.Command)})
+	}
+
+	session, err := client.Connect(ctx, &mcp.CommandTransport{
+		Command:           cmd,
+		TerminateDuration: cfg.TerminateDuration,
+	}, nil)
+	if err != nil {
+		// A failed Connect waits for the process, so its state and stderr
+		// are complete here.
+		if cmd.ProcessState != nil {
+			err = fmt.Errorf("%w (%s)", err, cmd.ProcessState)
+		}
+		if tail := strings.TrimSpace(stderr.String()); tail != "" {
+			err = fmt.Errorf("%w; stderr: %s", err, tail)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// maxStderrTail is the number of bytes of the process stderr included in the
+// start up errors.
+const maxStderrTail = 4096
+
+// tailWriter keeps the last max bytes written to it.
+type tailWriter struct {
+	max int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.max {
+		w.buf = w.buf[len(w.buf)-w.max:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
+
+// logWriter logs each line written to it.
+type logWriter struct {
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	// Don't buffer a line without end forever.
+	if len(w.buf) >= maxStderrTail {
+		w.log(w.buf)
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *logWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	w.logger.Info("MCP server stderr", "line", string(line))
+}
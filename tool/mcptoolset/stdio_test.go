@@ -0,0 +1,251 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/mcptoolset"
+)
+
+// stdioServerEnv makes the test binary act as a MCP stdio server, see
+// TestMain.
+const stdioServerEnv = "MCPTOOLSET_TEST_STDIO_SERVER"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(stdioServerEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "serve":
+		if pidFile := os.Getenv("PID_FILE"); pidFile != "" {
+			if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0o600); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		fmt.Fprintln(os.Stderr, "weather server: listening on stdio")
+		server := mcp.NewServer(&mcp.Implementation{Name: "weather_server", Version: "v1.0.0"}, nil)
+		mcp.AddTool(server, &mcp.Tool{Name: "get_weather", Description: "returns weather in the given city"}, weatherFunc)
+		if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "weather server: missing API key")
+		os.Exit(3)
+	}
+}
+
+func newStdioConfig(t *testing.T, mode string) mcptoolset.StdioConfig {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mcptoolset.StdioConfig{
+		Command: exe,
+		Env:     []string{stdioServerEnv + "=" + mode},
+	}
+}
+
+func TestNewStdio(t *testing.T) {
+	ts, err := mcptoolset.NewStdio(t.Context(), newStdioConfig(t, "serve"))
+	if err != nil {
+		t.Fatalf("NewStdio() error = %v", err)
+	}
+
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "london"}}}}},
+		genai.NewContentFromText("sunny", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "weather_agent", Model: llm, Toolsets: []tool.Toolset{ts}})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	var gotResponse *genai.FunctionResponse
+	for event, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session1", "what is the weather in london?") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, part := range event.Content.Parts {
+			if part.FunctionResponse != nil {
+				gotResponse = part.FunctionResponse
+			}
+		}
+	}
+	want := &genai.FunctionResponse{
+		Name:     "get_weather",
+		Response: map[string]any{"output": map[string]any{"weather_summary": `Today in "london" is sunny`}},
+	}
+	if diff := cmp.Diff(want, gotResponse, cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID")); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := ts.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := ts.Tools(newReadonlyContext(t)); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("Tools() after Close() error = %v, want a closed error", err)
+	}
+}
+
+func TestNewStdio_LogsStderr(t *testing.T) {
+	var buf syncBuffer
+	cfg := newStdioConfig(t, "serve")
+	cfg.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	ts, err := mcptoolset.NewStdio(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewStdio() error = %v", err)
+	}
+	// The server writes to stderr before it answers the initialization.
+	if err := ts.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	for _, want := range []string{`msg="MCP server stderr"`, "command=" + cfg.Command, `line="weather server: listening on stdio"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestNewStdio_Restart(t *testing.T) {
+	cfg := newStdioConfig(t, "serve")
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	cfg.Env = append(cfg.Env, "PID_FILE="+pidFile)
+	ts, err := mcptoolset.NewStdio(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewStdio() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+
+	weatherTool := getFunctionTool(t, ts, "get_weather")
+
+	pid := readPID(t, pidFile)
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The call fails on the broken connection, the tool set starts a new
+	// server and retries it.
+	toolCtx := toolinternal.NewToolContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}), "", nil)
+	got, err := weatherTool.Run(toolCtx, map[string]any{"city": "london"})
+	if err != nil {
+		t.Fatalf("Run() after the server exited error = %v", err)
+	}
+	want := map[string]any{"output": map[string]any{"weather_summary": `Today in "london" is sunny`}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+	}
+	if got := readPID(t, pidFile); got == pid {
+		t.Errorf("server wasn't restarted, pid = %d", got)
+	}
+}
+
+func TestNewStdio_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     mcptoolset.StdioConfig
+		wantErr []string
+	}{
+		{
+			name:    "no command",
+			wantErr: []string{"command is required"},
+		},
+		{
+			name:    "command not found",
+			cfg:     mcptoolset.StdioConfig{Command: filepath.Join(t.TempDir(), "missing")},
+			wantErr: []string{"failed to start MCP server", "missing"},
+		},
+		{
+			name:    "process exits",
+			cfg:     newStdioConfig(t, "fail"),
+			wantErr: []string{"failed to start MCP server", "exit status 3", "stderr: weather server: missing API key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := mcptoolset.NewStdio(t.Context(), tt.cfg)
+			if err == nil {
+				_ = ts.Close()
+				t.Fatal("NewStdio() succeeded, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("NewStdio() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func newReadonlyContext(t *testing.T) agent.ReadonlyContext {
+	return icontext.NewReadonlyContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}))
+}
+
+func readPID(t *testing.T, name string) int {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
@@ -15,7 +15,6 @@
 package mcptoolset
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -29,11 +28,10 @@ import (
 	"google.golang.org/adk/tool"
 )
 
-type getSessionFunc func(ctx context.Context) (*mcp.ClientSession, error)
-
-func convertTool(t *mcp.Tool, getSessionFunc getSessionFunc) (tool.Tool, error) {
+func convertTool(t *mcp.Tool, set *set) (*mcpTool, error) {
 	return &mcpTool{
 		name:        t.Name,
+		mcpName:     t.Name,
 		description: t.Description,
 		funcDeclaration: &genai.FunctionDeclaration{
 			Name:                 t.Name,
@@ -41,16 +39,25 @@ func convertTool(t *mcp.Tool, getSessionFunc getSessionFunc) (tool.Tool, error)
 			ParametersJsonSchema: t.InputSchema,
 			ResponseJsonSchema:   t.OutputSchema,
 		},
-		getSessionFunc: getSessionFunc,
+		set: set,
 	}, nil
 }
 
 type mcpTool struct {
-	name            string
+	name string
+	// mcpName is the name of the tool on the MCP server.
+	mcpName         string
 	description     string
 	funcDeclaration *genai.FunctionDeclaration
 
-	getSessionFunc getSessionFunc
+	// set provides the MCP session for the tool calls.
+	set *set
+}
+
+// setName sets the name exposed to the LLM.
+func (t *mcpTool) setName(name string) {
+	t.name = name
+	t.funcDeclaration.Name = name
 }
 
 // Name implements the tool.Tool.
@@ -77,18 +84,17 @@ func (t *mcpTool) Declaration() *genai.FunctionDeclaration {
 }
 
 func (t *mcpTool) Run(ctx tool.Context, args any) (map[string]any, error) {
-	session, err := t.getSessionFunc(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get session: %w", err)
-	}
-
 	// TODO: add auth
-	res, err := session.CallTool(ctx, &mcp.CallToolParams{
-		Name:      t.name,
-		Arguments: args,
+	var res *mcp.CallToolResult
+	err := t.set.call(ctx, func(session *mcp.ClientSession) (err error) {
+		res, err = session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      t.mcpName,
+			Arguments: args,
+		})
+		return err
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to call MCP tool %q with err: %w", t.name, err)
+		return nil, fmt.Errorf("failed to call MCP tool %q with err: %w", t.mcpName, err)
 	}
 
 	if res.IsError {
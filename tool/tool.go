@@ -21,6 +21,7 @@ import (
 	"context"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
 )
@@ -51,6 +52,16 @@ type Context interface {
 	Actions() *session.EventActions
 	// SearchMemory performs a semantic search on the agent's memory.
 	SearchMemory(context.Context, string) (*memory.SearchResponse, error)
+
+	// RequestCredential requests the credential described by cfg from the
+	// client. The tool should return after requesting it, e.g. with a result
+	// telling that the authorization is pending. Once the client provides
+	// the credential, the tool is called again with the same arguments and
+	// Credential returns it.
+	RequestCredential(cfg *auth.Config)
+	// Credential returns the credential described by cfg, which the client
+	// provided in the current invocation, or nil.
+	Credential(cfg *auth.Config) *auth.Credential
 }
 
 // Toolset is an interface for a collection of tools. It allows grouping
@@ -78,3 +89,11 @@ func StringPredicate(allowedTools []string) Predicate {
 		return m[tool.Name()]
 	}
 }
+
+// DenyPredicate is a helper that creates a Predicate excluding the tools of a string slice.
+func DenyPredicate(deniedTools []string) Predicate {
+	allowed := StringPredicate(deniedTools)
+	return func(ctx agent.ReadonlyContext, tool Tool) bool {
+		return !allowed(ctx, tool)
+	}
+}